@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/apikey"
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/infra"
+	"moneytransfer/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// apiKeyCmd groups the commands managing the API keys of organizations
+var apiKeyCmd = &cobra.Command{
+	Use:   "api-key",
+	Short: "Manage the API keys organizations authenticate with",
+	Long: `These commands issue, rotate and revoke the API keys of organizations.
+A key authenticates the requests of its organization to the REST API, which only
+lets it debit the accounts of that organization. Keys are printed once, when they
+are created or rotated: only their hash is stored.`,
+}
+
+// apiKeyCreateCmd represents the api-key create command
+var apiKeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Issue an API key for an organization",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		organization, _ := cmd.Flags().GetString("organization")
+		name, _ := cmd.Flags().GetString("name")
+
+		svc, logger := newAPIKeyService()
+		k, plaintext, err := svc.Create(context.Background(), organization, name)
+		if err != nil {
+			logger.Error("failed to create api key", slog.Any("error", err), slog.String("organization", organization))
+			os.Exit(1)
+		}
+		writeIssuedKey(os.Stdout, k, plaintext)
+	},
+}
+
+// apiKeyRotateCmd represents the api-key rotate command
+var apiKeyRotateCmd = &cobra.Command{
+	Use:   "rotate <id>",
+	Short: "Replace an API key with a new one",
+	Long: `This command issues a new key for the organization of the key <id>. The old key
+stays valid for the grace period set with --grace, so that clients can switch to the
+new key without downtime, and is rejected afterwards.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		grace, _ := cmd.Flags().GetDuration("grace")
+
+		svc, logger := newAPIKeyService()
+		id := parseAPIKeyID(args[0], logger)
+		k, plaintext, err := svc.Rotate(context.Background(), id, grace)
+		if err != nil {
+			logger.Error("failed to rotate api key", slog.Any("error", err), slog.Int64("api_key_id", id))
+			os.Exit(1)
+		}
+		writeIssuedKey(os.Stdout, k, plaintext)
+	},
+}
+
+// apiKeyRevokeCmd represents the api-key revoke command
+var apiKeyRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke an API key at once",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, logger := newAPIKeyService()
+		id := parseAPIKeyID(args[0], logger)
+		if err := svc.Revoke(context.Background(), id); err != nil {
+			logger.Error("failed to revoke api key", slog.Any("error", err), slog.Int64("api_key_id", id))
+			os.Exit(1)
+		}
+		fmt.Printf("API key %d revoked\n", id)
+	},
+}
+
+// apiKeyListCmd represents the api-key list command
+var apiKeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the API keys of an organization",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		organization, _ := cmd.Flags().GetString("organization")
+
+		svc, logger := newAPIKeyService()
+		keys, err := svc.List(context.Background(), organization)
+		if err != nil {
+			logger.Error("failed to list api keys", slog.Any("error", err), slog.String("organization", organization))
+			os.Exit(1)
+		}
+
+		w := bufio.NewWriter(os.Stdout)
+		writeAPIKeys(w, keys, time.Now())
+		if err := w.Flush(); err != nil {
+			logger.Error("failed to write api keys", slog.Any("error", err))
+			os.Exit(1)
+		}
+	},
+}
+
+// newAPIKeyService creates the api key service of the configured database
+// and a logger writing to standard error, standard output holds the keys
+func newAPIKeyService() (service.APIKeyService, *slog.Logger) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		os.Exit(1)
+	}
+	logger := newLogger(os.Stderr, config)
+
+	db, err := infra.NewDatabase(config.DatabaseURL, logger)
+	if err != nil {
+		logger.Error("failed to create new database", slog.Any("error", err))
+		os.Exit(1)
+	}
+	return service.NewAPIKeyService(apikey.NewPostgresRepository(db), audit.NewPostgresRepository(db), logger, nil), logger
+}
+
+// parseAPIKeyID parses the ID of an API key given as argument
+func parseAPIKeyID(arg string, logger *slog.Logger) int64 {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		logger.Error("invalid api key id", slog.String("id", arg))
+		os.Exit(1)
+	}
+	return id
+}
+
+// writeIssuedKey writes a key that was just issued, with its plaintext
+func writeIssuedKey(w io.Writer, k *apikey.Key, plaintext string) {
+	fmt.Fprintf(w, "ID:            %d\n", k.ID)
+	fmt.Fprintf(w, "Organization:  %s\n", k.OrganizationName)
+	if k.Name != "" {
+		fmt.Fprintf(w, "Name:          %s\n", k.Name)
+	}
+	if k.RotatedFrom != 0 {
+		fmt.Fprintf(w, "Rotated from:  %d\n", k.RotatedFrom)
+	}
+	fmt.Fprintf(w, "Key:           %s\n", plaintext)
+	fmt.Fprintln(w, "The key is not stored and cannot be shown again.")
+}
+
+// writeAPIKeys writes a table of keys and of their state at the given time
+func writeAPIKeys(w io.Writer, keys []apikey.Key, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tPREFIX\tNAME\tSTATE\tCREATED\tROTATED FROM")
+	for _, k := range keys {
+		state := "active"
+		switch {
+		case !k.RevokedAt.IsZero():
+			state = "revoked " + k.RevokedAt.Format(time.RFC3339)
+		case !k.ExpiresAt.IsZero() && !k.Active(now):
+			state = "expired " + k.ExpiresAt.Format(time.RFC3339)
+		case !k.ExpiresAt.IsZero():
+			state = "expires " + k.ExpiresAt.Format(time.RFC3339)
+		}
+		rotatedFrom := ""
+		if k.RotatedFrom != 0 {
+			rotatedFrom = strconv.FormatInt(k.RotatedFrom, 10)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Prefix, k.Name, state, k.CreatedAt.Format(time.RFC3339), rotatedFrom)
+	}
+	tw.Flush()
+}
+
+func init() {
+	apiKeyCreateCmd.Flags().String("organization", "", "Name of the organization the key is issued for")
+	apiKeyCreateCmd.Flags().String("name", "", "Description of what the key is used for")
+	apiKeyCreateCmd.MarkFlagRequired("organization")
+	apiKeyRotateCmd.Flags().Duration("grace", 24*time.Hour, "Time the old key stays valid, 0 to reject it at once")
+	apiKeyListCmd.Flags().String("organization", "", "Name of the organization")
+	apiKeyListCmd.MarkFlagRequired("organization")
+
+	apiKeyCmd.AddCommand(apiKeyCreateCmd, apiKeyRotateCmd, apiKeyRevokeCmd, apiKeyListCmd)
+	rootCmd.AddCommand(apiKeyCmd)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/infra"
+	"moneytransfer/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// auditCmd groups the commands reading the audit log
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Read the audit log",
+	Long: `These commands read the append-only audit log of the bulk transfers, returns and
+administrative actions, with their actor, source, before and after states and outcome.
+The actions performed with the CLI have the actor system.`,
+}
+
+// auditListCmd represents the audit list command
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List audit events, newest first",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		organization, _ := cmd.Flags().GetString("organization")
+		actor, _ := cmd.Flags().GetString("actor")
+		iban, _ := cmd.Flags().GetString("iban")
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		limit, _ := cmd.Flags().GetInt("limit")
+		beforeID, _ := cmd.Flags().GetInt64("before-id")
+		showStates, _ := cmd.Flags().GetBool("states")
+
+		svc, logger := newAuditService()
+		filter := audit.Filter{
+			Actor:            actor,
+			OrganizationName: organization,
+			AccountIBAN:      iban,
+			BeforeID:         beforeID,
+			Limit:            limit,
+		}
+		var err error
+		if filter.From, err = parseAuditTime(from); err != nil {
+			logger.Error("invalid --from time", slog.Any("error", err))
+			os.Exit(1)
+		}
+		if filter.To, err = parseAuditTime(to); err != nil {
+			logger.Error("invalid --to time", slog.Any("error", err))
+			os.Exit(1)
+		}
+
+		events, err := svc.List(context.Background(), filter)
+		if err != nil {
+			logger.Error("failed to list audit events", slog.Any("error", err))
+			os.Exit(1)
+		}
+
+		w := bufio.NewWriter(os.Stdout)
+		writeAuditEvents(w, events, showStates)
+		if err := w.Flush(); err != nil {
+			logger.Error("failed to write audit events", slog.Any("error", err))
+			os.Exit(1)
+		}
+	},
+}
+
+// newAuditService creates the audit service of the configured database
+// and a logger writing to standard error, standard output holds the events
+func newAuditService() (service.AuditService, *slog.Logger) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		os.Exit(1)
+	}
+	logger := newLogger(os.Stderr, config)
+
+	db, err := infra.NewDatabase(config.DatabaseURL, logger)
+	if err != nil {
+		logger.Error("failed to create new database", slog.Any("error", err))
+		os.Exit(1)
+	}
+	return service.NewAuditService(audit.NewPostgresRepository(db), logger, nil), logger
+}
+
+// parseAuditTime parses a bound of the time range given as an RFC 3339 time or a date,
+// the zero time if s is empty
+func parseAuditTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// writeAuditEvents writes a table of audit events, with their states if showStates is set
+func writeAuditEvents(w io.Writer, events []audit.Event, showStates bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "ID\tTIME\tACTOR\tSOURCE\tACTION\tORGANIZATION\tACCOUNT\tRESOURCE\tOUTCOME"
+	if showStates {
+		header += "\tBEFORE\tAFTER"
+	}
+	fmt.Fprintln(tw, header)
+	for _, e := range events {
+		resource := e.ResourceType
+		if e.ResourceID != "" {
+			resource += ":" + e.ResourceID
+		}
+		outcome := string(e.Outcome)
+		if e.ErrorCode != "" {
+			outcome += " (" + e.ErrorCode + ")"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
+			e.ID, e.OccurredAt.Format(time.RFC3339), e.Actor, orDash(e.SourceIP), e.Action,
+			orDash(e.OrganizationName), orDash(e.AccountIBAN), orDash(resource), outcome)
+		if showStates {
+			fmt.Fprintf(tw, "\t%s\t%s", orDash(string(e.Before)), orDash(string(e.After)))
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+// orDash returns s, or a dash if it is empty so that the columns stay aligned
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	auditListCmd.Flags().String("organization", "", "Name of the organization acted on")
+	auditListCmd.Flags().String("actor", "", "Subject of the actor, such as api_key:<id> or system")
+	auditListCmd.Flags().String("iban", "", "IBAN of the account acted on")
+	auditListCmd.Flags().String("from", "", "Start of the time range, included (YYYY-MM-DD or RFC 3339)")
+	auditListCmd.Flags().String("to", "", "End of the time range, excluded (YYYY-MM-DD or RFC 3339)")
+	auditListCmd.Flags().Int("limit", audit.DefaultLimit, "Maximum number of events")
+	auditListCmd.Flags().Int64("before-id", 0, "Only list the events older than this event")
+	auditListCmd.Flags().Bool("states", false, "Also print the states before and after the actions")
+
+	auditCmd.AddCommand(auditListCmd)
+	rootCmd.AddCommand(auditCmd)
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/fieldcrypt"
+	"moneytransfer/internal/infra"
+	"moneytransfer/internal/transfer"
+
+	"github.com/spf13/cobra"
+)
+
+// encryptionCmd groups the commands managing the encryption of the counterparty details
+var encryptionCmd = &cobra.Command{
+	Use:   "encryption",
+	Short: "Manage the encryption of the counterparty details at rest",
+	Long: `The counterparty names, IBANs and the descriptions of the transfers are encrypted
+with the keys of the keyring given by FIELD_ENCRYPTION_KEYRING_FILE, or by
+FIELD_ENCRYPTION_KEYS, FIELD_ENCRYPTION_PRIMARY_KEY and FIELD_ENCRYPTION_INDEX_KEY.
+New values are encrypted with the primary key, the other keys of the keyring are
+kept to read the values encrypted before a rotation until they are re-encrypted.`,
+}
+
+// encryptionGenerateKeyCmd represents the encryption generate-key command
+var encryptionGenerateKeyCmd = &cobra.Command{
+	Use:   "generate-key",
+	Short: "Print a random base64 key for the keyring",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		key, err := fieldcrypt.GenerateKey()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to generate key:", err)
+			os.Exit(1)
+		}
+		fmt.Println(key)
+	},
+}
+
+// encryptionReencryptCmd represents the encryption reencrypt command
+var encryptionReencryptCmd = &cobra.Command{
+	Use:   "reencrypt",
+	Short: "Encrypt the counterparty details with the primary key",
+	Long: `This command encrypts with the primary key the counterparty details stored in clear,
+before the encryption was enabled, encrypted with another key of the keyring, or in the
+former enc:v1 format that is not bound to its transfer, and indexes the IBANs for the
+searches. It scans the transfers by batches, each in its own
+transaction, and can run while the server is serving. A key can be removed from the
+keyring once the command completed after the rotation to a new primary key.
+An interrupted run resumes from the start, or from --after-id.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		pause, _ := cmd.Flags().GetDuration("pause")
+		afterID, _ := cmd.Flags().GetInt64("after-id")
+
+		// Load the configuration
+		config, err := config.LoadConfig()
+		if err != nil {
+			os.Exit(1)
+		}
+		logger := newLogger(os.Stderr, config)
+
+		if batchSize <= 0 {
+			logger.Error("invalid batch size, expected a positive number", slog.Int("batch_size", batchSize))
+			os.Exit(1)
+		}
+		if !config.FieldEncryption.Enabled() {
+			logger.Error("field encryption is disabled, set FIELD_ENCRYPTION_KEYRING_FILE or FIELD_ENCRYPTION_KEYS")
+			os.Exit(1)
+		}
+
+		// Create DB instance
+		db, err := infra.NewDatabase(config.DatabaseURL, logger)
+		if err != nil {
+			logger.Error("failed to create new database", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer db.Close()
+		repo := newTransferRepository(db, config, logger)
+
+		// An interrupted run stops after the current batch, which is committed
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		scanned, reencrypted := 0, 0
+		for ctx.Err() == nil {
+			batch, err := repo.Reencrypt(context.WithoutCancel(ctx), afterID, batchSize)
+			if err != nil {
+				logger.Error("failed to re-encrypt transfers", slog.Any("error", err), slog.Int64("after_id", afterID))
+				os.Exit(1)
+			}
+			scanned += batch.Scanned
+			reencrypted += batch.Reencrypted
+			if batch.Scanned > 0 {
+				afterID = batch.LastID
+			}
+			logger.Info("Re-encrypted transfers", slog.Int("scanned", scanned), slog.Int("reencrypted", reencrypted), slog.Int64("last_id", afterID))
+			if batch.Scanned < batchSize {
+				logger.Info("Re-encryption completed", slog.Int("scanned", scanned), slog.Int("reencrypted", reencrypted))
+				return
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(pause):
+			}
+		}
+		logger.Warn("Re-encryption interrupted, resume it with --after-id", slog.Int64("after_id", afterID))
+		os.Exit(1)
+	},
+}
+
+// newFieldCipher returns the cipher of the counterparty details of the configured keyring,
+// nil when the encryption is disabled
+func newFieldCipher(config *config.Config) (transfer.FieldCipher, error) {
+	fc := config.FieldEncryption
+	if !fc.Enabled() {
+		return nil, nil
+	}
+	if fc.KeyringFile != "" {
+		return fieldcrypt.LoadKeyringFile(fc.KeyringFile)
+	}
+	return fieldcrypt.ParseKeyring(fc.Keys, fc.PrimaryKeyID, fc.IndexKey)
+}
+
+// newTransferRepository creates the transfer repository of db, encrypting the counterparty
+// details with the configured keyring. It exits when the keyring cannot be loaded.
+func newTransferRepository(db *sql.DB, config *config.Config, logger *slog.Logger) transfer.Repository {
+	cipher, err := newFieldCipher(config)
+	if err != nil {
+		logger.Error("failed to load the field encryption keyring", slog.Any("error", err))
+		os.Exit(1)
+	}
+	return transfer.NewPostgresRepository(db, cipher)
+}
+
+func init() {
+	encryptionReencryptCmd.Flags().Int("batch-size", 500, "Number of transfers scanned per transaction")
+	encryptionReencryptCmd.Flags().Duration("pause", 100*time.Millisecond, "Pause between two batches")
+	encryptionReencryptCmd.Flags().Int64("after-id", 0, "Resume after the transfer with this id")
+
+	encryptionCmd.AddCommand(encryptionGenerateKeyCmd, encryptionReencryptCmd)
+	rootCmd.AddCommand(encryptionCmd)
+}
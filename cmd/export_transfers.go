@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/infra"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/transfer"
+
+	"github.com/spf13/cobra"
+)
+
+// exportTransfersCmd represents the export-transfers command
+var exportTransfersCmd = &cobra.Command{
+	Use:   "export-transfers",
+	Short: "Export the transfers of an account as CSV or NDJSON",
+	Long: `This command streams the transfers of an account created from --from to --to
+included, in UTC, to standard output or to a file, as CSV with a header row or as
+newline delimited JSON. The transfers are read through a server-side cursor from a
+single snapshot of the database, so the export is consistent even while bulk
+transfers are being executed. The output is gzip compressed with --gzip, or when
+the output file ends with .gz.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load the configuration
+		config, err := config.LoadConfig()
+		if err != nil {
+			os.Exit(1)
+		}
+
+		// Logs go to standard error, standard output may hold the export
+		logger := newLogger(os.Stderr, config)
+
+		filter := service.ExportFilter{}
+		filter.IBAN, _ = cmd.Flags().GetString("iban")
+		formatFlag, _ := cmd.Flags().GetString("format")
+		format := service.ExportFormat(formatFlag)
+		if format != service.ExportFormatCSV && format != service.ExportFormatNDJSON {
+			logger.Error("invalid format, expected csv or ndjson", slog.String("format", formatFlag))
+			os.Exit(1)
+		}
+		if fromFlag, _ := cmd.Flags().GetString("from"); fromFlag != "" {
+			filter.From, err = time.Parse("2006-01-02", fromFlag)
+			if err != nil {
+				logger.Error("invalid from date, expected YYYY-MM-DD", slog.String("from", fromFlag))
+				os.Exit(1)
+			}
+		}
+		if toFlag, _ := cmd.Flags().GetString("to"); toFlag != "" {
+			to, err := time.Parse("2006-01-02", toFlag)
+			if err != nil {
+				logger.Error("invalid to date, expected YYYY-MM-DD", slog.String("to", toFlag))
+				os.Exit(1)
+			}
+			// The last day is included
+			filter.To = to.AddDate(0, 0, 1)
+		}
+		statusFlag, _ := cmd.Flags().GetString("status")
+		filter.Status = transfer.Status(statusFlag)
+		if filter.Status != "" && filter.Status != transfer.StatusExecuted && filter.Status != transfer.StatusReturned {
+			logger.Error("invalid status, expected executed or returned", slog.String("status", statusFlag))
+			os.Exit(1)
+		}
+		filter.BatchID, _ = cmd.Flags().GetInt64("batch-id")
+		filter.CounterpartyIBAN, _ = cmd.Flags().GetString("counterparty-iban")
+
+		// Create DB instance
+		db, err := infra.NewDatabase(config.DatabaseURL, logger)
+		if err != nil {
+			logger.Error("failed to create new database", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		exportService := service.NewExportService(db, account.NewPostgresRepository(db), newTransferRepository(db, config, logger), logger, nil)
+
+		out := os.Stdout
+		output, _ := cmd.Flags().GetString("output")
+		if output != "" {
+			out, err = os.Create(output)
+			if err != nil {
+				logger.Error("failed to create output file", slog.Any("error", err))
+				os.Exit(1)
+			}
+			defer out.Close()
+		}
+		bw := bufio.NewWriter(out)
+		var w io.Writer = bw
+		var gz *gzip.Writer
+		if compress, _ := cmd.Flags().GetBool("gzip"); compress || strings.HasSuffix(output, ".gz") {
+			gz = gzip.NewWriter(bw)
+			w = gz
+		}
+
+		count, err := exportService.ExportTransfers(context.Background(), format, filter, w)
+		if err == nil && gz != nil {
+			err = gz.Close()
+		}
+		if err == nil {
+			err = bw.Flush()
+		}
+		if err != nil {
+			logger.Error("failed to export transfers", slog.Any("error", err), slog.String("iban", filter.IBAN), slog.Int("transfers", count))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	exportTransfersCmd.Flags().String("iban", "", "IBAN of the account")
+	exportTransfersCmd.Flags().String("format", "csv", "Format of the export: csv or ndjson")
+	exportTransfersCmd.Flags().String("from", "", "First day of the transfers (YYYY-MM-DD), unbounded if not set")
+	exportTransfersCmd.Flags().String("to", "", "Last day of the transfers (YYYY-MM-DD), unbounded if not set")
+	exportTransfersCmd.Flags().String("status", "", "Status of the transfers: executed or returned, all if not set")
+	exportTransfersCmd.Flags().Int64("batch-id", 0, "Batch the transfers were created by, all if not set")
+	exportTransfersCmd.Flags().String("counterparty-iban", "", "IBAN of the counterparty of the transfers, all if not set")
+	exportTransfersCmd.Flags().Bool("gzip", false, "Compress the export with gzip")
+	exportTransfersCmd.Flags().StringP("output", "o", "", "File the export is written to, standard output if not set")
+	exportTransfersCmd.MarkFlagRequired("iban")
+	rootCmd.AddCommand(exportTransfersCmd)
+}
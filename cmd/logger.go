@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"io"
+	"log/slog"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/logging"
+)
+
+// newLogger creates a JSON logger writing to w at the level of config,
+// with the personal data redacted to the log detail of config
+func newLogger(w io.Writer, config *config.Config) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: config.LogLevel})
+	return slog.New(logging.NewRedactingHandler(handler, logging.Detail(config.LogDetail)))
+}
@@ -4,15 +4,30 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"database/sql"
 	"log/slog"
+	"net/http"
 	"os"
+	"time"
 
 	"moneytransfer/config"
 	"moneytransfer/internal/account"
 	"moneytransfer/internal/api/rest"
+	"moneytransfer/internal/apikey"
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/event"
 	"moneytransfer/internal/infra"
+	"moneytransfer/internal/oidc"
+	"moneytransfer/internal/outbound"
+	"moneytransfer/internal/ratelimit"
 	"moneytransfer/internal/service"
+	"moneytransfer/internal/signing"
+	"moneytransfer/internal/tlsconfig"
 	"moneytransfer/internal/transfer"
+	"moneytransfer/internal/webhook"
 
 	"github.com/spf13/cobra"
 )
@@ -32,6 +47,9 @@ import (
 // @host localhost:8080
 // @BasePath /api/v1
 
+// jwksTimeout is the timeout of the requests fetching the keys of the identity provider
+const jwksTimeout = 10 * time.Second
+
 // restCmd represents the rest command
 var restCmd = &cobra.Command{
 	Use:   "rest",
@@ -46,7 +64,7 @@ It sets up the necessary routes and listens for incoming HTTP requests.`,
 		}
 
 		// Create logger instance
-		logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: config.LogLevel}))
+		logger := newLogger(os.Stdout, config)
 
 		// Create DB instance
 		db, err := infra.NewDatabase(config.DatabaseURL, logger)
@@ -55,20 +73,98 @@ It sets up the necessary routes and listens for incoming HTTP requests.`,
 			os.Exit(1)
 		}
 
+		if !config.FieldEncryption.Enabled() {
+			logger.Warn("field encryption is disabled, counterparty details are stored in clear")
+		}
+
 		// Create account and transaction repositories
 		accountRepo := account.NewPostgresRepository(db)
-		transferRepo := transfer.NewPostgresRepository(db)
+		transferRepo := newTransferRepository(db, config, logger)
+		batchRepo := batch.NewPostgresRepository(db)
+		eventRepo := event.NewPostgresRepository(db)
+		webhookRepo := webhook.NewPostgresRepository(db)
+		apiKeyRepo := apikey.NewPostgresRepository(db)
+		signingRepo := signing.NewPostgresRepository(db)
+		auditRepo := audit.NewPostgresRepository(db)
+
+		// The broker wakes up event streams when the transfer service commits events
+		broker := event.NewBroker()
+
+		// The policy authorizes the requests of authenticated callers, in every service
+		defaultRole := authz.Role(config.Authorization.DefaultRole)
+		if config.Authorization.DefaultRole == "none" {
+			defaultRole = ""
+		}
+		policy := authz.NewPolicy(authz.NewPostgresRepository(db), accountRepo, defaultRole, logger)
+		transferService := newTransferService(db, logger, config, accountRepo, transferRepo, batchRepo, eventRepo, auditRepo, broker, policy)
+		eventService := service.NewEventService(eventRepo, accountRepo, broker, policy)
+		// Endpoints are restricted to public addresses, when they are registered and when they are delivered to
+		webhookURLs := webhook.URLPolicy{AllowHTTP: config.Webhook.AllowHTTP}
+		webhookService := service.NewWebhookService(webhookRepo, auditRepo, webhookURLs, logger, policy)
+		statusReportService := service.NewStatusReportService(accountRepo, batchRepo, transferRepo, logger, policy)
+		statementService := service.NewStatementService(db, accountRepo, transferRepo, logger, policy)
+		exportService := service.NewExportService(db, accountRepo, transferRepo, logger, policy)
+		apiKeyService := service.NewAPIKeyService(apiKeyRepo, auditRepo, logger, policy)
+		signingService := service.NewSigningService(signingRepo, auditRepo, config.RequestSigning.Window, logger)
+		auditService := service.NewAuditService(auditRepo, logger, policy)
+		chainService := service.NewChainService(db, accountRepo, transferRepo, logger, policy)
+
+		// The webhook worker delivers the events committed by the transfer service
+		notify, unsubscribe := broker.Subscribe()
+		defer unsubscribe()
+		worker := webhook.NewWorker(webhookRepo, eventRepo, webhookURLs.Client(), logger, webhook.WorkerConfig{
+			MaxAttempts:  config.Webhook.MaxAttempts,
+			BaseDelay:    config.Webhook.BaseDelay,
+			MaxDelay:     config.Webhook.MaxDelay,
+			PollInterval: config.Webhook.PollInterval,
+			Timeout:      config.Webhook.Timeout,
+			BatchSize:    config.Webhook.BatchSize,
+		}, notify)
+		ctx, stopWorker := context.WithCancel(context.Background())
+		defer stopWorker()
+		go worker.Run(ctx)
 
-		// Create transfer service with retry config
-		retryConfig := service.RetryConfig{
-			BaseDelay:  config.RetryConfig.BaseDelay,
-			MaxDelay:   config.RetryConfig.MaxDelay,
-			MaxRetries: config.RetryConfig.MaxRetries,
+		// The outbound job hands the executed transfers to the sending bank when an output directory is configured
+		if config.Outbound.Dir != "" {
+			job := outbound.NewJob(db, accountRepo, transferRepo, logger, outbound.JobConfig{
+				Dir:       config.Outbound.Dir,
+				Interval:  config.Outbound.Interval,
+				BatchSize: config.Outbound.BatchSize,
+			})
+			go job.Run(ctx)
 		}
-		transferService := service.NewTransferService(db, logger, accountRepo, transferRepo, retryConfig)
 
 		// create a new rest api instance
-		api, err := rest.NewApi(logger, transferService, config.ServerPort)
+		opts := []rest.Option{
+			rest.WithMaxUploadBytes(config.MaxUploadBytes),
+			rest.WithEventService(eventService),
+			rest.WithWebhookService(webhookService),
+			rest.WithStatusReportService(statusReportService),
+			rest.WithStatementService(statementService),
+			rest.WithExportService(exportService),
+			rest.WithAPIKeyService(apiKeyService),
+			rest.WithAuditService(auditService),
+			rest.WithChainService(chainService),
+			rest.WithRequestSigning(signingService, config.RequestSigning.Required),
+			rest.WithHeartbeatInterval(config.SSEHeartbeatInterval),
+			rest.WithRateLimiter(newRateLimiter(db, config.RateLimit), config.RateLimit.Key == "subject"),
+			rest.WithTrustedProxies(config.TrustedProxies),
+		}
+		// Bearer tokens of the identity provider are accepted besides API keys when it is configured
+		if config.OIDC.Enabled() {
+			opts = append(opts, rest.WithTokenVerifier(newTokenVerifier(config.OIDC)))
+		}
+		// The server is served over TLS when a certificate is configured, and clients
+		// authenticate with their certificates when a client CA bundle is configured
+		if config.TLS.Enabled() {
+			tlsOpts, err := newTLSOptions(config.TLS, logger)
+			if err != nil {
+				logger.Error("failed to configure tls", slog.Any("error", err))
+				os.Exit(1)
+			}
+			opts = append(opts, tlsOpts...)
+		}
+		api, err := rest.NewApi(logger, transferService, config.ServerPort, opts...)
 		if err != nil {
 			logger.Error("failed to create new rest api", slog.Any("error", err))
 			os.Exit(1)
@@ -79,6 +175,75 @@ It sets up the necessary routes and listens for incoming HTTP requests.`,
 	},
 }
 
+// newRateLimiter creates the rate limiter of config, its limits are per minute and kept in
+// memory unless they are shared between instances in the database
+func newRateLimiter(db *sql.DB, config config.RateLimitConfig) *ratelimit.Limiter {
+	store := ratelimit.NewMemoryStore()
+	if config.Store == "postgres" {
+		store = ratelimit.NewPostgresStore(db)
+	}
+	return ratelimit.NewLimiter(store, ratelimit.Config{
+		Submit:     ratelimit.Limit{Rate: config.SubmitPerMinute / 60, Burst: config.SubmitBurst},
+		Read:       ratelimit.Limit{Rate: config.ReadPerMinute / 60, Burst: config.ReadBurst},
+		Client:     ratelimit.Limit{Rate: config.ClientPerMinute / 60, Burst: config.ClientBurst},
+		DailyLines: config.DailyLines,
+		DailyBytes: config.DailyBytes,
+	})
+}
+
+// newTransferService creates the transfer service with the retry configuration and the chunk size of config,
+// policy may be nil for trusted callers
+func newTransferService(db *sql.DB, logger *slog.Logger, config *config.Config, accountRepo account.Repository, transferRepo transfer.Repository, batchRepo batch.Repository, eventRepo event.Repository, auditRepo audit.Repository, broker *event.Broker, policy authz.Authorizer) service.TransferService {
+	retryConfig := service.RetryConfig{
+		BaseDelay:  config.RetryConfig.BaseDelay,
+		MaxDelay:   config.RetryConfig.MaxDelay,
+		MaxRetries: config.RetryConfig.MaxRetries,
+	}
+	return service.NewTransferService(db, logger, accountRepo, transferRepo, batchRepo, eventRepo, auditRepo, broker, retryConfig, config.BulkChunkSize, policy)
+}
+
+// newTokenVerifier creates the verifier of the bearer tokens of the identity provider of config
+func newTokenVerifier(config config.OIDCConfig) *oidc.Verifier {
+	var keys *oidc.KeySet
+	if config.JWKSFile != "" {
+		keys = oidc.NewFileKeySet(config.JWKSFile, config.JWKSRefreshInterval)
+	} else {
+		keys = oidc.NewRemoteKeySet(config.JWKSURL, &http.Client{Timeout: jwksTimeout}, config.JWKSRefreshInterval)
+	}
+	return oidc.NewVerifier(keys, oidc.Config{
+		Issuer:            config.Issuer,
+		Audience:          config.Audience,
+		OrganizationClaim: config.OrganizationClaim,
+		UserClaim:         config.UserClaim,
+		Leeway:            config.Leeway,
+	})
+}
+
+// newTLSOptions returns the options serving the rest api over the TLS of config
+func newTLSOptions(config config.TLSConfig, logger *slog.Logger) ([]rest.Option, error) {
+	tlsConfig, err := tlsconfig.NewServerConfig(tlsconfig.Config{
+		CertFile:           config.CertFile,
+		KeyFile:            config.KeyFile,
+		MinVersion:         config.MinVersion,
+		CipherSuites:       config.CipherSuites,
+		ClientCAFile:       config.ClientCAFile,
+		ClientCertRequired: config.ClientCertRequired,
+		ReloadInterval:     config.ReloadInterval,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+	opts := []rest.Option{rest.WithTLSConfig(tlsConfig)}
+	if config.ClientCAFile != "" {
+		mapper, err := tlsconfig.NewSubjectMapper(config.ClientOrganizationField)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, rest.WithCertificateMapper(mapper))
+	}
+	return opts, nil
+}
+
 func init() {
 	rootCmd.AddCommand(restCmd)
 }
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/infra"
+	"moneytransfer/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// roleCmd groups the commands managing the roles granted to the callers of organizations
+var roleCmd = &cobra.Command{
+	Use:   "role",
+	Short: "Manage the roles granted to the callers of organizations",
+	Long: `These commands grant and revoke the roles of the callers of organizations.
+A role is granted to a subject, the identity of a caller: api_key:<id> for an API
+key, oidc:<sub> for a token of the identity provider and mtls:<DN> for a client
+certificate. It applies to all the accounts of the organization, or to a single
+account with --iban.
+
+  viewer     reads batches, statements, exports and events
+  submitter  also submits bulk transfers
+  approver   also returns transfers
+  admin      also manages webhooks and reads the audit log
+
+Callers without any grant in their organization have AUTHORIZATION_DEFAULT_ROLE.`,
+}
+
+// roleGrantCmd represents the role grant command
+var roleGrantCmd = &cobra.Command{
+	Use:   "grant",
+	Short: "Grant a role to a subject of an organization",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		organization, _ := cmd.Flags().GetString("organization")
+		subject, _ := cmd.Flags().GetString("subject")
+		role, _ := cmd.Flags().GetString("role")
+		iban, _ := cmd.Flags().GetString("iban")
+
+		svc, logger := newRoleService()
+		g, err := svc.Grant(context.Background(), organization, subject, authz.Role(role), iban)
+		if err != nil {
+			logger.Error("failed to grant role", slog.Any("error", err), slog.String("organization", organization), slog.String("subject", subject))
+			os.Exit(1)
+		}
+		fmt.Printf("Grant %d: %s is %s of %s\n", g.ID, g.Subject, g.Role, grantScope(*g))
+	},
+}
+
+// roleRevokeCmd represents the role revoke command
+var roleRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke a grant at once",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, logger := newRoleService()
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || id <= 0 {
+			logger.Error("invalid grant id", slog.String("id", args[0]))
+			os.Exit(1)
+		}
+		if err := svc.Revoke(context.Background(), id); err != nil {
+			logger.Error("failed to revoke grant", slog.Any("error", err), slog.Int64("grant_id", id))
+			os.Exit(1)
+		}
+		fmt.Printf("Grant %d revoked\n", id)
+	},
+}
+
+// roleListCmd represents the role list command
+var roleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the roles granted in an organization",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		organization, _ := cmd.Flags().GetString("organization")
+
+		svc, logger := newRoleService()
+		grants, err := svc.List(context.Background(), organization)
+		if err != nil {
+			logger.Error("failed to list grants", slog.Any("error", err), slog.String("organization", organization))
+			os.Exit(1)
+		}
+
+		w := bufio.NewWriter(os.Stdout)
+		writeGrants(w, grants)
+		if err := w.Flush(); err != nil {
+			logger.Error("failed to write grants", slog.Any("error", err))
+			os.Exit(1)
+		}
+	},
+}
+
+// newRoleService creates the role service of the configured database
+// and a logger writing to standard error, standard output holds the grants
+func newRoleService() (service.RoleService, *slog.Logger) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		os.Exit(1)
+	}
+	logger := newLogger(os.Stderr, config)
+
+	db, err := infra.NewDatabase(config.DatabaseURL, logger)
+	if err != nil {
+		logger.Error("failed to create new database", slog.Any("error", err))
+		os.Exit(1)
+	}
+	return service.NewRoleService(authz.NewPostgresRepository(db), audit.NewPostgresRepository(db), logger), logger
+}
+
+// grantScope describes what a grant applies to
+func grantScope(g authz.Grant) string {
+	if g.AccountIBAN == "" {
+		return "organization " + g.OrganizationName
+	}
+	return "account " + g.AccountIBAN
+}
+
+// writeGrants writes a table of grants
+func writeGrants(w io.Writer, grants []authz.Grant) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSUBJECT\tROLE\tACCOUNT\tCREATED")
+	for _, g := range grants {
+		iban := g.AccountIBAN
+		if iban == "" {
+			iban = "*"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", g.ID, g.Subject, g.Role, iban, g.CreatedAt.Format(time.RFC3339))
+	}
+	tw.Flush()
+}
+
+func init() {
+	roleGrantCmd.Flags().String("organization", "", "Name of the organization")
+	roleGrantCmd.Flags().String("subject", "", "Subject the role is granted to, such as api_key:<id>")
+	roleGrantCmd.Flags().String("role", "", "Role granted: viewer, submitter, approver or admin")
+	roleGrantCmd.Flags().String("iban", "", "IBAN of the single account the role applies to")
+	roleGrantCmd.MarkFlagRequired("organization")
+	roleGrantCmd.MarkFlagRequired("subject")
+	roleGrantCmd.MarkFlagRequired("role")
+	roleListCmd.Flags().String("organization", "", "Name of the organization")
+	roleListCmd.MarkFlagRequired("organization")
+
+	roleCmd.AddCommand(roleGrantCmd, roleRevokeCmd, roleListCmd)
+	rootCmd.AddCommand(roleCmd)
+}
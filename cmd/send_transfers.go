@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/infra"
+	"moneytransfer/internal/outbound"
+
+	"github.com/spf13/cobra"
+)
+
+// sendTransfersCmd represents the send-transfers command
+var sendTransfersCmd = &cobra.Command{
+	Use:   "send-transfers",
+	Short: "Write the unsent executed transfers to pain.001 files for the sending bank",
+	Long: `This command writes the executed transfers that were not sent yet to ISO 20022
+pain.001 files in the output directory, one file per debtor account with one payment
+information block per execution date, and marks them as sent. A transfer is never
+written to two files. The paths of the files are printed to standard output.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load the configuration
+		config, err := config.LoadConfig()
+		if err != nil {
+			os.Exit(1)
+		}
+
+		// Logs go to standard error, standard output holds the paths of the files
+		logger := newLogger(os.Stderr, config)
+
+		dir := config.Outbound.Dir
+		if flag, _ := cmd.Flags().GetString("dir"); flag != "" {
+			dir = flag
+		}
+		if dir == "" {
+			logger.Error("no output directory, set --dir or OUTBOUND_DIR")
+			os.Exit(1)
+		}
+
+		// Create DB instance
+		db, err := infra.NewDatabase(config.DatabaseURL, logger)
+		if err != nil {
+			logger.Error("failed to create new database", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		job := outbound.NewJob(db, account.NewPostgresRepository(db), newTransferRepository(db, config, logger), logger, outbound.JobConfig{
+			Dir:       dir,
+			Interval:  config.Outbound.Interval,
+			BatchSize: config.Outbound.BatchSize,
+		})
+		paths, err := job.RunOnce(context.Background())
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+		if err != nil {
+			logger.Error("failed to send transfers", slog.Any("error", err))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	sendTransfersCmd.Flags().String("dir", "", "Directory the files are written to, OUTBOUND_DIR if not set")
+	rootCmd.AddCommand(sendTransfersCmd)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/infra"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/signing"
+
+	"github.com/spf13/cobra"
+)
+
+// signingKeyCmd groups the commands managing the signing keys of organizations
+var signingKeyCmd = &cobra.Command{
+	Use:   "signing-key",
+	Short: "Manage the keys organizations sign their bulk uploads with",
+	Long: `These commands issue and revoke the signing keys of organizations. A signing key
+is a secret shared with the organization, which signs its bulk uploads with an
+HMAC-SHA256 keyed with it. Signatures are verified when present and required when
+REQUEST_SIGNATURE_REQUIRED is set. Unlike API keys, the secrets are stored, as the
+server needs them to verify the signatures.`,
+}
+
+// signingKeyCreateCmd represents the signing-key create command
+var signingKeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Issue a signing key for an organization",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		organization, _ := cmd.Flags().GetString("organization")
+
+		svc, logger := newSigningService()
+		k, err := svc.CreateKey(context.Background(), organization)
+		if err != nil {
+			logger.Error("failed to create signing key", slog.Any("error", err), slog.String("organization", organization))
+			os.Exit(1)
+		}
+		fmt.Printf("ID:            %d\n", k.ID)
+		fmt.Printf("Organization:  %s\n", k.OrganizationName)
+		fmt.Printf("Secret:        %s\n", k.Secret)
+	},
+}
+
+// signingKeyRevokeCmd represents the signing-key revoke command
+var signingKeyRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke a signing key at once",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, logger := newSigningService()
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || id <= 0 {
+			logger.Error("invalid signing key id", slog.String("id", args[0]))
+			os.Exit(1)
+		}
+		if err := svc.RevokeKey(context.Background(), id); err != nil {
+			logger.Error("failed to revoke signing key", slog.Any("error", err), slog.Int64("signing_key_id", id))
+			os.Exit(1)
+		}
+		fmt.Printf("Signing key %d revoked\n", id)
+	},
+}
+
+// signingKeyListCmd represents the signing-key list command
+var signingKeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the signing keys of an organization",
+	Long: `This command lists the signing keys of an organization. Their secrets are only
+shown with --show-secrets.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		organization, _ := cmd.Flags().GetString("organization")
+		showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+
+		svc, logger := newSigningService()
+		keys, err := svc.ListKeys(context.Background(), organization)
+		if err != nil {
+			logger.Error("failed to list signing keys", slog.Any("error", err), slog.String("organization", organization))
+			os.Exit(1)
+		}
+
+		w := bufio.NewWriter(os.Stdout)
+		writeSigningKeys(w, keys, showSecrets)
+		if err := w.Flush(); err != nil {
+			logger.Error("failed to write signing keys", slog.Any("error", err))
+			os.Exit(1)
+		}
+	},
+}
+
+// newSigningService creates the signing service of the configured database
+// and a logger writing to standard error, standard output holds the keys
+func newSigningService() (service.SigningService, *slog.Logger) {
+	config, err := config.LoadConfig()
+	if err != nil {
+		os.Exit(1)
+	}
+	logger := newLogger(os.Stderr, config)
+
+	db, err := infra.NewDatabase(config.DatabaseURL, logger)
+	if err != nil {
+		logger.Error("failed to create new database", slog.Any("error", err))
+		os.Exit(1)
+	}
+	return service.NewSigningService(signing.NewPostgresRepository(db), audit.NewPostgresRepository(db), config.RequestSigning.Window, logger), logger
+}
+
+// writeSigningKeys writes a table of signing keys, with their secrets if showSecrets is set
+func writeSigningKeys(w io.Writer, keys []signing.Key, showSecrets bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if showSecrets {
+		fmt.Fprintln(tw, "ID\tSTATE\tCREATED\tSECRET")
+	} else {
+		fmt.Fprintln(tw, "ID\tSTATE\tCREATED")
+	}
+	for _, k := range keys {
+		state := "active"
+		if !k.RevokedAt.IsZero() {
+			state = "revoked " + k.RevokedAt.Format(time.RFC3339)
+		}
+		if showSecrets {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", k.ID, state, k.CreatedAt.Format(time.RFC3339), k.Secret)
+		} else {
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", k.ID, state, k.CreatedAt.Format(time.RFC3339))
+		}
+	}
+	tw.Flush()
+}
+
+func init() {
+	signingKeyCreateCmd.Flags().String("organization", "", "Name of the organization the key is issued for")
+	signingKeyCreateCmd.MarkFlagRequired("organization")
+	signingKeyListCmd.Flags().String("organization", "", "Name of the organization")
+	signingKeyListCmd.Flags().Bool("show-secrets", false, "Show the secrets of the keys")
+	signingKeyListCmd.MarkFlagRequired("organization")
+
+	signingKeyCmd.AddCommand(signingKeyCreateCmd, signingKeyRevokeCmd, signingKeyListCmd)
+	rootCmd.AddCommand(signingKeyCmd)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/infra"
+	"moneytransfer/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// statementCmd represents the statement command
+var statementCmd = &cobra.Command{
+	Use:   "statement",
+	Short: "Generate the camt.053 or MT940 statement of an account",
+	Long: `This command writes the ISO 20022 camt.053 bank to customer statement of an
+account for the days from --from to --to included, in UTC, to standard output or
+to a file. The statement gives the opening and closing booked balances of the period
+and one entry per transfer debited and per returned transfer credited within it.
+With --format mt940, the statement is written as a SWIFT MT940 customer statement.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load the configuration
+		config, err := config.LoadConfig()
+		if err != nil {
+			os.Exit(1)
+		}
+
+		// Logs go to standard error, standard output may hold the statement
+		logger := newLogger(os.Stderr, config)
+
+		iban, _ := cmd.Flags().GetString("iban")
+		fromFlag, _ := cmd.Flags().GetString("from")
+		from, err := time.Parse("2006-01-02", fromFlag)
+		if err != nil {
+			logger.Error("invalid from date, expected YYYY-MM-DD", slog.String("from", fromFlag))
+			os.Exit(1)
+		}
+		to := from
+		if toFlag, _ := cmd.Flags().GetString("to"); toFlag != "" {
+			to, err = time.Parse("2006-01-02", toFlag)
+			if err != nil {
+				logger.Error("invalid to date, expected YYYY-MM-DD", slog.String("to", toFlag))
+				os.Exit(1)
+			}
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		if format != "camt053" && format != "mt940" {
+			logger.Error("invalid format, expected camt053 or mt940", slog.String("format", format))
+			os.Exit(1)
+		}
+
+		// Create DB instance
+		db, err := infra.NewDatabase(config.DatabaseURL, logger)
+		if err != nil {
+			logger.Error("failed to create new database", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		statementService := service.NewStatementService(db, account.NewPostgresRepository(db), newTransferRepository(db, config, logger), logger, nil)
+
+		out := os.Stdout
+		if output, _ := cmd.Flags().GetString("output"); output != "" {
+			out, err = os.Create(output)
+			if err != nil {
+				logger.Error("failed to create output file", slog.Any("error", err))
+				os.Exit(1)
+			}
+			defer out.Close()
+		}
+		w := bufio.NewWriter(out)
+
+		write := statementService.WriteStatement
+		if format == "mt940" {
+			write = statementService.WriteMT940Statement
+		}
+		err = write(context.Background(), iban, from, to, w)
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			logger.Error("failed to write statement", slog.Any("error", err), slog.String("iban", iban))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	statementCmd.Flags().String("iban", "", "IBAN of the account")
+	statementCmd.Flags().String("from", "", "First day of the period (YYYY-MM-DD)")
+	statementCmd.Flags().String("to", "", "Last day of the period (YYYY-MM-DD), defaults to --from")
+	statementCmd.Flags().String("format", "camt053", "Format of the statement: camt053 or mt940")
+	statementCmd.Flags().StringP("output", "o", "", "File the statement is written to, standard output if not set")
+	statementCmd.MarkFlagRequired("iban")
+	statementCmd.MarkFlagRequired("from")
+	rootCmd.AddCommand(statementCmd)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/infra"
+	"moneytransfer/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// statusReportCmd represents the status-report command
+var statusReportCmd = &cobra.Command{
+	Use:   "status-report <batch-id>",
+	Short: "Generate the pain.002 payment status report of a batch",
+	Long: `This command writes the ISO 20022 pain.002 customer payment status report
+of the batch created by a bulk transfer request, to standard output or to a file.
+The report references the original pain.001 message and the end-to-end IDs of its
+transactions, and gives their ACCP, PART, RJCT or PDNG statuses with reason codes.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load the configuration
+		config, err := config.LoadConfig()
+		if err != nil {
+			os.Exit(1)
+		}
+
+		// Logs go to standard error, standard output may hold the report
+		logger := newLogger(os.Stderr, config)
+
+		batchID, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || batchID <= 0 {
+			logger.Error("invalid batch ID", slog.String("batch_id", args[0]))
+			os.Exit(1)
+		}
+
+		// Create DB instance
+		db, err := infra.NewDatabase(config.DatabaseURL, logger)
+		if err != nil {
+			logger.Error("failed to create new database", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		reportService := service.NewStatusReportService(account.NewPostgresRepository(db), batch.NewPostgresRepository(db), newTransferRepository(db, config, logger), logger, nil)
+
+		out := os.Stdout
+		if output, _ := cmd.Flags().GetString("output"); output != "" {
+			out, err = os.Create(output)
+			if err != nil {
+				logger.Error("failed to create output file", slog.Any("error", err))
+				os.Exit(1)
+			}
+			defer out.Close()
+		}
+		w := bufio.NewWriter(out)
+
+		err = reportService.WriteStatusReport(context.Background(), batchID, w)
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			logger.Error("failed to write status report", slog.Any("error", err), slog.Int64("batch_id", batchID))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	statusReportCmd.Flags().StringP("output", "o", "", "File the report is written to, standard output if not set")
+	rootCmd.AddCommand(statusReportCmd)
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// transferCmd groups the commands operating on transfers
+var transferCmd = &cobra.Command{
+	Use:   "transfer",
+	Short: "Operate on transfers without going through the REST API",
+}
+
+func init() {
+	rootCmd.AddCommand(transferCmd)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/api/rest"
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/event"
+	"moneytransfer/internal/infra"
+
+	"github.com/spf13/cobra"
+)
+
+// transferReturnCmd represents the transfer return command
+var transferReturnCmd = &cobra.Command{
+	Use:   "return <transfer-id>",
+	Short: "Record the return of an executed transfer by the receiving bank",
+	Long: `This command records that the receiving bank returned an executed transfer,
+as reported to the operators by the sending bank. The transfer is marked as
+returned with the reason given by the bank, its amount is credited back to the
+debtor account, and a transfer.returned event is recorded and delivered to the
+webhooks of the organization.
+
+Returns move money back to the customer, so they are only recorded by operators
+and never through the REST API. The command asks for a confirmation on standard
+input unless --yes is set.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load the configuration
+		config, err := config.LoadConfig()
+		if err != nil {
+			os.Exit(1)
+		}
+
+		// Logs go to standard error, standard output holds the returned transfer
+		logger := newLogger(os.Stderr, config)
+
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || id <= 0 {
+			logger.Error("invalid transfer ID", slog.String("transfer_id", args[0]))
+			os.Exit(1)
+		}
+		reason, _ := cmd.Flags().GetString("reason")
+		if reason = strings.TrimSpace(reason); reason == "" {
+			logger.Error("the return reason must not be empty")
+			os.Exit(1)
+		}
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		out := bufio.NewWriter(os.Stdout)
+		defer out.Flush()
+
+		if !yes {
+			fmt.Fprintf(out, "Return transfer %d with reason %s and credit its amount back? [y/N] ", id, reason)
+			out.Flush()
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				fmt.Fprintln(out, "Aborted, the transfer was not returned")
+				out.Flush()
+				os.Exit(1)
+			}
+		}
+
+		// Create DB instance
+		db, err := infra.NewDatabase(config.DatabaseURL, logger)
+		if err != nil {
+			logger.Error("failed to create new database", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		// Events are picked up by the webhook worker and the event streams of the servers, which poll the events table
+		transferService := newTransferService(db, logger, config, account.NewPostgresRepository(db), newTransferRepository(db, config, logger),
+			batch.NewPostgresRepository(db), event.NewPostgresRepository(db), audit.NewPostgresRepository(db), nil, nil)
+
+		t, err := transferService.ReturnTransfer(context.Background(), id, reason)
+		if err != nil {
+			problem := rest.ServiceProblem(err, err.Error())
+			logger.Error("transfer return rejected", slog.Any("error", err), slog.String("code", problem.Code))
+			writeProblem(out, problem)
+			out.Flush()
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(out, "Transfer %d returned: %s credited back to bank account %d\n", t.ID, formatCents(t.AmountCents), t.BankAccountID)
+	},
+}
+
+func init() {
+	transferReturnCmd.Flags().String("reason", "", "Return reason given by the receiving bank, e.g. a return reason code such as AC04")
+	transferReturnCmd.Flags().Bool("yes", false, "Return the transfer without asking for a confirmation")
+	transferReturnCmd.MarkFlagRequired("reason")
+	transferCmd.AddCommand(transferReturnCmd)
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/api/rest"
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/event"
+	"moneytransfer/internal/infra"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/transfer"
+
+	"github.com/spf13/cobra"
+)
+
+// submitPageSize is the number of executed transfers read at once to print their outcome
+const submitPageSize = 500
+
+// transferSubmitCmd represents the transfer submit command
+var transferSubmitCmd = &cobra.Command{
+	Use:   "submit",
+	Short: "Execute a bulk transfer file in-process",
+	Long: `This command executes a bulk transfer file directly against the database, for
+operations and backfills. The file is validated and turned into a bulk transfer
+request exactly as POST /api/v1/transfers does, in any of its formats, and the
+request is executed by the transfer service within the command.
+
+With --dry-run the file is validated, every transfer is checked against the debtor
+account and its balance, and nothing is written. Otherwise the command asks for a
+confirmation on standard input unless --yes is set. The outcome of every credit
+transfer line is printed to standard output, and the command exits with a non-zero
+status when the bulk transfer is rejected.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load the configuration
+		config, err := config.LoadConfig()
+		if err != nil {
+			os.Exit(1)
+		}
+
+		// Logs go to standard error, standard output holds the outcome of the transfers
+		logger := newLogger(os.Stderr, config)
+
+		name, _ := cmd.Flags().GetString("file")
+		mediaType, _ := cmd.Flags().GetString("media-type")
+		if mediaType == "" {
+			mediaType = rest.FileMediaType(name)
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		file, err := os.Open(name)
+		if err != nil {
+			logger.Error("failed to open file", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		out := bufio.NewWriter(os.Stdout)
+		defer out.Flush()
+
+		request, transferCount, err := rest.NewBulkTransferRequest(file, mediaType)
+		if err != nil {
+			writeProblem(out, problemOf(err, "Invalid bulk transfer file"))
+			out.Flush()
+			os.Exit(1)
+		}
+
+		// Create DB instance
+		db, err := infra.NewDatabase(config.DatabaseURL, logger)
+		if err != nil {
+			logger.Error("failed to create new database", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		accountRepo := account.NewPostgresRepository(db)
+		transferRepo := newTransferRepository(db, config, logger)
+
+		if dryRun {
+			if !dryRunBulkTransfer(out, accountRepo, request, transferCount) {
+				out.Flush()
+				os.Exit(1)
+			}
+			return
+		}
+
+		if !yes {
+			fmt.Fprintf(out, "Execute %d transfers from %s (%s)? [y/N] ", transferCount, request.OrganizationIBAN, request.OrganizationName)
+			out.Flush()
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				fmt.Fprintln(out, "Aborted, no transfer was executed")
+				out.Flush()
+				os.Exit(1)
+			}
+		}
+
+		// Events are picked up by the webhook worker and the event streams of the servers, which poll the events table
+		transferService := newTransferService(db, logger, config, accountRepo, transferRepo, batch.NewPostgresRepository(db), event.NewPostgresRepository(db), audit.NewPostgresRepository(db), nil, nil)
+
+		ctx := context.Background()
+		b, err := transferService.BulkTransfer(ctx, request)
+		if err != nil {
+			problem := rest.ServiceProblem(err, err.Error())
+			if b != nil {
+				problem.With("batch_id", b.ID)
+			}
+			logger.Error("bulk transfer rejected", slog.Any("error", err), slog.String("code", problem.Code))
+			writeProblem(out, problem)
+			fmt.Fprintln(out, "No transfer was executed")
+			out.Flush()
+			os.Exit(1)
+		}
+
+		if err := writeExecutedTransfers(ctx, out, transferRepo, b); err != nil {
+			logger.Error("failed to list the executed transfers", slog.Any("error", err), slog.Int64("batch_id", b.ID))
+			out.Flush()
+			os.Exit(1)
+		}
+	},
+}
+
+// problemOf returns the problem reported by err, or an internal error problem with detail
+func problemOf(err error, detail string) *rest.Problem {
+	var problem *rest.Problem
+	if errors.As(err, &problem) {
+		return problem
+	}
+	return rest.ServiceProblem(err, detail+": "+err.Error())
+}
+
+// writeProblem writes a problem with the position in the file and the batch it concerns, if any
+func writeProblem(w io.Writer, p *rest.Problem) {
+	row, _ := p.Extensions["row"].(int)
+	column, _ := p.Extensions["column"].(string)
+	line, _ := p.Extensions["line"].(int)
+	switch {
+	case row != 0 && column != "":
+		fmt.Fprintf(w, "row %d, column %s: ", row, column)
+	case row != 0:
+		fmt.Fprintf(w, "row %d: ", row)
+	case line != 0:
+		fmt.Fprintf(w, "line %d: ", line)
+	}
+	fmt.Fprintf(w, "%s: %s\n", p.Code, p.Detail)
+	if batchID, ok := p.Extensions["batch_id"].(int64); ok {
+		fmt.Fprintf(w, "Batch %d failed\n", batchID)
+	}
+}
+
+// dryRunBulkTransfer checks the transfers of the request against the debtor account like the
+// transfer service does, without writing anything, and reports whether they would be executed
+func dryRunBulkTransfer(w io.Writer, accountRepo account.Repository, request service.BulkTransferRequest, transferCount int) bool {
+	acc, err := accountRepo.GetByIBAN(request.OrganizationIBAN, nil)
+	if err != nil {
+		if errors.Is(err, account.ErrNotFound) {
+			err = fmt.Errorf("%w: %s", service.ErrAccountNotFound, request.OrganizationIBAN)
+		}
+		writeProblem(w, problemOf(err, "Failed to get the debtor account"))
+		return false
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tAMOUNT\tCOUNTERPARTY\tIBAN\tBIC\tOUTCOME")
+	valid := true
+	var line int
+	var total int64
+	err = request.Source(func(t transfer.Transfer) error {
+		line++
+		total += t.AmountCents
+		outcome := "valid"
+		t.BankAccountID = acc.ID
+		if err := t.Validate(); err != nil {
+			valid = false
+			outcome = "invalid: " + err.Error()
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", line, formatCents(t.AmountCents), t.CounterpartyName, t.CounterpartyIBAN, t.CounterpartyBIC, outcome)
+		return nil
+	})
+	tw.Flush()
+	if err != nil {
+		writeProblem(w, problemOf(err, "Failed to read the bulk transfer file"))
+		return false
+	}
+
+	fmt.Fprintf(w, "Dry run: %d transfers, %s in total, from %s with a balance of %s\n",
+		transferCount, formatCents(total), acc.IBAN, formatCents(acc.BalanceCents))
+	if acc.BalanceCents < total {
+		writeProblem(w, problemOf(&service.InsufficientFundsError{RequiredCents: total, AvailableCents: acc.BalanceCents}, ""))
+		valid = false
+	}
+	if valid {
+		fmt.Fprintln(w, "The bulk transfer would be executed")
+	}
+	return valid
+}
+
+// writeExecutedTransfers writes the outcome of every transfer of an executed batch, in the order of the file
+func writeExecutedTransfers(ctx context.Context, w io.Writer, transferRepo transfer.Repository, b *batch.Batch) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tAMOUNT\tCOUNTERPARTY\tIBAN\tBIC\tOUTCOME")
+	var line int
+	var afterID int64
+	for {
+		transfers, err := transferRepo.ListByBatch(ctx, b.ID, afterID, submitPageSize)
+		if err != nil {
+			return err
+		}
+		for _, t := range transfers {
+			line++
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\texecuted as transfer %d\n", line, formatCents(t.AmountCents), t.CounterpartyName, t.CounterpartyIBAN, t.CounterpartyBIC, t.ID)
+			afterID = t.ID
+		}
+		if len(transfers) < submitPageSize {
+			break
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Batch %d executed: %d transfers, %s in total\n", b.ID, b.TransferCount, formatCents(b.TotalCents))
+	return err
+}
+
+// formatCents formats an amount in cents as a decimal number of euros
+func formatCents(cents int64) string {
+	return fmt.Sprintf("%d.%02d EUR", cents/100, cents%100)
+}
+
+func init() {
+	transferSubmitCmd.Flags().String("file", "", "Bulk transfer file to execute")
+	transferSubmitCmd.Flags().String("media-type", "", "Media type of the file, derived from its extension if not set")
+	transferSubmitCmd.Flags().Bool("dry-run", false, "Validate the file and check it against the debtor account without executing it")
+	transferSubmitCmd.Flags().Bool("yes", false, "Execute the file without asking for a confirmation")
+	transferSubmitCmd.MarkFlagRequired("file")
+	transferCmd.AddCommand(transferSubmitCmd)
+}
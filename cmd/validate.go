@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"moneytransfer/internal/api/rest"
+
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "Validate a bulk transfer file without executing it",
+	Long: `This command checks a bulk transfer file in any of the formats accepted by
+POST /api/v1/transfers, without connecting to the database. The file is decoded and
+its credit transfers are checked like the API does, amounts included, and the IBANs
+and BICs of the organization and of the counterparties are checked as well. The
+report lists the errors by credit transfer line, with the problem code the API would
+reject them with, and gives the number of transfers and their total in cents.
+The format of the file is derived from its extension unless --media-type is set;
+standard input is read when the file is -. The command exits with a non-zero status
+when the file is invalid.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// The command works offline, it needs none of the configuration of the service
+		logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			logger.Error("invalid format, expected text or json", slog.String("format", format))
+			os.Exit(1)
+		}
+		maxErrors, _ := cmd.Flags().GetInt("max-errors")
+		if maxErrors < 0 {
+			logger.Error("invalid maximum number of errors", slog.Int("max_errors", maxErrors))
+			os.Exit(1)
+		}
+
+		name := args[0]
+		mediaType, _ := cmd.Flags().GetString("media-type")
+		if mediaType == "" {
+			mediaType = rest.FileMediaType(name)
+		}
+
+		in := os.Stdin
+		if name != "-" {
+			f, err := os.Open(name)
+			if err != nil {
+				logger.Error("failed to open file", slog.Any("error", err))
+				os.Exit(1)
+			}
+			defer f.Close()
+			in = f
+		}
+
+		report, err := rest.ValidateBulkTransferFile(bufio.NewReader(in), mediaType, maxErrors)
+		if err != nil {
+			logger.Error("failed to validate file", slog.Any("error", err), slog.String("file", name))
+			os.Exit(1)
+		}
+
+		w := bufio.NewWriter(os.Stdout)
+		if format == "json" {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(report)
+		} else {
+			writeValidationReport(w, name, report)
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			logger.Error("failed to write report", slog.Any("error", err))
+			os.Exit(1)
+		}
+		if !report.Valid {
+			os.Exit(1)
+		}
+	},
+}
+
+// writeValidationReport writes the human-readable report of the validation of a bulk transfer file
+func writeValidationReport(w io.Writer, name string, report *rest.BulkTransferFileReport) {
+	result := "valid"
+	if !report.Valid {
+		result = "invalid"
+	}
+	fmt.Fprintf(w, "%s: %s\n", name, result)
+	fmt.Fprintf(w, "Media type:    %s\n", report.MediaType)
+	if report.OrganizationName != "" || report.OrganizationIBAN != "" {
+		fmt.Fprintf(w, "Organization:  %s, BIC %s, IBAN %s\n", report.OrganizationName, report.OrganizationBIC, report.OrganizationIBAN)
+	}
+	if report.MessageID != "" {
+		fmt.Fprintf(w, "Message ID:    %s\n", report.MessageID)
+	}
+	fmt.Fprintf(w, "Transfers:     %d\n", report.Transfers)
+	fmt.Fprintf(w, "Total:         %d cents\n", report.TotalCents)
+	fmt.Fprintf(w, "Errors:        %d\n", report.ErrorCount)
+
+	for _, e := range report.Errors {
+		switch {
+		case e.Row != 0 && e.Column != "":
+			fmt.Fprintf(w, "  row %d, column %s: ", e.Row, e.Column)
+		case e.Row != 0:
+			fmt.Fprintf(w, "  row %d: ", e.Row)
+		case e.Line != 0:
+			fmt.Fprintf(w, "  line %d: ", e.Line)
+		default:
+			fmt.Fprint(w, "  ")
+		}
+		fmt.Fprintf(w, "%s: %s\n", e.Code, e.Detail)
+		for _, v := range e.Violations {
+			path := v.InstancePath
+			if path == "" {
+				path = "/"
+			}
+			fmt.Fprintf(w, "    %s: %s (schema %s)\n", path, v.Message, v.SchemaPath)
+		}
+	}
+	if omitted := report.ErrorCount - len(report.Errors); omitted > 0 {
+		fmt.Fprintf(w, "  ... %d more errors\n", omitted)
+	}
+}
+
+func init() {
+	validateCmd.Flags().String("format", "text", "Format of the report: text or json")
+	validateCmd.Flags().String("media-type", "", "Media type of the file, derived from its extension if not set")
+	validateCmd.Flags().Int("max-errors", 100, "Maximum number of errors listed in the report, all if 0")
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"moneytransfer/config"
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/infra"
+	"moneytransfer/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// verifyChainCmd represents the verify-chain command
+var verifyChainCmd = &cobra.Command{
+	Use:   "verify-chain",
+	Short: "Verify the hash chains of the transfers of accounts",
+	Long: `This command recomputes the SHA-256 hash chain of the debits and credits of an account,
+or of every account with transfers when --iban is not set, and prints for each account the
+number of links verified, the hash of the last one and the first broken link. A transfer,
+a link or a balance edited in the database outside of the service breaks the chain.
+The command exits with status 1 when a chain is broken.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		iban, _ := cmd.Flags().GetString("iban")
+
+		// Load the configuration
+		config, err := config.LoadConfig()
+		if err != nil {
+			os.Exit(1)
+		}
+
+		// Logs go to standard error, standard output holds the reports
+		logger := newLogger(os.Stderr, config)
+
+		// Create DB instance
+		db, err := infra.NewDatabase(config.DatabaseURL, logger)
+		if err != nil {
+			logger.Error("failed to create new database", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		chainService := service.NewChainService(db, account.NewPostgresRepository(db), newTransferRepository(db, config, logger), logger, nil)
+
+		var reports []service.ChainReport
+		if iban != "" {
+			report, err := chainService.Verify(context.Background(), iban)
+			if err != nil {
+				logger.Error("failed to verify hash chain", slog.Any("error", err), slog.String("iban", iban))
+				os.Exit(1)
+			}
+			reports = append(reports, *report)
+		} else if reports, err = chainService.VerifyAll(context.Background()); err != nil {
+			logger.Error("failed to verify hash chains", slog.Any("error", err))
+			os.Exit(1)
+		}
+
+		w := bufio.NewWriter(os.Stdout)
+		writeChainReports(w, reports)
+		if err := w.Flush(); err != nil {
+			logger.Error("failed to write reports", slog.Any("error", err))
+			os.Exit(1)
+		}
+		for _, r := range reports {
+			if r.Broken != nil {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// writeChainReports writes a table of the verifications of hash chains
+func writeChainReports(w io.Writer, reports []service.ChainReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "IBAN\tLINKS\tHEAD\tSTATUS")
+	for _, r := range reports {
+		status := "ok"
+		if b := r.Broken; b != nil && b.Seq == 0 {
+			status = fmt.Sprintf("broken at transfer %d: %s, %s", b.TransferID, b.Reason, b.Detail)
+		} else if b != nil {
+			status = fmt.Sprintf("broken at link %d (transfer %d): %s, %s", b.Seq, b.TransferID, b.Reason, b.Detail)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", r.IBAN, r.Links, orDash(r.Head), status)
+	}
+	tw.Flush()
+}
+
+func init() {
+	verifyChainCmd.Flags().String("iban", "", "IBAN of the account, all the accounts with a chain if not set")
+	rootCmd.AddCommand(verifyChainCmd)
+}
@@ -24,74 +24,1190 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/audit-events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the audit events of an organization, newest first: the bulk transfers, returns and\nadministrative actions with their actor, source and outcome. The organization defaults to the\norganization of the caller. Older pages are read by passing the smallest ID received as before_id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "List audit events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization name",
+                        "name": "organization",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subject of the actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IBAN of the account acted on",
+                        "name": "iban",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the time range, included (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the time range, excluded (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only events older than this event",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of events (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rest.AuditEventResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/batches/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the state of the batch created by a bulk transfer request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfers"
+                ],
+                "summary": "Get a batch",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/batches/{id}/status-report": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the ISO 20022 pain.002 customer payment status report of the batch created by a bulk transfer request.\nThe report references the original pain.001 message and the end-to-end IDs of its transactions.\nThe group status is PDNG while the batch is received, RJCT with a reason code once it failed,\nand ACCP once it is executed, or PART when some of its transfers were returned.\nReturned transfers are reported RJCT with their return reason.\nReports answer pain.001.001.09 messages in version pain.002.001.10, and all other batches in version pain.002.001.03.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "transfers"
+                ],
+                "summary": "Get the payment status report of a batch",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "pain.002 document",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/chain/verification": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Recomputes the SHA-256 hash chain of the debits and credits of the account, which links each\ntransfer and return to the balance it left and to the previous link, and reports the first\nbroken link: a transfer, a link or the balance edited in the database outside of the service.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Verify the hash chain of an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "IBAN of the account",
+                        "name": "iban",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ChainVerificationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streams the status changes of batches and transfers as Server-Sent Events.\nThe stream is scoped to an organization, a bank account or both, at least one is required.\nEvery message carries the event sequence as its id, so a client that reconnects with\nthe Last-Event-ID header resumes after the last event it received.\nComments are sent as heartbeats while there are no events.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Stream status changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization name",
+                        "name": "organization",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization IBAN",
+                        "name": "iban",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume after this event, for clients that cannot send the Last-Event-ID header",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume after this event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of events",
+                        "schema": {
+                            "$ref": "#/definitions/rest.EventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
         "/health": {
             "get": {
-                "description": "This endpoint is used to check the health of the server",
-                "consumes": [
-                    "application/json"
+                "description": "This endpoint is used to check the health of the server",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/problems": {
+            "get": {
+                "description": "Lists the catalog of problem types returned in application/problem+json error responses",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "problems"
+                ],
+                "summary": "List problem types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rest.ProblemType"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/problems/{code}": {
+            "get": {
+                "description": "Describes the problem type identified by a problem type URI",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "problems"
+                ],
+                "summary": "Get a problem type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Problem code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ProblemType"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/schemas/bulk-transfer": {
+            "get": {
+                "description": "Returns the latest version of the JSON Schema of bulk transfer documents uploaded as application/json.\nDocuments declare the version they are written in with their schema_version member.",
+                "produces": [
+                    "application/schema+json"
+                ],
+                "tags": [
+                    "schemas"
+                ],
+                "summary": "Get the bulk transfer schema",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                }
+            }
+        },
+        "/schemas/bulk-transfer/{version}": {
+            "get": {
+                "description": "Returns a version of the JSON Schema of bulk transfer documents uploaded as application/json",
+                "produces": [
+                    "application/schema+json"
+                ],
+                "tags": [
+                    "schemas"
+                ],
+                "summary": "Get a version of the bulk transfer schema",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schema version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown versions carry supported_versions",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/statements": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the ISO 20022 camt.053.001.02 bank to customer statement of the account for the days from ` + "`" + `from` + "`" + ` to ` + "`" + `to` + "`" + ` included, in UTC.\nThe statement gives the opening and closing booked balances of the period, agreeing with the current balance of the account,\none DBIT entry per transfer debited within it and one CRDT entry per returned transfer credited back within it,\nwith their end-to-end and payment information references, counterparties and remittance information.\nWith ` + "`" + `format=mt940` + "`" + `, the same statement is returned as a SWIFT MT940 customer statement, split into pages at the FIN message length.",
+                "produces": [
+                    "text/xml",
+                    "text/plain"
+                ],
+                "tags": [
+                    "statements"
+                ],
+                "summary": "Get the statement of an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "IBAN of the account",
+                        "name": "iban",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day of the period (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day of the period (YYYY-MM-DD), defaults to from",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "camt053",
+                            "mt940"
+                        ],
+                        "type": "string",
+                        "default": "camt053",
+                        "description": "Format of the statement",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "camt.053 document or MT940 messages",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Transfer money from one account to multiple accounts.\nThe bulk transfer document is either uploaded as a file in a multipart form\nor sent directly as the request body with its media type as Content-Type.\nUploaded files are read as JSON unless their part Content-Type or their extension names another supported format.\nCSV documents start with a header row naming the columns organization_name, organization_bic, organization_iban,\namount, counterparty_name, counterparty_bic, counterparty_iban and description. The delimiter (comma, semicolon, tab or pipe)\nis detected from the header row and a UTF-8 byte order mark is skipped. CSV errors carry the row and column.\nAn optional end_to_end_id column sets the end-to-end ID of each credit transfer.\nISO 20022 pain.001.001.03 and pain.001.001.09 messages are accepted as application/xml or text/xml; their payment\ninformation blocks must debit a single EUR account and each transaction keeps its EndToEndId.\nSWIFT MT101 messages are accepted as application/vnd.swift.mt101 or as .mt101 and .fin files; their fields are\nvalidated at tag level, they must debit a single EUR account and each transaction keeps its :21: reference.\nDocuments are streamed, so the number of credit transfers is only limited by the maximum upload size.\nWhen request signing is enabled, uploads are signed with the X-Signature-Key, X-Signature-Timestamp (Unix seconds),\nX-Signature-Nonce and X-Signature headers. The signature is v1= followed by the hex encoded HMAC-SHA256, keyed with\nthe secret of the signing key, of the lines v1, the method, the path, the timestamp, the nonce and the hex encoded\nSHA-256 of the raw body, joined with newlines. Signatures are accepted within a window around their timestamp and once.\nRequests are rate limited per client address, then submissions per organization or per credential. Accepted uploads\ncount against daily quotas of bytes and of credit transfers, rejected ones do not; limited requests are answered 429\nwith Retry-After and RateLimit headers.\nErrors are returned as application/problem+json, see /problems for the catalog of problem types.",
+                "consumes": [
+                    "multipart/form-data",
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/vnd.swift.mt101"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfers"
+                ],
+                "summary": "Perform a bulk transfer",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "JSON, CSV, pain.001 XML or MT101 file containing bulk transfer details (multipart/form-data only)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "description": "Bulk transfer details (application/json only)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/rest.BulkTransferFileContent"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/rest.BulkTransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials, or a missing or invalid signature when uploads are signed",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The organization IBAN is not an account of the authenticated organization, or the caller may not submit transfers from it",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Insufficient funds problems carry required_cents and available_cents, rejected batches carry batch_id",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit or daily quota of lines or bytes exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streams the transfers of the account created from ` + "`" + `from` + "`" + ` to ` + "`" + `to` + "`" + ` included, in UTC, by creation time,\nas CSV with a header row or as newline delimited JSON. The transfers are read through a server-side cursor\nfrom a single snapshot, so the export is consistent even while bulk transfers are being executed,\nand is never held in memory. The response is gzip compressed when the request accepts the gzip encoding.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "transfers"
+                ],
+                "summary": "Export the transfers of an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "IBAN of the account",
+                        "name": "iban",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Format of the export",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day of the transfers (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day of the transfers (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "executed",
+                            "returned"
+                        ],
+                        "type": "string",
+                        "description": "Status of the transfers",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Batch the transfers were created by",
+                        "name": "batch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IBAN of the counterparty of the transfers",
+                        "name": "counterparty_iban",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV or NDJSON transfers",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the webhook endpoints of an organization, without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook endpoints",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization name",
+                        "name": "organization",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rest.WebhookEndpointResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Registers a URL of the organization to be notified of the given event types. The URL must be an https URL\nwhose host resolves to public addresses only, redirects are not followed.\nDeliveries are signed: the X-Webhook-Signature header is \"v1=\" followed by the hex encoded\nHMAC-SHA256, keyed with the returned secret, of the X-Webhook-Timestamp header, a dot and the body.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register a webhook endpoint",
+                "parameters": [
+                    {
+                        "description": "Endpoint details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.RegisterWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/rest.WebhookEndpointResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stops the deliveries to the endpoint, its pending deliveries become dead",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Disable a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Returns the latest deliveries to the endpoint, newest first, with their attempts",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of deliveries (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Health check endpoint",
                 "responses": {
                     "200": {
-                        "description": "ok",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rest.WebhookDeliveryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
                         }
                     }
                 }
             }
         },
-        "/transfers": {
+        "/webhooks/{id}/deliveries/{delivery_id}/redeliver": {
             "post": {
-                "description": "Transfer money from one account to multiple accounts using a file upload",
-                "consumes": [
-                    "multipart/form-data"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Delivers the event of a delivery to its endpoint again, as a new pending delivery.\nDead deliveries are only delivered again this way.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transfers"
+                    "webhooks"
                 ],
-                "summary": "Perform a bulk transfer",
+                "summary": "Redeliver a webhook",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "JSON file containing bulk transfer details",
-                        "name": "file",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/rest.BulkTransferResponse"
+                            "$ref": "#/definitions/rest.WebhookDeliveryResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/rest.ErrorResponse"
+                            "$ref": "#/definitions/rest.Problem"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
                         "schema": {
-                            "$ref": "#/definitions/rest.ErrorResponse"
+                            "$ref": "#/definitions/rest.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/rest.ErrorResponse"
+                            "$ref": "#/definitions/rest.Problem"
                         }
                     }
                 }
@@ -99,18 +1215,424 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "rest.AuditEventResponse": {
+            "type": "object",
+            "properties": {
+                "account_iban": {
+                    "type": "string"
+                },
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "description": "Actor is the subject of the credentials that performed the action, system for the CLI",
+                    "type": "string"
+                },
+                "actor_organization": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "description": "Before and After are the state the action changed, before and after it",
+                    "type": "object"
+                },
+                "error_code": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "organization_name": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "description": "Outcome is one of success, failure or denied",
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                },
+                "source_ip": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error_code": {
+                    "description": "ErrorCode is the problem code of a failed batch",
+                    "type": "string"
+                },
+                "error_detail": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "description": "MessageID and MessageName identify the pain.001 message the batch was imported from",
+                    "type": "string"
+                },
+                "message_name": {
+                    "type": "string"
+                },
+                "organization_iban": {
+                    "type": "string"
+                },
+                "organization_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is one of received, executed or failed",
+                    "type": "string"
+                },
+                "total_cents": {
+                    "type": "integer"
+                },
+                "transfer_count": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.BrokenLinkResponse": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Reason is one of sequence_gap, previous_hash_mismatch, transfer_missing, transfer_account_mismatch,\nhash_mismatch, balance_mismatch, account_balance_mismatch or unchained_transfer",
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "Seq is the sequence of the link in the chain, 0 for a transfer without a link",
+                    "type": "integer"
+                },
+                "transfer_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "rest.BulkTransferFileContent": {
+            "type": "object",
+            "required": [
+                "credit_transfers",
+                "organization_bic",
+                "organization_iban",
+                "organization_name"
+            ],
+            "properties": {
+                "credit_transfers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rest.CreditTransfer"
+                    }
+                },
+                "message_id": {
+                    "description": "MessageID is the reference of the document given by the organization, it is\nthe original message referenced by the status reports of the batch",
+                    "type": "string"
+                },
+                "organization_bic": {
+                    "type": "string"
+                },
+                "organization_iban": {
+                    "type": "string"
+                },
+                "organization_name": {
+                    "type": "string"
+                },
+                "schema_version": {
+                    "description": "SchemaVersion is the version of the bulk transfer schema the document is written in,\nthe latest when empty. It must precede the credit transfers.",
+                    "type": "string"
+                }
+            }
+        },
         "rest.BulkTransferResponse": {
             "type": "object",
             "properties": {
+                "batch_id": {
+                    "description": "BatchID identifies the batch of the request, its status changes are streamed at /events",
+                    "type": "integer"
+                },
                 "message": {
                     "type": "string"
                 }
             }
         },
-        "rest.ErrorResponse": {
+        "rest.ChainVerificationResponse": {
             "type": "object",
             "properties": {
-                "message": {
+                "broken": {
+                    "$ref": "#/definitions/rest.BrokenLinkResponse"
+                },
+                "head": {
+                    "description": "Head is the hash of the last link verified",
+                    "type": "string"
+                },
+                "iban": {
+                    "type": "string"
+                },
+                "links": {
+                    "description": "Links counts the links verified, up to the broken one",
+                    "type": "integer"
+                },
+                "valid": {
+                    "description": "Valid is set when every link of the chain was verified",
+                    "type": "boolean"
+                }
+            }
+        },
+        "rest.CreditTransfer": {
+            "type": "object",
+            "required": [
+                "amount",
+                "counterparty_bic",
+                "counterparty_iban",
+                "counterparty_name",
+                "description"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "counterparty_bic": {
+                    "type": "string"
+                },
+                "counterparty_iban": {
+                    "type": "string"
+                },
+                "counterparty_name": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "end_to_end_id": {
+                    "description": "EndToEndID is the reference of the transfer passed on to the counterparty",
+                    "type": "string"
+                }
+            }
+        },
+        "rest.EventResponse": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "description": "Data is the state of the batch or transfer after the change",
+                    "type": "object"
+                },
+                "organization_iban": {
+                    "type": "string"
+                },
+                "organization_name": {
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "Seq is the position of the event in the event sequence, sent as the SSE id",
+                    "type": "integer"
+                },
+                "transfer_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "Type is one of batch.received, batch.executed or batch.failed, sent as the SSE event",
+                    "type": "string"
+                }
+            }
+        },
+        "rest.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is the stable, machine-readable identifier of the problem type",
+                    "type": "string"
+                },
+                "detail": {
+                    "description": "Detail is a human-readable explanation specific to this occurrence of the problem",
+                    "type": "string"
+                },
+                "instance": {
+                    "description": "Instance is the path of the request that caused the problem",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "RequestID identifies the request in the server logs",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is the HTTP status code of the response",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Title is a short, human-readable summary of the problem type",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is a URI reference that identifies the problem type",
+                    "type": "string"
+                }
+            }
+        },
+        "rest.ProblemType": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.RegisterWebhookRequest": {
+            "type": "object",
+            "required": [
+                "event_types",
+                "organization_name",
+                "url"
+            ],
+            "properties": {
+                "event_types": {
+                    "description": "EventTypes is a subset of batch.executed, batch.failed and transfer.returned",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "organization_name": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.WebhookAttemptResponse": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "description": "StatusCode is the HTTP status returned by the endpoint, zero if there was no response",
+                    "type": "integer"
+                }
+            }
+        },
+        "rest.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempt_log": {
+                    "description": "AttemptLog lists the attempts of the delivery, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rest.WebhookAttemptResponse"
+                    }
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "endpoint_id": {
+                    "type": "integer"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "redelivery_of": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status is one of pending, succeeded or dead",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.WebhookEndpointResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "organization_name": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret signs the deliveries to the endpoint, it is only returned on registration",
+                    "type": "string"
+                },
+                "url": {
                     "type": "string"
                 }
             }
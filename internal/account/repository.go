@@ -2,8 +2,12 @@ package account
 
 import (
 	"database/sql"
+	"errors"
 )
 
+// ErrNotFound is returned when the requested bank account does not exist
+var ErrNotFound = errors.New("bank account not found")
+
 //go:generate go run go.uber.org/mock/mockgen -source=repository.go -destination=../../mock/account_repository_mock.go -package=mock -mock_names=Repository=AccountRepositoryMock
 type Repository interface {
 	Create(acc *BankAccount, tx *sql.Tx) (*BankAccount, error)
@@ -2,7 +2,6 @@ package account
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 )
 
@@ -62,7 +61,7 @@ func (r *bankAccountPostgresRepository) Get(id int64, tx *sql.Tx) (*BankAccount,
 	err := row.Scan(&model.ID, &model.OrganizationName, &model.BalanceCents, &model.IBAN, &model.BIC)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("bank account not found")
+			return nil, ErrNotFound
 		}
 		return nil, err
 	}
@@ -121,7 +120,7 @@ func (r *bankAccountPostgresRepository) GetByIBAN(iban string, tx *sql.Tx) (*Ban
 	err := row.Scan(&model.ID, &model.OrganizationName, &model.BalanceCents, &model.IBAN, &model.BIC)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("bank account not found")
+			return nil, ErrNotFound
 		}
 		return nil, err
 	}
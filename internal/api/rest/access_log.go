@@ -0,0 +1,41 @@
+package rest
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// logRequests is a middleware that logs every request once it is handled, in place of gin's
+// default logger. The request is logged through the logger of the api so that its path and
+// query parameters, which may name accounts, are redacted like the other records.
+func (api *apiDetails) logRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		query := c.Request.URL.Query()
+		params := make([]any, 0, len(query))
+		for key, values := range query {
+			params = append(params, slog.String(key, strings.Join(values, ",")))
+		}
+
+		level := slog.LevelInfo
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		api.logger.Log(c.Request.Context(), level, "Request handled",
+			"request_id", requestIDFrom(c),
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			slog.Group("query", params...),
+			"status", c.Writer.Status(),
+			"latency", time.Since(start),
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		)
+	}
+}
@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"moneytransfer/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	logger := slog.New(logging.NewRedactingHandler(slog.NewJSONHandler(&buf, nil), logging.DetailPartial))
+	api := &apiDetails{logger: logger}
+	router := gin.New()
+	router.Use(api.logRequests(), requestID())
+	router.GET("/statements", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/statements?iban=FR7630006000011234567890189&from=2024-05-01", nil)
+	req.Header.Set(requestIDHeader, "req-1")
+	router.ServeHTTP(w, req)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "Request handled", record["msg"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "/statements", record["path"])
+	assert.Equal(t, float64(http.StatusNoContent), record["status"])
+	assert.Equal(t, map[string]any{"iban": "FR*********************0189", "from": "2024-05-01"}, record["query"])
+	assert.NotContains(t, buf.String(), "FR7630006000011234567890189")
+}
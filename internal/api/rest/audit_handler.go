@@ -0,0 +1,163 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditEventResponse represents an event of the audit log
+type AuditEventResponse struct {
+	ID         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Actor is the subject of the credentials that performed the action, system for the CLI
+	Actor             string `json:"actor"`
+	ActorOrganization string `json:"actor_organization,omitempty"`
+	User              string `json:"user,omitempty"`
+	SourceIP          string `json:"source_ip,omitempty"`
+	RequestID         string `json:"request_id,omitempty"`
+	Action            string `json:"action"`
+	OrganizationName  string `json:"organization_name,omitempty"`
+	AccountIBAN       string `json:"account_iban,omitempty"`
+	ResourceType      string `json:"resource_type,omitempty"`
+	ResourceID        string `json:"resource_id,omitempty"`
+	// Before and After are the state the action changed, before and after it
+	Before json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After  json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	// Outcome is one of success, failure or denied
+	Outcome   string `json:"outcome"`
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+func newAuditEventResponse(e *audit.Event) AuditEventResponse {
+	return AuditEventResponse{
+		ID:                e.ID,
+		OccurredAt:        e.OccurredAt,
+		Actor:             e.Actor,
+		ActorOrganization: e.ActorOrganization,
+		User:              e.User,
+		SourceIP:          e.SourceIP,
+		RequestID:         e.RequestID,
+		Action:            string(e.Action),
+		OrganizationName:  e.OrganizationName,
+		AccountIBAN:       e.AccountIBAN,
+		ResourceType:      e.ResourceType,
+		ResourceID:        e.ResourceID,
+		Before:            e.Before,
+		After:             e.After,
+		Outcome:           string(e.Outcome),
+		ErrorCode:         e.ErrorCode,
+	}
+}
+
+// auditSource is a middleware that records the client IP and the request ID in the
+// context of the request, for the audit events of the actions it performs
+func auditSource() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := audit.NewContext(c.Request.Context(), audit.Source{IP: c.ClientIP(), RequestID: requestIDFrom(c)})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// ListAuditEvents godoc
+// @Summary List audit events
+// @Description Returns the audit events of an organization, newest first: the bulk transfers, returns and
+// @Description administrative actions with their actor, source and outcome. The organization defaults to the
+// @Description organization of the caller. Older pages are read by passing the smallest ID received as before_id.
+// @Tags audit
+// @Produce json
+// @Param organization query string false "Organization name"
+// @Param actor query string false "Subject of the actor"
+// @Param iban query string false "IBAN of the account acted on"
+// @Param from query string false "Start of the time range, included (RFC 3339)"
+// @Param to query string false "End of the time range, excluded (RFC 3339)"
+// @Param before_id query int false "Only events older than this event"
+// @Param limit query int false "Maximum number of events (default 100, max 1000)"
+// @Success 200 {array} AuditEventResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Router /audit-events [get]
+func (api *apiDetails) ListAuditEvents(c *gin.Context) {
+	logger := api.logger.With("handler", "ListAuditEvents", "request_id", requestIDFrom(c))
+
+	filter, problem := auditFilter(c)
+	if problem != nil {
+		createErrorResponse(c, problem)
+		return
+	}
+
+	events, err := api.audit.List(c.Request.Context(), filter)
+	if err != nil {
+		problem := ServiceProblem(err, "Error listing audit events")
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("Failed to list audit events", "error", err, "organization", filter.OrganizationName)
+		}
+		createErrorResponse(c, problem)
+		return
+	}
+
+	resp := make([]AuditEventResponse, len(events))
+	for i := range events {
+		resp[i] = newAuditEventResponse(&events[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// auditFilter returns the filter of an audit events request, scoped to an organization
+func auditFilter(c *gin.Context) (audit.Filter, *Problem) {
+	filter := audit.Filter{
+		Actor:            c.Query("actor"),
+		OrganizationName: c.Query("organization"),
+		AccountIBAN:      c.Query("iban"),
+		Limit:            audit.DefaultLimit,
+	}
+	if filter.OrganizationName == "" {
+		if p, ok := auth.FromContext(c.Request.Context()); ok {
+			filter.OrganizationName = p.OrganizationName
+		}
+	}
+	// The whole log is only read with the CLI
+	if filter.OrganizationName == "" {
+		return filter, newProblem(problemInvalidRequest, "The organization query parameter is required")
+	}
+	for _, bound := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		s := c.Query(bound.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return filter, newProblem(problemInvalidRequest, "The "+bound.name+" query parameter must be an RFC 3339 time")
+		}
+		*bound.t = t
+	}
+	if s := c.Query("before_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			return filter, newProblem(problemInvalidRequest, "The before_id query parameter must be a positive integer")
+		}
+		filter.BeforeID = id
+	}
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return filter, newProblem(problemInvalidRequest, "The limit must be an integer between 1 and 1000")
+		}
+		filter.Limit = n
+	}
+	return filter, nil
+}
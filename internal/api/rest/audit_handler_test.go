@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/service"
+	"moneytransfer/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestListAuditEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMock          func(*mock.AuditServiceMock)
+		expectedStatusCode int
+		expectedCode       string
+	}{
+		{
+			name:  "Events of the organization of the caller",
+			query: "?actor=api_key:7&iban=FR10474608000002006107XXXXX&from=2024-05-01T00:00:00Z&before_id=10&limit=5",
+			setupMock: func(m *mock.AuditServiceMock) {
+				m.EXPECT().List(gomock.Any(), audit.Filter{
+					Actor:            "api_key:7",
+					OrganizationName: "ACME Corp",
+					AccountIBAN:      "FR10474608000002006107XXXXX",
+					From:             from,
+					BeforeID:         10,
+					Limit:            5,
+				}).Return([]audit.Event{{
+					ID:         9,
+					OccurredAt: from,
+					Actor:      "api_key:7",
+					Action:     audit.ActionBulkTransfer,
+					After:      json.RawMessage(`{"batch":{"id":42}}`),
+					Outcome:    audit.OutcomeSuccess,
+				}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Invalid time",
+			query:              "?from=yesterday",
+			setupMock:          func(m *mock.AuditServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+		{
+			name:  "Invalid filter",
+			query: "?limit=5000",
+			setupMock: func(m *mock.AuditServiceMock) {
+				m.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: limit must be between 1 and 1000", service.ErrInvalidAuditFilter))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       service.CodeInvalidAuditFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mock.NewAuditServiceMock(ctrl)
+			tt.setupMock(mockService)
+
+			api := &apiDetails{audit: mockService, logger: slog.Default()}
+			router := gin.New()
+			router.GET("/audit-events", withPrincipal("ACME Corp", "api_key:1"), api.ListAuditEvents)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/audit-events"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				var response []AuditEventResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				if assert.Len(t, response, 1) {
+					assert.Equal(t, "bulk_transfer.submit", response[0].Action)
+					assert.JSONEq(t, `{"batch":{"id":42}}`, string(response[0].After))
+				}
+				return
+			}
+
+			var problem problemResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedCode, problem.Code)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyHeader carries the API key of a request, as an alternative to the Authorization header
+const apiKeyHeader = "X-API-Key"
+
+// authenticate is a middleware that rejects requests without valid credentials.
+// The credentials are an API key, in the X-API-Key header or as a bearer token, a
+// JWT bearer token of the identity provider when a token verifier is configured, or
+// else a client certificate verified by the TLS handshake when a certificate mapper
+// is configured.
+// The principal they authenticate is stored in the context of the request, where
+// the services find the organization and the user the request acts for.
+func (api *apiDetails) authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := api.logger.With("request_id", requestIDFrom(c), "path", c.Request.URL.Path)
+
+		key, token := credentialsFrom(c)
+		var principal auth.Principal
+		var err error
+		cert := clientCertificateFrom(c)
+		switch {
+		case key == "" && token == "" && api.certificates != nil && cert != nil:
+			principal, err = api.certificates.MapCertificate(cert)
+			if errors.Is(err, auth.ErrInvalidCertificate) {
+				api.securityEvent(c, securityEventInvalidCertificate, "error", err)
+				unauthorized(c, newProblem(problemInvalidCertificate, err.Error()), "")
+				return
+			}
+		case key == "" && token == "":
+			api.securityEvent(c, securityEventMissingCredentials)
+			unauthorized(c, newProblem(problemUnauthorized, "An API key or a bearer token is required in the X-API-Key or Authorization header"), "")
+			return
+		case key == "" && api.tokens != nil && isJWT(token):
+			principal, err = api.tokens.VerifyToken(c.Request.Context(), token)
+			if errors.Is(err, auth.ErrInvalidToken) {
+				api.securityEvent(c, securityEventInvalidToken, "error", err)
+				unauthorized(c, newProblem(problemInvalidToken, err.Error()), `error="invalid_token"`)
+				return
+			}
+		default:
+			if key == "" {
+				key = token
+			}
+			if api.apiKeys == nil {
+				err = service.ErrInvalidAPIKey
+			} else {
+				principal, err = api.apiKeys.Authenticate(c.Request.Context(), key)
+			}
+			if errors.Is(err, service.ErrInvalidAPIKey) {
+				api.securityEvent(c, securityEventInvalidAPIKey)
+				unauthorized(c, ServiceProblem(err, ""), "")
+				return
+			}
+		}
+		if err != nil {
+			logger.Error("Failed to authenticate request", "error", err)
+			createErrorResponse(c, ServiceProblem(err, "Error authenticating request"))
+			return
+		}
+
+		logger.Debug("Request authenticated",
+			"organization", principal.OrganizationName,
+			"user", principal.User,
+			"subject", principal.Subject)
+		c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// credentialsFrom returns the API key of the X-API-Key header and the bearer token
+// of the Authorization header of the request, empty if there are none
+func credentialsFrom(c *gin.Context) (key, token string) {
+	key = c.GetHeader(apiKeyHeader)
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return key, ""
+	}
+	return key, strings.TrimSpace(token)
+}
+
+// clientCertificateFrom returns the client certificate verified by the TLS handshake
+// of the request, nil if the request is not over TLS or the client sent none
+func clientCertificateFrom(c *gin.Context) *x509.Certificate {
+	state := c.Request.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+// isJWT reports whether a bearer token is a JWT rather than an API key.
+// JWTs have three dot-separated segments, API keys have no dots.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// unauthorized writes p with the challenge of the bearer authentication scheme,
+// completed with the error parameters of RFC 6750 if any
+func unauthorized(c *gin.Context, p *Problem, params string) {
+	challenge := `Bearer realm="moneytransfer"`
+	if params != "" {
+		challenge = fmt.Sprintf("%s, %s", challenge, params)
+	}
+	c.Header("WWW-Authenticate", challenge)
+	createErrorResponse(c, p)
+}
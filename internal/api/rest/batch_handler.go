@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"moneytransfer/internal/batch"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchResponse represents the state of a bulk transfer request
+type BatchResponse struct {
+	ID               int64  `json:"id"`
+	OrganizationName string `json:"organization_name"`
+	OrganizationIBAN string `json:"organization_iban"`
+	// Status is one of received, executed or failed
+	Status        string `json:"status"`
+	TransferCount int    `json:"transfer_count"`
+	TotalCents    int64  `json:"total_cents"`
+	// ErrorCode is the problem code of a failed batch
+	ErrorCode   string `json:"error_code,omitempty"`
+	ErrorDetail string `json:"error_detail,omitempty"`
+	// MessageID and MessageName identify the pain.001 message the batch was imported from
+	MessageID   string    `json:"message_id,omitempty"`
+	MessageName string    `json:"message_name,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func newBatchResponse(b *batch.Batch) BatchResponse {
+	return BatchResponse{
+		ID:               b.ID,
+		OrganizationName: b.OrganizationName,
+		OrganizationIBAN: b.OrganizationIBAN,
+		Status:           string(b.Status),
+		TransferCount:    b.TransferCount,
+		TotalCents:       b.TotalCents,
+		ErrorCode:        b.ErrorCode,
+		ErrorDetail:      b.ErrorDetail,
+		MessageID:        b.MessageID,
+		MessageName:      b.MessageName,
+		CreatedAt:        b.CreatedAt,
+		UpdatedAt:        b.UpdatedAt,
+	}
+}
+
+// GetBatch godoc
+// @Summary Get a batch
+// @Description Returns the state of the batch created by a bulk transfer request
+// @Tags transfers
+// @Produce json
+// @Param id path int true "Batch ID"
+// @Success 200 {object} BatchResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Router /batches/{id} [get]
+func (api *apiDetails) GetBatch(c *gin.Context) {
+	logger := api.logger.With("handler", "GetBatch", "request_id", requestIDFrom(c))
+
+	id, ok := pathID(c, "id", "batch")
+	if !ok {
+		return
+	}
+
+	b, err := api.service.GetBatch(c.Request.Context(), id)
+	if err != nil {
+		problem := ServiceProblem(err, "Error getting batch")
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("Failed to get batch", "error", err, "batch_id", id)
+		}
+		createErrorResponse(c, problem)
+		return
+	}
+
+	c.JSON(http.StatusOK, newBatchResponse(b))
+}
+
+// GetBatchStatusReport godoc
+// @Summary Get the payment status report of a batch
+// @Description Returns the ISO 20022 pain.002 customer payment status report of the batch created by a bulk transfer request.
+// @Description The report references the original pain.001 message and the end-to-end IDs of its transactions.
+// @Description The group status is PDNG while the batch is received, RJCT with a reason code once it failed,
+// @Description and ACCP once it is executed, or PART when some of its transfers were returned.
+// @Description Returned transfers are reported RJCT with their return reason.
+// @Description Reports answer pain.001.001.09 messages in version pain.002.001.10, and all other batches in version pain.002.001.03.
+// @Tags transfers
+// @Produce xml
+// @Param id path int true "Batch ID"
+// @Success 200 {string} string "pain.002 document"
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Router /batches/{id}/status-report [get]
+func (api *apiDetails) GetBatchStatusReport(c *gin.Context) {
+	logger := api.logger.With("handler", "GetBatchStatusReport", "request_id", requestIDFrom(c))
+
+	id, ok := pathID(c, "id", "batch")
+	if !ok {
+		return
+	}
+	c.Header("Content-Type", "application/xml; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="batch-%d-pain.002.xml"`, id))
+	err := api.reports.WriteStatusReport(c.Request.Context(), id, c.Writer)
+	if err == nil {
+		return
+	}
+	// Once the report is being written, the response can no longer turn into a problem
+	if c.Writer.Written() {
+		logger.Error("Failed to write status report", "error", err, "batch_id", id)
+		return
+	}
+	c.Writer.Header().Del("Content-Type")
+	c.Writer.Header().Del("Content-Disposition")
+	problem := ServiceProblem(err, "Error generating status report")
+	if problem.Status >= http.StatusInternalServerError {
+		logger.Error("Failed to generate status report", "error", err, "batch_id", id)
+	}
+	createErrorResponse(c, problem)
+}
@@ -0,0 +1,179 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/service"
+	"moneytransfer/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestGetBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name               string
+		id                 string
+		setupMock          func(*mock.TransferServiceMock)
+		expectedStatusCode int
+		expectedCode       string
+	}{
+		{
+			name: "Batch found",
+			id:   "42",
+			setupMock: func(mockService *mock.TransferServiceMock) {
+				mockService.EXPECT().GetBatch(gomock.Any(), int64(42)).Return(&batch.Batch{
+					ID:               42,
+					OrganizationIBAN: "TEST123456789",
+					Status:           batch.StatusFailed,
+					ErrorCode:        service.CodeInsufficientFunds,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "Batch not found",
+			id:   "7",
+			setupMock: func(mockService *mock.TransferServiceMock) {
+				mockService.EXPECT().GetBatch(gomock.Any(), int64(7)).Return(nil, fmt.Errorf("%w: 7", service.ErrBatchNotFound))
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedCode:       service.CodeBatchNotFound,
+		},
+		{
+			name:               "Invalid batch ID",
+			id:                 "abc",
+			setupMock:          func(mockService *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mock.NewTransferServiceMock(ctrl)
+			tt.setupMock(mockService)
+
+			api := &apiDetails{service: mockService, logger: slog.Default()}
+			router := gin.New()
+			router.GET("/batches/:id", api.GetBatch)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/batches/"+tt.id, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				var response BatchResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, int64(42), response.ID)
+				assert.Equal(t, "failed", response.Status)
+				assert.Equal(t, service.CodeInsufficientFunds, response.ErrorCode)
+				return
+			}
+
+			var problem problemResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedCode, problem.Code)
+		})
+	}
+}
+
+func TestGetBatchStatusReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name               string
+		id                 string
+		setupMock          func(*mock.StatusReportServiceMock)
+		expectedStatusCode int
+		expectedCode       string
+		expectedBody       string
+	}{
+		{
+			name: "Report is written",
+			id:   "42",
+			setupMock: func(mockService *mock.StatusReportServiceMock) {
+				mockService.EXPECT().WriteStatusReport(gomock.Any(), int64(42), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int64, w io.Writer) error {
+						_, err := io.WriteString(w, "<Document/>")
+						return err
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "<Document/>",
+		},
+		{
+			name: "Batch not found",
+			id:   "7",
+			setupMock: func(mockService *mock.StatusReportServiceMock) {
+				mockService.EXPECT().WriteStatusReport(gomock.Any(), int64(7), gomock.Any()).Return(fmt.Errorf("%w: 7", service.ErrBatchNotFound))
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedCode:       service.CodeBatchNotFound,
+		},
+		{
+			name:               "Invalid batch ID",
+			id:                 "abc",
+			setupMock:          func(mockService *mock.StatusReportServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+		{
+			name: "Error while the report is written",
+			id:   "42",
+			setupMock: func(mockService *mock.StatusReportServiceMock) {
+				mockService.EXPECT().WriteStatusReport(gomock.Any(), int64(42), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int64, w io.Writer) error {
+						io.WriteString(w, "<Document>")
+						return errors.New("connection reset")
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "<Document>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mock.NewStatusReportServiceMock(ctrl)
+			tt.setupMock(mockService)
+
+			api := &apiDetails{reports: mockService, logger: slog.Default()}
+			router := gin.New()
+			router.GET("/batches/:id/status-report", api.GetBatchStatusReport)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/batches/"+tt.id+"/status-report", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+				assert.Equal(t, `attachment; filename="batch-42-pain.002.xml"`, w.Header().Get("Content-Disposition"))
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+				return
+			}
+
+			assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+			assert.Empty(t, w.Header().Get("Content-Disposition"))
+			var problem problemResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedCode, problem.Code)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// csvMediaType is the media type of bulk transfer documents in the CSV format
+	csvMediaType = "text/csv"
+	// csvPeekBytes is the size of the start of a CSV document read to detect its delimiter
+	csvPeekBytes = 64 << 10
+)
+
+var (
+	// utf8BOM is the byte order mark spreadsheet applications write at the start of UTF-8 exports
+	utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+	// csvDelimiters lists the delimiters detected in the header row, by order of preference
+	csvDelimiters = []rune{',', ';', '\t', '|'}
+)
+
+// csvColumns lists the columns every CSV document must have, they map to the
+// JSON fields of BulkTransferHeader and CreditTransfer
+var csvColumns = []string{
+	"organization_name",
+	"organization_bic",
+	"organization_iban",
+	"amount",
+	"counterparty_name",
+	"counterparty_bic",
+	"counterparty_iban",
+	"description",
+}
+
+// csvEndToEndIDColumn is the optional column of the end-to-end IDs of the credit transfers
+const csvEndToEndIDColumn = "end_to_end_id"
+
+// csvError reports an error at a row of a CSV document, and at a column when it is known.
+// Rows are numbered from 1, the header row included, as in spreadsheet applications.
+type csvError struct {
+	row    int
+	column string
+	err    error
+}
+
+func (e *csvError) Error() string {
+	if e.column == "" {
+		return fmt.Sprintf("row %d: %v", e.row, e.err)
+	}
+	return fmt.Sprintf("row %d, column %s: %v", e.row, e.column, e.err)
+}
+
+func (e *csvError) Unwrap() error {
+	return e.err
+}
+
+// decodeCSVBulkTransfer decodes a bulk transfer document in the CSV format.
+// The first row names the columns, in any order and case; unknown columns are ignored.
+// Every other row is a credit transfer, and carries the organization of the whole document:
+// the organization cells of a row must be empty or equal to those of the first row that has them.
+// The delimiter is detected from the header row, and a leading UTF-8 byte order mark is skipped.
+func decodeCSVBulkTransfer(r io.Reader, yield func(CreditTransfer) error) (*BulkTransferHeader, error) {
+	br := bufio.NewReaderSize(r, csvPeekBytes)
+	if bom, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(bom, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(br)
+	reader.Comma = detectCSVDelimiter(br)
+	reader.ReuseRecord = true
+
+	record, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("failed to parse CSV content: the header row is missing")
+		}
+		return nil, csvParseError(err)
+	}
+	columns, err := csvColumnIndexes(record)
+	if err != nil {
+		return nil, &csvError{row: 1, err: err}
+	}
+
+	var header BulkTransferHeader
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, csvParseError(err)
+		}
+		row, _ := reader.FieldPos(0)
+
+		cell := func(column string) string {
+			return strings.TrimSpace(record[columns[column]])
+		}
+		for _, f := range []struct {
+			column string
+			value  *string
+		}{
+			{"organization_name", &header.OrganizationName},
+			{"organization_bic", &header.OrganizationBIC},
+			{"organization_iban", &header.OrganizationIBAN},
+		} {
+			value := cell(f.column)
+			switch {
+			case value == "" || value == *f.value:
+			case *f.value == "":
+				*f.value = value
+			default:
+				return nil, &csvError{row: row, column: f.column, err: fmt.Errorf("%q differs from %q in a previous row, a document has a single organization", value, *f.value)}
+			}
+		}
+
+		ct := CreditTransfer{
+			Amount:           cell("amount"),
+			CounterpartyName: cell("counterparty_name"),
+			CounterpartyBIC:  cell("counterparty_bic"),
+			CounterpartyIBAN: cell("counterparty_iban"),
+			Description:      cell("description"),
+		}
+		if _, ok := columns[csvEndToEndIDColumn]; ok {
+			ct.EndToEndID = cell(csvEndToEndIDColumn)
+		}
+		if err := yield(ct); err != nil {
+			var ctErr *creditTransferError
+			if errors.As(err, &ctErr) {
+				return nil, &csvError{row: row, column: ctErr.field, err: err}
+			}
+			return nil, err
+		}
+	}
+
+	return &header, nil
+}
+
+// detectCSVDelimiter returns the delimiter that occurs most often outside quotes
+// in the header row, the comma when there is none
+func detectCSVDelimiter(br *bufio.Reader) rune {
+	start, _ := br.Peek(csvPeekBytes)
+	if i := bytes.IndexByte(start, '\n'); i >= 0 {
+		start = start[:i]
+	}
+
+	counts := make(map[rune]int)
+	quoted := false
+	for _, c := range string(start) {
+		if c == '"' {
+			quoted = !quoted
+			continue
+		}
+		if !quoted {
+			counts[c]++
+		}
+	}
+
+	delimiter := csvDelimiters[0]
+	for _, d := range csvDelimiters[1:] {
+		if counts[d] > counts[delimiter] {
+			delimiter = d
+		}
+	}
+	return delimiter
+}
+
+// csvColumnIndexes maps the CSV columns to their index in the header row
+func csvColumnIndexes(record []string) (map[string]int, error) {
+	columns := make(map[string]int, len(record))
+	for i, name := range record {
+		name = normalizeCSVColumn(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("duplicate column %s", name)
+		}
+		columns[name] = i
+	}
+
+	var missing []string
+	for _, name := range csvColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing columns %s", strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+// normalizeCSVColumn turns a column name such as "Counterparty IBAN" into its field name
+func normalizeCSVColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+// csvParseError converts an error of the CSV reader into a csvError
+func csvParseError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &csvError{row: parseErr.StartLine, err: parseErr.Err}
+	}
+	return err
+}
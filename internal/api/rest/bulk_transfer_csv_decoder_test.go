@@ -0,0 +1,165 @@
+package rest
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCSVBulkTransfer(t *testing.T) {
+	wantHeader := &BulkTransferHeader{OrganizationName: "Test Org", OrganizationBIC: "TESTBIC1", OrganizationIBAN: "TEST123456789"}
+	wantTransfers := []CreditTransfer{
+		{Amount: "1.50", CounterpartyName: "John Doe", CounterpartyBIC: "JOHNBIC", CounterpartyIBAN: "JOHNIBAN", Description: "One"},
+		{Amount: "2", CounterpartyName: "Doe, Jane", CounterpartyBIC: "JANEBIC", CounterpartyIBAN: "JANEIBAN", Description: "Two"},
+	}
+
+	tests := []struct {
+		name          string
+		content       string
+		wantHeader    *BulkTransferHeader
+		wantTransfers []CreditTransfer
+		wantRow       int
+		wantColumn    string
+		wantErr       bool
+	}{
+		{
+			name: "Comma delimited",
+			content: "organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,counterparty_iban,description\n" +
+				"Test Org,TESTBIC1,TEST123456789,1.50,John Doe,JOHNBIC,JOHNIBAN,One\n" +
+				"Test Org,TESTBIC1,TEST123456789,2,\"Doe, Jane\",JANEBIC,JANEIBAN,Two\n",
+			wantHeader:    wantHeader,
+			wantTransfers: wantTransfers,
+		},
+		{
+			name: "Semicolon delimited with BOM, CRLF and spreadsheet column names",
+			content: "\ufeffCounterparty Name;Amount;Counterparty BIC;Counterparty IBAN;Description;Organization Name;Organization BIC;Organization IBAN;Notes\r\n" +
+				"John Doe;1.50;JOHNBIC;JOHNIBAN;One;Test Org;TESTBIC1;TEST123456789;x\r\n" +
+				"Doe, Jane;2;JANEBIC;JANEIBAN;Two;;;;\r\n",
+			wantHeader:    wantHeader,
+			wantTransfers: wantTransfers,
+		},
+		{
+			name: "Tab delimited",
+			content: "organization_name\torganization_bic\torganization_iban\tamount\tcounterparty_name\tcounterparty_bic\tcounterparty_iban\tdescription\n" +
+				"Test Org\tTESTBIC1\tTEST123456789\t1.50\tJohn Doe\tJOHNBIC\tJOHNIBAN\tOne\n" +
+				"Test Org\tTESTBIC1\tTEST123456789\t2\tDoe, Jane\tJANEBIC\tJANEIBAN\tTwo\n",
+			wantHeader:    wantHeader,
+			wantTransfers: wantTransfers,
+		},
+		{
+			name:    "Empty content",
+			content: "",
+			wantErr: true,
+		},
+		{
+			name:    "Missing column",
+			content: "organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,description\n",
+			wantRow: 1,
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate column",
+			content: "amount,amount,organization_name,organization_bic,organization_iban,counterparty_name,counterparty_bic,counterparty_iban,description\n",
+			wantRow: 1,
+			wantErr: true,
+		},
+		{
+			name: "Wrong number of fields",
+			content: "organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,counterparty_iban,description\n" +
+				"Test Org,TESTBIC1,TEST123456789,1.50,John Doe,JOHNBIC,JOHNIBAN,One\n" +
+				"Test Org,TESTBIC1,TEST123456789,2\n",
+			wantRow: 3,
+			wantErr: true,
+		},
+		{
+			name: "Second organization",
+			content: "organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,counterparty_iban,description\n" +
+				"Test Org,TESTBIC1,TEST123456789,1.50,John Doe,JOHNBIC,JOHNIBAN,One\n" +
+				"Test Org,TESTBIC1,OTHER123456789,2,Jane Doe,JANEBIC,JANEIBAN,Two\n",
+			wantRow:    3,
+			wantColumn: "organization_iban",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var transfers []CreditTransfer
+			header, err := decodeCSVBulkTransfer(strings.NewReader(tt.content), func(ct CreditTransfer) error {
+				transfers = append(transfers, ct)
+				return nil
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				var csvErr *csvError
+				if tt.wantRow != 0 && assert.ErrorAs(t, err, &csvErr) {
+					assert.Equal(t, tt.wantRow, csvErr.row)
+					assert.Equal(t, tt.wantColumn, csvErr.column)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHeader, header)
+			assert.Equal(t, tt.wantTransfers, transfers)
+		})
+	}
+}
+
+func TestDecodeCSVBulkTransfer_YieldError(t *testing.T) {
+	content := "organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,counterparty_iban,description\n" +
+		"Test Org,TESTBIC1,TEST123456789,1,A,B,C,D\n" +
+		"Test Org,TESTBIC1,TEST123456789,x,A,B,C,D\n" +
+		"Test Org,TESTBIC1,TEST123456789,3,A,B,C,D\n"
+
+	t.Run("Credit transfer errors carry the row and column", func(t *testing.T) {
+		var calls int
+		_, err := decodeCSVBulkTransfer(strings.NewReader(content), func(ct CreditTransfer) error {
+			calls++
+			if ct.Amount == "x" {
+				return &creditTransferError{line: calls, transfer: ct, err: errors.New("invalid amount"), field: "amount", invalidAmount: true}
+			}
+			return nil
+		})
+
+		var csvErr *csvError
+		if assert.ErrorAs(t, err, &csvErr) {
+			assert.Equal(t, 3, csvErr.row)
+			assert.Equal(t, "amount", csvErr.column)
+		}
+		var ctErr *creditTransferError
+		assert.ErrorAs(t, err, &ctErr)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Other errors are returned as is", func(t *testing.T) {
+		stop := errors.New("stop")
+		_, err := decodeCSVBulkTransfer(strings.NewReader(content), func(ct CreditTransfer) error {
+			return stop
+		})
+		assert.Equal(t, stop, err)
+	})
+}
+
+func TestDetectCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   rune
+	}{
+		{"Comma", "a,b,c\n1;2,3", ','},
+		{"Semicolon", "a;b;c\n1,2,3", ';'},
+		{"Tab", "a\tb\tc", '\t'},
+		{"Pipe", "a|b|c", '|'},
+		{"Delimiters in quotes are ignored", `"a;b;c",d,e`, ','},
+		{"Single column defaults to comma", "amount", ','},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectCSVDelimiter(bufio.NewReader(strings.NewReader(tt.header))))
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"moneytransfer/internal/jsonschema"
+)
+
+// bulkTransferDecoder decodes a bulk transfer document of a single format.
+// It streams the credit transfers of the document to yield one at a time, so
+// that large documents are never held in memory, and returns the header once
+// the whole document has been read.
+// An error returned by yield stops the decoding and is returned as is.
+type bulkTransferDecoder func(r io.Reader, yield func(CreditTransfer) error) (*BulkTransferHeader, error)
+
+// bulkTransferDecoders maps the media type of a bulk transfer document to its decoder
+var bulkTransferDecoders = map[string]bulkTransferDecoder{
+	"application/json": decodeJSONBulkTransfer,
+	csvMediaType:       decodeCSVBulkTransfer,
+	"application/xml":  decodePain001BulkTransfer,
+	"text/xml":         decodePain001BulkTransfer,
+	mt101MediaType:     decodeMT101BulkTransfer,
+}
+
+// bulkTransferExtensions maps file extensions to the media type of their bulk transfer format,
+// for extensions the mime package may not know
+var bulkTransferExtensions = map[string]string{
+	".csv":   csvMediaType,
+	".mt101": mt101MediaType,
+	".fin":   mt101MediaType,
+}
+
+// supportedMediaTypes returns the media types of the supported bulk transfer formats
+func supportedMediaTypes() []string {
+	mediaTypes := make([]string, 0, len(bulkTransferDecoders))
+	for mediaType := range bulkTransferDecoders {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+// decodeJSONBulkTransfer decodes a bulk transfer document in the JSON format.
+// The document is read token by token and only one credit transfer is decoded at a time.
+// It is validated against the version of the bulk transfer schema named by its schema_version
+// member, the latest when it has none: every credit transfer as it is read, and the other
+// members once the whole document has been read. Members given twice and data following
+// the document are rejected.
+func decodeJSONBulkTransfer(r io.Reader, yield func(CreditTransfer) error) (*BulkTransferHeader, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := expectJSONDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	schema := latestBulkTransferSchema
+	// members holds the members of the document, credit transfers aside as they are never held in memory
+	members := make(map[string]any)
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JSON key: %w", err)
+		}
+		key, _ := token.(string)
+		// A member given twice would be read differently by other parsers, a second
+		// credit_transfers array would even be executed along with the first one
+		if _, ok := members[key]; ok {
+			return nil, fmt.Errorf("failed to parse JSON content: duplicate member %q", key)
+		}
+
+		if key == "credit_transfers" {
+			if err := decodeJSONCreditTransfers(dec, schema, yield); err != nil {
+				return nil, err
+			}
+			members[key] = []any{}
+			continue
+		}
+
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON member %q: %w", key, err)
+		}
+		if key == "schema_version" {
+			if _, ok := members["credit_transfers"]; ok {
+				return nil, errors.New("failed to parse JSON content: schema_version must precede credit_transfers")
+			}
+			if schema, err = bulkTransferSchemaOf(value); err != nil {
+				return nil, err
+			}
+		}
+		members[key] = value
+	}
+
+	if err := expectJSONDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("failed to parse JSON content: unexpected data after the document")
+	}
+	if err := schema.root.Validate(members); err != nil {
+		return nil, &schemaError{version: schema.version, err: err}
+	}
+
+	var header BulkTransferHeader
+	header.OrganizationName, _ = members["organization_name"].(string)
+	header.OrganizationBIC, _ = members["organization_bic"].(string)
+	header.OrganizationIBAN, _ = members["organization_iban"].(string)
+	header.MessageID, _ = members["message_id"].(string)
+	return &header, nil
+}
+
+// bulkTransferSchemaOf returns the version of the bulk transfer schema named by a schema_version member
+func bulkTransferSchemaOf(version any) (*bulkTransferSchema, error) {
+	if v, ok := version.(string); ok {
+		if schema, ok := bulkTransferSchemas[v]; ok {
+			return schema, nil
+		}
+	}
+	// The latest schema lists the supported versions
+	if err := latestBulkTransferSchema.schemaVersion.ValidateAt("/schema_version", version); err != nil {
+		return nil, &schemaError{version: latestBulkTransferSchema.version, err: err}
+	}
+	return nil, fmt.Errorf("failed to parse JSON content: unsupported schema_version %v", version)
+}
+
+// decodeJSONCreditTransfers decodes the credit_transfers array one element at a time,
+// validating every element against the schema
+func decodeJSONCreditTransfers(dec *json.Decoder, schema *bulkTransferSchema, yield func(CreditTransfer) error) error {
+	if err := expectJSONDelim(dec, '['); err != nil {
+		return err
+	}
+	for line := 1; dec.More(); line++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("failed to parse credit transfer: %w", err)
+		}
+		var value any
+		valueDec := json.NewDecoder(bytes.NewReader(raw))
+		valueDec.UseNumber()
+		if err := valueDec.Decode(&value); err != nil {
+			return fmt.Errorf("failed to parse credit transfer: %w", err)
+		}
+
+		var ct CreditTransfer
+		path := fmt.Sprintf("/credit_transfers/%d", line-1)
+		if err := schema.creditTransfer.ValidateAt(path, value); err != nil {
+			// The transfer is decoded as far as possible to name its counterparty in the problem
+			_ = json.Unmarshal(raw, &ct)
+			field, invalidAmount := invalidMember(path, err)
+			return &creditTransferError{
+				line:          line,
+				transfer:      ct,
+				err:           &schemaError{version: schema.version, err: err},
+				field:         field,
+				invalidAmount: invalidAmount,
+			}
+		}
+		if err := json.Unmarshal(raw, &ct); err != nil {
+			return fmt.Errorf("failed to parse credit transfer: %w", err)
+		}
+		if err := yield(ct); err != nil {
+			return err
+		}
+	}
+	return expectJSONDelim(dec, ']')
+}
+
+// invalidMember returns the member of the credit transfer at path named by the first schema violation,
+// and whether the amount is its only invalid member
+func invalidMember(path string, err error) (string, bool) {
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return "", false
+	}
+	member := func(v jsonschema.Violation) string {
+		name, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(v.InstancePath, path), "/"), "/")
+		return name
+	}
+	invalidAmount := true
+	for _, v := range validationErr.Violations {
+		invalidAmount = invalidAmount && member(v) == "amount"
+	}
+	return member(validationErr.Violations[0]), invalidAmount
+}
+
+// schemaError reports a bulk transfer document that does not match a version of the bulk transfer schema
+type schemaError struct {
+	version string
+	err     error
+}
+
+func (e *schemaError) Error() string {
+	return fmt.Sprintf("document does not match version %s of the bulk transfer schema: %v", e.version, e.err)
+}
+
+func (e *schemaError) Unwrap() error {
+	return e.err
+}
+
+// expectJSONDelim reads the next token and checks that it is the given delimiter
+func expectJSONDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to parse JSON content: %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("failed to parse JSON content: expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+// fileMediaType returns the media type of an uploaded bulk transfer file.
+// The Content-Type of the form part is used when it is a supported format, otherwise
+// the media type is derived from the file extension. Files that carry neither, such as
+// text/plain or application/octet-stream parts, are treated as JSON, the original
+// upload format, so that uploaded files are never rejected for their media type.
+func fileMediaType(header *multipart.FileHeader) string {
+	if mediaType, _, err := mime.ParseMediaType(header.Header.Get("Content-Type")); err == nil {
+		if _, ok := bulkTransferDecoders[mediaType]; ok {
+			return mediaType
+		}
+	}
+	return FileMediaType(header.Filename)
+}
+
+// FileMediaType returns the media type of a bulk transfer file derived from its extension,
+// JSON when the extension is not one of a supported format
+func FileMediaType(filename string) string {
+	if ext := strings.ToLower(filepath.Ext(filename)); ext != "" {
+		if mediaType, ok := bulkTransferExtensions[ext]; ok {
+			return mediaType
+		}
+		if mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(ext)); err == nil {
+			if _, ok := bulkTransferDecoders[mediaType]; ok {
+				return mediaType
+			}
+		}
+	}
+	return "application/json"
+}
+
+// spoolToTempFile copies r to a temporary file so that it can be read more than once.
+// The caller is responsible for closing and removing the file.
+func spoolToTempFile(r io.Reader) (*os.File, error) {
+	f, err := os.CreateTemp("", "bulk-transfer-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		removeTempFile(f)
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		removeTempFile(f)
+		return nil, err
+	}
+	return f, nil
+}
+
+// removeTempFile closes and removes a file created by spoolToTempFile
+func removeTempFile(f *os.File) {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// isBodyTooLarge reports whether err was caused by a request body exceeding its size limit
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
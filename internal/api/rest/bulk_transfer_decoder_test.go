@@ -0,0 +1,279 @@
+package rest
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"moneytransfer/internal/jsonschema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeJSONBulkTransfer(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantHeader    *BulkTransferHeader
+		wantTransfers []CreditTransfer
+		wantErr       bool
+	}{
+		{
+			name: "Header before credit transfers",
+			content: `{
+				"organization_name": "Test Org",
+				"organization_bic": "TESTBIC1",
+				"organization_iban": "TEST123456789",
+				"credit_transfers": [
+					{"amount": "1.50", "counterparty_name": "John Doe", "counterparty_bic": "JOHNBIC", "counterparty_iban": "JOHNIBAN", "description": "One"},
+					{"amount": "2", "counterparty_name": "Jane Doe", "counterparty_bic": "JANEBIC", "counterparty_iban": "JANEIBAN", "description": "Two"}
+				]
+			}`,
+			wantHeader: &BulkTransferHeader{OrganizationName: "Test Org", OrganizationBIC: "TESTBIC1", OrganizationIBAN: "TEST123456789"},
+			wantTransfers: []CreditTransfer{
+				{Amount: "1.50", CounterpartyName: "John Doe", CounterpartyBIC: "JOHNBIC", CounterpartyIBAN: "JOHNIBAN", Description: "One"},
+				{Amount: "2", CounterpartyName: "Jane Doe", CounterpartyBIC: "JANEBIC", CounterpartyIBAN: "JANEIBAN", Description: "Two"},
+			},
+		},
+		{
+			name: "Header after credit transfers and unknown keys",
+			content: `{
+				"credit_transfers": [{"amount": "1", "counterparty_name": "John Doe", "counterparty_bic": "JOHNBIC", "counterparty_iban": "JOHNIBAN", "description": "One", "priority": 1}],
+				"comment": {"nested": [1, 2, 3]},
+				"organization_name": "Test Org",
+				"organization_bic": "TESTBIC1",
+				"organization_iban": "TEST123456789",
+				"message_id": "MSG-1"
+			}`,
+			wantHeader:    &BulkTransferHeader{OrganizationName: "Test Org", OrganizationBIC: "TESTBIC1", OrganizationIBAN: "TEST123456789", MessageID: "MSG-1"},
+			wantTransfers: []CreditTransfer{{Amount: "1", CounterpartyName: "John Doe", CounterpartyBIC: "JOHNBIC", CounterpartyIBAN: "JOHNIBAN", Description: "One"}},
+		},
+		{
+			name: "Schema version",
+			content: `{
+				"schema_version": "1",
+				"organization_name": "Test Org",
+				"organization_bic": "TESTBIC1",
+				"organization_iban": "TEST123456789",
+				"credit_transfers": [{"amount": "1", "counterparty_name": "John Doe", "counterparty_bic": "JOHNBIC", "counterparty_iban": "JOHNIBAN", "description": "One"}]
+			}`,
+			wantHeader:    &BulkTransferHeader{OrganizationName: "Test Org", OrganizationBIC: "TESTBIC1", OrganizationIBAN: "TEST123456789"},
+			wantTransfers: []CreditTransfer{{Amount: "1", CounterpartyName: "John Doe", CounterpartyBIC: "JOHNBIC", CounterpartyIBAN: "JOHNIBAN", Description: "One"}},
+		},
+		{
+			name: "Schema version after credit transfers",
+			content: `{
+				"organization_name": "Test Org",
+				"organization_bic": "TESTBIC1",
+				"organization_iban": "TEST123456789",
+				"credit_transfers": [],
+				"schema_version": "1"
+			}`,
+			wantErr: true,
+		},
+		{
+			name:    "Not an object",
+			content: `[]`,
+			wantErr: true,
+		},
+		{
+			name:    "Credit transfers not an array",
+			content: `{"credit_transfers": {}}`,
+			wantErr: true,
+		},
+		{
+			name: "Credit transfers given twice",
+			content: `{
+				"organization_name": "Test Org",
+				"organization_bic": "TESTBIC1",
+				"organization_iban": "TEST123456789",
+				"credit_transfers": [{"amount": "1", "counterparty_name": "John Doe", "counterparty_bic": "JOHNBIC", "counterparty_iban": "JOHNIBAN", "description": "One"}],
+				"credit_transfers": [{"amount": "2", "counterparty_name": "Jane Doe", "counterparty_bic": "JANEBIC", "counterparty_iban": "JANEIBAN", "description": "Two"}]
+			}`,
+			wantErr: true,
+		},
+		{
+			name: "Header member given twice",
+			content: `{
+				"organization_name": "Test Org",
+				"organization_bic": "TESTBIC1",
+				"organization_iban": "TEST123456789",
+				"organization_iban": "OTHER123456789",
+				"credit_transfers": [{"amount": "1", "counterparty_name": "John Doe", "counterparty_bic": "JOHNBIC", "counterparty_iban": "JOHNIBAN", "description": "One"}]
+			}`,
+			wantErr: true,
+		},
+		{
+			name: "Data after the document",
+			content: `{
+				"organization_name": "Test Org",
+				"organization_bic": "TESTBIC1",
+				"organization_iban": "TEST123456789",
+				"credit_transfers": [{"amount": "1", "counterparty_name": "John Doe", "counterparty_bic": "JOHNBIC", "counterparty_iban": "JOHNIBAN", "description": "One"}]
+			}
+			{"credit_transfers": []}`,
+			wantErr: true,
+		},
+		{
+			name: "Trailing whitespace",
+			content: `{
+				"organization_name": "Test Org",
+				"organization_bic": "TESTBIC1",
+				"organization_iban": "TEST123456789",
+				"credit_transfers": [{"amount": "1", "counterparty_name": "John Doe", "counterparty_bic": "JOHNBIC", "counterparty_iban": "JOHNIBAN", "description": "One"}]
+			}` + "\n\n",
+			wantHeader:    &BulkTransferHeader{OrganizationName: "Test Org", OrganizationBIC: "TESTBIC1", OrganizationIBAN: "TEST123456789"},
+			wantTransfers: []CreditTransfer{{Amount: "1", CounterpartyName: "John Doe", CounterpartyBIC: "JOHNBIC", CounterpartyIBAN: "JOHNIBAN", Description: "One"}},
+		},
+		{
+			name:    "Truncated content",
+			content: `{"credit_transfers": [{"amount": "1"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var transfers []CreditTransfer
+			header, err := decodeJSONBulkTransfer(strings.NewReader(tt.content), func(ct CreditTransfer) error {
+				transfers = append(transfers, ct)
+				return nil
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHeader, header)
+			assert.Equal(t, tt.wantTransfers, transfers)
+		})
+	}
+}
+
+func TestDecodeJSONBulkTransfer_YieldError(t *testing.T) {
+	content := `{"credit_transfers": [
+		{"amount": "1", "counterparty_name": "A", "counterparty_bic": "B", "counterparty_iban": "C", "description": "D"},
+		{"amount": "2", "counterparty_name": "A", "counterparty_bic": "B", "counterparty_iban": "C", "description": "D"},
+		{"amount": "3", "counterparty_name": "A", "counterparty_bic": "B", "counterparty_iban": "C", "description": "D"}
+	]}`
+	stop := errors.New("stop")
+
+	var calls int
+	_, err := decodeJSONBulkTransfer(strings.NewReader(content), func(ct CreditTransfer) error {
+		calls++
+		if ct.Amount == "2" {
+			return stop
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 2, calls)
+}
+
+func TestDecodeJSONBulkTransfer_SchemaViolations(t *testing.T) {
+	t.Run("Credit transfer", func(t *testing.T) {
+		content := `{"credit_transfers": [
+			{"amount": "1", "counterparty_name": "A", "counterparty_bic": "B", "counterparty_iban": "C", "description": "D"},
+			{"amount": 2, "counterparty_name": "A", "counterparty_bic": "B", "counterparty_iban": "C"}
+		]}`
+		var calls int
+		_, err := decodeJSONBulkTransfer(strings.NewReader(content), func(ct CreditTransfer) error {
+			calls++
+			return nil
+		})
+
+		var ctErr *creditTransferError
+		assert.ErrorAs(t, err, &ctErr)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, 2, ctErr.line)
+		assert.Equal(t, "A", ctErr.transfer.CounterpartyName)
+		assert.False(t, ctErr.invalidAmount)
+
+		var validationErr *jsonschema.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []jsonschema.Violation{
+			{InstancePath: "/credit_transfers/1", SchemaPath: "/$defs/credit_transfer/required", Message: `missing required property "description"`},
+			{InstancePath: "/credit_transfers/1/amount", SchemaPath: "/$defs/credit_transfer/properties/amount/type", Message: "expected string, got integer"},
+		}, validationErr.Violations)
+	})
+
+	t.Run("Amount", func(t *testing.T) {
+		content := `{"credit_transfers": [{"amount": "1,50", "counterparty_name": "A", "counterparty_bic": "B", "counterparty_iban": "C", "description": "D"}]}`
+		_, err := decodeJSONBulkTransfer(strings.NewReader(content), func(ct CreditTransfer) error { return nil })
+
+		var ctErr *creditTransferError
+		assert.ErrorAs(t, err, &ctErr)
+		assert.True(t, ctErr.invalidAmount)
+		assert.Equal(t, "amount", ctErr.field)
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		content := `{"organization_name": "", "organization_bic": 1, "credit_transfers": []}`
+		_, err := decodeJSONBulkTransfer(strings.NewReader(content), func(ct CreditTransfer) error { return nil })
+
+		var schemaErr *schemaError
+		assert.ErrorAs(t, err, &schemaErr)
+		assert.Equal(t, "1", schemaErr.version)
+		var validationErr *jsonschema.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []jsonschema.Violation{
+			{InstancePath: "", SchemaPath: "/required", Message: `missing required property "organization_iban"`},
+			{InstancePath: "/organization_bic", SchemaPath: "/properties/organization_bic/type", Message: "expected string, got integer"},
+			{InstancePath: "/organization_name", SchemaPath: "/properties/organization_name/minLength", Message: "length must be at least 1"},
+		}, validationErr.Violations)
+	})
+
+	t.Run("Unsupported schema version", func(t *testing.T) {
+		content := `{"schema_version": "0", "credit_transfers": [{"amount": "1"}]}`
+		var calls int
+		_, err := decodeJSONBulkTransfer(strings.NewReader(content), func(ct CreditTransfer) error {
+			calls++
+			return nil
+		})
+
+		var validationErr *jsonschema.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Zero(t, calls)
+		assert.Equal(t, []jsonschema.Violation{
+			{InstancePath: "/schema_version", SchemaPath: "/properties/schema_version/enum", Message: `must be one of ["1"]`},
+		}, validationErr.Violations)
+	})
+}
+
+func TestFileMediaType(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		want        string
+	}{
+		{"Explicit content type", "payroll.txt", "application/json", "application/json"},
+		{"Octet stream falls back to extension", "payroll.json", "application/octet-stream", "application/json"},
+		{"Missing content type falls back to extension", "payroll.json", "", "application/json"},
+		{"Unknown file defaults to JSON", "payroll", "", "application/json"},
+		{"CSV file by extension", "payroll.CSV", "", "text/csv"},
+		{"XML file by extension", "payments.xml", "", "text/xml"},
+		{"MT101 file by extension", "payments.fin", "", "application/vnd.swift.mt101"},
+		{"Explicit CSV content type", "payroll", "text/csv; charset=utf-8", "text/csv"},
+		{"Text part defaults to JSON", "payroll.txt", "text/plain", "application/json"},
+		{"Text file defaults to JSON", "payroll.txt", "", "application/json"},
+		{"Octet stream without extension defaults to JSON", "payroll", "application/octet-stream", "application/json"},
+		{"Unsupported content type falls back to extension", "payroll.csv", "text/plain", "text/csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := &multipart.FileHeader{
+				Filename: tt.filename,
+				Header:   textproto.MIMEHeader{},
+			}
+			if tt.contentType != "" {
+				header.Header.Set("Content-Type", tt.contentType)
+			}
+			assert.Equal(t, tt.want, fileMediaType(header))
+		})
+	}
+}
@@ -1,31 +1,48 @@
 package rest
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
 	"strconv"
 	"strings"
 
+	"moneytransfer/internal/jsonschema"
 	"moneytransfer/internal/service"
 	"moneytransfer/internal/transfer"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
 )
 
 // BulkTransferResponse represents the structure of a successful bulk transfer response
 type BulkTransferResponse struct {
 	Message string `json:"message"`
+	// BatchID identifies the batch of the request, its status changes are streamed at /events
+	BatchID int64 `json:"batch_id"`
+}
+
+// BulkTransferHeader represents the organization fields of a bulk transfer file
+type BulkTransferHeader struct {
+	OrganizationName string `json:"organization_name" validate:"required"`
+	OrganizationBIC  string `json:"organization_bic" validate:"required"`
+	OrganizationIBAN string `json:"organization_iban" validate:"required"`
+	// MessageID is the reference of the document given by the organization, it is
+	// the original message referenced by the status reports of the batch
+	MessageID string `json:"message_id,omitempty"`
+	// MessageName is the name of the payment message the document was imported from
+	MessageName string `json:"-"`
 }
 
 // BulkTransferFileContent represents the structure of the JSON file content
 type BulkTransferFileContent struct {
-	OrganizationName string           `json:"organization_name" validate:"required"`
-	OrganizationBIC  string           `json:"organization_bic" validate:"required"`
-	OrganizationIBAN string           `json:"organization_iban" validate:"required"`
-	CreditTransfers  []CreditTransfer `json:"credit_transfers" validate:"required"`
+	// SchemaVersion is the version of the bulk transfer schema the document is written in,
+	// the latest when empty. It must precede the credit transfers.
+	SchemaVersion string `json:"schema_version,omitempty"`
+	BulkTransferHeader
+	CreditTransfers []CreditTransfer `json:"credit_transfers" validate:"required"`
 }
 
 // CreditTransfer represents the structure of a credit transfer
@@ -35,106 +52,373 @@ type CreditTransfer struct {
 	CounterpartyBIC  string `json:"counterparty_bic" validate:"required"`
 	CounterpartyIBAN string `json:"counterparty_iban" validate:"required"`
 	Description      string `json:"description" validate:"required"`
+	// EndToEndID is the reference of the transfer passed on to the counterparty
+	EndToEndID string `json:"end_to_end_id,omitempty"`
+	// PaymentInformationID is the payment information block of a pain.001 document
+	PaymentInformationID string `json:"-"`
 }
 
 // BulkTransfer godoc
 // @Summary Perform a bulk transfer
-// @Description Transfer money from one account to multiple accounts using a file upload
+// @Description Transfer money from one account to multiple accounts.
+// @Description The bulk transfer document is either uploaded as a file in a multipart form
+// @Description or sent directly as the request body with its media type as Content-Type.
+// @Description Uploaded files are read as JSON unless their part Content-Type or their extension names another supported format.
+// @Description CSV documents start with a header row naming the columns organization_name, organization_bic, organization_iban,
+// @Description amount, counterparty_name, counterparty_bic, counterparty_iban and description. The delimiter (comma, semicolon, tab or pipe)
+// @Description is detected from the header row and a UTF-8 byte order mark is skipped. CSV errors carry the row and column.
+// @Description An optional end_to_end_id column sets the end-to-end ID of each credit transfer.
+// @Description ISO 20022 pain.001.001.03 and pain.001.001.09 messages are accepted as application/xml or text/xml; their payment
+// @Description information blocks must debit a single EUR account and each transaction keeps its EndToEndId.
+// @Description SWIFT MT101 messages are accepted as application/vnd.swift.mt101 or as .mt101 and .fin files; their fields are
+// @Description validated at tag level, they must debit a single EUR account and each transaction keeps its :21: reference.
+// @Description Documents are streamed, so the number of credit transfers is only limited by the maximum upload size.
+// @Description When request signing is enabled, uploads are signed with the X-Signature-Key, X-Signature-Timestamp (Unix seconds),
+// @Description X-Signature-Nonce and X-Signature headers. The signature is v1= followed by the hex encoded HMAC-SHA256, keyed with
+// @Description the secret of the signing key, of the lines v1, the method, the path, the timestamp, the nonce and the hex encoded
+// @Description SHA-256 of the raw body, joined with newlines. Signatures are accepted within a window around their timestamp and once.
+// @Description Requests are rate limited per client address, then submissions per organization or per credential. Accepted uploads
+// @Description count against daily quotas of bytes and of credit transfers, rejected ones do not; limited requests are answered 429
+// @Description with Retry-After and RateLimit headers.
 // @Tags transfers
 // @Accept multipart/form-data
+// @Accept json
+// @Accept text/csv
+// @Accept xml
+// @Accept application/vnd.swift.mt101
 // @Produce json
-// @Param file formData file true "JSON file containing bulk transfer details"
+// @Param file formData file false "JSON, CSV, pain.001 XML or MT101 file containing bulk transfer details (multipart/form-data only)"
+// @Param request body BulkTransferFileContent false "Bulk transfer details (application/json only)"
+// @Description Errors are returned as application/problem+json, see /problems for the catalog of problem types.
 // @Success 201 {object} BulkTransferResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 422 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials, or a missing or invalid signature when uploads are signed"
+// @Failure 403 {object} Problem "The organization IBAN is not an account of the authenticated organization, or the caller may not submit transfers from it"
+// @Failure 409 {object} Problem
+// @Failure 413 {object} Problem
+// @Failure 415 {object} Problem
+// @Failure 422 {object} Problem "Insufficient funds problems carry required_cents and available_cents, rejected batches carry batch_id"
+// @Failure 429 {object} Problem "Rate limit or daily quota of lines or bytes exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
 // @Router /transfers [post]
 func (api *apiDetails) BulkTransfer(c *gin.Context) {
-	logger := api.logger.With("handler", "BulkTransfer")
+	logger := api.logger.With("handler", "BulkTransfer", "request_id", requestIDFrom(c))
 	logger.Info("Starting bulk transfer process")
 
-	file, _, err := c.Request.FormFile("file")
-	if err != nil {
-		logger.Error("Failed to retrieve file from request", "error", err)
-		createErrorResponse(c, http.StatusBadRequest, "Error retrieving the file")
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, api.maxUploadBytes)
+
+	var file io.ReadSeeker
+	mediaType := c.ContentType()
+	if mediaType == gin.MIMEMultipartPOSTForm {
+		formFile, header, err := c.Request.FormFile("file")
+		if err != nil {
+			if isBodyTooLarge(err) {
+				logger.Error("Bulk transfer upload exceeds maximum size", "max_upload_bytes", api.maxUploadBytes)
+				createErrorResponse(c, payloadTooLargeProblem(api.maxUploadBytes))
+				return
+			}
+			logger.Error("Failed to retrieve file from request", "error", err)
+			createErrorResponse(c, newProblem(problemInvalidRequest, "Error retrieving the file"))
+			return
+		}
+		defer formFile.Close()
+
+		file = formFile
+		mediaType = fileMediaType(header)
+	}
+
+	decode, ok := bulkTransferDecoders[mediaType]
+	if !ok {
+		logger.Error("Unsupported bulk transfer media type", "media_type", mediaType)
+		createErrorResponse(c, unsupportedMediaTypeProblem(mediaType))
 		return
 	}
-	defer file.Close()
 
-	fileContent, err := io.ReadAll(file)
+	if signed, ok := signedBody(c); ok && file == nil {
+		// The body of signed requests is already kept in a temporary file
+		file = signed
+	}
+	if file == nil {
+		// The request body can only be read once, but the document is read again
+		// while the transfers are executed, so it is kept in a temporary file.
+		tempFile, err := spoolToTempFile(c.Request.Body)
+		if err != nil {
+			if isBodyTooLarge(err) {
+				logger.Error("Bulk transfer request exceeds maximum size", "max_upload_bytes", api.maxUploadBytes)
+				createErrorResponse(c, payloadTooLargeProblem(api.maxUploadBytes))
+				return
+			}
+			logger.Error("Failed to read request body", "error", err)
+			createErrorResponse(c, newProblem(problemInvalidRequest, "Error reading request body"))
+			return
+		}
+		defer removeTempFile(tempFile)
+
+		file = tempFile
+	}
+
+	size, err := uploadSize(file)
 	if err != nil {
-		logger.Error("Failed to read file content", "error", err)
-		createErrorResponse(c, http.StatusBadRequest, "Error reading file content")
+		logger.Error("Failed to get upload size", "error", err)
+		createErrorResponse(c, newProblem(problemInternalError, "Error reading request body"))
 		return
 	}
 
-	var bulkTransferContent BulkTransferFileContent
-	err = json.Unmarshal(fileContent, &bulkTransferContent)
+	// First pass: validate the whole document before anything is executed
+	request, transferCount, err := newBulkTransferRequest(file, decode)
 	if err != nil {
-		logger.Error("Failed to parse JSON content", "error", err)
-		createErrorResponse(c, http.StatusBadRequest, "Error parsing JSON content")
+		if isBodyTooLarge(err) {
+			logger.Error("Bulk transfer upload exceeds maximum size", "max_upload_bytes", api.maxUploadBytes)
+			createErrorResponse(c, payloadTooLargeProblem(api.maxUploadBytes))
+			return
+		}
+		problem := documentProblem(err)
+		logger.Error("Invalid bulk transfer content", "error", err, "code", problem.Code, "media_type", mediaType)
+		createErrorResponse(c, problem)
 		return
 	}
 
-	if err := validate.Struct(bulkTransferContent); err != nil {
-		logger.Error("Invalid bulk transfer request structure", "error", err)
-		createErrorResponse(c, http.StatusBadRequest, "Invalid request")
+	// Only the uploads accepted by the service count against the daily quotas, the
+	// reservation is refunded when the service rejects the batch
+	reservation, ok := api.reserveQuotas(c, int64(transferCount), size)
+	if !ok {
 		return
 	}
 
 	logger.Info("Processing bulk transfer request",
-		"organization", bulkTransferContent.OrganizationName,
-		"transferCount", len(bulkTransferContent.CreditTransfers))
-
-	// Convert BulkTransferFileContent to service.BulkTransferRequest
-	request := service.BulkTransferRequest{
-		OrganizationName: bulkTransferContent.OrganizationName,
-		OrganizationBIC:  bulkTransferContent.OrganizationBIC,
-		OrganizationIBAN: bulkTransferContent.OrganizationIBAN,
-		Transfers:        make([]transfer.Transfer, len(bulkTransferContent.CreditTransfers)),
-	}
+		"organization", request.OrganizationName,
+		"transferCount", transferCount)
 
-	for i, ct := range bulkTransferContent.CreditTransfers {
-		amount, err := parseAmount(ct.Amount)
-		if err != nil {
-			logger.Error("Invalid amount for transfer",
-				"error", err,
-				"counterparty", ct.CounterpartyName,
-				"amount", ct.Amount)
-			createErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("Invalid amount for transfer to %s", ct.CounterpartyName))
-			return
-		}
-		request.Transfers[i] = transfer.Transfer{
-			AmountCents:      amount,
-			CounterpartyName: ct.CounterpartyName,
-			CounterpartyBIC:  ct.CounterpartyBIC,
-			CounterpartyIBAN: ct.CounterpartyIBAN,
-			Description:      ct.Description,
-		}
-	}
-
-	err = api.service.BulkTransfer(c.Request.Context(), request)
+	b, err := api.service.BulkTransfer(c.Request.Context(), request)
 	if err != nil {
-		if errors.Is(err, service.ErrInsufficientFunds) {
-			logger.Warn("Insufficient funds for bulk transfer",
+		api.refundQuotas(c, reservation)
+		problem := ServiceProblem(err, "Error processing bulk transfer")
+		if b != nil {
+			problem.With("batch_id", b.ID)
+		}
+		if problem.Status < http.StatusInternalServerError {
+			logger.Warn("Bulk transfer rejected",
 				"error", err,
-				"organization", bulkTransferContent.OrganizationName)
-			createErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+				"code", problem.Code,
+				"organization", request.OrganizationName)
 		} else {
 			logger.Error("Failed to process bulk transfer",
 				"error", err,
-				"organization", bulkTransferContent.OrganizationName)
-			createErrorResponse(c, http.StatusInternalServerError, "Error processing bulk transfer")
+				"organization", request.OrganizationName)
 		}
+		createErrorResponse(c, problem)
 		return
 	}
 
 	logger.Info("Bulk transfer processed successfully",
-		"organization", bulkTransferContent.OrganizationName,
-		"transferCount", len(bulkTransferContent.CreditTransfers))
+		"organization", request.OrganizationName,
+		"transferCount", transferCount,
+		"batch_id", b.ID)
 	c.JSON(http.StatusCreated, BulkTransferResponse{
 		Message: "Bulk transfer processed successfully",
+		BatchID: b.ID,
+	})
+}
+
+// uploadSize returns the size of an upload and rewinds it
+func uploadSize(file io.ReadSeeker) (int64, error) {
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// unsupportedMediaTypeProblem reports a bulk transfer document of a format that is not supported
+func unsupportedMediaTypeProblem(mediaType string) *Problem {
+	return newProblem(problemUnsupportedMediaType, fmt.Sprintf("Unsupported media type %q", mediaType)).
+		With("supported_media_types", supportedMediaTypes())
+}
+
+// payloadTooLargeProblem reports an upload exceeding the maximum upload size
+func payloadTooLargeProblem(maxUploadBytes int64) *Problem {
+	return newProblem(problemPayloadTooLarge, fmt.Sprintf("The request body exceeds the maximum size of %d bytes", maxUploadBytes)).
+		With("max_upload_bytes", maxUploadBytes)
+}
+
+// checkCreditTransfer validates a credit transfer of a bulk transfer document,
+// line being its position in the document
+func checkCreditTransfer(line int, ct CreditTransfer) error {
+	if err := validate.Struct(ct); err != nil {
+		return &creditTransferError{line: line, transfer: ct, err: err, field: invalidField(err)}
+	}
+	if _, err := parseAmount(ct.Amount); err != nil {
+		return &creditTransferError{line: line, transfer: ct, err: err, field: "amount", invalidAmount: true}
+	}
+	return nil
+}
+
+// headerProblem validates the header of a bulk transfer document once all its transfers
+// have been read, and returns the problem it has if any
+func headerProblem(header *BulkTransferHeader, transferCount int) *Problem {
+	if err := validate.Struct(header); err != nil {
+		return newProblem(problemInvalidRequest, err.Error())
+	}
+	if transferCount == 0 {
+		return newProblem(problemInvalidRequest, "The request contains no credit transfers")
+	}
+	return nil
+}
+
+// documentProblem maps an error of the decoding or of the validation of a bulk transfer
+// document onto the problem catalog
+func documentProblem(err error) *Problem {
+	var problem *Problem
+	var ctErr *creditTransferError
+	var csvErr *csvError
+	var schemaErr *schemaError
+	switch {
+	case errors.As(err, &problem):
+		return problem
+	case errors.As(err, &ctErr) && ctErr.invalidAmount:
+		return withSchemaViolations(withCSVPosition(newProblem(problemInvalidAmount, fmt.Sprintf("Invalid amount for transfer to %s: %v", ctErr.transfer.CounterpartyName, ctErr.err)).
+			With("line", ctErr.line), err), err)
+	case errors.As(err, &ctErr) && errors.As(err, &csvErr):
+		return withCSVPosition(newProblem(problemInvalidTransfer, fmt.Sprintf("Invalid credit transfer at row %d, column %s: %v", csvErr.row, csvErr.column, ctErr.err)).
+			With("line", ctErr.line), err)
+	case errors.As(err, &ctErr):
+		return withSchemaViolations(newProblem(problemInvalidTransfer, fmt.Sprintf("Invalid credit transfer at line %d: %v", ctErr.line, ctErr.err)).
+			With("line", ctErr.line), err)
+	case errors.As(err, &schemaErr):
+		return withSchemaViolations(newProblem(problemInvalidRequest, err.Error()), err)
+	case errors.As(err, &csvErr):
+		return withCSVPosition(newProblem(problemInvalidContent, "Failed to parse CSV content at "+err.Error()), err)
+	default:
+		return newProblem(problemInvalidContent, err.Error())
+	}
+}
+
+// creditTransferError reports an invalid credit transfer of a bulk transfer document
+type creditTransferError struct {
+	line     int
+	transfer CreditTransfer
+	err      error
+	// field is the document field of the credit transfer that is invalid, if known
+	field         string
+	invalidAmount bool
+}
+
+func (e *creditTransferError) Error() string {
+	return fmt.Sprintf("invalid credit transfer at line %d: %v", e.line, e.err)
+}
+
+func (e *creditTransferError) Unwrap() error {
+	return e.err
+}
+
+// invalidField returns the document field of the first validation error of a credit transfer
+func invalidField(err error) string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) || len(validationErrs) == 0 {
+		return ""
+	}
+	field, ok := reflect.TypeOf(CreditTransfer{}).FieldByName(validationErrs[0].StructField())
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+// withCSVPosition adds the row and column of a CSV document error to the problem
+func withCSVPosition(p *Problem, err error) *Problem {
+	var csvErr *csvError
+	if !errors.As(err, &csvErr) {
+		return p
+	}
+	p.With("row", csvErr.row)
+	if csvErr.column != "" {
+		p.With("column", csvErr.column)
+	}
+	return p
+}
+
+// withSchemaViolations adds the schema version and the violations of a JSON document error to the problem
+func withSchemaViolations(p *Problem, err error) *Problem {
+	var schemaErr *schemaError
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &schemaErr) || !errors.As(err, &validationErr) {
+		return p
+	}
+	return p.With("schema_version", schemaErr.version).
+		With("violations", validationErr.Violations)
+}
+
+// NewBulkTransferRequest validates a bulk transfer file of the given media type like
+// POST /transfers does, and returns the request that executes it along with its number
+// of credit transfers. The service reads the transfers from file again as it executes
+// them. Invalid files are reported by a *Problem error.
+func NewBulkTransferRequest(file io.ReadSeeker, mediaType string) (service.BulkTransferRequest, int, error) {
+	decode, ok := bulkTransferDecoders[mediaType]
+	if !ok {
+		return service.BulkTransferRequest{}, 0, unsupportedMediaTypeProblem(mediaType)
+	}
+	request, transferCount, err := newBulkTransferRequest(file, decode)
+	if err != nil {
+		return service.BulkTransferRequest{}, 0, documentProblem(err)
+	}
+	return request, transferCount, nil
+}
+
+// newBulkTransferRequest validates the whole document in a first pass, and returns the request
+// that streams its transfers from the document again, along with their number.
+// An invalid header is reported by a *Problem error, other errors are those of the decoding.
+func newBulkTransferRequest(file io.ReadSeeker, decode bulkTransferDecoder) (service.BulkTransferRequest, int, error) {
+	var transferCount int
+	header, err := decode(file, func(ct CreditTransfer) error {
+		transferCount++
+		return checkCreditTransfer(transferCount, ct)
 	})
+	if err != nil {
+		return service.BulkTransferRequest{}, 0, err
+	}
+	if problem := headerProblem(header, transferCount); problem != nil {
+		return service.BulkTransferRequest{}, 0, problem
+	}
+
+	return service.BulkTransferRequest{
+		OrganizationName: header.OrganizationName,
+		OrganizationBIC:  header.OrganizationBIC,
+		OrganizationIBAN: header.OrganizationIBAN,
+		MessageID:        header.MessageID,
+		MessageName:      header.MessageName,
+		Source:           creditTransferSource(file, decode),
+	}, transferCount, nil
+}
+
+// creditTransferSource returns a service.TransferSource that decodes the credit
+// transfers of file from the start every time it is called
+func creditTransferSource(file io.ReadSeeker, decode bulkTransferDecoder) service.TransferSource {
+	return func(yield func(transfer.Transfer) error) error {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind bulk transfer content: %w", err)
+		}
+		_, err := decode(file, func(ct CreditTransfer) error {
+			amount, err := parseAmount(ct.Amount)
+			if err != nil {
+				return err
+			}
+			return yield(transfer.Transfer{
+				AmountCents:          amount,
+				CounterpartyName:     ct.CounterpartyName,
+				CounterpartyBIC:      ct.CounterpartyBIC,
+				CounterpartyIBAN:     ct.CounterpartyIBAN,
+				Description:          ct.Description,
+				EndToEndID:           ct.EndToEndID,
+				PaymentInformationID: ct.PaymentInformationID,
+			})
+		})
+		return err
+	}
 }
 
 // parseAmount converts a string amount to int64 cents
@@ -2,14 +2,20 @@ package rest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"strings"
 	"testing"
 
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/transfer"
 	"moneytransfer/mock"
 
 	"github.com/gin-gonic/gin"
@@ -18,6 +24,22 @@ import (
 	gomock "go.uber.org/mock/gomock"
 )
 
+// problemResponse is the decoded body of a problem+json response
+type problemResponse struct {
+	Type           string `json:"type"`
+	Title          string `json:"title"`
+	Status         int    `json:"status"`
+	Detail         string `json:"detail"`
+	Instance       string `json:"instance"`
+	Code           string `json:"code"`
+	Line           int    `json:"line"`
+	Row            int    `json:"row"`
+	Column         string `json:"column"`
+	RequiredCents  int64  `json:"required_cents"`
+	AvailableCents int64  `json:"available_cents"`
+	BatchID        int64  `json:"batch_id"`
+}
+
 func TestBulkTransfer(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -47,11 +69,12 @@ func TestBulkTransfer(t *testing.T) {
 			setupMock: func(mockService *mock.TransferServiceMock) {
 				mockService.EXPECT().
 					BulkTransfer(gomock.Any(), gomock.Any()).
-					Return(nil)
+					Return(&batch.Batch{ID: 42, Status: batch.StatusExecuted}, nil)
 			},
 			expectedStatusCode: http.StatusCreated,
 			expectedResponse: BulkTransferResponse{
 				Message: "Bulk transfer processed successfully",
+				BatchID: 42,
 			},
 		},
 		{
@@ -61,8 +84,9 @@ func TestBulkTransfer(t *testing.T) {
 			}`,
 			setupMock:          func(mockService *mock.TransferServiceMock) {},
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse: ErrorResponse{
-				Message: "Invalid request",
+			expectedResponse: problemResponse{
+				Code:  "invalid_request",
+				Title: "Invalid request",
 			},
 		},
 		{
@@ -83,8 +107,12 @@ func TestBulkTransfer(t *testing.T) {
 			}`,
 			setupMock:          func(mockService *mock.TransferServiceMock) {},
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse: ErrorResponse{
-				Message: "Invalid amount for transfer to John Doe",
+			expectedResponse: problemResponse{
+				Code:  "invalid_amount",
+				Title: "Invalid credit transfer amount",
+				Detail: "Invalid amount for transfer to John Doe: document does not match version 1 of the bulk transfer schema: " +
+					"/credit_transfers/0/amount: must match the pattern \"^[0-9]+(\\\\.[0-9]{1,2})?$\" (schema /$defs/credit_transfer/properties/amount/pattern)",
+				Line: 1,
 			},
 		},
 		{
@@ -106,11 +134,44 @@ func TestBulkTransfer(t *testing.T) {
 			setupMock: func(mockService *mock.TransferServiceMock) {
 				mockService.EXPECT().
 					BulkTransfer(gomock.Any(), gomock.Any()).
-					Return(fmt.Errorf("insufficient funds"))
+					Return(&batch.Batch{ID: 42, Status: batch.StatusFailed}, &service.InsufficientFundsError{RequiredCents: 100000, AvailableCents: 5000})
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: problemResponse{
+				BatchID:        42,
+				Code:           "insufficient_funds",
+				Title:          "Insufficient funds",
+				Detail:         "insufficient funds: 100000 cents required, 5000 cents available",
+				RequiredCents:  100000,
+				AvailableCents: 5000,
+			},
+		},
+		{
+			name: "Internal error",
+			fileContent: `{
+				"organization_name": "Test Org",
+				"organization_bic": "TESTBIC1",
+				"organization_iban": "TEST123456789",
+				"credit_transfers": [
+					{
+						"amount": "1000.00",
+						"counterparty_name": "John Doe",
+						"counterparty_bic": "JOHNDOEBIC",
+						"counterparty_iban": "JOHNDOE987654321",
+						"description": "Test transfer"
+					}
+				]
+			}`,
+			setupMock: func(mockService *mock.TransferServiceMock) {
+				mockService.EXPECT().
+					BulkTransfer(gomock.Any(), gomock.Any()).
+					Return(nil, fmt.Errorf("connection refused"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
-			expectedResponse: ErrorResponse{
-				Message: "Error processing bulk transfer",
+			expectedResponse: problemResponse{
+				Code:   "internal_error",
+				Title:  "Internal server error",
+				Detail: "Error processing bulk transfer",
 			},
 		},
 	}
@@ -127,8 +188,9 @@ func TestBulkTransfer(t *testing.T) {
 			validate = validator.New()
 
 			api := &apiDetails{
-				service: mockService,
-				logger:  slog.Default(),
+				service:        mockService,
+				logger:         slog.Default(),
+				maxUploadBytes: defaultMaxUploadBytes,
 			}
 
 			router := gin.New()
@@ -155,9 +217,17 @@ func TestBulkTransfer(t *testing.T) {
 				json.Unmarshal(w.Body.Bytes(), &bulkTransferResponse)
 				response = bulkTransferResponse
 			} else {
-				var errorResponse ErrorResponse
-				json.Unmarshal(w.Body.Bytes(), &errorResponse)
-				response = errorResponse
+				assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+				var problem problemResponse
+				json.Unmarshal(w.Body.Bytes(), &problem)
+				assert.Equal(t, "/api/v1/problems/"+problem.Code, problem.Type)
+				assert.Equal(t, tt.expectedStatusCode, problem.Status)
+				assert.Equal(t, "/transfers", problem.Instance)
+				problem.Type, problem.Status, problem.Instance = "", 0, ""
+				if tt.expectedResponse.(problemResponse).Detail == "" {
+					problem.Detail = ""
+				}
+				response = problem
 			}
 
 			assert.Equal(t, tt.expectedResponse, response)
@@ -165,6 +235,350 @@ func TestBulkTransfer(t *testing.T) {
 	}
 }
 
+func TestBulkTransfer_UntypedFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	content := `{
+		"organization_name": "Test Org",
+		"organization_bic": "TESTBIC1",
+		"organization_iban": "TEST123456789",
+		"credit_transfers": [
+			{
+				"amount": "100.50",
+				"counterparty_name": "John Doe",
+				"counterparty_bic": "JOHNDOEBIC",
+				"counterparty_iban": "JOHNDOE987654321",
+				"description": "Test transfer"
+			}
+		]
+	}`
+
+	// Uploaded files whose part type and extension name no supported format are decoded as JSON
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+	}{
+		{"Octet stream without extension", "payroll", "application/octet-stream"},
+		{"Plain text file", "payroll.txt", "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mock.NewTransferServiceMock(ctrl)
+			mockService.EXPECT().BulkTransfer(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, req service.BulkTransferRequest) (*batch.Batch, error) {
+					transfers := collectTransfers(t, req)
+					assert.Len(t, transfers, 1)
+					return &batch.Batch{ID: 42, Status: batch.StatusExecuted}, nil
+				})
+
+			validate = validator.New()
+			api := &apiDetails{service: mockService, logger: slog.Default(), maxUploadBytes: defaultMaxUploadBytes}
+			router := gin.New()
+			router.POST("/transfers", api.BulkTransfer)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, tt.filename))
+			header.Set("Content-Type", tt.contentType)
+			part, _ := writer.CreatePart(header)
+			part.Write([]byte(content))
+			writer.Close()
+
+			req, _ := http.NewRequest(http.MethodPost, "/transfers", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		})
+	}
+}
+
+// collectTransfers reads all transfers of a bulk transfer request
+func collectTransfers(t *testing.T, req service.BulkTransferRequest) []transfer.Transfer {
+	t.Helper()
+	var transfers []transfer.Transfer
+	err := req.Source(func(tr transfer.Transfer) error {
+		transfers = append(transfers, tr)
+		return nil
+	})
+	assert.NoError(t, err)
+	return transfers
+}
+
+func TestNewBulkTransferRequest(t *testing.T) {
+	content := "organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,counterparty_iban,description\n" +
+		"Test Org,TESTBIC1,TEST123456789,100.50,John Doe,JOHNDOEBIC,JOHNDOE987654321,Test transfer\n"
+
+	t.Run("Valid file", func(t *testing.T) {
+		req, count, err := NewBulkTransferRequest(strings.NewReader(content), csvMediaType)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, "TEST123456789", req.OrganizationIBAN)
+		transfers := collectTransfers(t, req)
+		assert.Len(t, transfers, 1)
+		assert.Equal(t, int64(10050), transfers[0].AmountCents)
+	})
+
+	t.Run("Invalid file", func(t *testing.T) {
+		_, _, err := NewBulkTransferRequest(strings.NewReader(strings.Replace(content, "100.50", "abc", 1)), csvMediaType)
+		var problem *Problem
+		assert.ErrorAs(t, err, &problem)
+		assert.Equal(t, "invalid_amount", problem.Code)
+		assert.Equal(t, 2, problem.Extensions["row"])
+	})
+
+	t.Run("Unsupported media type", func(t *testing.T) {
+		_, _, err := NewBulkTransferRequest(strings.NewReader(content), "text/plain")
+		var problem *Problem
+		assert.ErrorAs(t, err, &problem)
+		assert.Equal(t, "unsupported_media_type", problem.Code)
+	})
+}
+
+func TestBulkTransfer_RequestBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validContent := `{
+		"organization_name": "Test Org",
+		"organization_bic": "TESTBIC1",
+		"organization_iban": "TEST123456789",
+		"credit_transfers": [
+			{
+				"amount": "100.50",
+				"counterparty_name": "John Doe",
+				"counterparty_bic": "JOHNDOEBIC",
+				"counterparty_iban": "JOHNDOE987654321",
+				"description": "Test transfer"
+			}
+		]
+	}`
+
+	tests := []struct {
+		name               string
+		contentType        string
+		body               string
+		maxUploadBytes     int64
+		setupMock          func(*mock.TransferServiceMock)
+		expectedStatusCode int
+		expectedCode       string
+		expectedRow        int
+		expectedColumn     string
+	}{
+		{
+			name:        "Successful bulk transfer with JSON body",
+			contentType: "application/json",
+			body:        validContent,
+			setupMock: func(mockService *mock.TransferServiceMock) {
+				mockService.EXPECT().
+					BulkTransfer(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, req service.BulkTransferRequest) (*batch.Batch, error) {
+						assert.Equal(t, "TEST123456789", req.OrganizationIBAN)
+						transfers := collectTransfers(t, req)
+						assert.Len(t, transfers, 1)
+						assert.Equal(t, int64(10050), transfers[0].AmountCents)
+						return &batch.Batch{ID: 1}, nil
+					})
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:        "JSON body with charset parameter",
+			contentType: "application/json; charset=utf-8",
+			body:        validContent,
+			setupMock: func(mockService *mock.TransferServiceMock) {
+				mockService.EXPECT().BulkTransfer(gomock.Any(), gomock.Any()).Return(&batch.Batch{ID: 1}, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "Malformed JSON body",
+			contentType:        "application/json",
+			body:               `{"organization_name": `,
+			setupMock:          func(mockService *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_content",
+		},
+		{
+			name:        "Invalid credit transfer",
+			contentType: "application/json",
+			body: `{
+				"organization_name": "Test Org",
+				"organization_bic": "TESTBIC1",
+				"organization_iban": "TEST123456789",
+				"credit_transfers": [
+					{"amount": "1", "counterparty_name": "A", "counterparty_bic": "B", "counterparty_iban": "C", "description": "D"},
+					{"amount": "1", "counterparty_name": "A"}
+				]
+			}`,
+			setupMock:          func(mockService *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_transfer",
+		},
+		{
+			name:        "CSV amount with a decimal comma",
+			contentType: "text/csv",
+			body: "\ufefforganization_name;organization_bic;organization_iban;amount;counterparty_name;counterparty_bic;counterparty_iban;description\n" +
+				"Test Org;TESTBIC1;TEST123456789;100,50;John Doe;JOHNDOEBIC;JOHNDOE987654321;Test transfer\n",
+			setupMock:          func(mockService *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_amount",
+			expectedRow:        2,
+			expectedColumn:     "amount",
+		},
+		{
+			name:        "CSV body ends in the same request as JSON",
+			contentType: "text/csv; charset=utf-8",
+			body: "organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,counterparty_iban,description\n" +
+				"Test Org,TESTBIC1,TEST123456789,100.50,John Doe,JOHNDOEBIC,JOHNDOE987654321,Test transfer\n",
+			setupMock: func(mockService *mock.TransferServiceMock) {
+				mockService.EXPECT().
+					BulkTransfer(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, req service.BulkTransferRequest) (*batch.Batch, error) {
+						assert.Equal(t, "Test Org", req.OrganizationName)
+						assert.Equal(t, "TESTBIC1", req.OrganizationBIC)
+						assert.Equal(t, "TEST123456789", req.OrganizationIBAN)
+						assert.Equal(t, []transfer.Transfer{{
+							AmountCents:      10050,
+							CounterpartyName: "John Doe",
+							CounterpartyBIC:  "JOHNDOEBIC",
+							CounterpartyIBAN: "JOHNDOE987654321",
+							Description:      "Test transfer",
+						}}, collectTransfers(t, req))
+						return &batch.Batch{ID: 1}, nil
+					})
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:        "Valid pain.001 body",
+			contentType: "application/xml",
+			body: pain001Document("1", strings.Replace(pain001Block("1", "TEST123456789", "Test Org", "EUR", "100.50"),
+				"<IBAN>JOHNIBAN</IBAN>", "<IBAN>JOHNDOE987654321</IBAN>", 1)),
+			setupMock: func(mockService *mock.TransferServiceMock) {
+				mockService.EXPECT().
+					BulkTransfer(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, req service.BulkTransferRequest) (*batch.Batch, error) {
+						assert.Equal(t, "Test Org", req.OrganizationName)
+						assert.Equal(t, "TESTBIC1", req.OrganizationBIC)
+						assert.Equal(t, "TEST123456789", req.OrganizationIBAN)
+						assert.Equal(t, "MSG-1", req.MessageID)
+						assert.Equal(t, "pain.001.001.03", req.MessageName)
+						assert.Equal(t, []transfer.Transfer{{
+							AmountCents:          10050,
+							CounterpartyName:     "John Doe",
+							CounterpartyBIC:      "JOHNBIC",
+							CounterpartyIBAN:     "JOHNDOE987654321",
+							Description:          "Invoice 1",
+							EndToEndID:           "E2E-1",
+							PaymentInformationID: "1",
+						}}, collectTransfers(t, req))
+						return &batch.Batch{ID: 1}, nil
+					})
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:        "pain.001 body with multiple debtor accounts",
+			contentType: "text/xml",
+			body: pain001Document("2",
+				pain001Block("1", "TEST123456789", "Test Org", "EUR", "1"),
+				pain001Block("2", "OTHER123456789", "Test Org", "EUR", "1")),
+			setupMock:          func(mockService *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_content",
+		},
+		{
+			name:        "Invalid CSV credit transfer",
+			contentType: "text/csv",
+			body: "organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,counterparty_iban,description\n" +
+				"Test Org,TESTBIC1,TEST123456789,1,A,B,C,D\n" +
+				"Test Org,TESTBIC1,TEST123456789,1,,B,C,D\n",
+			setupMock:          func(mockService *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_transfer",
+			expectedRow:        3,
+			expectedColumn:     "counterparty_name",
+		},
+		{
+			name:        "Malformed CSV body",
+			contentType: "text/csv",
+			body: "organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,counterparty_iban,description\n" +
+				"Test Org,TESTBIC1\n",
+			setupMock:          func(mockService *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_content",
+			expectedRow:        2,
+		},
+		{
+			name:               "Request body too large",
+			contentType:        "application/json",
+			body:               validContent,
+			maxUploadBytes:     64,
+			setupMock:          func(mockService *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedCode:       "payload_too_large",
+		},
+		{
+			name:               "Unsupported media type",
+			contentType:        "text/plain",
+			body:               validContent,
+			setupMock:          func(mockService *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+			expectedCode:       "unsupported_media_type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock.NewTransferServiceMock(ctrl)
+			tt.setupMock(mockService)
+
+			validate = validator.New()
+
+			api := &apiDetails{
+				service:        mockService,
+				logger:         slog.Default(),
+				maxUploadBytes: defaultMaxUploadBytes,
+			}
+			if tt.maxUploadBytes != 0 {
+				api.maxUploadBytes = tt.maxUploadBytes
+			}
+
+			router := gin.New()
+			router.POST("/transfers", api.BulkTransfer)
+
+			req, _ := http.NewRequest("POST", "/transfers", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+
+			if tt.expectedCode == "" {
+				var response BulkTransferResponse
+				json.Unmarshal(w.Body.Bytes(), &response)
+				assert.Equal(t, "Bulk transfer processed successfully", response.Message)
+				return
+			}
+
+			var problem problemResponse
+			json.Unmarshal(w.Body.Bytes(), &problem)
+			assert.Equal(t, tt.expectedCode, problem.Code)
+			assert.Equal(t, tt.expectedRow, problem.Row)
+			assert.Equal(t, tt.expectedColumn, problem.Column)
+		})
+	}
+}
+
 func TestParseAmount(t *testing.T) {
 	tests := []struct {
 		name    string
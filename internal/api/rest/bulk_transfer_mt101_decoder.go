@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"moneytransfer/internal/swift"
+)
+
+// mt101MediaType is the media type of bulk transfer documents made of SWIFT MT101 messages
+const mt101MediaType = "application/vnd.swift.mt101"
+
+// decodeMT101BulkTransfer decodes a bulk transfer document made of one or more SWIFT MT101
+// request for transfer messages, with or without their FIN header and trailer blocks.
+// The ordering customer, given once per message or in every transaction, is the organization
+// of the bulk transfer and every transaction is a credit transfer. The transactions must all
+// debit the same account.
+func decodeMT101BulkTransfer(r io.Reader, yield func(CreditTransfer) error) (*BulkTransferHeader, error) {
+	var header *BulkTransferHeader
+	var debtorTransaction string
+	messages, err := swift.ReadMT101(r, func(msg *swift.MT101, tx *swift.MT101Transaction) error {
+		orderingCustomer := msg.OrderingCustomer
+		if orderingCustomer == nil {
+			orderingCustomer = tx.OrderingCustomer
+		}
+		switch {
+		case header == nil:
+			header, debtorTransaction = mt101DebtorHeader(msg, orderingCustomer), tx.Reference
+		case orderingCustomer.Account != header.OrganizationIBAN:
+			return fmt.Errorf("transaction %q debits %s but transaction %q debits %s, a bulk transfer debits a single account",
+				tx.Reference, orderingCustomer.Account, debtorTransaction, header.OrganizationIBAN)
+		}
+
+		if tx.Currency != sepaCurrency {
+			return fmt.Errorf("transaction %q is in %q, only %s transfers are supported", tx.Reference, tx.Currency, sepaCurrency)
+		}
+
+		return yield(CreditTransfer{
+			Amount:               tx.Amount,
+			CounterpartyName:     tx.Beneficiary.Name,
+			CounterpartyBIC:      tx.AccountWithInstitution,
+			CounterpartyIBAN:     tx.Beneficiary.Account,
+			Description:          tx.RemittanceInformation,
+			EndToEndID:           tx.Reference,
+			PaymentInformationID: msg.SenderReference,
+		})
+	})
+	if err != nil {
+		var ctErr *creditTransferError
+		if errors.As(err, &ctErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid MT101 message: %w", err)
+	}
+
+	header.MessageID = messages[0].SenderReference
+	header.MessageName = swift.MessageTypeMT101
+	return header, nil
+}
+
+// mt101DebtorHeader returns the organization of the bulk transfer from the ordering customer.
+// Its BIC is given by option G of the ordering customer or else by the account servicing institution.
+func mt101DebtorHeader(msg *swift.MT101, orderingCustomer *swift.Party) *BulkTransferHeader {
+	bic := orderingCustomer.BIC
+	if bic == "" {
+		bic = msg.AccountServicingInstitution
+	}
+	return &BulkTransferHeader{
+		OrganizationName: orderingCustomer.Name,
+		OrganizationBIC:  bic,
+		OrganizationIBAN: orderingCustomer.Account,
+	}
+}
@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mt101Message builds a text block only MT101 message whose ordering customer is given in
+// sequence A, with the given transactions
+func mt101Message(reference, iban string, transactions ...string) string {
+	return ":20:" + reference + "\r\n" +
+		":28D:1/1\r\n" +
+		":50H:/" + iban + "\r\n" +
+		"TEST ORG\r\n" +
+		":52A:TESTBIC1\r\n" +
+		":30:240304\r\n" +
+		strings.Join(transactions, "") +
+		"-\r\n"
+}
+
+// mt101Transaction builds a transaction of an MT101 message
+func mt101Transaction(reference, amount string) string {
+	return ":21:" + reference + "\r\n" +
+		":32B:" + amount + "\r\n" +
+		":57A:JOHNBIC1\r\n" +
+		":59:/JOHNIBAN\r\n" +
+		"JOHN DOE\r\n" +
+		":70:INVOICE " + reference + "\r\n" +
+		":71A:SHA\r\n"
+}
+
+func TestDecodeMT101BulkTransfer(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantHeader    *BulkTransferHeader
+		wantTransfers []CreditTransfer
+		wantErr       string
+	}{
+		{
+			name: "Messages debiting the same account",
+			content: mt101Message("MSG-1", "TEST123456789", mt101Transaction("E2E-1", "EUR1,5"), mt101Transaction("E2E-2", "EUR2,")) +
+				mt101Message("MSG-2", "TEST123456789", mt101Transaction("E2E-3", "EUR0,01")),
+			wantHeader: &BulkTransferHeader{OrganizationName: "TEST ORG", OrganizationBIC: "TESTBIC1", OrganizationIBAN: "TEST123456789", MessageID: "MSG-1", MessageName: "MT101"},
+			wantTransfers: []CreditTransfer{
+				{Amount: "1.5", CounterpartyName: "JOHN DOE", CounterpartyBIC: "JOHNBIC1", CounterpartyIBAN: "JOHNIBAN", Description: "INVOICE E2E-1", EndToEndID: "E2E-1", PaymentInformationID: "MSG-1"},
+				{Amount: "2", CounterpartyName: "JOHN DOE", CounterpartyBIC: "JOHNBIC1", CounterpartyIBAN: "JOHNIBAN", Description: "INVOICE E2E-2", EndToEndID: "E2E-2", PaymentInformationID: "MSG-1"},
+				{Amount: "0.01", CounterpartyName: "JOHN DOE", CounterpartyBIC: "JOHNBIC1", CounterpartyIBAN: "JOHNIBAN", Description: "INVOICE E2E-3", EndToEndID: "E2E-3", PaymentInformationID: "MSG-2"},
+			},
+		},
+		{
+			name: "Ordering customer identified by a BIC in every transaction",
+			content: strings.Replace(mt101Message("MSG-1", "TEST123456789", strings.Replace(mt101Transaction("E2E-1", "EUR1,"), ":57A:", ":50G:/TEST123456789\r\nTESTBIC2\r\n:57A:", 1)),
+				":50H:/TEST123456789\r\nTEST ORG\r\n", "", 1),
+			wantHeader: &BulkTransferHeader{OrganizationBIC: "TESTBIC2", OrganizationIBAN: "TEST123456789", MessageID: "MSG-1", MessageName: "MT101"},
+			wantTransfers: []CreditTransfer{
+				{Amount: "1", CounterpartyName: "JOHN DOE", CounterpartyBIC: "JOHNBIC1", CounterpartyIBAN: "JOHNIBAN", Description: "INVOICE E2E-1", EndToEndID: "E2E-1", PaymentInformationID: "MSG-1"},
+			},
+		},
+		{
+			name: "Multiple debtor accounts",
+			content: mt101Message("MSG-1", "TEST123456789", mt101Transaction("E2E-1", "EUR1,")) +
+				mt101Message("MSG-2", "OTHER123456789", mt101Transaction("E2E-2", "EUR1,")),
+			wantErr: `transaction "E2E-2" debits OTHER123456789 but transaction "E2E-1" debits TEST123456789, a bulk transfer debits a single account`,
+		},
+		{
+			name:    "Foreign currency",
+			content: mt101Message("MSG-1", "TEST123456789", mt101Transaction("E2E-1", "USD1,")),
+			wantErr: `transaction "E2E-1" is in "USD", only EUR transfers are supported`,
+		},
+		{
+			name:    "Invalid field",
+			content: mt101Message("MSG-1", "TEST123456789", mt101Transaction("E2E-1", "EUR1.00")),
+			wantErr: `invalid MT101 message: line 8: field :32B: expected a currency code followed by an amount with a decimal comma, got "EUR1.00"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var transfers []CreditTransfer
+			header, err := decodeMT101BulkTransfer(strings.NewReader(tt.content), func(ct CreditTransfer) error {
+				transfers = append(transfers, ct)
+				return nil
+			})
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHeader, header)
+			assert.Equal(t, tt.wantTransfers, transfers)
+		})
+	}
+}
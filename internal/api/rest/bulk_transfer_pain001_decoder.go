@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"moneytransfer/internal/iso20022"
+)
+
+// sepaCurrency is the only currency of the accounts and transfers of the service
+const sepaCurrency = "EUR"
+
+// decodePain001BulkTransfer decodes a bulk transfer document in the ISO 20022
+// pain.001.001.03 or pain.001.001.09 format.
+// The debtor of the payment information blocks is the organization of the bulk transfer,
+// and every credit transfer transaction is a credit transfer. A message may hold several
+// payment information blocks, but they must all debit the same account.
+func decodePain001BulkTransfer(r io.Reader, yield func(CreditTransfer) error) (*BulkTransferHeader, error) {
+	var header *BulkTransferHeader
+	var debtorBlock string
+	var checked *iso20022.PaymentInformation
+	pain001, err := iso20022.ReadPain001(r, func(pmtInf *iso20022.PaymentInformation, tx *iso20022.CreditTransferTransaction) error {
+		// The transactions of a block are streamed with the same block, which is checked once
+		if pmtInf != checked {
+			h, err := pain001DebtorHeader(pmtInf)
+			if err != nil {
+				return err
+			}
+			switch {
+			case header == nil:
+				header, debtorBlock = h, pmtInf.ID
+			case h.OrganizationIBAN != header.OrganizationIBAN:
+				return fmt.Errorf("payment information %q debits %s but payment information %q debits %s, a bulk transfer debits a single account",
+					pmtInf.ID, h.OrganizationIBAN, debtorBlock, header.OrganizationIBAN)
+			}
+			checked = pmtInf
+		}
+
+		if currency := tx.Amount.Instructed.Currency; currency != sepaCurrency {
+			return fmt.Errorf("transaction %q is in %q, only %s transfers are supported", tx.PaymentID.EndToEndID, currency, sepaCurrency)
+		}
+		if tx.CreditorAccount.ID.IBAN == "" && tx.CreditorAccount.ID.Other != nil {
+			return fmt.Errorf("the creditor account of transaction %q is not identified by an IBAN, which is not supported", tx.PaymentID.EndToEndID)
+		}
+
+		return yield(CreditTransfer{
+			Amount:               strings.TrimSpace(tx.Amount.Instructed.Value),
+			CounterpartyName:     strings.TrimSpace(tx.Creditor.Name),
+			CounterpartyBIC:      strings.TrimSpace(tx.CreditorAgent.FinancialInstitution.Code()),
+			CounterpartyIBAN:     strings.TrimSpace(tx.CreditorAccount.ID.IBAN),
+			Description:          tx.RemittanceInformation.String(),
+			EndToEndID:           strings.TrimSpace(tx.PaymentID.EndToEndID),
+			PaymentInformationID: pmtInf.ID,
+		})
+	})
+	if err != nil {
+		var ctErr *creditTransferError
+		if errors.As(err, &ctErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid pain.001 message: %w", err)
+	}
+
+	// The organization name falls back to the initiating party
+	if header.OrganizationName == "" {
+		header.OrganizationName = strings.TrimSpace(pain001.GroupHeader.InitiatingParty.Name)
+	}
+	header.MessageID = strings.TrimSpace(pain001.GroupHeader.MessageID)
+	header.MessageName = iso20022.MessageName(pain001.Namespace)
+	return header, nil
+}
+
+// pain001DebtorHeader checks that the payment information block is supported
+// and returns the organization of the bulk transfer from its debtor
+func pain001DebtorHeader(pmtInf *iso20022.PaymentInformation) (*BulkTransferHeader, error) {
+	if pmtInf.PaymentMethod != iso20022.PaymentMethodTransfer {
+		return nil, fmt.Errorf("payment information %q has payment method %q, only %s is supported", pmtInf.ID, pmtInf.PaymentMethod, iso20022.PaymentMethodTransfer)
+	}
+	if pmtInf.DebtorAccount.ID.IBAN == "" {
+		return nil, fmt.Errorf("the debtor account of payment information %q is not identified by an IBAN, which is not supported", pmtInf.ID)
+	}
+	if currency := pmtInf.DebtorAccount.Currency; currency != "" && currency != sepaCurrency {
+		return nil, fmt.Errorf("the debtor account of payment information %q is in %q, only %s accounts are supported", pmtInf.ID, currency, sepaCurrency)
+	}
+
+	return &BulkTransferHeader{
+		OrganizationName: strings.TrimSpace(pmtInf.Debtor.Name),
+		OrganizationBIC:  strings.TrimSpace(pmtInf.DebtorAgent.FinancialInstitution.Code()),
+		OrganizationIBAN: strings.TrimSpace(pmtInf.DebtorAccount.ID.IBAN),
+	}, nil
+}
@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pain001Document builds a pain.001.001.03 document with the given payment information blocks
+func pain001Document(numberOfTransactions string, blocks ...string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>MSG-1</MsgId>
+      <CreDtTm>2024-03-01T10:00:00</CreDtTm>
+      <NbOfTxs>` + numberOfTransactions + `</NbOfTxs>
+      <InitgPty><Nm>Test Org</Nm></InitgPty>
+    </GrpHdr>` + strings.Join(blocks, "") + `
+  </CstmrCdtTrfInitn>
+</Document>`
+}
+
+// pain001Block builds a payment information block debiting iban with a single transaction
+func pain001Block(id, iban, debtorName, currency, amount string) string {
+	return `
+    <PmtInf>
+      <PmtInfId>` + id + `</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <ReqdExctnDt>2024-03-04</ReqdExctnDt>
+      <Dbtr><Nm>` + debtorName + `</Nm></Dbtr>
+      <DbtrAcct><Id><IBAN>` + iban + `</IBAN></Id></DbtrAcct>
+      <DbtrAgt><FinInstnId><BIC>TESTBIC1</BIC></FinInstnId></DbtrAgt>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-` + id + `</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="` + currency + `">` + amount + `</InstdAmt></Amt>
+        <CdtrAgt><FinInstnId><BIC>JOHNBIC</BIC></FinInstnId></CdtrAgt>
+        <Cdtr><Nm>John Doe</Nm></Cdtr>
+        <CdtrAcct><Id><IBAN>JOHNIBAN</IBAN></Id></CdtrAcct>
+        <RmtInf><Ustrd>Invoice ` + id + `</Ustrd></RmtInf>
+      </CdtTrfTxInf>
+    </PmtInf>`
+}
+
+func TestDecodePain001BulkTransfer(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantHeader    *BulkTransferHeader
+		wantTransfers []CreditTransfer
+		wantErr       string
+	}{
+		{
+			name: "Blocks debiting the same account",
+			content: pain001Document("2",
+				pain001Block("1", "TEST123456789", "Test Org", "EUR", "1.50"),
+				pain001Block("2", "TEST123456789", "Test Org", "EUR", "2")),
+			wantHeader: &BulkTransferHeader{OrganizationName: "Test Org", OrganizationBIC: "TESTBIC1", OrganizationIBAN: "TEST123456789", MessageID: "MSG-1", MessageName: "pain.001.001.03"},
+			wantTransfers: []CreditTransfer{
+				{Amount: "1.50", CounterpartyName: "John Doe", CounterpartyBIC: "JOHNBIC", CounterpartyIBAN: "JOHNIBAN", Description: "Invoice 1", EndToEndID: "E2E-1", PaymentInformationID: "1"},
+				{Amount: "2", CounterpartyName: "John Doe", CounterpartyBIC: "JOHNBIC", CounterpartyIBAN: "JOHNIBAN", Description: "Invoice 2", EndToEndID: "E2E-2", PaymentInformationID: "2"},
+			},
+		},
+		{
+			name:       "Organization name falls back to the initiating party",
+			content:    pain001Document("1", pain001Block("1", "TEST123456789", "", "EUR", "1")),
+			wantHeader: &BulkTransferHeader{OrganizationName: "Test Org", OrganizationBIC: "TESTBIC1", OrganizationIBAN: "TEST123456789", MessageID: "MSG-1", MessageName: "pain.001.001.03"},
+			wantTransfers: []CreditTransfer{
+				{Amount: "1", CounterpartyName: "John Doe", CounterpartyBIC: "JOHNBIC", CounterpartyIBAN: "JOHNIBAN", Description: "Invoice 1", EndToEndID: "E2E-1", PaymentInformationID: "1"},
+			},
+		},
+		{
+			name: "Multiple debtor accounts",
+			content: pain001Document("2",
+				pain001Block("1", "TEST123456789", "Test Org", "EUR", "1"),
+				pain001Block("2", "OTHER123456789", "Test Org", "EUR", "1")),
+			wantErr: `payment information "2" debits OTHER123456789 but payment information "1" debits TEST123456789, a bulk transfer debits a single account`,
+		},
+		{
+			name:    "Foreign currency",
+			content: pain001Document("1", pain001Block("1", "TEST123456789", "Test Org", "USD", "1")),
+			wantErr: `transaction "E2E-1" is in "USD", only EUR transfers are supported`,
+		},
+		{
+			name:    "Unsupported payment method",
+			content: strings.Replace(pain001Document("1", pain001Block("1", "TEST123456789", "Test Org", "EUR", "1")), "<PmtMtd>TRF", "<PmtMtd>CHK", 1),
+			wantErr: `payment information "1" has payment method "CHK", only TRF is supported`,
+		},
+		{
+			name: "Debtor account without IBAN",
+			content: strings.Replace(pain001Document("1", pain001Block("1", "TEST123456789", "Test Org", "EUR", "1")),
+				"<IBAN>TEST123456789</IBAN>", "<Othr><Id>12345</Id></Othr>", 1),
+			wantErr: `the debtor account of payment information "1" is not identified by an IBAN`,
+		},
+		{
+			name:    "Transaction count mismatch",
+			content: pain001Document("2", pain001Block("1", "TEST123456789", "Test Org", "EUR", "1")),
+			wantErr: "invalid pain.001 message: group header: number of transactions is 2 but 1 transactions were found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var transfers []CreditTransfer
+			header, err := decodePain001BulkTransfer(strings.NewReader(tt.content), func(ct CreditTransfer) error {
+				transfers = append(transfers, ct)
+				return nil
+			})
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHeader, header)
+			assert.Equal(t, tt.wantTransfers, transfers)
+		})
+	}
+}
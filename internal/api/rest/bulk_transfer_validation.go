@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"moneytransfer/internal/jsonschema"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/tools"
+)
+
+// BulkTransferFileReport is the outcome of the validation of a bulk transfer file
+type BulkTransferFileReport struct {
+	MediaType        string `json:"media_type"`
+	OrganizationName string `json:"organization_name,omitempty"`
+	OrganizationBIC  string `json:"organization_bic,omitempty"`
+	OrganizationIBAN string `json:"organization_iban,omitempty"`
+	MessageID        string `json:"message_id,omitempty"`
+	// Transfers counts the credit transfers read, TotalCents sums the amounts of the valid ones
+	Transfers  int   `json:"transfers"`
+	TotalCents int64 `json:"total_cents"`
+	Valid      bool  `json:"valid"`
+	// ErrorCount counts the errors found, Errors lists them up to the maximum number of reported errors
+	ErrorCount int                     `json:"error_count"`
+	Errors     []BulkTransferFileError `json:"errors"`
+}
+
+// BulkTransferFileError is an error of a bulk transfer file, with the problem code the API rejects it with
+type BulkTransferFileError struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+	// Line is the position of the credit transfer in the file
+	Line int `json:"line,omitempty"`
+	// Row and Column locate the error in a CSV file
+	Row    int    `json:"row,omitempty"`
+	Column string `json:"column,omitempty"`
+	// Violations are the violations of the schema of a JSON file
+	Violations []jsonschema.Violation `json:"violations,omitempty"`
+}
+
+// ValidateBulkTransferFile validates a bulk transfer file of the given media type without executing it.
+//
+// The file is decoded and checked like the first pass of POST /transfers, except that
+// the validation carries on after an invalid credit transfer so that all of them are
+// reported; errors in the format of the file still stop it. The IBANs and BICs of the
+// organization and of the counterparties are also checked, which the API leaves to the
+// sending bank. At most maxErrors errors are listed, all of them when it is zero.
+// An error is only returned when the media type is not supported.
+func ValidateBulkTransferFile(r io.Reader, mediaType string, maxErrors int) (*BulkTransferFileReport, error) {
+	decode, ok := bulkTransferDecoders[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported media type %q, expected one of %s", mediaType, strings.Join(supportedMediaTypes(), ", "))
+	}
+
+	report := &BulkTransferFileReport{MediaType: mediaType, Errors: []BulkTransferFileError{}}
+	add := func(p *Problem) {
+		report.ErrorCount++
+		if maxErrors == 0 || len(report.Errors) < maxErrors {
+			report.Errors = append(report.Errors, fileError(p))
+		}
+	}
+
+	overflow := false
+	header, err := decode(r, func(ct CreditTransfer) error {
+		report.Transfers++
+		line := report.Transfers
+		checkErr := checkCreditTransfer(line, ct)
+		if checkErr != nil {
+			add(documentProblem(checkErr))
+		}
+		// Missing accounts are reported by checkCreditTransfer
+		if ct.CounterpartyIBAN != "" {
+			if err := tools.ValidateIBAN(ct.CounterpartyIBAN); err != nil {
+				add(documentProblem(&creditTransferError{line: line, transfer: ct, err: err, field: "counterparty_iban"}))
+			}
+		}
+		if ct.CounterpartyBIC != "" {
+			if err := tools.ValidateBIC(ct.CounterpartyBIC); err != nil {
+				add(documentProblem(&creditTransferError{line: line, transfer: ct, err: err, field: "counterparty_bic"}))
+			}
+		}
+		if checkErr != nil {
+			return nil
+		}
+
+		amount, _ := parseAmount(ct.Amount)
+		if report.TotalCents > math.MaxInt64-amount {
+			if !overflow {
+				overflow = true
+				add(newProblem(problemAmountOverflow, service.ErrAmountOverflow.Error()).With("line", line))
+			}
+			return nil
+		}
+		report.TotalCents += amount
+		return nil
+	})
+	if err != nil {
+		add(documentProblem(err))
+		return report, nil
+	}
+
+	report.OrganizationName = header.OrganizationName
+	report.OrganizationBIC = header.OrganizationBIC
+	report.OrganizationIBAN = header.OrganizationIBAN
+	report.MessageID = header.MessageID
+	if p := headerProblem(header, report.Transfers); p != nil {
+		add(p)
+	}
+	if header.OrganizationIBAN != "" {
+		if err := tools.ValidateIBAN(header.OrganizationIBAN); err != nil {
+			add(newProblem(problemInvalidRequest, "Invalid organization IBAN: "+err.Error()))
+		}
+	}
+	if header.OrganizationBIC != "" {
+		if err := tools.ValidateBIC(header.OrganizationBIC); err != nil {
+			add(newProblem(problemInvalidRequest, "Invalid organization BIC: "+err.Error()))
+		}
+	}
+
+	report.Valid = report.ErrorCount == 0
+	return report, nil
+}
+
+// fileError returns the error of a bulk transfer file reported by a problem
+func fileError(p *Problem) BulkTransferFileError {
+	fe := BulkTransferFileError{Code: p.Code, Detail: p.Detail}
+	fe.Line, _ = p.Extensions["line"].(int)
+	fe.Row, _ = p.Extensions["row"].(int)
+	fe.Column, _ = p.Extensions["column"].(string)
+	fe.Violations, _ = p.Extensions["violations"].([]jsonschema.Violation)
+	return fe
+}
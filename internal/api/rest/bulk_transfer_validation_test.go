@@ -0,0 +1,90 @@
+package rest
+
+import (
+	"strings"
+	"testing"
+
+	"moneytransfer/internal/jsonschema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBulkTransferFile(t *testing.T) {
+	t.Run("Valid file", func(t *testing.T) {
+		content := "organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,counterparty_iban,description\n" +
+			"Acme,BNPAFRPP,FR1420041010050500013M02606,100.50,John Doe,DEUTDEFF,DE89370400440532013000,Salary\n" +
+			"Acme,,,2,Jane Doe,ABNANL2A,NL91ABNA0417164300,Salary\n"
+
+		report, err := ValidateBulkTransferFile(strings.NewReader(content), csvMediaType, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, &BulkTransferFileReport{
+			MediaType:        csvMediaType,
+			OrganizationName: "Acme",
+			OrganizationBIC:  "BNPAFRPP",
+			OrganizationIBAN: "FR1420041010050500013M02606",
+			Transfers:        2,
+			TotalCents:       10250,
+			Valid:            true,
+			Errors:           []BulkTransferFileError{},
+		}, report)
+	})
+
+	t.Run("Every invalid transfer is reported", func(t *testing.T) {
+		content := `{
+			"organization_name": "Acme",
+			"organization_bic": "BNPAFRPP",
+			"organization_iban": "FR10474608000002006107XXXXX",
+			"credit_transfers": [
+				{"amount": "1", "counterparty_name": "A", "counterparty_bic": "DEUTDEFF", "counterparty_iban": "DE89370400440532013000", "description": "D"},
+				{"amount": "1", "counterparty_name": "A", "counterparty_bic": "BIC", "counterparty_iban": "DE88370400440532013000", "description": "D"},
+				{"amount": "2", "counterparty_name": "A", "counterparty_bic": "DEUTDEFF", "counterparty_iban": "DE89370400440532013000", "description": "D"}
+			]
+		}`
+
+		report, err := ValidateBulkTransferFile(strings.NewReader(content), "application/json", 0)
+		assert.NoError(t, err)
+		assert.False(t, report.Valid)
+		assert.Equal(t, 3, report.Transfers)
+		assert.Equal(t, int64(400), report.TotalCents)
+		assert.Equal(t, []BulkTransferFileError{
+			{Code: "invalid_transfer", Detail: `Invalid credit transfer at line 2: invalid IBAN "DE88370400440532013000": wrong check digits`, Line: 2},
+			{Code: "invalid_transfer", Detail: `Invalid credit transfer at line 2: invalid BIC "BIC": expected 8 or 11 letters or digits, the first 6 being letters`, Line: 2},
+			{Code: "invalid_request", Detail: `Invalid organization IBAN: invalid IBAN "FR10474608000002006107XXXXX": wrong check digits`},
+		}, report.Errors)
+		assert.Equal(t, 3, report.ErrorCount)
+	})
+
+	t.Run("Schema violation stops the validation", func(t *testing.T) {
+		content := `{"credit_transfers": [{"amount": "1,5", "counterparty_name": "A", "counterparty_bic": "DEUTDEFF", "counterparty_iban": "DE89370400440532013000", "description": "D"}]}`
+
+		report, err := ValidateBulkTransferFile(strings.NewReader(content), "application/json", 0)
+		assert.NoError(t, err)
+		assert.False(t, report.Valid)
+		assert.Len(t, report.Errors, 1)
+		assert.Equal(t, "invalid_amount", report.Errors[0].Code)
+		assert.Equal(t, 1, report.Errors[0].Line)
+		assert.Equal(t, []jsonschema.Violation{{
+			InstancePath: "/credit_transfers/0/amount",
+			SchemaPath:   "/$defs/credit_transfer/properties/amount/pattern",
+			Message:      `must match the pattern "^[0-9]+(\\.[0-9]{1,2})?$"`,
+		}}, report.Errors[0].Violations)
+	})
+
+	t.Run("Errors are capped", func(t *testing.T) {
+		content := "organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,counterparty_iban,description\n" +
+			"Acme,BNPAFRPP,FR1420041010050500013M02606,x,John Doe,DEUTDEFF,DE89370400440532013000,Salary\n" +
+			"Acme,,,y,John Doe,DEUTDEFF,DE89370400440532013000,Salary\n" +
+			"Acme,,,z,John Doe,DEUTDEFF,DE89370400440532013000,Salary\n"
+
+		report, err := ValidateBulkTransferFile(strings.NewReader(content), csvMediaType, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.ErrorCount)
+		assert.Len(t, report.Errors, 2)
+		assert.Equal(t, "invalid_amount", report.Errors[1].Code)
+	})
+
+	t.Run("Unsupported media type", func(t *testing.T) {
+		_, err := ValidateBulkTransferFile(strings.NewReader(""), "text/plain", 0)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"net/http"
+
+	"moneytransfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BrokenLinkResponse represents the first broken link of a hash chain
+type BrokenLinkResponse struct {
+	// Seq is the sequence of the link in the chain, 0 for a transfer without a link
+	Seq        int64 `json:"seq"`
+	TransferID int64 `json:"transfer_id"`
+	// Reason is one of sequence_gap, previous_hash_mismatch, transfer_missing, transfer_account_mismatch,
+	// hash_mismatch, balance_mismatch, account_balance_mismatch or unchained_transfer
+	Reason string `json:"reason"`
+	Detail string `json:"detail"`
+}
+
+// ChainVerificationResponse represents the verification of the hash chain of an account
+type ChainVerificationResponse struct {
+	IBAN string `json:"iban"`
+	// Valid is set when every link of the chain was verified
+	Valid bool `json:"valid"`
+	// Links counts the links verified, up to the broken one
+	Links int `json:"links"`
+	// Head is the hash of the last link verified
+	Head   string              `json:"head,omitempty"`
+	Broken *BrokenLinkResponse `json:"broken,omitempty"`
+}
+
+func newChainVerificationResponse(r *service.ChainReport) ChainVerificationResponse {
+	resp := ChainVerificationResponse{IBAN: r.IBAN, Valid: r.Broken == nil, Links: r.Links, Head: r.Head}
+	if r.Broken != nil {
+		resp.Broken = &BrokenLinkResponse{
+			Seq:        r.Broken.Seq,
+			TransferID: r.Broken.TransferID,
+			Reason:     string(r.Broken.Reason),
+			Detail:     r.Broken.Detail,
+		}
+	}
+	return resp
+}
+
+// VerifyChain godoc
+// @Summary Verify the hash chain of an account
+// @Description Recomputes the SHA-256 hash chain of the debits and credits of the account, which links each
+// @Description transfer and return to the balance it left and to the previous link, and reports the first
+// @Description broken link: a transfer, a link or the balance edited in the database outside of the service.
+// @Tags accounts
+// @Produce json
+// @Param iban query string true "IBAN of the account"
+// @Success 200 {object} ChainVerificationResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 422 {object} Problem
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Router /chain/verification [get]
+func (api *apiDetails) VerifyChain(c *gin.Context) {
+	logger := api.logger.With("handler", "VerifyChain", "request_id", requestIDFrom(c))
+
+	iban := c.Query("iban")
+	if iban == "" {
+		createErrorResponse(c, newProblem(problemInvalidRequest, "The iban query parameter is required"))
+		return
+	}
+
+	report, err := api.chains.Verify(c.Request.Context(), iban)
+	if err != nil {
+		problem := ServiceProblem(err, "Error verifying hash chain")
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("Failed to verify hash chain", "error", err, "iban", iban)
+		}
+		createErrorResponse(c, problem)
+		return
+	}
+	c.JSON(http.StatusOK, newChainVerificationResponse(report))
+}
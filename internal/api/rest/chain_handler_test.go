@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/service"
+	"moneytransfer/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestVerifyChain(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const iban = "FR1420041010050500013M02606"
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMock          func(*mock.ChainServiceMock)
+		expectedStatusCode int
+		expectedCode       string
+		expectedBody       string
+	}{
+		{
+			name:  "Intact chain",
+			query: "?iban=" + iban,
+			setupMock: func(m *mock.ChainServiceMock) {
+				m.EXPECT().Verify(gomock.Any(), iban).Return(&service.ChainReport{IBAN: iban, Links: 3, Head: "abc"}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"iban":"` + iban + `","valid":true,"links":3,"head":"abc"}`,
+		},
+		{
+			name:  "Broken chain",
+			query: "?iban=" + iban,
+			setupMock: func(m *mock.ChainServiceMock) {
+				m.EXPECT().Verify(gomock.Any(), iban).Return(&service.ChainReport{
+					IBAN:   iban,
+					Links:  1,
+					Head:   "abc",
+					Broken: &service.BrokenLink{Seq: 2, TransferID: 7, Reason: service.ChainBreakHash, Detail: "link or transfer 7 was modified"},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"iban":"` + iban + `","valid":false,"links":1,"head":"abc",
+				"broken":{"seq":2,"transfer_id":7,"reason":"hash_mismatch","detail":"link or transfer 7 was modified"}}`,
+		},
+		{
+			name:               "Missing IBAN",
+			setupMock:          func(m *mock.ChainServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+		{
+			name:  "Denied",
+			query: "?iban=" + iban,
+			setupMock: func(m *mock.ChainServiceMock) {
+				m.EXPECT().Verify(gomock.Any(), iban).Return(nil, fmt.Errorf("%w: api_key:7 may not manage account %s", authz.ErrDenied, iban))
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedCode:       service.CodePermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mock.NewChainServiceMock(ctrl)
+			tt.setupMock(mockService)
+
+			api := &apiDetails{chains: mockService, logger: slog.Default()}
+			router := gin.New()
+			router.GET("/chain/verification", api.VerifyChain)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/chain/verification"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+				return
+			}
+			var problem problemResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedCode, problem.Code)
+		})
+	}
+}
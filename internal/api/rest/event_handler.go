@@ -0,0 +1,193 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"moneytransfer/internal/event"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// lastEventIDHeader is sent by SSE clients when they reconnect
+	lastEventIDHeader = "Last-Event-ID"
+
+	// eventPageSize is the maximum number of events read from the database at once
+	eventPageSize = 100
+
+	// eventRetryMillis is the reconnection delay suggested to SSE clients
+	eventRetryMillis = 3000
+
+	// defaultHeartbeatInterval is the interval of SSE heartbeats unless configured otherwise
+	defaultHeartbeatInterval = 15 * time.Second
+
+	// defaultEventPollInterval is the interval at which the event stream looks for events
+	// without being notified, e.g. events written by another instance
+	defaultEventPollInterval = time.Second
+)
+
+// EventResponse represents an event of the event stream, sent as the data of an SSE message
+type EventResponse struct {
+	// Seq is the position of the event in the event sequence, sent as the SSE id
+	Seq int64 `json:"seq"`
+	// Type is one of batch.received, batch.executed or batch.failed, sent as the SSE event
+	Type             string    `json:"type"`
+	OrganizationName string    `json:"organization_name"`
+	OrganizationIBAN string    `json:"organization_iban"`
+	BatchID          int64     `json:"batch_id,omitempty"`
+	TransferID       int64     `json:"transfer_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	// Data is the state of the batch or transfer after the change
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+func newEventResponse(e event.Event) EventResponse {
+	return EventResponse{
+		Seq:              e.Seq,
+		Type:             string(e.Type),
+		OrganizationName: e.OrganizationName,
+		OrganizationIBAN: e.OrganizationIBAN,
+		BatchID:          e.BatchID,
+		TransferID:       e.TransferID,
+		CreatedAt:        e.CreatedAt,
+		Data:             e.Data,
+	}
+}
+
+// StreamEvents godoc
+// @Summary Stream status changes
+// @Description Streams the status changes of batches and transfers as Server-Sent Events.
+// @Description The stream is scoped to an organization, a bank account or both, at least one is required.
+// @Description Every message carries the event sequence as its id, so a client that reconnects with
+// @Description the Last-Event-ID header resumes after the last event it received.
+// @Description Comments are sent as heartbeats while there are no events.
+// @Tags events
+// @Produce text/event-stream
+// @Param organization query string false "Organization name"
+// @Param iban query string false "Organization IBAN"
+// @Param last_event_id query int false "Resume after this event, for clients that cannot send the Last-Event-ID header"
+// @Param Last-Event-ID header int false "Resume after this event"
+// @Success 200 {object} EventResponse "Stream of events"
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Security ApiKeyAuth
+// @Router /events [get]
+func (api *apiDetails) StreamEvents(c *gin.Context) {
+	logger := api.logger.With("handler", "StreamEvents", "request_id", requestIDFrom(c))
+
+	filter := event.Filter{
+		OrganizationName: c.Query("organization"),
+		OrganizationIBAN: c.Query("iban"),
+	}
+	if err := filter.Validate(); err != nil {
+		createErrorResponse(c, newProblem(problemInvalidRequest, "The organization or iban query parameter is required"))
+		return
+	}
+
+	lastSeq, err := lastEventID(c)
+	if err != nil {
+		createErrorResponse(c, newProblem(problemInvalidRequest, err.Error()))
+		return
+	}
+
+	// Subscribe before the first read so that no notification is missed in between,
+	// the subscription is denied when the principal may not read the events of the filter
+	notify, unsubscribe, err := api.events.Subscribe(c.Request.Context(), filter)
+	if err != nil {
+		problem := ServiceProblem(err, "Error subscribing to events")
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("Failed to subscribe to events", "error", err)
+		}
+		createErrorResponse(c, problem)
+		return
+	}
+	defer unsubscribe()
+
+	ctx := c.Request.Context()
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetryMillis)
+	w.Flush()
+
+	logger.Info("Event stream opened", "organization", filter.OrganizationName, "organization_iban", filter.OrganizationIBAN, "last_event_id", lastSeq)
+
+	heartbeat := time.NewTicker(api.heartbeatInterval)
+	defer heartbeat.Stop()
+	poll := time.NewTicker(api.eventPollInterval)
+	defer poll.Stop()
+
+	for {
+		for {
+			events, err := api.events.ListEvents(ctx, filter, lastSeq, eventPageSize)
+			if err != nil {
+				if ctx.Err() == nil {
+					// The client reconnects and resumes from the last event it received
+					logger.Error("Failed to list events", "error", err)
+				}
+				return
+			}
+			for _, e := range events {
+				if err := writeEvent(w, e); err != nil {
+					logger.Error("Failed to write event", "error", err)
+					return
+				}
+				lastSeq = e.Seq
+			}
+			if len(events) < eventPageSize {
+				break
+			}
+		}
+		w.Flush()
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Event stream closed by client", "last_event_id", lastSeq)
+			return
+		case <-api.shutdown:
+			logger.Info("Event stream closed by server shutdown", "last_event_id", lastSeq)
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case <-notify:
+		case <-poll.C:
+		}
+	}
+}
+
+// lastEventID returns the sequence of the last event the client received, zero if none
+func lastEventID(c *gin.Context) (int64, error) {
+	value := c.GetHeader(lastEventIDHeader)
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	seq, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || seq < 0 {
+		return 0, fmt.Errorf("invalid last event ID %q", value)
+	}
+	return seq, nil
+}
+
+// writeEvent writes e as an SSE message
+func writeEvent(w gin.ResponseWriter, e event.Event) error {
+	data, err := json.Marshal(newEventResponse(e))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, data)
+	return err
+}
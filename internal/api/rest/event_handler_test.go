@@ -0,0 +1,215 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/event"
+	"moneytransfer/internal/service"
+	"moneytransfer/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// streamEvents serves an event stream request until the handler returns
+// and returns the recorded response. stop is called once the stream has started.
+func streamEvents(api *apiDetails, req *http.Request, stop func()) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.GET("/events", api.StreamEvents)
+
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.ServeHTTP(w, req)
+	}()
+	stop()
+	<-done
+	return w
+}
+
+func TestStreamEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	executed := event.Event{
+		Seq:              6,
+		Type:             event.TypeBatchExecuted,
+		OrganizationName: "Test Org",
+		OrganizationIBAN: "TEST123456789",
+		BatchID:          42,
+		Data:             json.RawMessage(`{"id":42,"status":"executed"}`),
+	}
+
+	t.Run("Resumes after the last event ID", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockEvents := mock.NewEventServiceMock(ctrl)
+		filter := event.Filter{OrganizationName: "Test Org"}
+
+		written := make(chan struct{})
+		mockEvents.EXPECT().Subscribe(gomock.Any(), gomock.Any()).Return(make(chan struct{}), func() {}, nil)
+		mockEvents.EXPECT().ListEvents(gomock.Any(), filter, int64(5), eventPageSize).DoAndReturn(
+			func(context.Context, event.Filter, int64, int) ([]event.Event, error) {
+				close(written)
+				return []event.Event{executed}, nil
+			})
+
+		api := &apiDetails{
+			events:            mockEvents,
+			logger:            slog.Default(),
+			heartbeatInterval: time.Hour,
+			eventPollInterval: time.Hour,
+			shutdown:          make(chan struct{}),
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "/events?organization=Test+Org", nil)
+		req.Header.Set(lastEventIDHeader, "5")
+		w := streamEvents(api, req, func() {
+			<-written
+			api.closeEventStreams()
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "retry: 3000\n\n")
+		assert.Contains(t, w.Body.String(), "id: 6\nevent: batch.executed\ndata: {")
+		assert.Contains(t, w.Body.String(), `"data":{"id":42,"status":"executed"}`)
+	})
+
+	t.Run("Reads new events when notified", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockEvents := mock.NewEventServiceMock(ctrl)
+		filter := event.Filter{OrganizationIBAN: "TEST123456789"}
+
+		notify := make(chan struct{}, 1)
+		written := make(chan struct{})
+		mockEvents.EXPECT().Subscribe(gomock.Any(), gomock.Any()).Return(notify, func() {}, nil)
+		gomock.InOrder(
+			mockEvents.EXPECT().ListEvents(gomock.Any(), filter, int64(0), eventPageSize).DoAndReturn(
+				func(context.Context, event.Filter, int64, int) ([]event.Event, error) {
+					notify <- struct{}{}
+					return nil, nil
+				}),
+			mockEvents.EXPECT().ListEvents(gomock.Any(), filter, int64(0), eventPageSize).DoAndReturn(
+				func(context.Context, event.Filter, int64, int) ([]event.Event, error) {
+					close(written)
+					return []event.Event{executed}, nil
+				}),
+		)
+
+		api := &apiDetails{
+			events:            mockEvents,
+			logger:            slog.Default(),
+			heartbeatInterval: time.Hour,
+			eventPollInterval: time.Hour,
+			shutdown:          make(chan struct{}),
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/events?iban=TEST123456789", nil)
+		w := streamEvents(api, req, func() {
+			<-written
+			cancel()
+		})
+
+		assert.Contains(t, w.Body.String(), "id: 6\nevent: batch.executed\n")
+	})
+
+	t.Run("Sends heartbeats", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockEvents := mock.NewEventServiceMock(ctrl)
+
+		mockEvents.EXPECT().Subscribe(gomock.Any(), gomock.Any()).Return(make(chan struct{}), func() {}, nil)
+		mockEvents.EXPECT().ListEvents(gomock.Any(), gomock.Any(), int64(0), eventPageSize).Return(nil, nil).AnyTimes()
+
+		api := &apiDetails{
+			events:            mockEvents,
+			logger:            slog.Default(),
+			heartbeatInterval: 5 * time.Millisecond,
+			eventPollInterval: time.Hour,
+			shutdown:          make(chan struct{}),
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "/events?organization=Test+Org", nil)
+		w := streamEvents(api, req, func() {
+			time.Sleep(50 * time.Millisecond)
+			api.closeEventStreams()
+		})
+
+		assert.Contains(t, w.Body.String(), ": heartbeat\n\n")
+	})
+
+	t.Run("Events of another organization are denied", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockEvents := mock.NewEventServiceMock(ctrl)
+		filter := event.Filter{OrganizationName: "Other Org"}
+		mockEvents.EXPECT().Subscribe(gomock.Any(), filter).Return(nil, nil, fmt.Errorf("%w: events of organization Other Org", service.ErrPermissionDenied))
+
+		api := &apiDetails{events: mockEvents, logger: slog.Default()}
+		router := gin.New()
+		router.GET("/events", api.StreamEvents)
+
+		req, _ := http.NewRequest(http.MethodGet, "/events?organization=Other+Org", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), service.CodePermissionDenied)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			url         string
+			lastEventID string
+		}{
+			{name: "Missing scope", url: "/events"},
+			{name: "Invalid Last-Event-ID header", url: "/events?organization=Test+Org", lastEventID: "abc"},
+			{name: "Negative last_event_id", url: "/events?organization=Test+Org&last_event_id=-1"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				api := &apiDetails{events: mock.NewEventServiceMock(gomock.NewController(t)), logger: slog.Default()}
+				router := gin.New()
+				router.GET("/events", api.StreamEvents)
+
+				req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+				if tt.lastEventID != "" {
+					req.Header.Set(lastEventIDHeader, tt.lastEventID)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+			})
+		}
+	})
+}
+
+func TestGracefulStopServer_EndsEventStreams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	api, err := NewApi(slog.Default(), mock.NewTransferServiceMock(ctrl), "0",
+		WithEventService(mock.NewEventServiceMock(ctrl)),
+		WithAPIKeyService(mock.NewAPIKeyServiceMock(ctrl)))
+	assert.NoError(t, err)
+
+	details := api.(*apiDetails)
+	api.GracefulStopServer()
+	api.GracefulStopServer()
+
+	select {
+	case <-details.shutdown:
+	default:
+		t.Fatal("expected event streams to be ended")
+	}
+}
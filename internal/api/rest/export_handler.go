@@ -0,0 +1,143 @@
+package rest
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/transfer"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportContentTypes maps the export formats to the content type of their response
+var exportContentTypes = map[service.ExportFormat]string{
+	service.ExportFormatCSV:    "text/csv; charset=utf-8",
+	service.ExportFormatNDJSON: "application/x-ndjson",
+}
+
+// ExportTransfers godoc
+// @Summary Export the transfers of an account
+// @Description Streams the transfers of the account created from `from` to `to` included, in UTC, by creation time,
+// @Description as CSV with a header row or as newline delimited JSON. The transfers are read through a server-side cursor
+// @Description from a single snapshot, so the export is consistent even while bulk transfers are being executed,
+// @Description and is never held in memory. The response is gzip compressed when the request accepts the gzip encoding.
+// @Tags transfers
+// @Produce plain
+// @Param iban query string true "IBAN of the account"
+// @Param format query string false "Format of the export" Enums(csv, ndjson) default(csv)
+// @Param from query string false "First day of the transfers (YYYY-MM-DD)"
+// @Param to query string false "Last day of the transfers (YYYY-MM-DD)"
+// @Param status query string false "Status of the transfers" Enums(executed, returned)
+// @Param batch_id query int false "Batch the transfers were created by"
+// @Param counterparty_iban query string false "IBAN of the counterparty of the transfers"
+// @Success 200 {string} string "CSV or NDJSON transfers"
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 422 {object} Problem
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Router /transfers/export [get]
+func (api *apiDetails) ExportTransfers(c *gin.Context) {
+	logger := api.logger.With("handler", "ExportTransfers", "request_id", requestIDFrom(c))
+
+	filter, format, problem := exportRequest(c)
+	if problem != nil {
+		createErrorResponse(c, problem)
+		return
+	}
+
+	c.Header("Content-Type", exportContentTypes[format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="transfers-%s.%s"`, filter.IBAN, format))
+	c.Header("Vary", "Accept-Encoding")
+	var w io.Writer = c.Writer
+	var gz *gzip.Writer
+	if acceptsGzip(c.GetHeader("Accept-Encoding")) {
+		c.Header("Content-Encoding", "gzip")
+		gz = gzip.NewWriter(c.Writer)
+		w = gz
+	}
+
+	count, err := api.exports.ExportTransfers(c.Request.Context(), format, filter, w)
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		return
+	}
+	// Once the export is being written, the response can no longer turn into a problem
+	if c.Writer.Written() {
+		logger.Error("Failed to write export", "error", err, "iban", filter.IBAN, "transfers", count)
+		return
+	}
+	for _, header := range []string{"Content-Type", "Content-Disposition", "Content-Encoding"} {
+		c.Writer.Header().Del(header)
+	}
+	problem = ServiceProblem(err, "Error exporting transfers")
+	if problem.Status >= http.StatusInternalServerError {
+		logger.Error("Failed to export transfers", "error", err, "iban", filter.IBAN)
+	}
+	createErrorResponse(c, problem)
+}
+
+// exportRequest returns the filter and the format of an export request
+func exportRequest(c *gin.Context) (service.ExportFilter, service.ExportFormat, *Problem) {
+	filter := service.ExportFilter{IBAN: c.Query("iban")}
+	if filter.IBAN == "" {
+		return filter, "", newProblem(problemInvalidRequest, "The iban query parameter is required")
+	}
+	format := service.ExportFormat(c.DefaultQuery("format", string(service.ExportFormatCSV)))
+	if _, ok := exportContentTypes[format]; !ok {
+		return filter, "", newProblem(problemInvalidRequest, fmt.Sprintf("The format query parameter must be %s or %s", service.ExportFormatCSV, service.ExportFormatNDJSON))
+	}
+	if s := c.Query("from"); s != "" {
+		from, err := time.Parse(statementDateLayout, s)
+		if err != nil {
+			return filter, "", newProblem(problemInvalidRequest, "The from query parameter must be a date formatted as YYYY-MM-DD")
+		}
+		filter.From = from
+	}
+	if s := c.Query("to"); s != "" {
+		to, err := time.Parse(statementDateLayout, s)
+		if err != nil {
+			return filter, "", newProblem(problemInvalidRequest, "The to query parameter must be a date formatted as YYYY-MM-DD")
+		}
+		// The last day is included
+		filter.To = to.AddDate(0, 0, 1)
+	}
+	switch status := transfer.Status(c.Query("status")); status {
+	case "", transfer.StatusExecuted, transfer.StatusReturned:
+		filter.Status = status
+	default:
+		return filter, "", newProblem(problemInvalidRequest, fmt.Sprintf("The status query parameter must be %s or %s", transfer.StatusExecuted, transfer.StatusReturned))
+	}
+	if s := c.Query("batch_id"); s != "" {
+		batchID, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || batchID <= 0 {
+			return filter, "", newProblem(problemInvalidRequest, "The batch_id query parameter must be a positive integer")
+		}
+		filter.BatchID = batchID
+	}
+	filter.CounterpartyIBAN = c.Query("counterparty_iban")
+	return filter, format, nil
+}
+
+// acceptsGzip reports whether an Accept-Encoding header accepts the gzip encoding
+func acceptsGzip(acceptEncoding string) bool {
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		if strings.TrimSpace(name) != "gzip" {
+			continue
+		}
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		return !ok || strings.Trim(q, "0.") != ""
+	}
+	return false
+}
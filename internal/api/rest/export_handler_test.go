@@ -0,0 +1,178 @@
+package rest
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/transfer"
+	"moneytransfer/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestExportTransfers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const iban = "FR1420041010050500013M02606"
+	march4 := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	march6 := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+
+	// writeExport writes a fixed export
+	writeExport := func(_ context.Context, _ service.ExportFormat, _ service.ExportFilter, w io.Writer) (int, error) {
+		_, err := io.WriteString(w, "id\n1\n")
+		return 1, err
+	}
+
+	tests := []struct {
+		name                string
+		query               string
+		acceptEncoding      string
+		setupMock           func(*mock.ExportServiceMock)
+		expectedStatusCode  int
+		expectedCode        string
+		expectedContentType string
+		expectedDisposition string
+		expectedGzip        bool
+	}{
+		{
+			name:  "CSV export of a period",
+			query: "?iban=" + iban + "&from=2024-03-04&to=2024-03-05&status=returned&batch_id=42&counterparty_iban=DE89370400440532013000",
+			setupMock: func(mockService *mock.ExportServiceMock) {
+				mockService.EXPECT().ExportTransfers(gomock.Any(), service.ExportFormatCSV,
+					service.ExportFilter{IBAN: iban, From: march4, To: march6, Status: transfer.StatusReturned, BatchID: 42, CounterpartyIBAN: "DE89370400440532013000"}, gomock.Any()).
+					DoAndReturn(writeExport)
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedDisposition: `attachment; filename="transfers-FR1420041010050500013M02606.csv"`,
+		},
+		{
+			name:           "Gzip compressed NDJSON export",
+			query:          "?iban=" + iban + "&format=ndjson",
+			acceptEncoding: "br;q=1.0, gzip;q=0.8",
+			setupMock: func(mockService *mock.ExportServiceMock) {
+				mockService.EXPECT().ExportTransfers(gomock.Any(), service.ExportFormatNDJSON, service.ExportFilter{IBAN: iban}, gomock.Any()).
+					DoAndReturn(writeExport)
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedDisposition: `attachment; filename="transfers-FR1420041010050500013M02606.ndjson"`,
+			expectedGzip:        true,
+		},
+		{
+			name:           "Gzip refused by the client",
+			query:          "?iban=" + iban,
+			acceptEncoding: "gzip;q=0",
+			setupMock: func(mockService *mock.ExportServiceMock) {
+				mockService.EXPECT().ExportTransfers(gomock.Any(), service.ExportFormatCSV, service.ExportFilter{IBAN: iban}, gomock.Any()).
+					DoAndReturn(writeExport)
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedDisposition: `attachment; filename="transfers-FR1420041010050500013M02606.csv"`,
+		},
+		{
+			name:           "Account not found",
+			query:          "?iban=DE89370400440532013000",
+			acceptEncoding: "gzip",
+			setupMock: func(mockService *mock.ExportServiceMock) {
+				mockService.EXPECT().ExportTransfers(gomock.Any(), service.ExportFormatCSV, gomock.Any(), gomock.Any()).
+					Return(0, fmt.Errorf("%w: DE89370400440532013000", service.ErrAccountNotFound))
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedCode:       service.CodeAccountNotFound,
+		},
+		{
+			name:               "Missing IBAN",
+			query:              "?format=csv",
+			setupMock:          func(mockService *mock.ExportServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+		{
+			name:               "Unknown format",
+			query:              "?iban=" + iban + "&format=xlsx",
+			setupMock:          func(mockService *mock.ExportServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+		{
+			name:               "Unknown status",
+			query:              "?iban=" + iban + "&status=pending",
+			setupMock:          func(mockService *mock.ExportServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+		{
+			name:               "Invalid batch ID",
+			query:              "?iban=" + iban + "&batch_id=-1",
+			setupMock:          func(mockService *mock.ExportServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+		{
+			name:               "Invalid date",
+			query:              "?iban=" + iban + "&to=05/03/2024",
+			setupMock:          func(mockService *mock.ExportServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mock.NewExportServiceMock(ctrl)
+			tt.setupMock(mockService)
+
+			api := &apiDetails{exports: mockService, logger: slog.Default()}
+			router := gin.New()
+			router.GET("/transfers/export", api.ExportTransfers)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/transfers/export"+tt.query, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedDisposition, w.Header().Get("Content-Disposition"))
+				body := io.Reader(w.Body)
+				if tt.expectedGzip {
+					assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+					gz, err := gzip.NewReader(w.Body)
+					require.NoError(t, err)
+					body = gz
+				} else {
+					assert.Empty(t, w.Header().Get("Content-Encoding"))
+				}
+				content, err := io.ReadAll(body)
+				require.NoError(t, err)
+				assert.Equal(t, "id\n1\n", string(content))
+				return
+			}
+
+			assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+			assert.Empty(t, w.Header().Get("Content-Disposition"))
+			assert.Empty(t, w.Header().Get("Content-Encoding"))
+			var problem problemResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedCode, problem.Code)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"moneytransfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// problemContentType is the media type of RFC 7807 error responses
+	problemContentType = "application/problem+json"
+
+	// problemTypeBaseURI is the base of the type URI of every problem in the catalog.
+	// The type URI of a problem resolves to its catalog entry.
+	problemTypeBaseURI = "/api/v1/problems/"
+)
+
+// Problem represents an RFC 7807 problem details error response
+type Problem struct {
+	// Type is a URI reference that identifies the problem type
+	Type string `json:"type"`
+	// Title is a short, human-readable summary of the problem type
+	Title string `json:"title"`
+	// Status is the HTTP status code of the response
+	Status int `json:"status"`
+	// Detail is a human-readable explanation specific to this occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+	// Instance is the path of the request that caused the problem
+	Instance string `json:"instance,omitempty"`
+	// Code is the stable, machine-readable identifier of the problem type
+	Code string `json:"code"`
+	// RequestID identifies the request in the server logs
+	RequestID string `json:"request_id,omitempty"`
+	// Extensions holds additional members specific to the problem type
+	Extensions map[string]any `json:"-" swaggerignore:"true"`
+}
+
+// MarshalJSON encodes the problem with its extension members at the top level.
+// Extension members never override the standard members.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+7)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	members["code"] = p.Code
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	if p.RequestID != "" {
+		members["request_id"] = p.RequestID
+	}
+	return json.Marshal(members)
+}
+
+// Error returns the detail of the problem, so that functions reporting problems can return them as errors
+func (p *Problem) Error() string {
+	return p.Detail
+}
+
+// With adds an extension member to the problem
+func (p *Problem) With(key string, value any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any)
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// ProblemType represents an entry of the problem type catalog
+type ProblemType struct {
+	Type   string `json:"type"`
+	Code   string `json:"code"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+}
+
+// problemCatalog lists every problem type the API can return, by code
+var problemCatalog = map[string]ProblemType{}
+
+// registerProblemType adds a problem type to the catalog
+func registerProblemType(code, title string, status int) ProblemType {
+	pt := ProblemType{
+		Type:   problemTypeBaseURI + code,
+		Code:   code,
+		Title:  title,
+		Status: status,
+	}
+	problemCatalog[code] = pt
+	return pt
+}
+
+var (
+	problemInvalidRequest       = registerProblemType("invalid_request", "Invalid request", http.StatusBadRequest)
+	problemInvalidContent       = registerProblemType("invalid_content", "Bulk transfer content cannot be parsed", http.StatusBadRequest)
+	problemInvalidAmount        = registerProblemType("invalid_amount", "Invalid credit transfer amount", http.StatusBadRequest)
+	problemPayloadTooLarge      = registerProblemType("payload_too_large", "Request body too large", http.StatusRequestEntityTooLarge)
+	problemUnsupportedMediaType = registerProblemType("unsupported_media_type", "Unsupported media type", http.StatusUnsupportedMediaType)
+	problemNotFound             = registerProblemType("not_found", "Resource not found", http.StatusNotFound)
+	problemMethodNotAllowed     = registerProblemType("method_not_allowed", "Method not allowed", http.StatusMethodNotAllowed)
+	problemUnauthorized         = registerProblemType("unauthorized", "Authentication required", http.StatusUnauthorized)
+	problemInvalidToken         = registerProblemType("invalid_token", "Invalid bearer token", http.StatusUnauthorized)
+	problemSignatureRequired    = registerProblemType("signature_required", "Request signature required", http.StatusUnauthorized)
+	problemInvalidCertificate   = registerProblemType("invalid_client_certificate", "Invalid client certificate", http.StatusUnauthorized)
+	problemRateLimited          = registerProblemType("rate_limited", "Too many requests", http.StatusTooManyRequests)
+	problemQuotaExceeded        = registerProblemType("quota_exceeded", "Daily quota exceeded", http.StatusTooManyRequests)
+
+	// Problem types of the domain errors of the service
+	problemInvalidTransfer   = registerProblemType(service.CodeInvalidTransfer, "Invalid credit transfer", http.StatusBadRequest)
+	problemInsufficientFunds = registerProblemType(service.CodeInsufficientFunds, "Insufficient funds", http.StatusUnprocessableEntity)
+	problemAccountNotFound   = registerProblemType(service.CodeAccountNotFound, "Account not found", http.StatusUnprocessableEntity)
+	problemAmountOverflow    = registerProblemType(service.CodeAmountOverflow, "Total amount too large", http.StatusUnprocessableEntity)
+	problemConcurrentUpdate  = registerProblemType(service.CodeConcurrentUpdate, "Account updated concurrently", http.StatusConflict)
+	problemBatchNotFound     = registerProblemType(service.CodeBatchNotFound, "Batch not found", http.StatusNotFound)
+	problemTransferNotFound  = registerProblemType(service.CodeTransferNotFound, "Transfer not found", http.StatusNotFound)
+	problemNotReturnable     = registerProblemType(service.CodeNotReturnable, "Transfer cannot be returned", http.StatusConflict)
+	problemInvalidPeriod     = registerProblemType(service.CodeInvalidPeriod, "Invalid statement period", http.StatusBadRequest)
+	problemAccountNotOwned   = registerProblemType(service.CodeAccountNotOwned, "Account not owned by the organization", http.StatusForbidden)
+	problemPermissionDenied  = registerProblemType(service.CodePermissionDenied, "Permission denied", http.StatusForbidden)
+	problemInvalidAPIKey     = registerProblemType(service.CodeInvalidAPIKey, "Invalid API key", http.StatusUnauthorized)
+	problemInvalidSignature  = registerProblemType(service.CodeInvalidSignature, "Invalid request signature", http.StatusUnauthorized)
+	problemInternalError     = registerProblemType(service.CodeInternalError, "Internal server error", http.StatusInternalServerError)
+
+	problemInvalidWebhookEndpoint  = registerProblemType(service.CodeInvalidWebhookEndpoint, "Invalid webhook endpoint", http.StatusBadRequest)
+	problemWebhookEndpointNotFound = registerProblemType(service.CodeWebhookEndpointNotFound, "Webhook endpoint not found", http.StatusNotFound)
+	problemWebhookDeliveryNotFound = registerProblemType(service.CodeWebhookDeliveryNotFound, "Webhook delivery not found", http.StatusNotFound)
+
+	problemInvalidAuditFilter = registerProblemType(service.CodeInvalidAuditFilter, "Invalid audit event filter", http.StatusBadRequest)
+)
+
+// newProblem creates a problem of the given type
+func newProblem(pt ProblemType, detail string) *Problem {
+	return &Problem{
+		Type:   pt.Type,
+		Title:  pt.Title,
+		Status: pt.Status,
+		Detail: detail,
+		Code:   pt.Code,
+	}
+}
+
+// ServiceProblem maps an error returned by the service onto the problem catalog.
+// Details of internal errors are not exposed to the client, internalDetail is used instead.
+func ServiceProblem(err error, internalDetail string) *Problem {
+	pt, ok := problemCatalog[service.ErrorCode(err)]
+	if !ok || pt.Code == service.CodeInternalError {
+		return newProblem(problemInternalError, internalDetail)
+	}
+
+	p := newProblem(pt, err.Error())
+
+	var fundsErr *service.InsufficientFundsError
+	if errors.As(err, &fundsErr) {
+		p.With("required_cents", fundsErr.RequiredCents).
+			With("available_cents", fundsErr.AvailableCents)
+	}
+
+	var transferErr *service.InvalidTransferError
+	if errors.As(err, &transferErr) {
+		p.With("line", transferErr.Line)
+	}
+	return p
+}
+
+// createErrorResponse writes p as an application/problem+json response and aborts the request
+func createErrorResponse(c *gin.Context, p *Problem) {
+	p.Instance = c.Request.URL.Path
+	p.RequestID = requestIDFrom(c)
+
+	body, err := json.MarshalIndent(p, "", "    ")
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Abort()
+	c.Data(p.Status, problemContentType, body)
+}
+
+// listProblemTypes godoc
+// @Summary List problem types
+// @Description Lists the catalog of problem types returned in application/problem+json error responses
+// @Tags problems
+// @Produce json
+// @Success 200 {array} ProblemType
+// @Router /problems [get]
+func (api *apiDetails) listProblemTypes(c *gin.Context) {
+	types := make([]ProblemType, 0, len(problemCatalog))
+	for _, pt := range problemCatalog {
+		types = append(types, pt)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Code < types[j].Code
+	})
+	c.JSON(http.StatusOK, types)
+}
+
+// getProblemType godoc
+// @Summary Get a problem type
+// @Description Describes the problem type identified by a problem type URI
+// @Tags problems
+// @Produce json
+// @Param code path string true "Problem code"
+// @Success 200 {object} ProblemType
+// @Failure 404 {object} Problem
+// @Router /problems/{code} [get]
+func (api *apiDetails) getProblemType(c *gin.Context) {
+	pt, ok := problemCatalog[c.Param("code")]
+	if !ok {
+		createErrorResponse(c, newProblem(problemNotFound, "Unknown problem type"))
+		return
+	}
+	c.JSON(http.StatusOK, pt)
+}
+
+// pathID parses a positive integer path parameter, the problem response is written if it is invalid
+func pathID(c *gin.Context, param, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil || id <= 0 {
+		createErrorResponse(c, newProblem(problemInvalidRequest, "The "+name+" ID must be a positive integer"))
+		return 0, false
+	}
+	return id, true
+}
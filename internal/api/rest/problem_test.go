@@ -0,0 +1,150 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"moneytransfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblem_MarshalJSON(t *testing.T) {
+	p := newProblem(problemInsufficientFunds, "not enough money").
+		With("required_cents", 200).
+		With("code", "overridden")
+	p.RequestID = "req-1"
+
+	body, err := json.Marshal(p)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "/api/v1/problems/insufficient_funds",
+		"title": "Insufficient funds",
+		"status": 422,
+		"detail": "not enough money",
+		"code": "insufficient_funds",
+		"request_id": "req-1",
+		"required_cents": 200
+	}`, string(body))
+}
+
+func TestServiceProblem(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   string
+		wantStatus int
+		wantDetail string
+	}{
+		{
+			name:       "Account not found",
+			err:        fmt.Errorf("%w: FR76", service.ErrAccountNotFound),
+			wantCode:   "account_not_found",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantDetail: "account not found: FR76",
+		},
+		{
+			name:       "Account of another organization",
+			err:        fmt.Errorf("%w: FR76", service.ErrAccountNotOwned),
+			wantCode:   "account_not_owned",
+			wantStatus: http.StatusForbidden,
+			wantDetail: "account is not owned by the authenticated organization: FR76",
+		},
+		{
+			name:       "Concurrent update",
+			err:        fmt.Errorf("%w after 3 attempts", service.ErrConcurrentUpdate),
+			wantCode:   "concurrent_update",
+			wantStatus: http.StatusConflict,
+			wantDetail: "account was updated concurrently after 3 attempts",
+		},
+		{
+			name:       "Internal error details are hidden",
+			err:        errors.New("pq: password authentication failed"),
+			wantCode:   "internal_error",
+			wantStatus: http.StatusInternalServerError,
+			wantDetail: "Error processing bulk transfer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ServiceProblem(tt.err, "Error processing bulk transfer")
+			assert.Equal(t, tt.wantCode, p.Code)
+			assert.Equal(t, tt.wantStatus, p.Status)
+			assert.Equal(t, tt.wantDetail, p.Detail)
+		})
+	}
+
+	t.Run("Invalid transfer carries the line", func(t *testing.T) {
+		p := ServiceProblem(&service.InvalidTransferError{Line: 7, Err: errors.New("amount is required")}, "Error processing bulk transfer")
+		assert.Equal(t, "invalid_transfer", p.Code)
+		assert.Equal(t, 7, p.Extensions["line"])
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(requestID())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, requestIDFrom(c))
+	})
+
+	t.Run("Client request ID is reused", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set(requestIDHeader, "client-id-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "client-id-1", w.Header().Get(requestIDHeader))
+		assert.Equal(t, "client-id-1", w.Body.String())
+	})
+
+	t.Run("Invalid request ID is replaced", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set(requestIDHeader, "bad id\nwith newline")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Len(t, w.Header().Get(requestIDHeader), 32)
+		assert.Equal(t, w.Header().Get(requestIDHeader), w.Body.String())
+	})
+}
+
+func TestProblemRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	api := &apiDetails{logger: slog.Default()}
+	router := api.setupRouter()
+
+	t.Run("Problem type catalog entry", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/problems/insufficient_funds", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var pt ProblemType
+		json.Unmarshal(w.Body.Bytes(), &pt)
+		assert.Equal(t, problemInsufficientFunds, pt)
+	})
+
+	t.Run("Unknown route", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/unknown", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+		var p problemResponse
+		json.Unmarshal(w.Body.Bytes(), &p)
+		assert.Equal(t, "not_found", p.Code)
+		assert.NotEmpty(t, w.Header().Get(requestIDHeader))
+	})
+}
@@ -0,0 +1,124 @@
+package rest
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers of the rate limits, after the IETF draft on RateLimit header fields
+const (
+	rateLimitLimitHeader     = "RateLimit-Limit"
+	rateLimitRemainingHeader = "RateLimit-Remaining"
+	rateLimitResetHeader     = "RateLimit-Reset"
+	rateLimitPolicyHeader    = "RateLimit-Policy"
+	retryAfterHeader         = "Retry-After"
+)
+
+// rateLimitKey returns the key the requests of the caller are limited with:
+// its organization, or its subject when every credential is limited on its own
+func (api *apiDetails) rateLimitKey(c *gin.Context) string {
+	p, ok := auth.FromContext(c.Request.Context())
+	if !ok {
+		return "ip:" + c.ClientIP()
+	}
+	if api.limitBySubject {
+		return "subject:" + p.Subject
+	}
+	return "organization:" + p.OrganizationName
+}
+
+// rateLimit limits the requests of each caller, GET and HEAD requests are limited as reads
+// and the others as submissions. Requests are let through when the store fails.
+func (api *apiDetails) rateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		class := ratelimit.ClassSubmit
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			class = ratelimit.ClassRead
+		}
+		api.limit(c, class, api.rateLimitKey(c))
+	}
+}
+
+// rateLimitClient limits the requests of each client address before their credentials are
+// checked, so that floods of invalid credentials do not reach the lookup of the credentials
+func (api *apiDetails) rateLimitClient() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		api.limit(c, ratelimit.ClassClient, "ip:"+c.ClientIP())
+	}
+}
+
+// limit takes a token of the bucket of the class of key, and lets the request through
+// or writes the problem
+func (api *apiDetails) limit(c *gin.Context, class ratelimit.Class, key string) {
+	r, err := api.limiter.Allow(c.Request.Context(), class, key)
+	if err != nil {
+		api.logger.Error("Failed to apply rate limit", "error", err, "request_id", requestIDFrom(c), "key", key, "class", class)
+		c.Next()
+		return
+	}
+	if r.Limit > 0 {
+		c.Header(rateLimitLimitHeader, strconv.Itoa(r.Limit))
+		c.Header(rateLimitRemainingHeader, strconv.Itoa(r.Remaining))
+		c.Header(rateLimitResetHeader, strconv.FormatInt(ceilSeconds(r.Reset), 10))
+		c.Header(rateLimitPolicyHeader, fmt.Sprintf("%d;w=%d", r.Limit, ceilSeconds(r.Window)))
+	}
+	if !r.Allowed {
+		retryAfter := ceilSeconds(r.RetryAfter)
+		c.Header(retryAfterHeader, strconv.FormatInt(retryAfter, 10))
+		api.logger.Warn("Rate limit exceeded", "request_id", requestIDFrom(c), "key", key, "class", class)
+		createErrorResponse(c, newProblem(problemRateLimited, fmt.Sprintf("Too many %s requests, retry in %d seconds", class, retryAfter)).
+			With("retry_after", retryAfter))
+		return
+	}
+	c.Next()
+}
+
+// reserveQuotas counts the credit transfers and the bytes of an upload against the daily quotas
+// of the caller in a single step, otherwise it writes the problem and returns false. The
+// reservation is refunded with refundQuotas when the upload is rejected. Requests are let
+// through when the store fails.
+func (api *apiDetails) reserveQuotas(c *gin.Context, lines, bytes int64) (*ratelimit.Reservation, bool) {
+	if api.limiter == nil {
+		return nil, true
+	}
+
+	key := api.rateLimitKey(c)
+	r, q, err := api.limiter.ReserveUpload(c.Request.Context(), key, lines, bytes)
+	if err != nil {
+		api.logger.Error("Failed to apply quotas", "error", err, "request_id", requestIDFrom(c), "key", key)
+		return nil, true
+	}
+	if q.Allowed {
+		return r, true
+	}
+
+	retryAfter := ceilSeconds(q.Reset)
+	c.Header(retryAfterHeader, strconv.FormatInt(retryAfter, 10))
+	api.logger.Warn("Daily quota exceeded", "request_id", requestIDFrom(c), "key", key, "quota", q.Quota, "used", q.Used, "requested", q.Requested)
+	createErrorResponse(c, newProblem(problemQuotaExceeded, fmt.Sprintf("The daily quota of %d %s is exceeded, %d used and %d requested", q.Limit, q.Quota, q.Used, q.Requested)).
+		With("quota", q.Quota).
+		With("limit", q.Limit).
+		With("used", q.Used).
+		With("retry_after", retryAfter))
+	return nil, false
+}
+
+// refundQuotas gives the quotas reserved for a rejected upload back to the caller
+func (api *apiDetails) refundQuotas(c *gin.Context, r *ratelimit.Reservation) {
+	if err := r.Refund(c.Request.Context()); err != nil {
+		api.logger.Error("Failed to refund quotas", "error", err, "request_id", requestIDFrom(c), "key", api.rateLimitKey(c))
+	}
+}
+
+// ceilSeconds rounds d up to whole seconds
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,259 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/ratelimit"
+	"moneytransfer/internal/service"
+	"moneytransfer/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// withPrincipal authenticates the requests as a principal of the organization
+func withPrincipal(organizationName, subject string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := auth.NewContext(c.Request.Context(), auth.Principal{OrganizationName: organizationName, Subject: subject})
+		c.Request = c.Request.WithContext(ctx)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{
+		Submit: ratelimit.Limit{Rate: 0.1, Burst: 1},
+		Read:   ratelimit.Limit{Rate: 1, Burst: 2},
+	})
+
+	tests := []struct {
+		name               string
+		bySubject          bool
+		requests           []string
+		expectedStatusCode int
+		expectedRemaining  string
+		expectedRetryAfter string
+	}{
+		{
+			name:               "Submission within the limit",
+			requests:           []string{"POST ACME Corp api_key:1"},
+			expectedStatusCode: http.StatusOK,
+			expectedRemaining:  "0",
+		},
+		{
+			name:               "Submission over the limit of the organization",
+			requests:           []string{"POST ACME Corp api_key:2"},
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedRemaining:  "0",
+			expectedRetryAfter: "10",
+		},
+		{
+			name:               "Reads limited apart from submissions",
+			requests:           []string{"GET ACME Corp api_key:1"},
+			expectedStatusCode: http.StatusOK,
+			expectedRemaining:  "1",
+		},
+		{
+			name:               "Organizations limited apart",
+			requests:           []string{"POST Other Corp api_key:3"},
+			expectedStatusCode: http.StatusOK,
+			expectedRemaining:  "0",
+		},
+		{
+			name:               "Credentials limited apart",
+			bySubject:          true,
+			requests:           []string{"POST Third Corp api_key:4", "POST Third Corp api_key:5"},
+			expectedStatusCode: http.StatusOK,
+			expectedRemaining:  "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &apiDetails{limiter: limiter, limitBySubject: tt.bySubject, logger: slog.Default()}
+
+			var w *httptest.ResponseRecorder
+			for _, r := range tt.requests {
+				fields := strings.Fields(r)
+				method, subject := fields[0], fields[len(fields)-1]
+				organization := strings.Join(fields[1:len(fields)-1], " ")
+
+				router := gin.New()
+				router.Use(withPrincipal(organization, subject), api.rateLimit())
+				router.Handle(method, "/transfers", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+				w = httptest.NewRecorder()
+				req, _ := http.NewRequest(method, "/transfers", nil)
+				router.ServeHTTP(w, req)
+			}
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedRemaining, w.Header().Get(rateLimitRemainingHeader))
+			assert.NotEmpty(t, w.Header().Get(rateLimitLimitHeader))
+			assert.NotEmpty(t, w.Header().Get(rateLimitPolicyHeader))
+			assert.Equal(t, tt.expectedRetryAfter, w.Header().Get(retryAfterHeader))
+			if tt.expectedStatusCode != http.StatusOK {
+				var problem problemResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				assert.Equal(t, "rate_limited", problem.Code)
+			}
+		})
+	}
+}
+
+// quotaTestContent is a bulk transfer document of two credit transfers
+const quotaTestContent = `{
+		"organization_name": "Test Org",
+		"organization_bic": "TESTBIC1",
+		"organization_iban": "TEST123456789",
+		"credit_transfers": [
+			{"amount": "100.50", "counterparty_name": "John Doe", "counterparty_bic": "JOHNDOEBIC", "counterparty_iban": "JOHNDOE987654321", "description": "Test transfer"},
+			{"amount": "20.00", "counterparty_name": "Jane Doe", "counterparty_bic": "JANEDOEBIC", "counterparty_iban": "JANEDOE987654321", "description": "Test transfer"}
+		]
+	}`
+
+func TestBulkTransfer_Quotas(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const content = quotaTestContent
+
+	tests := []struct {
+		name               string
+		config             ratelimit.Config
+		setupMock          func(*mock.TransferServiceMock)
+		expectedStatusCode int
+		expectedQuota      string
+	}{
+		{
+			name:   "Within the quotas",
+			config: ratelimit.Config{DailyLines: 2, DailyBytes: int64(len(content))},
+			setupMock: func(mockService *mock.TransferServiceMock) {
+				mockService.EXPECT().BulkTransfer(gomock.Any(), gomock.Any()).Return(&batch.Batch{ID: 42}, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "Too many lines",
+			config:             ratelimit.Config{DailyLines: 1},
+			setupMock:          func(mockService *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedQuota:      string(ratelimit.QuotaLines),
+		},
+		{
+			name:               "Too many bytes",
+			config:             ratelimit.Config{DailyBytes: int64(len(content)) - 1},
+			setupMock:          func(mockService *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedQuota:      string(ratelimit.QuotaBytes),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mock.NewTransferServiceMock(ctrl)
+			tt.setupMock(mockService)
+
+			api := &apiDetails{
+				service:        mockService,
+				limiter:        ratelimit.NewLimiter(ratelimit.NewMemoryStore(), tt.config),
+				logger:         slog.Default(),
+				maxUploadBytes: defaultMaxUploadBytes,
+			}
+			router := gin.New()
+			router.POST("/transfers", withPrincipal("Test Org", "api_key:1"), api.BulkTransfer)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "/transfers", strings.NewReader(content))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusCreated {
+				return
+			}
+			var problem struct {
+				Code  string `json:"code"`
+				Quota string `json:"quota"`
+				Limit int64  `json:"limit"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, "quota_exceeded", problem.Code)
+			assert.Equal(t, tt.expectedQuota, problem.Quota)
+			assert.NotEmpty(t, w.Header().Get(retryAfterHeader))
+		})
+	}
+}
+
+func TestBulkTransfer_RejectedUploadsDoNotUseQuotas(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	mockService := mock.NewTransferServiceMock(ctrl)
+
+	api := &apiDetails{
+		service:        mockService,
+		limiter:        ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{DailyLines: 2, DailyBytes: int64(len(quotaTestContent))}),
+		logger:         slog.Default(),
+		maxUploadBytes: defaultMaxUploadBytes,
+	}
+	router := gin.New()
+	router.POST("/transfers", withPrincipal("Test Org", "api_key:1"), api.BulkTransfer)
+
+	upload := func(content string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/transfers", strings.NewReader(content))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Neither the invalid document nor the batch rejected by the service count against the quotas
+	assert.Equal(t, http.StatusBadRequest, upload(`{"organization_name": "Test Org"}`))
+	mockService.EXPECT().BulkTransfer(gomock.Any(), gomock.Any()).Return(nil, service.ErrInsufficientFunds)
+	assert.Equal(t, http.StatusUnprocessableEntity, upload(quotaTestContent))
+
+	mockService.EXPECT().BulkTransfer(gomock.Any(), gomock.Any()).Return(&batch.Batch{ID: 42}, nil)
+	assert.Equal(t, http.StatusCreated, upload(quotaTestContent))
+	assert.Equal(t, http.StatusTooManyRequests, upload(quotaTestContent))
+}
+
+func TestRateLimitClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	mockAPIKeys := mock.NewAPIKeyServiceMock(ctrl)
+
+	api := &apiDetails{
+		apiKeys: mockAPIKeys,
+		limiter: ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{Client: ratelimit.Limit{Rate: 0.1, Burst: 1}}),
+		logger:  slog.Default(),
+	}
+	router := api.setupRouter()
+	require.NoError(t, router.SetTrustedProxies(nil))
+
+	request := func(remoteAddr, forwardedFor string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/batches/1", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-API-Key", "invalid")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// The second request of the address is rejected before its API key is looked up,
+	// whatever address its forwarding header claims
+	mockAPIKeys.EXPECT().Authenticate(gomock.Any(), "invalid").Return(auth.Principal{}, service.ErrInvalidAPIKey).Times(2)
+	assert.Equal(t, http.StatusUnauthorized, request("192.0.2.1:1234", "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, request("192.0.2.1:1234", "198.51.100.2"))
+	assert.Equal(t, http.StatusUnauthorized, request("192.0.2.2:1234", ""))
+}
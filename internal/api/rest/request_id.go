@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// requestIDHeader carries the request ID in both requests and responses
+	requestIDHeader = "X-Request-ID"
+	// requestIDKey is the key of the request ID in the gin context
+	requestIDKey = "request_id"
+	// maxRequestIDLength limits the length of request IDs provided by clients
+	maxRequestIDLength = 128
+)
+
+// requestID is a middleware that assigns an ID to every request.
+// An ID provided by the client in the X-Request-ID header is reused so that
+// requests can be correlated across services, otherwise a random one is generated.
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !isValidRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
+
+// requestIDFrom returns the ID assigned to the request by the requestID middleware
+func requestIDFrom(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// newRequestID generates a random request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// isValidRequestID reports whether a client provided request ID can be used as is.
+// Only short IDs of printable ASCII characters are accepted, as they end up in logs.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -2,15 +2,20 @@ package rest
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	_ "moneytransfer/docs" // This is important!
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/ratelimit"
 	"moneytransfer/internal/service"
 
 	swaggerFiles "github.com/swaggo/files"
@@ -20,6 +25,9 @@ import (
 const (
 	nilArgErr   = "nil %v not allowed"
 	emptyArgErr = "empty %v not allowed"
+
+	// defaultMaxUploadBytes is the maximum size of a bulk transfer upload unless configured otherwise
+	defaultMaxUploadBytes = 100 << 20
 )
 
 // @title Money Transfer API
@@ -37,6 +45,11 @@ const (
 // @host localhost:8080
 // @BasePath /api/v1
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key of the organization. API keys and the JWTs of the identity provider are also accepted as bearer tokens in the Authorization header. Client certificates are accepted when mutual TLS is configured.
+
 // RestApi defines methods to handle rest server
 type RestApi interface {
 	StartServer()
@@ -44,13 +57,157 @@ type RestApi interface {
 }
 
 type apiDetails struct {
-	service service.TransferService
-	server  *http.Server
-	logger  *slog.Logger
+	service           service.TransferService
+	events            service.EventService
+	webhooks          service.WebhookService
+	reports           service.StatusReportService
+	statements        service.StatementService
+	exports           service.ExportService
+	apiKeys           service.APIKeyService
+	audit             service.AuditService
+	chains            service.ChainService
+	tokens            auth.TokenVerifier
+	certificates      auth.CertificateMapper
+	signatures        service.SigningService
+	signatureRequired bool
+	limiter           *ratelimit.Limiter
+	limitBySubject    bool
+	trustedProxies    []string
+	server            *http.Server
+	tlsConfig         *tls.Config
+	logger            *slog.Logger
+	maxUploadBytes    int64
+	heartbeatInterval time.Duration
+	eventPollInterval time.Duration
+	// shutdown is closed when the server stops, to end long-lived event streams
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+// Option configures optional settings of the rest api
+type Option func(*apiDetails)
+
+// WithMaxUploadBytes sets the maximum size in bytes of a bulk transfer upload
+func WithMaxUploadBytes(n int64) Option {
+	return func(api *apiDetails) {
+		api.maxUploadBytes = n
+	}
+}
+
+// WithEventService enables the event stream, served from the events of s
+func WithEventService(s service.EventService) Option {
+	return func(api *apiDetails) {
+		api.events = s
+	}
+}
+
+// WithWebhookService enables the webhook endpoints
+func WithWebhookService(s service.WebhookService) Option {
+	return func(api *apiDetails) {
+		api.webhooks = s
+	}
+}
+
+// WithStatusReportService enables the payment status reports of batches
+func WithStatusReportService(s service.StatusReportService) Option {
+	return func(api *apiDetails) {
+		api.reports = s
+	}
+}
+
+// WithStatementService enables the camt.053 and MT940 statements of accounts
+func WithStatementService(s service.StatementService) Option {
+	return func(api *apiDetails) {
+		api.statements = s
+	}
+}
+
+// WithExportService enables the export of the transfers of accounts
+func WithExportService(s service.ExportService) Option {
+	return func(api *apiDetails) {
+		api.exports = s
+	}
+}
+
+// WithAuditService enables the listing of the audit log
+func WithAuditService(s service.AuditService) Option {
+	return func(api *apiDetails) {
+		api.audit = s
+	}
+}
+
+// WithChainService enables the verification of the hash chains of accounts
+func WithChainService(s service.ChainService) Option {
+	return func(api *apiDetails) {
+		api.chains = s
+	}
+}
+
+// WithAPIKeyService enables the authentication of requests with API keys
+func WithAPIKeyService(s service.APIKeyService) Option {
+	return func(api *apiDetails) {
+		api.apiKeys = s
+	}
+}
+
+// WithTokenVerifier enables the authentication of requests with the JWT bearer tokens verified by v
+func WithTokenVerifier(v auth.TokenVerifier) Option {
+	return func(api *apiDetails) {
+		api.tokens = v
+	}
+}
+
+// WithCertificateMapper enables the authentication of requests with the client
+// certificates verified by the TLS handshake, mapped onto their principal by m
+func WithCertificateMapper(m auth.CertificateMapper) Option {
+	return func(api *apiDetails) {
+		api.certificates = m
+	}
+}
+
+// WithTLSConfig serves the api over TLS with config, which provides the certificate of the server
+func WithTLSConfig(config *tls.Config) Option {
+	return func(api *apiDetails) {
+		api.tlsConfig = config
+	}
+}
+
+// WithRequestSigning enables the verification of the signatures of bulk uploads with s,
+// required rejects the uploads that are not signed
+func WithRequestSigning(s service.SigningService, required bool) Option {
+	return func(api *apiDetails) {
+		api.signatures = s
+		api.signatureRequired = required
+	}
+}
+
+// WithRateLimiter limits the rate of the requests and the daily uploads of each organization
+// with l, or of each credential when bySubject is set
+func WithRateLimiter(l *ratelimit.Limiter, bySubject bool) Option {
+	return func(api *apiDetails) {
+		api.limiter = l
+		api.limitBySubject = bySubject
+	}
+}
+
+// WithTrustedProxies sets the proxies whose forwarding headers give the client address of the
+// requests, which keys the limits of the client addresses. The address of the connection is the
+// client address when none is set.
+func WithTrustedProxies(proxies []string) Option {
+	return func(api *apiDetails) {
+		api.trustedProxies = proxies
+	}
+}
+
+// WithHeartbeatInterval sets the interval of the heartbeats sent on idle event streams
+func WithHeartbeatInterval(d time.Duration) Option {
+	return func(api *apiDetails) {
+		api.heartbeatInterval = d
+	}
 }
 
 // NewApi creates new api instance, otherwise returns error
-func NewApi(logger *slog.Logger, a service.TransferService, port string) (RestApi, error) {
+func NewApi(logger *slog.Logger, a service.TransferService, port string, opts ...Option) (RestApi, error) {
 	if logger == nil {
 		return nil, fmt.Errorf(nilArgErr, "logger")
 	}
@@ -64,14 +221,43 @@ func NewApi(logger *slog.Logger, a service.TransferService, port string) (RestAp
 	}
 
 	api := &apiDetails{
-		service: a,
-		logger:  logger,
+		service:           a,
+		logger:            logger,
+		maxUploadBytes:    defaultMaxUploadBytes,
+		heartbeatInterval: defaultHeartbeatInterval,
+		eventPollInterval: defaultEventPollInterval,
+		shutdown:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(api)
+	}
+
+	if api.maxUploadBytes <= 0 {
+		return nil, fmt.Errorf("invalid max upload bytes %v", api.maxUploadBytes)
+	}
+
+	if api.heartbeatInterval <= 0 {
+		return nil, fmt.Errorf("invalid heartbeat interval %v", api.heartbeatInterval)
+	}
+
+	// The API is never served without authentication
+	if api.apiKeys == nil && api.tokens == nil && api.certificates == nil {
+		return nil, errors.New("an api key service, a token verifier or a certificate mapper is required")
+	}
+
+	// Client certificates are only available over TLS
+	if api.certificates != nil && (api.tlsConfig == nil || api.tlsConfig.ClientCAs == nil) {
+		return nil, errors.New("a tls config with client CAs is required to authenticate client certificates")
 	}
 
 	router := api.setupRouter()
+	if err := router.SetTrustedProxies(api.trustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 	api.server = &http.Server{
-		Addr:    fmt.Sprintf("0.0.0.0:%v", port),
-		Handler: router,
+		Addr:      fmt.Sprintf("0.0.0.0:%v", port),
+		Handler:   router,
+		TLSConfig: api.tlsConfig,
 	}
 
 	// Add Swagger documentation route
@@ -84,7 +270,14 @@ func NewApi(logger *slog.Logger, a service.TransferService, port string) (RestAp
 // it listens for a kill signal to stop the server gracefully
 func (a *apiDetails) StartServer() {
 	go func() {
-		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if a.server.TLSConfig != nil {
+			// The certificate is provided by the TLS config, it may be reloaded while the server runs
+			err = a.server.ListenAndServeTLS("", "")
+		} else {
+			err = a.server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			a.logger.Error("listen error", slog.String("error", err.Error()))
 		}
 	}()
@@ -98,7 +291,10 @@ func (a *apiDetails) StartServer() {
 }
 
 // GracefulStopServer stops the rest server gracefully
+// open event streams are ended first, as the server waits for all requests to complete
 func (a *apiDetails) GracefulStopServer() {
+	a.closeEventStreams()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 	if err := a.server.Shutdown(ctx); err != nil {
@@ -106,3 +302,10 @@ func (a *apiDetails) GracefulStopServer() {
 	}
 	a.logger.Info("Server exiting")
 }
+
+// closeEventStreams ends all open event streams, it may be called more than once
+func (a *apiDetails) closeEventStreams() {
+	a.shutdownOnce.Do(func() {
+		close(a.shutdown)
+	})
+}
@@ -8,29 +8,75 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-var validate *validator.Validate
-
-type ErrorResponse struct {
-	Message string `json:"message"`
-}
-
-func createErrorResponse(c *gin.Context, code int, message string) {
-	c.IndentedJSON(code, &ErrorResponse{
-		Message: message,
-	})
-}
+// validate checks the documents decoded by the handlers, and by ValidateBulkTransferFile outside of the router
+var validate = validator.New()
 
 func (api *apiDetails) setupRouter() *gin.Engine {
-	validate = validator.New()
-	r := gin.Default()
+	r := gin.New()
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(func(c *gin.Context) {
+		createErrorResponse(c, newProblem(problemNotFound, "No route matches the request"))
+	})
+	r.NoMethod(func(c *gin.Context) {
+		createErrorResponse(c, newProblem(problemMethodNotAllowed, "The route does not support the request method"))
+	})
+	r.Use(api.logRequests(), gin.Recovery(), requestID(), auditSource())
 	config := cors.DefaultConfig()
 	config.AllowHeaders = append(config.AllowHeaders, "Access-Control-Allow-Origin")
 	config.AllowOrigins = []string{"*"}
+	config.ExposeHeaders = append(config.ExposeHeaders, requestIDHeader,
+		rateLimitLimitHeader, rateLimitRemainingHeader, rateLimitResetHeader, rateLimitPolicyHeader, retryAfterHeader)
 	r.Use(cors.New(config))
 
 	apiV1 := r.Group("/api/v1")
 	apiV1.GET("/swagger/*any", ginSwagger.WrapHandler(swagFiles.Handler))
 	apiV1.GET("/health", api.health)
-	apiV1.POST("/transfers", api.BulkTransfer)
+	apiV1.GET("/problems", api.listProblemTypes)
+	apiV1.GET("/problems/:code", api.getProblemType)
+	apiV1.GET("/schemas/bulk-transfer", api.getBulkTransferSchema)
+	apiV1.GET("/schemas/bulk-transfer/:version", api.getBulkTransferSchemaVersion)
+
+	// The routes above are public, the others require an API key
+	authenticated := apiV1.Group("")
+	if api.limiter != nil {
+		// Floods are rejected by client address before their credentials are looked up
+		authenticated.Use(api.rateLimitClient())
+	}
+	authenticated.Use(api.authenticate())
+	if api.limiter != nil {
+		// Limited requests are rejected before their body is read or their signature verified
+		authenticated.Use(api.rateLimit())
+	}
+	if api.signatures != nil {
+		authenticated.POST("/transfers", api.verifySignature(), api.BulkTransfer)
+	} else {
+		authenticated.POST("/transfers", api.BulkTransfer)
+	}
+	if api.exports != nil {
+		authenticated.GET("/transfers/export", api.ExportTransfers)
+	}
+	authenticated.GET("/batches/:id", api.GetBatch)
+	if api.reports != nil {
+		authenticated.GET("/batches/:id/status-report", api.GetBatchStatusReport)
+	}
+	if api.statements != nil {
+		authenticated.GET("/statements", api.GetStatement)
+	}
+	if api.events != nil {
+		authenticated.GET("/events", api.StreamEvents)
+	}
+	if api.audit != nil {
+		authenticated.GET("/audit-events", api.ListAuditEvents)
+	}
+	if api.chains != nil {
+		authenticated.GET("/chain/verification", api.VerifyChain)
+	}
+	if api.webhooks != nil {
+		authenticated.POST("/webhooks", api.RegisterWebhook)
+		authenticated.GET("/webhooks", api.ListWebhooks)
+		authenticated.DELETE("/webhooks/:id", api.DisableWebhook)
+		authenticated.GET("/webhooks/:id/deliveries", api.ListWebhookDeliveries)
+		authenticated.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", api.RedeliverWebhook)
+	}
 	return r
 }
@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"moneytransfer/internal/jsonschema"
+
+	"github.com/gin-gonic/gin"
+)
+
+// schemaContentType is the media type of JSON Schema documents
+const schemaContentType = "application/schema+json"
+
+// schemaFiles holds the published JSON Schemas, named <name>.v<version>.json
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// bulkTransferSchema is a version of the JSON Schema of bulk transfer documents
+type bulkTransferSchema struct {
+	version  string
+	document []byte
+	// root validates the document, creditTransfer an element of credit_transfers
+	// and schemaVersion the schema_version member
+	root           *jsonschema.Schema
+	creditTransfer *jsonschema.Schema
+	schemaVersion  *jsonschema.Schema
+}
+
+var (
+	// bulkTransferSchemas are the versions of the bulk transfer schema, by version
+	bulkTransferSchemas = loadBulkTransferSchemas()
+	// latestBulkTransferSchema is the version documents without a schema_version are validated with
+	latestBulkTransferSchema = bulkTransferSchemas[bulkTransferSchemaVersions()[len(bulkTransferSchemas)-1]]
+)
+
+// loadBulkTransferSchemas compiles the embedded versions of the bulk transfer schema
+func loadBulkTransferSchemas() map[string]*bulkTransferSchema {
+	names, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		panic(err)
+	}
+	schemas := make(map[string]*bulkTransferSchema)
+	for _, entry := range names {
+		version, ok := strings.CutPrefix(strings.TrimSuffix(entry.Name(), ".json"), "bulk-transfer.v")
+		if !ok {
+			continue
+		}
+		document, err := schemaFiles.ReadFile(path.Join("schemas", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		root := jsonschema.MustCompile(document)
+		schema := &bulkTransferSchema{version: version, document: document, root: root}
+		if schema.creditTransfer, err = root.Subschema("/properties/credit_transfers/items"); err != nil {
+			panic(fmt.Sprintf("bulk transfer schema %s: %v", version, err))
+		}
+		if schema.schemaVersion, err = root.Subschema("/properties/schema_version"); err != nil {
+			panic(fmt.Sprintf("bulk transfer schema %s: %v", version, err))
+		}
+		schemas[version] = schema
+	}
+	return schemas
+}
+
+// bulkTransferSchemaVersions returns the versions of the bulk transfer schema, oldest first
+func bulkTransferSchemaVersions() []string {
+	versions := make([]string, 0, len(bulkTransferSchemas))
+	for version := range bulkTransferSchemas {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		vi, _ := strconv.Atoi(versions[i])
+		vj, _ := strconv.Atoi(versions[j])
+		return vi < vj
+	})
+	return versions
+}
+
+// getBulkTransferSchema godoc
+// @Summary Get the bulk transfer schema
+// @Description Returns the latest version of the JSON Schema of bulk transfer documents uploaded as application/json.
+// @Description Documents declare the version they are written in with their schema_version member.
+// @Tags schemas
+// @Produce application/schema+json
+// @Success 200 {object} object
+// @Router /schemas/bulk-transfer [get]
+func (api *apiDetails) getBulkTransferSchema(c *gin.Context) {
+	c.Data(http.StatusOK, schemaContentType, latestBulkTransferSchema.document)
+}
+
+// getBulkTransferSchemaVersion godoc
+// @Summary Get a version of the bulk transfer schema
+// @Description Returns a version of the JSON Schema of bulk transfer documents uploaded as application/json
+// @Tags schemas
+// @Produce application/schema+json
+// @Param version path string true "Schema version"
+// @Success 200 {object} object
+// @Failure 404 {object} Problem "Unknown versions carry supported_versions"
+// @Router /schemas/bulk-transfer/{version} [get]
+func (api *apiDetails) getBulkTransferSchemaVersion(c *gin.Context) {
+	schema, ok := bulkTransferSchemas[c.Param("version")]
+	if !ok {
+		createErrorResponse(c, newProblem(problemNotFound, "Unknown bulk transfer schema version").
+			With("supported_versions", bulkTransferSchemaVersions()))
+		return
+	}
+	c.Data(http.StatusOK, schemaContentType, schema.document)
+}
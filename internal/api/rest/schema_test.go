@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"moneytransfer/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestSchemaRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	api := &apiDetails{logger: slog.Default()}
+	router := api.setupRouter()
+
+	t.Run("Latest version", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/schemas/bulk-transfer", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, schemaContentType, w.Header().Get("Content-Type"))
+		var schema map[string]any
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &schema))
+		assert.Equal(t, "/api/v1/schemas/bulk-transfer/1", schema["$id"])
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/schemas/bulk-transfer/1", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, string(bulkTransferSchemas["1"].document), w.Body.String())
+	})
+
+	t.Run("Unknown version", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/schemas/bulk-transfer/0", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var p struct {
+			Code              string   `json:"code"`
+			SupportedVersions []string `json:"supported_versions"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &p)
+		assert.Equal(t, "not_found", p.Code)
+		assert.Equal(t, []string{"1"}, p.SupportedVersions)
+	})
+}
+
+func TestBulkTransfer_SchemaViolations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validate = validator.New()
+
+	ctrl := gomock.NewController(t)
+	api := &apiDetails{
+		service:        mock.NewTransferServiceMock(ctrl),
+		logger:         slog.Default(),
+		maxUploadBytes: defaultMaxUploadBytes,
+	}
+	router := gin.New()
+	router.POST("/transfers", api.BulkTransfer)
+
+	req, _ := http.NewRequest("POST", "/transfers", strings.NewReader(`{
+		"schema_version": "1",
+		"organization_bic": "TESTBIC1",
+		"organization_iban": "TEST123456789",
+		"credit_transfers": [
+			{"amount": "1", "counterparty_name": "A", "counterparty_bic": "B", "counterparty_iban": "C", "description": "D"}
+		]
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{
+		"type": "/api/v1/problems/invalid_request",
+		"title": "Invalid request",
+		"status": 400,
+		"detail": "document does not match version 1 of the bulk transfer schema: /: missing required property \"organization_name\" (schema /required)",
+		"instance": "/transfers",
+		"code": "invalid_request",
+		"schema_version": "1",
+		"violations": [
+			{"instance_path": "", "schema_path": "/required", "message": "missing required property \"organization_name\""}
+		]
+	}`, w.Body.String())
+}
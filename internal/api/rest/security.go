@@ -0,0 +1,36 @@
+package rest
+
+import (
+	"moneytransfer/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Security events are the rejections of requests whose credentials or signatures
+// cannot be trusted. They are logged apart from the validation errors of the requests,
+// with category=security, so that they can be routed to and alerted on separately.
+const (
+	securityEventMissingCredentials = "missing_credentials"
+	securityEventInvalidAPIKey      = "invalid_api_key"
+	securityEventInvalidToken       = "invalid_token"
+	securityEventInvalidCertificate = "invalid_client_certificate"
+	securityEventMissingSignature   = "missing_signature"
+	securityEventInvalidSignature   = "invalid_signature"
+)
+
+// securityEvent logs a security event about the request, args are added to the attributes
+// identifying the request and, once it is authenticated, its principal
+func (api *apiDetails) securityEvent(c *gin.Context, event string, args ...any) {
+	attrs := []any{
+		"category", "security",
+		"event", event,
+		"request_id", requestIDFrom(c),
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"client_ip", c.ClientIP(),
+	}
+	if p, ok := auth.FromContext(c.Request.Context()); ok {
+		attrs = append(attrs, "organization", p.OrganizationName, "subject", p.Subject)
+	}
+	api.logger.Warn("Security event", append(attrs, args...)...)
+}
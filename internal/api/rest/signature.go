@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/signing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signedBodyKey is the context key of the temporary file holding a signed request body
+const signedBodyKey = "signed_body"
+
+// verifySignature is a middleware that verifies the signature of the request in the
+// X-Signature headers. The signature covers the raw body, which is spooled to a temporary
+// file while it is hashed and handed to the next handlers from there. Requests without a
+// signature are rejected when signatures are required and let through otherwise; invalid
+// signatures are always rejected. Rejections are logged as security events.
+func (api *apiDetails) verifySignature() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := api.logger.With("request_id", requestIDFrom(c), "path", c.Request.URL.Path)
+
+		if !hasSignature(c) {
+			if api.signatureRequired {
+				api.securityEvent(c, securityEventMissingSignature)
+				createErrorResponse(c, newProblem(problemSignatureRequired, "The request must be signed in the "+
+					signing.SignatureHeader+", "+signing.KeyHeader+", "+signing.TimestampHeader+" and "+signing.NonceHeader+" headers"))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		hash := sha256.New()
+		body := io.TeeReader(http.MaxBytesReader(c.Writer, c.Request.Body, api.maxUploadBytes), hash)
+		file, err := spoolToTempFile(body)
+		if err != nil {
+			if isBodyTooLarge(err) {
+				logger.Error("Signed request exceeds maximum size", "max_upload_bytes", api.maxUploadBytes)
+				createErrorResponse(c, payloadTooLargeProblem(api.maxUploadBytes))
+				return
+			}
+			logger.Error("Failed to read request body", "error", err)
+			createErrorResponse(c, newProblem(problemInvalidRequest, "Error reading request body"))
+			return
+		}
+		defer removeTempFile(file)
+
+		r, err := signing.ParseRequest(c.Request.Method, c.Request.URL.Path, c.GetHeader, hash.Sum(nil))
+		if err != nil {
+			api.securityEvent(c, securityEventInvalidSignature, "error", err)
+			createErrorResponse(c, newProblem(problemInvalidSignature, err.Error()))
+			return
+		}
+		if err := api.signatures.Verify(c.Request.Context(), r); err != nil {
+			if errors.Is(err, service.ErrInvalidSignature) {
+				api.securityEvent(c, securityEventInvalidSignature, "signing_key_id", r.KeyID, "error", err)
+				createErrorResponse(c, ServiceProblem(err, ""))
+				return
+			}
+			logger.Error("Failed to verify request signature", "error", err)
+			createErrorResponse(c, ServiceProblem(err, "Error verifying request signature"))
+			return
+		}
+
+		c.Set(signedBodyKey, file)
+		c.Request.Body = file
+		c.Next()
+	}
+}
+
+// hasSignature reports whether the request carries any of the signature headers.
+// A partial set of headers is an invalid signature, not a missing one.
+func hasSignature(c *gin.Context) bool {
+	for _, h := range []string{signing.SignatureHeader, signing.KeyHeader, signing.TimestampHeader, signing.NonceHeader} {
+		if c.GetHeader(h) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// signedBody returns the temporary file holding the body of a request verified by verifySignature
+func signedBody(c *gin.Context) (*os.File, bool) {
+	v, ok := c.Get(signedBodyKey)
+	if !ok {
+		return nil, false
+	}
+	f, ok := v.(*os.File)
+	return f, ok
+}
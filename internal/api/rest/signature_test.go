@@ -0,0 +1,219 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/signing"
+	"moneytransfer/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestVerifySignature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := `{
+		"organization_name": "Test Org",
+		"organization_bic": "TESTBIC1",
+		"organization_iban": "TEST123456789",
+		"credit_transfers": [
+			{
+				"amount": "100.50",
+				"counterparty_name": "John Doe",
+				"counterparty_bic": "JOHNDOEBIC",
+				"counterparty_iban": "JOHNDOE987654321",
+				"description": "Test transfer"
+			}
+		]
+	}`
+	sum := sha256.Sum256([]byte(body))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signed := map[string]string{
+		signing.KeyHeader:       "7",
+		signing.TimestampHeader: timestamp,
+		signing.NonceHeader:     "0123456789abcdef",
+		signing.SignatureHeader: "v1=abcd",
+	}
+	without := func(header string) map[string]string {
+		headers := make(map[string]string)
+		for k, v := range signed {
+			if k != header {
+				headers[k] = v
+			}
+		}
+		return headers
+	}
+
+	tests := []struct {
+		name               string
+		required           bool
+		headers            map[string]string
+		setupMock          func(*mock.SigningServiceMock, *mock.TransferServiceMock)
+		expectedStatusCode int
+		expectedCode       string
+	}{
+		{
+			name:     "Valid signature",
+			required: true,
+			headers:  signed,
+			setupMock: func(signatures *mock.SigningServiceMock, transfers *mock.TransferServiceMock) {
+				signatures.EXPECT().Verify(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, r signing.Request) error {
+					assert.Equal(t, int64(7), r.KeyID)
+					assert.Equal(t, http.MethodPost, r.Method)
+					assert.Equal(t, "/transfers", r.Path)
+					assert.Equal(t, timestamp, strconv.FormatInt(r.Timestamp.Unix(), 10))
+					assert.Equal(t, hex.EncodeToString(sum[:]), r.BodyHash)
+					assert.Equal(t, "v1=abcd", r.Signature)
+					return nil
+				})
+				transfers.EXPECT().BulkTransfer(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, req service.BulkTransferRequest) (*batch.Batch, error) {
+					assert.Len(t, collectTransfers(t, req), 1)
+					return &batch.Batch{ID: 1}, nil
+				})
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "Missing signature when required",
+			required:           true,
+			setupMock:          func(*mock.SigningServiceMock, *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedCode:       "signature_required",
+		},
+		{
+			name: "Missing signature when optional",
+			setupMock: func(_ *mock.SigningServiceMock, transfers *mock.TransferServiceMock) {
+				transfers.EXPECT().BulkTransfer(gomock.Any(), gomock.Any()).Return(&batch.Batch{ID: 1}, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:    "Invalid signature when optional",
+			headers: signed,
+			setupMock: func(signatures *mock.SigningServiceMock, _ *mock.TransferServiceMock) {
+				signatures.EXPECT().Verify(gomock.Any(), gomock.Any()).
+					Return(fmt.Errorf("%w: signature does not match the request", service.ErrInvalidSignature))
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedCode:       service.CodeInvalidSignature,
+		},
+		{
+			name:               "Partial signature headers",
+			required:           true,
+			headers:            without(signing.NonceHeader),
+			setupMock:          func(*mock.SigningServiceMock, *mock.TransferServiceMock) {},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedCode:       service.CodeInvalidSignature,
+		},
+		{
+			name:     "Replayed nonce",
+			required: true,
+			headers:  signed,
+			setupMock: func(signatures *mock.SigningServiceMock, _ *mock.TransferServiceMock) {
+				signatures.EXPECT().Verify(gomock.Any(), gomock.Any()).
+					Return(fmt.Errorf("%w: nonce already used", service.ErrInvalidSignature))
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedCode:       service.CodeInvalidSignature,
+		},
+		{
+			name:     "Signing key store unavailable",
+			required: true,
+			headers:  signed,
+			setupMock: func(signatures *mock.SigningServiceMock, _ *mock.TransferServiceMock) {
+				signatures.EXPECT().Verify(gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedCode:       service.CodeInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			signatures := mock.NewSigningServiceMock(ctrl)
+			transfers := mock.NewTransferServiceMock(ctrl)
+			tt.setupMock(signatures, transfers)
+
+			api := &apiDetails{
+				service:           transfers,
+				signatures:        signatures,
+				signatureRequired: tt.required,
+				logger:            slog.Default(),
+				maxUploadBytes:    defaultMaxUploadBytes,
+			}
+			router := gin.New()
+			router.POST("/transfers", api.verifySignature(), api.BulkTransfer)
+
+			req, _ := http.NewRequest(http.MethodPost, "/transfers", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedCode != "" {
+				var p problemResponse
+				json.Unmarshal(w.Body.Bytes(), &p)
+				assert.Equal(t, tt.expectedCode, p.Code)
+			}
+		})
+	}
+}
+
+func TestVerifySignature_Multipart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	part, _ := mw.CreateFormFile("file", "transfers.csv")
+	part.Write([]byte("organization_name,organization_bic,organization_iban,amount,counterparty_name,counterparty_bic,counterparty_iban,description\n" +
+		"Test Org,TESTBIC1,TEST123456789,100.50,John Doe,JOHNDOEBIC,JOHNDOE987654321,Test transfer\n"))
+	mw.Close()
+	sum := sha256.Sum256(form.Bytes())
+
+	ctrl := gomock.NewController(t)
+	signatures := mock.NewSigningServiceMock(ctrl)
+	transfers := mock.NewTransferServiceMock(ctrl)
+	// The signature covers the raw multipart body, the file is then read from it
+	signatures.EXPECT().Verify(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, r signing.Request) error {
+		assert.Equal(t, hex.EncodeToString(sum[:]), r.BodyHash)
+		return nil
+	})
+	transfers.EXPECT().BulkTransfer(gomock.Any(), gomock.Any()).Return(&batch.Batch{ID: 1}, nil)
+
+	api := &apiDetails{service: transfers, signatures: signatures, signatureRequired: true, logger: slog.Default(), maxUploadBytes: defaultMaxUploadBytes}
+	router := gin.New()
+	router.POST("/transfers", api.verifySignature(), api.BulkTransfer)
+
+	req, _ := http.NewRequest(http.MethodPost, "/transfers", &form)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set(signing.KeyHeader, "7")
+	req.Header.Set(signing.TimestampHeader, strconv.FormatInt(time.Now().Unix(), 10))
+	req.Header.Set(signing.NonceHeader, "0123456789abcdef")
+	req.Header.Set(signing.SignatureHeader, "v1=abcd")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// statementDateLayout is the layout of the dates of a statement period
+	statementDateLayout = "2006-01-02"
+	// statementFormatCamt053 and statementFormatMT940 are the formats statements are written in
+	statementFormatCamt053 = "camt053"
+	statementFormatMT940   = "mt940"
+)
+
+// GetStatement godoc
+// @Summary Get the statement of an account
+// @Description Returns the ISO 20022 camt.053.001.02 bank to customer statement of the account for the days from `from` to `to` included, in UTC.
+// @Description The statement gives the opening and closing booked balances of the period, agreeing with the current balance of the account,
+// @Description one DBIT entry per transfer debited within it and one CRDT entry per returned transfer credited back within it,
+// @Description with their end-to-end and payment information references, counterparties and remittance information.
+// @Description With `format=mt940`, the same statement is returned as a SWIFT MT940 customer statement, split into pages at the FIN message length.
+// @Tags statements
+// @Produce xml
+// @Produce plain
+// @Param iban query string true "IBAN of the account"
+// @Param from query string true "First day of the period (YYYY-MM-DD)"
+// @Param to query string false "Last day of the period (YYYY-MM-DD), defaults to from"
+// @Param format query string false "Format of the statement" Enums(camt053, mt940) default(camt053)
+// @Success 200 {string} string "camt.053 document or MT940 messages"
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 422 {object} Problem
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Router /statements [get]
+func (api *apiDetails) GetStatement(c *gin.Context) {
+	logger := api.logger.With("handler", "GetStatement", "request_id", requestIDFrom(c))
+
+	iban := c.Query("iban")
+	if iban == "" {
+		createErrorResponse(c, newProblem(problemInvalidRequest, "The iban query parameter is required"))
+		return
+	}
+	from, err := time.Parse(statementDateLayout, c.Query("from"))
+	if err != nil {
+		createErrorResponse(c, newProblem(problemInvalidRequest, "The from query parameter must be a date formatted as YYYY-MM-DD"))
+		return
+	}
+	to := from
+	if s := c.Query("to"); s != "" {
+		to, err = time.Parse(statementDateLayout, s)
+		if err != nil {
+			createErrorResponse(c, newProblem(problemInvalidRequest, "The to query parameter must be a date formatted as YYYY-MM-DD"))
+			return
+		}
+	}
+
+	write, contentType, extension := api.statements.WriteStatement, "application/xml; charset=utf-8", "camt.053.xml"
+	switch format := c.DefaultQuery("format", statementFormatCamt053); format {
+	case statementFormatCamt053:
+	case statementFormatMT940:
+		write, contentType, extension = api.statements.WriteMT940Statement, "text/plain; charset=utf-8", "sta"
+	default:
+		createErrorResponse(c, newProblem(problemInvalidRequest, fmt.Sprintf("The format query parameter must be %s or %s", statementFormatCamt053, statementFormatMT940)))
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-%s-%s-%s.%s"`,
+		iban, from.Format(statementDateLayout), to.Format(statementDateLayout), extension))
+	err = write(c.Request.Context(), iban, from, to, c.Writer)
+	if err == nil {
+		return
+	}
+	// Once the statement is being written, the response can no longer turn into a problem
+	if c.Writer.Written() {
+		logger.Error("Failed to write statement", "error", err, "iban", iban)
+		return
+	}
+	c.Writer.Header().Del("Content-Type")
+	c.Writer.Header().Del("Content-Disposition")
+	problem := ServiceProblem(err, "Error generating statement")
+	if problem.Status >= http.StatusInternalServerError {
+		logger.Error("Failed to generate statement", "error", err, "iban", iban)
+	}
+	createErrorResponse(c, problem)
+}
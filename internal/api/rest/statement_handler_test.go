@@ -0,0 +1,214 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/service"
+	"moneytransfer/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestGetStatement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const iban = "FR1420041010050500013M02606"
+	march4 := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	march5 := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		query               string
+		setupMock           func(*mock.StatementServiceMock)
+		expectedStatusCode  int
+		expectedCode        string
+		expectedContentType string
+		expectedDisposition string
+		expectedBody        string
+	}{
+		{
+			name:  "Statement of a period",
+			query: "?iban=" + iban + "&from=2024-03-04&to=2024-03-05",
+			setupMock: func(mockService *mock.StatementServiceMock) {
+				mockService.EXPECT().WriteStatement(gomock.Any(), iban, march4, march5, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, _, _ time.Time, w io.Writer) error {
+						_, err := io.WriteString(w, "<Document/>")
+						return err
+					})
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/xml; charset=utf-8",
+			expectedDisposition: `attachment; filename="statement-FR1420041010050500013M02606-2024-03-04-2024-03-05.camt.053.xml"`,
+			expectedBody:        "<Document/>",
+		},
+		{
+			name:  "Statement of a day",
+			query: "?iban=" + iban + "&from=2024-03-04",
+			setupMock: func(mockService *mock.StatementServiceMock) {
+				mockService.EXPECT().WriteStatement(gomock.Any(), iban, march4, march4, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, _, _ time.Time, w io.Writer) error {
+						_, err := io.WriteString(w, "<Document/>")
+						return err
+					})
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/xml; charset=utf-8",
+			expectedDisposition: `attachment; filename="statement-FR1420041010050500013M02606-2024-03-04-2024-03-04.camt.053.xml"`,
+			expectedBody:        "<Document/>",
+		},
+		{
+			name:  "MT940 statement",
+			query: "?iban=" + iban + "&from=2024-03-04&format=mt940",
+			setupMock: func(mockService *mock.StatementServiceMock) {
+				mockService.EXPECT().WriteMT940Statement(gomock.Any(), iban, march4, march4, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, _, _ time.Time, w io.Writer) error {
+						_, err := io.WriteString(w, ":20:7-240304\r\n")
+						return err
+					})
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedDisposition: `attachment; filename="statement-FR1420041010050500013M02606-2024-03-04-2024-03-04.sta"`,
+			expectedBody:        ":20:7-240304\r\n",
+		},
+		{
+			name:  "Account not found",
+			query: "?iban=DE89370400440532013000&from=2024-03-04",
+			setupMock: func(mockService *mock.StatementServiceMock) {
+				mockService.EXPECT().WriteStatement(gomock.Any(), "DE89370400440532013000", march4, march4, gomock.Any()).
+					Return(fmt.Errorf("%w: DE89370400440532013000", service.ErrAccountNotFound))
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedCode:       service.CodeAccountNotFound,
+		},
+		{
+			name:  "Period ending before it starts",
+			query: "?iban=" + iban + "&from=2024-03-05&to=2024-03-04",
+			setupMock: func(mockService *mock.StatementServiceMock) {
+				mockService.EXPECT().WriteStatement(gomock.Any(), iban, march5, march4, gomock.Any()).
+					Return(fmt.Errorf("%w: 2024-03-05 is after 2024-03-04", service.ErrInvalidPeriod))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       service.CodeInvalidPeriod,
+		},
+		{
+			name:               "Missing IBAN",
+			query:              "?from=2024-03-04",
+			setupMock:          func(mockService *mock.StatementServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+		{
+			name:               "Unknown format",
+			query:              "?iban=" + iban + "&from=2024-03-04&format=mt950",
+			setupMock:          func(mockService *mock.StatementServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+		{
+			name:               "Invalid date",
+			query:              "?iban=" + iban + "&from=04/03/2024",
+			setupMock:          func(mockService *mock.StatementServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mock.NewStatementServiceMock(ctrl)
+			tt.setupMock(mockService)
+
+			api := &apiDetails{statements: mockService, logger: slog.Default()}
+			router := gin.New()
+			router.GET("/statements", api.GetStatement)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/statements"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedDisposition, w.Header().Get("Content-Disposition"))
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+				return
+			}
+
+			assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+			assert.Empty(t, w.Header().Get("Content-Disposition"))
+			var problem problemResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedCode, problem.Code)
+		})
+	}
+}
+
+func TestGetStatement_Authorization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const iban = "FR1420041010050500013M02606"
+	march4 := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	// The statement service authorizes the read of the account with the policy
+	tests := []struct {
+		name               string
+		authzErr           error
+		expectedStatusCode int
+		expectedCode       string
+	}{
+		{
+			name:               "Allowed",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Denied",
+			authzErr:           fmt.Errorf("%w: api_key:7 may not read account %s", authz.ErrDenied, iban),
+			expectedStatusCode: http.StatusForbidden,
+			expectedCode:       service.CodePermissionDenied,
+		},
+		{
+			name:               "Policy failure",
+			authzErr:           errors.New("connection refused"),
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedCode:       service.CodeInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mock.NewStatementServiceMock(ctrl)
+			mockService.EXPECT().WriteStatement(gomock.Any(), iban, march4, march4, gomock.Any()).Return(tt.authzErr)
+
+			api := &apiDetails{statements: mockService, logger: slog.Default()}
+			router := gin.New()
+			router.GET("/statements", api.GetStatement)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/statements?iban="+iban+"&from=2024-03-04", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				return
+			}
+			var problem problemResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedCode, problem.Code)
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"moneytransfer/internal/event"
+	"moneytransfer/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultDeliveryLimit is the number of deliveries listed when no limit is given
+	defaultDeliveryLimit = 50
+	// maxDeliveryLimit is the maximum number of deliveries listed at once
+	maxDeliveryLimit = 500
+)
+
+// RegisterWebhookRequest represents the body of a webhook endpoint registration
+type RegisterWebhookRequest struct {
+	OrganizationName string `json:"organization_name" validate:"required"`
+	URL              string `json:"url" validate:"required"`
+	// EventTypes is a subset of batch.executed, batch.failed and transfer.returned
+	EventTypes []string `json:"event_types" validate:"required,min=1"`
+}
+
+// WebhookEndpointResponse represents a registered webhook endpoint
+type WebhookEndpointResponse struct {
+	ID               int64    `json:"id"`
+	OrganizationName string   `json:"organization_name"`
+	URL              string   `json:"url"`
+	EventTypes       []string `json:"event_types"`
+	Active           bool     `json:"active"`
+	// Secret signs the deliveries to the endpoint, it is only returned on registration
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newWebhookEndpointResponse(e *webhook.Endpoint) WebhookEndpointResponse {
+	eventTypes := make([]string, len(e.EventTypes))
+	for i, t := range e.EventTypes {
+		eventTypes[i] = string(t)
+	}
+	return WebhookEndpointResponse{
+		ID:               e.ID,
+		OrganizationName: e.OrganizationName,
+		URL:              e.URL,
+		EventTypes:       eventTypes,
+		Active:           e.Active,
+		CreatedAt:        e.CreatedAt,
+	}
+}
+
+// WebhookDeliveryResponse represents a delivery of an event to a webhook endpoint
+type WebhookDeliveryResponse struct {
+	ID         int64  `json:"id"`
+	EndpointID int64  `json:"endpoint_id"`
+	EventID    int64  `json:"event_id"`
+	EventType  string `json:"event_type"`
+	// Status is one of pending, succeeded or dead
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	RedeliveryOf  int64           `json:"redelivery_of,omitempty"`
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	// AttemptLog lists the attempts of the delivery, oldest first
+	AttemptLog []WebhookAttemptResponse `json:"attempt_log"`
+}
+
+// WebhookAttemptResponse represents an attempt of a webhook delivery
+type WebhookAttemptResponse struct {
+	Attempt int `json:"attempt"`
+	// StatusCode is the HTTP status returned by the endpoint, zero if there was no response
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newWebhookDeliveryResponse(d *webhook.Delivery) WebhookDeliveryResponse {
+	attempts := make([]WebhookAttemptResponse, len(d.AttemptLog))
+	for i, a := range d.AttemptLog {
+		attempts[i] = WebhookAttemptResponse{
+			Attempt:    a.Attempt,
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			DurationMs: a.Duration.Milliseconds(),
+			CreatedAt:  a.CreatedAt,
+		}
+	}
+	return WebhookDeliveryResponse{
+		ID:            d.ID,
+		EndpointID:    d.EndpointID,
+		EventID:       d.EventSeq,
+		EventType:     string(d.EventType),
+		Status:        string(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		RedeliveryOf:  d.RedeliveryOf,
+		Payload:       d.Payload,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+		AttemptLog:    attempts,
+	}
+}
+
+// RegisterWebhook godoc
+// @Summary Register a webhook endpoint
+// @Description Registers a URL of the organization to be notified of the given event types. The URL must be an https URL
+// @Description whose host resolves to public addresses only, redirects are not followed.
+// @Description Deliveries are signed: the X-Webhook-Signature header is "v1=" followed by the hex encoded
+// @Description HMAC-SHA256, keyed with the returned secret, of the X-Webhook-Timestamp header, a dot and the body.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body RegisterWebhookRequest true "Endpoint details"
+// @Success 201 {object} WebhookEndpointResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Router /webhooks [post]
+func (api *apiDetails) RegisterWebhook(c *gin.Context) {
+	logger := api.logger.With("handler", "RegisterWebhook", "request_id", requestIDFrom(c))
+
+	var req RegisterWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		createErrorResponse(c, newProblem(problemInvalidRequest, "Error decoding request body"))
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		createErrorResponse(c, newProblem(problemInvalidRequest, err.Error()))
+		return
+	}
+
+	eventTypes := make([]event.Type, len(req.EventTypes))
+	for i, t := range req.EventTypes {
+		eventTypes[i] = event.Type(t)
+	}
+
+	e, err := api.webhooks.RegisterEndpoint(c.Request.Context(), req.OrganizationName, req.URL, eventTypes)
+	if err != nil {
+		problem := ServiceProblem(err, "Error registering webhook endpoint")
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("Failed to register webhook endpoint", "error", err, "organization", req.OrganizationName)
+		}
+		createErrorResponse(c, problem)
+		return
+	}
+
+	resp := newWebhookEndpointResponse(e)
+	resp.Secret = e.Secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListWebhooks godoc
+// @Summary List webhook endpoints
+// @Description Returns the webhook endpoints of an organization, without their secrets
+// @Tags webhooks
+// @Produce json
+// @Param organization query string true "Organization name"
+// @Success 200 {array} WebhookEndpointResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Router /webhooks [get]
+func (api *apiDetails) ListWebhooks(c *gin.Context) {
+	logger := api.logger.With("handler", "ListWebhooks", "request_id", requestIDFrom(c))
+
+	organization := c.Query("organization")
+	if organization == "" {
+		createErrorResponse(c, newProblem(problemInvalidRequest, "The organization query parameter is required"))
+		return
+	}
+
+	endpoints, err := api.webhooks.ListEndpoints(c.Request.Context(), organization)
+	if err != nil {
+		logger.Error("Failed to list webhook endpoints", "error", err, "organization", organization)
+		createErrorResponse(c, ServiceProblem(err, "Error listing webhook endpoints"))
+		return
+	}
+
+	resp := make([]WebhookEndpointResponse, len(endpoints))
+	for i := range endpoints {
+		resp[i] = newWebhookEndpointResponse(&endpoints[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DisableWebhook godoc
+// @Summary Disable a webhook endpoint
+// @Description Stops the deliveries to the endpoint, its pending deliveries become dead
+// @Tags webhooks
+// @Param id path int true "Endpoint ID"
+// @Success 204
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Router /webhooks/{id} [delete]
+func (api *apiDetails) DisableWebhook(c *gin.Context) {
+	logger := api.logger.With("handler", "DisableWebhook", "request_id", requestIDFrom(c))
+
+	id, ok := pathID(c, "id", "endpoint")
+	if !ok {
+		return
+	}
+
+	if err := api.webhooks.DisableEndpoint(c.Request.Context(), id); err != nil {
+		problem := ServiceProblem(err, "Error disabling webhook endpoint")
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("Failed to disable webhook endpoint", "error", err, "endpoint_id", id)
+		}
+		createErrorResponse(c, problem)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Returns the latest deliveries to the endpoint, newest first, with their attempts
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Param limit query int false "Maximum number of deliveries (default 50, max 500)"
+// @Success 200 {array} WebhookDeliveryResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (api *apiDetails) ListWebhookDeliveries(c *gin.Context) {
+	logger := api.logger.With("handler", "ListWebhookDeliveries", "request_id", requestIDFrom(c))
+
+	id, ok := pathID(c, "id", "endpoint")
+	if !ok {
+		return
+	}
+
+	limit := defaultDeliveryLimit
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > maxDeliveryLimit {
+			createErrorResponse(c, newProblem(problemInvalidRequest, "The limit must be an integer between 1 and 500"))
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := api.webhooks.ListDeliveries(c.Request.Context(), id, limit)
+	if err != nil {
+		problem := ServiceProblem(err, "Error listing webhook deliveries")
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("Failed to list webhook deliveries", "error", err, "endpoint_id", id)
+		}
+		createErrorResponse(c, problem)
+		return
+	}
+
+	resp := make([]WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		resp[i] = newWebhookDeliveryResponse(&deliveries[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver a webhook
+// @Description Delivers the event of a delivery to its endpoint again, as a new pending delivery.
+// @Description Dead deliveries are only delivered again this way.
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Endpoint ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 202 {object} WebhookDeliveryResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Permission denied"
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem "Rate limit exceeded, see Retry-After"
+// @Failure 500 {object} Problem
+// @Security ApiKeyAuth
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (api *apiDetails) RedeliverWebhook(c *gin.Context) {
+	logger := api.logger.With("handler", "RedeliverWebhook", "request_id", requestIDFrom(c))
+
+	endpointID, ok := pathID(c, "id", "endpoint")
+	if !ok {
+		return
+	}
+	deliveryID, ok := pathID(c, "delivery_id", "delivery")
+	if !ok {
+		return
+	}
+
+	d, err := api.webhooks.Redeliver(c.Request.Context(), endpointID, deliveryID)
+	if err != nil {
+		problem := ServiceProblem(err, "Error redelivering webhook")
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("Failed to redeliver webhook", "error", err, "endpoint_id", endpointID, "delivery_id", deliveryID)
+		}
+		createErrorResponse(c, problem)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, newWebhookDeliveryResponse(d))
+}
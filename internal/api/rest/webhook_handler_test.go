@@ -0,0 +1,324 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/event"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/webhook"
+	"moneytransfer/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func newWebhookTestRouter(t *testing.T, setupMock func(*mock.WebhookServiceMock)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	mockService := mock.NewWebhookServiceMock(ctrl)
+	setupMock(mockService)
+
+	validate = validator.New()
+	api := &apiDetails{webhooks: mockService, logger: slog.Default()}
+	router := gin.New()
+	router.POST("/webhooks", api.RegisterWebhook)
+	router.GET("/webhooks", api.ListWebhooks)
+	router.DELETE("/webhooks/:id", api.DisableWebhook)
+	router.GET("/webhooks/:id/deliveries", api.ListWebhookDeliveries)
+	router.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", api.RedeliverWebhook)
+	return router
+}
+
+func TestRegisterWebhook(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		setupMock          func(*mock.WebhookServiceMock)
+		expectedStatusCode int
+		expectedCode       string
+	}{
+		{
+			name: "Endpoint registered",
+			body: `{"organization_name":"ACME Corp","url":"https://example.com/hook","event_types":["batch.executed","transfer.returned"]}`,
+			setupMock: func(m *mock.WebhookServiceMock) {
+				m.EXPECT().RegisterEndpoint(gomock.Any(), "ACME Corp", "https://example.com/hook",
+					[]event.Type{event.TypeBatchExecuted, event.TypeTransferReturned}).
+					Return(&webhook.Endpoint{
+						ID:               1,
+						OrganizationName: "ACME Corp",
+						URL:              "https://example.com/hook",
+						Secret:           "whsec_test",
+						EventTypes:       []event.Type{event.TypeBatchExecuted, event.TypeTransferReturned},
+						Active:           true,
+					}, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name: "Invalid endpoint",
+			body: `{"organization_name":"ACME Corp","url":"ftp://example.com","event_types":["batch.executed"]}`,
+			setupMock: func(m *mock.WebhookServiceMock) {
+				m.EXPECT().RegisterEndpoint(gomock.Any(), "ACME Corp", "ftp://example.com", gomock.Any()).
+					Return(nil, fmt.Errorf("%w: URL must be an absolute http or https URL", service.ErrInvalidWebhookEndpoint))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       service.CodeInvalidWebhookEndpoint,
+		},
+		{
+			name:               "Missing event types",
+			body:               `{"organization_name":"ACME Corp","url":"https://example.com/hook"}`,
+			setupMock:          func(m *mock.WebhookServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+		{
+			name: "Internal error",
+			body: `{"organization_name":"ACME Corp","url":"https://example.com/hook","event_types":["batch.failed"]}`,
+			setupMock: func(m *mock.WebhookServiceMock) {
+				m.EXPECT().RegisterEndpoint(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedCode:       service.CodeInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newWebhookTestRouter(t, tt.setupMock)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusCreated {
+				var response WebhookEndpointResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, int64(1), response.ID)
+				assert.Equal(t, "whsec_test", response.Secret)
+				assert.Equal(t, []string{"batch.executed", "transfer.returned"}, response.EventTypes)
+				return
+			}
+
+			var problem problemResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedCode, problem.Code)
+		})
+	}
+}
+
+func TestListWebhooks(t *testing.T) {
+	t.Run("Secrets are not listed", func(t *testing.T) {
+		router := newWebhookTestRouter(t, func(m *mock.WebhookServiceMock) {
+			m.EXPECT().ListEndpoints(gomock.Any(), "ACME Corp").Return([]webhook.Endpoint{
+				{ID: 1, OrganizationName: "ACME Corp", URL: "https://example.com/hook", Secret: "whsec_test", Active: true},
+			}, nil)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/webhooks?organization=ACME+Corp", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "whsec_test")
+		var response []WebhookEndpointResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response, 1)
+	})
+
+	t.Run("Organization is required", func(t *testing.T) {
+		router := newWebhookTestRouter(t, func(m *mock.WebhookServiceMock) {})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/webhooks", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDisableWebhook(t *testing.T) {
+	tests := []struct {
+		name               string
+		id                 string
+		setupMock          func(*mock.WebhookServiceMock)
+		expectedStatusCode int
+	}{
+		{
+			name: "Endpoint disabled",
+			id:   "1",
+			setupMock: func(m *mock.WebhookServiceMock) {
+				m.EXPECT().DisableEndpoint(gomock.Any(), int64(1)).Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name: "Endpoint not found",
+			id:   "2",
+			setupMock: func(m *mock.WebhookServiceMock) {
+				m.EXPECT().DisableEndpoint(gomock.Any(), int64(2)).Return(fmt.Errorf("%w: 2", service.ErrWebhookEndpointNotFound))
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "Invalid endpoint ID",
+			id:                 "0",
+			setupMock:          func(m *mock.WebhookServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newWebhookTestRouter(t, tt.setupMock)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, "/webhooks/"+tt.id, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	tests := []struct {
+		name               string
+		query              string
+		setupMock          func(*mock.WebhookServiceMock)
+		expectedStatusCode int
+	}{
+		{
+			name: "Deliveries with attempts",
+			setupMock: func(m *mock.WebhookServiceMock) {
+				m.EXPECT().ListDeliveries(gomock.Any(), int64(1), defaultDeliveryLimit).Return([]webhook.Delivery{
+					{
+						ID:         10,
+						EndpointID: 1,
+						EventSeq:   3,
+						EventType:  event.TypeBatchExecuted,
+						Payload:    json.RawMessage(`{"id":3}`),
+						Status:     webhook.DeliveryPending,
+						Attempts:   1,
+						AttemptLog: []webhook.Attempt{
+							{Attempt: 1, StatusCode: 503, Error: "unexpected response status 503", Duration: 20 * time.Millisecond},
+						},
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:  "Custom limit",
+			query: "?limit=5",
+			setupMock: func(m *mock.WebhookServiceMock) {
+				m.EXPECT().ListDeliveries(gomock.Any(), int64(1), 5).Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Invalid limit",
+			query:              "?limit=1000",
+			setupMock:          func(m *mock.WebhookServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "Endpoint not found",
+			setupMock: func(m *mock.WebhookServiceMock) {
+				m.EXPECT().ListDeliveries(gomock.Any(), int64(1), defaultDeliveryLimit).Return(nil, fmt.Errorf("%w: 1", service.ErrWebhookEndpointNotFound))
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newWebhookTestRouter(t, tt.setupMock)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/webhooks/1/deliveries"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.name == "Deliveries with attempts" {
+				var response []WebhookDeliveryResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				if assert.Len(t, response, 1) && assert.Len(t, response[0].AttemptLog, 1) {
+					assert.Equal(t, int64(3), response[0].EventID)
+					assert.Equal(t, 503, response[0].AttemptLog[0].StatusCode)
+					assert.Equal(t, int64(20), response[0].AttemptLog[0].DurationMs)
+				}
+			}
+		})
+	}
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	tests := []struct {
+		name               string
+		path               string
+		setupMock          func(*mock.WebhookServiceMock)
+		expectedStatusCode int
+		expectedCode       string
+	}{
+		{
+			name: "Redelivery scheduled",
+			path: "/webhooks/1/deliveries/10/redeliver",
+			setupMock: func(m *mock.WebhookServiceMock) {
+				m.EXPECT().Redeliver(gomock.Any(), int64(1), int64(10)).Return(&webhook.Delivery{
+					ID: 11, EndpointID: 1, Status: webhook.DeliveryPending, RedeliveryOf: 10,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusAccepted,
+		},
+		{
+			name: "Delivery not found",
+			path: "/webhooks/1/deliveries/12/redeliver",
+			setupMock: func(m *mock.WebhookServiceMock) {
+				m.EXPECT().Redeliver(gomock.Any(), int64(1), int64(12)).Return(nil, fmt.Errorf("%w: 12", service.ErrWebhookDeliveryNotFound))
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedCode:       service.CodeWebhookDeliveryNotFound,
+		},
+		{
+			name:               "Invalid delivery ID",
+			path:               "/webhooks/1/deliveries/x/redeliver",
+			setupMock:          func(m *mock.WebhookServiceMock) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newWebhookTestRouter(t, tt.setupMock)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusAccepted {
+				var response WebhookDeliveryResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, int64(10), response.RedeliveryOf)
+				return
+			}
+
+			var problem problemResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedCode, problem.Code)
+		})
+	}
+}
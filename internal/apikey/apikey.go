@@ -0,0 +1,101 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// keyPrefix starts every key, so that leaked keys are easy to recognize
+	keyPrefix = "mtk_"
+	// prefixBytes and secretBytes are the number of random bytes of the two parts of a key
+	prefixBytes = 6
+	secretBytes = 32
+)
+
+type Key struct {
+	// ID is the unique identifier for the key
+	// it is generated by the database
+	ID               int64
+	OrganizationName string
+	// Name describes what the key is used for
+	Name string
+	// Prefix is the public part of the key, it identifies the key in lists and logs
+	Prefix string
+	// Hash is the hex encoded SHA-256 digest of the key, the key itself is never stored
+	Hash string
+	// RotatedFrom is the key this key replaced, zero if none
+	RotatedFrom int64
+	CreatedAt   time.Time
+	// ExpiresAt is the end of the grace period of a rotated key, zero if the key does not expire
+	ExpiresAt time.Time
+	// RevokedAt is the time the key was revoked, zero if it was not
+	RevokedAt time.Time
+}
+
+// Generate returns a new key of the organization and its plaintext, which is
+// formatted as mtk_<prefix>_<secret>. The plaintext is not kept in the key.
+func Generate(organizationName, name string) (*Key, string, error) {
+	random := make([]byte, prefixBytes+secretBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	prefix := hex.EncodeToString(random[:prefixBytes])
+	plaintext := keyPrefix + prefix + "_" + hex.EncodeToString(random[prefixBytes:])
+	return &Key{
+		OrganizationName: organizationName,
+		Name:             name,
+		Prefix:           prefix,
+		Hash:             Hash(plaintext),
+	}, plaintext, nil
+}
+
+// Hash returns the hex encoded SHA-256 digest of a plaintext key.
+// Keys are random, so a fast unsalted hash is enough to protect them at rest.
+func Hash(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParsePrefix returns the prefix of a plaintext key, or false if it is not formatted as a key
+func ParsePrefix(plaintext string) (string, bool) {
+	rest, ok := strings.CutPrefix(plaintext, keyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 2*prefixBytes || len(secret) != 2*secretBytes {
+		return "", false
+	}
+	return prefix, true
+}
+
+// Matches reports whether plaintext is the key, in constant time
+func (k *Key) Matches(plaintext string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(plaintext)), []byte(k.Hash)) == 1
+}
+
+// Active reports whether the key can be used to authenticate at the given time
+func (k *Key) Active(now time.Time) bool {
+	if !k.RevokedAt.IsZero() {
+		return false
+	}
+	return k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt)
+}
+
+func (k *Key) Validate() error {
+	if k.OrganizationName == "" {
+		return errors.New("organization name is required")
+	}
+	if k.Prefix == "" || k.Hash == "" {
+		return errors.New("key prefix and hash are required")
+	}
+	return nil
+}
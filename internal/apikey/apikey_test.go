@@ -0,0 +1,75 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	k, plaintext, err := Generate("Test Org", "erp")
+
+	assert.NoError(t, err)
+	assert.NoError(t, k.Validate())
+	assert.True(t, strings.HasPrefix(plaintext, "mtk_"+k.Prefix+"_"))
+	assert.NotContains(t, k.Hash, plaintext)
+	assert.True(t, k.Matches(plaintext))
+	assert.False(t, k.Matches(plaintext+"0"))
+
+	prefix, ok := ParsePrefix(plaintext)
+	assert.True(t, ok)
+	assert.Equal(t, k.Prefix, prefix)
+
+	other, otherPlaintext, err := Generate("Test Org", "erp")
+	assert.NoError(t, err)
+	assert.NotEqual(t, k.Prefix, other.Prefix)
+	assert.False(t, k.Matches(otherPlaintext))
+}
+
+func TestParsePrefix(t *testing.T) {
+	secret := strings.Repeat("ab", secretBytes)
+	tests := []struct {
+		name      string
+		plaintext string
+		want      string
+		wantOK    bool
+	}{
+		{"Valid key", "mtk_0123456789ab_" + secret, "0123456789ab", true},
+		{"Missing marker", "0123456789ab_" + secret, "", false},
+		{"Missing secret", "mtk_0123456789ab", "", false},
+		{"Short prefix", "mtk_0123_" + secret, "", false},
+		{"Short secret", "mtk_0123456789ab_abcd", "", false},
+		{"Empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, ok := ParsePrefix(tt.plaintext)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, prefix)
+		})
+	}
+}
+
+func TestKey_Active(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		key  Key
+		want bool
+	}{
+		{"New key", Key{}, true},
+		{"Rotated key in its grace period", Key{ExpiresAt: now.Add(time.Hour)}, true},
+		{"Rotated key after its grace period", Key{ExpiresAt: now}, false},
+		{"Revoked key", Key{RevokedAt: now.Add(-time.Hour)}, false},
+		{"Revoked key in its grace period", Key{ExpiresAt: now.Add(time.Hour), RevokedAt: now}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.key.Active(now))
+		})
+	}
+}
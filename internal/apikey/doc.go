@@ -0,0 +1,12 @@
+// Package apikey provides the API keys organizations authenticate with.
+//
+// A key is issued for an organization and shown once: only its SHA-256 hash is
+// stored, along with a prefix that identifies the key without revealing it.
+// Rotating a key issues a new one and keeps the old one valid for a grace
+// period, so that clients can be updated without downtime. Revoked keys are
+// rejected at once.
+//
+// Key components:
+//   - Key: Struct representing an issued API key
+//   - Repository: Interface to store API keys
+package apikey
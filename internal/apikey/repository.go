@@ -0,0 +1,30 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the requested key does not exist
+	ErrNotFound = errors.New("api key not found")
+	// ErrInactive is returned when a key that is revoked or expired is rotated
+	ErrInactive = errors.New("api key is revoked or expired")
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=repository.go -destination=../../mock/apikey_repository_mock.go -package=mock -mock_names=Repository=APIKeyRepositoryMock
+type Repository interface {
+	Create(ctx context.Context, k *Key) error
+	Get(ctx context.Context, id int64) (*Key, error)
+	// GetByPrefix returns the key of the given prefix, whether it is active or not
+	GetByPrefix(ctx context.Context, prefix string) (*Key, error)
+	// List returns the keys of the organization, oldest first
+	List(ctx context.Context, organizationName string) ([]Key, error)
+	// Rotate creates next as the replacement of the active key id, for the same
+	// organization, and makes the latter expire at expiresAt, in one transaction.
+	// It returns ErrInactive if the key is revoked or expired.
+	Rotate(ctx context.Context, id int64, next *Key, expiresAt time.Time) error
+	// Revoke revokes the key, revoking a revoked key keeps its revocation time
+	Revoke(ctx context.Context, id int64) error
+}
@@ -0,0 +1,147 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &postgresRepository{db: db}
+}
+
+const keyColumns = `id, organization_name, name, prefix, hash, COALESCE(rotated_from, 0), created_at, expires_at, revoked_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanKey(row rowScanner) (*Key, error) {
+	var k Key
+	var expiresAt, revokedAt sql.NullTime
+	err := row.Scan(&k.ID, &k.OrganizationName, &k.Name, &k.Prefix, &k.Hash, &k.RotatedFrom, &k.CreatedAt, &expiresAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	k.ExpiresAt = expiresAt.Time
+	k.RevokedAt = revokedAt.Time
+	return &k, nil
+}
+
+func (r *postgresRepository) Create(ctx context.Context, k *Key) error {
+	return createKey(ctx, r.db, k)
+}
+
+// rowQueryer is implemented by *sql.DB and *sql.Tx
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func createKey(ctx context.Context, q rowQueryer, k *Key) error {
+	query := `
+		INSERT INTO api_keys (organization_name, name, prefix, hash, rotated_from)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	rotatedFrom := sql.NullInt64{Int64: k.RotatedFrom, Valid: k.RotatedFrom != 0}
+	err := q.QueryRowContext(ctx, query, k.OrganizationName, k.Name, k.Prefix, k.Hash, rotatedFrom).
+		Scan(&k.ID, &k.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) Get(ctx context.Context, id int64) (*Key, error) {
+	k, err := scanKey(r.db.QueryRowContext(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return k, nil
+}
+
+func (r *postgresRepository) GetByPrefix(ctx context.Context, prefix string) (*Key, error) {
+	k, err := scanKey(r.db.QueryRowContext(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE prefix = $1`, prefix))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return k, nil
+}
+
+func (r *postgresRepository) List(ctx context.Context, organizationName string) ([]Key, error) {
+	query := `SELECT ` + keyColumns + ` FROM api_keys WHERE organization_name = $1 ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, organizationName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []Key
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *postgresRepository) Rotate(ctx context.Context, id int64, next *Key, expiresAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The key is locked so that it is rotated once when rotations run concurrently
+	k, err := scanKey(tx.QueryRowContext(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE id = $1 FOR UPDATE`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to lock api key: %w", err)
+	}
+	if !k.Active(time.Now()) {
+		return ErrInactive
+	}
+
+	next.OrganizationName = k.OrganizationName
+	next.RotatedFrom = k.ID
+	if err := createKey(ctx, tx, next); err != nil {
+		return err
+	}
+	// A key rotated again during its grace period does not get a longer one
+	query := `UPDATE api_keys SET expires_at = LEAST(COALESCE(expires_at, $2), $2) WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, id, expiresAt); err != nil {
+		return fmt.Errorf("failed to expire api key: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *postgresRepository) Revoke(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
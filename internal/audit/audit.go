@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"moneytransfer/internal/auth"
+)
+
+// Action is an audited action
+type Action string
+
+const (
+	ActionBulkTransfer     Action = "bulk_transfer.submit"
+	ActionTransferReturn   Action = "transfer.return"
+	ActionRoleGrant        Action = "role.grant"
+	ActionRoleRevoke       Action = "role.revoke"
+	ActionAPIKeyCreate     Action = "api_key.create"
+	ActionAPIKeyRotate     Action = "api_key.rotate"
+	ActionAPIKeyRevoke     Action = "api_key.revoke"
+	ActionSigningKeyCreate Action = "signing_key.create"
+	ActionSigningKeyRevoke Action = "signing_key.revoke"
+	ActionWebhookRegister  Action = "webhook.register"
+	ActionWebhookDisable   Action = "webhook.disable"
+)
+
+// Outcome is how an audited action ended
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	// OutcomeFailure is an action that was attempted and failed, nothing was changed
+	OutcomeFailure Outcome = "failure"
+	// OutcomeDenied is an action the actor was not allowed to perform
+	OutcomeDenied Outcome = "denied"
+)
+
+// SystemActor is the actor of the trusted callers, such as the commands of the CLI
+const SystemActor = "system"
+
+type Event struct {
+	// ID is the position of the event in the log
+	// it is generated by the database
+	ID         int64
+	OccurredAt time.Time
+	// Actor is the subject of the principal that performed the action, SystemActor for trusted callers
+	Actor             string
+	ActorOrganization string
+	User              string
+	SourceIP          string
+	RequestID         string
+	Action            Action
+	// OrganizationName and AccountIBAN are the organization and the account acted on, if any
+	OrganizationName string
+	AccountIBAN      string
+	// ResourceType and ResourceID identify the record the action created or changed, such as batch 42
+	ResourceType string
+	ResourceID   string
+	// Before and After are the JSON encoded snapshots of the state the action changed, empty if none
+	Before  json.RawMessage
+	After   json.RawMessage
+	Outcome Outcome
+	// ErrorCode is the error code of the actions that did not succeed
+	ErrorCode string
+}
+
+// NewEvent returns a successful event of the action performed for ctx. The actor is
+// the principal of ctx and the source is the one ctx carries.
+func NewEvent(ctx context.Context, action Action) *Event {
+	e := &Event{
+		Actor:   SystemActor,
+		Action:  action,
+		Outcome: OutcomeSuccess,
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		e.Actor = p.Subject
+		e.ActorOrganization = p.OrganizationName
+		e.User = p.User
+	}
+	if s, ok := SourceFrom(ctx); ok {
+		e.SourceIP = s.IP
+		e.RequestID = s.RequestID
+	}
+	return e
+}
+
+// SetSnapshots sets the state before and after the action, encoded as JSON.
+// A nil state has no snapshot.
+func (e *Event) SetSnapshots(before, after any) error {
+	var err error
+	if e.Before, err = snapshot(before); err != nil {
+		return err
+	}
+	if e.After, err = snapshot(after); err != nil {
+		return err
+	}
+	return nil
+}
+
+// snapshot encodes v as JSON
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// Source is the origin of the request an action is performed for
+type Source struct {
+	IP        string
+	RequestID string
+}
+
+type sourceKey struct{}
+
+// NewContext returns a copy of ctx carrying the source of its request
+func NewContext(ctx context.Context, s Source) context.Context {
+	return context.WithValue(ctx, sourceKey{}, s)
+}
+
+// SourceFrom returns the source carried by ctx, if any
+func SourceFrom(ctx context.Context) (Source, bool) {
+	s, ok := ctx.Value(sourceKey{}).(Source)
+	return s, ok
+}
+
+const (
+	// DefaultLimit is the number of events returned when no limit is given
+	DefaultLimit = 100
+	// MaxLimit is the maximum number of events returned at once
+	MaxLimit = 1000
+)
+
+// Filter selects the events of the log, the empty fields select every event
+type Filter struct {
+	Actor            string
+	OrganizationName string
+	AccountIBAN      string
+	// From and To bound the time the events occurred at, From included and To excluded
+	From time.Time
+	To   time.Time
+	// BeforeID only selects the events older than the event with this ID, to page through the log
+	BeforeID int64
+	Limit    int
+}
+
+func (f Filter) Validate() error {
+	if f.Limit <= 0 || f.Limit > MaxLimit {
+		return errors.New("limit must be between 1 and 1000")
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.To.After(f.From) {
+		return errors.New("end of the time range must be after its start")
+	}
+	if f.BeforeID < 0 {
+		return errors.New("before id must not be negative")
+	}
+	return nil
+}
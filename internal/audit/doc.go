@@ -0,0 +1,14 @@
+// Package audit provides the append-only log of the money-moving and administrative actions.
+//
+// An audit event records who performed an action, from where and with which outcome,
+// together with snapshots of the state it changed before and after. The events of the
+// money-moving actions are appended in the transaction that makes the change, so a
+// change is never committed without its event. Rejected actions are recorded on their
+// own. The events cannot be updated or deleted, the database refuses it.
+//
+// Key components:
+//   - Event: Struct representing an action and its outcome
+//   - Source: Origin of the request an action was performed for, carried by its context
+//   - Filter: Criteria the log is queried with
+//   - Repository: Interface to append and query events
+package audit
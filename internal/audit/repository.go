@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=repository.go -destination=../../mock/audit_repository_mock.go -package=mock -mock_names=Repository=AuditRepositoryMock
+type Repository interface {
+	// Append inserts the event in tx, or on its own when tx is nil, and sets its ID and time
+	Append(ctx context.Context, tx *sql.Tx, e *Event) error
+	// List returns the events of the filter, newest first
+	List(ctx context.Context, filter Filter) ([]Event, error)
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &postgresRepository{db: db}
+}
+
+const eventColumns = `id, occurred_at, actor, actor_organization, actor_user, source_ip, request_id, action,
+	organization_name, account_iban, resource_type, resource_id, before_state, after_state, outcome, error_code`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEvent(row rowScanner) (*Event, error) {
+	var e Event
+	var before, after []byte
+	if err := row.Scan(&e.ID, &e.OccurredAt, &e.Actor, &e.ActorOrganization, &e.User, &e.SourceIP, &e.RequestID, &e.Action,
+		&e.OrganizationName, &e.AccountIBAN, &e.ResourceType, &e.ResourceID, &before, &after, &e.Outcome, &e.ErrorCode); err != nil {
+		return nil, err
+	}
+	e.Before = before
+	e.After = after
+	return &e, nil
+}
+
+// nullJSON stores an empty snapshot as NULL
+func nullJSON(raw []byte) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return raw
+}
+
+func (r *postgresRepository) Append(ctx context.Context, tx *sql.Tx, e *Event) error {
+	query := `
+		INSERT INTO audit_events (actor, actor_organization, actor_user, source_ip, request_id, action,
+			organization_name, account_iban, resource_type, resource_id, before_state, after_state, outcome, error_code)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, occurred_at
+	`
+	args := []any{e.Actor, e.ActorOrganization, e.User, e.SourceIP, e.RequestID, e.Action,
+		e.OrganizationName, e.AccountIBAN, e.ResourceType, e.ResourceID, nullJSON(e.Before), nullJSON(e.After), e.Outcome, e.ErrorCode}
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, args...)
+	}
+
+	if err := row.Scan(&e.ID, &e.OccurredAt); err != nil {
+		return fmt.Errorf("failed to append audit event: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) List(ctx context.Context, filter Filter) ([]Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM audit_events
+		WHERE ($1 = '' OR actor = $1)
+			AND ($2 = '' OR organization_name = $2)
+			AND ($3 = '' OR account_iban = $3)
+			AND ($4::timestamptz IS NULL OR occurred_at >= $4)
+			AND ($5::timestamptz IS NULL OR occurred_at < $5)
+			AND ($6 = 0 OR id < $6)
+		ORDER BY id DESC
+		LIMIT $7
+	`
+	rows, err := r.db.QueryContext(ctx, query, filter.Actor, filter.OrganizationName, filter.AccountIBAN,
+		nullTime(filter.From), nullTime(filter.To), filter.BeforeID, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *e)
+	}
+	return events, rows.Err()
+}
+
+// nullTime passes a zero time as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+)
+
+var (
+	// ErrInvalidToken is returned when a bearer token cannot be verified
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrInvalidCertificate is returned when a client certificate does not identify an organization
+	ErrInvalidCertificate = errors.New("invalid client certificate")
+)
+
+// Principal is an authenticated caller
+type Principal struct {
+	// OrganizationName is the organization the caller acts for
+	OrganizationName string
+	// User identifies the person behind the caller, empty for API keys
+	User string
+	// Subject identifies the credential the caller authenticated with, such as api_key:<id>
+	Subject string
+}
+
+// TokenVerifier authenticates bearer tokens issued by an identity provider
+type TokenVerifier interface {
+	// VerifyToken returns the principal of a token, or an error wrapping
+	// ErrInvalidToken if the token is not valid
+	VerifyToken(ctx context.Context, token string) (Principal, error)
+}
+
+// TokenVerifierFunc is a function used as a TokenVerifier
+type TokenVerifierFunc func(ctx context.Context, token string) (Principal, error)
+
+func (f TokenVerifierFunc) VerifyToken(ctx context.Context, token string) (Principal, error) {
+	return f(ctx, token)
+}
+
+// CertificateMapper authenticates the client certificates verified by the TLS handshake
+type CertificateMapper interface {
+	// MapCertificate returns the principal of a verified certificate, or an error
+	// wrapping ErrInvalidCertificate if it does not identify an organization
+	MapCertificate(cert *x509.Certificate) (Principal, error)
+}
+
+// CertificateMapperFunc is a function used as a CertificateMapper
+type CertificateMapperFunc func(cert *x509.Certificate) (Principal, error)
+
+func (f CertificateMapperFunc) MapCertificate(cert *x509.Certificate) (Principal, error) {
+	return f(cert)
+}
+
+// principalKey is the context key of the principal
+type principalKey struct{}
+
+// NewContext returns a copy of ctx that carries p
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal carried by ctx, or false if the caller is trusted
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,13 @@
+// Package auth carries the identity of the caller of a request.
+//
+// The REST API authenticates requests and stores the principal in the context of
+// the request, where the services read it to decide what the caller may do.
+// Requests without a principal come from trusted callers, such as the commands
+// run by operators, which act on behalf of every organization.
+//
+// Key components:
+//   - Principal: Struct representing an authenticated caller
+//   - TokenVerifier: Interface to authenticate the bearer tokens of an identity provider
+//   - CertificateMapper: Interface to authenticate the client certificates of mutual TLS
+//   - NewContext, FromContext: Store and read the principal of a request
+package auth
@@ -0,0 +1,95 @@
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Role is a set of permissions granted to a principal
+type Role string
+
+const (
+	// RoleViewer reads batches, statements, exports and events
+	RoleViewer Role = "viewer"
+	// RoleSubmitter also submits bulk transfers
+	RoleSubmitter Role = "submitter"
+	// RoleApprover also returns executed transfers
+	RoleApprover Role = "approver"
+	// RoleAdmin may do everything, including managing webhooks and reading the audit log
+	RoleAdmin Role = "admin"
+)
+
+// Action is an operation subject to authorization
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionSubmit Action = "submit"
+	ActionReturn Action = "return"
+	ActionManage Action = "manage"
+)
+
+// rolePermissions are the actions each role allows
+var rolePermissions = map[Role][]Action{
+	RoleViewer:    {ActionRead},
+	RoleSubmitter: {ActionRead, ActionSubmit},
+	RoleApprover:  {ActionRead, ActionSubmit, ActionReturn},
+	RoleAdmin:     {ActionRead, ActionSubmit, ActionReturn, ActionManage},
+}
+
+// Roles returns the roles that can be granted, from the least to the most privileged
+func Roles() []Role {
+	return []Role{RoleViewer, RoleSubmitter, RoleApprover, RoleAdmin}
+}
+
+// ParseRole returns the role of its name
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := rolePermissions[role]; !ok {
+		return "", fmt.Errorf("unknown role %q, expected viewer, submitter, approver or admin", name)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role allows the action
+func (r Role) Allows(action Action) bool {
+	for _, a := range rolePermissions[r] {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+type Grant struct {
+	// ID is the unique identifier for the grant
+	// it is generated by the database
+	ID               int64
+	OrganizationName string
+	// Subject is the principal the role is granted to, such as api_key:<id>
+	Subject string
+	Role    Role
+	// AccountIBAN restricts the grant to an account, it applies to every account of the organization when empty
+	AccountIBAN string
+	CreatedAt   time.Time
+}
+
+func (g *Grant) Validate() error {
+	if g.OrganizationName == "" {
+		return errors.New("organization name is required")
+	}
+	if g.Subject == "" {
+		return errors.New("subject is required")
+	}
+	if _, err := ParseRole(string(g.Role)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// appliesTo reports whether the grant covers the account of the IBAN, or the
+// whole organization when iban is empty
+func (g *Grant) appliesTo(iban string) bool {
+	return g.AccountIBAN == "" || (iban != "" && g.AccountIBAN == iban)
+}
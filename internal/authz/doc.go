@@ -0,0 +1,15 @@
+// Package authz decides what authenticated callers may do.
+//
+// Principals are granted roles in their organization, either on all its accounts
+// or on a single account. Each role allows a set of actions: viewers read, submitters
+// also submit bulk transfers, approvers also return transfers, and admins may do
+// everything, including managing the webhooks of the organization. The policy is
+// consulted by the services themselves, so that every frontend
+// applies the same rules. Denials are logged as security events.
+//
+// Key components:
+//   - Role, Action: The roles that can be granted and the actions they allow
+//   - Grant: Struct representing a role granted to a principal
+//   - Policy: Authorizes the actions of the principal of a context
+//   - Repository: Interface to store grants
+package authz
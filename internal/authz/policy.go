@@ -0,0 +1,104 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/auth"
+)
+
+// ErrDenied is returned when the principal of a request may not perform an action
+var ErrDenied = errors.New("permission denied")
+
+// Resource is what an action is performed on: an account, or an organization as a whole
+// when AccountIBAN is empty. An empty OrganizationName is the organization of the principal.
+type Resource struct {
+	OrganizationName string
+	AccountIBAN      string
+}
+
+//go:generate go run go.uber.org/mock/mockgen -source=policy.go -destination=../../mock/authorizer_mock.go -package=mock -mock_names=Authorizer=AuthorizerMock
+type Authorizer interface {
+	// Authorize returns an error wrapping ErrDenied unless the principal of ctx may
+	// perform the action on the resource. Trusted callers may perform any action.
+	Authorize(ctx context.Context, action Action, resource Resource) error
+}
+
+// Policy authorizes the actions of principals from the roles granted to them.
+// Principals without any grant in their organization have the default role, if any.
+type Policy struct {
+	grants      Repository
+	accounts    account.Repository
+	defaultRole Role
+	logger      *slog.Logger
+}
+
+// NewPolicy returns the policy of the grants of repo. defaultRole may be empty,
+// principals without grants are then denied everything.
+func NewPolicy(grants Repository, accounts account.Repository, defaultRole Role, logger *slog.Logger) *Policy {
+	return &Policy{
+		grants:      grants,
+		accounts:    accounts,
+		defaultRole: defaultRole,
+		logger:      logger,
+	}
+}
+
+func (p *Policy) Authorize(ctx context.Context, action Action, resource Resource) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	// Principals never act on the resources of another organization, whatever their roles.
+	// Unknown accounts are denied the same way, so that IBANs cannot be probed.
+	if resource.OrganizationName != "" && resource.OrganizationName != principal.OrganizationName {
+		return p.deny(ctx, principal, action, resource, "resource of another organization")
+	}
+	if resource.AccountIBAN != "" {
+		bankAccount, err := p.accounts.GetByIBAN(resource.AccountIBAN, nil)
+		if err != nil && !errors.Is(err, account.ErrNotFound) {
+			return err
+		}
+		if err != nil || bankAccount.OrganizationName != principal.OrganizationName {
+			return p.deny(ctx, principal, action, resource, "account of another organization")
+		}
+	}
+
+	grants, err := p.grants.GrantsOf(ctx, principal.OrganizationName, principal.Subject)
+	if err != nil {
+		return err
+	}
+	if len(grants) == 0 && p.defaultRole != "" {
+		grants = []Grant{{OrganizationName: principal.OrganizationName, Subject: principal.Subject, Role: p.defaultRole}}
+	}
+	for _, g := range grants {
+		if g.appliesTo(resource.AccountIBAN) && g.Role.Allows(action) {
+			return nil
+		}
+	}
+	return p.deny(ctx, principal, action, resource, "no role allows the action")
+}
+
+// deny logs the denial as a security event and returns its error
+func (p *Policy) deny(ctx context.Context, principal auth.Principal, action Action, resource Resource, reason string) error {
+	p.logger.WarnContext(ctx, "Security event",
+		"category", "security",
+		"event", "permission_denied",
+		"principal", principal.Subject,
+		"principal_organization", principal.OrganizationName,
+		"user", principal.User,
+		"action", action,
+		"organization", resource.OrganizationName,
+		"account_iban", resource.AccountIBAN,
+		"reason", reason)
+
+	target := "the organization"
+	if resource.AccountIBAN != "" {
+		target = "account " + resource.AccountIBAN
+	}
+	return fmt.Errorf("%w: %s may not %s %s", ErrDenied, principal.Subject, action, target)
+}
@@ -0,0 +1,198 @@
+package authz_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/authz"
+	"moneytransfer/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRole_Allows(t *testing.T) {
+	tests := []struct {
+		role    authz.Role
+		allowed []authz.Action
+		denied  []authz.Action
+	}{
+		{authz.RoleViewer, []authz.Action{authz.ActionRead}, []authz.Action{authz.ActionSubmit, authz.ActionReturn, authz.ActionManage}},
+		{authz.RoleSubmitter, []authz.Action{authz.ActionRead, authz.ActionSubmit}, []authz.Action{authz.ActionReturn, authz.ActionManage}},
+		{authz.RoleApprover, []authz.Action{authz.ActionRead, authz.ActionSubmit, authz.ActionReturn}, []authz.Action{authz.ActionManage}},
+		{authz.RoleAdmin, []authz.Action{authz.ActionRead, authz.ActionSubmit, authz.ActionReturn, authz.ActionManage}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			for _, a := range tt.allowed {
+				assert.True(t, tt.role.Allows(a), a)
+			}
+			for _, a := range tt.denied {
+				assert.False(t, tt.role.Allows(a), a)
+			}
+		})
+	}
+
+	_, err := authz.ParseRole("owner")
+	assert.Error(t, err)
+}
+
+func TestPolicy_Authorize(t *testing.T) {
+	principal := auth.Principal{OrganizationName: "Test Org", Subject: "api_key:1"}
+	ownAccount := &account.BankAccount{ID: 1, OrganizationName: "Test Org", IBAN: "FR10474608000002006107XXXXX"}
+	otherAccount := &account.BankAccount{ID: 2, OrganizationName: "Test Org", IBAN: "FR10474608000002006107YYYYY"}
+
+	tests := []struct {
+		name        string
+		defaultRole authz.Role
+		action      authz.Action
+		resource    authz.Resource
+		setupMocks  func(*mock.AuthzRepositoryMock, *mock.AccountRepositoryMock)
+		wantErr     error
+	}{
+		{
+			name:     "Organization grant covers every account",
+			action:   authz.ActionSubmit,
+			resource: authz.Resource{AccountIBAN: ownAccount.IBAN},
+			setupMocks: func(grants *mock.AuthzRepositoryMock, accounts *mock.AccountRepositoryMock) {
+				accounts.EXPECT().GetByIBAN(ownAccount.IBAN, gomock.Nil()).Return(ownAccount, nil)
+				grants.EXPECT().GrantsOf(gomock.Any(), "Test Org", "api_key:1").Return([]authz.Grant{{Role: authz.RoleSubmitter}}, nil)
+			},
+		},
+		{
+			name:     "Role without the action",
+			action:   authz.ActionReturn,
+			resource: authz.Resource{AccountIBAN: ownAccount.IBAN},
+			setupMocks: func(grants *mock.AuthzRepositoryMock, accounts *mock.AccountRepositoryMock) {
+				accounts.EXPECT().GetByIBAN(ownAccount.IBAN, gomock.Nil()).Return(ownAccount, nil)
+				grants.EXPECT().GrantsOf(gomock.Any(), "Test Org", "api_key:1").Return([]authz.Grant{{Role: authz.RoleSubmitter}}, nil)
+			},
+			wantErr: authz.ErrDenied,
+		},
+		{
+			name:     "Account grant covers its account",
+			action:   authz.ActionRead,
+			resource: authz.Resource{AccountIBAN: ownAccount.IBAN},
+			setupMocks: func(grants *mock.AuthzRepositoryMock, accounts *mock.AccountRepositoryMock) {
+				accounts.EXPECT().GetByIBAN(ownAccount.IBAN, gomock.Nil()).Return(ownAccount, nil)
+				grants.EXPECT().GrantsOf(gomock.Any(), "Test Org", "api_key:1").
+					Return([]authz.Grant{{Role: authz.RoleViewer, AccountIBAN: ownAccount.IBAN}}, nil)
+			},
+		},
+		{
+			name:     "Account grant does not cover other accounts",
+			action:   authz.ActionRead,
+			resource: authz.Resource{AccountIBAN: otherAccount.IBAN},
+			setupMocks: func(grants *mock.AuthzRepositoryMock, accounts *mock.AccountRepositoryMock) {
+				accounts.EXPECT().GetByIBAN(otherAccount.IBAN, gomock.Nil()).Return(otherAccount, nil)
+				grants.EXPECT().GrantsOf(gomock.Any(), "Test Org", "api_key:1").
+					Return([]authz.Grant{{Role: authz.RoleAdmin, AccountIBAN: ownAccount.IBAN}}, nil)
+			},
+			wantErr: authz.ErrDenied,
+		},
+		{
+			name:     "Account grant does not cover the organization",
+			action:   authz.ActionRead,
+			resource: authz.Resource{},
+			setupMocks: func(grants *mock.AuthzRepositoryMock, _ *mock.AccountRepositoryMock) {
+				grants.EXPECT().GrantsOf(gomock.Any(), "Test Org", "api_key:1").
+					Return([]authz.Grant{{Role: authz.RoleAdmin, AccountIBAN: ownAccount.IBAN}}, nil)
+			},
+			wantErr: authz.ErrDenied,
+		},
+		{
+			name:       "Organization of another principal",
+			action:     authz.ActionRead,
+			resource:   authz.Resource{OrganizationName: "Other Org"},
+			setupMocks: func(*mock.AuthzRepositoryMock, *mock.AccountRepositoryMock) {},
+			wantErr:    authz.ErrDenied,
+		},
+		{
+			name:     "Account of another organization",
+			action:   authz.ActionRead,
+			resource: authz.Resource{AccountIBAN: "DE89370400440532013000"},
+			setupMocks: func(_ *mock.AuthzRepositoryMock, accounts *mock.AccountRepositoryMock) {
+				accounts.EXPECT().GetByIBAN("DE89370400440532013000", gomock.Nil()).
+					Return(&account.BankAccount{OrganizationName: "Other Org"}, nil)
+			},
+			wantErr: authz.ErrDenied,
+		},
+		{
+			name:     "Unknown account",
+			action:   authz.ActionRead,
+			resource: authz.Resource{AccountIBAN: "DE89370400440532013000"},
+			setupMocks: func(_ *mock.AuthzRepositoryMock, accounts *mock.AccountRepositoryMock) {
+				accounts.EXPECT().GetByIBAN("DE89370400440532013000", gomock.Nil()).Return(nil, account.ErrNotFound)
+			},
+			wantErr: authz.ErrDenied,
+		},
+		{
+			name:        "Default role of principals without grants",
+			defaultRole: authz.RoleViewer,
+			action:      authz.ActionRead,
+			resource:    authz.Resource{},
+			setupMocks: func(grants *mock.AuthzRepositoryMock, _ *mock.AccountRepositoryMock) {
+				grants.EXPECT().GrantsOf(gomock.Any(), "Test Org", "api_key:1").Return(nil, nil)
+			},
+		},
+		{
+			name:        "Grants replace the default role",
+			defaultRole: authz.RoleAdmin,
+			action:      authz.ActionManage,
+			resource:    authz.Resource{},
+			setupMocks: func(grants *mock.AuthzRepositoryMock, _ *mock.AccountRepositoryMock) {
+				grants.EXPECT().GrantsOf(gomock.Any(), "Test Org", "api_key:1").Return([]authz.Grant{{Role: authz.RoleViewer}}, nil)
+			},
+			wantErr: authz.ErrDenied,
+		},
+		{
+			name:     "Without grants nor default role",
+			action:   authz.ActionRead,
+			resource: authz.Resource{},
+			setupMocks: func(grants *mock.AuthzRepositoryMock, _ *mock.AccountRepositoryMock) {
+				grants.EXPECT().GrantsOf(gomock.Any(), "Test Org", "api_key:1").Return(nil, nil)
+			},
+			wantErr: authz.ErrDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			grants := mock.NewAuthzRepositoryMock(ctrl)
+			accounts := mock.NewAccountRepositoryMock(ctrl)
+			tt.setupMocks(grants, accounts)
+			policy := authz.NewPolicy(grants, accounts, tt.defaultRole, slog.Default())
+
+			err := policy.Authorize(auth.NewContext(context.Background(), principal), tt.action, tt.resource)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPolicy_Authorize_TrustedCaller(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	policy := authz.NewPolicy(mock.NewAuthzRepositoryMock(ctrl), mock.NewAccountRepositoryMock(ctrl), "", slog.Default())
+
+	err := policy.Authorize(context.Background(), authz.ActionManage, authz.Resource{OrganizationName: "Other Org"})
+	assert.NoError(t, err)
+}
+
+func TestPolicy_Authorize_StoreFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	grants := mock.NewAuthzRepositoryMock(ctrl)
+	grants.EXPECT().GrantsOf(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("connection refused"))
+	policy := authz.NewPolicy(grants, mock.NewAccountRepositoryMock(ctrl), authz.RoleAdmin, slog.Default())
+
+	ctx := auth.NewContext(context.Background(), auth.Principal{OrganizationName: "Test Org", Subject: "api_key:1"})
+	err := policy.Authorize(ctx, authz.ActionRead, authz.Resource{})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, authz.ErrDenied)
+}
@@ -0,0 +1,20 @@
+package authz
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrGrantNotFound is returned when the requested grant does not exist
+var ErrGrantNotFound = errors.New("grant not found")
+
+//go:generate go run go.uber.org/mock/mockgen -source=repository.go -destination=../../mock/authz_repository_mock.go -package=mock -mock_names=Repository=AuthzRepositoryMock
+type Repository interface {
+	// CreateGrant records the grant, granting a role that is already granted is a no-op
+	CreateGrant(ctx context.Context, g *Grant) error
+	DeleteGrant(ctx context.Context, id int64) error
+	// ListGrants returns the grants of the organization, oldest first
+	ListGrants(ctx context.Context, organizationName string) ([]Grant, error)
+	// GrantsOf returns the grants of a subject in the organization
+	GrantsOf(ctx context.Context, organizationName, subject string) ([]Grant, error)
+}
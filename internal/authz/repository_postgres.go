@@ -0,0 +1,90 @@
+package authz
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &postgresRepository{db: db}
+}
+
+const grantColumns = `id, organization_name, subject, role, COALESCE(account_iban, ''), created_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanGrant(row rowScanner) (*Grant, error) {
+	var g Grant
+	if err := row.Scan(&g.ID, &g.OrganizationName, &g.Subject, &g.Role, &g.AccountIBAN, &g.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *postgresRepository) CreateGrant(ctx context.Context, g *Grant) error {
+	// The no-op update makes the existing grant returned on conflict
+	query := `
+		INSERT INTO role_grants (organization_name, subject, role, account_iban)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (organization_name, subject, role, COALESCE(account_iban, ''))
+		DO UPDATE SET role = EXCLUDED.role
+		RETURNING id, created_at
+	`
+	accountIBAN := sql.NullString{String: g.AccountIBAN, Valid: g.AccountIBAN != ""}
+	err := r.db.QueryRowContext(ctx, query, g.OrganizationName, g.Subject, g.Role, accountIBAN).Scan(&g.ID, &g.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create grant: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) DeleteGrant(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM role_grants WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete grant: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrGrantNotFound
+	}
+	return nil
+}
+
+func (r *postgresRepository) ListGrants(ctx context.Context, organizationName string) ([]Grant, error) {
+	query := `SELECT ` + grantColumns + ` FROM role_grants WHERE organization_name = $1 ORDER BY id`
+	return r.queryGrants(ctx, query, organizationName)
+}
+
+func (r *postgresRepository) GrantsOf(ctx context.Context, organizationName, subject string) ([]Grant, error) {
+	query := `SELECT ` + grantColumns + ` FROM role_grants WHERE organization_name = $1 AND subject = $2 ORDER BY id`
+	return r.queryGrants(ctx, query, organizationName, subject)
+}
+
+func (r *postgresRepository) queryGrants(ctx context.Context, query string, args ...any) ([]Grant, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list grants: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []Grant
+	for rows.Next() {
+		g, err := scanGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan grant: %w", err)
+		}
+		grants = append(grants, *g)
+	}
+	return grants, rows.Err()
+}
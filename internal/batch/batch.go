@@ -0,0 +1,73 @@
+package batch
+
+import (
+	"errors"
+	"time"
+)
+
+// Status is the lifecycle state of a batch
+type Status string
+
+const (
+	// StatusReceived is the state of a batch whose transfers are being executed
+	StatusReceived Status = "received"
+	// StatusExecuted is the state of a batch whose transfers were all executed
+	StatusExecuted Status = "executed"
+	// StatusFailed is the state of a batch that was rejected, none of its transfers were executed
+	StatusFailed Status = "failed"
+)
+
+type Batch struct {
+	// ID is the unique identifier for the batch
+	// it is generated by the database
+	ID               int64  `json:"id"`
+	OrganizationName string `json:"organization_name"`
+	OrganizationIBAN string `json:"organization_iban"`
+	// BankAccountID is the debited account, it is only known once the batch is executed
+	BankAccountID int64 `json:"bank_account_id,omitempty"`
+	// MessageID and MessageName identify the payment message the batch was imported from,
+	// such as the MsgId of a pain.001.001.03 message; they are empty for other requests
+	MessageID     string `json:"message_id,omitempty"`
+	MessageName   string `json:"message_name,omitempty"`
+	Status        Status `json:"status"`
+	TransferCount int    `json:"transfer_count"`
+	TotalCents    int64  `json:"total_cents"`
+	// ErrorCode and ErrorDetail explain why a failed batch was rejected
+	ErrorCode   string    `json:"error_code,omitempty"`
+	ErrorDetail string    `json:"error_detail,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewBatch(organizationName, organizationIBAN string, transferCount int, totalCents int64) *Batch {
+	return &Batch{
+		OrganizationName: organizationName,
+		OrganizationIBAN: organizationIBAN,
+		Status:           StatusReceived,
+		TransferCount:    transferCount,
+		TotalCents:       totalCents,
+	}
+}
+
+// IsFinal reports whether the batch has reached a state it will not leave
+func (b *Batch) IsFinal() bool {
+	return b.Status == StatusExecuted || b.Status == StatusFailed
+}
+
+func (b *Batch) Validate() error {
+	if b.OrganizationIBAN == "" {
+		return errors.New("organization IBAN is required")
+	}
+	switch b.Status {
+	case StatusReceived, StatusExecuted, StatusFailed:
+	default:
+		return errors.New("invalid status")
+	}
+	if b.Status == StatusExecuted && b.BankAccountID == 0 {
+		return errors.New("bank account ID is required")
+	}
+	if b.Status == StatusFailed && b.ErrorCode == "" {
+		return errors.New("error code is required")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package batch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBatch(t *testing.T) {
+	b := NewBatch("Test Org", "NL91ABNA0417164300", 3, 4500)
+
+	assert.Equal(t, "Test Org", b.OrganizationName)
+	assert.Equal(t, "NL91ABNA0417164300", b.OrganizationIBAN)
+	assert.Equal(t, StatusReceived, b.Status)
+	assert.Equal(t, 3, b.TransferCount)
+	assert.Equal(t, int64(4500), b.TotalCents)
+	assert.False(t, b.IsFinal())
+}
+
+func TestBatch_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		batch   Batch
+		wantErr string
+	}{
+		{
+			name:  "Valid received batch",
+			batch: Batch{OrganizationIBAN: "NL91ABNA0417164300", Status: StatusReceived},
+		},
+		{
+			name:  "Valid executed batch",
+			batch: Batch{OrganizationIBAN: "NL91ABNA0417164300", Status: StatusExecuted, BankAccountID: 1},
+		},
+		{
+			name:    "Missing organization IBAN",
+			batch:   Batch{Status: StatusReceived},
+			wantErr: "organization IBAN is required",
+		},
+		{
+			name:    "Unknown status",
+			batch:   Batch{OrganizationIBAN: "NL91ABNA0417164300", Status: "pending"},
+			wantErr: "invalid status",
+		},
+		{
+			name:    "Executed batch without account",
+			batch:   Batch{OrganizationIBAN: "NL91ABNA0417164300", Status: StatusExecuted},
+			wantErr: "bank account ID is required",
+		},
+		{
+			name:    "Failed batch without error code",
+			batch:   Batch{OrganizationIBAN: "NL91ABNA0417164300", Status: StatusFailed},
+			wantErr: "error code is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.batch.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
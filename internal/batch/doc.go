@@ -0,0 +1,13 @@
+// Package batch provides functionality for tracking bulk transfer requests.
+//
+// This package defines the Batch struct, which represents a single bulk transfer
+// request and the state it is in, and includes the repository used to persist it.
+//
+// Key components:
+//   - Batch: Struct representing a bulk transfer request
+//   - Status: Lifecycle state of a batch
+//   - Repository: Interface to store and load batches
+//
+// A batch is received before its transfers are executed and is either executed
+// or failed afterwards. The transfers created by a batch reference it.
+package batch
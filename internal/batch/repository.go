@@ -0,0 +1,17 @@
+package batch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrNotFound is returned when the requested batch does not exist
+var ErrNotFound = errors.New("batch not found")
+
+//go:generate go run go.uber.org/mock/mockgen -source=repository.go -destination=../../mock/batch_repository_mock.go -package=mock -mock_names=Repository=BatchRepositoryMock
+type Repository interface {
+	Create(ctx context.Context, tx *sql.Tx, b *Batch) error
+	Update(ctx context.Context, tx *sql.Tx, b *Batch) error
+	Get(ctx context.Context, id int64) (*Batch, error)
+}
@@ -0,0 +1,96 @@
+package batch
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &postgresRepository{db: db}
+}
+
+// Create inserts the batch and sets its ID and timestamps
+func (r *postgresRepository) Create(ctx context.Context, tx *sql.Tx, b *Batch) error {
+	query := `
+		INSERT INTO transfer_batches (organization_name, organization_iban, status, transfer_count, total_cents, message_id, message_name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+	args := []any{b.OrganizationName, b.OrganizationIBAN, b.Status, b.TransferCount, b.TotalCents, b.MessageID, b.MessageName}
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, args...)
+	}
+
+	if err := row.Scan(&b.ID, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to create batch: %w", err)
+	}
+	return nil
+}
+
+// Update stores the state of the batch and refreshes its update timestamp
+func (r *postgresRepository) Update(ctx context.Context, tx *sql.Tx, b *Batch) error {
+	query := `
+		UPDATE transfer_batches
+		SET bank_account_id = NULLIF($1, 0), status = $2, transfer_count = $3, total_cents = $4,
+			error_code = $5, error_detail = $6, updated_at = now()
+		WHERE id = $7
+		RETURNING updated_at
+	`
+	args := []any{b.BankAccountID, b.Status, b.TransferCount, b.TotalCents, b.ErrorCode, b.ErrorDetail, b.ID}
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, args...)
+	}
+
+	if err := row.Scan(&b.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to update batch: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) Get(ctx context.Context, id int64) (*Batch, error) {
+	query := `
+		SELECT id, organization_name, organization_iban, COALESCE(bank_account_id, 0), status,
+			transfer_count, total_cents, error_code, error_detail, message_id, message_name, created_at, updated_at
+		FROM transfer_batches
+		WHERE id = $1
+	`
+	var b Batch
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&b.ID,
+		&b.OrganizationName,
+		&b.OrganizationIBAN,
+		&b.BankAccountID,
+		&b.Status,
+		&b.TransferCount,
+		&b.TotalCents,
+		&b.ErrorCode,
+		&b.ErrorDetail,
+		&b.MessageID,
+		&b.MessageName,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &b, nil
+}
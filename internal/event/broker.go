@@ -0,0 +1,50 @@
+package event
+
+import "sync"
+
+// Broker tells subscribers that new events may have been committed.
+// It carries no events itself, subscribers read them from the repository,
+// so a notification that is missed or coalesced loses nothing.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[chan struct{}]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[chan struct{}]struct{})}
+}
+
+// Subscribe returns a channel that receives a value after Notify is called and
+// a function that ends the subscription
+func (b *Broker) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+}
+
+// Notify wakes up all subscribers without blocking.
+// Notifications to a subscriber that has not consumed the previous one are coalesced.
+// Notify on a nil broker does nothing.
+func (b *Broker) Notify() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
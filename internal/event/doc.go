@@ -0,0 +1,17 @@
+// Package event provides the persisted sequence of state changes of batches and transfers.
+//
+// Every state change is appended to the events table in the transaction that makes
+// the change, so an event exists if and only if the change was committed. Events are
+// numbered by a sequence that clients use to resume a stream where they left off.
+//
+// Sequence numbers are assigned on insert rather than on commit, so readers hold back the
+// events that could still be followed by a lower sequence number: those of a stream wait
+// for the transactions writing events of its organization or account, those of every
+// organization wait for the oldest transaction in progress in the database.
+//
+// Key components:
+//   - Event: Struct representing a single state change
+//   - Filter: Scope of the events a client is allowed to see
+//   - Repository: Interface to append and read events
+//   - Broker: In-process notification of newly committed events
+package event
@@ -0,0 +1,76 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Type identifies the state change an event records
+type Type string
+
+const (
+	// TypeBatchReceived is recorded when a bulk transfer request is accepted for execution
+	TypeBatchReceived Type = "batch.received"
+	// TypeBatchExecuted is recorded when all transfers of a batch were executed
+	TypeBatchExecuted Type = "batch.executed"
+	// TypeBatchFailed is recorded when a batch was rejected
+	TypeBatchFailed Type = "batch.failed"
+	// TypeTransferReturned is recorded when an executed transfer was returned and credited back
+	TypeTransferReturned Type = "transfer.returned"
+)
+
+type Event struct {
+	// Seq is the position of the event in the event sequence
+	// it is generated by the database
+	Seq              int64
+	Type             Type
+	OrganizationName string
+	OrganizationIBAN string
+	BankAccountID    int64
+	BatchID          int64
+	TransferID       int64
+	// Data is the JSON encoded state of the batch or transfer after the change, transfers
+	// without their counterparty details
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
+
+// NewEvent returns an event of the given type with data encoded as JSON
+func NewEvent(eventType Type, organizationName, organizationIBAN string, data any) (*Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		Type:             eventType,
+		OrganizationName: organizationName,
+		OrganizationIBAN: organizationIBAN,
+		Data:             raw,
+	}, nil
+}
+
+// Filter limits the events read to those of an organization or a bank account.
+// At least one of the fields must be set.
+type Filter struct {
+	OrganizationName string
+	OrganizationIBAN string
+}
+
+func (f Filter) Validate() error {
+	if f.OrganizationName == "" && f.OrganizationIBAN == "" {
+		return errors.New("organization name or IBAN is required")
+	}
+	return nil
+}
+
+// Matches reports whether the event is in the scope of the filter
+func (f Filter) Matches(e Event) bool {
+	if f.OrganizationName != "" && e.OrganizationName != f.OrganizationName {
+		return false
+	}
+	if f.OrganizationIBAN != "" && e.OrganizationIBAN != f.OrganizationIBAN {
+		return false
+	}
+	return true
+}
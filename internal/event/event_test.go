@@ -0,0 +1,71 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEvent(t *testing.T) {
+	e, err := NewEvent(TypeBatchReceived, "Test Org", "NL91ABNA0417164300", map[string]any{"id": 7})
+
+	assert.NoError(t, err)
+	assert.Equal(t, TypeBatchReceived, e.Type)
+	assert.Equal(t, "Test Org", e.OrganizationName)
+	assert.Equal(t, "NL91ABNA0417164300", e.OrganizationIBAN)
+	assert.JSONEq(t, `{"id":7}`, string(e.Data))
+}
+
+func TestFilter(t *testing.T) {
+	e := Event{OrganizationName: "Test Org", OrganizationIBAN: "NL91ABNA0417164300"}
+
+	tests := []struct {
+		name      string
+		filter    Filter
+		wantErr   bool
+		wantMatch bool
+	}{
+		{name: "Empty filter", filter: Filter{}, wantErr: true, wantMatch: true},
+		{name: "Organization", filter: Filter{OrganizationName: "Test Org"}, wantMatch: true},
+		{name: "Other organization", filter: Filter{OrganizationName: "Other Org"}, wantMatch: false},
+		{name: "IBAN", filter: Filter{OrganizationIBAN: "NL91ABNA0417164300"}, wantMatch: true},
+		{name: "Organization and other IBAN", filter: Filter{OrganizationName: "Test Org", OrganizationIBAN: "DE89370400440532013000"}, wantMatch: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, tt.filter.Validate() != nil)
+			assert.Equal(t, tt.wantMatch, tt.filter.Matches(e))
+		})
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+
+	ch, cancel := b.Subscribe()
+
+	// Notifications are coalesced and never block
+	b.Notify()
+	b.Notify()
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("expected a notification")
+	}
+	select {
+	case <-ch:
+		t.Fatal("expected notifications to be coalesced")
+	default:
+	}
+
+	cancel()
+	b.Notify()
+	select {
+	case <-ch:
+		t.Fatal("expected no notification after cancel")
+	default:
+	}
+}
@@ -0,0 +1,14 @@
+package event
+
+import (
+	"context"
+	"database/sql"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=repository.go -destination=../../mock/event_repository_mock.go -package=mock -mock_names=Repository=EventRepositoryMock
+type Repository interface {
+	Append(ctx context.Context, tx *sql.Tx, e *Event) error
+	// ListAfter returns up to limit events of the filter with a sequence greater than afterSeq,
+	// in sequence order
+	ListAfter(ctx context.Context, filter Filter, afterSeq int64, limit int) ([]Event, error)
+}
@@ -0,0 +1,147 @@
+package event
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Prefixes of the advisory lock keys taken by the writers of the events of an
+// organization and of an account
+const (
+	organizationLockPrefix = "events:organization:"
+	accountLockPrefix      = "events:account:"
+)
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &postgresRepository{db: db}
+}
+
+// Append inserts the event and sets its sequence and creation time. The writing
+// transaction first takes shared advisory locks on its organization and account, held
+// until it ends, so that a reader can tell whether any of their events is in flight.
+func (r *postgresRepository) Append(ctx context.Context, tx *sql.Tx, e *Event) error {
+	query := `
+		WITH writer AS (
+			SELECT pg_advisory_xact_lock_shared(hashtextextended($8 || $2::text, 0)) AS organization_lock,
+				pg_advisory_xact_lock_shared(hashtextextended($9 || $3::text, 0)) AS account_lock
+		)
+		INSERT INTO events (type, organization_name, organization_iban, bank_account_id, batch_id, transfer_id, data)
+		SELECT $1::text, $2::text, $3::text, NULLIF($4::bigint, 0), NULLIF($5::bigint, 0), NULLIF($6::bigint, 0), $7::jsonb
+		FROM writer
+		RETURNING seq, created_at
+	`
+	data := e.Data
+	if len(data) == 0 {
+		data = []byte("{}")
+	}
+	args := []any{e.Type, e.OrganizationName, e.OrganizationIBAN, e.BankAccountID, e.BatchID, e.TransferID, []byte(data),
+		organizationLockPrefix, accountLockPrefix}
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, args...)
+	}
+
+	if err := row.Scan(&e.Seq, &e.CreatedAt); err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	return nil
+}
+
+// ListAfter reads the events in a transaction of its own. Sequence numbers are assigned
+// when an event is inserted, not when it is committed, so a reader that returned newer
+// events could move past the lower sequence number of an event that is committed later
+// and never see it.
+//
+// A reader scoped to an organization or an account tries to take the exclusive advisory
+// lock its writers share: when it gets it, none of their transactions is in flight and
+// every event of the filter is read, while new writers wait for the read to end and get
+// greater sequence numbers. Otherwise, and for readers of every organization such as the
+// webhook worker, only the events written by transactions older than every transaction
+// still in progress are returned, so that one long transaction anywhere in the database
+// holds back those readers until it ends.
+func (r *postgresRepository) ListAfter(ctx context.Context, filter Filter, afterSeq int64, limit int) ([]Event, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	quiescent, err := lockWriters(ctx, tx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT seq, type, organization_name, organization_iban, COALESCE(bank_account_id, 0),
+			COALESCE(batch_id, 0), COALESCE(transfer_id, 0), data, created_at
+		FROM events
+		WHERE seq > $1
+			AND ($2 = '' OR organization_name = $2)
+			AND ($3 = '' OR organization_iban = $3)
+			AND ($5 OR txid < pg_snapshot_xmin(pg_current_snapshot()))
+		ORDER BY seq
+		LIMIT $4
+	`
+	rows, err := tx.QueryContext(ctx, query, afterSeq, filter.OrganizationName, filter.OrganizationIBAN, limit, quiescent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		var data []byte
+		if err := rows.Scan(
+			&e.Seq,
+			&e.Type,
+			&e.OrganizationName,
+			&e.OrganizationIBAN,
+			&e.BankAccountID,
+			&e.BatchID,
+			&e.TransferID,
+			&data,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		e.Data = data
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return events, nil
+}
+
+// lockWriters tries to take the exclusive advisory lock shared by the writers of the
+// account of the filter, or else of its organization, and reports whether it got it.
+// The lock is released when the transaction ends.
+func lockWriters(ctx context.Context, tx *sql.Tx, filter Filter) (bool, error) {
+	var key string
+	switch {
+	case filter.OrganizationIBAN != "":
+		key = accountLockPrefix + filter.OrganizationIBAN
+	case filter.OrganizationName != "":
+		key = organizationLockPrefix + filter.OrganizationName
+	default:
+		return false, nil
+	}
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock(hashtextextended($1, 0))`, key).Scan(&locked); err != nil {
+		return false, fmt.Errorf("failed to lock event writers: %w", err)
+	}
+	return locked, nil
+}
@@ -0,0 +1,189 @@
+package event
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+type PostgresRepositoryTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	pgContainer testcontainers.Container
+	db          *sql.DB
+	repo        Repository
+}
+
+func TestPostgresRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresRepositoryTestSuite))
+}
+
+func (s *PostgresRepositoryTestSuite) SetupSuite() {
+	s.ctx = context.Background()
+	req := testcontainers.ContainerRequest{
+		Image:        "postgres:13",
+		ExposedPorts: []string{"5432/tcp"},
+		WaitingFor:   wait.ForListeningPort("5432/tcp"),
+		Env: map[string]string{
+			"POSTGRES_DB":       "testdb",
+			"POSTGRES_USER":     "testuser",
+			"POSTGRES_PASSWORD": "testpass",
+		},
+	}
+
+	var err error
+	s.pgContainer, err = testcontainers.GenericContainer(s.ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	s.Require().NoError(err)
+
+	host, err := s.pgContainer.Host(s.ctx)
+	s.Require().NoError(err)
+
+	port, err := s.pgContainer.MappedPort(s.ctx, "5432")
+	s.Require().NoError(err)
+
+	dbURL := fmt.Sprintf("postgres://testuser:testpass@%s:%s/testdb?sslmode=disable", host, port.Port())
+	s.db, err = sql.Open("postgres", dbURL)
+	s.Require().NoError(err)
+
+	s.repo = NewPostgresRepository(s.db)
+
+	err = s.db.Ping()
+	s.Require().NoError(err)
+
+	s.createSchema()
+}
+
+func (s *PostgresRepositoryTestSuite) TearDownSuite() {
+	s.db.Close()
+	s.pgContainer.Terminate(s.ctx)
+}
+
+func (s *PostgresRepositoryTestSuite) createSchema() {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS events (
+			seq BIGSERIAL PRIMARY KEY,
+			type TEXT NOT NULL,
+			organization_name TEXT NOT NULL,
+			organization_iban TEXT NOT NULL,
+			bank_account_id BIGINT,
+			batch_id BIGINT,
+			transfer_id BIGINT,
+			data JSONB NOT NULL DEFAULT '{}',
+			txid XID8 NOT NULL DEFAULT pg_current_xact_id(),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`)
+	s.Require().NoError(err)
+}
+
+// lastSeq returns the sequence of the last event appended so far
+func (s *PostgresRepositoryTestSuite) lastSeq() int64 {
+	var seq int64
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM events`).Scan(&seq)
+	s.Require().NoError(err)
+	return seq
+}
+
+func (s *PostgresRepositoryTestSuite) appendEvent(tx *sql.Tx, organizationName, organizationIBAN string) Event {
+	e := &Event{
+		Type:             TypeBatchReceived,
+		OrganizationName: organizationName,
+		OrganizationIBAN: organizationIBAN,
+		BatchID:          1,
+		Data:             []byte(`{"id":1}`),
+	}
+	err := s.repo.Append(s.ctx, tx, e)
+	s.Require().NoError(err)
+	return *e
+}
+
+func seqs(events []Event) []int64 {
+	var result []int64
+	for _, e := range events {
+		result = append(result, e.Seq)
+	}
+	return result
+}
+
+func (s *PostgresRepositoryTestSuite) TestAppendAndListAfter() {
+	after := s.lastSeq()
+	appended := s.appendEvent(nil, "ACME Corp", "FR10474608000002006107XXXXX")
+
+	events, err := s.repo.ListAfter(s.ctx, Filter{OrganizationName: "ACME Corp"}, after, 10)
+	s.Require().NoError(err)
+	s.Require().Len(events, 1)
+	s.Equal(appended.Seq, events[0].Seq)
+	s.Equal(TypeBatchReceived, events[0].Type)
+	s.Equal("FR10474608000002006107XXXXX", events[0].OrganizationIBAN)
+	s.Equal(int64(1), events[0].BatchID)
+	s.Equal(int64(0), events[0].TransferID)
+	s.JSONEq(`{"id":1}`, string(events[0].Data))
+
+	events, err = s.repo.ListAfter(s.ctx, Filter{OrganizationName: "Other Corp"}, after, 10)
+	s.Require().NoError(err)
+	s.Empty(events)
+}
+
+func (s *PostgresRepositoryTestSuite) TestListAfter_TransactionOfAnotherOrganization() {
+	after := s.lastSeq()
+
+	tx, err := s.db.BeginTx(s.ctx, nil)
+	s.Require().NoError(err)
+	defer tx.Rollback()
+	other := s.appendEvent(tx, "Other Corp", "DE89370400440532013000")
+	own := s.appendEvent(nil, "ACME Corp", "FR10474608000002006107XXXXX")
+
+	// The transaction in progress of another organization does not hold back the stream
+	events, err := s.repo.ListAfter(s.ctx, Filter{OrganizationName: "ACME Corp"}, after, 10)
+	s.Require().NoError(err)
+	s.Equal([]int64{own.Seq}, seqs(events))
+
+	events, err = s.repo.ListAfter(s.ctx, Filter{OrganizationIBAN: "FR10474608000002006107XXXXX"}, after, 10)
+	s.Require().NoError(err)
+	s.Equal([]int64{own.Seq}, seqs(events))
+
+	// Readers of every organization wait for the oldest transaction in progress
+	events, err = s.repo.ListAfter(s.ctx, Filter{}, after, 10)
+	s.Require().NoError(err)
+	s.Empty(events)
+
+	s.Require().NoError(tx.Commit())
+
+	events, err = s.repo.ListAfter(s.ctx, Filter{}, after, 10)
+	s.Require().NoError(err)
+	s.Equal([]int64{other.Seq, own.Seq}, seqs(events))
+}
+
+func (s *PostgresRepositoryTestSuite) TestListAfter_TransactionOfTheSameOrganization() {
+	after := s.lastSeq()
+
+	tx, err := s.db.BeginTx(s.ctx, nil)
+	s.Require().NoError(err)
+	defer tx.Rollback()
+	first := s.appendEvent(tx, "ACME Corp", "FR10474608000002006107XXXXX")
+	second := s.appendEvent(nil, "ACME Corp", "FR10474608000002006107XXXXX")
+
+	// The event committed first is held back until the one with the lower sequence is committed
+	events, err := s.repo.ListAfter(s.ctx, Filter{OrganizationName: "ACME Corp"}, after, 10)
+	s.Require().NoError(err)
+	s.Empty(events)
+
+	events, err = s.repo.ListAfter(s.ctx, Filter{OrganizationIBAN: "FR10474608000002006107XXXXX"}, after, 10)
+	s.Require().NoError(err)
+	s.Empty(events)
+
+	s.Require().NoError(tx.Commit())
+
+	events, err = s.repo.ListAfter(s.ctx, Filter{OrganizationName: "ACME Corp"}, after, 10)
+	s.Require().NoError(err)
+	s.Equal([]int64{first.Seq, second.Seq}, seqs(events))
+}
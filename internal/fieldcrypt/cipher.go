@@ -0,0 +1,165 @@
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix starts every encrypted value, values without it are stored in clear. Values of the
+// former v1 format are bound to their column only, they are still read until re-encrypted.
+const (
+	prefix   = "enc:v2:"
+	prefixV1 = "enc:v1:"
+)
+
+var (
+	// ErrUnknownKey is returned when a value is encrypted with a key that is not in the keyring
+	ErrUnknownKey = errors.New("unknown encryption key")
+	// ErrInvalidCiphertext is returned when an encrypted value is malformed or was tampered with
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+// Encrypt encrypts the value of the column in the row with a new data key, itself encrypted with
+// the primary key. The result is enc:v2:<key id>:<encrypted data key>:<encrypted value>.
+// The row is the key of the row the value is stored in, such as its ID, so that values cannot be
+// moved from one row to another without failing their decryption.
+func (k *Keyring) Encrypt(plaintext, column, row string) (string, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	// The data key is bound to the ID of the key it is encrypted with, the value to its column and row
+	wrapped, err := seal(k.keys[k.primary], dataKey, []byte(k.primary))
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(dataKey, []byte(plaintext), valueAAD(prefix, column, row))
+	if err != nil {
+		return "", err
+	}
+	return prefix + k.primary + ":" + base64.RawStdEncoding.EncodeToString(wrapped) + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the value of the column in the row stored as stored. Values stored in clear,
+// before the encryption was enabled or the re-encryption ran, are returned as they are.
+func (k *Keyring) Decrypt(stored, column, row string) (string, error) {
+	version, keyID, wrapped, sealed, ok := parse(stored)
+	if !ok {
+		if strings.HasPrefix(stored, prefix) || strings.HasPrefix(stored, prefixV1) {
+			return "", fmt.Errorf("%w: malformed value of %s", ErrInvalidCiphertext, column)
+		}
+		return stored, nil
+	}
+	key, ok := k.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	dataKey, err := open(key, wrapped, []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("%w: data key of %s: %w", ErrInvalidCiphertext, column, err)
+	}
+	plaintext, err := open(dataKey, sealed, valueAAD(version, column, row))
+	if err != nil {
+		return "", fmt.Errorf("%w: value of %s: %w", ErrInvalidCiphertext, column, err)
+	}
+	return string(plaintext), nil
+}
+
+// KeyID returns the ID of the key a stored value is encrypted with, false if it is stored in clear
+func KeyID(stored string) (string, bool) {
+	_, keyID, _, _, ok := parse(stored)
+	return keyID, ok
+}
+
+// Current reports whether a stored value is encrypted with the primary key in the current format,
+// values stored in clear, encrypted with a retired key or in the v1 format are not
+func (k *Keyring) Current(stored string) bool {
+	version, keyID, _, _, ok := parse(stored)
+	return ok && version == prefix && keyID == k.primary
+}
+
+// valueAAD returns the additional data a value of the format of prefix is bound to: its column,
+// and for the current format its row, separated by a byte that no column name holds
+func valueAAD(version, column, row string) []byte {
+	if version == prefixV1 {
+		return []byte(column)
+	}
+	return []byte(column + "\x00" + row)
+}
+
+// BlindIndex returns the index of the value, a keyed hash of the value with its spaces
+// removed and in upper case, so that IBANs are found however they were written
+func (k *Keyring) BlindIndex(value string) string {
+	normalized := strings.ToUpper(strings.Join(strings.Fields(value), ""))
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parse splits an encrypted value into the prefix of its format, the ID of its key, its encrypted
+// data key and its encrypted value
+func parse(stored string) (string, string, []byte, []byte, bool) {
+	version := prefix
+	rest, ok := strings.CutPrefix(stored, prefix)
+	if !ok {
+		version = prefixV1
+		if rest, ok = strings.CutPrefix(stored, prefixV1); !ok {
+			return "", "", nil, nil, false
+		}
+	}
+	parts := strings.Split(rest, ":")
+	if len(parts) != 3 || !keyIDPattern.MatchString(parts[0]) {
+		return "", "", nil, nil, false
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", "", nil, nil, false
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", "", nil, nil, false
+	}
+	return version, parts[0], wrapped, sealed, true
+}
+
+// seal encrypts plaintext with AES-GCM under key, the random nonce preceding the ciphertext
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts a ciphertext sealed by seal
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,15 @@
+// Package fieldcrypt provides the encryption at rest of the personal data stored in columns.
+//
+// Values are encrypted with envelope encryption: each value is encrypted with AES-256-GCM
+// under a random data key, which is itself encrypted under a key of the keyring. The ID of
+// that key is stored with the ciphertext, so that the keyring can hold retired keys to read
+// the values encrypted before a rotation while new values are encrypted with its primary key.
+// The ciphertext is bound to the column and to the row it is stored in, so that values moved
+// between rows fail their decryption. Blind indexes, keyed hashes of
+// normalized values, allow exact-match searches on encrypted columns.
+//
+// Key components:
+//   - Keyring: Struct holding the keys, read from a keyring file or from environment variables
+//   - Encrypt / Decrypt: Methods sealing and opening the values of a column in a row
+//   - BlindIndex: Method computing the searchable index of a value
+package fieldcrypt
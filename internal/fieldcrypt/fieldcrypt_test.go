@@ -0,0 +1,205 @@
+package fieldcrypt
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	column = "transfers.counterparty_iban"
+	row    = "42"
+)
+
+func key(b byte) []byte {
+	k := make([]byte, KeySize)
+	for i := range k {
+		k[i] = b
+	}
+	return k
+}
+
+func encoded(b byte) string {
+	return base64.StdEncoding.EncodeToString(key(b))
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	k, err := NewKeyring(map[string][]byte{"k1": key(1)}, "k1", key(9))
+	require.NoError(t, err)
+
+	stored, err := k.Encrypt("FR7630006000011234567890189", column, row)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored, "enc:v2:k1:"))
+	assert.NotContains(t, stored, "FR76")
+
+	t.Run("Round trip", func(t *testing.T) {
+		plaintext, err := k.Decrypt(stored, column, row)
+		require.NoError(t, err)
+		assert.Equal(t, "FR7630006000011234567890189", plaintext)
+	})
+
+	t.Run("Values are encrypted with a new data key each time", func(t *testing.T) {
+		again, err := k.Encrypt("FR7630006000011234567890189", column, row)
+		require.NoError(t, err)
+		assert.NotEqual(t, stored, again)
+	})
+
+	t.Run("Ciphertext is bound to its column", func(t *testing.T) {
+		_, err := k.Decrypt(stored, "transfers.description", row)
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("Ciphertext is bound to its row", func(t *testing.T) {
+		_, err := k.Decrypt(stored, column, "43")
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("Tampered ciphertext", func(t *testing.T) {
+		// The value of another ciphertext does not open with the data key of this one
+		other, err := k.Encrypt("FR7630006000011234567890189", column, row)
+		require.NoError(t, err)
+		parts, otherParts := strings.Split(stored, ":"), strings.Split(other, ":")
+		parts[4] = otherParts[4]
+		_, err = k.Decrypt(strings.Join(parts, ":"), column, row)
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("Malformed ciphertext", func(t *testing.T) {
+		_, err := k.Decrypt("enc:v2:k1:not-base64", column, row)
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+		_, err = k.Decrypt("enc:v1:k1:not-base64", column, row)
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("Values in clear are returned as they are", func(t *testing.T) {
+		plaintext, err := k.Decrypt("Jean Dupont", column, row)
+		require.NoError(t, err)
+		assert.Equal(t, "Jean Dupont", plaintext)
+	})
+
+	t.Run("Key ID", func(t *testing.T) {
+		id, ok := KeyID(stored)
+		assert.True(t, ok)
+		assert.Equal(t, "k1", id)
+		_, ok = KeyID("Jean Dupont")
+		assert.False(t, ok)
+	})
+}
+
+func TestKeyring_V1Values(t *testing.T) {
+	k, err := NewKeyring(map[string][]byte{"k1": key(1)}, "k1", key(9))
+	require.NoError(t, err)
+
+	// A value of the v1 format, bound to its column only
+	dataKey := key(5)
+	wrapped, err := seal(key(1), dataKey, []byte("k1"))
+	require.NoError(t, err)
+	sealed, err := seal(dataKey, []byte("Jean Dupont"), []byte(column))
+	require.NoError(t, err)
+	stored := "enc:v1:k1:" + base64.RawStdEncoding.EncodeToString(wrapped) + ":" + base64.RawStdEncoding.EncodeToString(sealed)
+
+	plaintext, err := k.Decrypt(stored, column, row)
+	require.NoError(t, err)
+	assert.Equal(t, "Jean Dupont", plaintext)
+
+	// It is re-encrypted in the current format, although its key is the primary one
+	assert.False(t, k.Current(stored))
+	id, ok := KeyID(stored)
+	assert.True(t, ok)
+	assert.Equal(t, "k1", id)
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	old, err := NewKeyring(map[string][]byte{"k1": key(1)}, "k1", key(9))
+	require.NoError(t, err)
+	stored, err := old.Encrypt("Loyer", column, row)
+	require.NoError(t, err)
+
+	rotated, err := NewKeyring(map[string][]byte{"k1": key(1), "k2": key(2)}, "k2", key(9))
+	require.NoError(t, err)
+	plaintext, err := rotated.Decrypt(stored, column, row)
+	require.NoError(t, err)
+	assert.Equal(t, "Loyer", plaintext)
+
+	reencrypted, err := rotated.Encrypt(plaintext, column, row)
+	require.NoError(t, err)
+	id, _ := KeyID(reencrypted)
+	assert.Equal(t, "k2", id)
+
+	assert.False(t, rotated.Current(stored))
+	assert.True(t, rotated.Current(reencrypted))
+	assert.False(t, rotated.Current("Loyer"))
+
+	retired, err := NewKeyring(map[string][]byte{"k2": key(2)}, "k2", key(9))
+	require.NoError(t, err)
+	_, err = retired.Decrypt(stored, column, row)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestKeyring_BlindIndex(t *testing.T) {
+	k, err := NewKeyring(map[string][]byte{"k1": key(1)}, "k1", key(9))
+	require.NoError(t, err)
+	other, err := NewKeyring(map[string][]byte{"k1": key(1)}, "k1", key(8))
+	require.NoError(t, err)
+
+	index := k.BlindIndex("FR7630006000011234567890189")
+	assert.Len(t, index, 64)
+	assert.Equal(t, index, k.BlindIndex("fr76 3000 6000 0112 3456 7890 189"))
+	assert.NotEqual(t, index, k.BlindIndex("FR7630006000011234567890188"))
+	assert.NotEqual(t, index, other.BlindIndex("FR7630006000011234567890189"))
+}
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     map[string][]byte
+		primary  string
+		indexKey []byte
+		wantErr  string
+	}{
+		{name: "No key", keys: map[string][]byte{}, primary: "k1", indexKey: key(9), wantErr: "no key"},
+		{name: "Invalid key ID", keys: map[string][]byte{"k:1": key(1)}, primary: "k:1", indexKey: key(9), wantErr: "invalid key ID"},
+		{name: "Short key", keys: map[string][]byte{"k1": key(1)[:16]}, primary: "k1", indexKey: key(9), wantErr: "16 bytes long"},
+		{name: "Unknown primary key", keys: map[string][]byte{"k1": key(1)}, primary: "k2", indexKey: key(9), wantErr: "not in the keyring"},
+		{name: "Missing index key", keys: map[string][]byte{"k1": key(1)}, primary: "k1", wantErr: "index key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.keys, tt.primary, tt.indexKey)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadKeyringFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	content := `{"primary_key_id": "2024-06", "keys": {"2024-01": "` + encoded(1) + `", "2024-06": "` + encoded(2) + `"}, "index_key": "` + encoded(9) + `"}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	k, err := LoadKeyringFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06", k.PrimaryKeyID())
+	assert.Equal(t, []string{"2024-01", "2024-06"}, k.KeyIDs())
+
+	_, err = LoadKeyringFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read keyring file")
+}
+
+func TestParseKeyring(t *testing.T) {
+	k, err := ParseKeyring("k1:"+encoded(1)+", k2:"+encoded(2), "k2", encoded(9))
+	require.NoError(t, err)
+	assert.Equal(t, "k2", k.PrimaryKeyID())
+	assert.Equal(t, []string{"k1", "k2"}, k.KeyIDs())
+
+	_, err = ParseKeyring(encoded(1), "k1", encoded(9))
+	assert.ErrorContains(t, err, "<id>:<base64 key>")
+
+	_, err = ParseKeyring("k1:not base64", "k1", encoded(9))
+	assert.ErrorContains(t, err, "invalid base64 key")
+}
@@ -0,0 +1,141 @@
+package fieldcrypt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// KeySize is the size in bytes of the keys of a keyring, AES-256 keys
+const KeySize = 32
+
+// keyIDPattern restricts key IDs to characters that need no escaping in the stored values
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,32}$`)
+
+// Keyring holds the keys encrypting the data keys of the values, by ID, and the key of the blind indexes
+type Keyring struct {
+	keys map[string][]byte
+	// primary is the ID of the key encrypting new values
+	primary  string
+	indexKey []byte
+}
+
+// NewKeyring returns a keyring of the keys by ID, encrypting new values with the primary key.
+// Every key, the index key included, must be KeySize bytes long.
+func NewKeyring(keys map[string][]byte, primary string, indexKey []byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("keyring has no key")
+	}
+	k := &Keyring{keys: make(map[string][]byte, len(keys)), primary: primary}
+	for id, key := range keys {
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("invalid key ID %q, expected 1 to 32 letters, digits or dashes", id)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("key %s is %d bytes long, expected %d", id, len(key), KeySize)
+		}
+		k.keys[id] = key
+	}
+	if _, ok := k.keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key %q is not in the keyring", primary)
+	}
+	if len(indexKey) != KeySize {
+		return nil, fmt.Errorf("index key is %d bytes long, expected %d", len(indexKey), KeySize)
+	}
+	k.indexKey = indexKey
+	return k, nil
+}
+
+// keyringFile is the JSON document of a keyring file, keys being base64 encoded
+type keyringFile struct {
+	PrimaryKeyID string            `json:"primary_key_id"`
+	Keys         map[string]string `json:"keys"`
+	IndexKey     string            `json:"index_key"`
+}
+
+// LoadKeyringFile reads a keyring from a JSON file such as
+//
+//	{"primary_key_id": "2024-06", "keys": {"2024-01": "<base64>", "2024-06": "<base64>"}, "index_key": "<base64>"}
+func LoadKeyringFile(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring file: %w", err)
+	}
+	var f keyringFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode keyring file %s: %w", path, err)
+	}
+	keys := make(map[string][]byte, len(f.Keys))
+	for id, encoded := range f.Keys {
+		if keys[id], err = decodeKey(encoded); err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+	}
+	indexKey, err := decodeKey(f.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("index key: %w", err)
+	}
+	return NewKeyring(keys, f.PrimaryKeyID, indexKey)
+}
+
+// ParseKeyring returns the keyring given by environment variables: keys is a comma-separated
+// list of <id>:<base64 key> pairs, primary the ID of the primary key and indexKey the base64
+// encoded key of the blind indexes
+func ParseKeyring(keys, primary, indexKey string) (*Keyring, error) {
+	parsed := make(map[string][]byte)
+	for _, pair := range strings.Split(keys, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, errors.New("keys must be a comma-separated list of <id>:<base64 key> pairs")
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		parsed[id] = key
+	}
+	decodedIndexKey, err := decodeKey(indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("index key: %w", err)
+	}
+	return NewKeyring(parsed, primary, decodedIndexKey)
+}
+
+// decodeKey decodes a base64 encoded key
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 key: %w", err)
+	}
+	return key, nil
+}
+
+// GenerateKey returns a random key, base64 encoded as keyrings expect it
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// PrimaryKeyID returns the ID of the key encrypting new values
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+// KeyIDs returns the IDs of the keys of the keyring, sorted
+func (k *Keyring) KeyIDs() []string {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,223 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+const (
+	// NamespaceCamt053V02 is the namespace of camt.053.001.02 bank to customer statements
+	NamespaceCamt053V02 = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+	// BalanceOpeningBooked is the type of the booked balance at the start of a statement
+	BalanceOpeningBooked = "OPBD"
+	// BalanceClosingBooked is the type of the booked balance at the end of a statement
+	BalanceClosingBooked = "CLBD"
+
+	// Credit and Debit indicate whether an entry or a balance is a credit or a debit
+	Credit = "CRDT"
+	Debit  = "DBIT"
+
+	// EntryStatusBooked is the status of entries booked on the account
+	EntryStatusBooked = "BOOK"
+)
+
+// StatementGroupHeader identifies a statement message
+type StatementGroupHeader struct {
+	MessageID        string `xml:"MsgId"`
+	CreationDateTime string `xml:"CreDtTm"`
+}
+
+// Statement is what a camt.053 statement holds besides its entries
+type Statement struct {
+	ID               string             `xml:"Id"`
+	CreationDateTime string             `xml:"CreDtTm"`
+	FromToDate       DateTimePeriod     `xml:"FrToDt"`
+	Account          StatementAccount   `xml:"Acct"`
+	Balances         []Balance          `xml:"Bal"`
+	Summary          *TransactionsTotal `xml:"TxsSummry,omitempty"`
+}
+
+type DateTimePeriod struct {
+	From string `xml:"FrDtTm"`
+	To   string `xml:"ToDtTm"`
+}
+
+// StatementAccount identifies the reported account, its owner and the bank servicing it
+type StatementAccount struct {
+	ID       AccountID `xml:"Id"`
+	Currency string    `xml:"Ccy,omitempty"`
+	Owner    *Party    `xml:"Ownr,omitempty"`
+	Servicer *Agent    `xml:"Svcr,omitempty"`
+}
+
+// Balance is the booked balance of the account at a date
+type Balance struct {
+	Type                 BalanceType `xml:"Tp"`
+	Amount               Amount      `xml:"Amt"`
+	CreditDebitIndicator string      `xml:"CdtDbtInd"`
+	Date                 Date        `xml:"Dt"`
+}
+
+type BalanceType struct {
+	CodeOrProprietary struct {
+		Code string `xml:"Cd"`
+	} `xml:"CdOrPrtry"`
+}
+
+// Date is a date given in a Dt child element
+type Date struct {
+	Date string `xml:"Dt"`
+}
+
+// NewBalance returns a balance of the given type, amount and date. The amount is
+// given as an absolute decimal value with whether the balance is a credit or a debit.
+func NewBalance(balanceType string, amount Amount, creditDebit, date string) Balance {
+	b := Balance{Amount: amount, CreditDebitIndicator: creditDebit, Date: Date{Date: date}}
+	b.Type.CodeOrProprietary.Code = balanceType
+	return b
+}
+
+// TransactionsTotal counts and sums the credit and debit entries of a statement
+type TransactionsTotal struct {
+	Credits NumberAndSum `xml:"TtlCdtNtries"`
+	Debits  NumberAndSum `xml:"TtlDbtNtries"`
+}
+
+type NumberAndSum struct {
+	NumberOfEntries string `xml:"NbOfNtries"`
+	Sum             string `xml:"Sum"`
+}
+
+// Entry is a movement of the account
+type Entry struct {
+	Amount               Amount              `xml:"Amt"`
+	CreditDebitIndicator string              `xml:"CdtDbtInd"`
+	Status               string              `xml:"Sts"`
+	BookingDate          Date                `xml:"BookgDt"`
+	ValueDate            Date                `xml:"ValDt"`
+	AccountServicerRef   string              `xml:"AcctSvcrRef,omitempty"`
+	BankTransactionCode  BankTransactionCode `xml:"BkTxCd"`
+	Details              []EntryTransaction  `xml:"NtryDtls>TxDtls,omitempty"`
+}
+
+// BankTransactionCode classifies an entry, such as PMNT/ICDT/ESCT for an issued SEPA credit transfer
+type BankTransactionCode struct {
+	Domain struct {
+		Code   string `xml:"Cd"`
+		Family struct {
+			Code          string `xml:"Cd"`
+			SubFamilyCode string `xml:"SubFmlyCd"`
+		} `xml:"Fmly"`
+	} `xml:"Domn"`
+}
+
+// NewBankTransactionCode returns the bank transaction code of the given domain, family and sub-family
+func NewBankTransactionCode(domain, family, subFamily string) BankTransactionCode {
+	var c BankTransactionCode
+	c.Domain.Code = domain
+	c.Domain.Family.Code = family
+	c.Domain.Family.SubFamilyCode = subFamily
+	return c
+}
+
+// EntryTransaction gives the references and the parties of the transaction of an entry
+type EntryTransaction struct {
+	References            TransactionReferences  `xml:"Refs"`
+	RelatedParties        *RelatedParties        `xml:"RltdPties,omitempty"`
+	RelatedAgents         *RelatedAgents         `xml:"RltdAgts,omitempty"`
+	RemittanceInformation *RemittanceInformation `xml:"RmtInf,omitempty"`
+	ReturnInformation     *ReturnInformation     `xml:"RtrInf,omitempty"`
+}
+
+type TransactionReferences struct {
+	AccountServicerRef   string `xml:"AcctSvcrRef,omitempty"`
+	PaymentInformationID string `xml:"PmtInfId,omitempty"`
+	EndToEndID           string `xml:"EndToEndId,omitempty"`
+}
+
+type RelatedParties struct {
+	Creditor        *Party   `xml:"Cdtr,omitempty"`
+	CreditorAccount *Account `xml:"CdtrAcct,omitempty"`
+}
+
+type RelatedAgents struct {
+	CreditorAgent *Agent `xml:"CdtrAgt,omitempty"`
+}
+
+// ReturnInformation explains why a payment was returned, with a reason code or free text
+type ReturnInformation struct {
+	Reason                *StatusReasonCode `xml:"Rsn,omitempty"`
+	AdditionalInformation string            `xml:"AddtlInf,omitempty"`
+}
+
+// Camt053Writer streams a camt.053 statement, so that the entries of large statements
+// are never held in memory. The balances of the statement are written first, and its
+// entries follow one after the other.
+type Camt053Writer struct {
+	enc *xml.Encoder
+}
+
+// NewCamt053Writer starts a camt.053.001.02 statement message on w, with its group header
+// and the statement up to its entries
+func NewCamt053Writer(w io.Writer, header StatementGroupHeader, statement Statement) (*Camt053Writer, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	root := xml.StartElement{Name: xml.Name{Local: "Document"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: NamespaceCamt053V02}}}
+	if err := enc.EncodeToken(root); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeToken(startElement("BkToCstmrStmt")); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeElement(header, startElement("GrpHdr")); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeToken(startElement("Stmt")); err != nil {
+		return nil, err
+	}
+	// The fields of the statement are encoded one by one, as its element is left open for the entries
+	for _, field := range []struct {
+		name  string
+		value any
+	}{
+		{"Id", statement.ID},
+		{"CreDtTm", statement.CreationDateTime},
+		{"FrToDt", statement.FromToDate},
+		{"Acct", statement.Account},
+	} {
+		if err := enc.EncodeElement(field.value, startElement(field.name)); err != nil {
+			return nil, err
+		}
+	}
+	for _, balance := range statement.Balances {
+		if err := enc.EncodeElement(balance, startElement("Bal")); err != nil {
+			return nil, err
+		}
+	}
+	if statement.Summary != nil {
+		if err := enc.EncodeElement(statement.Summary, startElement("TxsSummry")); err != nil {
+			return nil, err
+		}
+	}
+	return &Camt053Writer{enc: enc}, nil
+}
+
+// WriteEntry writes an entry of the statement
+func (w *Camt053Writer) WriteEntry(e Entry) error {
+	return w.enc.EncodeElement(e, startElement("Ntry"))
+}
+
+// Close ends the statement message and flushes it, it does not close the underlying writer
+func (w *Camt053Writer) Close() error {
+	for _, name := range []string{"Stmt", "BkToCstmrStmt", "Document"} {
+		if err := w.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	return w.enc.Close()
+}
@@ -0,0 +1,167 @@
+package iso20022
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCamt053Writer(t *testing.T) {
+	var out strings.Builder
+	w, err := NewCamt053Writer(&out,
+		StatementGroupHeader{MessageID: "CAMT-1", CreationDateTime: "2024-03-05T06:00:00Z"},
+		Statement{
+			ID:               "STMT-1",
+			CreationDateTime: "2024-03-05T06:00:00Z",
+			FromToDate:       DateTimePeriod{From: "2024-03-04T00:00:00Z", To: "2024-03-05T00:00:00Z"},
+			Account: StatementAccount{
+				ID:       AccountID{IBAN: "FR1420041010050500013M02606"},
+				Currency: "EUR",
+				Owner:    &Party{Name: "ACME Corp"},
+				Servicer: &Agent{FinancialInstitution: FinancialInstitution{BIC: "BNPAFRPP"}},
+			},
+			Balances: []Balance{
+				NewBalance(BalanceOpeningBooked, Amount{Value: "1000.00", Currency: "EUR"}, Credit, "2024-03-04"),
+				NewBalance(BalanceClosingBooked, Amount{Value: "899.50", Currency: "EUR"}, Credit, "2024-03-04"),
+			},
+			Summary: &TransactionsTotal{
+				Credits: NumberAndSum{NumberOfEntries: "0", Sum: "0.00"},
+				Debits:  NumberAndSum{NumberOfEntries: "1", Sum: "100.50"},
+			},
+		})
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteEntry(Entry{
+		Amount:               Amount{Value: "100.50", Currency: "EUR"},
+		CreditDebitIndicator: Debit,
+		Status:               EntryStatusBooked,
+		BookingDate:          Date{Date: "2024-03-04"},
+		ValueDate:            Date{Date: "2024-03-04"},
+		AccountServicerRef:   "T1",
+		BankTransactionCode:  NewBankTransactionCode("PMNT", "ICDT", "ESCT"),
+		Details: []EntryTransaction{{
+			References:            TransactionReferences{AccountServicerRef: "T1", EndToEndID: "E2E-1"},
+			RelatedParties:        &RelatedParties{Creditor: &Party{Name: "John Doe"}, CreditorAccount: &Account{ID: AccountID{IBAN: "DE89370400440532013000"}}},
+			RelatedAgents:         &RelatedAgents{CreditorAgent: &Agent{FinancialInstitution: FinancialInstitution{BIC: "DEUTDEFF"}}},
+			RemittanceInformation: &RemittanceInformation{Unstructured: []string{"Invoice 1"}},
+		}},
+	}))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <GrpHdr>
+      <MsgId>CAMT-1</MsgId>
+      <CreDtTm>2024-03-05T06:00:00Z</CreDtTm>
+    </GrpHdr>
+    <Stmt>
+      <Id>STMT-1</Id>
+      <CreDtTm>2024-03-05T06:00:00Z</CreDtTm>
+      <FrToDt>
+        <FrDtTm>2024-03-04T00:00:00Z</FrDtTm>
+        <ToDtTm>2024-03-05T00:00:00Z</ToDtTm>
+      </FrToDt>
+      <Acct>
+        <Id>
+          <IBAN>FR1420041010050500013M02606</IBAN>
+        </Id>
+        <Ccy>EUR</Ccy>
+        <Ownr>
+          <Nm>ACME Corp</Nm>
+        </Ownr>
+        <Svcr>
+          <FinInstnId>
+            <BIC>BNPAFRPP</BIC>
+          </FinInstnId>
+        </Svcr>
+      </Acct>
+      <Bal>
+        <Tp>
+          <CdOrPrtry>
+            <Cd>OPBD</Cd>
+          </CdOrPrtry>
+        </Tp>
+        <Amt Ccy="EUR">1000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt>
+          <Dt>2024-03-04</Dt>
+        </Dt>
+      </Bal>
+      <Bal>
+        <Tp>
+          <CdOrPrtry>
+            <Cd>CLBD</Cd>
+          </CdOrPrtry>
+        </Tp>
+        <Amt Ccy="EUR">899.50</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt>
+          <Dt>2024-03-04</Dt>
+        </Dt>
+      </Bal>
+      <TxsSummry>
+        <TtlCdtNtries>
+          <NbOfNtries>0</NbOfNtries>
+          <Sum>0.00</Sum>
+        </TtlCdtNtries>
+        <TtlDbtNtries>
+          <NbOfNtries>1</NbOfNtries>
+          <Sum>100.50</Sum>
+        </TtlDbtNtries>
+      </TxsSummry>
+      <Ntry>
+        <Amt Ccy="EUR">100.50</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt>
+          <Dt>2024-03-04</Dt>
+        </BookgDt>
+        <ValDt>
+          <Dt>2024-03-04</Dt>
+        </ValDt>
+        <AcctSvcrRef>T1</AcctSvcrRef>
+        <BkTxCd>
+          <Domn>
+            <Cd>PMNT</Cd>
+            <Fmly>
+              <Cd>ICDT</Cd>
+              <SubFmlyCd>ESCT</SubFmlyCd>
+            </Fmly>
+          </Domn>
+        </BkTxCd>
+        <NtryDtls>
+          <TxDtls>
+            <Refs>
+              <AcctSvcrRef>T1</AcctSvcrRef>
+              <EndToEndId>E2E-1</EndToEndId>
+            </Refs>
+            <RltdPties>
+              <Cdtr>
+                <Nm>John Doe</Nm>
+              </Cdtr>
+              <CdtrAcct>
+                <Id>
+                  <IBAN>DE89370400440532013000</IBAN>
+                </Id>
+              </CdtrAcct>
+            </RltdPties>
+            <RltdAgts>
+              <CdtrAgt>
+                <FinInstnId>
+                  <BIC>DEUTDEFF</BIC>
+                </FinInstnId>
+              </CdtrAgt>
+            </RltdAgts>
+            <RmtInf>
+              <Ustrd>Invoice 1</Ustrd>
+            </RmtInf>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`, out.String())
+}
@@ -0,0 +1,13 @@
+// Package iso20022 provides the ISO 20022 payment messages exchanged with corporate customers.
+//
+// Messages are XML documents whose root element namespace names the message and its version.
+// Only the elements the service needs are modelled; other elements are ignored when reading.
+//
+// Key components:
+//   - GroupHeader, PaymentInformation, CreditTransferTransaction: Blocks of a pain.001 message
+//   - ReadPain001: Streams the credit transfer transactions of a pain.001 message
+//   - Pain001Writer: Streams a pain.001 credit transfer initiation, by payment information block
+//   - Pain002Writer: Streams a pain.002 payment status report, by payment information block
+//   - Camt053Writer: Streams a camt.053 account statement, its balances first and then its entries
+//   - Amount: Decimal amount with the currency it is expressed in
+package iso20022
@@ -0,0 +1,411 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// NamespacePain001V03 is the namespace of pain.001.001.03 customer credit transfer initiations
+	NamespacePain001V03 = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+	// NamespacePain001V09 is the namespace of pain.001.001.09 customer credit transfer initiations
+	NamespacePain001V09 = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"
+
+	// PaymentMethodTransfer is the payment method of credit transfers
+	PaymentMethodTransfer = "TRF"
+	// ServiceLevelSEPA is the service level of SEPA credit transfers
+	ServiceLevelSEPA = "SEPA"
+	// ChargeBearerSLEV is the charge bearer of SEPA credit transfers, charges follow the service level
+	ChargeBearerSLEV = "SLEV"
+)
+
+// GroupHeader is the set of characteristics shared by all payments of a message
+type GroupHeader struct {
+	MessageID        string `xml:"MsgId"`
+	CreationDateTime string `xml:"CreDtTm"`
+	// NumberOfTransactions is the number of credit transfer transactions of the message
+	NumberOfTransactions string `xml:"NbOfTxs"`
+	// ControlSum is the sum of the amounts of all transactions of the message, it is optional
+	ControlSum      string `xml:"CtrlSum,omitempty"`
+	InitiatingParty Party  `xml:"InitgPty"`
+}
+
+// Party identifies the debtor, the creditor or the initiating party of a payment
+type Party struct {
+	Name string `xml:"Nm,omitempty"`
+}
+
+// Account identifies a cash account, by IBAN in SEPA payments
+type Account struct {
+	ID       AccountID `xml:"Id"`
+	Currency string    `xml:"Ccy,omitempty"`
+}
+
+type AccountID struct {
+	IBAN string `xml:"IBAN,omitempty"`
+	// Other is set when the account is identified by something other than an IBAN
+	Other *GenericID `xml:"Othr,omitempty"`
+}
+
+type GenericID struct {
+	ID string `xml:"Id"`
+}
+
+// Agent identifies the financial institution of the debtor or the creditor
+type Agent struct {
+	FinancialInstitution FinancialInstitution `xml:"FinInstnId"`
+}
+
+// FinancialInstitution holds the BIC of an agent, its element is BIC in version 03 and BICFI in version 09
+type FinancialInstitution struct {
+	BIC   string `xml:"BIC,omitempty"`
+	BICFI string `xml:"BICFI,omitempty"`
+}
+
+// Code returns the BIC of the financial institution, whichever version it was given in
+func (f FinancialInstitution) Code() string {
+	if f.BICFI != "" {
+		return f.BICFI
+	}
+	return f.BIC
+}
+
+// DateChoice is a date given as the element value in version 03,
+// and as a Dt or DtTm child element in version 09
+type DateChoice struct {
+	Value    string `xml:",chardata"`
+	Date     string `xml:"Dt,omitempty"`
+	DateTime string `xml:"DtTm,omitempty"`
+}
+
+// String returns the date of the element, whichever way it was given
+func (d DateChoice) String() string {
+	switch {
+	case d.Date != "":
+		return d.Date
+	case d.DateTime != "":
+		return d.DateTime
+	default:
+		return strings.TrimSpace(d.Value)
+	}
+}
+
+// PaymentInformation is a set of credit transfers from a single debtor account.
+// Transactions are not held in it but streamed by ReadPain001.
+type PaymentInformation struct {
+	ID                     string                  `xml:"PmtInfId"`
+	PaymentMethod          string                  `xml:"PmtMtd"`
+	NumberOfTransactions   string                  `xml:"NbOfTxs,omitempty"`
+	ControlSum             string                  `xml:"CtrlSum,omitempty"`
+	PaymentTypeInformation *PaymentTypeInformation `xml:"PmtTpInf,omitempty"`
+	RequestedExecutionDate DateChoice              `xml:"ReqdExctnDt"`
+	Debtor                 Party                   `xml:"Dbtr"`
+	DebtorAccount          Account                 `xml:"DbtrAcct"`
+	DebtorAgent            Agent                   `xml:"DbtrAgt"`
+	ChargeBearer           string                  `xml:"ChrgBr,omitempty"`
+}
+
+// PaymentTypeInformation gives the service level of the payments of a block, SEPA for SEPA credit transfers
+type PaymentTypeInformation struct {
+	ServiceLevel struct {
+		Code string `xml:"Cd"`
+	} `xml:"SvcLvl"`
+}
+
+// NewPaymentTypeInformation returns the payment type information of the given service level
+func NewPaymentTypeInformation(serviceLevel string) *PaymentTypeInformation {
+	var p PaymentTypeInformation
+	p.ServiceLevel.Code = serviceLevel
+	return &p
+}
+
+// CreditTransferTransaction is a single credit transfer to a creditor
+type CreditTransferTransaction struct {
+	PaymentID             PaymentID             `xml:"PmtId"`
+	Amount                TransactionAmount     `xml:"Amt"`
+	CreditorAgent         Agent                 `xml:"CdtrAgt"`
+	Creditor              Party                 `xml:"Cdtr"`
+	CreditorAccount       Account               `xml:"CdtrAcct"`
+	RemittanceInformation RemittanceInformation `xml:"RmtInf"`
+}
+
+type PaymentID struct {
+	InstructionID string `xml:"InstrId,omitempty"`
+	EndToEndID    string `xml:"EndToEndId"`
+}
+
+type TransactionAmount struct {
+	Instructed Amount `xml:"InstdAmt"`
+}
+
+// Amount is a decimal amount in the given currency
+type Amount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// RemittanceInformation is the information the creditor receives to reconcile the payment
+type RemittanceInformation struct {
+	Unstructured []string                          `xml:"Ustrd,omitempty"`
+	Structured   []StructuredRemittanceInformation `xml:"Strd,omitempty"`
+}
+
+type StructuredRemittanceInformation struct {
+	CreditorReference struct {
+		Reference string `xml:"Ref"`
+	} `xml:"CdtrRefInf"`
+}
+
+// String returns the unstructured remittance information, or the creditor
+// references of the structured remittance information when there is none
+func (r RemittanceInformation) String() string {
+	var parts []string
+	for _, u := range r.Unstructured {
+		if u = strings.TrimSpace(u); u != "" {
+			parts = append(parts, u)
+		}
+	}
+	if len(parts) == 0 {
+		for _, s := range r.Structured {
+			if ref := strings.TrimSpace(s.CreditorReference.Reference); ref != "" {
+				parts = append(parts, ref)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Pain001Header is what a pain.001 message holds besides its transactions
+type Pain001Header struct {
+	// Namespace identifies the version of the message
+	Namespace          string
+	GroupHeader        GroupHeader
+	PaymentInformation []PaymentInformation
+}
+
+// ReadPain001 reads a pain.001.001.03 or pain.001.001.09 message and streams its
+// credit transfer transactions to yield, along with the payment information block
+// they belong to, so that large messages are never held in memory.
+// Once the whole message has been read, the numbers of transactions and the control
+// sums of the group header and of every payment information block are checked
+// against the transactions, and the header is returned.
+// An error returned by yield stops the reading and is returned as is.
+func ReadPain001(r io.Reader, yield func(*PaymentInformation, *CreditTransferTransaction) error) (*Pain001Header, error) {
+	dec := xml.NewDecoder(r)
+
+	root, err := nextStart(dec)
+	if err != nil {
+		return nil, err
+	}
+	if root.Name.Local != "Document" {
+		return nil, fmt.Errorf("expected a Document root element, got %s", root.Name.Local)
+	}
+	if root.Name.Space != NamespacePain001V03 && root.Name.Space != NamespacePain001V09 {
+		return nil, fmt.Errorf("unsupported message %q, only %s and %s are supported", root.Name.Space, NamespacePain001V03, NamespacePain001V09)
+	}
+
+	initiation, err := nextStart(dec)
+	if err != nil {
+		return nil, err
+	}
+	if initiation.Name.Local != "CstmrCdtTrfInitn" {
+		return nil, fmt.Errorf("expected a CstmrCdtTrfInitn element, got %s", initiation.Name.Local)
+	}
+
+	header := &Pain001Header{Namespace: root.Name.Space}
+	total := newTally()
+	var hasGroupHeader bool
+	for {
+		start, err := nextChild(dec)
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			break
+		}
+
+		switch start.Name.Local {
+		case "GrpHdr":
+			if err := dec.DecodeElement(&header.GroupHeader, start); err != nil {
+				return nil, fmt.Errorf("failed to parse group header: %w", err)
+			}
+			hasGroupHeader = true
+		case "PmtInf":
+			if !hasGroupHeader {
+				return nil, errors.New("the group header must precede the payment information blocks")
+			}
+			pmtInf, err := readPaymentInformation(dec, total, yield)
+			if err != nil {
+				return nil, err
+			}
+			header.PaymentInformation = append(header.PaymentInformation, *pmtInf)
+		default:
+			if err := dec.Skip(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !hasGroupHeader {
+		return nil, errors.New("the group header is missing")
+	}
+	if len(header.PaymentInformation) == 0 {
+		return nil, errors.New("the message has no payment information block")
+	}
+	if err := total.check("group header", header.GroupHeader.NumberOfTransactions, header.GroupHeader.ControlSum); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// readPaymentInformation reads the payment information block whose start element was just read
+func readPaymentInformation(dec *xml.Decoder, total *tally, yield func(*PaymentInformation, *CreditTransferTransaction) error) (*PaymentInformation, error) {
+	var pmtInf PaymentInformation
+	block := newTally()
+	for {
+		start, err := nextChild(dec)
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			break
+		}
+
+		var target any
+		switch start.Name.Local {
+		case "PmtInfId":
+			target = &pmtInf.ID
+		case "PmtMtd":
+			target = &pmtInf.PaymentMethod
+		case "NbOfTxs":
+			target = &pmtInf.NumberOfTransactions
+		case "CtrlSum":
+			target = &pmtInf.ControlSum
+		case "PmtTpInf":
+			pmtInf.PaymentTypeInformation = &PaymentTypeInformation{}
+			target = pmtInf.PaymentTypeInformation
+		case "ReqdExctnDt":
+			target = &pmtInf.RequestedExecutionDate
+		case "Dbtr":
+			target = &pmtInf.Debtor
+		case "DbtrAcct":
+			target = &pmtInf.DebtorAccount
+		case "DbtrAgt":
+			target = &pmtInf.DebtorAgent
+		case "ChrgBr":
+			target = &pmtInf.ChargeBearer
+		case "CdtTrfTxInf":
+			line, _ := dec.InputPos()
+			var tx CreditTransferTransaction
+			if err := dec.DecodeElement(&tx, start); err != nil {
+				return nil, fmt.Errorf("line %d: failed to parse credit transfer transaction: %w", line, err)
+			}
+			amount, ok := new(big.Rat).SetString(strings.TrimSpace(tx.Amount.Instructed.Value))
+			if !ok || amount.Sign() <= 0 {
+				return nil, fmt.Errorf("line %d: invalid instructed amount %q of transaction %q", line, tx.Amount.Instructed.Value, tx.PaymentID.EndToEndID)
+			}
+			block.add(amount)
+			total.add(amount)
+			if err := yield(&pmtInf, &tx); err != nil {
+				return nil, err
+			}
+			continue
+		default:
+			if err := dec.Skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := dec.DecodeElement(target, start); err != nil {
+			return nil, fmt.Errorf("failed to parse payment information %s: %w", start.Name.Local, err)
+		}
+	}
+
+	if block.count == 0 {
+		return nil, fmt.Errorf("payment information %q has no credit transfer transaction", pmtInf.ID)
+	}
+	if err := block.check(fmt.Sprintf("payment information %q", pmtInf.ID), pmtInf.NumberOfTransactions, pmtInf.ControlSum); err != nil {
+		return nil, err
+	}
+	return &pmtInf, nil
+}
+
+// tally counts and sums transaction amounts exactly
+type tally struct {
+	count int
+	sum   *big.Rat
+}
+
+func newTally() *tally {
+	return &tally{sum: new(big.Rat)}
+}
+
+func (t *tally) add(amount *big.Rat) {
+	t.count++
+	t.sum.Add(t.sum, amount)
+}
+
+// check compares the tally with the declared number of transactions and control sum,
+// an empty declaration is not checked
+func (t *tally) check(block, numberOfTransactions, controlSum string) error {
+	if numberOfTransactions != "" {
+		n, err := strconv.Atoi(strings.TrimSpace(numberOfTransactions))
+		if err != nil {
+			return fmt.Errorf("%s: invalid number of transactions %q", block, numberOfTransactions)
+		}
+		if n != t.count {
+			return fmt.Errorf("%s: number of transactions is %d but %d transactions were found", block, n, t.count)
+		}
+	}
+	if controlSum != "" {
+		sum, ok := new(big.Rat).SetString(strings.TrimSpace(controlSum))
+		if !ok {
+			return fmt.Errorf("%s: invalid control sum %q", block, controlSum)
+		}
+		if sum.Cmp(t.sum) != 0 {
+			return fmt.Errorf("%s: control sum is %s but the transactions sum to %s", block, strings.TrimSpace(controlSum), t.sum.FloatString(2))
+		}
+	}
+	return nil
+}
+
+// nextStart returns the next start element of the document
+func nextStart(dec *xml.Decoder) (*xml.StartElement, error) {
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("unexpected end of document")
+			}
+			return nil, fmt.Errorf("failed to parse XML content: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return &start, nil
+		}
+	}
+}
+
+// nextChild returns the next child start element of the current element,
+// nil once the end of the current element is reached
+func nextChild(dec *xml.Decoder) (*xml.StartElement, error) {
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("unexpected end of document")
+			}
+			return nil, fmt.Errorf("failed to parse XML content: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			return &t, nil
+		case xml.EndElement:
+			return nil, nil
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package iso20022
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pain001V03 is a pain.001.001.03 message with two payment information blocks
+const pain001V03 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>MSG-1</MsgId>
+      <CreDtTm>2024-03-01T10:00:00</CreDtTm>
+      <NbOfTxs>3</NbOfTxs>
+      <CtrlSum>%s</CtrlSum>
+      <InitgPty><Nm>ACME Corp</Nm></InitgPty>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>PMT-1</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <NbOfTxs>2</NbOfTxs>
+      <CtrlSum>150.5</CtrlSum>
+      <ReqdExctnDt>2024-03-04</ReqdExctnDt>
+      <Dbtr><Nm>ACME Corp</Nm></Dbtr>
+      <DbtrAcct><Id><IBAN>FR1420041010050500013M02606</IBAN></Id><Ccy>EUR</Ccy></DbtrAcct>
+      <DbtrAgt><FinInstnId><BIC>BNPAFRPP</BIC></FinInstnId></DbtrAgt>
+      <CdtTrfTxInf>
+        <PmtId><InstrId>I-1</InstrId><EndToEndId>E2E-1</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">100.50</InstdAmt></Amt>
+        <CdtrAgt><FinInstnId><BIC>DEUTDEFF</BIC></FinInstnId></CdtrAgt>
+        <Cdtr><Nm>John Doe</Nm></Cdtr>
+        <CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct>
+        <RmtInf><Ustrd>Invoice 1</Ustrd></RmtInf>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-2</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">50</InstdAmt></Amt>
+        <CdtrAgt><FinInstnId><BIC>NWBKGB2L</BIC></FinInstnId></CdtrAgt>
+        <Cdtr><Nm>Jane Doe</Nm></Cdtr>
+        <CdtrAcct><Id><IBAN>GB29NWBK60161331926819</IBAN></Id></CdtrAcct>
+        <RmtInf><Strd><CdtrRefInf><Ref>RF18539007547034</Ref></CdtrRefInf></Strd></RmtInf>
+      </CdtTrfTxInf>
+    </PmtInf>
+    <PmtInf>
+      <PmtInfId>PMT-2</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <ReqdExctnDt>2024-03-05</ReqdExctnDt>
+      <Dbtr><Nm>ACME Corp</Nm></Dbtr>
+      <DbtrAcct><Id><IBAN>FR1420041010050500013M02606</IBAN></Id></DbtrAcct>
+      <DbtrAgt><FinInstnId><BIC>BNPAFRPP</BIC></FinInstnId></DbtrAgt>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-3</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">0.01</InstdAmt></Amt>
+        <Cdtr><Nm>Max Mustermann</Nm></Cdtr>
+        <CdtrAcct><Id><IBAN>DE02120300000000202051</IBAN></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+// pain001V09 is a pain.001.001.09 message, agents are identified by BICFI and dates are wrapped
+const pain001V09 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>MSG-9</MsgId>
+      <CreDtTm>2024-03-01T10:00:00Z</CreDtTm>
+      <NbOfTxs>1</NbOfTxs>
+      <InitgPty><Nm>ACME Corp</Nm></InitgPty>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>PMT-9</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <ReqdExctnDt><Dt>2024-03-04</Dt></ReqdExctnDt>
+      <Dbtr><Nm>ACME Corp</Nm></Dbtr>
+      <DbtrAcct><Id><IBAN>FR1420041010050500013M02606</IBAN></Id></DbtrAcct>
+      <DbtrAgt><FinInstnId><BICFI>BNPAFRPP</BICFI></FinInstnId></DbtrAgt>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-9</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">12.34</InstdAmt></Amt>
+        <CdtrAgt><FinInstnId><BICFI>DEUTDEFF</BICFI></FinInstnId></CdtrAgt>
+        <Cdtr><Nm>John Doe</Nm></Cdtr>
+        <CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct>
+        <RmtInf><Ustrd>Part one</Ustrd><Ustrd>part two</Ustrd></RmtInf>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+type readTransaction struct {
+	block string
+	tx    CreditTransferTransaction
+}
+
+func readAll(t *testing.T, content string) (*Pain001Header, []readTransaction, error) {
+	t.Helper()
+	var txs []readTransaction
+	header, err := ReadPain001(strings.NewReader(content), func(pmtInf *PaymentInformation, tx *CreditTransferTransaction) error {
+		txs = append(txs, readTransaction{block: pmtInf.ID, tx: *tx})
+		return nil
+	})
+	return header, txs, err
+}
+
+func TestReadPain001_V03(t *testing.T) {
+	header, txs, err := readAll(t, fmt.Sprintf(pain001V03, "150.51"))
+	require.NoError(t, err)
+
+	assert.Equal(t, NamespacePain001V03, header.Namespace)
+	assert.Equal(t, "MSG-1", header.GroupHeader.MessageID)
+	require.Len(t, header.PaymentInformation, 2)
+	assert.Equal(t, "FR1420041010050500013M02606", header.PaymentInformation[0].DebtorAccount.ID.IBAN)
+	assert.Equal(t, "BNPAFRPP", header.PaymentInformation[0].DebtorAgent.FinancialInstitution.Code())
+	assert.Equal(t, "2024-03-05", header.PaymentInformation[1].RequestedExecutionDate.String())
+
+	require.Len(t, txs, 3)
+	assert.Equal(t, "PMT-1", txs[0].block)
+	assert.Equal(t, "E2E-1", txs[0].tx.PaymentID.EndToEndID)
+	assert.Equal(t, Amount{Value: "100.50", Currency: "EUR"}, txs[0].tx.Amount.Instructed)
+	assert.Equal(t, "DEUTDEFF", txs[0].tx.CreditorAgent.FinancialInstitution.Code())
+	assert.Equal(t, "Invoice 1", txs[0].tx.RemittanceInformation.String())
+	assert.Equal(t, "RF18539007547034", txs[1].tx.RemittanceInformation.String())
+	assert.Equal(t, "PMT-2", txs[2].block)
+	assert.Equal(t, "", txs[2].tx.CreditorAgent.FinancialInstitution.Code())
+}
+
+func TestReadPain001_V09(t *testing.T) {
+	header, txs, err := readAll(t, pain001V09)
+	require.NoError(t, err)
+
+	assert.Equal(t, NamespacePain001V09, header.Namespace)
+	assert.Equal(t, "2024-03-04", header.PaymentInformation[0].RequestedExecutionDate.String())
+	assert.Equal(t, "BNPAFRPP", header.PaymentInformation[0].DebtorAgent.FinancialInstitution.Code())
+	require.Len(t, txs, 1)
+	assert.Equal(t, "DEUTDEFF", txs[0].tx.CreditorAgent.FinancialInstitution.Code())
+	assert.Equal(t, "Part one part two", txs[0].tx.RemittanceInformation.String())
+}
+
+func TestReadPain001_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "Group control sum mismatch",
+			content: fmt.Sprintf(pain001V03, "150.50"),
+			wantErr: "group header: control sum is 150.50 but the transactions sum to 150.51",
+		},
+		{
+			name:    "Block control sum mismatch",
+			content: strings.Replace(fmt.Sprintf(pain001V03, "150.51"), "<CtrlSum>150.5</CtrlSum>", "<CtrlSum>150</CtrlSum>", 1),
+			wantErr: `payment information "PMT-1": control sum is 150 but the transactions sum to 150.50`,
+		},
+		{
+			name:    "Group transaction count mismatch",
+			content: strings.Replace(fmt.Sprintf(pain001V03, "150.51"), "<NbOfTxs>3</NbOfTxs>", "<NbOfTxs>4</NbOfTxs>", 1),
+			wantErr: "group header: number of transactions is 4 but 3 transactions were found",
+		},
+		{
+			name:    "Block transaction count mismatch",
+			content: strings.Replace(fmt.Sprintf(pain001V03, "150.51"), "<NbOfTxs>2</NbOfTxs>", "<NbOfTxs>1</NbOfTxs>", 1),
+			wantErr: `payment information "PMT-1": number of transactions is 1 but 2 transactions were found`,
+		},
+		{
+			name:    "Invalid amount",
+			content: strings.Replace(pain001V09, ">12.34<", ">-12.34<", 1),
+			wantErr: `invalid instructed amount "-12.34" of transaction "E2E-9"`,
+		},
+		{
+			name:    "Unsupported message",
+			content: strings.Replace(pain001V09, "pain.001.001.09", "pain.001.001.02", 1),
+			wantErr: "unsupported message",
+		},
+		{
+			name:    "Payment information without group header",
+			content: strings.ReplaceAll(pain001V09, "GrpHdr>", "Other>"),
+			wantErr: "the group header must precede the payment information blocks",
+		},
+		{
+			name:    "Truncated document",
+			content: pain001V09[:len(pain001V09)/2],
+			wantErr: "unexpected EOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := readAll(t, tt.content)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadPain001_YieldError(t *testing.T) {
+	stop := errors.New("stop")
+	var calls int
+	_, err := ReadPain001(strings.NewReader(fmt.Sprintf(pain001V03, "150.51")), func(*PaymentInformation, *CreditTransferTransaction) error {
+		calls++
+		return stop
+	})
+
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+}
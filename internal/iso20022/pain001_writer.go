@@ -0,0 +1,105 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// Pain001Writer streams a pain.001.001.03 customer credit transfer initiation, so that the
+// transactions of large messages are never held in memory. The payment information blocks
+// are started one after the other, and the transactions written after a block is started
+// belong to it. The numbers of transactions and the control sums of the group header and of
+// the blocks are written before their transactions, so they must be known up front.
+type Pain001Writer struct {
+	enc     *xml.Encoder
+	inBlock bool
+}
+
+// NewPain001Writer starts a pain.001.001.03 message on w with its group header
+func NewPain001Writer(w io.Writer, header GroupHeader) (*Pain001Writer, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	root := xml.StartElement{Name: xml.Name{Local: "Document"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: NamespacePain001V03}}}
+	if err := enc.EncodeToken(root); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeToken(startElement("CstmrCdtTrfInitn")); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeElement(header, startElement("GrpHdr")); err != nil {
+		return nil, err
+	}
+	return &Pain001Writer{enc: enc}, nil
+}
+
+// StartPaymentInformation ends the current payment information block, if any, and starts the given one
+func (w *Pain001Writer) StartPaymentInformation(p PaymentInformation) error {
+	if err := w.endPaymentInformation(); err != nil {
+		return err
+	}
+	if err := w.enc.EncodeToken(startElement("PmtInf")); err != nil {
+		return err
+	}
+	w.inBlock = true
+
+	// The fields of the block are encoded one by one, as its element is left open for the transactions
+	fields := []struct {
+		name  string
+		value any
+	}{
+		{"PmtInfId", p.ID},
+		{"PmtMtd", p.PaymentMethod},
+		{"NbOfTxs", p.NumberOfTransactions},
+		{"CtrlSum", p.ControlSum},
+		{"PmtTpInf", p.PaymentTypeInformation},
+		{"ReqdExctnDt", p.RequestedExecutionDate},
+		{"Dbtr", p.Debtor},
+		{"DbtrAcct", p.DebtorAccount},
+		{"DbtrAgt", p.DebtorAgent},
+		{"ChrgBr", p.ChargeBearer},
+	}
+	for _, field := range fields {
+		// Optional elements are left out when empty, a nil pointer is encoded as nothing
+		if field.value == "" {
+			continue
+		}
+		if err := w.enc.EncodeElement(field.value, startElement(field.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteTransaction writes a credit transfer transaction of the current payment information block
+func (w *Pain001Writer) WriteTransaction(t CreditTransferTransaction) error {
+	if !w.inBlock {
+		return errors.New("a payment information block must be started before its transactions")
+	}
+	return w.enc.EncodeElement(t, startElement("CdtTrfTxInf"))
+}
+
+// Close ends the message and flushes it, it does not close the underlying writer
+func (w *Pain001Writer) Close() error {
+	if err := w.endPaymentInformation(); err != nil {
+		return err
+	}
+	for _, name := range []string{"CstmrCdtTrfInitn", "Document"} {
+		if err := w.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	return w.enc.Close()
+}
+
+func (w *Pain001Writer) endPaymentInformation() error {
+	if !w.inBlock {
+		return nil
+	}
+	w.inBlock = false
+	return w.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "PmtInf"}})
+}
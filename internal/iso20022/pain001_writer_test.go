@@ -0,0 +1,88 @@
+package iso20022
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPain001Writer(t *testing.T) {
+	block := func(id, date, count, sum string) PaymentInformation {
+		return PaymentInformation{
+			ID:                     id,
+			PaymentMethod:          PaymentMethodTransfer,
+			NumberOfTransactions:   count,
+			ControlSum:             sum,
+			PaymentTypeInformation: NewPaymentTypeInformation(ServiceLevelSEPA),
+			RequestedExecutionDate: DateChoice{Value: date},
+			Debtor:                 Party{Name: "ACME Corp"},
+			DebtorAccount:          Account{ID: AccountID{IBAN: "FR1420041010050500013M02606"}, Currency: "EUR"},
+			DebtorAgent:            Agent{FinancialInstitution: FinancialInstitution{BIC: "BNPAFRPP"}},
+			ChargeBearer:           ChargeBearerSLEV,
+		}
+	}
+	transaction := func(endToEndID, amount string) CreditTransferTransaction {
+		return CreditTransferTransaction{
+			PaymentID:             PaymentID{EndToEndID: endToEndID},
+			Amount:                TransactionAmount{Instructed: Amount{Value: amount, Currency: "EUR"}},
+			CreditorAgent:         Agent{FinancialInstitution: FinancialInstitution{BIC: "DEUTDEFF"}},
+			Creditor:              Party{Name: "John Doe"},
+			CreditorAccount:       Account{ID: AccountID{IBAN: "DE89370400440532013000"}},
+			RemittanceInformation: RemittanceInformation{Unstructured: []string{"Invoice " + endToEndID}},
+		}
+	}
+
+	var out strings.Builder
+	w, err := NewPain001Writer(&out, GroupHeader{
+		MessageID:            "OUT-1",
+		CreationDateTime:     "2024-03-05T06:00:00Z",
+		NumberOfTransactions: "3",
+		ControlSum:           "150.51",
+		InitiatingParty:      Party{Name: "ACME Corp"},
+	})
+	require.NoError(t, err)
+	assert.Error(t, w.WriteTransaction(transaction("E2E-0", "1.00")), "transactions cannot precede their block")
+
+	require.NoError(t, w.StartPaymentInformation(block("OUT-1-20240304", "2024-03-04", "2", "150.50")))
+	require.NoError(t, w.WriteTransaction(transaction("E2E-1", "100.50")))
+	require.NoError(t, w.WriteTransaction(transaction("E2E-2", "50.00")))
+	require.NoError(t, w.StartPaymentInformation(block("OUT-1-20240305", "2024-03-05", "1", "0.01")))
+	require.NoError(t, w.WriteTransaction(transaction("E2E-3", "0.01")))
+	require.NoError(t, w.Close())
+
+	document := out.String()
+	assert.True(t, strings.HasPrefix(document, `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>OUT-1</MsgId>`), document)
+	assert.Contains(t, document, `    <PmtInf>
+      <PmtInfId>OUT-1-20240304</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <NbOfTxs>2</NbOfTxs>
+      <CtrlSum>150.50</CtrlSum>
+      <PmtTpInf>
+        <SvcLvl>
+          <Cd>SEPA</Cd>
+        </SvcLvl>
+      </PmtTpInf>
+      <ReqdExctnDt>2024-03-04</ReqdExctnDt>`)
+	assert.Contains(t, document, `      <ChrgBr>SLEV</ChrgBr>
+      <CdtTrfTxInf>`)
+
+	// The message reads back with its counts and control sums checked
+	var endToEndIDs []string
+	header, err := ReadPain001(strings.NewReader(document), func(p *PaymentInformation, tx *CreditTransferTransaction) error {
+		endToEndIDs = append(endToEndIDs, p.ID+"/"+tx.PaymentID.EndToEndID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, NamespacePain001V03, header.Namespace)
+	assert.Equal(t, []string{"OUT-1-20240304/E2E-1", "OUT-1-20240304/E2E-2", "OUT-1-20240305/E2E-3"}, endToEndIDs)
+	require.Len(t, header.PaymentInformation, 2)
+	assert.Equal(t, ServiceLevelSEPA, header.PaymentInformation[0].PaymentTypeInformation.ServiceLevel.Code)
+	assert.Equal(t, ChargeBearerSLEV, header.PaymentInformation[1].ChargeBearer)
+	assert.Equal(t, "2024-03-05", header.PaymentInformation[1].RequestedExecutionDate.String())
+}
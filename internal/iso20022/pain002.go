@@ -0,0 +1,213 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	// NamespacePain002V03 is the namespace of pain.002.001.03 customer payment status reports
+	NamespacePain002V03 = "urn:iso:std:iso:20022:tech:xsd:pain.002.001.03"
+	// NamespacePain002V10 is the namespace of pain.002.001.10 customer payment status reports
+	NamespacePain002V10 = "urn:iso:std:iso:20022:tech:xsd:pain.002.001.10"
+
+	// StatusAccepted is the status of payments that passed all checks and are accepted for execution
+	StatusAccepted = "ACCP"
+	// StatusPartiallyAccepted is the status of a group of payments of which some were rejected
+	StatusPartiallyAccepted = "PART"
+	// StatusRejected is the status of payments that were rejected
+	StatusRejected = "RJCT"
+	// StatusPending is the status of payments whose processing has not finished
+	StatusPending = "PDNG"
+
+	// ReasonNarrative is the reason code of statuses explained by their additional information only
+	ReasonNarrative = "NARR"
+
+	// maxAdditionalInformation is the maximum length of the additional information of a status reason
+	maxAdditionalInformation = 105
+)
+
+// MessageName returns the name of the message of the given namespace, such as pain.001.001.03
+func MessageName(namespace string) string {
+	_, name, _ := strings.Cut(namespace, "xsd:")
+	return name
+}
+
+// StatusReportNamespace returns the namespace of the pain.002 status reports answering
+// messages of the given name: version 10 for pain.001.001.09 messages, version 03 otherwise
+func StatusReportNamespace(messageName string) string {
+	if messageName == MessageName(NamespacePain001V09) {
+		return NamespacePain002V10
+	}
+	return NamespacePain002V03
+}
+
+// StatusReportGroupHeader identifies a status report
+type StatusReportGroupHeader struct {
+	MessageID        string `xml:"MsgId"`
+	CreationDateTime string `xml:"CreDtTm"`
+}
+
+// OriginalGroupStatus references the reported message and gives the status of all its payments
+type OriginalGroupStatus struct {
+	OriginalMessageID            string         `xml:"OrgnlMsgId"`
+	OriginalMessageName          string         `xml:"OrgnlMsgNmId"`
+	OriginalNumberOfTransactions string         `xml:"OrgnlNbOfTxs,omitempty"`
+	OriginalControlSum           string         `xml:"OrgnlCtrlSum,omitempty"`
+	GroupStatus                  string         `xml:"GrpSts,omitempty"`
+	StatusReasons                []StatusReason `xml:"StsRsnInf,omitempty"`
+}
+
+// StatusReason explains a status with a reason code, such as AM04 for insufficient funds,
+// and free text of up to 105 characters
+type StatusReason struct {
+	Reason                *StatusReasonCode `xml:"Rsn,omitempty"`
+	AdditionalInformation string            `xml:"AddtlInf,omitempty"`
+}
+
+type StatusReasonCode struct {
+	Code string `xml:"Cd"`
+}
+
+// NewStatusReason returns a status reason with the given code and additional information,
+// which is truncated to the maximum length allowed
+func NewStatusReason(code, additionalInformation string) StatusReason {
+	if runes := []rune(additionalInformation); len(runes) > maxAdditionalInformation {
+		additionalInformation = string(runes[:maxAdditionalInformation])
+	}
+	return StatusReason{Reason: &StatusReasonCode{Code: code}, AdditionalInformation: additionalInformation}
+}
+
+// PaymentInformationStatus references a payment information block of the reported message
+// and gives the status of all its payments
+type PaymentInformationStatus struct {
+	OriginalPaymentInformationID string
+	OriginalNumberOfTransactions string
+	Status                       string
+	StatusReasons                []StatusReason
+}
+
+// TransactionStatus gives the status of a credit transfer transaction of the reported message
+type TransactionStatus struct {
+	StatusID                     string                        `xml:"StsId,omitempty"`
+	OriginalEndToEndID           string                        `xml:"OrgnlEndToEndId"`
+	Status                       string                        `xml:"TxSts"`
+	StatusReasons                []StatusReason                `xml:"StsRsnInf,omitempty"`
+	OriginalTransactionReference *OriginalTransactionReference `xml:"OrgnlTxRef,omitempty"`
+}
+
+// OriginalTransactionReference repeats the key elements of the reported transaction
+type OriginalTransactionReference struct {
+	Amount          TransactionAmount `xml:"Amt"`
+	CreditorAgent   *Agent            `xml:"CdtrAgt,omitempty"`
+	CreditorAccount *Account          `xml:"CdtrAcct,omitempty"`
+}
+
+// Pain002Writer streams a pain.002 customer payment status report, so that the statuses
+// of the transactions of large messages are never held in memory.
+// The payment information blocks are started one after the other, and the transactions
+// written after a block is started belong to it.
+type Pain002Writer struct {
+	enc       *xml.Encoder
+	namespace string
+	inBlock   bool
+}
+
+// NewPain002Writer starts a status report of the given namespace on w, with its group header
+// and the status of the original group of payments
+func NewPain002Writer(w io.Writer, namespace string, header StatusReportGroupHeader, group OriginalGroupStatus) (*Pain002Writer, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	pw := &Pain002Writer{enc: enc, namespace: namespace}
+	root := xml.StartElement{Name: xml.Name{Local: "Document"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: namespace}}}
+	if err := enc.EncodeToken(root); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeToken(startElement("CstmrPmtStsRpt")); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeElement(header, startElement("GrpHdr")); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeElement(group, startElement("OrgnlGrpInfAndSts")); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+// StartPaymentInformation ends the current payment information block, if any, and starts the given one
+func (w *Pain002Writer) StartPaymentInformation(s PaymentInformationStatus) error {
+	if err := w.endPaymentInformation(); err != nil {
+		return err
+	}
+	if err := w.enc.EncodeToken(startElement("OrgnlPmtInfAndSts")); err != nil {
+		return err
+	}
+	w.inBlock = true
+
+	for _, e := range []struct {
+		name  string
+		value string
+	}{
+		{"OrgnlPmtInfId", s.OriginalPaymentInformationID},
+		{"OrgnlNbOfTxs", s.OriginalNumberOfTransactions},
+		{"PmtInfSts", s.Status},
+	} {
+		if e.value == "" {
+			continue
+		}
+		if err := w.enc.EncodeElement(e.value, startElement(e.name)); err != nil {
+			return err
+		}
+	}
+	for _, reason := range s.StatusReasons {
+		if err := w.enc.EncodeElement(reason, startElement("StsRsnInf")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteTransaction writes the status of a transaction of the current payment information block
+func (w *Pain002Writer) WriteTransaction(s TransactionStatus) error {
+	if !w.inBlock {
+		return errors.New("a payment information block must be started before its transactions")
+	}
+	// Agents are identified by BIC in version 03 and by BICFI in later versions
+	if ref := s.OriginalTransactionReference; ref != nil && ref.CreditorAgent != nil && w.namespace != NamespacePain002V03 {
+		code := ref.CreditorAgent.FinancialInstitution.Code()
+		ref.CreditorAgent = &Agent{FinancialInstitution: FinancialInstitution{BICFI: code}}
+	}
+	return w.enc.EncodeElement(s, startElement("TxInfAndSts"))
+}
+
+// Close ends the status report and flushes it, it does not close the underlying writer
+func (w *Pain002Writer) Close() error {
+	if err := w.endPaymentInformation(); err != nil {
+		return err
+	}
+	for _, name := range []string{"CstmrPmtStsRpt", "Document"} {
+		if err := w.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	return w.enc.Close()
+}
+
+func (w *Pain002Writer) endPaymentInformation() error {
+	if !w.inBlock {
+		return nil
+	}
+	w.inBlock = false
+	return w.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "OrgnlPmtInfAndSts"}})
+}
+
+func startElement(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
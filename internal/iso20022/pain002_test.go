@@ -0,0 +1,139 @@
+package iso20022
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageName(t *testing.T) {
+	assert.Equal(t, "pain.001.001.03", MessageName(NamespacePain001V03))
+	assert.Equal(t, "", MessageName("not a namespace"))
+
+	assert.Equal(t, NamespacePain002V03, StatusReportNamespace("pain.001.001.03"))
+	assert.Equal(t, NamespacePain002V10, StatusReportNamespace("pain.001.001.09"))
+	assert.Equal(t, NamespacePain002V03, StatusReportNamespace(""))
+}
+
+func TestPain002Writer(t *testing.T) {
+	var out strings.Builder
+	w, err := NewPain002Writer(&out, NamespacePain002V03,
+		StatusReportGroupHeader{MessageID: "STS-1", CreationDateTime: "2024-03-04T10:00:00Z"},
+		OriginalGroupStatus{
+			OriginalMessageID:            "MSG-1",
+			OriginalMessageName:          "pain.001.001.03",
+			OriginalNumberOfTransactions: "2",
+			OriginalControlSum:           "150.50",
+			GroupStatus:                  StatusPartiallyAccepted,
+		})
+	require.NoError(t, err)
+
+	require.NoError(t, w.StartPaymentInformation(PaymentInformationStatus{
+		OriginalPaymentInformationID: "PMT-1",
+		OriginalNumberOfTransactions: "2",
+		Status:                       StatusPartiallyAccepted,
+	}))
+	require.NoError(t, w.WriteTransaction(TransactionStatus{
+		StatusID:           "1",
+		OriginalEndToEndID: "E2E-1",
+		Status:             StatusAccepted,
+		OriginalTransactionReference: &OriginalTransactionReference{
+			Amount:          TransactionAmount{Instructed: Amount{Value: "100.50", Currency: "EUR"}},
+			CreditorAgent:   &Agent{FinancialInstitution: FinancialInstitution{BIC: "DEUTDEFF"}},
+			CreditorAccount: &Account{ID: AccountID{IBAN: "DE89370400440532013000"}},
+		},
+	}))
+	require.NoError(t, w.WriteTransaction(TransactionStatus{
+		StatusID:           "2",
+		OriginalEndToEndID: "E2E-2",
+		Status:             StatusRejected,
+		StatusReasons:      []StatusReason{NewStatusReason("AC04", "")},
+	}))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.03">
+  <CstmrPmtStsRpt>
+    <GrpHdr>
+      <MsgId>STS-1</MsgId>
+      <CreDtTm>2024-03-04T10:00:00Z</CreDtTm>
+    </GrpHdr>
+    <OrgnlGrpInfAndSts>
+      <OrgnlMsgId>MSG-1</OrgnlMsgId>
+      <OrgnlMsgNmId>pain.001.001.03</OrgnlMsgNmId>
+      <OrgnlNbOfTxs>2</OrgnlNbOfTxs>
+      <OrgnlCtrlSum>150.50</OrgnlCtrlSum>
+      <GrpSts>PART</GrpSts>
+    </OrgnlGrpInfAndSts>
+    <OrgnlPmtInfAndSts>
+      <OrgnlPmtInfId>PMT-1</OrgnlPmtInfId>
+      <OrgnlNbOfTxs>2</OrgnlNbOfTxs>
+      <PmtInfSts>PART</PmtInfSts>
+      <TxInfAndSts>
+        <StsId>1</StsId>
+        <OrgnlEndToEndId>E2E-1</OrgnlEndToEndId>
+        <TxSts>ACCP</TxSts>
+        <OrgnlTxRef>
+          <Amt>
+            <InstdAmt Ccy="EUR">100.50</InstdAmt>
+          </Amt>
+          <CdtrAgt>
+            <FinInstnId>
+              <BIC>DEUTDEFF</BIC>
+            </FinInstnId>
+          </CdtrAgt>
+          <CdtrAcct>
+            <Id>
+              <IBAN>DE89370400440532013000</IBAN>
+            </Id>
+          </CdtrAcct>
+        </OrgnlTxRef>
+      </TxInfAndSts>
+      <TxInfAndSts>
+        <StsId>2</StsId>
+        <OrgnlEndToEndId>E2E-2</OrgnlEndToEndId>
+        <TxSts>RJCT</TxSts>
+        <StsRsnInf>
+          <Rsn>
+            <Cd>AC04</Cd>
+          </Rsn>
+        </StsRsnInf>
+      </TxInfAndSts>
+    </OrgnlPmtInfAndSts>
+  </CstmrPmtStsRpt>
+</Document>`, out.String())
+}
+
+func TestPain002Writer_V10(t *testing.T) {
+	var out strings.Builder
+	w, err := NewPain002Writer(&out, NamespacePain002V10,
+		StatusReportGroupHeader{MessageID: "STS-1", CreationDateTime: "2024-03-04T10:00:00Z"},
+		OriginalGroupStatus{OriginalMessageID: "MSG-9", OriginalMessageName: "pain.001.001.09", GroupStatus: StatusAccepted})
+	require.NoError(t, err)
+
+	err = w.WriteTransaction(TransactionStatus{OriginalEndToEndID: "E2E-9", Status: StatusAccepted})
+	assert.EqualError(t, err, "a payment information block must be started before its transactions")
+
+	require.NoError(t, w.StartPaymentInformation(PaymentInformationStatus{OriginalPaymentInformationID: "PMT-9", Status: StatusAccepted}))
+	require.NoError(t, w.WriteTransaction(TransactionStatus{
+		OriginalEndToEndID: "E2E-9",
+		Status:             StatusAccepted,
+		OriginalTransactionReference: &OriginalTransactionReference{
+			Amount:        TransactionAmount{Instructed: Amount{Value: "12.34", Currency: "EUR"}},
+			CreditorAgent: &Agent{FinancialInstitution: FinancialInstitution{BIC: "DEUTDEFF"}},
+		},
+	}))
+	require.NoError(t, w.Close())
+
+	assert.Contains(t, out.String(), `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.10">`)
+	assert.Contains(t, out.String(), "<BICFI>DEUTDEFF</BICFI>")
+	assert.NotContains(t, out.String(), "<BIC>")
+}
+
+func TestNewStatusReason(t *testing.T) {
+	reason := NewStatusReason(ReasonNarrative, strings.Repeat("é", 120))
+	assert.Equal(t, ReasonNarrative, reason.Reason.Code)
+	assert.Len(t, []rune(reason.AdditionalInformation), 105)
+}
@@ -0,0 +1,15 @@
+// Package jsonschema validates JSON documents against JSON Schemas.
+//
+// It implements the subset of JSON Schema draft 2020-12 the published schemas of the
+// service are written in, and rejects schemas that use any other keyword, so that a
+// schema never silently relies on a keyword that is not enforced. Violations report the
+// JSON pointer of the invalid value in the document and of the keyword in the schema.
+//
+// Supported keywords:
+//   - Annotations: $schema, $id, $comment, title, description, examples, default
+//   - Structure: $defs and local $ref such as #/$defs/name
+//   - Any instance: type, enum, const
+//   - Objects: properties, required, additionalProperties
+//   - Arrays: items, minItems, maxItems
+//   - Strings: minLength, maxLength, pattern
+package jsonschema
@@ -0,0 +1,457 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// annotations are the keywords that do not constrain instances
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true, "examples": true, "default": true,
+}
+
+// instanceTypes are the values of the type keyword
+var instanceTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true,
+}
+
+// Schema is a compiled JSON Schema, or one of its subschemas
+type Schema struct {
+	// nodes are the compiled schemas of the document by JSON pointer
+	nodes map[string]*node
+	node  *node
+}
+
+// node is a compiled schema object or boolean schema
+type node struct {
+	// path is the JSON pointer of the schema within its document
+	path string
+	// never is set for the false boolean schema, which no instance matches
+	never bool
+
+	ref        string
+	refNode    *node
+	types      []string
+	enum       []any
+	constValue any
+	hasConst   bool
+
+	properties           map[string]*node
+	required             []string
+	additionalProperties *node
+
+	items    *node
+	minItems int
+	maxItems int
+
+	minLength int
+	maxLength int
+	pattern   *regexp.Regexp
+}
+
+// Compile compiles a JSON Schema document
+func Compile(data []byte) (*Schema, error) {
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	nodes := map[string]*node{}
+	root, err := compileNode(doc, "", nodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nodes {
+		if n.ref == "" {
+			continue
+		}
+		target, ok := strings.CutPrefix(n.ref, "#")
+		if !ok {
+			return nil, fmt.Errorf("schema %s: only local references are supported, got %q", displayPath(n.path), n.ref)
+		}
+		if n.refNode, ok = nodes[target]; !ok {
+			return nil, fmt.Errorf("schema %s: unresolved reference %q", displayPath(n.path), n.ref)
+		}
+	}
+	return &Schema{nodes: nodes, node: root}, nil
+}
+
+// MustCompile is like Compile but panics if the schema cannot be compiled.
+// It simplifies the initialization of global variables holding compiled schemas.
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Subschema returns the subschema at the JSON pointer within the schema document, such as
+// /properties/items. Violations of the subschema report their keyword from the document root.
+func (s *Schema) Subschema(pointer string) (*Schema, error) {
+	n, ok := s.nodes[pointer]
+	if !ok {
+		return nil, fmt.Errorf("no subschema at %q", pointer)
+	}
+	return &Schema{nodes: s.nodes, node: n}, nil
+}
+
+// compileNode compiles the schema at path and registers it and its subschemas in nodes
+func compileNode(value any, path string, nodes map[string]*node) (*node, error) {
+	n := &node{path: path, minItems: -1, maxItems: -1, minLength: -1, maxLength: -1}
+	nodes[path] = n
+	switch v := value.(type) {
+	case bool:
+		n.never = !v
+		return n, nil
+	case map[string]any:
+		return n, n.compile(v, nodes)
+	default:
+		return nil, fmt.Errorf("schema %s: expected an object or a boolean", displayPath(path))
+	}
+}
+
+// compile compiles the keywords of a schema object
+func (n *node) compile(keywords map[string]any, nodes map[string]*node) error {
+	for _, keyword := range sortedKeys(keywords) {
+		value := keywords[keyword]
+		path := n.path + "/" + escape(keyword)
+		var err error
+		switch keyword {
+		case "$ref":
+			var ok bool
+			if n.ref, ok = value.(string); !ok {
+				err = fmt.Errorf("expected a string")
+			}
+		case "type":
+			n.types, err = compileTypes(value)
+		case "enum":
+			var ok bool
+			if n.enum, ok = value.([]any); !ok || len(n.enum) == 0 {
+				err = fmt.Errorf("expected a non-empty array")
+			}
+		case "const":
+			n.constValue, n.hasConst = value, true
+		case "properties", "$defs":
+			// Definitions are only reached through references, which resolve against nodes
+			children, childErr := compileChildren(value, path, nodes)
+			if childErr != nil {
+				// Errors of subschemas already name their path
+				return childErr
+			}
+			if keyword == "properties" {
+				n.properties = children
+			}
+		case "required":
+			n.required, err = compileStrings(value)
+		case "additionalProperties", "items":
+			child, childErr := compileNode(value, path, nodes)
+			if childErr != nil {
+				return childErr
+			}
+			if keyword == "items" {
+				n.items = child
+			} else {
+				n.additionalProperties = child
+			}
+		case "minItems":
+			n.minItems, err = compileCount(value)
+		case "maxItems":
+			n.maxItems, err = compileCount(value)
+		case "minLength":
+			n.minLength, err = compileCount(value)
+		case "maxLength":
+			n.maxLength, err = compileCount(value)
+		case "pattern":
+			source, ok := value.(string)
+			if !ok {
+				err = fmt.Errorf("expected a string")
+				break
+			}
+			n.pattern, err = regexp.Compile(source)
+		default:
+			if !annotations[keyword] {
+				err = fmt.Errorf("unsupported keyword")
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("schema %s: %w", displayPath(path), err)
+		}
+	}
+	return nil
+}
+
+func compileChildren(value any, path string, nodes map[string]*node) (map[string]*node, error) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("schema %s: expected an object", displayPath(path))
+	}
+	children := make(map[string]*node, len(object))
+	for name, child := range object {
+		n, err := compileNode(child, path+"/"+escape(name), nodes)
+		if err != nil {
+			return nil, err
+		}
+		children[name] = n
+	}
+	return children, nil
+}
+
+func compileTypes(value any) ([]string, error) {
+	if name, ok := value.(string); ok {
+		value = []any{name}
+	}
+	types, err := compileStrings(value)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range types {
+		if !instanceTypes[t] {
+			return nil, fmt.Errorf("unknown type %q", t)
+		}
+	}
+	return types, nil
+}
+
+func compileStrings(value any) ([]string, error) {
+	values, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an array of strings")
+	}
+	strs := make([]string, len(values))
+	for i, v := range values {
+		if strs[i], ok = v.(string); !ok {
+			return nil, fmt.Errorf("expected an array of strings")
+		}
+	}
+	return strs, nil
+}
+
+func compileCount(value any) (int, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("expected a non-negative integer")
+	}
+	count, err := strconv.Atoi(number.String())
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("expected a non-negative integer")
+	}
+	return count, nil
+}
+
+// Violation is a keyword of the schema that an instance does not satisfy
+type Violation struct {
+	// InstancePath is the JSON pointer of the invalid value in the document, empty for the document itself
+	InstancePath string `json:"instance_path"`
+	// SchemaPath is the JSON pointer of the keyword in the schema document
+	SchemaPath string `json:"schema_path"`
+	Message    string `json:"message"`
+}
+
+// ValidationError reports the violations of an instance that does not match its schema
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	v := e.Violations[0]
+	msg := fmt.Sprintf("%s: %s (schema %s)", displayPath(v.InstancePath), v.Message, displayPath(v.SchemaPath))
+	if len(e.Violations) > 1 {
+		msg += fmt.Sprintf(" and %d more violations", len(e.Violations)-1)
+	}
+	return msg
+}
+
+// Validate validates an instance decoded by encoding/json, with or without UseNumber,
+// and returns a *ValidationError listing all its violations when it does not match
+func (s *Schema) Validate(instance any) error {
+	return s.ValidateAt("", instance)
+}
+
+// ValidateAt is like Validate for an instance found at instancePath in its document,
+// which prefixes the paths of the violations
+func (s *Schema) ValidateAt(instancePath string, instance any) error {
+	var violations []Violation
+	s.node.validate(instancePath, instance, &violations)
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func (n *node) validate(path string, instance any, violations *[]Violation) {
+	report := func(keyword, format string, args ...any) {
+		*violations = append(*violations, Violation{
+			InstancePath: path,
+			SchemaPath:   n.path + "/" + keyword,
+			Message:      fmt.Sprintf(format, args...),
+		})
+	}
+	if n.never {
+		*violations = append(*violations, Violation{InstancePath: path, SchemaPath: n.path, Message: "no value is allowed"})
+		return
+	}
+	if n.refNode != nil {
+		n.refNode.validate(path, instance, violations)
+	}
+
+	if len(n.types) > 0 && !hasType(instance, n.types) {
+		report("type", "expected %s, got %s", strings.Join(n.types, " or "), typeOf(instance))
+		return
+	}
+	if n.enum != nil && !containsValue(n.enum, instance) {
+		allowed, _ := json.Marshal(n.enum)
+		report("enum", "must be one of %s", allowed)
+	}
+	if n.hasConst && !equal(n.constValue, instance) {
+		expected, _ := json.Marshal(n.constValue)
+		report("const", "must be %s", expected)
+	}
+
+	switch v := instance.(type) {
+	case map[string]any:
+		for _, name := range n.required {
+			if _, ok := v[name]; !ok {
+				report("required", "missing required property %q", name)
+			}
+		}
+		for _, name := range sortedKeys(v) {
+			memberPath := path + "/" + escape(name)
+			if child, ok := n.properties[name]; ok {
+				child.validate(memberPath, v[name], violations)
+			} else if n.additionalProperties != nil {
+				n.additionalProperties.validate(memberPath, v[name], violations)
+			}
+		}
+	case []any:
+		if n.minItems >= 0 && len(v) < n.minItems {
+			report("minItems", "number of items must be at least %d", n.minItems)
+		}
+		if n.maxItems >= 0 && len(v) > n.maxItems {
+			report("maxItems", "number of items must be at most %d", n.maxItems)
+		}
+		if n.items != nil {
+			for i, item := range v {
+				n.items.validate(path+"/"+strconv.Itoa(i), item, violations)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if n.minLength >= 0 && length < n.minLength {
+			report("minLength", "length must be at least %d", n.minLength)
+		}
+		if n.maxLength >= 0 && length > n.maxLength {
+			report("maxLength", "length must be at most %d", n.maxLength)
+		}
+		if n.pattern != nil && !n.pattern.MatchString(v) {
+			report("pattern", "must match the pattern %q", n.pattern.String())
+		}
+	}
+}
+
+// typeOf returns the JSON type of an instance
+func typeOf(instance any) string {
+	switch v := instance.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number, float64:
+		if isInteger(v) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", instance)
+	}
+}
+
+func hasType(instance any, types []string) bool {
+	actual := typeOf(instance)
+	for _, t := range types {
+		if t == actual || t == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func isInteger(number any) bool {
+	switch v := number.(type) {
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return true
+		}
+		f, err := v.Float64()
+		return err == nil && f == float64(int64(f))
+	case float64:
+		return v == float64(int64(v))
+	}
+	return false
+}
+
+func containsValue(values []any, instance any) bool {
+	for _, v := range values {
+		if equal(v, instance) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares JSON values, numbers are equal when their values are
+func equal(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// escape escapes a reference token of a JSON pointer
+func escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// displayPath returns a JSON pointer for messages, the empty pointer of the document being shown as /
+func displayPath(pointer string) string {
+	if pointer == "" {
+		return "/"
+	}
+	return pointer
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
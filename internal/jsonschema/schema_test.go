@@ -0,0 +1,174 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "Order",
+	"type": "object",
+	"required": ["id", "lines"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "integer"},
+		"status": {"enum": ["open", "closed"]},
+		"kind": {"const": "order"},
+		"note": {"type": ["string", "null"], "maxLength": 5},
+		"lines": {
+			"type": "array",
+			"minItems": 1,
+			"maxItems": 2,
+			"items": {"$ref": "#/$defs/line"}
+		}
+	},
+	"$defs": {
+		"line": {
+			"type": "object",
+			"required": ["sku"],
+			"properties": {
+				"sku": {"type": "string", "minLength": 2, "pattern": "^[A-Z]+$"},
+				"a/b": {"type": "number"}
+			}
+		}
+	}
+}`
+
+// decode decodes a JSON document the way documents are validated
+func decode(t *testing.T, document string) any {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(document))
+	dec.UseNumber()
+	var v any
+	assert.NoError(t, dec.Decode(&v))
+	return v
+}
+
+func TestValidate(t *testing.T) {
+	schema := MustCompile([]byte(testSchema))
+
+	tests := []struct {
+		name     string
+		document string
+		want     []Violation
+	}{
+		{
+			name:     "Valid document",
+			document: `{"id": 1, "status": "open", "kind": "order", "note": null, "lines": [{"sku": "AB", "a/b": 1.5}]}`,
+		},
+		{
+			name:     "Integral number is an integer",
+			document: `{"id": 2.0, "lines": [{"sku": "AB"}]}`,
+		},
+		{
+			name:     "Wrong type",
+			document: `[]`,
+			want:     []Violation{{InstancePath: "", SchemaPath: "/type", Message: "expected object, got array"}},
+		},
+		{
+			name:     "Missing and additional properties",
+			document: `{"extra": true}`,
+			want: []Violation{
+				{InstancePath: "", SchemaPath: "/required", Message: `missing required property "id"`},
+				{InstancePath: "", SchemaPath: "/required", Message: `missing required property "lines"`},
+				{InstancePath: "/extra", SchemaPath: "/additionalProperties", Message: "no value is allowed"},
+			},
+		},
+		{
+			name:     "Enum, const and string length",
+			document: `{"id": 1.5, "status": "lost", "kind": "invoice", "note": "too long", "lines": [{"sku": "AB"}]}`,
+			want: []Violation{
+				{InstancePath: "/id", SchemaPath: "/properties/id/type", Message: "expected integer, got number"},
+				{InstancePath: "/kind", SchemaPath: "/properties/kind/const", Message: `must be "order"`},
+				{InstancePath: "/note", SchemaPath: "/properties/note/maxLength", Message: "length must be at most 5"},
+				{InstancePath: "/status", SchemaPath: "/properties/status/enum", Message: `must be one of ["open","closed"]`},
+			},
+		},
+		{
+			name:     "Array items through a reference",
+			document: `{"id": 1, "lines": [{"sku": "A"}, {"sku": "ab", "a/b": "x"}, {}]}`,
+			want: []Violation{
+				{InstancePath: "/lines", SchemaPath: "/properties/lines/maxItems", Message: "number of items must be at most 2"},
+				{InstancePath: "/lines/0/sku", SchemaPath: "/$defs/line/properties/sku/minLength", Message: "length must be at least 2"},
+				{InstancePath: "/lines/1/a~1b", SchemaPath: "/$defs/line/properties/a~1b/type", Message: "expected number, got string"},
+				{InstancePath: "/lines/1/sku", SchemaPath: "/$defs/line/properties/sku/pattern", Message: `must match the pattern "^[A-Z]+$"`},
+				{InstancePath: "/lines/2", SchemaPath: "/$defs/line/required", Message: `missing required property "sku"`},
+			},
+		},
+		{
+			name:     "Too few items",
+			document: `{"id": 1, "lines": []}`,
+			want:     []Violation{{InstancePath: "/lines", SchemaPath: "/properties/lines/minItems", Message: "number of items must be at least 1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(decode(t, tt.document))
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.want, validationErr.Violations)
+		})
+	}
+}
+
+func TestValidate_WithoutUseNumber(t *testing.T) {
+	schema := MustCompile([]byte(testSchema))
+
+	var document any
+	assert.NoError(t, json.Unmarshal([]byte(`{"id": 1, "lines": [{"sku": "AB", "a/b": 0.5}]}`), &document))
+	assert.NoError(t, schema.Validate(document))
+}
+
+func TestSubschema(t *testing.T) {
+	schema := MustCompile([]byte(testSchema))
+
+	line, err := schema.Subschema("/properties/lines/items")
+	assert.NoError(t, err)
+	err = line.ValidateAt("/lines/3", decode(t, `{"sku": 1}`))
+	assert.EqualError(t, err, `/lines/3/sku: expected string, got integer (schema /$defs/line/properties/sku/type)`)
+
+	_, err = schema.Subschema("/properties/unknown")
+	assert.Error(t, err)
+}
+
+func TestValidationError(t *testing.T) {
+	err := &ValidationError{Violations: []Violation{
+		{InstancePath: "", SchemaPath: "/required", Message: `missing required property "id"`},
+		{InstancePath: "/a", SchemaPath: "/properties/a/type", Message: "expected string, got integer"},
+	}}
+	assert.EqualError(t, err, `/: missing required property "id" (schema /required) and 1 more violations`)
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{"Invalid JSON", `{`, "invalid schema: unexpected EOF"},
+		{"Not a schema", `1`, "schema /: expected an object or a boolean"},
+		{"Unsupported keyword", `{"properties": {"a": {"oneOf": []}}}`, "schema /properties/a/oneOf: unsupported keyword"},
+		{"Unknown type", `{"type": "decimal"}`, `schema /type: unknown type "decimal"`},
+		{"Invalid pattern", `{"pattern": "("}`, "schema /pattern: error parsing regexp: missing closing ): `(`"},
+		{"Negative length", `{"minLength": -1}`, "schema /minLength: expected a non-negative integer"},
+		{"Empty enum", `{"enum": []}`, "schema /enum: expected a non-empty array"},
+		{"Remote reference", `{"$ref": "other.json"}`, `schema /: only local references are supported, got "other.json"`},
+		{"Unresolved reference", `{"$ref": "#/$defs/missing"}`, `schema /: unresolved reference "#/$defs/missing"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
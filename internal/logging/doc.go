@@ -0,0 +1,12 @@
+// Package logging provides the redaction of the personal data written to the logs.
+//
+// Log records are redacted as they are handled: the values of the attributes known to
+// hold IBANs, names or descriptions are masked by their key, and the IBANs and email
+// addresses found in any other string, error or message are masked by their pattern.
+// The level of detail kept is configurable, clear text being meant for debugging
+// outside of production only.
+//
+// Key components:
+//   - Detail: Level of detail of the personal data written to the logs
+//   - NewRedactingHandler: Function wrapping a slog.Handler with the redaction
+package logging
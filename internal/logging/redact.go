@@ -0,0 +1,192 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Detail is the level of detail of the personal data written to the logs
+type Detail string
+
+const (
+	// DetailClear writes the personal data as is, for debugging outside of production
+	DetailClear Detail = "clear"
+	// DetailPartial keeps enough to tell values apart: the country code and the last
+	// 4 characters of IBANs, the initials of names and the first letter of emails
+	DetailPartial Detail = "partial"
+	// DetailMasked replaces the personal data entirely
+	DetailMasked Detail = "masked"
+)
+
+// Redacted replaces the values that are masked entirely
+const Redacted = "[REDACTED]"
+
+// ParseDetail returns the detail of its name
+func ParseDetail(name string) (Detail, error) {
+	switch d := Detail(name); d {
+	case DetailClear, DetailPartial, DetailMasked:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown log detail %q", name)
+	}
+}
+
+// kind is the kind of personal data an attribute holds
+type kind int
+
+const (
+	kindNone kind = iota
+	kindIBAN
+	kindName
+	kindText
+)
+
+// attributeKinds maps the keys of the attributes holding personal data to their kind,
+// the keys ending with iban are IBANs
+var attributeKinds = map[string]kind{
+	"name":                   kindName,
+	"counterparty_name":      kindName,
+	"creditor_name":          kindName,
+	"debtor_name":            kindName,
+	"user":                   kindName,
+	"description":            kindText,
+	"remittance_information": kindText,
+}
+
+var (
+	// ibanPattern matches IBANs, printed electronically or in groups of 4 characters
+	ibanPattern = regexp.MustCompile(`\b[A-Z]{2}[0-9]{2}(?: ?[A-Z0-9]){11,30}\b`)
+	// emailPattern matches email addresses
+	emailPattern = regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)
+)
+
+// attributeKind returns the kind of personal data held by the attribute with the key
+func attributeKind(key string) kind {
+	key = strings.ToLower(key)
+	if strings.HasSuffix(key, "iban") {
+		return kindIBAN
+	}
+	return attributeKinds[key]
+}
+
+type redactingHandler struct {
+	next   slog.Handler
+	detail Detail
+}
+
+// NewRedactingHandler returns a handler that redacts the personal data of the records
+// to the detail before passing them to next. next is returned as is for DetailClear.
+func NewRedactingHandler(next slog.Handler, detail Detail) slog.Handler {
+	if detail == DetailClear {
+		return next
+	}
+	return &redactingHandler{next: next, detail: detail}
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, h.redactText(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactAttr(a)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted), detail: h.detail}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name), detail: h.detail}
+}
+
+// redactAttr returns the attribute with its personal data redacted, the members of groups included
+func (h *redactingHandler) redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		members := v.Group()
+		redacted := make([]slog.Attr, len(members))
+		for i, m := range members {
+			redacted[i] = h.redactAttr(m)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindString:
+		return slog.String(a.Key, h.redactValue(a.Key, v.String()))
+	case slog.KindAny:
+		// Errors are written as their message, which may quote the values that caused them
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, h.redactValue(a.Key, err.Error()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// redactValue redacts the value of the attribute with the key
+func (h *redactingHandler) redactValue(key, value string) string {
+	switch attributeKind(key) {
+	case kindIBAN:
+		return h.maskIBAN(value)
+	case kindName:
+		return h.maskName(value)
+	case kindText:
+		return Redacted
+	default:
+		return h.redactText(value)
+	}
+}
+
+// redactText masks the IBANs and the email addresses found in free text
+func (h *redactingHandler) redactText(s string) string {
+	s = ibanPattern.ReplaceAllStringFunc(s, h.maskIBAN)
+	return emailPattern.ReplaceAllStringFunc(s, h.maskEmail)
+}
+
+// maskIBAN keeps the country code and the last 4 characters of an IBAN
+func (h *redactingHandler) maskIBAN(iban string) string {
+	compact := strings.ReplaceAll(iban, " ", "")
+	if h.detail != DetailPartial || len(compact) < 8 {
+		return Redacted
+	}
+	return compact[:2] + strings.Repeat("*", len(compact)-6) + compact[len(compact)-4:]
+}
+
+// maskName keeps the initials of a name
+func (h *redactingHandler) maskName(name string) string {
+	if h.detail != DetailPartial {
+		return Redacted
+	}
+	words := strings.FieldsFunc(name, func(r rune) bool { return unicode.IsSpace(r) || r == '-' })
+	initials := make([]string, 0, len(words))
+	for _, w := range words {
+		r, _ := utf8.DecodeRuneInString(w)
+		initials = append(initials, string(r)+".")
+	}
+	if len(initials) == 0 {
+		return ""
+	}
+	return strings.Join(initials, " ")
+}
+
+// maskEmail keeps the first letter and the domain of an email address
+func (h *redactingHandler) maskEmail(email string) string {
+	local, domain, _ := strings.Cut(email, "@")
+	if h.detail != DetailPartial || local == "" {
+		return Redacted
+	}
+	r, _ := utf8.DecodeRuneInString(local)
+	return string(r) + "***@" + domain
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logRecord logs a record with the attributes through a redacting JSON handler and returns it decoded
+func logRecord(t *testing.T, detail Detail, msg string, args ...any) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(NewRedactingHandler(slog.NewJSONHandler(&buf, nil), detail))
+	logger.Info(msg, args...)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
+
+func TestRedactingHandler(t *testing.T) {
+	const iban = "FR7630006000011234567890189"
+
+	t.Run("Partial detail", func(t *testing.T) {
+		record := logRecord(t, DetailPartial, "Bulk transfer from "+iban,
+			"organization_iban", iban,
+			"counterparty_name", "Jean-Luc Picard",
+			"description", "Invoice 42",
+			"user", "Jane Doe",
+			"error", errors.New("account DE89 3704 0044 0532 0130 00 of jane.doe@example.com not owned"),
+			"batch_id", 42,
+		)
+		assert.Equal(t, "Bulk transfer from FR*********************0189", record["msg"])
+		assert.Equal(t, "FR*********************0189", record["organization_iban"])
+		assert.Equal(t, "J. L. P.", record["counterparty_name"])
+		assert.Equal(t, Redacted, record["description"])
+		assert.Equal(t, "J. D.", record["user"])
+		assert.Equal(t, "account DE****************3000 of j***@example.com not owned", record["error"])
+		assert.Equal(t, float64(42), record["batch_id"])
+	})
+
+	t.Run("Masked detail", func(t *testing.T) {
+		record := logRecord(t, DetailMasked, "Transfer", "iban", iban, "counterparty_name", "Jean-Luc Picard", "note", "to jane.doe@example.com")
+		assert.Equal(t, Redacted, record["iban"])
+		assert.Equal(t, Redacted, record["counterparty_name"])
+		assert.Equal(t, "to "+Redacted, record["note"])
+	})
+
+	t.Run("Clear detail", func(t *testing.T) {
+		record := logRecord(t, DetailClear, "Transfer", "iban", iban, "counterparty_name", "Jean-Luc Picard")
+		assert.Equal(t, iban, record["iban"])
+		assert.Equal(t, "Jean-Luc Picard", record["counterparty_name"])
+	})
+
+	t.Run("Groups and attributes of the logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewRedactingHandler(slog.NewJSONHandler(&buf, nil), DetailPartial))
+		logger.With("account_iban", iban).WithGroup("query").Info("Request handled", slog.Group("params", "iban", iban))
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "FR*********************0189", record["account_iban"])
+		assert.Equal(t, map[string]any{"params": map[string]any{"iban": "FR*********************0189"}}, record["query"])
+	})
+
+	t.Run("Values that are not IBANs", func(t *testing.T) {
+		record := logRecord(t, DetailPartial, "Batch", "organization_bic", "DEUTDEFF", "request_id", "3f2a9c0e5b7d4e1f8a6b2c3d4e5f6a7b")
+		assert.Equal(t, "DEUTDEFF", record["organization_bic"])
+		assert.Equal(t, "3f2a9c0e5b7d4e1f8a6b2c3d4e5f6a7b", record["request_id"])
+	})
+}
+
+func TestParseDetail(t *testing.T) {
+	d, err := ParseDetail("masked")
+	assert.NoError(t, err)
+	assert.Equal(t, DetailMasked, d)
+
+	_, err = ParseDetail("none")
+	assert.Error(t, err)
+}
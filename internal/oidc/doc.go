@@ -0,0 +1,14 @@
+// Package oidc verifies the JWT bearer tokens of an OpenID Connect provider.
+//
+// Tokens must be signed with RS256 or ES256 by one of the keys of a JSON Web Key
+// Set, read from a file or fetched from a URL. The key set is cached and reloaded
+// periodically, and as soon as a token names a key it does not hold, so that the
+// provider can rotate its keys without restarting the server. The issuer, the
+// audience and the validity period of the tokens are checked, and their claims
+// are mapped onto the principal of the request.
+//
+// Key components:
+//   - KeySet: Cached JSON Web Key Set, from a file or a URL
+//   - Verifier: Verifies tokens and maps their claims onto an auth.Principal
+//   - Claims: Struct representing the claims of a verified token
+package oidc
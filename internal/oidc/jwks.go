@@ -0,0 +1,267 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// minRSAKeyBits is the minimum size of the RSA keys of a key set
+	minRSAKeyBits = 2048
+	// p256CoordinateBytes is the size of the coordinates of P-256 keys
+	p256CoordinateBytes = 32
+	// maxKeySetBytes limits the size of a key set fetched from a URL
+	maxKeySetBytes = 1 << 20
+	// minReloadInterval limits the reloads triggered by tokens naming unknown keys,
+	// so that forged tokens cannot make the server hammer the provider
+	minReloadInterval = 10 * time.Second
+)
+
+// KeySet is a JSON Web Key Set that is cached and reloaded from its source.
+// It is safe for concurrent use.
+type KeySet struct {
+	load            func(ctx context.Context) ([]byte, error)
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu       sync.Mutex
+	keys     map[string]crypto.PublicKey
+	loadedAt time.Time
+	// loading is closed when the reload in progress ends, nil when there is none;
+	// loadErr is the error of the last reload
+	loading chan struct{}
+	loadErr error
+}
+
+// NewFileKeySet returns the key set of a JWKS file, read again every refreshInterval
+func NewFileKeySet(path string, refreshInterval time.Duration) *KeySet {
+	return newKeySet(func(context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}, refreshInterval)
+}
+
+// NewRemoteKeySet returns the key set served at url, fetched again every refreshInterval
+func NewRemoteKeySet(url string, client *http.Client, refreshInterval time.Duration) *KeySet {
+	return newKeySet(func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/jwk-set+json, application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxKeySetBytes))
+	}, refreshInterval)
+}
+
+func newKeySet(load func(ctx context.Context) ([]byte, error), refreshInterval time.Duration) *KeySet {
+	return &KeySet{
+		load:            load,
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}
+}
+
+// Key returns the public key with the given ID. The key set is reloaded when it is
+// older than the refresh interval, or when it does not hold the key and was not
+// reloaded in the last few seconds. The cached keys keep being used when a reload
+// fails, an error is only returned when no key set was ever loaded. Tokens whose key
+// is cached are not held up by the reload of another token.
+func (s *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	age := now.Sub(s.loadedAt)
+	if s.keys == nil || age >= s.refreshInterval {
+		if err := s.reload(ctx, now); err != nil && s.keys == nil {
+			return nil, err
+		}
+	} else if _, ok := s.lookup(kid); !ok && (age >= minReloadInterval || s.loading != nil) {
+		// The provider may have rotated its keys since the last reload
+		s.reload(ctx, now)
+	}
+
+	key, ok := s.lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// lookup returns the key with the given ID, or the only key of the set when kid is empty
+func (s *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// reload replaces the keys with those of the source, or waits for the reload in
+// progress. It is called with the lock held, which is released while the source is
+// read so that a slow provider does not block the tokens verified with the cached
+// keys. The time of the attempt is recorded even when it fails, so that a failing
+// source is not retried on every token.
+func (s *KeySet) reload(ctx context.Context, now time.Time) error {
+	if loading := s.loading; loading != nil {
+		s.mu.Unlock()
+		select {
+		case <-loading:
+		case <-ctx.Done():
+			s.mu.Lock()
+			return ctx.Err()
+		}
+		s.mu.Lock()
+		return s.loadErr
+	}
+
+	s.loadedAt = now
+	loading := make(chan struct{})
+	s.loading = loading
+	s.mu.Unlock()
+
+	keys, err := s.fetch(ctx)
+
+	s.mu.Lock()
+	if err == nil {
+		s.keys = keys
+	}
+	s.loadErr = err
+	s.loading = nil
+	close(loading)
+	return err
+}
+
+// fetch reads and parses the key set of the source
+func (s *KeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := s.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load key set: %w", err)
+	}
+	return ParseKeySet(data)
+}
+
+// jsonWebKey is a member of the keys of a JSON Web Key Set, see RFC 7517
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// N and E are the modulus and exponent of RSA keys
+	N string `json:"n"`
+	E string `json:"e"`
+	// Crv, X and Y are the curve and coordinates of EC keys
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseKeySet returns the signature keys of a JSON Web Key Set by key ID.
+// RSA keys and EC keys on the P-256 curve are kept; keys of other types, or
+// meant for encryption, are skipped.
+func ParseKeySet(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid key set: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch {
+		case jwk.Kty == "RSA" && (jwk.Alg == "" || jwk.Alg == algRS256):
+			key, err = rsaPublicKey(jwk)
+		case jwk.Kty == "EC" && jwk.Crv == "P-256" && (jwk.Alg == "" || jwk.Alg == algES256):
+			key, err = ecdsaPublicKey(jwk)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d of key set: %w", i, err)
+		}
+		if _, ok := keys[jwk.Kid]; ok {
+			return nil, fmt.Errorf("invalid key set: duplicate key %q", jwk.Kid)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("invalid key set: no RS256 or ES256 signature key")
+	}
+	return keys, nil
+}
+
+func rsaPublicKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := decodeBigInt(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	if n.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA key of %d bits is shorter than %d bits", n.BitLen(), minRSAKeyBits)
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func ecdsaPublicKey(jwk jsonWebKey) (*ecdsa.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil || len(x) != p256CoordinateBytes {
+		return nil, errors.New("invalid x coordinate")
+	}
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil || len(y) != p256CoordinateBytes {
+		return nil, errors.New("invalid y coordinate")
+	}
+	// The point is validated as an uncompressed ECDH public key
+	point := append(append([]byte{4}, x...), y...)
+	if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+		return nil, errors.New("point is not on the P-256 curve")
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
+
+// decodeBigInt decodes an unsigned big-endian integer encoded in base64url without padding
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,211 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeySet(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	t.Run("Encryption and unsupported keys are skipped", func(t *testing.T) {
+		keys, err := ParseKeySet([]byte(`{"keys": [
+			{"kty": "EC", "kid": "enc", "use": "enc", "crv": "P-256", "x": "AA", "y": "AA"},
+			{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": "AA"},
+			` + string(mustJSON(t, jwk("ec-1", ecKey))) + `
+		]}`))
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+		assert.Contains(t, keys, "ec-1")
+	})
+
+	t.Run("Short RSA key", func(t *testing.T) {
+		_, err := ParseKeySet(keySetJSON(t, map[string]crypto.Signer{"rsa-1": smallKey}))
+		assert.ErrorContains(t, err, "shorter than 2048 bits")
+	})
+
+	t.Run("Point off the curve", func(t *testing.T) {
+		_, err := ParseKeySet([]byte(`{"keys": [{"kty": "EC", "kid": "ec-1", "crv": "P-256",
+			"x": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", "y": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}]}`))
+		assert.ErrorContains(t, err, "not on the P-256 curve")
+	})
+
+	t.Run("No signature key", func(t *testing.T) {
+		_, err := ParseKeySet([]byte(`{"keys": []}`))
+		assert.ErrorContains(t, err, "no RS256 or ES256 signature key")
+	})
+}
+
+func TestRemoteKeySet_Rotation(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var current atomic.Value
+	current.Store(keySetJSON(t, map[string]crypto.Signer{"old": oldKey}))
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(current.Load().([]byte))
+	}))
+	defer server.Close()
+
+	now := testNow
+	keys := NewRemoteKeySet(server.URL, server.Client(), time.Hour)
+	keys.now = func() time.Time { return now }
+
+	_, err = keys.Key(context.Background(), "old")
+	assert.NoError(t, err)
+	_, err = keys.Key(context.Background(), "old")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load(), "the key set is cached")
+
+	// The provider rotates its keys
+	current.Store(keySetJSON(t, map[string]crypto.Signer{"new": newKey}))
+
+	_, err = keys.Key(context.Background(), "new")
+	assert.ErrorIs(t, err, ErrInvalidToken, "unknown keys do not reload a key set that was just loaded")
+	assert.Equal(t, int32(1), fetches.Load())
+
+	now = now.Add(minReloadInterval)
+	_, err = keys.Key(context.Background(), "new")
+	assert.NoError(t, err, "unknown keys reload the key set")
+	assert.Equal(t, int32(2), fetches.Load())
+
+	_, err = keys.Key(context.Background(), "old")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestFileKeySet_Refresh(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, keySetJSON(t, map[string]crypto.Signer{"old": oldKey}), 0o600))
+
+	now := testNow
+	keys := NewFileKeySet(path, time.Hour)
+	keys.now = func() time.Time { return now }
+
+	_, err = keys.Key(context.Background(), "old")
+	assert.NoError(t, err)
+
+	t.Run("Failed reload keeps the cached keys", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+		now = now.Add(time.Hour)
+
+		_, err := keys.Key(context.Background(), "old")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Key set is reloaded after the refresh interval", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, keySetJSON(t, map[string]crypto.Signer{"new": newKey}), 0o600))
+		now = now.Add(time.Hour)
+
+		_, err := keys.Key(context.Background(), "new")
+		assert.NoError(t, err)
+		_, err = keys.Key(context.Background(), "old")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Missing key set", func(t *testing.T) {
+		missing := NewFileKeySet(filepath.Join(t.TempDir(), "missing.json"), time.Hour)
+
+		_, err := missing.Key(context.Background(), "old")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestKeySet_SlowReload(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	keys := newKeySet(func(context.Context) ([]byte, error) {
+		if fetches.Add(1) == 1 {
+			return keySetJSON(t, map[string]crypto.Signer{"old": oldKey}), nil
+		}
+		// The provider is slow to answer the reload
+		close(started)
+		<-release
+		return keySetJSON(t, map[string]crypto.Signer{"new": newKey}), nil
+	}, time.Hour)
+	var mu sync.Mutex
+	now := testNow
+	keys.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	_, err = keys.Key(context.Background(), "old")
+	require.NoError(t, err)
+
+	mu.Lock()
+	now = now.Add(time.Hour)
+	mu.Unlock()
+	reloaded := make(chan error)
+	go func() {
+		_, err := keys.Key(context.Background(), "new")
+		reloaded <- err
+	}()
+	<-started
+
+	t.Run("Cached keys are used during the reload", func(t *testing.T) {
+		_, err := keys.Key(context.Background(), "old")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unknown keys wait for the reload in progress", func(t *testing.T) {
+		waited := make(chan error)
+		go func() {
+			_, err := keys.Key(context.Background(), "new")
+			waited <- err
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := keys.Key(ctx, "new")
+		assert.Error(t, err, "waiting ends with the request")
+
+		close(release)
+		assert.NoError(t, <-reloaded)
+		assert.NoError(t, <-waited)
+		assert.Equal(t, int32(2), fetches.Load(), "the key set is loaded once")
+	})
+}
+
+// mustJSON returns the JSON encoding of v
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
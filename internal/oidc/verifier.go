@@ -0,0 +1,290 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"moneytransfer/internal/auth"
+)
+
+const (
+	algRS256 = "RS256"
+	algES256 = "ES256"
+
+	// maxTokenBytes limits the size of the tokens that are parsed
+	maxTokenBytes = 16 << 10
+	// maxNumericDate is 9999-12-31T23:59:59Z, the latest date claim accepted
+	maxNumericDate = 253402300799
+)
+
+// ErrInvalidToken is returned when a token cannot be verified
+var ErrInvalidToken = auth.ErrInvalidToken
+
+// Config is the configuration of a Verifier
+type Config struct {
+	// Issuer must be the iss claim of the tokens
+	Issuer string
+	// Audience must be one of the aud claims of the tokens
+	Audience string
+	// OrganizationClaim and UserClaim name the claims holding the organization and the
+	// user of the principal; nested claims are named by their path, such as org.name
+	OrganizationClaim string
+	UserClaim         string
+	// Leeway is the clock skew tolerated when checking the validity period of the tokens
+	Leeway time.Duration
+}
+
+// Verifier verifies the tokens signed by the keys of a key set
+type Verifier struct {
+	keys   *KeySet
+	config Config
+	now    func() time.Time
+}
+
+// NewVerifier returns a verifier of the tokens signed by the keys of keys
+func NewVerifier(keys *KeySet, config Config) *Verifier {
+	return &Verifier{
+		keys:   keys,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Claims are the claims of a verified token
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	// NotBefore and IssuedAt are zero when the token does not have them
+	NotBefore time.Time
+	IssuedAt  time.Time
+	// Raw holds all the claims of the token
+	Raw map[string]any
+}
+
+// String returns the string claim at the given path, empty if there is none
+func (c *Claims) String(path string) string {
+	var value any = c.Raw
+	for _, name := range strings.Split(path, ".") {
+		members, ok := value.(map[string]any)
+		if !ok {
+			return ""
+		}
+		value = members[name]
+	}
+	s, _ := value.(string)
+	return s
+}
+
+// header is the JOSE header of a token
+type header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid"`
+	Crit []string `json:"crit"`
+}
+
+// Verify checks the signature, issuer, subject, audience and validity period of a token
+// and returns its claims. Errors about the token wrap ErrInvalidToken, other
+// errors mean that the key set could not be loaded.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	if len(token) > maxTokenBytes {
+		return nil, fmt.Errorf("%w: token too large", ErrInvalidToken)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, fmt.Errorf("%w: malformed header: %v", ErrInvalidToken, err)
+	}
+	if h.Alg != algRS256 && h.Alg != algES256 {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, h.Alg)
+	}
+	if len(h.Crit) != 0 {
+		return nil, fmt.Errorf("%w: unsupported critical header parameters %v", ErrInvalidToken, h.Crit)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	key, err := v.keys.Key(ctx, h.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(h.Alg, key, digest[:], signature) {
+		return nil, fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+	}
+
+	var raw map[string]any
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims: %v", ErrInvalidToken, err)
+	}
+	claims, err := newClaims(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// VerifyToken verifies a token and returns the principal of its claims.
+// The organization claim is required, the user defaults to the subject.
+func (v *Verifier) VerifyToken(ctx context.Context, token string) (auth.Principal, error) {
+	claims, err := v.Verify(ctx, token)
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	organization := claims.String(v.config.OrganizationClaim)
+	if organization == "" {
+		return auth.Principal{}, fmt.Errorf("%w: missing organization claim %q", ErrInvalidToken, v.config.OrganizationClaim)
+	}
+	user := claims.Subject
+	if v.config.UserClaim != "" {
+		user = claims.String(v.config.UserClaim)
+	}
+	if user == "" {
+		return auth.Principal{}, fmt.Errorf("%w: missing user claim %q", ErrInvalidToken, v.config.UserClaim)
+	}
+	return auth.Principal{
+		OrganizationName: organization,
+		User:             user,
+		Subject:          "oidc:" + claims.Subject,
+	}, nil
+}
+
+func (v *Verifier) checkClaims(c *Claims) error {
+	if c.Issuer != v.config.Issuer {
+		return fmt.Errorf("unexpected issuer %q", c.Issuer)
+	}
+	// The subject identifies the principal the roles are granted to
+	if c.Subject == "" {
+		return errors.New("missing sub claim")
+	}
+	audience := false
+	for _, aud := range c.Audience {
+		audience = audience || aud == v.config.Audience
+	}
+	if !audience {
+		return fmt.Errorf("token is not intended for audience %q", v.config.Audience)
+	}
+
+	now := v.now()
+	if c.ExpiresAt.IsZero() {
+		return errors.New("missing exp claim")
+	}
+	if !now.Before(c.ExpiresAt.Add(v.config.Leeway)) {
+		return errors.New("token is expired")
+	}
+	if !c.NotBefore.IsZero() && now.Add(v.config.Leeway).Before(c.NotBefore) {
+		return errors.New("token is not valid yet")
+	}
+	return nil
+}
+
+// newClaims reads the registered claims of a token
+func newClaims(raw map[string]any) (*Claims, error) {
+	c := &Claims{Raw: raw}
+	var ok bool
+	if c.Issuer, ok = stringClaim(raw, "iss"); !ok {
+		return nil, errors.New("invalid iss claim")
+	}
+	if c.Subject, ok = stringClaim(raw, "sub"); !ok {
+		return nil, errors.New("invalid sub claim")
+	}
+
+	// aud is either a string or an array of strings
+	switch aud := raw["aud"].(type) {
+	case nil:
+	case string:
+		c.Audience = []string{aud}
+	case []any:
+		for _, a := range aud {
+			s, ok := a.(string)
+			if !ok {
+				return nil, errors.New("invalid aud claim")
+			}
+			c.Audience = append(c.Audience, s)
+		}
+	default:
+		return nil, errors.New("invalid aud claim")
+	}
+
+	for name, t := range map[string]*time.Time{"exp": &c.ExpiresAt, "nbf": &c.NotBefore, "iat": &c.IssuedAt} {
+		if *t, ok = timeClaim(raw, name); !ok {
+			return nil, fmt.Errorf("invalid %s claim", name)
+		}
+	}
+	return c, nil
+}
+
+// stringClaim returns the string claim name, empty if it is missing
+func stringClaim(raw map[string]any, name string) (string, bool) {
+	value, ok := raw[name]
+	if !ok {
+		return "", true
+	}
+	s, ok := value.(string)
+	return s, ok
+}
+
+// timeClaim returns the NumericDate claim name, zero if it is missing
+func timeClaim(raw map[string]any, name string) (time.Time, bool) {
+	value, ok := raw[name]
+	if !ok {
+		return time.Time{}, true
+	}
+	seconds, ok := value.(float64)
+	if !ok || seconds < 0 || seconds > maxNumericDate {
+		return time.Time{}, false
+	}
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*1e9)), true
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// verifySignature reports whether signature is the signature of digest by key with alg
+func verifySignature(alg string, key crypto.PublicKey, digest, signature []byte) bool {
+	switch alg {
+	case algRS256:
+		rsaKey, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest, signature) == nil
+	case algES256:
+		// ES256 signatures are the concatenation of r and s, see RFC 7518
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 2*p256CoordinateBytes {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:p256CoordinateBytes])
+		s := new(big.Int).SetBytes(signature[p256CoordinateBytes:])
+		return ecdsa.Verify(ecKey, digest, r, s)
+	default:
+		return false
+	}
+}
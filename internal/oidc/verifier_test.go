@@ -0,0 +1,199 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testNow is the time the tokens of the tests are verified at
+var testNow = time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+
+// signToken returns a token of the claims signed with key, which is an *rsa.PrivateKey or an *ecdsa.PrivateKey
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]any{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// jwk returns the JSON Web Key of the public key of key
+func jwk(kid string, key crypto.Signer) map[string]any {
+	switch k := key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]any{
+			"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+			"n": base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		x, y := make([]byte, 32), make([]byte, 32)
+		k.X.FillBytes(x)
+		k.Y.FillBytes(y)
+		return map[string]any{
+			"kty": "EC", "kid": kid, "crv": "P-256",
+			"x": base64.RawURLEncoding.EncodeToString(x),
+			"y": base64.RawURLEncoding.EncodeToString(y),
+		}
+	}
+	return nil
+}
+
+// keySetJSON returns the JSON Web Key Set of the keys, by key ID
+func keySetJSON(t *testing.T, keys map[string]crypto.Signer) []byte {
+	t.Helper()
+	set := map[string][]map[string]any{"keys": {}}
+	for kid, key := range keys {
+		set["keys"] = append(set["keys"], jwk(kid, key))
+	}
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+	return data
+}
+
+// staticKeySet returns a key set that always loads the given JSON Web Key Set
+func staticKeySet(data []byte) *KeySet {
+	s := newKeySet(func(context.Context) ([]byte, error) { return data, nil }, time.Hour)
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+func TestVerifier_VerifyToken(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	keys := staticKeySet(keySetJSON(t, map[string]crypto.Signer{"rsa-1": rsaKey, "ec-1": ecKey}))
+	verifier := NewVerifier(keys, Config{
+		Issuer:            "https://id.example.com",
+		Audience:          "moneytransfer",
+		OrganizationClaim: "org.name",
+		UserClaim:         "email",
+		Leeway:            time.Minute,
+	})
+	verifier.now = func() time.Time { return testNow }
+
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"iss":   "https://id.example.com",
+			"sub":   "user-42",
+			"aud":   []string{"moneytransfer", "other"},
+			"exp":   testNow.Add(time.Hour).Unix(),
+			"iat":   testNow.Unix(),
+			"email": "jane@acme.example",
+			"org":   map[string]any{"name": "Acme"},
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = v
+		}
+		return c
+	}
+
+	want := auth.Principal{OrganizationName: "Acme", User: "jane@acme.example", Subject: "oidc:user-42"}
+
+	t.Run("RS256 token", func(t *testing.T) {
+		p, err := verifier.VerifyToken(context.Background(), signToken(t, "RS256", "rsa-1", rsaKey, claims(nil)))
+		assert.NoError(t, err)
+		assert.Equal(t, want, p)
+	})
+
+	t.Run("ES256 token with a single audience", func(t *testing.T) {
+		token := signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"aud": "moneytransfer"}))
+		p, err := verifier.VerifyToken(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, want, p)
+	})
+
+	t.Run("Expired within the leeway", func(t *testing.T) {
+		token := signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"exp": testNow.Add(-30 * time.Second).Unix()}))
+		_, err := verifier.VerifyToken(context.Background(), token)
+		assert.NoError(t, err)
+	})
+
+	invalid := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"Expired", signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"exp": testNow.Add(-2 * time.Minute).Unix()})), "token is expired"},
+		{"Not valid yet", signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"nbf": testNow.Add(2 * time.Minute).Unix()})), "token is not valid yet"},
+		{"Missing expiry", signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"exp": nil})), "missing exp claim"},
+		{"Other issuer", signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"iss": "https://evil.example.com"})), "unexpected issuer"},
+		{"Missing subject", signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"sub": nil})), "missing sub claim"},
+		{"Empty subject", signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"sub": ""})), "missing sub claim"},
+		{"Other audience", signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"aud": "other"})), `not intended for audience "moneytransfer"`},
+		{"Missing organization", signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"org": "Acme"})), `missing organization claim "org.name"`},
+		{"Missing user", signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"email": nil})), `missing user claim "email"`},
+		{"Signed by another key", signToken(t, "ES256", "ec-1", otherKey, claims(nil)), "invalid signature"},
+		{"Algorithm of another key type", signToken(t, "ES256", "rsa-1", ecKey, claims(nil)), "invalid signature"},
+		{"Unknown key", signToken(t, "ES256", "ec-2", otherKey, claims(nil)), `unknown key "ec-2"`},
+		{"Unsigned token", unsignedToken(t, claims(nil)), `unsupported algorithm "none"`},
+		{"Malformed token", "mtk_0123_abcd", "malformed token"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.VerifyToken(context.Background(), tt.token)
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// unsignedToken returns a token of the claims with the none algorithm
+func unsignedToken(t *testing.T, claims map[string]any) string {
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
+
+func TestClaims_String(t *testing.T) {
+	c := &Claims{Raw: map[string]any{
+		"org":  map[string]any{"name": "Acme", "id": 42.0},
+		"user": "jane",
+	}}
+
+	assert.Equal(t, "jane", c.String("user"))
+	assert.Equal(t, "Acme", c.String("org.name"))
+	assert.Empty(t, c.String("org.id"))
+	assert.Empty(t, c.String("user.name"))
+	assert.Empty(t, c.String("missing"))
+}
@@ -0,0 +1,12 @@
+// Package outbound hands the executed transfers to the sending bank.
+//
+// The job gathers the executed transfers that were not sent yet and writes them to pain.001
+// credit transfer initiation files in an output directory, one file per debtor account with
+// one payment information block per execution date. Transfers are marked as sent in the
+// transaction that claimed them, and a file only gets its final name once that transaction
+// is committed, so that a transfer is never part of two files.
+//
+// Key components:
+//   - Job: Writes the files of the unsent transfers, once or periodically
+//   - JobConfig: Output directory, interval and batch size of the job
+package outbound
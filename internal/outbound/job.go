@@ -0,0 +1,324 @@
+package outbound
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/iso20022"
+	"moneytransfer/internal/transfer"
+)
+
+const (
+	// fileExtension is the extension of the files handed to the sending bank
+	fileExtension = ".xml"
+	// partialExtension is appended to the name of a file until its transfers are marked as sent
+	partialExtension = ".partial"
+	// notProvided is the end-to-end ID of transfers that were given none
+	notProvided = "NOTPROVIDED"
+	// currency is the currency of the bank accounts
+	currency = "EUR"
+	// maxNameLength and maxRemittanceLength are the lengths of names and unstructured
+	// remittance information allowed in SEPA credit transfers
+	maxNameLength       = 70
+	maxRemittanceLength = 140
+)
+
+// JobConfig is a struct that contains the configuration of the job
+type JobConfig struct {
+	// Dir is the directory the files are written to
+	Dir string
+	// Interval is the interval at which the job looks for unsent transfers
+	Interval time.Duration
+	// BatchSize is the maximum number of transfers sent at once
+	BatchSize int
+}
+
+// Job writes the executed transfers that were not sent yet to pain.001 files
+type Job struct {
+	db           *sql.DB
+	accountRepo  account.Repository
+	transferRepo transfer.Repository
+	logger       *slog.Logger
+	config       JobConfig
+}
+
+// NewJob is a function that creates a new outbound job
+func NewJob(db *sql.DB, accountRepo account.Repository, transferRepo transfer.Repository, logger *slog.Logger, config JobConfig) *Job {
+	return &Job{
+		db:           db,
+		accountRepo:  accountRepo,
+		transferRepo: transferRepo,
+		logger:       logger.With("component", "outbound_job"),
+		config:       config,
+	}
+}
+
+// Run sends the unsent transfers at every interval until ctx is canceled
+func (j *Job) Run(ctx context.Context) {
+	j.logger.Info("Outbound job started", "dir", j.config.Dir)
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			j.logger.Error("Failed to send transfers", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			j.logger.Info("Outbound job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce writes the files of all the transfers that are unsent when it is called,
+// and returns their paths. Nothing is written while another job is sending transfers.
+func (j *Job) RunOnce(ctx context.Context) ([]string, error) {
+	var paths []string
+	for {
+		written, claimed, err := j.sendBatch(ctx)
+		paths = append(paths, written...)
+		if err != nil || claimed < j.config.BatchSize {
+			return paths, err
+		}
+	}
+}
+
+// sendBatch writes the files of up to BatchSize unsent transfers and returns their paths,
+// along with the number of transfers claimed
+func (j *Job) sendBatch(ctx context.Context) ([]string, int, error) {
+	tx, err := j.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	locked, err := j.transferRepo.LockSending(ctx, tx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !locked {
+		j.logger.Debug("Transfers are being sent by another job")
+		return nil, 0, nil
+	}
+	// Files left partial by a job that stopped are completed or removed before new ones are written
+	if err := j.recoverPartialFiles(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	transfers, err := j.transferRepo.ClaimUnsent(ctx, tx, j.config.BatchSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(transfers) == 0 {
+		return nil, 0, nil
+	}
+
+	now := time.Now().UTC()
+	var partials []string
+	defer func() {
+		// Partial files are renamed once committed, those left are removed
+		for _, p := range partials {
+			os.Remove(p)
+		}
+	}()
+	for start := 0; start < len(transfers); {
+		end := start + 1
+		for end < len(transfers) && transfers[end].BankAccountID == transfers[start].BankAccountID {
+			end++
+		}
+		group := transfers[start:end]
+		start = end
+
+		acc, err := j.accountRepo.Get(group[0].BankAccountID, tx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get bank account %d: %w", group[0].BankAccountID, err)
+		}
+		// Transfers are sent once, so the first of them identifies the message
+		messageID := fmt.Sprintf("OUT-%d-%d", acc.ID, group[0].ID)
+		partial := filepath.Join(j.config.Dir, messageID+fileExtension+partialExtension)
+		partials = append(partials, partial)
+		if err := writeFile(partial, messageID, acc, group, now); err != nil {
+			return nil, 0, err
+		}
+
+		ids := make([]int64, len(group))
+		for i, t := range group {
+			ids[i] = t.ID
+		}
+		if err := j.transferRepo.MarkSent(ctx, tx, ids, messageID, now); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	paths := make([]string, 0, len(partials))
+	for _, partial := range partials {
+		path := strings.TrimSuffix(partial, partialExtension)
+		if err := os.Rename(partial, path); err != nil {
+			// The transfers are sent, the file is completed by the next run
+			return paths, len(transfers), fmt.Errorf("failed to complete file %s: %w", path, err)
+		}
+		paths = append(paths, path)
+		j.logger.Info("Transfers sent", "file", path)
+	}
+	partials = nil
+	return paths, len(transfers), nil
+}
+
+// recoverPartialFiles completes the partial files whose transfers were marked as sent,
+// and removes the others
+func (j *Job) recoverPartialFiles(ctx context.Context) error {
+	partials, err := filepath.Glob(filepath.Join(j.config.Dir, "*"+fileExtension+partialExtension))
+	if err != nil {
+		return err
+	}
+	for _, partial := range partials {
+		path := strings.TrimSuffix(partial, partialExtension)
+		n, err := j.transferRepo.CountSent(ctx, strings.TrimSuffix(filepath.Base(path), fileExtension))
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			j.logger.Warn("Removing file of unsent transfers", "file", partial)
+			if err := os.Remove(partial); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		j.logger.Warn("Completing file of sent transfers", "file", path)
+		if err := os.Rename(partial, path); err != nil {
+			return fmt.Errorf("failed to complete file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// writeFile writes the pain.001 message of the transfers of the account to path and syncs it,
+// with one payment information block per execution date
+func writeFile(path, messageID string, acc *account.BankAccount, transfers []transfer.Transfer, now time.Time) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := writeMessage(w, messageID, acc, transfers, now); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// writeMessage writes the pain.001 message of the transfers, which are ordered by creation time
+func writeMessage(w *bufio.Writer, messageID string, acc *account.BankAccount, transfers []transfer.Transfer, now time.Time) error {
+	var total int64
+	for _, t := range transfers {
+		total += t.AmountCents
+	}
+	pw, err := iso20022.NewPain001Writer(w, iso20022.GroupHeader{
+		MessageID:            messageID,
+		CreationDateTime:     now.Format(time.RFC3339),
+		NumberOfTransactions: strconv.Itoa(len(transfers)),
+		ControlSum:           formatCents(total),
+		InitiatingParty:      iso20022.Party{Name: truncate(acc.OrganizationName, maxNameLength)},
+	})
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(transfers); {
+		date := executionDate(transfers[start])
+		end := start
+		var sum int64
+		for end < len(transfers) && executionDate(transfers[end]) == date {
+			sum += transfers[end].AmountCents
+			end++
+		}
+
+		err := pw.StartPaymentInformation(iso20022.PaymentInformation{
+			ID:                     messageID + "-" + strings.ReplaceAll(date, "-", ""),
+			PaymentMethod:          iso20022.PaymentMethodTransfer,
+			NumberOfTransactions:   strconv.Itoa(end - start),
+			ControlSum:             formatCents(sum),
+			PaymentTypeInformation: iso20022.NewPaymentTypeInformation(iso20022.ServiceLevelSEPA),
+			RequestedExecutionDate: iso20022.DateChoice{Value: date},
+			Debtor:                 iso20022.Party{Name: truncate(acc.OrganizationName, maxNameLength)},
+			DebtorAccount:          iso20022.Account{ID: iso20022.AccountID{IBAN: acc.IBAN}, Currency: currency},
+			DebtorAgent:            iso20022.Agent{FinancialInstitution: iso20022.FinancialInstitution{BIC: acc.BIC}},
+			ChargeBearer:           iso20022.ChargeBearerSLEV,
+		})
+		if err != nil {
+			return err
+		}
+		for _, t := range transfers[start:end] {
+			if err := pw.WriteTransaction(creditTransfer(t)); err != nil {
+				return err
+			}
+		}
+		start = end
+	}
+	return pw.Close()
+}
+
+// creditTransfer returns the credit transfer transaction of the transfer
+func creditTransfer(t transfer.Transfer) iso20022.CreditTransferTransaction {
+	endToEndID := t.EndToEndID
+	if endToEndID == "" {
+		endToEndID = notProvided
+	}
+	tx := iso20022.CreditTransferTransaction{
+		PaymentID: iso20022.PaymentID{
+			InstructionID: "T" + strconv.FormatInt(t.ID, 10),
+			EndToEndID:    endToEndID,
+		},
+		Amount:          iso20022.TransactionAmount{Instructed: iso20022.Amount{Value: formatCents(t.AmountCents), Currency: currency}},
+		CreditorAgent:   iso20022.Agent{FinancialInstitution: iso20022.FinancialInstitution{BIC: t.CounterpartyBIC}},
+		Creditor:        iso20022.Party{Name: truncate(t.CounterpartyName, maxNameLength)},
+		CreditorAccount: iso20022.Account{ID: iso20022.AccountID{IBAN: t.CounterpartyIBAN}},
+	}
+	if t.Description != "" {
+		tx.RemittanceInformation.Unstructured = []string{truncate(t.Description, maxRemittanceLength)}
+	}
+	return tx
+}
+
+// executionDate returns the date the transfer was executed, in UTC
+func executionDate(t transfer.Transfer) string {
+	return t.CreatedAt.UTC().Format("2006-01-02")
+}
+
+// formatCents formats an amount in cents as a decimal amount in euros
+func formatCents(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// truncate shortens s to at most n characters
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,185 @@
+package outbound_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/iso20022"
+	"moneytransfer/internal/outbound"
+	"moneytransfer/internal/transfer"
+	"moneytransfer/mock"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJob_RunOnce(t *testing.T) {
+	march4 := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	march5 := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	newTransfer := func(id, accountID, amountCents int64, createdAt time.Time) transfer.Transfer {
+		return transfer.Transfer{
+			ID:               id,
+			CounterpartyName: "John Doe",
+			CounterpartyIBAN: "DE89370400440532013000",
+			CounterpartyBIC:  "DEUTDEFF",
+			AmountCents:      amountCents,
+			BankAccountID:    accountID,
+			Description:      fmt.Sprintf("Invoice %d", id),
+			EndToEndID:       fmt.Sprintf("E2E-%d", id),
+			Status:           transfer.StatusExecuted,
+			CreatedAt:        createdAt,
+		}
+	}
+	accounts := map[int64]*account.BankAccount{
+		1: {ID: 1, OrganizationName: "ACME Corp", IBAN: "FR1420041010050500013M02606", BIC: "BNPAFRPP"},
+		2: {ID: 2, OrganizationName: "Globex", IBAN: "FR7630006000011234567890189", BIC: "AGRIFRPP"},
+	}
+
+	// setup returns a job writing to a new directory, with up to batchSize transfers sent at once
+	setup := func(t *testing.T, batchSize int) (sqlmock.Sqlmock, *mock.AccountRepositoryMock, *mock.TransferRepositoryMock, *outbound.Job, string) {
+		ctrl := gomock.NewController(t)
+		mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+		mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+		mockDB, sqlMock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { mockDB.Close() })
+
+		dir := t.TempDir()
+		job := outbound.NewJob(mockDB, mockAccountRepo, mockTransferRepo, slog.Default(), outbound.JobConfig{Dir: dir, Interval: time.Second, BatchSize: batchSize})
+		return sqlMock, mockAccountRepo, mockTransferRepo, job, dir
+	}
+
+	t.Run("Transfers are written by debtor account and execution date", func(t *testing.T) {
+		sqlMock, mockAccountRepo, mockTransferRepo, job, dir := setup(t, 10)
+
+		sqlMock.ExpectBegin()
+		mockTransferRepo.EXPECT().LockSending(gomock.Any(), gomock.Any()).Return(true, nil)
+		mockTransferRepo.EXPECT().ClaimUnsent(gomock.Any(), gomock.Any(), 10).Return([]transfer.Transfer{
+			newTransfer(3, 1, 10050, march4),
+			newTransfer(5, 1, 5000, march4),
+			newTransfer(8, 1, 1, march5),
+			newTransfer(4, 2, 700, march5),
+		}, nil)
+		mockAccountRepo.EXPECT().Get(int64(1), gomock.Any()).Return(accounts[1], nil)
+		mockAccountRepo.EXPECT().Get(int64(2), gomock.Any()).Return(accounts[2], nil)
+		mockTransferRepo.EXPECT().MarkSent(gomock.Any(), gomock.Any(), []int64{3, 5, 8}, "OUT-1-3", gomock.Any()).Return(nil)
+		mockTransferRepo.EXPECT().MarkSent(gomock.Any(), gomock.Any(), []int64{4}, "OUT-2-4", gomock.Any()).Return(nil)
+		sqlMock.ExpectCommit()
+
+		paths, err := job.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+		assert.Equal(t, []string{filepath.Join(dir, "OUT-1-3.xml"), filepath.Join(dir, "OUT-2-4.xml")}, paths)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 2, "no partial file is left")
+
+		f, err := os.Open(paths[0])
+		require.NoError(t, err)
+		defer f.Close()
+		var transactions []string
+		header, err := iso20022.ReadPain001(f, func(p *iso20022.PaymentInformation, tx *iso20022.CreditTransferTransaction) error {
+			transactions = append(transactions, fmt.Sprintf("%s %s %s %s %s", p.ID, p.RequestedExecutionDate, tx.PaymentID.InstructionID, tx.PaymentID.EndToEndID, tx.Amount.Instructed.Value))
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "OUT-1-3", header.GroupHeader.MessageID)
+		assert.Equal(t, "3", header.GroupHeader.NumberOfTransactions)
+		assert.Equal(t, "150.51", header.GroupHeader.ControlSum)
+		require.Len(t, header.PaymentInformation, 2)
+		assert.Equal(t, "2", header.PaymentInformation[0].NumberOfTransactions)
+		assert.Equal(t, "150.50", header.PaymentInformation[0].ControlSum)
+		assert.Equal(t, "FR1420041010050500013M02606", header.PaymentInformation[0].DebtorAccount.ID.IBAN)
+		assert.Equal(t, "BNPAFRPP", header.PaymentInformation[0].DebtorAgent.FinancialInstitution.Code())
+		assert.Equal(t, []string{
+			"OUT-1-3-20240304 2024-03-04 T3 E2E-3 100.50",
+			"OUT-1-3-20240304 2024-03-04 T5 E2E-5 50.00",
+			"OUT-1-3-20240305 2024-03-05 T8 E2E-8 0.01",
+		}, transactions)
+	})
+
+	t.Run("Batches are sent until no transfer is left", func(t *testing.T) {
+		sqlMock, mockAccountRepo, mockTransferRepo, job, dir := setup(t, 2)
+
+		sqlMock.ExpectBegin()
+		mockTransferRepo.EXPECT().LockSending(gomock.Any(), gomock.Any()).Return(true, nil)
+		mockTransferRepo.EXPECT().ClaimUnsent(gomock.Any(), gomock.Any(), 2).Return([]transfer.Transfer{newTransfer(1, 1, 100, march4), newTransfer(2, 1, 100, march4)}, nil)
+		mockAccountRepo.EXPECT().Get(int64(1), gomock.Any()).Return(accounts[1], nil)
+		mockTransferRepo.EXPECT().MarkSent(gomock.Any(), gomock.Any(), []int64{1, 2}, "OUT-1-1", gomock.Any()).Return(nil)
+		sqlMock.ExpectCommit()
+		sqlMock.ExpectBegin()
+		mockTransferRepo.EXPECT().LockSending(gomock.Any(), gomock.Any()).Return(true, nil)
+		mockTransferRepo.EXPECT().ClaimUnsent(gomock.Any(), gomock.Any(), 2).Return([]transfer.Transfer{newTransfer(6, 1, 100, march4)}, nil)
+		mockAccountRepo.EXPECT().Get(int64(1), gomock.Any()).Return(accounts[1], nil)
+		mockTransferRepo.EXPECT().MarkSent(gomock.Any(), gomock.Any(), []int64{6}, "OUT-1-6", gomock.Any()).Return(nil)
+		sqlMock.ExpectCommit()
+
+		paths, err := job.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(dir, "OUT-1-1.xml"), filepath.Join(dir, "OUT-1-6.xml")}, paths)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("Nothing is sent while another job is sending", func(t *testing.T) {
+		sqlMock, _, mockTransferRepo, job, dir := setup(t, 10)
+
+		sqlMock.ExpectBegin()
+		mockTransferRepo.EXPECT().LockSending(gomock.Any(), gomock.Any()).Return(false, nil)
+		sqlMock.ExpectRollback()
+
+		paths, err := job.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, paths)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Files are removed when the transfers cannot be marked as sent", func(t *testing.T) {
+		sqlMock, mockAccountRepo, mockTransferRepo, job, dir := setup(t, 10)
+
+		sqlMock.ExpectBegin()
+		mockTransferRepo.EXPECT().LockSending(gomock.Any(), gomock.Any()).Return(true, nil)
+		mockTransferRepo.EXPECT().ClaimUnsent(gomock.Any(), gomock.Any(), 10).Return([]transfer.Transfer{newTransfer(3, 1, 100, march4), newTransfer(4, 2, 100, march4)}, nil)
+		mockAccountRepo.EXPECT().Get(int64(1), gomock.Any()).Return(accounts[1], nil)
+		mockTransferRepo.EXPECT().MarkSent(gomock.Any(), gomock.Any(), []int64{3}, "OUT-1-3", gomock.Any()).Return(nil)
+		mockAccountRepo.EXPECT().Get(int64(2), gomock.Any()).Return(accounts[2], nil)
+		mockTransferRepo.EXPECT().MarkSent(gomock.Any(), gomock.Any(), []int64{4}, "OUT-2-4", gomock.Any()).Return(transfer.ErrAlreadySent)
+		sqlMock.ExpectRollback()
+
+		paths, err := job.RunOnce(context.Background())
+		assert.ErrorIs(t, err, transfer.ErrAlreadySent)
+		assert.Empty(t, paths)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Partial files are completed once their transfers are sent", func(t *testing.T) {
+		sqlMock, _, mockTransferRepo, job, dir := setup(t, 10)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "OUT-1-3.xml.partial"), []byte("<Document/>"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "OUT-1-9.xml.partial"), []byte("<Document/>"), 0o644))
+
+		sqlMock.ExpectBegin()
+		mockTransferRepo.EXPECT().LockSending(gomock.Any(), gomock.Any()).Return(true, nil)
+		mockTransferRepo.EXPECT().CountSent(gomock.Any(), "OUT-1-3").Return(2, nil)
+		mockTransferRepo.EXPECT().CountSent(gomock.Any(), "OUT-1-9").Return(0, nil)
+		mockTransferRepo.EXPECT().ClaimUnsent(gomock.Any(), gomock.Any(), 10).Return(nil, nil)
+		sqlMock.ExpectRollback()
+
+		_, err := job.RunOnce(context.Background())
+		require.NoError(t, err)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "OUT-1-3.xml", entries[0].Name())
+	})
+}
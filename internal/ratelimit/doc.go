@@ -0,0 +1,15 @@
+// Package ratelimit provides the rate limits and daily quotas of the callers of the API.
+//
+// Requests are limited with token buckets: a bucket holds up to a burst of tokens and is
+// refilled at a steady rate, each request takes a token and is rejected when none is left.
+// The requests of each client address are limited as well, before their credentials are
+// checked. Bulk uploads are also counted against daily quotas of lines and bytes, reset every
+// day at midnight UTC: both are reserved in a single step once the upload is read, and the
+// reservation is refunded when the upload is rejected.
+//
+// Key components:
+//   - Limit: Struct representing the rate and the burst of a token bucket
+//   - Limiter: Struct applying the limits and the quotas of the requests to a store
+//   - Store: Interface to keep the buckets and the quota usage, in memory for a single
+//     instance or in Postgres for instances sharing their limits
+package ratelimit
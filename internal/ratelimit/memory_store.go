@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the number of tokens taken between two removals of the full buckets
+const sweepInterval = 1024
+
+type memoryBucket struct {
+	bucket
+	// fullAt is when the bucket is full again, it can then be forgotten
+	fullAt time.Time
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]memoryBucket
+	takes   int
+	// usage is the usage of the quotas on day, the usage of the previous days is dropped
+	day   time.Time
+	usage map[string]int64
+}
+
+// NewMemoryStore returns a store keeping the buckets and quotas in memory,
+// they are not shared between instances and are lost on restart
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets: make(map[string]memoryBucket),
+		usage:   make(map[string]int64),
+	}
+}
+
+func (s *memoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.takes++
+	if s.takes%sweepInterval == 0 {
+		for k, b := range s.buckets {
+			if !b.fullAt.After(now) {
+				delete(s.buckets, k)
+			}
+		}
+	}
+
+	b, r := take(s.buckets[key].bucket, limit, now)
+	s.buckets[key] = memoryBucket{bucket: b, fullAt: now.Add(r.Reset)}
+	return r, nil
+}
+
+func (s *memoryStore) Consume(_ context.Context, day time.Time, usages []Usage) ([]int64, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if day.After(s.day) {
+		s.day = day
+		s.usage = make(map[string]int64)
+	}
+
+	used := make([]int64, len(usages))
+	for i, u := range usages {
+		used[i] = s.usage[u.Key]
+		if used[i]+u.Amount > u.Max {
+			return used, i, nil
+		}
+	}
+	for i, u := range usages {
+		used[i] += u.Amount
+		s.usage[u.Key] = used[i]
+	}
+	return used, -1, nil
+}
+
+func (s *memoryStore) Refund(_ context.Context, day time.Time, usages []Usage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The usage of the previous days is already dropped
+	if !day.Equal(s.day) {
+		return nil
+	}
+	for _, u := range usages {
+		s.usage[u.Key] = max(s.usage[u.Key]-u.Amount, 0)
+	}
+	return nil
+}
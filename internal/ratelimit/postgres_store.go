@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore returns a store keeping the buckets and quotas in the database,
+// shared by all the instances using it
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback()
+
+	// The bucket is created full, then locked so that concurrent requests take their tokens in turn
+	query := `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, query, key, float64(limit.Burst), now); err != nil {
+		return Result{}, fmt.Errorf("failed to create rate limit bucket: %w", err)
+	}
+	var b bucket
+	if err := tx.QueryRowContext(ctx, `SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`, key).
+		Scan(&b.Tokens, &b.UpdatedAt); err != nil {
+		return Result{}, fmt.Errorf("failed to get rate limit bucket: %w", err)
+	}
+
+	b, r := take(b, limit, now)
+	if _, err := tx.ExecContext(ctx, `UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1`, key, b.Tokens, b.UpdatedAt); err != nil {
+		return Result{}, fmt.Errorf("failed to update rate limit bucket: %w", err)
+	}
+	return r, tx.Commit()
+}
+
+func (s *postgresStore) Consume(ctx context.Context, day time.Time, usages []Usage) ([]int64, int, error) {
+	// The day is sent as a date, it does not depend on the time zone of the session
+	date := day.Format(time.DateOnly)
+
+	// The usages are updated in one transaction, whose row locks make concurrent uploads of the
+	// same keys wait for it, and rolled back as a whole when one would exceed its max
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO quota_usage (key, day, used)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key, day) DO UPDATE SET used = quota_usage.used + EXCLUDED.used
+		WHERE quota_usage.used + EXCLUDED.used <= $4
+		RETURNING used
+	`
+	used := make([]int64, len(usages))
+	for i, u := range usages {
+		if u.Amount <= u.Max {
+			err := tx.QueryRowContext(ctx, query, u.Key, date, u.Amount, u.Max).Scan(&used[i])
+			if err == nil {
+				continue
+			}
+			if err != sql.ErrNoRows {
+				return nil, 0, fmt.Errorf("failed to update quota usage: %w", err)
+			}
+		}
+
+		// The amount exceeds what is left of the quota, the usages are left as they are
+		err := tx.QueryRowContext(ctx, `SELECT used FROM quota_usage WHERE key = $1 AND day = $2`, u.Key, date).Scan(&used[i])
+		if err != nil && err != sql.ErrNoRows {
+			return nil, 0, fmt.Errorf("failed to get quota usage: %w", err)
+		}
+		return used, i, nil
+	}
+	return used, -1, tx.Commit()
+}
+
+func (s *postgresStore) Refund(ctx context.Context, day time.Time, usages []Usage) error {
+	date := day.Format(time.DateOnly)
+	for _, u := range usages {
+		_, err := s.db.ExecContext(ctx, `UPDATE quota_usage SET used = GREATEST(used - $3, 0) WHERE key = $1 AND day = $2`, u.Key, date, u.Amount)
+		if err != nil {
+			return fmt.Errorf("failed to refund quota usage: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is the rate of a token bucket: it holds up to Burst tokens, refilled at Rate tokens per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Enabled reports whether the requests are limited
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result is the outcome of taking a token of a bucket
+type Result struct {
+	Allowed bool
+	// Limit is the burst of the bucket, zero when the requests are not limited
+	Limit     int
+	Remaining int
+	// RetryAfter is the time until a token is available again, when the request is not allowed
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// Window is the time an empty bucket takes to be full again
+	Window time.Duration
+}
+
+// bucket is the state of a token bucket, a zero bucket is full
+type bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// take refills b up to now and takes a token of it, it returns the bucket after and the outcome
+func take(b bucket, limit Limit, now time.Time) (bucket, Result) {
+	burst := float64(limit.Burst)
+	tokens := burst
+	if !b.UpdatedAt.IsZero() {
+		elapsed := now.Sub(b.UpdatedAt).Seconds()
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		tokens = math.Min(burst, b.Tokens+elapsed*limit.Rate)
+	}
+
+	r := Result{Limit: limit.Burst, Window: seconds(burst / limit.Rate)}
+	if tokens >= 1 {
+		tokens--
+		r.Allowed = true
+	} else {
+		r.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+	r.Remaining = int(tokens)
+	r.Reset = seconds((burst - tokens) / limit.Rate)
+	return bucket{Tokens: tokens, UpdatedAt: now}, r
+}
+
+// seconds converts a number of seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+// Quota is a daily quota of uploads
+type Quota string
+
+const (
+	// QuotaLines is the daily quota of credit transfers
+	QuotaLines Quota = "lines"
+	// QuotaBytes is the daily quota of bytes uploaded
+	QuotaBytes Quota = "bytes"
+)
+
+// QuotaResult is the outcome of counting an upload against the daily quotas
+type QuotaResult struct {
+	Allowed bool
+	// Quota is the quota the upload would exceed, when it is not allowed
+	Quota Quota
+	// Limit is the daily quota, zero when it is unlimited
+	Limit int64
+	Used  int64
+	// Requested is the amount of the upload counted against the quota
+	Requested int64
+	// Reset is the time until the quota is reset, at midnight UTC
+	Reset time.Duration
+}
+
+// Usage is an amount counted against the daily quota max of key
+type Usage struct {
+	Key    string
+	Amount int64
+	Max    int64
+}
+
+// Store keeps the token buckets and the usage of the quotas of the callers
+type Store interface {
+	// Take takes a token of the bucket of key at now, the bucket is full when it is first used
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+	// Consume adds the amounts of usages to the usage of their keys on day, all of them or none
+	// when one would exceed its max. It returns the usage of each key after, and the index of the
+	// usage that would exceed its max, -1 when the amounts were added.
+	Consume(ctx context.Context, day time.Time, usages []Usage) ([]int64, int, error)
+	// Refund subtracts the amounts of usages consumed on day from the usage of their keys
+	Refund(ctx context.Context, day time.Time, usages []Usage) error
+}
+
+// Class is a class of requests limited together
+type Class string
+
+const (
+	// ClassSubmit is the class of the requests changing state, bulk uploads first
+	ClassSubmit Class = "submit"
+	// ClassRead is the class of the requests reading state
+	ClassRead Class = "read"
+	// ClassClient is the class of all the requests of a client address, limited before
+	// their credentials are checked
+	ClassClient Class = "client"
+)
+
+// Config is the configuration of a limiter, zero limits and quotas are disabled
+type Config struct {
+	Submit Limit
+	Read   Limit
+	// Client is the limit of the requests of each client address, authenticated or not
+	Client Limit
+	// DailyLines and DailyBytes are the quotas of credit transfers and of bytes uploaded per day
+	DailyLines int64
+	DailyBytes int64
+}
+
+// Limiter applies the rate limits and the daily quotas of a configuration to the requests
+type Limiter struct {
+	store  Store
+	config Config
+	now    func() time.Time
+}
+
+// NewLimiter returns a limiter keeping its buckets and quotas in store
+func NewLimiter(store Store, config Config) *Limiter {
+	return &Limiter{
+		store:  store,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Allow takes a token of the bucket of the class of key
+func (l *Limiter) Allow(ctx context.Context, class Class, key string) (Result, error) {
+	limit := l.config.Read
+	switch class {
+	case ClassSubmit:
+		limit = l.config.Submit
+	case ClassClient:
+		limit = l.config.Client
+	}
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+	return l.store.Take(ctx, string(class)+":"+key, limit, l.now())
+}
+
+// Reservation is the usage of the daily quotas reserved for an upload
+type Reservation struct {
+	limiter *Limiter
+	day     time.Time
+	usages  []Usage
+}
+
+// Refund gives the usage of the reservation back to the quotas, once the upload was rejected.
+// A nil reservation has nothing to refund.
+func (r *Reservation) Refund(ctx context.Context) error {
+	if r == nil || len(r.usages) == 0 {
+		return nil
+	}
+	return r.limiter.store.Refund(ctx, r.day, r.usages)
+}
+
+// ReserveUpload counts the lines and bytes of an upload against the daily quotas of key in a single
+// step: both are counted, or neither when the upload exceeds what is left of one of them. The
+// reservation is nil when the upload is not allowed.
+func (l *Limiter) ReserveUpload(ctx context.Context, key string, lines, bytes int64) (*Reservation, QuotaResult, error) {
+	now := l.now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	reset := day.AddDate(0, 0, 1).Sub(now)
+
+	var quotas []Quota
+	var usages []Usage
+	for _, q := range []struct {
+		quota  Quota
+		amount int64
+		max    int64
+	}{
+		{QuotaLines, lines, l.config.DailyLines},
+		{QuotaBytes, bytes, l.config.DailyBytes},
+	} {
+		if q.max > 0 {
+			quotas = append(quotas, q.quota)
+			usages = append(usages, Usage{Key: string(q.quota) + ":" + key, Amount: q.amount, Max: q.max})
+		}
+	}
+	if len(usages) == 0 {
+		return &Reservation{limiter: l, day: day}, QuotaResult{Allowed: true}, nil
+	}
+
+	used, exceeded, err := l.store.Consume(ctx, day, usages)
+	if err != nil {
+		return nil, QuotaResult{}, err
+	}
+	if exceeded >= 0 {
+		return nil, QuotaResult{
+			Quota:     quotas[exceeded],
+			Limit:     usages[exceeded].Max,
+			Used:      used[exceeded],
+			Requested: usages[exceeded].Amount,
+			Reset:     reset,
+		}, nil
+	}
+	return &Reservation{limiter: l, day: day, usages: usages}, QuotaResult{Allowed: true, Reset: reset}, nil
+}
@@ -0,0 +1,177 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTake(t *testing.T) {
+	now := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	limit := Limit{Rate: 2, Burst: 3}
+
+	t.Run("New bucket is full", func(t *testing.T) {
+		b, r := take(bucket{}, limit, now)
+		assert.True(t, r.Allowed)
+		assert.Equal(t, 3, r.Limit)
+		assert.Equal(t, 2, r.Remaining)
+		assert.Equal(t, 500*time.Millisecond, r.Reset)
+		assert.Equal(t, 2.0, b.Tokens)
+	})
+
+	t.Run("Empty bucket", func(t *testing.T) {
+		_, r := take(bucket{Tokens: 0.5, UpdatedAt: now}, limit, now)
+		assert.False(t, r.Allowed)
+		assert.Equal(t, 0, r.Remaining)
+		assert.Equal(t, 250*time.Millisecond, r.RetryAfter)
+		assert.Equal(t, 1250*time.Millisecond, r.Reset)
+	})
+
+	t.Run("Refilled bucket", func(t *testing.T) {
+		_, r := take(bucket{Tokens: 0, UpdatedAt: now}, limit, now.Add(time.Second))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, 1, r.Remaining)
+	})
+
+	t.Run("Refill capped at the burst", func(t *testing.T) {
+		b, r := take(bucket{Tokens: 0, UpdatedAt: now}, limit, now.Add(time.Hour))
+		assert.True(t, r.Allowed)
+		assert.Equal(t, 2.0, b.Tokens)
+	})
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	l := NewLimiter(NewMemoryStore(), Config{Submit: Limit{Rate: 1, Burst: 2}})
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		r, err := l.Allow(ctx, ClassSubmit, "org:ACME Corp")
+		require.NoError(t, err)
+		assert.True(t, r.Allowed)
+	}
+	r, err := l.Allow(ctx, ClassSubmit, "org:ACME Corp")
+	require.NoError(t, err)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, time.Second, r.RetryAfter)
+
+	// Each caller has its own bucket
+	r, err = l.Allow(ctx, ClassSubmit, "org:Other Corp")
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+
+	// Reads are not limited
+	r, err = l.Allow(ctx, ClassRead, "org:ACME Corp")
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Zero(t, r.Limit)
+
+	now = now.Add(time.Second)
+	r, err = l.Allow(ctx, ClassSubmit, "org:ACME Corp")
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+}
+
+func TestLimiter_Quotas(t *testing.T) {
+	now := time.Date(2024, 3, 4, 22, 0, 0, 0, time.UTC)
+	l := NewLimiter(NewMemoryStore(), Config{DailyLines: 100})
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	r, q, err := l.ReserveUpload(ctx, "org:ACME Corp", 60, 1<<30)
+	require.NoError(t, err)
+	assert.True(t, q.Allowed)
+	assert.NotNil(t, r)
+	assert.Equal(t, 2*time.Hour, q.Reset)
+
+	// A batch exceeding what is left is rejected as a whole, bytes are not limited
+	r, q, err = l.ReserveUpload(ctx, "org:ACME Corp", 50, 1<<30)
+	require.NoError(t, err)
+	assert.False(t, q.Allowed)
+	assert.Nil(t, r)
+	assert.Equal(t, QuotaLines, q.Quota)
+	assert.Equal(t, int64(100), q.Limit)
+	assert.Equal(t, int64(60), q.Used)
+	assert.Equal(t, int64(50), q.Requested)
+
+	_, q, err = l.ReserveUpload(ctx, "org:ACME Corp", 40, 0)
+	require.NoError(t, err)
+	assert.True(t, q.Allowed)
+
+	// The quota is reset at midnight UTC
+	now = now.Add(3 * time.Hour)
+	_, q, err = l.ReserveUpload(ctx, "org:ACME Corp", 100, 0)
+	require.NoError(t, err)
+	assert.True(t, q.Allowed)
+}
+
+func TestLimiter_ReserveUpload(t *testing.T) {
+	now := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	l := NewLimiter(NewMemoryStore(), Config{DailyLines: 10, DailyBytes: 1000})
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	t.Run("Lines and bytes are reserved together", func(t *testing.T) {
+		_, q, err := l.ReserveUpload(ctx, "org:ACME Corp", 5, 1001)
+		require.NoError(t, err)
+		assert.False(t, q.Allowed)
+		assert.Equal(t, QuotaBytes, q.Quota)
+
+		// The lines of the rejected upload were not counted
+		_, q, err = l.ReserveUpload(ctx, "org:ACME Corp", 10, 0)
+		require.NoError(t, err)
+		assert.True(t, q.Allowed)
+	})
+
+	t.Run("Refunded reservation", func(t *testing.T) {
+		r, q, err := l.ReserveUpload(ctx, "org:Other Corp", 10, 1000)
+		require.NoError(t, err)
+		require.True(t, q.Allowed)
+		require.NoError(t, r.Refund(ctx))
+
+		_, q, err = l.ReserveUpload(ctx, "org:Other Corp", 10, 1000)
+		require.NoError(t, err)
+		assert.True(t, q.Allowed)
+	})
+
+	t.Run("Nil reservation", func(t *testing.T) {
+		var r *Reservation
+		assert.NoError(t, r.Refund(ctx))
+	})
+}
+
+func TestLimiter_AllowClient(t *testing.T) {
+	l := NewLimiter(NewMemoryStore(), Config{Client: Limit{Rate: 1, Burst: 1}})
+	ctx := context.Background()
+
+	r, err := l.Allow(ctx, ClassClient, "ip:192.0.2.1")
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+	r, err = l.Allow(ctx, ClassClient, "ip:192.0.2.1")
+	require.NoError(t, err)
+	assert.False(t, r.Allowed)
+
+	// Submissions are not limited
+	r, err = l.Allow(ctx, ClassSubmit, "ip:192.0.2.1")
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	now := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	s := NewMemoryStore().(*memoryStore)
+	limit := Limit{Rate: 1, Burst: 1}
+
+	_, err := s.Take(context.Background(), "a", limit, now)
+	require.NoError(t, err)
+	for i := 1; i < sweepInterval; i++ {
+		_, err := s.Take(context.Background(), "b", limit, now.Add(time.Minute))
+		require.NoError(t, err)
+	}
+	assert.NotContains(t, s.buckets, "a")
+	assert.Contains(t, s.buckets, "b")
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"moneytransfer/internal/apikey"
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/authz"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=api_key_service.go -destination=../../mock/api_key_service_mock.go -package=mock -mock_names=APIKeyService=APIKeyServiceMock
+type APIKeyService interface {
+	// Create issues a key for the organization. The plaintext of the key is only
+	// returned here, it is not stored and cannot be recovered.
+	Create(ctx context.Context, organizationName, name string) (*apikey.Key, string, error)
+	// Rotate issues a key replacing the key id, which stays valid for the grace period
+	Rotate(ctx context.Context, id int64, grace time.Duration) (*apikey.Key, string, error)
+	// Revoke invalidates the key at once
+	Revoke(ctx context.Context, id int64) error
+	List(ctx context.Context, organizationName string) ([]apikey.Key, error)
+	// Authenticate returns the principal of a plaintext key, or ErrInvalidAPIKey
+	// if the key is unknown, revoked or expired
+	Authenticate(ctx context.Context, plaintext string) (auth.Principal, error)
+}
+
+type apiKeyService struct {
+	repo      apikey.Repository
+	auditRepo audit.Repository
+	logger    *slog.Logger
+	policy    authz.Authorizer
+}
+
+// NewAPIKeyService is a function that creates a new api key service
+// auditRepo records the issued and revoked keys, it may be nil to not audit them
+// policy authorizes the management of the keys, it may be nil to allow it to every caller
+func NewAPIKeyService(repo apikey.Repository, auditRepo audit.Repository, logger *slog.Logger, policy authz.Authorizer) *apiKeyService {
+	return &apiKeyService{
+		repo:      repo,
+		auditRepo: auditRepo,
+		logger:    logger,
+		policy:    policy,
+	}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, organizationName, name string) (*apikey.Key, string, error) {
+	e := newAdminAuditEvent(ctx, audit.ActionAPIKeyCreate, organizationName, "api_key")
+	if err := authorize(ctx, s.policy, authz.ActionManage, authz.Resource{OrganizationName: organizationName}); err != nil {
+		return nil, "", recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+
+	k, plaintext, err := apikey.Generate(organizationName, name)
+	if err != nil {
+		return nil, "", recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+	if err := k.Validate(); err != nil {
+		return nil, "", recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+
+	if err := s.repo.Create(ctx, k); err != nil {
+		return nil, "", recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+
+	s.logger.Info("API key created", "api_key_id", k.ID, "prefix", k.Prefix, "organization", organizationName)
+	e.ResourceID = strconv.FormatInt(k.ID, 10)
+	recordAudit(ctx, s.auditRepo, s.logger, e, apiKeySnapshot(k), nil)
+	return k, plaintext, nil
+}
+
+func (s *apiKeyService) Rotate(ctx context.Context, id int64, grace time.Duration) (*apikey.Key, string, error) {
+	e := newAdminAuditEvent(ctx, audit.ActionAPIKeyRotate, "", "api_key")
+	e.ResourceID = strconv.FormatInt(id, 10)
+
+	if grace < 0 {
+		return nil, "", recordAudit(ctx, s.auditRepo, s.logger, e, nil, fmt.Errorf("invalid grace period %v", grace))
+	}
+	old, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, "", recordAudit(ctx, s.auditRepo, s.logger, e, nil, apiKeyError(err, id))
+	}
+	e.OrganizationName = old.OrganizationName
+	if err := authorize(ctx, s.policy, authz.ActionManage, authz.Resource{OrganizationName: old.OrganizationName}); err != nil {
+		return nil, "", recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+
+	next, plaintext, err := apikey.Generate(old.OrganizationName, old.Name)
+	if err != nil {
+		return nil, "", recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+	if err := s.repo.Rotate(ctx, id, next, time.Now().Add(grace)); err != nil {
+		return nil, "", recordAudit(ctx, s.auditRepo, s.logger, e, nil, apiKeyError(err, id))
+	}
+
+	s.logger.Info("API key rotated", "api_key_id", next.ID, "rotated_from", id, "organization", next.OrganizationName, "grace", grace)
+	recordAudit(ctx, s.auditRepo, s.logger, e, apiKeySnapshot(next), nil)
+	return next, plaintext, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, id int64) error {
+	e := newAdminAuditEvent(ctx, audit.ActionAPIKeyRevoke, "", "api_key")
+	e.ResourceID = strconv.FormatInt(id, 10)
+
+	k, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return recordAudit(ctx, s.auditRepo, s.logger, e, nil, apiKeyError(err, id))
+	}
+	e.OrganizationName = k.OrganizationName
+	if err := authorize(ctx, s.policy, authz.ActionManage, authz.Resource{OrganizationName: k.OrganizationName}); err != nil {
+		return recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+
+	if err := s.repo.Revoke(ctx, id); err != nil {
+		return recordAudit(ctx, s.auditRepo, s.logger, e, nil, apiKeyError(err, id))
+	}
+
+	s.logger.Info("API key revoked", "api_key_id", id)
+	recordAudit(ctx, s.auditRepo, s.logger, e, nil, nil)
+	return nil
+}
+
+func (s *apiKeyService) List(ctx context.Context, organizationName string) ([]apikey.Key, error) {
+	if err := authorize(ctx, s.policy, authz.ActionManage, authz.Resource{OrganizationName: organizationName}); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, organizationName)
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, plaintext string) (auth.Principal, error) {
+	prefix, ok := apikey.ParsePrefix(plaintext)
+	if !ok {
+		return auth.Principal{}, ErrInvalidAPIKey
+	}
+	k, err := s.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, apikey.ErrNotFound) {
+			return auth.Principal{}, ErrInvalidAPIKey
+		}
+		return auth.Principal{}, err
+	}
+	if !k.Matches(plaintext) || !k.Active(time.Now()) {
+		return auth.Principal{}, ErrInvalidAPIKey
+	}
+
+	return auth.Principal{
+		OrganizationName: k.OrganizationName,
+		Subject:          "api_key:" + strconv.FormatInt(k.ID, 10),
+	}, nil
+}
+
+// apiKeySnapshot is the audited state of a key, without its hash
+func apiKeySnapshot(k *apikey.Key) map[string]any {
+	return map[string]any{"id": k.ID, "name": k.Name, "prefix": k.Prefix}
+}
+
+// apiKeyError maps a repository error about the key with the given id onto the service errors
+func apiKeyError(err error, id int64) error {
+	switch {
+	case errors.Is(err, apikey.ErrNotFound):
+		return fmt.Errorf("%w: %d", ErrAPIKeyNotFound, id)
+	case errors.Is(err, apikey.ErrInactive):
+		return fmt.Errorf("%w: %d", ErrAPIKeyInactive, id)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/apikey"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/service"
+	"moneytransfer/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAPIKeyService_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewAPIKeyRepositoryMock(ctrl)
+	svc := service.NewAPIKeyService(mockRepo, nil, slog.Default(), nil)
+
+	mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, k *apikey.Key) error {
+		k.ID = 1
+		return nil
+	})
+
+	k, plaintext, err := svc.Create(context.Background(), "Test Org", "erp")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), k.ID)
+	assert.Equal(t, "Test Org", k.OrganizationName)
+	assert.True(t, k.Matches(plaintext))
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewAPIKeyRepositoryMock(ctrl)
+	svc := service.NewAPIKeyService(mockRepo, nil, slog.Default(), nil)
+
+	key, plaintext, err := apikey.Generate("Test Org", "erp")
+	assert.NoError(t, err)
+	key.ID = 7
+
+	t.Run("Active key authenticates its organization", func(t *testing.T) {
+		mockRepo.EXPECT().GetByPrefix(gomock.Any(), key.Prefix).Return(key, nil)
+
+		p, err := svc.Authenticate(context.Background(), plaintext)
+		assert.NoError(t, err)
+		assert.Equal(t, "Test Org", p.OrganizationName)
+		assert.Equal(t, "api_key:7", p.Subject)
+	})
+
+	t.Run("Wrong secret is rejected", func(t *testing.T) {
+		mockRepo.EXPECT().GetByPrefix(gomock.Any(), key.Prefix).Return(key, nil)
+
+		forged := plaintext[:len(plaintext)-4] + "0000"
+		_, err := svc.Authenticate(context.Background(), forged)
+		assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+	})
+
+	t.Run("Revoked key is rejected", func(t *testing.T) {
+		revoked := *key
+		revoked.RevokedAt = time.Now().Add(-time.Minute)
+		mockRepo.EXPECT().GetByPrefix(gomock.Any(), key.Prefix).Return(&revoked, nil)
+
+		_, err := svc.Authenticate(context.Background(), plaintext)
+		assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+	})
+
+	t.Run("Unknown key is rejected", func(t *testing.T) {
+		mockRepo.EXPECT().GetByPrefix(gomock.Any(), key.Prefix).Return(nil, apikey.ErrNotFound)
+
+		_, err := svc.Authenticate(context.Background(), plaintext)
+		assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+	})
+
+	t.Run("Malformed key is rejected without lookup", func(t *testing.T) {
+		_, err := svc.Authenticate(context.Background(), "secret")
+		assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+	})
+}
+
+func TestAPIKeyService_Rotate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewAPIKeyRepositoryMock(ctrl)
+	svc := service.NewAPIKeyService(mockRepo, nil, slog.Default(), nil)
+
+	old := &apikey.Key{ID: 7, OrganizationName: "Test Org", Name: "erp", Prefix: "0123456789ab", Hash: "hash"}
+
+	t.Run("Old key expires after the grace period", func(t *testing.T) {
+		before := time.Now()
+		mockRepo.EXPECT().Get(gomock.Any(), int64(7)).Return(old, nil)
+		mockRepo.EXPECT().Rotate(gomock.Any(), int64(7), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ int64, next *apikey.Key, expiresAt time.Time) error {
+				assert.WithinRange(t, expiresAt, before.Add(time.Hour), time.Now().Add(time.Hour))
+				next.ID = 8
+				next.RotatedFrom = 7
+				return nil
+			})
+
+		k, plaintext, err := svc.Rotate(context.Background(), 7, time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(8), k.ID)
+		assert.Equal(t, "Test Org", k.OrganizationName)
+		assert.Equal(t, "erp", k.Name)
+		assert.True(t, k.Matches(plaintext))
+	})
+
+	t.Run("Revoked key cannot be rotated", func(t *testing.T) {
+		mockRepo.EXPECT().Get(gomock.Any(), int64(7)).Return(old, nil)
+		mockRepo.EXPECT().Rotate(gomock.Any(), int64(7), gomock.Any(), gomock.Any()).Return(apikey.ErrInactive)
+
+		_, _, err := svc.Rotate(context.Background(), 7, time.Hour)
+		assert.ErrorIs(t, err, service.ErrAPIKeyInactive)
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		mockRepo.EXPECT().Get(gomock.Any(), int64(9)).Return(nil, apikey.ErrNotFound)
+
+		_, _, err := svc.Rotate(context.Background(), 9, time.Hour)
+		assert.ErrorIs(t, err, service.ErrAPIKeyNotFound)
+	})
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewAPIKeyRepositoryMock(ctrl)
+	svc := service.NewAPIKeyService(mockRepo, nil, slog.Default(), nil)
+
+	t.Run("Key is revoked", func(t *testing.T) {
+		mockRepo.EXPECT().Get(gomock.Any(), int64(7)).Return(&apikey.Key{ID: 7, OrganizationName: "Test Org"}, nil)
+		mockRepo.EXPECT().Revoke(gomock.Any(), int64(7)).Return(nil)
+
+		assert.NoError(t, svc.Revoke(context.Background(), 7))
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		mockRepo.EXPECT().Get(gomock.Any(), int64(9)).Return(nil, apikey.ErrNotFound)
+
+		err := svc.Revoke(context.Background(), 9)
+		assert.ErrorIs(t, err, service.ErrAPIKeyNotFound)
+		assert.Equal(t, service.CodeAPIKeyNotFound, service.ErrorCode(err))
+	})
+}
+
+func TestAPIKeyService_Authorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewAPIKeyRepositoryMock(ctrl)
+	mockPolicy := mock.NewAuthorizerMock(ctrl)
+	svc := service.NewAPIKeyService(mockRepo, nil, slog.Default(), mockPolicy)
+	denied := fmt.Errorf("%w: api_key:7 may not manage the organization", authz.ErrDenied)
+
+	t.Run("Key of another organization is not revoked", func(t *testing.T) {
+		mockRepo.EXPECT().Get(gomock.Any(), int64(9)).Return(&apikey.Key{ID: 9, OrganizationName: "Other Org"}, nil)
+		mockPolicy.EXPECT().Authorize(gomock.Any(), authz.ActionManage, authz.Resource{OrganizationName: "Other Org"}).Return(denied)
+
+		err := svc.Revoke(context.Background(), 9)
+		assert.ErrorIs(t, err, service.ErrPermissionDenied)
+	})
+
+	t.Run("Keys of another organization are not listed", func(t *testing.T) {
+		mockPolicy.EXPECT().Authorize(gomock.Any(), authz.ActionManage, authz.Resource{OrganizationName: "Other Org"}).Return(denied)
+
+		_, err := svc.List(context.Background(), "Other Org")
+		assert.ErrorIs(t, err, service.ErrPermissionDenied)
+	})
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/authz"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=audit_service.go -destination=../../mock/audit_service_mock.go -package=mock -mock_names=AuditService=AuditServiceMock
+type AuditService interface {
+	// List returns the audit events of the filter, newest first
+	List(ctx context.Context, filter audit.Filter) ([]audit.Event, error)
+}
+
+type auditService struct {
+	repo   audit.Repository
+	logger *slog.Logger
+	policy authz.Authorizer
+}
+
+// NewAuditService is a function that creates a new audit service
+// policy authorizes the reads of the audit log, it may be nil to allow them to every caller
+func NewAuditService(repo audit.Repository, logger *slog.Logger, policy authz.Authorizer) *auditService {
+	return &auditService{
+		repo:   repo,
+		logger: logger,
+		policy: policy,
+	}
+}
+
+func (s *auditService) List(ctx context.Context, filter audit.Filter) ([]audit.Event, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAuditFilter, err)
+	}
+	if err := authorize(ctx, s.policy, authz.ActionManage, authz.Resource{OrganizationName: filter.OrganizationName}); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// auditOutcome returns the outcome of an action that failed with err
+func auditOutcome(err error) audit.Outcome {
+	if errors.Is(err, ErrPermissionDenied) || errors.Is(err, ErrAccountNotOwned) {
+		return audit.OutcomeDenied
+	}
+	return audit.OutcomeFailure
+}
+
+// newAdminAuditEvent returns the audit event of an administrative action on a resource of the organization
+func newAdminAuditEvent(ctx context.Context, action audit.Action, organizationName, resourceType string) *audit.Event {
+	e := audit.NewEvent(ctx, action)
+	e.OrganizationName = organizationName
+	e.ResourceType = resourceType
+	return e
+}
+
+// recordAudit appends e on its own, with the state after the action or with the outcome of err
+// when it is not nil, and returns err. The administrative actions are single statements that
+// are recorded once they are done, an action is not undone when its event cannot be appended.
+// Nothing is recorded without a repository.
+func recordAudit(ctx context.Context, repo audit.Repository, logger *slog.Logger, e *audit.Event, after any, err error) error {
+	if repo == nil {
+		return err
+	}
+	if err != nil {
+		e.Outcome = auditOutcome(err)
+		e.ErrorCode = ErrorCode(err)
+	} else if snapErr := e.SetSnapshots(nil, after); snapErr != nil {
+		logger.Error("Failed to encode audit snapshot", "error", snapErr, "action", e.Action)
+	}
+	if appendErr := repo.Append(context.WithoutCancel(ctx), nil, e); appendErr != nil {
+		logger.Error("Failed to append audit event", "error", appendErr, "action", e.Action, "outcome", e.Outcome)
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package service_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/service"
+	"moneytransfer/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuditService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewAuditRepositoryMock(ctrl)
+	svc := service.NewAuditService(mockRepo, slog.Default(), nil)
+
+	t.Run("Events of an actor", func(t *testing.T) {
+		filter := audit.Filter{Actor: "api_key:7", OrganizationName: "Test Org", Limit: audit.DefaultLimit}
+		mockRepo.EXPECT().List(gomock.Any(), filter).Return([]audit.Event{{ID: 2, Actor: "api_key:7"}}, nil)
+
+		events, err := svc.List(context.Background(), filter)
+		assert.NoError(t, err)
+		assert.Len(t, events, 1)
+	})
+
+	t.Run("Limit too large", func(t *testing.T) {
+		_, err := svc.List(context.Background(), audit.Filter{Limit: audit.MaxLimit + 1})
+		assert.ErrorIs(t, err, service.ErrInvalidAuditFilter)
+		assert.Equal(t, service.CodeInvalidAuditFilter, service.ErrorCode(err))
+	})
+
+	t.Run("Empty time range", func(t *testing.T) {
+		now := time.Now()
+		_, err := svc.List(context.Background(), audit.Filter{From: now, To: now, Limit: audit.DefaultLimit})
+		assert.ErrorIs(t, err, service.ErrInvalidAuditFilter)
+	})
+}
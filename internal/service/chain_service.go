@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/transfer"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=chain_service.go -destination=../../mock/chain_service_mock.go -package=mock -mock_names=ChainService=ChainServiceMock
+type ChainService interface {
+	// Verify recomputes the hash chain of the account and reports its first broken link
+	Verify(ctx context.Context, iban string) (*ChainReport, error)
+	// VerifyAll verifies the hash chains of all the accounts with transfers or links, by account id
+	VerifyAll(ctx context.Context) ([]ChainReport, error)
+}
+
+// ChainBreak is the reason a link of a hash chain is broken
+type ChainBreak string
+
+const (
+	// ChainBreakSequenceGap is reported when a link is missing before the link
+	ChainBreakSequenceGap ChainBreak = "sequence_gap"
+	// ChainBreakPreviousHash is reported when the link does not follow the hash of the previous link
+	ChainBreakPreviousHash ChainBreak = "previous_hash_mismatch"
+	// ChainBreakTransferMissing is reported when the transfer of the link was deleted
+	ChainBreakTransferMissing ChainBreak = "transfer_missing"
+	// ChainBreakTransferAccount is reported when the transfer of the link was moved to another account
+	ChainBreakTransferAccount ChainBreak = "transfer_account_mismatch"
+	// ChainBreakHash is reported when the link or its transfer was edited
+	ChainBreakHash ChainBreak = "hash_mismatch"
+	// ChainBreakBalance is reported when the balance of the link does not follow from the previous link
+	ChainBreakBalance ChainBreak = "balance_mismatch"
+	// ChainBreakAccountBalance is reported when the balance of the account differs from the last link
+	ChainBreakAccountBalance ChainBreak = "account_balance_mismatch"
+	// ChainBreakUnchained is reported when a transfer of the account was created without a link
+	ChainBreakUnchained ChainBreak = "unchained_transfer"
+)
+
+// BrokenLink is the first link of a hash chain that failed its verification
+type BrokenLink struct {
+	// Seq is the sequence of the link, zero for a transfer without a link
+	Seq        int64
+	TransferID int64
+	Reason     ChainBreak
+	Detail     string
+}
+
+// ChainReport is the result of the verification of the hash chain of an account
+type ChainReport struct {
+	IBAN string
+	// Links counts the links verified, up to the broken one
+	Links int
+	// Head is the hash of the last link verified, which can be recorded elsewhere to detect
+	// a chain that was recomputed as a whole
+	Head string
+	// Broken is the first broken link, nil when the chain is intact
+	Broken *BrokenLink
+}
+
+// errChainBroken stops the iteration of the links at the first broken one
+var errChainBroken = errors.New("chain broken")
+
+type chainService struct {
+	db           *sql.DB
+	accountRepo  account.Repository
+	transferRepo transfer.Repository
+	logger       *slog.Logger
+	policy       authz.Authorizer
+}
+
+// NewChainService is a function that creates a new chain service
+// policy authorizes the verifications, it may be nil to allow them to every caller
+func NewChainService(db *sql.DB, accountRepo account.Repository, transferRepo transfer.Repository, logger *slog.Logger, policy authz.Authorizer) *chainService {
+	return &chainService{
+		db:           db,
+		accountRepo:  accountRepo,
+		transferRepo: transferRepo,
+		logger:       logger,
+		policy:       policy,
+	}
+}
+
+// Verify reads the chain in a single repeatable read transaction, so that the account and its
+// links are verified from a consistent snapshot while transfers are being executed
+func (s *chainService) Verify(ctx context.Context, iban string) (*ChainReport, error) {
+	if err := authorize(ctx, s.policy, authz.ActionManage, authz.Resource{AccountIBAN: iban}); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	acc, err := s.accountRepo.GetByIBAN(iban, tx)
+	if err != nil {
+		if errors.Is(err, account.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, iban)
+		}
+		return nil, err
+	}
+
+	report := &ChainReport{IBAN: acc.IBAN}
+	var prev *transfer.Link
+	err = s.transferRepo.ForEachLink(ctx, tx, acc.ID, func(l transfer.Link, t *transfer.Transfer) error {
+		if broken := verifyLink(prev, &l, t); broken != nil {
+			report.Broken = broken
+			return errChainBroken
+		}
+		report.Links++
+		report.Head = l.Hash
+		prev = &l
+		return nil
+	})
+	if err != nil && !errors.Is(err, errChainBroken) {
+		return nil, err
+	}
+
+	if report.Broken == nil && prev != nil && prev.BalanceCents != acc.BalanceCents {
+		report.Broken = &BrokenLink{
+			Seq:        prev.Seq,
+			TransferID: prev.TransferID,
+			Reason:     ChainBreakAccountBalance,
+			Detail:     fmt.Sprintf("account balance is %d cents, the last link left %d", acc.BalanceCents, prev.BalanceCents),
+		}
+	}
+	// Transfers are looked for even without links, the chain of the account may have been deleted as a whole
+	if report.Broken == nil {
+		id, err := s.transferRepo.FirstUnchained(ctx, tx, acc.ID)
+		if err != nil {
+			return nil, err
+		}
+		if id != 0 {
+			report.Broken = &BrokenLink{TransferID: id, Reason: ChainBreakUnchained, Detail: fmt.Sprintf("transfer %d has no link", id)}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	if report.Broken != nil {
+		s.logger.Warn("Hash chain broken", "iban", report.IBAN, "seq", report.Broken.Seq, "transfer_id", report.Broken.TransferID, "reason", report.Broken.Reason)
+	}
+	return report, nil
+}
+
+func (s *chainService) VerifyAll(ctx context.Context) ([]ChainReport, error) {
+	ids, err := s.transferRepo.ChainAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]ChainReport, 0, len(ids))
+	for _, id := range ids {
+		acc, err := s.accountRepo.Get(id, nil)
+		if err != nil {
+			return nil, fmt.Errorf("account %d: %w", id, err)
+		}
+		report, err := s.Verify(ctx, acc.IBAN)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+// verifyLink verifies the link l of the transfer t following prev, nil for the first link of the chain
+func verifyLink(prev, l *transfer.Link, t *transfer.Transfer) *BrokenLink {
+	broken := func(reason ChainBreak, format string, args ...any) *BrokenLink {
+		return &BrokenLink{Seq: l.Seq, TransferID: l.TransferID, Reason: reason, Detail: fmt.Sprintf(format, args...)}
+	}
+
+	wantSeq, wantPrevHash := int64(1), transfer.GenesisHash
+	if prev != nil {
+		wantSeq, wantPrevHash = prev.Seq+1, prev.Hash
+	}
+	if l.Seq != wantSeq {
+		return broken(ChainBreakSequenceGap, "expected link %d, found link %d", wantSeq, l.Seq)
+	}
+	if l.PrevHash != wantPrevHash {
+		return broken(ChainBreakPreviousHash, "link does not follow the hash of link %d", wantSeq-1)
+	}
+	if t == nil {
+		return broken(ChainBreakTransferMissing, "transfer %d no longer exists", l.TransferID)
+	}
+	if t.BankAccountID != l.BankAccountID {
+		return broken(ChainBreakTransferAccount, "transfer %d is of account %d", t.ID, t.BankAccountID)
+	}
+	if l.ComputeHash(t) != l.Hash {
+		return broken(ChainBreakHash, "link or transfer %d was modified", l.TransferID)
+	}
+	if prev != nil {
+		want := prev.BalanceCents - l.AmountCents
+		if l.Kind == transfer.LinkCredit {
+			want = prev.BalanceCents + l.AmountCents
+		}
+		if l.BalanceCents != want {
+			return broken(ChainBreakBalance, "expected a balance of %d cents, the link records %d", want, l.BalanceCents)
+		}
+	}
+	return nil
+}
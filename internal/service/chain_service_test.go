@@ -0,0 +1,243 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/transfer"
+	"moneytransfer/mock"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// chainedTransfer is a link of a test chain with the transfer it records
+type chainedTransfer struct {
+	link     transfer.Link
+	transfer *transfer.Transfer
+}
+
+// newTestChain returns the chain of account 1 debited of two transfers from 5000 cents, the first one returned
+func newTestChain() []chainedTransfer {
+	created := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	first, second := newTestTransfer(1000), newTestTransfer(2000)
+	first.ID, first.BankAccountID, first.CreatedAt = 1, 1, created
+	second.ID, second.BankAccountID, second.CreatedAt = 2, 1, created
+	returned := first
+	returned.Status, returned.ReturnReason, returned.ReturnedAt = transfer.StatusReturned, "AC04", created.Add(time.Hour)
+
+	chain := []chainedTransfer{
+		{link: transfer.Link{Kind: transfer.LinkDebit, TransferID: 1, AmountCents: 1000, BalanceCents: 4000}, transfer: &returned},
+		{link: transfer.Link{Kind: transfer.LinkDebit, TransferID: 2, AmountCents: 2000, BalanceCents: 2000}, transfer: &second},
+		{link: transfer.Link{Kind: transfer.LinkCredit, TransferID: 1, AmountCents: 1000, BalanceCents: 3000}, transfer: &returned},
+	}
+	prevHash := transfer.GenesisHash
+	for i := range chain {
+		l := &chain[i].link
+		l.BankAccountID, l.Seq, l.PrevHash = 1, int64(i+1), prevHash
+		// Debits hash the transfer as it was created, before its return
+		t := chain[i].transfer
+		if l.Kind == transfer.LinkDebit {
+			t = &first
+			if l.TransferID == 2 {
+				t = &second
+			}
+		}
+		l.Hash = l.ComputeHash(t)
+		prevHash = l.Hash
+	}
+	return chain
+}
+
+func TestChainService_Verify(t *testing.T) {
+	const iban = "FR1420041010050500013M02606"
+
+	// setup expects the verification of the chain of the account with its balance
+	setup := func(t *testing.T, balanceCents int64, chain []chainedTransfer, unchained int64) service.ChainService {
+		ctrl := gomock.NewController(t)
+		mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+		mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+		mockDB, sqlMock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { mockDB.Close() })
+
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(iban, gomock.Any()).Return(&account.BankAccount{ID: 1, IBAN: iban, BalanceCents: balanceCents}, nil)
+		mockTransferRepo.EXPECT().ForEachLink(gomock.Any(), gomock.Any(), int64(1), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *sql.Tx, _ int64, fn func(transfer.Link, *transfer.Transfer) error) error {
+				for _, c := range chain {
+					if err := fn(c.link, c.transfer); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		mockTransferRepo.EXPECT().FirstUnchained(gomock.Any(), gomock.Any(), int64(1)).Return(unchained, nil).AnyTimes()
+		sqlMock.ExpectCommit()
+
+		return service.NewChainService(mockDB, mockAccountRepo, mockTransferRepo, slog.Default(), nil)
+	}
+
+	t.Run("Intact chain", func(t *testing.T) {
+		chain := newTestChain()
+		report, err := setup(t, 3000, chain, 0).Verify(context.Background(), iban)
+		require.NoError(t, err)
+		assert.Nil(t, report.Broken)
+		assert.Equal(t, 3, report.Links)
+		assert.Equal(t, chain[2].link.Hash, report.Head)
+	})
+
+	tests := []struct {
+		name         string
+		balanceCents int64
+		tamper       func(chain []chainedTransfer) []chainedTransfer
+		unchained    int64
+		wantSeq      int64
+		wantReason   service.ChainBreak
+	}{
+		{
+			name:         "Edited transfer",
+			balanceCents: 3000,
+			tamper: func(chain []chainedTransfer) []chainedTransfer {
+				edited := *chain[1].transfer
+				edited.CounterpartyIBAN = "GB29NWBK60161331926819"
+				chain[1].transfer = &edited
+				return chain
+			},
+			wantSeq:    2,
+			wantReason: service.ChainBreakHash,
+		},
+		{
+			name:         "Deleted link",
+			balanceCents: 3000,
+			tamper: func(chain []chainedTransfer) []chainedTransfer {
+				return append(chain[:1], chain[2])
+			},
+			wantSeq:    3,
+			wantReason: service.ChainBreakSequenceGap,
+		},
+		{
+			name:         "Deleted transfer",
+			balanceCents: 3000,
+			tamper: func(chain []chainedTransfer) []chainedTransfer {
+				chain[1].transfer = nil
+				return chain
+			},
+			wantSeq:    2,
+			wantReason: service.ChainBreakTransferMissing,
+		},
+		{
+			name:         "Edited link balance",
+			balanceCents: 3000,
+			tamper: func(chain []chainedTransfer) []chainedTransfer {
+				chain[2].link.BalanceCents = 9000
+				return chain
+			},
+			wantSeq:    3,
+			wantReason: service.ChainBreakHash,
+		},
+		{
+			name:         "Edited account balance",
+			balanceCents: 900000,
+			wantSeq:      3,
+			wantReason:   service.ChainBreakAccountBalance,
+		},
+		{
+			name:         "Deleted chain",
+			balanceCents: 3000,
+			tamper: func(chain []chainedTransfer) []chainedTransfer {
+				return nil
+			},
+			unchained:  1,
+			wantReason: service.ChainBreakUnchained,
+		},
+		{
+			name:         "Transfer inserted without a link",
+			balanceCents: 3000,
+			unchained:    7,
+			wantReason:   service.ChainBreakUnchained,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newTestChain()
+			if tt.tamper != nil {
+				chain = tt.tamper(chain)
+			}
+			report, err := setup(t, tt.balanceCents, chain, tt.unchained).Verify(context.Background(), iban)
+			require.NoError(t, err)
+			require.NotNil(t, report.Broken)
+			assert.Equal(t, tt.wantSeq, report.Broken.Seq)
+			assert.Equal(t, tt.wantReason, report.Broken.Reason)
+		})
+	}
+
+	t.Run("Denied", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockPolicy := mock.NewAuthorizerMock(ctrl)
+		mockDB, sqlMock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer mockDB.Close()
+
+		mockPolicy.EXPECT().Authorize(gomock.Any(), authz.ActionManage, authz.Resource{AccountIBAN: iban}).
+			Return(fmt.Errorf("%w: api_key:7 may not manage account %s", authz.ErrDenied, iban))
+
+		svc := service.NewChainService(mockDB, mock.NewAccountRepositoryMock(ctrl), mock.NewTransferRepositoryMock(ctrl), slog.Default(), mockPolicy)
+		_, err = svc.Verify(context.Background(), iban)
+		assert.ErrorIs(t, err, service.ErrPermissionDenied)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+		mockDB, sqlMock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer mockDB.Close()
+
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(iban, gomock.Any()).Return(nil, account.ErrNotFound)
+		sqlMock.ExpectRollback()
+
+		svc := service.NewChainService(mockDB, mockAccountRepo, mock.NewTransferRepositoryMock(ctrl), slog.Default(), nil)
+		_, err = svc.Verify(context.Background(), iban)
+		assert.ErrorIs(t, err, service.ErrAccountNotFound)
+	})
+}
+
+func TestChainService_VerifyAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+	mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+	mockDB, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	// Account 2 has transfers but no links left
+	const iban = "DE89370400440532013000"
+	mockTransferRepo.EXPECT().ChainAccounts(gomock.Any()).Return([]int64{2}, nil)
+	mockAccountRepo.EXPECT().Get(int64(2), gomock.Any()).Return(&account.BankAccount{ID: 2, IBAN: iban}, nil)
+	sqlMock.ExpectBegin()
+	mockAccountRepo.EXPECT().GetByIBAN(iban, gomock.Any()).Return(&account.BankAccount{ID: 2, IBAN: iban, BalanceCents: 3000}, nil)
+	mockTransferRepo.EXPECT().ForEachLink(gomock.Any(), gomock.Any(), int64(2), gomock.Any()).Return(nil)
+	mockTransferRepo.EXPECT().FirstUnchained(gomock.Any(), gomock.Any(), int64(2)).Return(int64(5), nil)
+	sqlMock.ExpectCommit()
+
+	svc := service.NewChainService(mockDB, mockAccountRepo, mockTransferRepo, slog.Default(), nil)
+	reports, err := svc.VerifyAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.NotNil(t, reports[0].Broken)
+	assert.Equal(t, int64(5), reports[0].Broken.TransferID)
+	assert.Equal(t, service.ChainBreakUnchained, reports[0].Broken.Reason)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"moneytransfer/internal/authz"
+)
+
+// Error codes identify the domain errors of the service in a stable, machine-readable way.
+// They are part of the public API and must not be changed once published.
+const (
+	CodeInsufficientFunds = "insufficient_funds"
+	CodeAccountNotFound   = "account_not_found"
+	CodeInvalidTransfer   = "invalid_transfer"
+	CodeAmountOverflow    = "amount_overflow"
+	CodeConcurrentUpdate  = "concurrent_update"
+	CodeBatchNotFound     = "batch_not_found"
+	CodeTransferNotFound  = "transfer_not_found"
+	CodeNotReturnable     = "transfer_not_returnable"
+	CodeInvalidPeriod     = "invalid_statement_period"
+	CodeAccountNotOwned   = "account_not_owned"
+
+	CodeInvalidWebhookEndpoint  = "invalid_webhook_endpoint"
+	CodeWebhookEndpointNotFound = "webhook_endpoint_not_found"
+	CodeWebhookDeliveryNotFound = "webhook_delivery_not_found"
+	CodeInvalidAPIKey           = "invalid_api_key"
+	CodeAPIKeyNotFound          = "api_key_not_found"
+	CodeAPIKeyInactive          = "api_key_inactive"
+	CodeInvalidSignature        = "invalid_signature"
+	CodeSigningKeyNotFound      = "signing_key_not_found"
+	CodePermissionDenied        = "permission_denied"
+	CodeGrantNotFound           = "grant_not_found"
+	CodeInvalidAuditFilter      = "invalid_audit_filter"
+	CodeInternalError           = "internal_error"
+)
+
+// internalErrorDetail is the detail recorded for internal errors instead of their message,
+// the title of internal_error in the problem catalog
+const internalErrorDetail = "Internal server error"
+
+var (
+	// ErrInsufficientFunds is returned when the account balance does not cover the bulk transfer
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrAccountNotFound is returned when the account of the organization does not exist
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrInvalidTransfer is returned when a transfer of the request fails validation
+	ErrInvalidTransfer = errors.New("invalid transfer")
+	// ErrAmountOverflow is returned when the total of the transfers cannot be represented
+	ErrAmountOverflow = errors.New("total transfer amount exceeds maximum allowed value")
+	// ErrConcurrentUpdate is returned when the account kept being modified concurrently
+	// and the bulk transfer could not be executed within the maximum number of retries
+	ErrConcurrentUpdate = errors.New("account was updated concurrently")
+	// ErrBatchNotFound is returned when the requested batch does not exist
+	ErrBatchNotFound = errors.New("batch not found")
+	// ErrTransferNotFound is returned when the requested transfer does not exist
+	ErrTransferNotFound = errors.New("transfer not found")
+	// ErrNotReturnable is returned when a transfer that is not executed is returned
+	ErrNotReturnable = errors.New("transfer cannot be returned")
+	// ErrInvalidPeriod is returned when a statement is requested for a period that ends before it starts
+	ErrInvalidPeriod = errors.New("invalid statement period")
+	// ErrAccountNotOwned is returned when the authenticated organization debits an account it does not own
+	ErrAccountNotOwned = errors.New("account is not owned by the authenticated organization")
+	// ErrInvalidWebhookEndpoint is returned when a webhook endpoint fails validation
+	ErrInvalidWebhookEndpoint = errors.New("invalid webhook endpoint")
+	// ErrWebhookEndpointNotFound is returned when the requested webhook endpoint does not exist
+	ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+	// ErrWebhookDeliveryNotFound is returned when the requested webhook delivery does not exist
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrInvalidAPIKey is returned when an API key is unknown, revoked or expired
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrAPIKeyNotFound is returned when the requested API key does not exist
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPIKeyInactive is returned when a revoked or expired API key is rotated
+	ErrAPIKeyInactive = errors.New("api key is revoked or expired")
+	// ErrInvalidSignature is returned when the signature of a request is malformed, does not
+	// match the request, is made with an unknown or revoked key, is outside the accepted
+	// window or was already used
+	ErrInvalidSignature = errors.New("invalid request signature")
+	// ErrSigningKeyNotFound is returned when the requested signing key does not exist
+	ErrSigningKeyNotFound = errors.New("signing key not found")
+	// ErrPermissionDenied is returned when no role of the authenticated principal allows the action
+	ErrPermissionDenied = authz.ErrDenied
+	// ErrGrantNotFound is returned when the requested grant does not exist
+	ErrGrantNotFound = errors.New("grant not found")
+	// ErrInvalidAuditFilter is returned when the audit log is queried with an invalid filter
+	ErrInvalidAuditFilter = errors.New("invalid audit filter")
+)
+
+// InsufficientFundsError reports the amounts involved in an ErrInsufficientFunds error
+type InsufficientFundsError struct {
+	RequiredCents  int64
+	AvailableCents int64
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf("insufficient funds: %d cents required, %d cents available", e.RequiredCents, e.AvailableCents)
+}
+
+func (e *InsufficientFundsError) Is(target error) bool {
+	return target == ErrInsufficientFunds
+}
+
+// InvalidTransferError reports a transfer of a bulk transfer request that failed validation
+type InvalidTransferError struct {
+	// Line is the 1-based position of the transfer in the request
+	Line int
+	Err  error
+}
+
+func (e *InvalidTransferError) Error() string {
+	return fmt.Sprintf("invalid transfer at line %d: %v", e.Line, e.Err)
+}
+
+func (e *InvalidTransferError) Unwrap() error {
+	return e.Err
+}
+
+func (e *InvalidTransferError) Is(target error) bool {
+	return target == ErrInvalidTransfer
+}
+
+// ErrorCode returns the code of the domain error wrapped by err.
+// Errors that are not domain errors are reported as CodeInternalError.
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrInsufficientFunds):
+		return CodeInsufficientFunds
+	case errors.Is(err, ErrAccountNotFound):
+		return CodeAccountNotFound
+	case errors.Is(err, ErrInvalidTransfer):
+		return CodeInvalidTransfer
+	case errors.Is(err, ErrAmountOverflow):
+		return CodeAmountOverflow
+	case errors.Is(err, ErrConcurrentUpdate):
+		return CodeConcurrentUpdate
+	case errors.Is(err, ErrBatchNotFound):
+		return CodeBatchNotFound
+	case errors.Is(err, ErrTransferNotFound):
+		return CodeTransferNotFound
+	case errors.Is(err, ErrNotReturnable):
+		return CodeNotReturnable
+	case errors.Is(err, ErrInvalidPeriod):
+		return CodeInvalidPeriod
+	case errors.Is(err, ErrAccountNotOwned):
+		return CodeAccountNotOwned
+	case errors.Is(err, ErrInvalidWebhookEndpoint):
+		return CodeInvalidWebhookEndpoint
+	case errors.Is(err, ErrWebhookEndpointNotFound):
+		return CodeWebhookEndpointNotFound
+	case errors.Is(err, ErrWebhookDeliveryNotFound):
+		return CodeWebhookDeliveryNotFound
+	case errors.Is(err, ErrInvalidAPIKey):
+		return CodeInvalidAPIKey
+	case errors.Is(err, ErrAPIKeyNotFound):
+		return CodeAPIKeyNotFound
+	case errors.Is(err, ErrAPIKeyInactive):
+		return CodeAPIKeyInactive
+	case errors.Is(err, ErrInvalidSignature):
+		return CodeInvalidSignature
+	case errors.Is(err, ErrSigningKeyNotFound):
+		return CodeSigningKeyNotFound
+	case errors.Is(err, ErrPermissionDenied):
+		return CodePermissionDenied
+	case errors.Is(err, ErrGrantNotFound):
+		return CodeGrantNotFound
+	case errors.Is(err, ErrInvalidAuditFilter):
+		return CodeInvalidAuditFilter
+	default:
+		return CodeInternalError
+	}
+}
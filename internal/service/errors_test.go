@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"Insufficient funds sentinel", ErrInsufficientFunds, CodeInsufficientFunds},
+		{"Insufficient funds details", &InsufficientFundsError{RequiredCents: 2, AvailableCents: 1}, CodeInsufficientFunds},
+		{"Wrapped account not found", fmt.Errorf("%w: FR76", ErrAccountNotFound), CodeAccountNotFound},
+		{"Invalid transfer", &InvalidTransferError{Line: 3, Err: errors.New("amount is required")}, CodeInvalidTransfer},
+		{"Amount overflow", ErrAmountOverflow, CodeAmountOverflow},
+		{"Concurrent update", fmt.Errorf("%w after 3 attempts", ErrConcurrentUpdate), CodeConcurrentUpdate},
+		{"Batch not found", fmt.Errorf("%w: 42", ErrBatchNotFound), CodeBatchNotFound},
+		{"Transfer not found", fmt.Errorf("%w: 42", ErrTransferNotFound), CodeTransferNotFound},
+		{"Not returnable", fmt.Errorf("%w: transfer 42 is returned", ErrNotReturnable), CodeNotReturnable},
+		{"Invalid period", fmt.Errorf("%w: 2024-03-05 is after 2024-03-04", ErrInvalidPeriod), CodeInvalidPeriod},
+		{"Invalid webhook endpoint", fmt.Errorf("%w: %w", ErrInvalidWebhookEndpoint, errors.New("URL is required")), CodeInvalidWebhookEndpoint},
+		{"Webhook endpoint not found", ErrWebhookEndpointNotFound, CodeWebhookEndpointNotFound},
+		{"Webhook delivery not found", ErrWebhookDeliveryNotFound, CodeWebhookDeliveryNotFound},
+		{"Account not owned", fmt.Errorf("%w: FR76", ErrAccountNotOwned), CodeAccountNotOwned},
+		{"Invalid api key", ErrInvalidAPIKey, CodeInvalidAPIKey},
+		{"Api key not found", fmt.Errorf("%w: 42", ErrAPIKeyNotFound), CodeAPIKeyNotFound},
+		{"Api key inactive", fmt.Errorf("%w: 42", ErrAPIKeyInactive), CodeAPIKeyInactive},
+		{"Invalid signature", fmt.Errorf("%w: nonce already used", ErrInvalidSignature), CodeInvalidSignature},
+		{"Signing key not found", fmt.Errorf("%w: 42", ErrSigningKeyNotFound), CodeSigningKeyNotFound},
+		{"Permission denied", fmt.Errorf("%w: api_key:1 may not submit account FR76", ErrPermissionDenied), CodePermissionDenied},
+		{"Grant not found", fmt.Errorf("%w: 42", ErrGrantNotFound), CodeGrantNotFound},
+		{"Invalid audit filter", fmt.Errorf("%w: limit must be between 1 and 1000", ErrInvalidAuditFilter), CodeInvalidAuditFilter},
+		{"Unknown error", errors.New("connection refused"), CodeInternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ErrorCode(tt.err))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/event"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=event_service.go -destination=../../mock/event_service_mock.go -package=mock -mock_names=EventService=EventServiceMock
+type EventService interface {
+	// ListEvents returns up to limit committed events of the filter after the sequence afterSeq
+	ListEvents(ctx context.Context, filter event.Filter, afterSeq int64, limit int) ([]event.Event, error)
+	// Subscribe returns a channel that is signaled when new events may have been committed
+	// and a function that ends the subscription, once the principal of ctx is allowed to
+	// read the events of the filter
+	Subscribe(ctx context.Context, filter event.Filter) (<-chan struct{}, func(), error)
+}
+
+type eventService struct {
+	eventRepo   event.Repository
+	accountRepo account.Repository
+	broker      *event.Broker
+	policy      authz.Authorizer
+}
+
+// NewEventService is a function that creates a new event service
+// broker must be the one passed to the services that append events
+// policy authorizes the reading of the events, it may be nil to allow it to every caller
+func NewEventService(eventRepo event.Repository, accountRepo account.Repository, broker *event.Broker, policy authz.Authorizer) *eventService {
+	return &eventService{
+		eventRepo:   eventRepo,
+		accountRepo: accountRepo,
+		broker:      broker,
+		policy:      policy,
+	}
+}
+
+func (s *eventService) ListEvents(ctx context.Context, filter event.Filter, afterSeq int64, limit int) ([]event.Event, error) {
+	if err := s.authorizeFilter(ctx, filter); err != nil {
+		return nil, err
+	}
+	return s.eventRepo.ListAfter(ctx, filter, afterSeq, limit)
+}
+
+func (s *eventService) Subscribe(ctx context.Context, filter event.Filter) (<-chan struct{}, func(), error) {
+	if err := s.authorizeFilter(ctx, filter); err != nil {
+		return nil, nil, err
+	}
+	notify, unsubscribe := s.broker.Subscribe()
+	return notify, unsubscribe, nil
+}
+
+// authorizeFilter returns an error unless the filter is scoped and the principal of ctx may read
+// its events. Principals only read the events of their own organization and accounts, with or
+// without a policy.
+func (s *eventService) authorizeFilter(ctx context.Context, filter event.Filter) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	if p, ok := auth.FromContext(ctx); ok && filter.OrganizationName != "" && filter.OrganizationName != p.OrganizationName {
+		return fmt.Errorf("%w: events of organization %s", ErrPermissionDenied, filter.OrganizationName)
+	}
+	if filter.OrganizationIBAN != "" {
+		if err := checkAccountOwner(ctx, s.accountRepo, filter.OrganizationIBAN); err != nil {
+			return err
+		}
+	}
+	return authorize(ctx, s.policy, authz.ActionRead, authz.Resource{OrganizationName: filter.OrganizationName, AccountIBAN: filter.OrganizationIBAN})
+}
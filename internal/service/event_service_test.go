@@ -0,0 +1,86 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/event"
+	"moneytransfer/internal/service"
+	"moneytransfer/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEventService_ListEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockEventRepo := mock.NewEventRepositoryMock(ctrl)
+	svc := service.NewEventService(mockEventRepo, mock.NewAccountRepositoryMock(ctrl), event.NewBroker(), nil)
+
+	t.Run("Events of an organization", func(t *testing.T) {
+		filter := event.Filter{OrganizationName: "Test Org"}
+		want := []event.Event{{Seq: 5, Type: event.TypeBatchReceived}}
+		mockEventRepo.EXPECT().ListAfter(gomock.Any(), filter, int64(4), 10).Return(want, nil)
+
+		events, err := svc.ListEvents(context.Background(), filter, 4, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, want, events)
+	})
+
+	t.Run("Unscoped filter is rejected", func(t *testing.T) {
+		_, err := svc.ListEvents(context.Background(), event.Filter{}, 0, 10)
+		assert.Error(t, err)
+	})
+}
+
+func TestEventService_Authorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockEventRepo := mock.NewEventRepositoryMock(ctrl)
+	mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+	mockPolicy := mock.NewAuthorizerMock(ctrl)
+	ctx := auth.NewContext(context.Background(), auth.Principal{OrganizationName: "Test Org", Subject: "api_key:7"})
+	const otherIBAN = "DE89370400440532013000"
+
+	t.Run("Events of another organization are denied without a policy", func(t *testing.T) {
+		svc := service.NewEventService(mockEventRepo, mockAccountRepo, event.NewBroker(), nil)
+
+		_, err := svc.ListEvents(ctx, event.Filter{OrganizationName: "Other Org"}, 0, 10)
+		assert.ErrorIs(t, err, service.ErrPermissionDenied)
+
+		_, _, err = svc.Subscribe(ctx, event.Filter{OrganizationName: "Other Org"})
+		assert.ErrorIs(t, err, service.ErrPermissionDenied)
+	})
+
+	t.Run("Events of an account of another organization are denied without a policy", func(t *testing.T) {
+		svc := service.NewEventService(mockEventRepo, mockAccountRepo, event.NewBroker(), nil)
+		mockAccountRepo.EXPECT().GetByIBAN(otherIBAN, gomock.Any()).Return(&account.BankAccount{IBAN: otherIBAN, OrganizationName: "Other Org"}, nil)
+
+		_, err := svc.ListEvents(ctx, event.Filter{OrganizationIBAN: otherIBAN}, 0, 10)
+		assert.ErrorIs(t, err, service.ErrAccountNotOwned)
+	})
+
+	t.Run("Subscription denied by the policy", func(t *testing.T) {
+		svc := service.NewEventService(mockEventRepo, mockAccountRepo, event.NewBroker(), mockPolicy)
+		mockPolicy.EXPECT().Authorize(gomock.Any(), authz.ActionRead, authz.Resource{OrganizationName: "Test Org"}).
+			Return(fmt.Errorf("%w: api_key:7 may not read organization Test Org", authz.ErrDenied))
+
+		notify, unsubscribe, err := svc.Subscribe(ctx, event.Filter{OrganizationName: "Test Org"})
+		assert.ErrorIs(t, err, service.ErrPermissionDenied)
+		assert.Nil(t, notify)
+		assert.Nil(t, unsubscribe)
+	})
+
+	t.Run("Events allowed by the policy", func(t *testing.T) {
+		svc := service.NewEventService(mockEventRepo, mockAccountRepo, event.NewBroker(), mockPolicy)
+		filter := event.Filter{OrganizationName: "Test Org"}
+		mockPolicy.EXPECT().Authorize(gomock.Any(), authz.ActionRead, authz.Resource{OrganizationName: "Test Org"}).Return(nil)
+		mockEventRepo.EXPECT().ListAfter(gomock.Any(), filter, int64(0), 10).Return(nil, nil)
+
+		_, err := svc.ListEvents(ctx, filter, 0, 10)
+		assert.NoError(t, err)
+	})
+}
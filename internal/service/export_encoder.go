@@ -0,0 +1,119 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"moneytransfer/internal/transfer"
+)
+
+// exportedTransfer is a transfer as it is exported, its fields are the columns of CSV exports
+type exportedTransfer struct {
+	ID                   int64  `json:"id"`
+	CreatedAt            string `json:"created_at"`
+	BatchID              int64  `json:"batch_id,omitempty"`
+	Status               string `json:"status"`
+	Amount               string `json:"amount"`
+	Currency             string `json:"currency"`
+	CounterpartyName     string `json:"counterparty_name"`
+	CounterpartyIBAN     string `json:"counterparty_iban"`
+	CounterpartyBIC      string `json:"counterparty_bic"`
+	Description          string `json:"description"`
+	EndToEndID           string `json:"end_to_end_id,omitempty"`
+	PaymentInformationID string `json:"payment_information_id,omitempty"`
+	ReturnedAt           string `json:"returned_at,omitempty"`
+	ReturnReason         string `json:"return_reason,omitempty"`
+}
+
+// exportColumns is the header row of CSV exports, in the order of the fields of exportedTransfer
+var exportColumns = []string{
+	"id", "created_at", "batch_id", "status", "amount", "currency", "counterparty_name", "counterparty_iban",
+	"counterparty_bic", "description", "end_to_end_id", "payment_information_id", "returned_at", "return_reason",
+}
+
+// row returns the CSV row of the transfer
+func (t exportedTransfer) row() []string {
+	batchID := ""
+	if t.BatchID != 0 {
+		batchID = strconv.FormatInt(t.BatchID, 10)
+	}
+	return []string{
+		strconv.FormatInt(t.ID, 10), t.CreatedAt, batchID, t.Status, t.Amount, t.Currency, t.CounterpartyName, t.CounterpartyIBAN,
+		t.CounterpartyBIC, t.Description, t.EndToEndID, t.PaymentInformationID, t.ReturnedAt, t.ReturnReason,
+	}
+}
+
+// transferEncoder writes exported transfers in a format
+type transferEncoder interface {
+	Encode(t exportedTransfer) error
+	// Flush writes the buffered transfers
+	Flush() error
+}
+
+type csvTransferEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvTransferEncoder) Encode(t exportedTransfer) error {
+	return e.w.Write(t.row())
+}
+
+func (e *csvTransferEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonTransferEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonTransferEncoder) Encode(t exportedTransfer) error {
+	return e.enc.Encode(t)
+}
+
+func (e *ndjsonTransferEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+// newTransferEncoder returns the encoder of the format writing to w, CSV exports start with their header row
+func newTransferEncoder(format ExportFormat, w io.Writer) (transferEncoder, error) {
+	switch format {
+	case ExportFormatCSV:
+		e := &csvTransferEncoder{w: csv.NewWriter(w)}
+		return e, e.w.Write(exportColumns)
+	case ExportFormatNDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonTransferEncoder{w: bw, enc: json.NewEncoder(bw)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// exportTransfer returns the exported transfer of t, with its amount in euros and its times in UTC
+func exportTransfer(t transfer.Transfer) exportedTransfer {
+	e := exportedTransfer{
+		ID:                   t.ID,
+		CreatedAt:            t.CreatedAt.UTC().Format(time.RFC3339Nano),
+		BatchID:              t.BatchID,
+		Status:               string(t.Status),
+		Amount:               formatCents(t.AmountCents),
+		Currency:             accountCurrency,
+		CounterpartyName:     t.CounterpartyName,
+		CounterpartyIBAN:     t.CounterpartyIBAN,
+		CounterpartyBIC:      t.CounterpartyBIC,
+		Description:          t.Description,
+		EndToEndID:           t.EndToEndID,
+		PaymentInformationID: t.PaymentInformationID,
+		ReturnReason:         t.ReturnReason,
+	}
+	if !t.ReturnedAt.IsZero() {
+		e.ReturnedAt = t.ReturnedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return e
+}
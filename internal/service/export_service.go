@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/transfer"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=export_service.go -destination=../../mock/export_service_mock.go -package=mock -mock_names=ExportService=ExportServiceMock
+type ExportService interface {
+	// ExportTransfers writes the transfers of the account matching the filter to w in the given
+	// format, by creation time, and returns how many were written. The account is looked up before
+	// anything is written, so that w is left untouched when ErrAccountNotFound is returned.
+	ExportTransfers(ctx context.Context, format ExportFormat, filter ExportFilter, w io.Writer) (int, error)
+}
+
+// ExportFormat is a format transfers are exported in
+type ExportFormat string
+
+const (
+	// ExportFormatCSV writes a header row followed by one row per transfer
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatNDJSON writes one JSON object per transfer and per line
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// ExportFilter selects the transfers of an account to export
+type ExportFilter struct {
+	IBAN string
+	// From and To bound the creation time of the transfers, To excluded. Zero times leave it unbounded.
+	From time.Time
+	To   time.Time
+	// Status selects the transfers in the status, all statuses when empty
+	Status transfer.Status
+	// BatchID selects the transfers created by the batch, all transfers when zero
+	BatchID int64
+	// CounterpartyIBAN selects the transfers to the IBAN, all transfers when empty
+	CounterpartyIBAN string
+}
+
+type exportService struct {
+	db           *sql.DB
+	accountRepo  account.Repository
+	transferRepo transfer.Repository
+	logger       *slog.Logger
+	policy       authz.Authorizer
+}
+
+// NewExportService is a function that creates a new export service
+// policy authorizes the reads of the accounts, it may be nil to allow them to every caller
+func NewExportService(db *sql.DB, accountRepo account.Repository, transferRepo transfer.Repository, logger *slog.Logger, policy authz.Authorizer) *exportService {
+	return &exportService{
+		db:           db,
+		accountRepo:  accountRepo,
+		transferRepo: transferRepo,
+		logger:       logger,
+		policy:       policy,
+	}
+}
+
+// ExportTransfers reads the transfers through a server-side cursor in a single repeatable read
+// transaction, so that the export is a consistent snapshot of the account that leaves out the
+// transfers inserted while it is written, and is never held in memory as a whole.
+func (s *exportService) ExportTransfers(ctx context.Context, format ExportFormat, filter ExportFilter, w io.Writer) (int, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return 0, fmt.Errorf("%w: %s is after %s", ErrInvalidPeriod, filter.From.Format(time.RFC3339), filter.To.Format(time.RFC3339))
+	}
+	if err := authorize(ctx, s.policy, authz.ActionRead, authz.Resource{AccountIBAN: filter.IBAN}); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	acc, err := s.accountRepo.GetByIBAN(filter.IBAN, tx)
+	if err != nil {
+		if errors.Is(err, account.ErrNotFound) {
+			return 0, fmt.Errorf("%w: %s", ErrAccountNotFound, filter.IBAN)
+		}
+		return 0, err
+	}
+
+	enc, err := newTransferEncoder(format, w)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = s.transferRepo.ForEachTransfer(ctx, tx, transfer.Filter{
+		BankAccountID:    acc.ID,
+		CreatedFrom:      filter.From,
+		CreatedTo:        filter.To,
+		Status:           filter.Status,
+		BatchID:          filter.BatchID,
+		CounterpartyIBAN: filter.CounterpartyIBAN,
+	}, func(t transfer.Transfer) error {
+		count++
+		return enc.Encode(exportTransfer(t))
+	})
+	if err != nil {
+		return count, err
+	}
+	if err := enc.Flush(); err != nil {
+		return count, err
+	}
+	if err := tx.Commit(); err != nil {
+		return count, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Transfers exported", "account_id", acc.ID, "format", format, "transfers", count)
+	return count, nil
+}
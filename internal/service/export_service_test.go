@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/transfer"
+	"moneytransfer/mock"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestExportService_ExportTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+	mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+
+	mockDB, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	svc := service.NewExportService(mockDB, mockAccountRepo, mockTransferRepo, slog.Default(), nil)
+	ctx := context.Background()
+
+	const iban = "FR1420041010050500013M02606"
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+	acc := &account.BankAccount{ID: 7, IBAN: iban}
+
+	// forEachTransfer yields an executed transfer of a batch and a returned transfer
+	forEachTransfer := func(_ context.Context, _ any, _ transfer.Filter, fn func(transfer.Transfer) error) error {
+		executed := newTestTransfer(10050)
+		executed.ID, executed.BatchID, executed.Status, executed.EndToEndID = 1, testBatchID, transfer.StatusExecuted, "E2E-1"
+		executed.CreatedAt = time.Date(2024, 3, 4, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+		if err := fn(executed); err != nil {
+			return err
+		}
+		returned := newTestTransfer(2000)
+		returned.ID, returned.Status, returned.ReturnReason = 2, transfer.StatusReturned, "AC04"
+		returned.Description = `Invoice "12", March`
+		returned.CreatedAt = time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)
+		returned.ReturnedAt = time.Date(2024, 3, 5, 9, 30, 0, 0, time.UTC)
+		return fn(returned)
+	}
+
+	t.Run("CSV export of the filtered transfers", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(iban, gomock.Any()).Return(acc, nil)
+		mockTransferRepo.EXPECT().ForEachTransfer(ctx, gomock.Any(), transfer.Filter{BankAccountID: 7, CreatedFrom: from, CreatedTo: to, Status: transfer.StatusExecuted, BatchID: testBatchID, CounterpartyIBAN: "DE89370400440532013000"}, gomock.Any()).
+			DoAndReturn(forEachTransfer)
+		sqlMock.ExpectCommit()
+
+		var out strings.Builder
+		count, err := svc.ExportTransfers(ctx, service.ExportFormatCSV, service.ExportFilter{IBAN: iban, From: from, To: to, Status: transfer.StatusExecuted, BatchID: testBatchID, CounterpartyIBAN: "DE89370400440532013000"}, &out)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+		assert.Equal(t, "id,created_at,batch_id,status,amount,currency,counterparty_name,counterparty_iban,counterparty_bic,description,end_to_end_id,payment_information_id,returned_at,return_reason\n"+
+			"1,2024-03-04T09:00:00Z,42,executed,100.50,EUR,John Doe,DE89370400440532013000,DEUTDEFF,Test transfer,E2E-1,,,\n"+
+			`2,2024-03-05T08:00:00Z,,returned,20.00,EUR,John Doe,DE89370400440532013000,DEUTDEFF,"Invoice ""12"", March",,,2024-03-05T09:30:00Z,AC04`+"\n",
+			out.String())
+	})
+
+	t.Run("NDJSON export", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(iban, gomock.Any()).Return(acc, nil)
+		mockTransferRepo.EXPECT().ForEachTransfer(ctx, gomock.Any(), transfer.Filter{BankAccountID: 7}, gomock.Any()).DoAndReturn(forEachTransfer)
+		sqlMock.ExpectCommit()
+
+		var out strings.Builder
+		count, err := svc.ExportTransfers(ctx, service.ExportFormatNDJSON, service.ExportFilter{IBAN: iban}, &out)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, `{"id":1,"created_at":"2024-03-04T09:00:00Z","batch_id":42,"status":"executed","amount":"100.50","currency":"EUR","counterparty_name":"John Doe","counterparty_iban":"DE89370400440532013000","counterparty_bic":"DEUTDEFF","description":"Test transfer","end_to_end_id":"E2E-1"}`+"\n"+
+			`{"id":2,"created_at":"2024-03-05T08:00:00Z","status":"returned","amount":"20.00","currency":"EUR","counterparty_name":"John Doe","counterparty_iban":"DE89370400440532013000","counterparty_bic":"DEUTDEFF","description":"Invoice \"12\", March","returned_at":"2024-03-05T09:30:00Z","return_reason":"AC04"}`+"\n",
+			out.String())
+	})
+
+	t.Run("Period ending before it starts", func(t *testing.T) {
+		var out strings.Builder
+		_, err := svc.ExportTransfers(ctx, service.ExportFormatCSV, service.ExportFilter{IBAN: iban, From: to, To: from}, &out)
+		assert.ErrorIs(t, err, service.ErrInvalidPeriod)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("Unknown account", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN("DE89370400440532013000", gomock.Any()).Return(nil, account.ErrNotFound)
+		sqlMock.ExpectRollback()
+
+		var out strings.Builder
+		_, err := svc.ExportTransfers(ctx, service.ExportFormatCSV, service.ExportFilter{IBAN: "DE89370400440532013000"}, &out)
+		assert.ErrorIs(t, err, service.ErrAccountNotFound)
+		assert.Empty(t, out.String())
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		repoErr := errors.New("connection reset")
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(iban, gomock.Any()).Return(acc, nil)
+		mockTransferRepo.EXPECT().ForEachTransfer(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(repoErr)
+		sqlMock.ExpectRollback()
+
+		var out strings.Builder
+		_, err := svc.ExportTransfers(ctx, service.ExportFormatNDJSON, service.ExportFilter{IBAN: iban}, &out)
+		assert.ErrorIs(t, err, repoErr)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/authz"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=role_service.go -destination=../../mock/role_service_mock.go -package=mock -mock_names=RoleService=RoleServiceMock
+type RoleService interface {
+	// Grant grants the role to the subject in the organization, on a single account
+	// when accountIBAN is set and on all its accounts otherwise
+	Grant(ctx context.Context, organizationName, subject string, role authz.Role, accountIBAN string) (*authz.Grant, error)
+	// Revoke removes the grant at once
+	Revoke(ctx context.Context, id int64) error
+	List(ctx context.Context, organizationName string) ([]authz.Grant, error)
+}
+
+type roleService struct {
+	repo      authz.Repository
+	auditRepo audit.Repository
+	logger    *slog.Logger
+}
+
+// NewRoleService is a function that creates a new role service
+// auditRepo records the grants and revocations, it may be nil to not audit them
+func NewRoleService(repo authz.Repository, auditRepo audit.Repository, logger *slog.Logger) *roleService {
+	return &roleService{
+		repo:      repo,
+		auditRepo: auditRepo,
+		logger:    logger,
+	}
+}
+
+func (s *roleService) Grant(ctx context.Context, organizationName, subject string, role authz.Role, accountIBAN string) (*authz.Grant, error) {
+	e := newAdminAuditEvent(ctx, audit.ActionRoleGrant, organizationName, "grant")
+	e.AccountIBAN = accountIBAN
+
+	g := &authz.Grant{
+		OrganizationName: organizationName,
+		Subject:          subject,
+		Role:             role,
+		AccountIBAN:      accountIBAN,
+	}
+	if err := g.Validate(); err != nil {
+		return nil, recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+
+	if err := s.repo.CreateGrant(ctx, g); err != nil {
+		return nil, recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+
+	s.logger.Info("Role granted", "grant_id", g.ID, "organization", organizationName, "subject", subject, "role", role, "account_iban", accountIBAN)
+	e.ResourceID = strconv.FormatInt(g.ID, 10)
+	recordAudit(ctx, s.auditRepo, s.logger, e, map[string]any{"subject": subject, "role": role, "account_iban": accountIBAN}, nil)
+	return g, nil
+}
+
+func (s *roleService) Revoke(ctx context.Context, id int64) error {
+	e := newAdminAuditEvent(ctx, audit.ActionRoleRevoke, "", "grant")
+	e.ResourceID = strconv.FormatInt(id, 10)
+
+	if err := s.repo.DeleteGrant(ctx, id); err != nil {
+		if errors.Is(err, authz.ErrGrantNotFound) {
+			err = fmt.Errorf("%w: %d", ErrGrantNotFound, id)
+		}
+		return recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+
+	s.logger.Info("Role revoked", "grant_id", id)
+	recordAudit(ctx, s.auditRepo, s.logger, e, nil, nil)
+	return nil
+}
+
+func (s *roleService) List(ctx context.Context, organizationName string) ([]authz.Grant, error) {
+	return s.repo.ListGrants(ctx, organizationName)
+}
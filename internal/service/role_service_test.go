@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"testing"
+
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/service"
+	"moneytransfer/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRoleService_Grant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewAuthzRepositoryMock(ctrl)
+	svc := service.NewRoleService(mockRepo, nil, slog.Default())
+
+	t.Run("Account grant", func(t *testing.T) {
+		mockRepo.EXPECT().CreateGrant(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, g *authz.Grant) error {
+			assert.Equal(t, "FR10474608000002006107XXXXX", g.AccountIBAN)
+			g.ID = 1
+			return nil
+		})
+
+		g, err := svc.Grant(context.Background(), "Test Org", "api_key:7", authz.RoleViewer, "FR10474608000002006107XXXXX")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), g.ID)
+	})
+
+	t.Run("Unknown role", func(t *testing.T) {
+		_, err := svc.Grant(context.Background(), "Test Org", "api_key:7", authz.Role("owner"), "")
+		assert.Error(t, err)
+	})
+
+	t.Run("Missing subject", func(t *testing.T) {
+		_, err := svc.Grant(context.Background(), "Test Org", "", authz.RoleAdmin, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestRoleService_Revoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewAuthzRepositoryMock(ctrl)
+	svc := service.NewRoleService(mockRepo, nil, slog.Default())
+
+	mockRepo.EXPECT().DeleteGrant(gomock.Any(), int64(42)).Return(authz.ErrGrantNotFound)
+
+	err := svc.Revoke(context.Background(), 42)
+	assert.ErrorIs(t, err, service.ErrGrantNotFound)
+}
+
+func TestRoleService_Audit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewAuthzRepositoryMock(ctrl)
+	mockAuditRepo := mock.NewAuditRepositoryMock(ctrl)
+	svc := service.NewRoleService(mockRepo, mockAuditRepo, slog.Default())
+
+	ctx := auth.NewContext(context.Background(), auth.Principal{OrganizationName: "Test Org", Subject: "api_key:1"})
+	ctx = audit.NewContext(ctx, audit.Source{IP: "192.0.2.1", RequestID: "req-1"})
+
+	t.Run("Grant", func(t *testing.T) {
+		mockRepo.EXPECT().CreateGrant(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, g *authz.Grant) error {
+			g.ID = 3
+			return nil
+		})
+		mockAuditRepo.EXPECT().Append(gomock.Any(), nil, gomock.Any()).DoAndReturn(func(_ context.Context, _ *sql.Tx, e *audit.Event) error {
+			assert.Equal(t, audit.ActionRoleGrant, e.Action)
+			assert.Equal(t, audit.OutcomeSuccess, e.Outcome)
+			assert.Equal(t, "api_key:1", e.Actor)
+			assert.Equal(t, "192.0.2.1", e.SourceIP)
+			assert.Equal(t, "req-1", e.RequestID)
+			assert.Equal(t, "Test Org", e.OrganizationName)
+			assert.Equal(t, "3", e.ResourceID)
+			assert.JSONEq(t, `{"subject":"api_key:7","role":"viewer","account_iban":""}`, string(e.After))
+			return nil
+		})
+
+		_, err := svc.Grant(ctx, "Test Org", "api_key:7", authz.RoleViewer, "")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Failed revocation", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteGrant(gomock.Any(), int64(42)).Return(authz.ErrGrantNotFound)
+		mockAuditRepo.EXPECT().Append(gomock.Any(), nil, gomock.Any()).DoAndReturn(func(_ context.Context, _ *sql.Tx, e *audit.Event) error {
+			assert.Equal(t, audit.ActionRoleRevoke, e.Action)
+			assert.Equal(t, audit.OutcomeFailure, e.Outcome)
+			assert.Equal(t, service.CodeGrantNotFound, e.ErrorCode)
+			return nil
+		})
+
+		err := svc.Revoke(ctx, 42)
+		assert.ErrorIs(t, err, service.ErrGrantNotFound)
+	})
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/signing"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=signing_service.go -destination=../../mock/signing_service_mock.go -package=mock -mock_names=SigningService=SigningServiceMock
+type SigningService interface {
+	// CreateKey issues a signing key for the organization, its secret is returned
+	// here and by ListKeys as both ends of the signatures need it
+	CreateKey(ctx context.Context, organizationName string) (*signing.Key, error)
+	ListKeys(ctx context.Context, organizationName string) ([]signing.Key, error)
+	// RevokeKey invalidates the signing key at once
+	RevokeKey(ctx context.Context, id int64) error
+	// Verify checks the signature of a request and records its nonce. ErrInvalidSignature
+	// is returned if the signature is outside the accepted window, is made with an unknown
+	// or revoked key or a key of another organization than the authenticated one, does not
+	// match the request or was already used.
+	Verify(ctx context.Context, r signing.Request) error
+}
+
+type signingService struct {
+	repo      signing.Repository
+	auditRepo audit.Repository
+	// window is the maximum difference between the timestamp of a signature and the time it is verified
+	window time.Duration
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewSigningService is a function that creates a new signing service
+// auditRepo records the created and revoked keys, it may be nil to not audit them
+func NewSigningService(repo signing.Repository, auditRepo audit.Repository, window time.Duration, logger *slog.Logger) *signingService {
+	return &signingService{
+		repo:      repo,
+		auditRepo: auditRepo,
+		window:    window,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+func (s *signingService) CreateKey(ctx context.Context, organizationName string) (*signing.Key, error) {
+	e := newAdminAuditEvent(ctx, audit.ActionSigningKeyCreate, organizationName, "signing_key")
+
+	k, err := signing.NewKey(organizationName)
+	if err != nil {
+		return nil, recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+	if err := k.Validate(); err != nil {
+		return nil, recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+
+	if err := s.repo.CreateKey(ctx, k); err != nil {
+		return nil, recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+
+	s.logger.Info("Signing key created", "signing_key_id", k.ID, "organization", organizationName)
+	// The secret is never part of the audit log
+	e.ResourceID = strconv.FormatInt(k.ID, 10)
+	recordAudit(ctx, s.auditRepo, s.logger, e, map[string]any{"id": k.ID}, nil)
+	return k, nil
+}
+
+func (s *signingService) ListKeys(ctx context.Context, organizationName string) ([]signing.Key, error) {
+	return s.repo.ListKeys(ctx, organizationName)
+}
+
+func (s *signingService) RevokeKey(ctx context.Context, id int64) error {
+	e := newAdminAuditEvent(ctx, audit.ActionSigningKeyRevoke, "", "signing_key")
+	e.ResourceID = strconv.FormatInt(id, 10)
+
+	if err := s.repo.RevokeKey(ctx, id); err != nil {
+		if errors.Is(err, signing.ErrKeyNotFound) {
+			err = fmt.Errorf("%w: %d", ErrSigningKeyNotFound, id)
+		}
+		return recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+
+	s.logger.Info("Signing key revoked", "signing_key_id", id)
+	recordAudit(ctx, s.auditRepo, s.logger, e, nil, nil)
+	return nil
+}
+
+func (s *signingService) Verify(ctx context.Context, r signing.Request) error {
+	// The timestamp is checked first, stale requests are rejected without a lookup
+	now := s.now()
+	if r.Timestamp.Before(now.Add(-s.window)) || r.Timestamp.After(now.Add(s.window)) {
+		return fmt.Errorf("%w: timestamp outside the accepted window of %v", ErrInvalidSignature, s.window)
+	}
+
+	k, err := s.repo.GetKey(ctx, r.KeyID)
+	if err != nil {
+		if errors.Is(err, signing.ErrKeyNotFound) {
+			return fmt.Errorf("%w: unknown signing key %d", ErrInvalidSignature, r.KeyID)
+		}
+		return err
+	}
+	if !k.RevokedAt.IsZero() {
+		return fmt.Errorf("%w: signing key %d is revoked", ErrInvalidSignature, r.KeyID)
+	}
+	// The key of an organization cannot sign the requests of another one
+	if p, ok := auth.FromContext(ctx); ok && p.OrganizationName != k.OrganizationName {
+		return fmt.Errorf("%w: signing key %d belongs to another organization", ErrInvalidSignature, r.KeyID)
+	}
+	if !signing.Verify(k.Secret, r) {
+		return fmt.Errorf("%w: signature does not match the request", ErrInvalidSignature)
+	}
+
+	// The nonce is only recorded for valid signatures, and kept as long as the timestamp is
+	// within the window so that the request cannot be replayed
+	if err := s.repo.UseNonce(ctx, k.OrganizationName, r.Nonce, r.Timestamp.Add(s.window)); err != nil {
+		if errors.Is(err, signing.ErrNonceUsed) {
+			return fmt.Errorf("%w: nonce already used", ErrInvalidSignature)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/signing"
+	"moneytransfer/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSigningService_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewSigningRepositoryMock(ctrl)
+	svc := service.NewSigningService(mockRepo, nil, 5*time.Minute, slog.Default())
+
+	key := &signing.Key{ID: 7, OrganizationName: "Test Org", Secret: "mtsig_secret"}
+	ctx := auth.NewContext(context.Background(), auth.Principal{OrganizationName: "Test Org"})
+	signed := func(ts time.Time) signing.Request {
+		r := signing.Request{
+			KeyID:     7,
+			Method:    "POST",
+			Path:      "/api/v1/transfers",
+			Timestamp: ts.Truncate(time.Second),
+			Nonce:     "0123456789abcdef",
+			BodyHash:  "ab",
+		}
+		r.Signature = signing.Sign(key.Secret, r)
+		return r
+	}
+
+	t.Run("Valid signature records the nonce", func(t *testing.T) {
+		r := signed(time.Now())
+		mockRepo.EXPECT().GetKey(gomock.Any(), int64(7)).Return(key, nil)
+		mockRepo.EXPECT().UseNonce(gomock.Any(), "Test Org", r.Nonce, r.Timestamp.Add(5*time.Minute)).Return(nil)
+
+		assert.NoError(t, svc.Verify(ctx, r))
+	})
+
+	t.Run("Replayed nonce is rejected", func(t *testing.T) {
+		mockRepo.EXPECT().GetKey(gomock.Any(), int64(7)).Return(key, nil)
+		mockRepo.EXPECT().UseNonce(gomock.Any(), "Test Org", gomock.Any(), gomock.Any()).Return(signing.ErrNonceUsed)
+
+		err := svc.Verify(ctx, signed(time.Now()))
+		assert.ErrorIs(t, err, service.ErrInvalidSignature)
+		assert.ErrorContains(t, err, "nonce already used")
+	})
+
+	t.Run("Stale timestamp is rejected without a lookup", func(t *testing.T) {
+		err := svc.Verify(ctx, signed(time.Now().Add(-6*time.Minute)))
+		assert.ErrorIs(t, err, service.ErrInvalidSignature)
+		assert.ErrorContains(t, err, "window")
+	})
+
+	t.Run("Future timestamp is rejected", func(t *testing.T) {
+		err := svc.Verify(ctx, signed(time.Now().Add(6*time.Minute)))
+		assert.ErrorIs(t, err, service.ErrInvalidSignature)
+	})
+
+	t.Run("Tampered request is rejected", func(t *testing.T) {
+		r := signed(time.Now())
+		r.BodyHash = "cd"
+		mockRepo.EXPECT().GetKey(gomock.Any(), int64(7)).Return(key, nil)
+
+		err := svc.Verify(ctx, r)
+		assert.ErrorIs(t, err, service.ErrInvalidSignature)
+		assert.ErrorContains(t, err, "does not match")
+	})
+
+	t.Run("Unknown key is rejected", func(t *testing.T) {
+		mockRepo.EXPECT().GetKey(gomock.Any(), int64(7)).Return(nil, signing.ErrKeyNotFound)
+
+		err := svc.Verify(ctx, signed(time.Now()))
+		assert.ErrorIs(t, err, service.ErrInvalidSignature)
+	})
+
+	t.Run("Revoked key is rejected", func(t *testing.T) {
+		revoked := *key
+		revoked.RevokedAt = time.Now()
+		mockRepo.EXPECT().GetKey(gomock.Any(), int64(7)).Return(&revoked, nil)
+
+		err := svc.Verify(ctx, signed(time.Now()))
+		assert.ErrorIs(t, err, service.ErrInvalidSignature)
+		assert.ErrorContains(t, err, "revoked")
+	})
+
+	t.Run("Key of another organization is rejected", func(t *testing.T) {
+		mockRepo.EXPECT().GetKey(gomock.Any(), int64(7)).Return(key, nil)
+
+		other := auth.NewContext(context.Background(), auth.Principal{OrganizationName: "Other Org"})
+		err := svc.Verify(other, signed(time.Now()))
+		assert.ErrorIs(t, err, service.ErrInvalidSignature)
+		assert.ErrorContains(t, err, "another organization")
+	})
+
+	t.Run("Store failure is not a signature error", func(t *testing.T) {
+		mockRepo.EXPECT().GetKey(gomock.Any(), int64(7)).Return(nil, errors.New("connection refused"))
+
+		err := svc.Verify(ctx, signed(time.Now()))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, service.ErrInvalidSignature)
+	})
+}
+
+func TestSigningService_RevokeKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewSigningRepositoryMock(ctrl)
+	svc := service.NewSigningService(mockRepo, nil, 5*time.Minute, slog.Default())
+
+	mockRepo.EXPECT().RevokeKey(gomock.Any(), int64(42)).Return(signing.ErrKeyNotFound)
+
+	err := svc.RevokeKey(context.Background(), 42)
+	assert.ErrorIs(t, err, service.ErrSigningKeyNotFound)
+}
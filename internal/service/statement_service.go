@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/iso20022"
+	"moneytransfer/internal/swift"
+	"moneytransfer/internal/transfer"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=statement_service.go -destination=../../mock/statement_service_mock.go -package=mock -mock_names=StatementService=StatementServiceMock
+type StatementService interface {
+	// WriteStatement writes the camt.053 statement of the account with the given IBAN to w,
+	// for the days from fromDate to toDate included, in UTC. The account is looked up before
+	// anything is written, so that w is left untouched when ErrAccountNotFound is returned.
+	WriteStatement(ctx context.Context, iban string, fromDate, toDate time.Time, w io.Writer) error
+	// WriteMT940Statement writes the same statement as WriteStatement in the SWIFT MT940 format
+	WriteMT940Statement(ctx context.Context, iban string, fromDate, toDate time.Time, w io.Writer) error
+}
+
+const (
+	// accountCurrency is the currency of the bank accounts
+	accountCurrency = "EUR"
+	// dateLayout is the layout of ISO 20022 dates
+	dateLayout = "2006-01-02"
+)
+
+type statementService struct {
+	db           *sql.DB
+	accountRepo  account.Repository
+	transferRepo transfer.Repository
+	logger       *slog.Logger
+	policy       authz.Authorizer
+}
+
+// NewStatementService is a function that creates a new statement service
+// policy authorizes the reads of the accounts, it may be nil to allow them to every caller
+func NewStatementService(db *sql.DB, accountRepo account.Repository, transferRepo transfer.Repository, logger *slog.Logger, policy authz.Authorizer) *statementService {
+	return &statementService{
+		db:           db,
+		accountRepo:  accountRepo,
+		transferRepo: transferRepo,
+		logger:       logger,
+		policy:       policy,
+	}
+}
+
+// statementPeriod is the account of a statement and its balances over the period,
+// read in the transaction the entries of the statement are read in
+type statementPeriod struct {
+	tx      *sql.Tx
+	account *account.BankAccount
+	from    time.Time
+	to      time.Time
+	// end is the start of the day following the period
+	end     time.Time
+	within  *transfer.MovementSummary
+	opening int64
+	closing int64
+}
+
+// beginStatement opens the transaction of a statement and reads the balances of the account over
+// the period. The balances are derived from the current balance of the account by reverting the
+// movements booked since, so the statement is read in a single repeatable read transaction for
+// them to agree with the balance and the entries. The caller must roll the transaction back.
+func (s *statementService) beginStatement(ctx context.Context, iban string, fromDate, toDate time.Time) (*statementPeriod, error) {
+	p := &statementPeriod{from: truncateDay(fromDate), to: truncateDay(toDate)}
+	if p.to.Before(p.from) {
+		return nil, fmt.Errorf("%w: %s is after %s", ErrInvalidPeriod, p.from.Format(dateLayout), p.to.Format(dateLayout))
+	}
+	p.end = p.to.AddDate(0, 0, 1)
+	if err := authorize(ctx, s.policy, authz.ActionRead, authz.Resource{AccountIBAN: iban}); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	p.tx = tx
+
+	p.account, err = s.accountRepo.GetByIBAN(iban, tx)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, account.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, iban)
+		}
+		return nil, err
+	}
+
+	since, err := s.transferRepo.SummarizeMovements(ctx, tx, p.account.ID, p.end, time.Time{})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	p.within, err = s.transferRepo.SummarizeMovements(ctx, tx, p.account.ID, p.from, p.end)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	p.closing = p.account.BalanceCents - since.NetCents()
+	p.opening = p.closing - p.within.NetCents()
+	return p, nil
+}
+
+// WriteStatement reports the booked balances of the account at the start and at the end of the
+// period, and one entry per transfer debited and per returned transfer credited within it.
+func (s *statementService) WriteStatement(ctx context.Context, iban string, fromDate, toDate time.Time, w io.Writer) error {
+	p, err := s.beginStatement(ctx, iban, fromDate, toDate)
+	if err != nil {
+		return err
+	}
+	defer p.tx.Rollback()
+	acc, from, to, within := p.account, p.from, p.to, p.within
+
+	now := time.Now().UTC()
+	header := iso20022.StatementGroupHeader{
+		MessageID:        fmt.Sprintf("CAMT053-%d-%s", acc.ID, now.Format("20060102150405")),
+		CreationDateTime: now.Format(time.RFC3339),
+	}
+	statement := iso20022.Statement{
+		ID:               fmt.Sprintf("STMT-%d-%s-%s", acc.ID, from.Format("20060102"), to.Format("20060102")),
+		CreationDateTime: now.Format(time.RFC3339),
+		FromToDate:       iso20022.DateTimePeriod{From: from.Format(time.RFC3339), To: p.end.Format(time.RFC3339)},
+		Account: iso20022.StatementAccount{
+			ID:       iso20022.AccountID{IBAN: acc.IBAN},
+			Currency: accountCurrency,
+			Owner:    &iso20022.Party{Name: acc.OrganizationName},
+			Servicer: &iso20022.Agent{FinancialInstitution: iso20022.FinancialInstitution{BIC: acc.BIC}},
+		},
+		Balances: []iso20022.Balance{
+			balance(iso20022.BalanceOpeningBooked, p.opening, from),
+			balance(iso20022.BalanceClosingBooked, p.closing, to),
+		},
+		Summary: &iso20022.TransactionsTotal{
+			Credits: iso20022.NumberAndSum{NumberOfEntries: strconv.Itoa(within.Credits), Sum: formatCents(within.CreditCents)},
+			Debits:  iso20022.NumberAndSum{NumberOfEntries: strconv.Itoa(within.Debits), Sum: formatCents(within.DebitCents)},
+		},
+	}
+	sw, err := iso20022.NewCamt053Writer(w, header, statement)
+	if err != nil {
+		return err
+	}
+
+	err = s.transferRepo.ForEachMovement(ctx, p.tx, acc.ID, from, p.end, func(m transfer.Movement) error {
+		return sw.WriteEntry(statementEntry(m))
+	})
+	if err != nil {
+		return err
+	}
+	if err := p.tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Statement written", "account_id", acc.ID, "from", from.Format(dateLayout), "to", to.Format(dateLayout), "debits", within.Debits, "credits", within.Credits)
+	return sw.Close()
+}
+
+// WriteMT940Statement reports the same balances and entries as WriteStatement, as statement
+// lines whose bank reference is the one of the camt.053 entries and whose information to the
+// account owner gives the counterparty and the remittance information.
+// The statement number is the day of the year of the last day of the period.
+func (s *statementService) WriteMT940Statement(ctx context.Context, iban string, fromDate, toDate time.Time, w io.Writer) error {
+	p, err := s.beginStatement(ctx, iban, fromDate, toDate)
+	if err != nil {
+		return err
+	}
+	defer p.tx.Rollback()
+	acc, from, to := p.account, p.from, p.to
+
+	sw, err := swift.NewMT940Writer(w, swift.MT940Statement{
+		Reference:           fmt.Sprintf("%d-%s", acc.ID, to.Format("060102")),
+		Account:             acc.IBAN,
+		Number:              to.YearDay(),
+		Currency:            accountCurrency,
+		OpeningBalanceCents: p.opening,
+		OpeningDate:         from,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.transferRepo.ForEachMovement(ctx, p.tx, acc.ID, from, p.end, func(m transfer.Movement) error {
+		return sw.WriteLine(mt940Line(m))
+	})
+	if err != nil {
+		return err
+	}
+	if err := p.tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("MT940 statement written", "account_id", acc.ID, "from", from.Format(dateLayout), "to", to.Format(dateLayout), "debits", p.within.Debits, "credits", p.within.Credits)
+	return sw.Close(to)
+}
+
+// truncateDay returns the start of the UTC day of t
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// balance returns a booked balance of the given type at the end of the day of date
+func balance(balanceType string, cents int64, date time.Time) iso20022.Balance {
+	indicator := iso20022.Credit
+	if cents < 0 {
+		indicator, cents = iso20022.Debit, -cents
+	}
+	return iso20022.NewBalance(balanceType, iso20022.Amount{Value: formatCents(cents), Currency: accountCurrency}, indicator, date.Format(dateLayout))
+}
+
+// statementEntry returns the entry of a movement. Transfers are debited as issued credit transfers,
+// and returned transfers are credited back with their return reason.
+func statementEntry(m transfer.Movement) iso20022.Entry {
+	t := m.Transfer
+	bookingDate := iso20022.Date{Date: m.BookedAt.UTC().Format(dateLayout)}
+	entry := iso20022.Entry{
+		Amount:               iso20022.Amount{Value: formatCents(t.AmountCents), Currency: accountCurrency},
+		CreditDebitIndicator: iso20022.Debit,
+		Status:               iso20022.EntryStatusBooked,
+		BookingDate:          bookingDate,
+		ValueDate:            bookingDate,
+		AccountServicerRef:   "T" + strconv.FormatInt(t.ID, 10),
+		BankTransactionCode:  iso20022.NewBankTransactionCode("PMNT", "ICDT", "ESCT"),
+	}
+	details := iso20022.EntryTransaction{
+		References: iso20022.TransactionReferences{
+			AccountServicerRef:   entry.AccountServicerRef,
+			PaymentInformationID: t.PaymentInformationID,
+			EndToEndID:           t.EndToEndID,
+		},
+		RelatedParties: &iso20022.RelatedParties{
+			Creditor:        &iso20022.Party{Name: t.CounterpartyName},
+			CreditorAccount: &iso20022.Account{ID: iso20022.AccountID{IBAN: t.CounterpartyIBAN}},
+		},
+		RelatedAgents: &iso20022.RelatedAgents{
+			CreditorAgent: &iso20022.Agent{FinancialInstitution: iso20022.FinancialInstitution{BIC: t.CounterpartyBIC}},
+		},
+	}
+	if t.Description != "" {
+		details.RemittanceInformation = &iso20022.RemittanceInformation{Unstructured: []string{t.Description}}
+	}
+
+	if m.Credit {
+		entry.CreditDebitIndicator = iso20022.Credit
+		entry.AccountServicerRef = "R" + strconv.FormatInt(t.ID, 10)
+		entry.BankTransactionCode = iso20022.NewBankTransactionCode("PMNT", "ICDT", "RRTN")
+		details.References.AccountServicerRef = entry.AccountServicerRef
+		details.ReturnInformation = &iso20022.ReturnInformation{}
+		if isoReasonCode.MatchString(t.ReturnReason) {
+			details.ReturnInformation.Reason = &iso20022.StatusReasonCode{Code: t.ReturnReason}
+		} else {
+			details.ReturnInformation.AdditionalInformation = t.ReturnReason
+		}
+	}
+	entry.Details = []iso20022.EntryTransaction{details}
+	return entry
+}
+
+// mt940Line returns the statement line of a movement. Transfers are debited as transfers and
+// returned transfers are credited back as returned items, with their return reason.
+func mt940Line(m transfer.Movement) swift.MT940Line {
+	t := m.Transfer
+	bookingDate := m.BookedAt.UTC()
+	line := swift.MT940Line{
+		ValueDate:         bookingDate,
+		EntryDate:         bookingDate,
+		AmountCents:       t.AmountCents,
+		TransactionType:   "NTRF",
+		CustomerReference: t.EndToEndID,
+		BankReference:     "T" + strconv.FormatInt(t.ID, 10),
+		Information:       strings.Join([]string{t.CounterpartyName, t.CounterpartyIBAN, t.Description}, " "),
+	}
+	if m.Credit {
+		line.Credit = true
+		line.TransactionType = "NRTI"
+		line.BankReference = "R" + strconv.FormatInt(t.ID, 10)
+		line.Information = "RETURN " + t.ReturnReason + " " + line.Information
+	}
+	return line
+}
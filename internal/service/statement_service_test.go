@@ -0,0 +1,216 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/transfer"
+	"moneytransfer/mock"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStatementService_WriteStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+	mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+
+	mockDB, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	svc := service.NewStatementService(mockDB, mockAccountRepo, mockTransferRepo, slog.Default(), nil)
+	ctx := context.Background()
+
+	const iban = "FR1420041010050500013M02606"
+	from := time.Date(2024, 3, 4, 15, 30, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+	acc := &account.BankAccount{ID: 7, OrganizationName: "ACME Corp", BalanceCents: 100000, IBAN: iban, BIC: "BNPAFRPP"}
+
+	t.Run("Balances are derived from the account balance and the movements since", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(iban, gomock.Any()).Return(acc, nil)
+		mockTransferRepo.EXPECT().SummarizeMovements(ctx, gomock.Any(), int64(7), end, time.Time{}).
+			Return(&transfer.MovementSummary{Debits: 1, DebitCents: 5000}, nil)
+		mockTransferRepo.EXPECT().SummarizeMovements(ctx, gomock.Any(), int64(7), start, end).
+			Return(&transfer.MovementSummary{Debits: 1, DebitCents: 10000, Credits: 1, CreditCents: 2000}, nil)
+		mockTransferRepo.EXPECT().ForEachMovement(ctx, gomock.Any(), int64(7), start, end, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ any, _ int64, _, _ time.Time, fn func(transfer.Movement) error) error {
+				debited := newTestTransfer(10000)
+				debited.ID, debited.EndToEndID, debited.PaymentInformationID = 1, "E2E-1", "PMT-1"
+				if err := fn(transfer.Movement{Transfer: debited, BookedAt: start.Add(time.Hour)}); err != nil {
+					return err
+				}
+				returned := newTestTransfer(2000)
+				returned.ID, returned.Status, returned.ReturnReason = 2, transfer.StatusReturned, "AC04"
+				return fn(transfer.Movement{Transfer: returned, Credit: true, BookedAt: to.Add(time.Hour)})
+			})
+		sqlMock.ExpectCommit()
+
+		var out strings.Builder
+		err := svc.WriteStatement(ctx, iban, from, to, &out)
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+
+		statement := compact(out.String())
+		assert.Contains(t, statement, `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">`)
+		assert.Contains(t, statement, "<Id>STMT-7-20240304-20240305</Id>")
+		assert.Contains(t, statement, "<FrToDt><FrDtTm>2024-03-04T00:00:00Z</FrDtTm><ToDtTm>2024-03-06T00:00:00Z</ToDtTm></FrToDt>")
+		assert.Contains(t, statement, "<Acct><Id><IBAN>FR1420041010050500013M02606</IBAN></Id><Ccy>EUR</Ccy><Ownr><Nm>ACME Corp</Nm></Ownr>")
+		assert.Contains(t, statement, `<Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1130.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2024-03-04</Dt></Dt>`)
+		assert.Contains(t, statement, `<Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1050.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2024-03-05</Dt></Dt>`)
+		assert.Contains(t, statement, "<TtlCdtNtries><NbOfNtries>1</NbOfNtries><Sum>20.00</Sum></TtlCdtNtries><TtlDbtNtries><NbOfNtries>1</NbOfNtries><Sum>100.00</Sum></TtlDbtNtries>")
+		assert.Contains(t, statement, `<Amt Ccy="EUR">100.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts><BookgDt><Dt>2024-03-04</Dt></BookgDt>`)
+		assert.Contains(t, statement, "<Refs><AcctSvcrRef>T1</AcctSvcrRef><PmtInfId>PMT-1</PmtInfId><EndToEndId>E2E-1</EndToEndId></Refs>")
+		assert.Contains(t, statement, "<RmtInf><Ustrd>Test transfer</Ustrd></RmtInf>")
+		assert.Contains(t, statement, `<Amt Ccy="EUR">20.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts>BOOK</Sts><BookgDt><Dt>2024-03-05</Dt></BookgDt>`)
+		assert.Contains(t, statement, "<SubFmlyCd>RRTN</SubFmlyCd>")
+		assert.Contains(t, statement, "<RtrInf><Rsn><Cd>AC04</Cd></Rsn></RtrInf>")
+	})
+
+	t.Run("Negative balances are debits", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(iban, gomock.Any()).Return(&account.BankAccount{ID: 7, IBAN: iban, BalanceCents: -150}, nil)
+		mockTransferRepo.EXPECT().SummarizeMovements(ctx, gomock.Any(), int64(7), gomock.Any(), gomock.Any()).
+			Return(&transfer.MovementSummary{}, nil).Times(2)
+		mockTransferRepo.EXPECT().ForEachMovement(ctx, gomock.Any(), int64(7), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		sqlMock.ExpectCommit()
+
+		var out strings.Builder
+		assert.NoError(t, svc.WriteStatement(ctx, iban, from, from, &out))
+		assert.Contains(t, compact(out.String()), `<Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1.50</Amt><CdtDbtInd>DBIT</CdtDbtInd>`)
+	})
+
+	t.Run("Period ending before it starts", func(t *testing.T) {
+		var out strings.Builder
+		err := svc.WriteStatement(ctx, iban, to, from, &out)
+		assert.ErrorIs(t, err, service.ErrInvalidPeriod)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("Unknown account", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN("DE89370400440532013000", gomock.Any()).Return(nil, account.ErrNotFound)
+		sqlMock.ExpectRollback()
+
+		var out strings.Builder
+		err := svc.WriteStatement(ctx, "DE89370400440532013000", from, to, &out)
+		assert.ErrorIs(t, err, service.ErrAccountNotFound)
+		assert.Empty(t, out.String())
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		repoErr := errors.New("connection reset")
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(iban, gomock.Any()).Return(acc, nil)
+		mockTransferRepo.EXPECT().SummarizeMovements(ctx, gomock.Any(), int64(7), end, time.Time{}).Return(nil, repoErr)
+		sqlMock.ExpectRollback()
+
+		var out strings.Builder
+		err := svc.WriteStatement(ctx, iban, from, to, &out)
+		assert.ErrorIs(t, err, repoErr)
+		assert.Empty(t, out.String())
+	})
+}
+
+func TestStatementService_WriteMT940Statement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+	mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+
+	mockDB, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	svc := service.NewStatementService(mockDB, mockAccountRepo, mockTransferRepo, slog.Default(), nil)
+	ctx := context.Background()
+
+	const iban = "FR1420041010050500013M02606"
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Statement lines of the movements", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(iban, gomock.Any()).Return(&account.BankAccount{ID: 7, BalanceCents: 100000, IBAN: iban}, nil)
+		mockTransferRepo.EXPECT().SummarizeMovements(ctx, gomock.Any(), int64(7), end, time.Time{}).
+			Return(&transfer.MovementSummary{Debits: 1, DebitCents: 5000}, nil)
+		mockTransferRepo.EXPECT().SummarizeMovements(ctx, gomock.Any(), int64(7), from, end).
+			Return(&transfer.MovementSummary{Debits: 1, DebitCents: 10000, Credits: 1, CreditCents: 2000}, nil)
+		mockTransferRepo.EXPECT().ForEachMovement(ctx, gomock.Any(), int64(7), from, end, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ any, _ int64, _, _ time.Time, fn func(transfer.Movement) error) error {
+				debited := newTestTransfer(10000)
+				debited.ID, debited.EndToEndID = 1, "E2E-1"
+				if err := fn(transfer.Movement{Transfer: debited, BookedAt: from.Add(time.Hour)}); err != nil {
+					return err
+				}
+				returned := newTestTransfer(2000)
+				returned.ID, returned.Status, returned.ReturnReason = 2, transfer.StatusReturned, "AC04"
+				return fn(transfer.Movement{Transfer: returned, Credit: true, BookedAt: to.Add(time.Hour)})
+			})
+		sqlMock.ExpectCommit()
+
+		var out strings.Builder
+		err := svc.WriteMT940Statement(ctx, iban, from, to, &out)
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+
+		statement := out.String()
+		assert.True(t, strings.HasPrefix(statement, ":20:7-240305\r\n:25:FR1420041010050500013M02606\r\n:28C:65/1\r\n:60F:C240304EUR1130,00\r\n"), statement)
+		assert.Contains(t, statement, ":61:2403040304D100,00NTRFE2E-1//T1\r\n:86:John Doe DE89370400440532013000 Test transfer\r\n")
+		assert.Contains(t, statement, ":61:2403050305C20,00NRTINONREF//R2\r\n:86:RETURN AC04 ")
+		assert.True(t, strings.HasSuffix(statement, ":62F:C240305EUR1050,00\r\n-\r\n"), statement)
+	})
+
+	t.Run("Unknown account", func(t *testing.T) {
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN("DE89370400440532013000", gomock.Any()).Return(nil, account.ErrNotFound)
+		sqlMock.ExpectRollback()
+
+		var out strings.Builder
+		err := svc.WriteMT940Statement(ctx, "DE89370400440532013000", from, to, &out)
+		assert.ErrorIs(t, err, service.ErrAccountNotFound)
+		assert.Empty(t, out.String())
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestStatementService_Authorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPolicy := mock.NewAuthorizerMock(ctrl)
+	mockDB, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	// The account is never read when the policy denies it, so that its existence is not revealed
+	svc := service.NewStatementService(mockDB, mock.NewAccountRepositoryMock(ctrl), mock.NewTransferRepositoryMock(ctrl), slog.Default(), mockPolicy)
+	const iban = "FR1420041010050500013M02606"
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	mockPolicy.EXPECT().Authorize(gomock.Any(), authz.ActionRead, authz.Resource{AccountIBAN: iban}).
+		Return(fmt.Errorf("%w: api_key:7 may not read account %s", authz.ErrDenied, iban))
+
+	var out strings.Builder
+	err = svc.WriteStatement(context.Background(), iban, day, day, &out)
+	assert.ErrorIs(t, err, service.ErrPermissionDenied)
+	assert.Empty(t, out.String())
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
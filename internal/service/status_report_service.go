@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"time"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/iso20022"
+	"moneytransfer/internal/transfer"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=status_report_service.go -destination=../../mock/status_report_service_mock.go -package=mock -mock_names=StatusReportService=StatusReportServiceMock
+type StatusReportService interface {
+	// WriteStatusReport writes the pain.002 payment status report of the batch to w.
+	// The batch is looked up before anything is written, so that w is left untouched
+	// when ErrBatchNotFound is returned.
+	WriteStatusReport(ctx context.Context, batchID int64, w io.Writer) error
+}
+
+const (
+	// notProvided is the end-to-end ID reported for transfers that were given none
+	notProvided = "NOTPROVIDED"
+	// statusReportPageSize is the number of transfers read at once while writing a status report
+	statusReportPageSize = 1000
+)
+
+// statusReasonCodes maps the error codes of failed batches to ISO 20022 status reason codes,
+// other errors are reported with the NARR code and their detail
+var statusReasonCodes = map[string]string{
+	CodeInsufficientFunds: "AM04",
+	CodeAccountNotFound:   "AC02",
+	CodeAmountOverflow:    "AM02",
+}
+
+// isoReasonCode matches return reasons that are ISO 20022 reason codes, such as AC04
+var isoReasonCode = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}$`)
+
+type statusReportService struct {
+	accountRepo  account.Repository
+	batchRepo    batch.Repository
+	transferRepo transfer.Repository
+	logger       *slog.Logger
+	policy       authz.Authorizer
+}
+
+// NewStatusReportService is a function that creates a new status report service
+// policy authorizes the reports as reads of their batches, it may be nil to allow them to every caller
+func NewStatusReportService(accountRepo account.Repository, batchRepo batch.Repository, transferRepo transfer.Repository, logger *slog.Logger, policy authz.Authorizer) *statusReportService {
+	return &statusReportService{
+		accountRepo:  accountRepo,
+		batchRepo:    batchRepo,
+		transferRepo: transferRepo,
+		logger:       logger,
+		policy:       policy,
+	}
+}
+
+// WriteStatusReport reports the group status of the batch and, once it is executed, the status
+// of every transfer by payment information block. Received batches are pending, failed batches
+// are rejected as a whole, and executed batches are accepted except for their returned transfers.
+// The report references the pain.001 message the batch was imported from; batches of other
+// requests are referenced by their ID. Authenticated callers only get the reports of the batches
+// of their organization's accounts, whether or not a policy is configured.
+func (s *statusReportService) WriteStatusReport(ctx context.Context, batchID int64, w io.Writer) error {
+	b, err := s.batchRepo.Get(ctx, batchID)
+	if err != nil {
+		if errors.Is(err, batch.ErrNotFound) {
+			return fmt.Errorf("%w: %d", ErrBatchNotFound, batchID)
+		}
+		return err
+	}
+	if err := checkAccountOwner(ctx, s.accountRepo, b.OrganizationIBAN); err != nil {
+		return err
+	}
+	if err := authorize(ctx, s.policy, authz.ActionRead, authz.Resource{AccountIBAN: b.OrganizationIBAN}); err != nil {
+		return err
+	}
+
+	var counts []transfer.PaymentInformationCount
+	if b.Status == batch.StatusExecuted {
+		counts, err = s.transferRepo.CountByPaymentInformation(ctx, b.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	originalMessageID := b.MessageID
+	if originalMessageID == "" {
+		originalMessageID = strconv.FormatInt(b.ID, 10)
+	}
+	originalMessageName := b.MessageName
+	if originalMessageName == "" {
+		originalMessageName = iso20022.MessageName(iso20022.NamespacePain001V03)
+	}
+
+	group := iso20022.OriginalGroupStatus{
+		OriginalMessageID:            originalMessageID,
+		OriginalMessageName:          originalMessageName,
+		OriginalNumberOfTransactions: strconv.Itoa(b.TransferCount),
+		OriginalControlSum:           formatCents(b.TotalCents),
+	}
+	switch b.Status {
+	case batch.StatusReceived:
+		group.GroupStatus = iso20022.StatusPending
+	case batch.StatusFailed:
+		group.GroupStatus = iso20022.StatusRejected
+		group.StatusReasons = []iso20022.StatusReason{batchStatusReason(b)}
+	default:
+		var total transfer.PaymentInformationCount
+		for _, c := range counts {
+			total.Transfers += c.Transfers
+			total.Returned += c.Returned
+		}
+		group.GroupStatus = countStatus(total)
+	}
+
+	now := time.Now().UTC()
+	header := iso20022.StatusReportGroupHeader{
+		MessageID:        fmt.Sprintf("STS-%d-%s", b.ID, now.Format("20060102150405")),
+		CreationDateTime: now.Format(time.RFC3339),
+	}
+	pw, err := iso20022.NewPain002Writer(w, iso20022.StatusReportNamespace(b.MessageName), header, group)
+	if err != nil {
+		return err
+	}
+
+	if len(counts) > 0 {
+		if err := s.writeTransactions(ctx, pw, b.ID, originalMessageID, counts); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("Status report written", "batch_id", b.ID, "group_status", group.GroupStatus)
+	return pw.Close()
+}
+
+// writeTransactions writes the status of the transfers of the batch by payment information block.
+// Transfers without a block, which were not imported from a pain.001 message, are reported in a
+// block named after the original message.
+func (s *statusReportService) writeTransactions(ctx context.Context, pw *iso20022.Pain002Writer, batchID int64, originalMessageID string, counts []transfer.PaymentInformationCount) error {
+	blocks := make(map[string]transfer.PaymentInformationCount, len(counts))
+	for _, c := range counts {
+		blocks[c.PaymentInformationID] = c
+	}
+
+	var afterID int64
+	current, started := "", false
+	for {
+		transfers, err := s.transferRepo.ListByBatch(ctx, batchID, afterID, statusReportPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range transfers {
+			if !started || t.PaymentInformationID != current {
+				current, started = t.PaymentInformationID, true
+				c := blocks[current]
+				id := current
+				if id == "" {
+					id = originalMessageID
+				}
+				err := pw.StartPaymentInformation(iso20022.PaymentInformationStatus{
+					OriginalPaymentInformationID: id,
+					OriginalNumberOfTransactions: strconv.Itoa(c.Transfers),
+					Status:                       countStatus(c),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if err := pw.WriteTransaction(transactionStatus(t)); err != nil {
+				return err
+			}
+		}
+
+		if len(transfers) < statusReportPageSize {
+			return nil
+		}
+		afterID = transfers[len(transfers)-1].ID
+	}
+}
+
+// countStatus returns the status of a group of executed transfers of which some may have been returned
+func countStatus(c transfer.PaymentInformationCount) string {
+	switch {
+	case c.Returned == 0:
+		return iso20022.StatusAccepted
+	case c.Returned == c.Transfers:
+		return iso20022.StatusRejected
+	default:
+		return iso20022.StatusPartiallyAccepted
+	}
+}
+
+// batchStatusReason explains why the batch was rejected
+func batchStatusReason(b *batch.Batch) iso20022.StatusReason {
+	if code, ok := statusReasonCodes[b.ErrorCode]; ok {
+		return iso20022.NewStatusReason(code, "")
+	}
+	return iso20022.NewStatusReason(iso20022.ReasonNarrative, b.ErrorDetail)
+}
+
+// transactionStatus returns the status of the transfer, returned transfers are rejected
+// with their return reason
+func transactionStatus(t transfer.Transfer) iso20022.TransactionStatus {
+	endToEndID := t.EndToEndID
+	if endToEndID == "" {
+		endToEndID = notProvided
+	}
+
+	status := iso20022.TransactionStatus{
+		StatusID:           strconv.FormatInt(t.ID, 10),
+		OriginalEndToEndID: endToEndID,
+		Status:             iso20022.StatusAccepted,
+		OriginalTransactionReference: &iso20022.OriginalTransactionReference{
+			Amount:          iso20022.TransactionAmount{Instructed: iso20022.Amount{Value: formatCents(t.AmountCents), Currency: accountCurrency}},
+			CreditorAgent:   &iso20022.Agent{FinancialInstitution: iso20022.FinancialInstitution{BIC: t.CounterpartyBIC}},
+			CreditorAccount: &iso20022.Account{ID: iso20022.AccountID{IBAN: t.CounterpartyIBAN}},
+		},
+	}
+	if t.Status == transfer.StatusReturned {
+		status.Status = iso20022.StatusRejected
+		if isoReasonCode.MatchString(t.ReturnReason) {
+			status.StatusReasons = []iso20022.StatusReason{iso20022.NewStatusReason(t.ReturnReason, "")}
+		} else {
+			status.StatusReasons = []iso20022.StatusReason{iso20022.NewStatusReason(iso20022.ReasonNarrative, t.ReturnReason)}
+		}
+	}
+	return status
+}
+
+// formatCents formats an amount in cents as a decimal amount in euros
+func formatCents(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
@@ -0,0 +1,146 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"moneytransfer/internal/account"
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/transfer"
+	"moneytransfer/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStatusReportService_WriteStatusReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockBatchRepo := mock.NewBatchRepositoryMock(ctrl)
+	mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+	mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+	svc := service.NewStatusReportService(mockAccountRepo, mockBatchRepo, mockTransferRepo, slog.Default(), nil)
+	ctx := context.Background()
+
+	t.Run("Executed batch with a returned transfer is partially accepted", func(t *testing.T) {
+		mockBatchRepo.EXPECT().Get(ctx, int64(testBatchID)).Return(&batch.Batch{
+			ID:            testBatchID,
+			Status:        batch.StatusExecuted,
+			TransferCount: 3,
+			TotalCents:    15050,
+			MessageID:     "MSG-1",
+			MessageName:   "pain.001.001.03",
+		}, nil)
+		mockTransferRepo.EXPECT().CountByPaymentInformation(ctx, int64(testBatchID)).Return([]transfer.PaymentInformationCount{
+			{PaymentInformationID: "PMT-1", Transfers: 2, Returned: 1},
+			{PaymentInformationID: "PMT-2", Transfers: 1},
+		}, nil)
+		returned := transfer.Transfer{ID: 2, AmountCents: 50, EndToEndID: "E2E-2", PaymentInformationID: "PMT-1", Status: transfer.StatusReturned, ReturnReason: "AC04"}
+		mockTransferRepo.EXPECT().ListByBatch(ctx, int64(testBatchID), int64(0), gomock.Any()).Return([]transfer.Transfer{
+			{ID: 1, AmountCents: 10000, CounterpartyBIC: "DEUTDEFF", CounterpartyIBAN: "DE89370400440532013000", EndToEndID: "E2E-1", PaymentInformationID: "PMT-1", Status: transfer.StatusExecuted},
+			returned,
+			{ID: 3, AmountCents: 5000, PaymentInformationID: "PMT-2", Status: transfer.StatusExecuted},
+		}, nil)
+
+		var out strings.Builder
+		err := svc.WriteStatusReport(ctx, testBatchID, &out)
+		assert.NoError(t, err)
+
+		report := compact(out.String())
+		assert.Contains(t, report, `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.03">`)
+		assert.Contains(t, report, "<OrgnlMsgId>MSG-1</OrgnlMsgId><OrgnlMsgNmId>pain.001.001.03</OrgnlMsgNmId><OrgnlNbOfTxs>3</OrgnlNbOfTxs><OrgnlCtrlSum>150.50</OrgnlCtrlSum><GrpSts>PART</GrpSts>")
+		assert.Contains(t, report, "<OrgnlPmtInfId>PMT-1</OrgnlPmtInfId><OrgnlNbOfTxs>2</OrgnlNbOfTxs><PmtInfSts>PART</PmtInfSts>")
+		assert.Contains(t, report, "<OrgnlEndToEndId>E2E-1</OrgnlEndToEndId><TxSts>ACCP</TxSts>")
+		assert.Contains(t, report, `<InstdAmt Ccy="EUR">100.00</InstdAmt>`)
+		assert.Contains(t, report, "<OrgnlEndToEndId>E2E-2</OrgnlEndToEndId><TxSts>RJCT</TxSts><StsRsnInf><Rsn><Cd>AC04</Cd></Rsn></StsRsnInf>")
+		assert.Contains(t, report, "<OrgnlPmtInfId>PMT-2</OrgnlPmtInfId><OrgnlNbOfTxs>1</OrgnlNbOfTxs><PmtInfSts>ACCP</PmtInfSts>")
+		assert.Contains(t, report, "<OrgnlEndToEndId>NOTPROVIDED</OrgnlEndToEndId>")
+	})
+
+	t.Run("Failed batch is rejected with the reason code of its error", func(t *testing.T) {
+		mockBatchRepo.EXPECT().Get(ctx, int64(testBatchID)).Return(&batch.Batch{
+			ID:            testBatchID,
+			Status:        batch.StatusFailed,
+			TransferCount: 1,
+			TotalCents:    100,
+			ErrorCode:     service.CodeInsufficientFunds,
+			ErrorDetail:   "insufficient funds: 100 cents required, 0 cents available",
+		}, nil)
+
+		var out strings.Builder
+		err := svc.WriteStatusReport(ctx, testBatchID, &out)
+		assert.NoError(t, err)
+
+		report := compact(out.String())
+		assert.Contains(t, report, "<OrgnlMsgId>42</OrgnlMsgId>")
+		assert.Contains(t, report, "<GrpSts>RJCT</GrpSts><StsRsnInf><Rsn><Cd>AM04</Cd></Rsn></StsRsnInf>")
+		assert.NotContains(t, report, "<TxInfAndSts>")
+	})
+
+	t.Run("Failed batch without a reason code is explained by its detail", func(t *testing.T) {
+		mockBatchRepo.EXPECT().Get(ctx, int64(testBatchID)).Return(&batch.Batch{
+			ID:          testBatchID,
+			Status:      batch.StatusFailed,
+			ErrorCode:   service.CodeConcurrentUpdate,
+			ErrorDetail: "account was updated concurrently",
+		}, nil)
+
+		var out strings.Builder
+		assert.NoError(t, svc.WriteStatusReport(ctx, testBatchID, &out))
+		assert.Contains(t, compact(out.String()), "<Rsn><Cd>NARR</Cd></Rsn><AddtlInf>account was updated concurrently</AddtlInf>")
+	})
+
+	t.Run("Received batch is pending", func(t *testing.T) {
+		mockBatchRepo.EXPECT().Get(ctx, int64(testBatchID)).Return(&batch.Batch{ID: testBatchID, Status: batch.StatusReceived, MessageName: "pain.001.001.09"}, nil)
+
+		var out strings.Builder
+		assert.NoError(t, svc.WriteStatusReport(ctx, testBatchID, &out))
+		assert.Contains(t, out.String(), "pain.002.001.10")
+		assert.Contains(t, out.String(), "<GrpSts>PDNG</GrpSts>")
+	})
+
+	t.Run("Unknown batch", func(t *testing.T) {
+		mockBatchRepo.EXPECT().Get(ctx, int64(1)).Return(nil, batch.ErrNotFound)
+
+		var out strings.Builder
+		err := svc.WriteStatusReport(ctx, 1, &out)
+		assert.ErrorIs(t, err, service.ErrBatchNotFound)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("Batch of another organization", func(t *testing.T) {
+		// The ownership of the batch is checked without a policy too
+		ctx := auth.NewContext(ctx, auth.Principal{OrganizationName: "Test Org", Subject: "api_key:7"})
+		mockBatchRepo.EXPECT().Get(ctx, int64(testBatchID)).Return(&batch.Batch{ID: testBatchID, OrganizationIBAN: "FR1420041010050500013M02606", Status: batch.StatusExecuted}, nil)
+		mockAccountRepo.EXPECT().GetByIBAN("FR1420041010050500013M02606", nil).Return(&account.BankAccount{ID: 2, OrganizationName: "Other Org"}, nil)
+
+		var out strings.Builder
+		err := svc.WriteStatusReport(ctx, testBatchID, &out)
+		assert.ErrorIs(t, err, service.ErrAccountNotOwned)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		repoErr := errors.New("connection reset")
+		mockBatchRepo.EXPECT().Get(ctx, int64(testBatchID)).Return(&batch.Batch{ID: testBatchID, Status: batch.StatusExecuted}, nil)
+		mockTransferRepo.EXPECT().CountByPaymentInformation(ctx, int64(testBatchID)).Return(nil, repoErr)
+
+		var out strings.Builder
+		err := svc.WriteStatusReport(ctx, testBatchID, &out)
+		assert.ErrorIs(t, err, repoErr)
+		assert.Empty(t, out.String())
+	})
+}
+
+// compact removes the indentation of an XML document so that nested elements can be matched on one line
+func compact(document string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(document, "\n") {
+		b.WriteString(strings.TrimSpace(line))
+	}
+	return b.String()
+}
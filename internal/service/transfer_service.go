@@ -4,28 +4,65 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log/slog"
 	"math"
-	"strings"
+	"strconv"
 	"time"
 
 	"moneytransfer/internal/account"
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/event"
 	"moneytransfer/internal/tools"
 	"moneytransfer/internal/transfer"
-)
 
-var ErrInsufficientFunds = errors.New("insufficient funds")
+	"github.com/lib/pq"
+)
 
 type BulkTransferRequest struct {
 	OrganizationName string
 	OrganizationBIC  string
 	OrganizationIBAN string
-	Transfers        []transfer.Transfer
+	// MessageID and MessageName identify the payment message the request was imported from, if any
+	MessageID   string
+	MessageName string
+	// Transfers holds the transfers of a request that fits in memory
+	Transfers []transfer.Transfer
+	// Source streams the transfers of a request that is too large to be held
+	// in memory. It takes precedence over Transfers when set.
+	Source TransferSource
+}
+
+// TransferSource streams the transfers of a bulk transfer request to yield one at a time.
+// A source is read more than once, at least once to validate the request and once per
+// attempt to execute it, and must produce the same transfers every time.
+// An error returned by yield stops the source and must be returned as is.
+type TransferSource func(yield func(transfer.Transfer) error) error
+
+// forEachTransfer calls fn for every transfer of the request, in order
+func (r BulkTransferRequest) forEachTransfer(fn func(transfer.Transfer) error) error {
+	if r.Source != nil {
+		return r.Source(fn)
+	}
+	for _, t := range r.Transfers {
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 //go:generate go run go.uber.org/mock/mockgen -source=transfer_service.go -destination=../../mock/service_mock.go -package=mock -mock_names=TransferService=TransferServiceMock
 type TransferService interface {
-	BulkTransfer(ctx context.Context, req BulkTransferRequest) error
+	// BulkTransfer executes the request and returns the batch that records it.
+	// The batch is also returned with the error of a request that was rejected
+	// after it was received, in which case it is in the failed state.
+	BulkTransfer(ctx context.Context, req BulkTransferRequest) (*batch.Batch, error)
+	GetBatch(ctx context.Context, id int64) (*batch.Batch, error)
+	ReturnTransfer(ctx context.Context, id int64, reason string) (*transfer.Transfer, error)
 }
 
 // RetryConfig is a struct that contains the retry configuration for the transfer service
@@ -38,39 +75,268 @@ type RetryConfig struct {
 type transferService struct {
 	accountRepo  account.Repository
 	transferRepo transfer.Repository
+	batchRepo    batch.Repository
+	eventRepo    event.Repository
+	auditRepo    audit.Repository
+	broker       *event.Broker
 	db           *sql.DB
 	logger       *slog.Logger
 	retryConfig  RetryConfig
+	chunkSize    int
+	policy       authz.Authorizer
 }
 
 // NewTransferService is a function that creates a new transfer service
-func NewTransferService(db *sql.DB, logger *slog.Logger, accountRepo account.Repository, transferRepo transfer.Repository, retryConfig RetryConfig) *transferService {
+// chunkSize is the number of transfers inserted into the database at once
+// broker is notified whenever events are committed, it may be nil
+// auditRepo records the bulk transfers and returns in their transactions, it may be nil to not audit them
+// policy authorizes the actions of authenticated callers, it may be nil to only restrict them to their accounts
+func NewTransferService(db *sql.DB, logger *slog.Logger, accountRepo account.Repository, transferRepo transfer.Repository, batchRepo batch.Repository, eventRepo event.Repository, auditRepo audit.Repository, broker *event.Broker, retryConfig RetryConfig, chunkSize int, policy authz.Authorizer) *transferService {
 	return &transferService{
 		accountRepo:  accountRepo,
 		transferRepo: transferRepo,
+		batchRepo:    batchRepo,
+		eventRepo:    eventRepo,
+		auditRepo:    auditRepo,
+		broker:       broker,
 		db:           db,
 		logger:       logger,
 		retryConfig:  retryConfig,
+		chunkSize:    chunkSize,
+		policy:       policy,
 	}
 }
 
 // BulkTransfer is a function that processes a bulk transfer request
 // It retries the transfer if the database transaction fails due to serialization conflicts
 // It returns an error if the transfer fails after the maximum number of retries
-func (s *transferService) BulkTransfer(ctx context.Context, req BulkTransferRequest) error {
-	s.logger.Info("Processing bulk transfer request", "organization_bic", req.OrganizationBIC, "organization_iban", req.OrganizationIBAN)
+func (s *transferService) BulkTransfer(ctx context.Context, req BulkTransferRequest) (*batch.Batch, error) {
+	s.logger.Info("Processing bulk transfer request", append(principalAttrs(ctx), "organization_bic", req.OrganizationBIC, "organization_iban", req.OrganizationIBAN)...)
 
-	var err error
+	// Authenticated callers are rejected before anything is recorded, so that
+	// the events of another organization never mention their requests
+	if err := checkAccountOwner(ctx, s.accountRepo, req.OrganizationIBAN); err != nil {
+		s.logger.Warn("Bulk transfer from an account of another organization rejected", "error", err)
+		s.auditRejection(ctx, newBulkTransferAuditEvent(ctx, req), err)
+		return nil, err
+	}
+	if err := s.authorize(ctx, authz.ActionSubmit, authz.Resource{AccountIBAN: req.OrganizationIBAN}); err != nil {
+		s.auditRejection(ctx, newBulkTransferAuditEvent(ctx, req), err)
+		return nil, err
+	}
+
+	// The request is read once up front so that invalid amounts are rejected
+	// before a transaction is started
+	totalTransfer, count, err := calculateTotalTransfer(req)
+	if err != nil {
+		s.logger.Error("Failed to calculate total transfer", "error", err)
+		s.auditRejection(ctx, newBulkTransferAuditEvent(ctx, req), err)
+		return nil, err
+	}
+
+	b, err := s.receiveBatch(ctx, req, count, totalTransfer)
+	if err != nil {
+		s.logger.Error("Failed to record batch", "error", err)
+		return nil, err
+	}
+
+	err = s.withRetry(ctx, "bulk transfer", func() error {
+		return s.executeBulkTransfer(ctx, req, b)
+	})
+	if err != nil {
+		s.failBatch(ctx, b, err)
+		return b, err
+	}
+	s.logger.Info("Bulk transfer processed successfully", append(principalAttrs(ctx), "organization_bic", req.OrganizationBIC, "organization_iban", req.OrganizationIBAN, "batch_id", b.ID)...)
+	return b, nil
+}
+
+// principalAttrs returns the log attributes of the principal of ctx, none for trusted callers
+func principalAttrs(ctx context.Context) []any {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return []any{"principal", p.Subject, "principal_organization", p.OrganizationName, "user", p.User}
+}
+
+// checkAccountOwner returns ErrAccountNotOwned unless the account of the IBAN belongs
+// to the organization of the principal of ctx. Trusted callers may debit any account.
+// An unknown IBAN is reported the same way, so that callers cannot probe the IBANs
+// of other organizations.
+func checkAccountOwner(ctx context.Context, accountRepo account.Repository, iban string) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	bankAccount, err := accountRepo.GetByIBAN(iban, nil)
+	if err != nil && !errors.Is(err, account.ErrNotFound) {
+		return err
+	}
+	if err != nil || bankAccount.OrganizationName != p.OrganizationName {
+		return fmt.Errorf("%w: %s", ErrAccountNotOwned, iban)
+	}
+	return nil
+}
+
+// transferNotFound returns the error of a transfer that does not exist. Principals are denied
+// it like the transfers of other organizations, so that they cannot tell which IDs exist.
+func transferNotFound(ctx context.Context, id int64) error {
+	if _, ok := auth.FromContext(ctx); ok {
+		return fmt.Errorf("%w: transfer %d", ErrPermissionDenied, id)
+	}
+	return fmt.Errorf("%w: %d", ErrTransferNotFound, id)
+}
+
+// authorize returns an error unless policy allows the principal of ctx the action on the resource,
+// every action is allowed without a policy. The services check it themselves, so that every
+// frontend enforces the same rules.
+func authorize(ctx context.Context, policy authz.Authorizer, action authz.Action, resource authz.Resource) error {
+	if policy == nil {
+		return nil
+	}
+	return policy.Authorize(ctx, action, resource)
+}
+
+// authorize returns an error unless the policy allows the action, every action is allowed without a policy
+func (s *transferService) authorize(ctx context.Context, action authz.Action, resource authz.Resource) error {
+	return authorize(ctx, s.policy, action, resource)
+}
+
+// GetBatch returns the batch with the given id
+func (s *transferService) GetBatch(ctx context.Context, id int64) (*batch.Batch, error) {
+	b, err := s.batchRepo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, batch.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrBatchNotFound, id)
+		}
+		return nil, err
+	}
+	if err := s.authorize(ctx, authz.ActionRead, authz.Resource{AccountIBAN: b.OrganizationIBAN}); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ReturnTransfer marks an executed transfer returned by the receiving bank and credits
+// its amount back to the bank account, recording a transfer.returned event
+func (s *transferService) ReturnTransfer(ctx context.Context, id int64, reason string) (*transfer.Transfer, error) {
+	s.logger.Info("Processing transfer return", "transfer_id", id, "reason", reason)
+
+	var returned *transfer.Transfer
+	err := s.withRetry(ctx, "transfer return", func() error {
+		var err error
+		returned, err = s.executeReturnTransfer(ctx, id, reason)
+		return err
+	})
+	if err != nil {
+		e := audit.NewEvent(ctx, audit.ActionTransferReturn)
+		e.ResourceType = "transfer"
+		e.ResourceID = strconv.FormatInt(id, 10)
+		s.auditRejection(ctx, e, err)
+		return nil, err
+	}
+
+	s.logger.Info("Transfer returned successfully", "transfer_id", id, "amount_cents", returned.AmountCents)
+	return returned, nil
+}
+
+func (s *transferService) executeReturnTransfer(ctx context.Context, id int64, reason string) (*transfer.Transfer, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t, err := s.transferRepo.Get(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, transfer.ErrNotFound) {
+			return nil, transferNotFound(ctx, id)
+		}
+		return nil, err
+	}
+
+	// The scope of the principal is checked before the status of the transfer, so that
+	// the transfers of other organizations reveal nothing
+	bankAccount, err := s.accountRepo.Get(t.BankAccountID, tx)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := auth.FromContext(ctx); ok && bankAccount.OrganizationName != p.OrganizationName {
+		return nil, fmt.Errorf("%w: transfer %d", ErrPermissionDenied, id)
+	}
+	if err := s.authorize(ctx, authz.ActionReturn, authz.Resource{AccountIBAN: bankAccount.IBAN}); err != nil {
+		return nil, err
+	}
+
+	transferBefore := newTransferSnapshot(t)
+	if err := t.Return(reason, time.Now().UTC()); err != nil {
+		return nil, fmt.Errorf("%w: transfer %d is %s", ErrNotReturnable, id, t.Status)
+	}
+
+	if bankAccount.BalanceCents > math.MaxInt64-t.AmountCents {
+		return nil, ErrAmountOverflow
+	}
+	accountBefore := newAccountSnapshot(bankAccount)
+	bankAccount.BalanceCents += t.AmountCents
+
+	if err := s.accountRepo.Update(bankAccount, tx); err != nil {
+		return nil, err
+	}
+	if err := s.transferRepo.UpdateStatus(ctx, tx, t); err != nil {
+		return nil, err
+	}
+	if err := s.transferRepo.ChainReturn(ctx, tx, t, bankAccount.BalanceCents); err != nil {
+		return nil, err
+	}
+
+	// The event is read by the event streams and copied into the webhook deliveries, which are
+	// not encrypted, so it carries the snapshot of the transfer without its counterparty details
+	e, err := event.NewEvent(event.TypeTransferReturned, bankAccount.OrganizationName, bankAccount.IBAN, newTransferSnapshot(t))
+	if err != nil {
+		return nil, err
+	}
+	e.BankAccountID = bankAccount.ID
+	e.BatchID = t.BatchID
+	e.TransferID = t.ID
+	if err := s.eventRepo.Append(ctx, tx, e); err != nil {
+		return nil, err
+	}
+
+	ae := audit.NewEvent(ctx, audit.ActionTransferReturn)
+	ae.OrganizationName = bankAccount.OrganizationName
+	ae.AccountIBAN = bankAccount.IBAN
+	ae.ResourceType = "transfer"
+	ae.ResourceID = strconv.FormatInt(t.ID, 10)
+	if err := ae.SetSnapshots(
+		auditState{Account: accountBefore, Transfer: transferBefore},
+		auditState{Account: newAccountSnapshot(bankAccount), Transfer: newTransferSnapshot(t)},
+	); err != nil {
+		return nil, err
+	}
+	if err := s.appendAudit(ctx, tx, ae); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.broker.Notify()
+	return t, nil
+}
 
+// withRetry runs fn, retrying when its transaction fails due to serialization conflicts.
+// op names the operation in the logs.
+func (s *transferService) withRetry(ctx context.Context, op string, fn func() error) error {
+	var err error
 	for attempt := 0; attempt < s.retryConfig.MaxRetries; attempt++ {
-		err = s.executeBulkTransfer(ctx, req)
+		err = fn()
 		if err == nil {
-			s.logger.Info("Bulk transfer processed successfully", "organization_bic", req.OrganizationBIC, "organization_iban", req.OrganizationIBAN, "attempt", attempt+1)
 			return nil
 		}
 
 		if !isRetryableError(err) {
-			s.logger.Error("Non-retryable error occurred during bulk transfer", "error", err, "attempt", attempt+1)
+			s.logger.Error("Non-retryable error occurred", "operation", op, "error", err, "attempt", attempt+1)
 			return err
 		}
 
@@ -79,7 +345,7 @@ func (s *transferService) BulkTransfer(ctx context.Context, req BulkTransferRequ
 		}
 
 		delay := tools.CalculateBackoff(s.retryConfig.BaseDelay, s.retryConfig.MaxDelay, attempt)
-		s.logger.Warn("Retryable error occurred during bulk transfer, retrying", "error", err, "attempt", attempt+1, "retry_after", delay)
+		s.logger.Warn("Retryable error occurred, retrying", "operation", op, "error", err, "attempt", attempt+1, "retry_after", delay)
 
 		select {
 		case <-time.After(delay):
@@ -89,11 +355,106 @@ func (s *transferService) BulkTransfer(ctx context.Context, req BulkTransferRequ
 		}
 	}
 
-	s.logger.Error("Failed to process bulk transfer after maximum retries", "max_retries", s.retryConfig.MaxRetries)
+	// The cause stays in the logs, the driver messages are not shown to the callers
+	s.logger.Error("Failed after maximum retries", "operation", op, "error", err, "max_retries", s.retryConfig.MaxRetries)
+	if isSerializationFailure(err) {
+		return fmt.Errorf("%w after %d attempts", ErrConcurrentUpdate, s.retryConfig.MaxRetries)
+	}
 	return err
 }
 
-func (s *transferService) executeBulkTransfer(ctx context.Context, req BulkTransferRequest) error {
+// receiveBatch records a new batch for the request together with its batch.received event
+func (s *transferService) receiveBatch(ctx context.Context, req BulkTransferRequest, count int, totalTransfer int64) (*batch.Batch, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	b := batch.NewBatch(req.OrganizationName, req.OrganizationIBAN, count, totalTransfer)
+	b.MessageID = req.MessageID
+	b.MessageName = req.MessageName
+	if err := s.batchRepo.Create(ctx, tx, b); err != nil {
+		return nil, err
+	}
+	if err := s.appendBatchEvent(ctx, tx, event.TypeBatchReceived, b); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.broker.Notify()
+	return b, nil
+}
+
+// failBatch records that the batch was rejected with err together with its batch.failed event.
+// It is recorded even if ctx was canceled, so that the batch does not stay received forever.
+func (s *transferService) failBatch(ctx context.Context, b *batch.Batch, cause error) {
+	ctx = context.WithoutCancel(ctx)
+
+	b.Status = batch.StatusFailed
+	b.ErrorCode = ErrorCode(cause)
+	b.ErrorDetail = cause.Error()
+	if b.ErrorCode == CodeInternalError {
+		// The batch is served to the organization in events, webhooks and status reports,
+		// so internal errors, which may hold driver messages, are only logged
+		s.logger.Error("Batch failed with an internal error", "batch_id", b.ID, "error", cause)
+		b.ErrorDetail = internalErrorDetail
+	}
+
+	err := func() error {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := s.batchRepo.Update(ctx, tx, b); err != nil {
+			return err
+		}
+		if err := s.appendBatchEvent(ctx, tx, event.TypeBatchFailed, b); err != nil {
+			return err
+		}
+
+		e := newBulkTransferAuditEvent(ctx, BulkTransferRequest{OrganizationName: b.OrganizationName, OrganizationIBAN: b.OrganizationIBAN})
+		e.ResourceType = "batch"
+		e.ResourceID = strconv.FormatInt(b.ID, 10)
+		e.Outcome = auditOutcome(cause)
+		e.ErrorCode = b.ErrorCode
+		if err := e.SetSnapshots(nil, auditState{Batch: b}); err != nil {
+			return err
+		}
+		if err := s.appendAudit(ctx, tx, e); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}()
+	if err != nil {
+		s.logger.Error("Failed to record failed batch", "batch_id", b.ID, "error", err)
+		return
+	}
+	s.broker.Notify()
+}
+
+// appendBatchEvent appends an event of the given type with the state of the batch
+func (s *transferService) appendBatchEvent(ctx context.Context, tx *sql.Tx, eventType event.Type, b *batch.Batch) error {
+	e, err := event.NewEvent(eventType, b.OrganizationName, b.OrganizationIBAN, b)
+	if err != nil {
+		return err
+	}
+	e.BankAccountID = b.BankAccountID
+	e.BatchID = b.ID
+	return s.eventRepo.Append(ctx, tx, e)
+}
+
+// executeBulkTransfer executes the bulk transfer in a single transaction.
+// The transfers are validated and inserted in chunks of chunkSize as they are
+// read from the request, so that memory use does not grow with the request size.
+// The batch is marked executed in the same transaction.
+func (s *transferService) executeBulkTransfer(ctx context.Context, req BulkTransferRequest, b *batch.Batch) error {
+	totalTransfer := b.TotalCents
+
 	s.logger.Info("Processing bulk transfer request", "organization_bic", req.OrganizationBIC, "organization_iban", req.OrganizationIBAN)
 
 	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
@@ -102,77 +463,212 @@ func (s *transferService) executeBulkTransfer(ctx context.Context, req BulkTrans
 	}
 	defer tx.Rollback()
 
-	account, err := s.accountRepo.GetByIBAN(req.OrganizationIBAN, tx)
+	bankAccount, err := s.accountRepo.GetByIBAN(req.OrganizationIBAN, tx)
 	if err != nil {
 		s.logger.Error("Failed to get bank account", "error", err)
+		if errors.Is(err, account.ErrNotFound) {
+			return fmt.Errorf("%w: %s", ErrAccountNotFound, req.OrganizationIBAN)
+		}
 		return err
 	}
 
-	totalTransfer, err := calculateTotalTransfer(req.Transfers)
-	if err != nil {
-		s.logger.Error("Failed to calculate total transfer", "error", err)
-		return err
-	}
+	s.logger.Debug("Transfer details", "total_transfer", totalTransfer, "account_balance", bankAccount.BalanceCents)
 
-	s.logger.Debug("Transfer details", "total_transfer", totalTransfer, "account_balance", account.BalanceCents)
+	if bankAccount.BalanceCents < totalTransfer {
+		s.logger.Warn("Insufficient funds", "required", totalTransfer, "available", bankAccount.BalanceCents)
+		return &InsufficientFundsError{RequiredCents: totalTransfer, AvailableCents: bankAccount.BalanceCents}
+	}
 
-	if account.BalanceCents < totalTransfer {
-		s.logger.Warn("Insufficient funds", "required", totalTransfer, "available", account.BalanceCents)
-		return ErrInsufficientFunds
+	// balance is the balance of the account before the chunk, each transfer is chained with the balance it leaves
+	balance := bankAccount.BalanceCents
+	chunk := make([]transfer.Transfer, 0, s.chunkSize)
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		if err := s.transferRepo.CreateBulkTransfers(ctx, tx, chunk, balance); err != nil {
+			return err
+		}
+		for _, t := range chunk {
+			balance -= t.AmountCents
+		}
+		chunk = chunk[:0]
+		return nil
 	}
 
-	transfersList := make([]transfer.Transfer, len(req.Transfers))
-	for i, ct := range req.Transfers {
-		transfersList[i] = *transfer.NewTransfer(
+	var line int
+	err = req.forEachTransfer(func(ct transfer.Transfer) error {
+		line++
+		t := transfer.NewTransfer(
 			ct.CounterpartyName,
 			ct.CounterpartyIBAN,
 			ct.CounterpartyBIC,
 			ct.AmountCents,
-			account.ID,
+			bankAccount.ID,
 			ct.Description,
 		)
-	}
+		t.BatchID = b.ID
+		t.EndToEndID = ct.EndToEndID
+		t.PaymentInformationID = ct.PaymentInformationID
+		if err := t.Validate(); err != nil {
+			return &InvalidTransferError{Line: line, Err: err}
+		}
 
-	err = s.transferRepo.CreateBulkTransfers(ctx, tx, transfersList)
+		chunk = append(chunk, *t)
+		if len(chunk) < s.chunkSize {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
 	if err != nil {
 		s.logger.Error("Failed to create transfers", "error", err)
 		return err
 	}
 
 	// Update the account balance
-	account.BalanceCents = account.BalanceCents - totalTransfer
-	err = s.accountRepo.Update(account, tx)
+	accountBefore := newAccountSnapshot(bankAccount)
+	bankAccount.BalanceCents = bankAccount.BalanceCents - totalTransfer
+	err = s.accountRepo.Update(bankAccount, tx)
 	if err != nil {
 		s.logger.Error("Failed to update account balance", "error", err)
 		return err
 	}
 
+	executed := *b
+	executed.BankAccountID = bankAccount.ID
+	executed.Status = batch.StatusExecuted
+	if err := s.batchRepo.Update(ctx, tx, &executed); err != nil {
+		s.logger.Error("Failed to update batch", "error", err)
+		return err
+	}
+	if err := s.appendBatchEvent(ctx, tx, event.TypeBatchExecuted, &executed); err != nil {
+		s.logger.Error("Failed to append batch event", "error", err)
+		return err
+	}
+
+	e := newBulkTransferAuditEvent(ctx, req)
+	e.ResourceType = "batch"
+	e.ResourceID = strconv.FormatInt(executed.ID, 10)
+	if err := e.SetSnapshots(
+		auditState{Account: accountBefore},
+		auditState{Account: newAccountSnapshot(bankAccount), Batch: &executed},
+	); err != nil {
+		return err
+	}
+	if err := s.appendAudit(ctx, tx, e); err != nil {
+		s.logger.Error("Failed to append audit event", "error", err)
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		s.logger.Error("Failed to commit transaction", "error", err)
 		return err
 	}
+	*b = executed
+	s.broker.Notify()
 
 	s.logger.Info("Bulk transfer processed successfully", "organization_bic", req.OrganizationBIC, "organization_iban", req.OrganizationIBAN, "total_transfer", totalTransfer)
 	return nil
 }
 
-func calculateTotalTransfer(transfers []transfer.Transfer) (int64, error) {
+// accountSnapshot is the state of a bank account recorded by the audit events
+type accountSnapshot struct {
+	ID           int64  `json:"id"`
+	IBAN         string `json:"iban"`
+	BalanceCents int64  `json:"balance_cents"`
+}
+
+func newAccountSnapshot(a *account.BankAccount) *accountSnapshot {
+	return &accountSnapshot{ID: a.ID, IBAN: a.IBAN, BalanceCents: a.BalanceCents}
+}
+
+// transferSnapshot is the state of a transfer recorded by the audit events and the transfer.returned
+// events, without the counterparty details which are only stored encrypted
+type transferSnapshot struct {
+	ID           int64           `json:"id"`
+	BatchID      int64           `json:"batch_id,omitempty"`
+	EndToEndID   string          `json:"end_to_end_id,omitempty"`
+	Status       transfer.Status `json:"status"`
+	AmountCents  int64           `json:"amount_cents"`
+	ReturnReason string          `json:"return_reason,omitempty"`
+	ReturnedAt   *time.Time      `json:"returned_at,omitempty"`
+}
+
+func newTransferSnapshot(t *transfer.Transfer) *transferSnapshot {
+	s := &transferSnapshot{ID: t.ID, BatchID: t.BatchID, EndToEndID: t.EndToEndID, Status: t.Status, AmountCents: t.AmountCents, ReturnReason: t.ReturnReason}
+	if !t.ReturnedAt.IsZero() {
+		returnedAt := t.ReturnedAt
+		s.ReturnedAt = &returnedAt
+	}
+	return s
+}
+
+// auditState is the state changed by a bulk transfer or a return, before or after it
+type auditState struct {
+	Account  *accountSnapshot  `json:"account,omitempty"`
+	Batch    *batch.Batch      `json:"batch,omitempty"`
+	Transfer *transferSnapshot `json:"transfer,omitempty"`
+}
+
+// newBulkTransferAuditEvent returns the audit event of the bulk transfer request
+func newBulkTransferAuditEvent(ctx context.Context, req BulkTransferRequest) *audit.Event {
+	e := audit.NewEvent(ctx, audit.ActionBulkTransfer)
+	e.OrganizationName = req.OrganizationName
+	e.AccountIBAN = req.OrganizationIBAN
+	return e
+}
+
+// appendAudit appends the audit event in tx, nothing is audited without an audit repository
+func (s *transferService) appendAudit(ctx context.Context, tx *sql.Tx, e *audit.Event) error {
+	if s.auditRepo == nil {
+		return nil
+	}
+	return s.auditRepo.Append(ctx, tx, e)
+}
+
+// auditRejection records an action that was rejected with err before anything was changed
+func (s *transferService) auditRejection(ctx context.Context, e *audit.Event, err error) {
+	recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+}
+
+// calculateTotalTransfer returns the total amount and the number of transfers of the request
+func calculateTotalTransfer(req BulkTransferRequest) (int64, int, error) {
 	var total int64
-	for _, t := range transfers {
+	var line int
+	err := req.forEachTransfer(func(t transfer.Transfer) error {
+		line++
 		// Check for potential overflow
 		if t.AmountCents < 0 {
-			return 0, errors.New("negative transfer amount not allowed")
+			return &InvalidTransferError{Line: line, Err: errors.New("negative transfer amount not allowed")}
 		}
 		if total > math.MaxInt64-t.AmountCents {
-			return 0, errors.New("total transfer amount exceeds maximum allowed value")
+			return ErrAmountOverflow
 		}
 		total += t.AmountCents
-	}
-	return total, nil
+		return nil
+	})
+	return total, line, err
 }
 
+// Postgres SQLSTATE codes of the transactions rolled back for concurrent updates
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
 func isRetryableError(err error) bool {
-	// Check if the error is due to serializable isolation conflicts
-	// This might need to be adjusted based on the specific error returned by your database
-	return errors.Is(err, sql.ErrTxDone) || errors.Is(err, sql.ErrConnDone) || strings.Contains(err.Error(), "serialization failure")
+	return isSerializationFailure(err) || errors.Is(err, sql.ErrTxDone) || errors.Is(err, sql.ErrConnDone)
+}
+
+// isSerializationFailure reports whether the transaction failed with a serialization failure
+// or a deadlock, the only errors reported as concurrent updates once retries are exhausted
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == sqlStateSerializationFailure || pqErr.Code == sqlStateDeadlockDetected
 }
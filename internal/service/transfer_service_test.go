@@ -3,17 +3,25 @@ package service_test
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"testing"
 	"time"
 
 	"moneytransfer/internal/account"
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/auth"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/batch"
+	"moneytransfer/internal/event"
 	"moneytransfer/internal/service"
 	"moneytransfer/internal/transfer"
 	"moneytransfer/mock"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
@@ -29,12 +37,75 @@ func (m *MockDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, err
 
 // Implement other necessary methods of sql.DB interface with empty bodies
 
+// newTestTransfer returns a valid transfer of the given amount
+func newTestTransfer(amountCents int64) transfer.Transfer {
+	return transfer.Transfer{
+		CounterpartyName: "John Doe",
+		CounterpartyIBAN: "DE89370400440532013000",
+		CounterpartyBIC:  "DEUTDEFF",
+		AmountCents:      amountCents,
+		Description:      "Test transfer",
+	}
+}
+
+// testBatchID is the ID given to the batches created in the tests
+const testBatchID = 42
+
+// eventOfType matches an event of the given type of the test batch
+func eventOfType(eventType event.Type) gomock.Matcher {
+	return gomock.Cond(func(x any) bool {
+		e, ok := x.(*event.Event)
+		return ok && e.Type == eventType && e.BatchID == testBatchID
+	})
+}
+
+// expectReceiveBatch expects the batch of a request to be recorded in its own transaction
+func expectReceiveBatch(sqlMock sqlmock.Sqlmock, batchRepo *mock.BatchRepositoryMock, eventRepo *mock.EventRepositoryMock) {
+	sqlMock.ExpectBegin()
+	batchRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ *sql.Tx, b *batch.Batch) error {
+		b.ID = testBatchID
+		return nil
+	})
+	eventRepo.EXPECT().Append(gomock.Any(), gomock.Any(), eventOfType(event.TypeBatchReceived)).Return(nil)
+	sqlMock.ExpectCommit()
+}
+
+// expectExecuteBatch expects the batch to be marked executed in the transaction of its transfers
+func expectExecuteBatch(batchRepo *mock.BatchRepositoryMock, eventRepo *mock.EventRepositoryMock) {
+	batchRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ *sql.Tx, b *batch.Batch) error {
+		if b.Status != batch.StatusExecuted {
+			return errors.New("batch is not executed")
+		}
+		return nil
+	})
+	eventRepo.EXPECT().Append(gomock.Any(), gomock.Any(), eventOfType(event.TypeBatchExecuted)).Return(nil)
+}
+
+// expectFailBatch expects the batch to be marked failed with the given error code in its own transaction.
+// Internal errors are recorded with the generic title of the catalog rather than their message.
+func expectFailBatch(sqlMock sqlmock.Sqlmock, batchRepo *mock.BatchRepositoryMock, eventRepo *mock.EventRepositoryMock, code string) {
+	sqlMock.ExpectBegin()
+	batchRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ *sql.Tx, b *batch.Batch) error {
+		if b.Status != batch.StatusFailed || b.ErrorCode != code {
+			return errors.New("batch is not failed with " + code)
+		}
+		if (code == service.CodeInternalError) != (b.ErrorDetail == "Internal server error") {
+			return errors.New("unexpected error detail " + b.ErrorDetail)
+		}
+		return nil
+	})
+	eventRepo.EXPECT().Append(gomock.Any(), gomock.Any(), eventOfType(event.TypeBatchFailed)).Return(nil)
+	sqlMock.ExpectCommit()
+}
+
 func TestTransferService_BulkTransfer(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
 	mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+	mockBatchRepo := mock.NewBatchRepositoryMock(ctrl)
+	mockEventRepo := mock.NewEventRepositoryMock(ctrl)
 
 	// Create a new sqlmock database connection
 	mockDB, sqlMock, err := sqlmock.New()
@@ -51,20 +122,27 @@ func TestTransferService_BulkTransfer(t *testing.T) {
 		MaxRetries: 3,
 	}
 
-	svc := service.NewTransferService(mockDB, logger, mockAccountRepo, mockTransferRepo, retryConfig)
+	svc := service.NewTransferService(mockDB, logger, mockAccountRepo, mockTransferRepo, mockBatchRepo, mockEventRepo, nil, event.NewBroker(), retryConfig, 100, nil)
 
 	t.Run("Successful bulk transfer", func(t *testing.T) {
 		ctx := context.Background()
+		referenced := newTestTransfer(2000)
+		referenced.EndToEndID = "E2E-2"
+		referenced.PaymentInformationID = "PMT-1"
 		req := service.BulkTransferRequest{
 			OrganizationName: "Test Org",
 			OrganizationBIC:  "TESTBIC",
 			OrganizationIBAN: "TEST123456789",
+			MessageID:        "MSG-1",
+			MessageName:      "pain.001.001.03",
 			Transfers: []transfer.Transfer{
-				{AmountCents: 1000},
-				{AmountCents: 2000},
+				newTestTransfer(1000),
+				referenced,
 			},
 		}
 
+		expectReceiveBatch(sqlMock, mockBatchRepo, mockEventRepo)
+
 		// Expect a transaction to be started
 		sqlMock.ExpectBegin()
 
@@ -76,14 +154,32 @@ func TestTransferService_BulkTransfer(t *testing.T) {
 			OrganizationName: req.OrganizationName,
 		}, nil)
 
-		mockTransferRepo.EXPECT().CreateBulkTransfers(ctx, gomock.Any(), gomock.Any()).Return(nil)
+		mockTransferRepo.EXPECT().CreateBulkTransfers(ctx, gomock.Any(), gomock.Any(), int64(5000)).
+			DoAndReturn(func(_ context.Context, _ *sql.Tx, transfers []transfer.Transfer, _ int64) error {
+				for _, tr := range transfers {
+					assert.Equal(t, int64(testBatchID), tr.BatchID)
+				}
+				assert.Equal(t, "E2E-2", transfers[1].EndToEndID)
+				assert.Equal(t, "PMT-1", transfers[1].PaymentInformationID)
+				return nil
+			})
 		mockAccountRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+		expectExecuteBatch(mockBatchRepo, mockEventRepo)
 
 		// Expect the transaction to be committed
 		sqlMock.ExpectCommit()
 
-		err := svc.BulkTransfer(ctx, req)
+		b, err := svc.BulkTransfer(ctx, req)
 		assert.NoError(t, err)
+		if assert.NotNil(t, b) {
+			assert.Equal(t, "MSG-1", b.MessageID)
+			assert.Equal(t, "pain.001.001.03", b.MessageName)
+			assert.Equal(t, int64(testBatchID), b.ID)
+			assert.Equal(t, batch.StatusExecuted, b.Status)
+			assert.Equal(t, int64(1), b.BankAccountID)
+			assert.Equal(t, 2, b.TransferCount)
+			assert.Equal(t, int64(3000), b.TotalCents)
+		}
 	})
 
 	t.Run("Insufficient funds", func(t *testing.T) {
@@ -93,11 +189,13 @@ func TestTransferService_BulkTransfer(t *testing.T) {
 			OrganizationBIC:  "TESTBIC",
 			OrganizationIBAN: "TEST123456789",
 			Transfers: []transfer.Transfer{
-				{AmountCents: 3000},
-				{AmountCents: 3000},
+				newTestTransfer(3000),
+				newTestTransfer(3000),
 			},
 		}
 
+		expectReceiveBatch(sqlMock, mockBatchRepo, mockEventRepo)
+
 		// Expect a transaction to be started
 		sqlMock.ExpectBegin()
 
@@ -111,10 +209,18 @@ func TestTransferService_BulkTransfer(t *testing.T) {
 
 		// Expect the transaction to be rolled back
 		sqlMock.ExpectRollback()
+		expectFailBatch(sqlMock, mockBatchRepo, mockEventRepo, service.CodeInsufficientFunds)
 
-		err := svc.BulkTransfer(ctx, req)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "insufficient funds")
+		b, err := svc.BulkTransfer(ctx, req)
+		assert.ErrorIs(t, err, service.ErrInsufficientFunds)
+		if assert.NotNil(t, b) {
+			assert.Equal(t, batch.StatusFailed, b.Status)
+		}
+		var fundsErr *service.InsufficientFundsError
+		if assert.ErrorAs(t, err, &fundsErr) {
+			assert.Equal(t, int64(6000), fundsErr.RequiredCents)
+			assert.Equal(t, int64(5000), fundsErr.AvailableCents)
+		}
 	})
 
 	t.Run("Retryable error", func(t *testing.T) {
@@ -124,11 +230,13 @@ func TestTransferService_BulkTransfer(t *testing.T) {
 			OrganizationBIC:  "TESTBIC",
 			OrganizationIBAN: "TEST123456789",
 			Transfers: []transfer.Transfer{
-				{AmountCents: 1000},
+				newTestTransfer(1000),
 			},
 		}
 
-		retryableErr := errors.New("serialization failure")
+		retryableErr := &pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}
+
+		expectReceiveBatch(sqlMock, mockBatchRepo, mockEventRepo)
 
 		// Expect 3 transaction begins (initial + 2 retries)
 		for i := 0; i < 3; i++ {
@@ -140,13 +248,40 @@ func TestTransferService_BulkTransfer(t *testing.T) {
 				BIC:              req.OrganizationBIC,
 				OrganizationName: req.OrganizationName,
 			}, nil)
-			mockTransferRepo.EXPECT().CreateBulkTransfers(ctx, gomock.Any(), gomock.Any()).Return(retryableErr)
+			mockTransferRepo.EXPECT().CreateBulkTransfers(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(retryableErr)
 			sqlMock.ExpectRollback()
 		}
+		expectFailBatch(sqlMock, mockBatchRepo, mockEventRepo, service.CodeConcurrentUpdate)
 
-		err := svc.BulkTransfer(ctx, req)
-		assert.Error(t, err)
-		assert.Equal(t, retryableErr, err)
+		_, err := svc.BulkTransfer(ctx, req)
+		assert.ErrorIs(t, err, service.ErrConcurrentUpdate)
+		// The driver message is only logged
+		assert.Equal(t, "account was updated concurrently after 3 attempts", err.Error())
+	})
+
+	t.Run("Retryable connection error", func(t *testing.T) {
+		ctx := context.Background()
+		req := service.BulkTransferRequest{
+			OrganizationName: "Test Org",
+			OrganizationBIC:  "TESTBIC",
+			OrganizationIBAN: "TEST123456789",
+			Transfers: []transfer.Transfer{
+				newTestTransfer(1000),
+			},
+		}
+
+		expectReceiveBatch(sqlMock, mockBatchRepo, mockEventRepo)
+		for i := 0; i < 3; i++ {
+			sqlMock.ExpectBegin()
+			mockAccountRepo.EXPECT().GetByIBAN(req.OrganizationIBAN, gomock.Any()).Return(nil, sql.ErrConnDone)
+			sqlMock.ExpectRollback()
+		}
+		// Only serialization failures and deadlocks are concurrent updates
+		expectFailBatch(sqlMock, mockBatchRepo, mockEventRepo, service.CodeInternalError)
+
+		_, err := svc.BulkTransfer(ctx, req)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.NotErrorIs(t, err, service.ErrConcurrentUpdate)
 	})
 
 	t.Run("Non-retryable error", func(t *testing.T) {
@@ -156,23 +291,477 @@ func TestTransferService_BulkTransfer(t *testing.T) {
 			OrganizationBIC:  "TESTBIC",
 			OrganizationIBAN: "TEST123456789",
 			Transfers: []transfer.Transfer{
-				{AmountCents: 1000},
+				newTestTransfer(1000),
 			},
 		}
 
 		nonRetryableErr := errors.New("non-retryable error")
 
+		expectReceiveBatch(sqlMock, mockBatchRepo, mockEventRepo)
 		sqlMock.ExpectBegin()
 		mockAccountRepo.EXPECT().GetByIBAN(req.OrganizationIBAN, gomock.Any()).Return(nil, nonRetryableErr)
 		sqlMock.ExpectRollback()
+		expectFailBatch(sqlMock, mockBatchRepo, mockEventRepo, service.CodeInternalError)
 
-		err := svc.BulkTransfer(ctx, req)
+		_, err := svc.BulkTransfer(ctx, req)
 		assert.Error(t, err)
 		assert.Equal(t, nonRetryableErr, err)
 	})
 
+	t.Run("Account not found", func(t *testing.T) {
+		ctx := context.Background()
+		req := service.BulkTransferRequest{
+			OrganizationIBAN: "UNKNOWN",
+			Transfers:        []transfer.Transfer{newTestTransfer(1000)},
+		}
+
+		expectReceiveBatch(sqlMock, mockBatchRepo, mockEventRepo)
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(req.OrganizationIBAN, gomock.Any()).Return(nil, account.ErrNotFound)
+		sqlMock.ExpectRollback()
+		expectFailBatch(sqlMock, mockBatchRepo, mockEventRepo, service.CodeAccountNotFound)
+
+		_, err := svc.BulkTransfer(ctx, req)
+		assert.ErrorIs(t, err, service.ErrAccountNotFound)
+		assert.Equal(t, service.CodeAccountNotFound, service.ErrorCode(err))
+	})
+
+	t.Run("Account of another organization", func(t *testing.T) {
+		ctx := auth.NewContext(context.Background(), auth.Principal{OrganizationName: "Other Org", Subject: "api_key:1"})
+		req := service.BulkTransferRequest{
+			OrganizationName: "Other Org",
+			OrganizationIBAN: "TEST123456789",
+			Transfers:        []transfer.Transfer{newTestTransfer(1000)},
+		}
+
+		// No batch is recorded for the request
+		mockAccountRepo.EXPECT().GetByIBAN(req.OrganizationIBAN, gomock.Nil()).Return(&account.BankAccount{
+			ID:               1,
+			BalanceCents:     5000,
+			IBAN:             req.OrganizationIBAN,
+			OrganizationName: "Test Org",
+		}, nil)
+
+		b, err := svc.BulkTransfer(ctx, req)
+		assert.Nil(t, b)
+		assert.ErrorIs(t, err, service.ErrAccountNotOwned)
+		assert.Equal(t, service.CodeAccountNotOwned, service.ErrorCode(err))
+	})
+
+	t.Run("Unknown account of an authenticated organization", func(t *testing.T) {
+		ctx := auth.NewContext(context.Background(), auth.Principal{OrganizationName: "Test Org", Subject: "api_key:1"})
+		req := service.BulkTransferRequest{
+			OrganizationIBAN: "UNKNOWN",
+			Transfers:        []transfer.Transfer{newTestTransfer(1000)},
+		}
+
+		mockAccountRepo.EXPECT().GetByIBAN(req.OrganizationIBAN, gomock.Nil()).Return(nil, account.ErrNotFound)
+
+		_, err := svc.BulkTransfer(ctx, req)
+		assert.ErrorIs(t, err, service.ErrAccountNotOwned)
+	})
+
 	// Ensure all expectations were met
 	if err := sqlMock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestTransferService_BulkTransfer_Streaming(t *testing.T) {
+	retryConfig := service.RetryConfig{
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   time.Millisecond * 10,
+		MaxRetries: 3,
+	}
+
+	t.Run("Transfers from a source are inserted in chunks", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+		mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+		mockBatchRepo := mock.NewBatchRepositoryMock(ctrl)
+		mockEventRepo := mock.NewEventRepositoryMock(ctrl)
+		mockDB, sqlMock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		svc := service.NewTransferService(mockDB, slog.Default(), mockAccountRepo, mockTransferRepo, mockBatchRepo, mockEventRepo, nil, nil, retryConfig, 2, nil)
+
+		reads := 0
+		req := service.BulkTransferRequest{
+			OrganizationIBAN: "TEST123456789",
+			Source: func(yield func(transfer.Transfer) error) error {
+				reads++
+				for i := 0; i < 5; i++ {
+					if err := yield(newTestTransfer(100)); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}
+
+		expectReceiveBatch(sqlMock, mockBatchRepo, mockEventRepo)
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(req.OrganizationIBAN, gomock.Any()).Return(&account.BankAccount{
+			ID:           1,
+			BalanceCents: 1000,
+			IBAN:         req.OrganizationIBAN,
+		}, nil)
+
+		var chunkSizes []int
+		var balances []int64
+		mockTransferRepo.EXPECT().CreateBulkTransfers(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *sql.Tx, transfers []transfer.Transfer, balanceCents int64) error {
+				chunkSizes = append(chunkSizes, len(transfers))
+				balances = append(balances, balanceCents)
+				for _, tr := range transfers {
+					assert.Equal(t, int64(1), tr.BankAccountID)
+				}
+				return nil
+			}).Times(3)
+		mockAccountRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(acc *account.BankAccount, _ *sql.Tx) error {
+			assert.Equal(t, int64(500), acc.BalanceCents)
+			return nil
+		})
+		expectExecuteBatch(mockBatchRepo, mockEventRepo)
+		sqlMock.ExpectCommit()
+
+		_, err = svc.BulkTransfer(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2, 2, 1}, chunkSizes)
+		assert.Equal(t, []int64{1000, 800, 600}, balances)
+		assert.Equal(t, 2, reads, "source should be read once to validate and once to execute")
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid transfer is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+		mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+		mockBatchRepo := mock.NewBatchRepositoryMock(ctrl)
+		mockEventRepo := mock.NewEventRepositoryMock(ctrl)
+		mockDB, sqlMock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		svc := service.NewTransferService(mockDB, slog.Default(), mockAccountRepo, mockTransferRepo, mockBatchRepo, mockEventRepo, nil, nil, retryConfig, 2, nil)
+
+		invalid := newTestTransfer(100)
+		invalid.CounterpartyIBAN = ""
+		req := service.BulkTransferRequest{
+			OrganizationIBAN: "TEST123456789",
+			Transfers:        []transfer.Transfer{newTestTransfer(100), newTestTransfer(100), invalid},
+		}
+
+		expectReceiveBatch(sqlMock, mockBatchRepo, mockEventRepo)
+		sqlMock.ExpectBegin()
+		mockAccountRepo.EXPECT().GetByIBAN(req.OrganizationIBAN, gomock.Any()).Return(&account.BankAccount{
+			ID:           1,
+			BalanceCents: 1000,
+			IBAN:         req.OrganizationIBAN,
+		}, nil)
+		mockTransferRepo.EXPECT().CreateBulkTransfers(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		sqlMock.ExpectRollback()
+		expectFailBatch(sqlMock, mockBatchRepo, mockEventRepo, service.CodeInvalidTransfer)
+
+		_, err = svc.BulkTransfer(context.Background(), req)
+		var invalidErr *service.InvalidTransferError
+		if assert.ErrorAs(t, err, &invalidErr) {
+			assert.Equal(t, 3, invalidErr.Line)
+			assert.EqualError(t, invalidErr.Err, "counterparty IBAN is required")
+		}
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestTransferService_GetBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockBatchRepo := mock.NewBatchRepositoryMock(ctrl)
+	svc := service.NewTransferService(nil, slog.Default(), nil, nil, mockBatchRepo, nil, nil, nil, service.RetryConfig{}, 100, nil)
+
+	t.Run("Batch found", func(t *testing.T) {
+		want := &batch.Batch{ID: testBatchID, Status: batch.StatusExecuted}
+		mockBatchRepo.EXPECT().Get(gomock.Any(), int64(testBatchID)).Return(want, nil)
+
+		b, err := svc.GetBatch(context.Background(), testBatchID)
+		assert.NoError(t, err)
+		assert.Equal(t, want, b)
+	})
+
+	t.Run("Batch not found", func(t *testing.T) {
+		mockBatchRepo.EXPECT().Get(gomock.Any(), int64(7)).Return(nil, batch.ErrNotFound)
+
+		_, err := svc.GetBatch(context.Background(), 7)
+		assert.ErrorIs(t, err, service.ErrBatchNotFound)
+		assert.Equal(t, service.CodeBatchNotFound, service.ErrorCode(err))
+	})
+}
+
+func TestTransferService_GetBatch_Policy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockBatchRepo := mock.NewBatchRepositoryMock(ctrl)
+	mockPolicy := mock.NewAuthorizerMock(ctrl)
+	svc := service.NewTransferService(nil, slog.Default(), nil, nil, mockBatchRepo, nil, nil, nil, service.RetryConfig{}, 100, mockPolicy)
+
+	b := &batch.Batch{ID: testBatchID, OrganizationName: "ACME Corp", OrganizationIBAN: "FR10474608000002006107XXXXX", Status: batch.StatusExecuted}
+	resource := authz.Resource{AccountIBAN: b.OrganizationIBAN}
+
+	t.Run("Allowed", func(t *testing.T) {
+		mockBatchRepo.EXPECT().Get(gomock.Any(), int64(testBatchID)).Return(b, nil)
+		mockPolicy.EXPECT().Authorize(gomock.Any(), authz.ActionRead, resource).Return(nil)
+
+		got, err := svc.GetBatch(context.Background(), testBatchID)
+		assert.NoError(t, err)
+		assert.Equal(t, b, got)
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		mockBatchRepo.EXPECT().Get(gomock.Any(), int64(testBatchID)).Return(b, nil)
+		mockPolicy.EXPECT().Authorize(gomock.Any(), authz.ActionRead, resource).Return(fmt.Errorf("%w: api_key:7 may not read", authz.ErrDenied))
+
+		_, err := svc.GetBatch(context.Background(), testBatchID)
+		assert.ErrorIs(t, err, service.ErrPermissionDenied)
+		assert.Equal(t, service.CodePermissionDenied, service.ErrorCode(err))
+	})
+}
+
+func TestTransferService_ReturnTransfer(t *testing.T) {
+	retryConfig := service.RetryConfig{
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   time.Millisecond * 10,
+		MaxRetries: 3,
+	}
+
+	newService := func(t *testing.T) (*mock.AccountRepositoryMock, *mock.TransferRepositoryMock, *mock.EventRepositoryMock, sqlmock.Sqlmock, service.TransferService) {
+		ctrl := gomock.NewController(t)
+		mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+		mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+		mockEventRepo := mock.NewEventRepositoryMock(ctrl)
+		mockDB, sqlMock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		t.Cleanup(func() { mockDB.Close() })
+
+		svc := service.NewTransferService(mockDB, slog.Default(), mockAccountRepo, mockTransferRepo, nil, mockEventRepo, nil, nil, retryConfig, 100, nil)
+		return mockAccountRepo, mockTransferRepo, mockEventRepo, sqlMock, svc
+	}
+
+	executed := func() *transfer.Transfer {
+		tr := newTestTransfer(1500)
+		tr.ID = 7
+		tr.BankAccountID = 1
+		tr.BatchID = testBatchID
+		tr.Status = transfer.StatusExecuted
+		return &tr
+	}
+
+	t.Run("Returned transfer is credited back", func(t *testing.T) {
+		mockAccountRepo, mockTransferRepo, mockEventRepo, sqlMock, svc := newService(t)
+
+		sqlMock.ExpectBegin()
+		mockTransferRepo.EXPECT().Get(gomock.Any(), gomock.Any(), int64(7)).Return(executed(), nil)
+		mockAccountRepo.EXPECT().Get(int64(1), gomock.Any()).Return(&account.BankAccount{
+			ID:               1,
+			BalanceCents:     500,
+			IBAN:             "TEST123456789",
+			OrganizationName: "Test Org",
+		}, nil)
+		mockAccountRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(acc *account.BankAccount, _ *sql.Tx) error {
+			assert.Equal(t, int64(2000), acc.BalanceCents)
+			return nil
+		})
+		mockTransferRepo.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		mockTransferRepo.EXPECT().ChainReturn(gomock.Any(), gomock.Any(), gomock.Any(), int64(2000)).Return(nil)
+		mockEventRepo.EXPECT().Append(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ *sql.Tx, e *event.Event) error {
+			assert.Equal(t, event.TypeTransferReturned, e.Type)
+			assert.Equal(t, "Test Org", e.OrganizationName)
+			assert.Equal(t, int64(7), e.TransferID)
+			assert.Equal(t, int64(testBatchID), e.BatchID)
+			// The counterparty details are never copied into the events
+			var data map[string]any
+			assert.NoError(t, json.Unmarshal(e.Data, &data))
+			assert.Equal(t, float64(7), data["id"])
+			assert.Equal(t, "AC04", data["return_reason"])
+			for _, key := range []string{"counterparty_name", "counterparty_iban", "counterparty_bic", "description"} {
+				assert.NotContains(t, data, key)
+			}
+			return nil
+		})
+		sqlMock.ExpectCommit()
+
+		tr, err := svc.ReturnTransfer(context.Background(), 7, "AC04")
+		assert.NoError(t, err)
+		assert.Equal(t, transfer.StatusReturned, tr.Status)
+		assert.Equal(t, "AC04", tr.ReturnReason)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("Transfer not found", func(t *testing.T) {
+		_, mockTransferRepo, _, sqlMock, svc := newService(t)
+
+		sqlMock.ExpectBegin()
+		mockTransferRepo.EXPECT().Get(gomock.Any(), gomock.Any(), int64(7)).Return(nil, transfer.ErrNotFound)
+		sqlMock.ExpectRollback()
+
+		_, err := svc.ReturnTransfer(context.Background(), 7, "AC04")
+		assert.ErrorIs(t, err, service.ErrTransferNotFound)
+	})
+
+	t.Run("Transfer already returned", func(t *testing.T) {
+		mockAccountRepo, mockTransferRepo, _, sqlMock, svc := newService(t)
+
+		returned := executed()
+		returned.Status = transfer.StatusReturned
+		sqlMock.ExpectBegin()
+		mockTransferRepo.EXPECT().Get(gomock.Any(), gomock.Any(), int64(7)).Return(returned, nil)
+		mockAccountRepo.EXPECT().Get(int64(1), gomock.Any()).Return(&account.BankAccount{ID: 1, IBAN: "TEST123456789", OrganizationName: "Test Org"}, nil)
+		sqlMock.ExpectRollback()
+
+		_, err := svc.ReturnTransfer(context.Background(), 7, "AC04")
+		assert.ErrorIs(t, err, service.ErrNotReturnable)
+		assert.Equal(t, service.CodeNotReturnable, service.ErrorCode(err))
+	})
+}
+
+func TestTransferService_ReturnTransfer_OutOfScope(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+	mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+	mockPolicy := mock.NewAuthorizerMock(ctrl)
+	mockDB, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	ctx := auth.NewContext(context.Background(), auth.Principal{OrganizationName: "Test Org", Subject: "api_key:7"})
+	newService := func(policy authz.Authorizer) service.TransferService {
+		return service.NewTransferService(mockDB, slog.Default(), mockAccountRepo, mockTransferRepo, nil, nil, nil, nil, service.RetryConfig{MaxRetries: 1}, 100, policy)
+	}
+	other := newTestTransfer(1500)
+	other.ID = 8
+	other.BankAccountID = 2
+	other.Status = transfer.StatusReturned
+	otherAccount := &account.BankAccount{ID: 2, IBAN: "OTHER123456789", OrganizationName: "Other Org"}
+
+	// A missing transfer and a transfer of another organization, whatever its status, are
+	// reported the same way, so that principals cannot probe the IDs of the transfers
+	var errs []error
+
+	sqlMock.ExpectBegin()
+	mockTransferRepo.EXPECT().Get(gomock.Any(), gomock.Any(), int64(9)).Return(nil, transfer.ErrNotFound)
+	sqlMock.ExpectRollback()
+	_, err = newService(mockPolicy).ReturnTransfer(ctx, 9, "AC04")
+	errs = append(errs, err)
+
+	sqlMock.ExpectBegin()
+	mockTransferRepo.EXPECT().Get(gomock.Any(), gomock.Any(), int64(8)).Return(&other, nil)
+	mockAccountRepo.EXPECT().Get(int64(2), gomock.Any()).Return(otherAccount, nil)
+	sqlMock.ExpectRollback()
+	_, err = newService(mockPolicy).ReturnTransfer(ctx, 8, "AC04")
+	errs = append(errs, err)
+
+	sqlMock.ExpectBegin()
+	mockTransferRepo.EXPECT().Get(gomock.Any(), gomock.Any(), int64(8)).Return(&other, nil)
+	mockAccountRepo.EXPECT().Get(int64(2), gomock.Any()).Return(otherAccount, nil)
+	sqlMock.ExpectRollback()
+	_, err = newService(nil).ReturnTransfer(ctx, 8, "AC04")
+	errs = append(errs, err)
+
+	for _, err := range errs {
+		assert.ErrorIs(t, err, service.ErrPermissionDenied)
+		assert.Equal(t, service.CodePermissionDenied, service.ErrorCode(err))
+	}
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestTransferService_Audit(t *testing.T) {
+	newService := func(t *testing.T) (*mock.AccountRepositoryMock, *mock.TransferRepositoryMock, *mock.EventRepositoryMock, *mock.AuditRepositoryMock, sqlmock.Sqlmock, service.TransferService) {
+		ctrl := gomock.NewController(t)
+		mockAccountRepo := mock.NewAccountRepositoryMock(ctrl)
+		mockTransferRepo := mock.NewTransferRepositoryMock(ctrl)
+		mockEventRepo := mock.NewEventRepositoryMock(ctrl)
+		mockAuditRepo := mock.NewAuditRepositoryMock(ctrl)
+		mockDB, sqlMock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		t.Cleanup(func() { mockDB.Close() })
+
+		svc := service.NewTransferService(mockDB, slog.Default(), mockAccountRepo, mockTransferRepo, nil, mockEventRepo, mockAuditRepo, nil, service.RetryConfig{MaxRetries: 1}, 100, nil)
+		return mockAccountRepo, mockTransferRepo, mockEventRepo, mockAuditRepo, sqlMock, svc
+	}
+
+	ctx := auth.NewContext(context.Background(), auth.Principal{OrganizationName: "Test Org", Subject: "api_key:7"})
+	ctx = audit.NewContext(ctx, audit.Source{IP: "192.0.2.1", RequestID: "req-1"})
+
+	t.Run("Return recorded in its transaction", func(t *testing.T) {
+		mockAccountRepo, mockTransferRepo, mockEventRepo, mockAuditRepo, sqlMock, svc := newService(t)
+
+		tr := newTestTransfer(1500)
+		tr.ID = 7
+		tr.BankAccountID = 1
+		tr.Status = transfer.StatusExecuted
+
+		sqlMock.ExpectBegin()
+		mockTransferRepo.EXPECT().Get(gomock.Any(), gomock.Any(), int64(7)).Return(&tr, nil)
+		mockAccountRepo.EXPECT().Get(int64(1), gomock.Any()).Return(&account.BankAccount{
+			ID:               1,
+			BalanceCents:     500,
+			IBAN:             "TEST123456789",
+			OrganizationName: "Test Org",
+		}, nil)
+		mockAccountRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+		mockTransferRepo.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		mockTransferRepo.EXPECT().ChainReturn(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		mockEventRepo.EXPECT().Append(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		mockAuditRepo.EXPECT().Append(gomock.Any(), gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(func(_ context.Context, _ *sql.Tx, e *audit.Event) error {
+			assert.Equal(t, audit.ActionTransferReturn, e.Action)
+			assert.Equal(t, audit.OutcomeSuccess, e.Outcome)
+			assert.Equal(t, "api_key:7", e.Actor)
+			assert.Equal(t, "192.0.2.1", e.SourceIP)
+			assert.Equal(t, "req-1", e.RequestID)
+			assert.Equal(t, "TEST123456789", e.AccountIBAN)
+			assert.Equal(t, "7", e.ResourceID)
+			assert.Contains(t, string(e.Before), `"balance_cents":500`)
+			assert.Contains(t, string(e.After), `"balance_cents":2000`)
+			assert.Contains(t, string(e.After), `"status":"returned"`)
+			assert.NotContains(t, string(e.After), tr.CounterpartyIBAN)
+			return nil
+		})
+		sqlMock.ExpectCommit()
+
+		_, err := svc.ReturnTransfer(ctx, 7, "AC04")
+		assert.NoError(t, err)
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("Bulk transfer from another organization denied", func(t *testing.T) {
+		mockAccountRepo, _, _, mockAuditRepo, _, svc := newService(t)
+
+		mockAccountRepo.EXPECT().GetByIBAN("OTHER123456789", gomock.Any()).Return(&account.BankAccount{
+			ID:               2,
+			IBAN:             "OTHER123456789",
+			OrganizationName: "Other Org",
+		}, nil)
+		mockAuditRepo.EXPECT().Append(gomock.Any(), nil, gomock.Any()).DoAndReturn(func(_ context.Context, _ *sql.Tx, e *audit.Event) error {
+			assert.Equal(t, audit.ActionBulkTransfer, e.Action)
+			assert.Equal(t, audit.OutcomeDenied, e.Outcome)
+			assert.Equal(t, service.CodeAccountNotOwned, e.ErrorCode)
+			assert.Equal(t, "OTHER123456789", e.AccountIBAN)
+			return nil
+		})
+
+		_, err := svc.BulkTransfer(ctx, service.BulkTransferRequest{
+			OrganizationName: "Other Org",
+			OrganizationIBAN: "OTHER123456789",
+			Transfers:        []transfer.Transfer{newTestTransfer(1000)},
+		})
+		assert.ErrorIs(t, err, service.ErrAccountNotOwned)
+	})
+}
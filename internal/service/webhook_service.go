@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"moneytransfer/internal/audit"
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/event"
+	"moneytransfer/internal/webhook"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=webhook_service.go -destination=../../mock/webhook_service_mock.go -package=mock -mock_names=WebhookService=WebhookServiceMock
+type WebhookService interface {
+	// RegisterEndpoint registers a URL of the organization to be notified of the given event types.
+	// The returned endpoint carries the secret of its signatures.
+	RegisterEndpoint(ctx context.Context, organizationName, url string, eventTypes []event.Type) (*webhook.Endpoint, error)
+	ListEndpoints(ctx context.Context, organizationName string) ([]webhook.Endpoint, error)
+	// DisableEndpoint stops the deliveries to the endpoint, pending deliveries become dead.
+	// The endpoints of other organizations are denied to authenticated callers.
+	DisableEndpoint(ctx context.Context, id int64) error
+	// ListDeliveries returns the latest deliveries to the endpoint with their attempts
+	ListDeliveries(ctx context.Context, endpointID int64, limit int) ([]webhook.Delivery, error)
+	// Redeliver delivers the event of a delivery to its endpoint again, as a new delivery
+	Redeliver(ctx context.Context, endpointID, deliveryID int64) (*webhook.Delivery, error)
+}
+
+type webhookService struct {
+	repo      webhook.Repository
+	auditRepo audit.Repository
+	urls      webhook.URLPolicy
+	logger    *slog.Logger
+	policy    authz.Authorizer
+}
+
+// NewWebhookService is a function that creates a new webhook service
+// auditRepo records the registered and disabled endpoints, it may be nil to not audit them
+// urls restricts the URLs of the endpoints to the public addresses
+// policy authorizes the management of the endpoints, it may be nil to allow it to every caller
+func NewWebhookService(repo webhook.Repository, auditRepo audit.Repository, urls webhook.URLPolicy, logger *slog.Logger, policy authz.Authorizer) *webhookService {
+	return &webhookService{
+		repo:      repo,
+		auditRepo: auditRepo,
+		urls:      urls,
+		logger:    logger,
+		policy:    policy,
+	}
+}
+
+func (s *webhookService) RegisterEndpoint(ctx context.Context, organizationName, url string, eventTypes []event.Type) (*webhook.Endpoint, error) {
+	ae := newAdminAuditEvent(ctx, audit.ActionWebhookRegister, organizationName, "webhook_endpoint")
+	if err := authorize(ctx, s.policy, authz.ActionManage, authz.Resource{OrganizationName: organizationName}); err != nil {
+		return nil, recordAudit(ctx, s.auditRepo, s.logger, ae, nil, err)
+	}
+
+	e, err := webhook.NewEndpoint(organizationName, url, eventTypes)
+	if err != nil {
+		return nil, recordAudit(ctx, s.auditRepo, s.logger, ae, nil, err)
+	}
+	if err := e.Validate(); err != nil {
+		return nil, recordAudit(ctx, s.auditRepo, s.logger, ae, nil, fmt.Errorf("%w: %w", ErrInvalidWebhookEndpoint, err))
+	}
+	if err := s.urls.Check(ctx, e.URL); err != nil {
+		return nil, recordAudit(ctx, s.auditRepo, s.logger, ae, nil, fmt.Errorf("%w: %w", ErrInvalidWebhookEndpoint, err))
+	}
+
+	if err := s.repo.CreateEndpoint(ctx, e); err != nil {
+		return nil, recordAudit(ctx, s.auditRepo, s.logger, ae, nil, err)
+	}
+
+	s.logger.Info("Webhook endpoint registered", "endpoint_id", e.ID, "organization", organizationName, "event_types", eventTypes)
+	// The secret is never part of the audit log
+	ae.ResourceID = strconv.FormatInt(e.ID, 10)
+	recordAudit(ctx, s.auditRepo, s.logger, ae, map[string]any{"id": e.ID, "url": e.URL, "event_types": eventTypes}, nil)
+	return e, nil
+}
+
+func (s *webhookService) ListEndpoints(ctx context.Context, organizationName string) ([]webhook.Endpoint, error) {
+	if err := authorize(ctx, s.policy, authz.ActionManage, authz.Resource{OrganizationName: organizationName}); err != nil {
+		return nil, err
+	}
+	return s.repo.ListEndpoints(ctx, organizationName)
+}
+
+// getEndpoint returns the endpoint with the given id once the principal of ctx is allowed to manage
+// the endpoints of its organization
+func (s *webhookService) getEndpoint(ctx context.Context, id int64) (*webhook.Endpoint, error) {
+	e, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, endpointError(err, id)
+	}
+	if err := authorize(ctx, s.policy, authz.ActionManage, authz.Resource{OrganizationName: e.OrganizationName}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (s *webhookService) DisableEndpoint(ctx context.Context, id int64) error {
+	e := newAdminAuditEvent(ctx, audit.ActionWebhookDisable, "", "webhook_endpoint")
+	e.ResourceID = strconv.FormatInt(id, 10)
+
+	endpoint, err := s.getEndpoint(ctx, id)
+	if err != nil {
+		return recordAudit(ctx, s.auditRepo, s.logger, e, nil, err)
+	}
+	e.OrganizationName = endpoint.OrganizationName
+
+	// The endpoint is disabled within its organization, so that the update cannot reach another one
+	if err := s.repo.DisableEndpoint(ctx, endpoint.OrganizationName, id); err != nil {
+		return recordAudit(ctx, s.auditRepo, s.logger, e, nil, endpointError(err, id))
+	}
+
+	s.logger.Info("Webhook endpoint disabled", "endpoint_id", id)
+	recordAudit(ctx, s.auditRepo, s.logger, e, nil, nil)
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, endpointID int64, limit int) ([]webhook.Delivery, error) {
+	e, err := s.getEndpoint(ctx, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, e.OrganizationName, endpointID, limit)
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, endpointID, deliveryID int64) (*webhook.Delivery, error) {
+	if _, err := s.getEndpoint(ctx, endpointID); err != nil {
+		return nil, err
+	}
+	d, err := s.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, webhook.ErrDeliveryNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrWebhookDeliveryNotFound, deliveryID)
+		}
+		return nil, err
+	}
+	// A delivery is only found through the endpoint it belongs to
+	if d.EndpointID != endpointID {
+		return nil, fmt.Errorf("%w: %d", ErrWebhookDeliveryNotFound, deliveryID)
+	}
+
+	redelivery := d.Redelivery()
+	if err := s.repo.CreateDelivery(ctx, redelivery); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Webhook redelivery scheduled", "endpoint_id", endpointID, "delivery_id", redelivery.ID, "redelivery_of", deliveryID)
+	return redelivery, nil
+}
+
+// endpointError maps a repository error about the endpoint with the given id onto the service errors
+func endpointError(err error, id int64) error {
+	if errors.Is(err, webhook.ErrEndpointNotFound) {
+		return fmt.Errorf("%w: %d", ErrWebhookEndpointNotFound, id)
+	}
+	return err
+}
@@ -0,0 +1,187 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"testing"
+
+	"moneytransfer/internal/authz"
+	"moneytransfer/internal/event"
+	"moneytransfer/internal/service"
+	"moneytransfer/internal/webhook"
+	"moneytransfer/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// webhookURLs resolves the hosts of the tests without DNS
+var webhookURLs = webhook.URLPolicy{
+	LookupHost: func(_ context.Context, host string) ([]netip.Addr, error) {
+		switch host {
+		case "erp.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+		case "intranet.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.5")}, nil
+		default:
+			return nil, fmt.Errorf("no such host %s", host)
+		}
+	},
+}
+
+func TestWebhookService_RegisterEndpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewWebhookRepositoryMock(ctrl)
+	svc := service.NewWebhookService(mockRepo, nil, webhookURLs, slog.Default(), nil)
+
+	t.Run("Endpoint is registered with a secret", func(t *testing.T) {
+		mockRepo.EXPECT().CreateEndpoint(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *webhook.Endpoint) error {
+			e.ID = 1
+			return nil
+		})
+
+		e, err := svc.RegisterEndpoint(context.Background(), "Test Org", "https://erp.example.com/hooks", []event.Type{event.TypeBatchExecuted})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), e.ID)
+		assert.NotEmpty(t, e.Secret)
+	})
+
+	t.Run("Invalid endpoint is rejected", func(t *testing.T) {
+		_, err := svc.RegisterEndpoint(context.Background(), "Test Org", "not a url", []event.Type{event.TypeBatchExecuted})
+		assert.ErrorIs(t, err, service.ErrInvalidWebhookEndpoint)
+		assert.Equal(t, service.CodeInvalidWebhookEndpoint, service.ErrorCode(err))
+	})
+
+	for name, url := range map[string]string{
+		"Endpoint with an http URL is rejected":        "http://erp.example.com/hooks",
+		"Endpoint on a loopback address is rejected":   "https://127.0.0.1:8080/hooks",
+		"Endpoint on the metadata service is rejected": "https://169.254.169.254/latest/meta-data",
+		"Endpoint resolving to a private address":      "https://intranet.example.com/hooks",
+		"Endpoint of an unknown host is rejected":      "https://unknown.example.com/hooks",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.RegisterEndpoint(context.Background(), "Test Org", url, []event.Type{event.TypeBatchExecuted})
+			assert.ErrorIs(t, err, service.ErrInvalidWebhookEndpoint)
+		})
+	}
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewWebhookRepositoryMock(ctrl)
+	svc := service.NewWebhookService(mockRepo, nil, webhookURLs, slog.Default(), nil)
+
+	dead := &webhook.Delivery{
+		ID:         3,
+		EndpointID: 1,
+		EventSeq:   9,
+		EventType:  event.TypeBatchFailed,
+		Payload:    json.RawMessage(`{"id":9}`),
+		Status:     webhook.DeliveryDead,
+		Attempts:   8,
+	}
+
+	t.Run("Dead delivery is delivered again", func(t *testing.T) {
+		mockRepo.EXPECT().GetEndpoint(gomock.Any(), int64(1)).Return(&webhook.Endpoint{ID: 1, OrganizationName: "Test Org"}, nil)
+		mockRepo.EXPECT().GetDelivery(gomock.Any(), int64(3)).Return(dead, nil)
+		mockRepo.EXPECT().CreateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d *webhook.Delivery) error {
+			d.ID = 4
+			return nil
+		})
+
+		d, err := svc.Redeliver(context.Background(), 1, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), d.ID)
+		assert.Equal(t, int64(3), d.RedeliveryOf)
+		assert.Equal(t, webhook.DeliveryPending, d.Status)
+	})
+
+	t.Run("Delivery of another endpoint is not found", func(t *testing.T) {
+		mockRepo.EXPECT().GetEndpoint(gomock.Any(), int64(2)).Return(&webhook.Endpoint{ID: 2, OrganizationName: "Test Org"}, nil)
+		mockRepo.EXPECT().GetDelivery(gomock.Any(), int64(3)).Return(dead, nil)
+
+		_, err := svc.Redeliver(context.Background(), 2, 3)
+		assert.ErrorIs(t, err, service.ErrWebhookDeliveryNotFound)
+	})
+
+	t.Run("Unknown delivery", func(t *testing.T) {
+		mockRepo.EXPECT().GetEndpoint(gomock.Any(), int64(1)).Return(&webhook.Endpoint{ID: 1, OrganizationName: "Test Org"}, nil)
+		mockRepo.EXPECT().GetDelivery(gomock.Any(), int64(5)).Return(nil, webhook.ErrDeliveryNotFound)
+
+		_, err := svc.Redeliver(context.Background(), 1, 5)
+		assert.ErrorIs(t, err, service.ErrWebhookDeliveryNotFound)
+	})
+}
+
+func TestWebhookService_ListDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewWebhookRepositoryMock(ctrl)
+	svc := service.NewWebhookService(mockRepo, nil, webhookURLs, slog.Default(), nil)
+
+	t.Run("Unknown endpoint", func(t *testing.T) {
+		mockRepo.EXPECT().GetEndpoint(gomock.Any(), int64(7)).Return(nil, webhook.ErrEndpointNotFound)
+
+		_, err := svc.ListDeliveries(context.Background(), 7, 50)
+		assert.ErrorIs(t, err, service.ErrWebhookEndpointNotFound)
+	})
+
+	t.Run("Deliveries with attempts", func(t *testing.T) {
+		want := []webhook.Delivery{{ID: 3, EndpointID: 1, AttemptLog: []webhook.Attempt{{Attempt: 1, StatusCode: 500}}}}
+		mockRepo.EXPECT().GetEndpoint(gomock.Any(), int64(1)).Return(&webhook.Endpoint{ID: 1, OrganizationName: "Test Org"}, nil)
+		mockRepo.EXPECT().ListDeliveries(gomock.Any(), "Test Org", int64(1), 50).Return(want, nil)
+
+		deliveries, err := svc.ListDeliveries(context.Background(), 1, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, want, deliveries)
+	})
+}
+
+func TestWebhookService_EndpointOfAnotherOrganization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewWebhookRepositoryMock(ctrl)
+	mockPolicy := mock.NewAuthorizerMock(ctrl)
+	svc := service.NewWebhookService(mockRepo, nil, webhookURLs, slog.Default(), mockPolicy)
+
+	// The endpoint 1 belongs to Other Org, the policy denies it to the callers of Test Org
+	mockRepo.EXPECT().GetEndpoint(gomock.Any(), int64(1)).Return(&webhook.Endpoint{ID: 1, OrganizationName: "Other Org"}, nil).Times(3)
+	mockPolicy.EXPECT().Authorize(gomock.Any(), authz.ActionManage, authz.Resource{OrganizationName: "Other Org"}).
+		Return(fmt.Errorf("%w: api_key:7 may not manage the organization", authz.ErrDenied)).Times(3)
+
+	t.Run("Endpoint is not disabled", func(t *testing.T) {
+		err := svc.DisableEndpoint(context.Background(), 1)
+		assert.ErrorIs(t, err, service.ErrPermissionDenied)
+	})
+
+	t.Run("Deliveries are not listed", func(t *testing.T) {
+		_, err := svc.ListDeliveries(context.Background(), 1, 50)
+		assert.ErrorIs(t, err, service.ErrPermissionDenied)
+	})
+
+	t.Run("Deliveries are not redelivered", func(t *testing.T) {
+		_, err := svc.Redeliver(context.Background(), 1, 3)
+		assert.ErrorIs(t, err, service.ErrPermissionDenied)
+	})
+}
+
+func TestWebhookService_DisableEndpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mock.NewWebhookRepositoryMock(ctrl)
+	svc := service.NewWebhookService(mockRepo, nil, webhookURLs, slog.Default(), nil)
+
+	t.Run("Endpoint is disabled within its organization", func(t *testing.T) {
+		mockRepo.EXPECT().GetEndpoint(gomock.Any(), int64(1)).Return(&webhook.Endpoint{ID: 1, OrganizationName: "Test Org"}, nil)
+		mockRepo.EXPECT().DisableEndpoint(gomock.Any(), "Test Org", int64(1)).Return(nil)
+
+		assert.NoError(t, svc.DisableEndpoint(context.Background(), 1))
+	})
+
+	t.Run("Unknown endpoint", func(t *testing.T) {
+		mockRepo.EXPECT().GetEndpoint(gomock.Any(), int64(2)).Return(nil, webhook.ErrEndpointNotFound)
+
+		err := svc.DisableEndpoint(context.Background(), 2)
+		assert.ErrorIs(t, err, service.ErrWebhookEndpointNotFound)
+	})
+}
@@ -0,0 +1,13 @@
+// Package signing provides the HMAC signatures of the requests of organizations.
+//
+// A signed request carries the ID of a signing key of its organization, a timestamp,
+// a nonce and an HMAC-SHA256, keyed with the secret of the signing key, of a canonical
+// representation of the request: its method, path, timestamp, nonce and the SHA-256
+// of its body. The body cannot be altered in transit without breaking the signature,
+// and a signature can only be used once, within a window around its timestamp.
+//
+// Key components:
+//   - Key: Struct representing the signing secret of an organization
+//   - Request: Struct representing the signed parts of a request
+//   - Repository: Interface to store signing keys and the nonces already used
+package signing
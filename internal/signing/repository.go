@@ -0,0 +1,28 @@
+package signing
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrKeyNotFound is returned when the requested signing key does not exist
+	ErrKeyNotFound = errors.New("signing key not found")
+	// ErrNonceUsed is returned when a nonce was already used by the organization
+	ErrNonceUsed = errors.New("nonce already used")
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=repository.go -destination=../../mock/signing_repository_mock.go -package=mock -mock_names=Repository=SigningRepositoryMock
+type Repository interface {
+	CreateKey(ctx context.Context, k *Key) error
+	GetKey(ctx context.Context, id int64) (*Key, error)
+	// ListKeys returns the signing keys of the organization, oldest first
+	ListKeys(ctx context.Context, organizationName string) ([]Key, error)
+	// RevokeKey revokes the key, revoking a revoked key keeps its revocation time
+	RevokeKey(ctx context.Context, id int64) error
+
+	// UseNonce records the nonce of the organization until expiresAt, or returns
+	// ErrNonceUsed if it is already recorded. Expired nonces are forgotten.
+	UseNonce(ctx context.Context, organizationName, nonce string, expiresAt time.Time) error
+}
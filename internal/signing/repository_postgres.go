@@ -0,0 +1,120 @@
+package signing
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &postgresRepository{db: db}
+}
+
+const keyColumns = `id, organization_name, secret, created_at, revoked_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanKey(row rowScanner) (*Key, error) {
+	var k Key
+	var revokedAt sql.NullTime
+	if err := row.Scan(&k.ID, &k.OrganizationName, &k.Secret, &k.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	k.RevokedAt = revokedAt.Time
+	return &k, nil
+}
+
+func (r *postgresRepository) CreateKey(ctx context.Context, k *Key) error {
+	query := `
+		INSERT INTO signing_keys (organization_name, secret)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`
+	if err := r.db.QueryRowContext(ctx, query, k.OrganizationName, k.Secret).Scan(&k.ID, &k.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create signing key: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) GetKey(ctx context.Context, id int64) (*Key, error) {
+	k, err := scanKey(r.db.QueryRowContext(ctx, `SELECT `+keyColumns+` FROM signing_keys WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return k, nil
+}
+
+func (r *postgresRepository) ListKeys(ctx context.Context, organizationName string) ([]Key, error) {
+	query := `SELECT ` + keyColumns + ` FROM signing_keys WHERE organization_name = $1 ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, organizationName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []Key
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan signing key: %w", err)
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *postgresRepository) RevokeKey(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE signing_keys SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke signing key: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+func (r *postgresRepository) UseNonce(ctx context.Context, organizationName, nonce string, expiresAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Expired nonces can no longer be replayed, the window of their signatures is over
+	if _, err := tx.ExecContext(ctx, `DELETE FROM request_nonces WHERE organization_name = $1 AND expires_at < now()`, organizationName); err != nil {
+		return fmt.Errorf("failed to delete expired nonces: %w", err)
+	}
+	query := `
+		INSERT INTO request_nonces (organization_name, nonce, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_name, nonce) DO NOTHING
+	`
+	res, err := tx.ExecContext(ctx, query, organizationName, nonce, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to record nonce: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNonceUsed
+	}
+	return tx.Commit()
+}
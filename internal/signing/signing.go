@@ -0,0 +1,146 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// KeyHeader carries the ID of the signing key of a request
+	KeyHeader = "X-Signature-Key"
+	// TimestampHeader carries the time a request was signed, in Unix seconds
+	TimestampHeader = "X-Signature-Timestamp"
+	// NonceHeader carries the nonce of a request, a value the organization never uses twice
+	NonceHeader = "X-Signature-Nonce"
+	// SignatureHeader carries the signature of a request, see Sign
+	SignatureHeader = "X-Signature"
+
+	// signatureVersion prefixes signatures and canonical requests so that the scheme can evolve
+	signatureVersion = "v1"
+	// minNonceLength and maxNonceLength bound the length of nonces
+	minNonceLength = 16
+	maxNonceLength = 128
+)
+
+type Key struct {
+	// ID is the unique identifier for the key
+	// it is generated by the database
+	ID               int64
+	OrganizationName string
+	// Secret is the key of the HMAC of the signatures
+	Secret    string
+	CreatedAt time.Time
+	// RevokedAt is the time the key was revoked, zero if it was not
+	RevokedAt time.Time
+}
+
+// NewKey returns a signing key of the organization with a new random secret
+func NewKey(organizationName string) (*Key, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	return &Key{
+		OrganizationName: organizationName,
+		Secret:           "mtsig_" + hex.EncodeToString(secret),
+	}, nil
+}
+
+func (k *Key) Validate() error {
+	if k.OrganizationName == "" {
+		return errors.New("organization name is required")
+	}
+	if k.Secret == "" {
+		return errors.New("secret is required")
+	}
+	return nil
+}
+
+// Request is the signed representation of a request
+type Request struct {
+	KeyID     int64
+	Method    string
+	Path      string
+	Timestamp time.Time
+	Nonce     string
+	// BodyHash is the hex encoded SHA-256 of the body of the request
+	BodyHash  string
+	Signature string
+}
+
+// ParseRequest returns the signed representation of a request from the values of its
+// signature headers, obtained with header, and the SHA-256 of its body
+func ParseRequest(method, path string, header func(string) string, bodyHash []byte) (Request, error) {
+	r := Request{
+		Method:    method,
+		Path:      path,
+		Nonce:     header(NonceHeader),
+		BodyHash:  hex.EncodeToString(bodyHash),
+		Signature: header(SignatureHeader),
+	}
+
+	var err error
+	if r.KeyID, err = strconv.ParseInt(header(KeyHeader), 10, 64); err != nil || r.KeyID <= 0 {
+		return r, fmt.Errorf("invalid %s header", KeyHeader)
+	}
+	seconds, err := strconv.ParseInt(header(TimestampHeader), 10, 64)
+	if err != nil || seconds <= 0 {
+		return r, fmt.Errorf("invalid %s header, expected Unix seconds", TimestampHeader)
+	}
+	r.Timestamp = time.Unix(seconds, 0)
+	if !isValidNonce(r.Nonce) {
+		return r, fmt.Errorf("invalid %s header, expected %d to %d printable ASCII characters", NonceHeader, minNonceLength, maxNonceLength)
+	}
+	if !strings.HasPrefix(r.Signature, signatureVersion+"=") {
+		return r, fmt.Errorf("invalid %s header, expected %s=<hex encoded HMAC-SHA256>", SignatureHeader, signatureVersion)
+	}
+	return r, nil
+}
+
+// isValidNonce reports whether a nonce has an acceptable length and only printable ASCII characters
+func isValidNonce(nonce string) bool {
+	if len(nonce) < minNonceLength || len(nonce) > maxNonceLength {
+		return false
+	}
+	for i := 0; i < len(nonce); i++ {
+		if nonce[i] < 0x21 || nonce[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Canonical returns the canonical representation of the request that is signed:
+// the signature version, the method, the path, the Unix timestamp in seconds, the
+// nonce and the hex encoded SHA-256 of the body, one per line.
+func (r Request) Canonical() string {
+	return strings.Join([]string{
+		signatureVersion,
+		strings.ToUpper(r.Method),
+		r.Path,
+		strconv.FormatInt(r.Timestamp.Unix(), 10),
+		r.Nonce,
+		r.BodyHash,
+	}, "\n")
+}
+
+// Sign returns the signature of the request: v1= followed by the hex encoded
+// HMAC-SHA256, keyed with secret, of its canonical representation
+func Sign(secret string, r Request) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(r.Canonical()))
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature of the request was made with secret
+func Verify(secret string, r Request) bool {
+	return hmac.Equal([]byte(Sign(secret, r)), []byte(r.Signature))
+}
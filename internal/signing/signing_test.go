@@ -0,0 +1,103 @@
+package signing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKey(t *testing.T) {
+	k, err := NewKey("Test Org")
+
+	assert.NoError(t, err)
+	assert.NoError(t, k.Validate())
+	assert.True(t, strings.HasPrefix(k.Secret, "mtsig_"))
+
+	other, err := NewKey("Test Org")
+	assert.NoError(t, err)
+	assert.NotEqual(t, k.Secret, other.Secret)
+}
+
+func TestSign(t *testing.T) {
+	body := sha256.Sum256([]byte(`{"organization_name":"Test Org"}`))
+	r := Request{
+		KeyID:     1,
+		Method:    "post",
+		Path:      "/api/v1/transfers",
+		Timestamp: time.Unix(1700000000, 0),
+		Nonce:     "0123456789abcdef",
+		BodyHash:  hex.EncodeToString(body[:]),
+	}
+	assert.Equal(t, "v1\nPOST\n/api/v1/transfers\n1700000000\n0123456789abcdef\n"+r.BodyHash, r.Canonical())
+
+	r.Signature = Sign("secret", r)
+	assert.True(t, strings.HasPrefix(r.Signature, "v1="))
+	assert.True(t, Verify("secret", r))
+	assert.False(t, Verify("other secret", r))
+
+	tampered := []func(*Request){
+		func(r *Request) { r.Method = "PUT" },
+		func(r *Request) { r.Path = "/api/v1/transfers/other" },
+		func(r *Request) { r.Timestamp = r.Timestamp.Add(time.Second) },
+		func(r *Request) { r.Nonce = "fedcba9876543210" },
+		func(r *Request) { r.BodyHash = strings.Repeat("0", 64) },
+	}
+	for _, tamper := range tampered {
+		other := r
+		tamper(&other)
+		assert.False(t, Verify("secret", other))
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	valid := map[string]string{
+		KeyHeader:       "7",
+		TimestampHeader: "1700000000",
+		NonceHeader:     "0123456789abcdef",
+		SignatureHeader: "v1=abcd",
+	}
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		wantErr string
+	}{
+		{"Valid headers", "", "", ""},
+		{"Missing key", KeyHeader, "", KeyHeader},
+		{"Invalid key", KeyHeader, "seven", KeyHeader},
+		{"Missing timestamp", TimestampHeader, "", TimestampHeader},
+		{"Timestamp in milliseconds", TimestampHeader, "1700000000.123", TimestampHeader},
+		{"Short nonce", NonceHeader, "0123", NonceHeader},
+		{"Long nonce", NonceHeader, strings.Repeat("a", maxNonceLength+1), NonceHeader},
+		{"Nonce with spaces", NonceHeader, "0123456789 abcdef", NonceHeader},
+		{"Missing signature", SignatureHeader, "", SignatureHeader},
+		{"Unknown signature version", SignatureHeader, "v2=abcd", SignatureHeader},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := func(name string) string {
+				if name == tt.header {
+					return tt.value
+				}
+				return valid[name]
+			}
+			r, err := ParseRequest("POST", "/api/v1/transfers", header, []byte{0xab})
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, int64(7), r.KeyID)
+			assert.Equal(t, "1700000000", strconv.FormatInt(r.Timestamp.Unix(), 10))
+			assert.Equal(t, "0123456789abcdef", r.Nonce)
+			assert.Equal(t, "ab", r.BodyHash)
+			assert.Equal(t, "v1=abcd", r.Signature)
+		})
+	}
+}
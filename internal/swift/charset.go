@@ -0,0 +1,93 @@
+package swift
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// xCharacters are the characters of the SWIFT X character set besides letters and digits
+const xCharacters = "/-?:().,'+ "
+
+// isX reports whether r is in the SWIFT X character set
+func isX(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(xCharacters, r)
+}
+
+// checkX returns an error for the first character of s that is not in the SWIFT X character set
+func checkX(s string) error {
+	for i, r := range s {
+		if !isX(r) {
+			return fmt.Errorf("character %q at position %d is not in the SWIFT X character set", r, utf8.RuneCountInString(s[:i])+1)
+		}
+	}
+	return nil
+}
+
+// transliterations replaces the accented letters of the Latin alphabets with their base letters,
+// and the dashes, quotes and separators with their closest characters of the X character set
+var transliterations = strings.NewReplacer(
+	"À", "A", "Á", "A", "Â", "A", "Ã", "A", "Ä", "A", "Å", "A", "Æ", "AE", "Ç", "C",
+	"È", "E", "É", "E", "Ê", "E", "Ë", "E", "Ì", "I", "Í", "I", "Î", "I", "Ï", "I",
+	"Ñ", "N", "Ò", "O", "Ó", "O", "Ô", "O", "Õ", "O", "Ö", "O", "Ø", "O", "Œ", "OE",
+	"Ù", "U", "Ú", "U", "Û", "U", "Ü", "U", "Ý", "Y", "ß", "ss",
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "æ", "ae", "ç", "c",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ì", "i", "í", "i", "î", "i", "ï", "i",
+	"ñ", "n", "ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "œ", "oe",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y",
+	"–", "-", "—", "-", "‘", "'", "’", "'", "“", "'", "”", "'", "\"", "'",
+	"&", "+", "\t", " ", "\r", " ", "\n", " ",
+)
+
+// ToX transliterates s to the SWIFT X character set: accented letters lose their accent
+// and the other characters that are not in the set are replaced with a dot
+func ToX(s string) string {
+	s = transliterations.Replace(s)
+	return strings.Map(func(r rune) rune {
+		if isX(r) {
+			return r
+		}
+		return '.'
+	}, s)
+}
+
+// wrap splits the words of s into at most maxLines lines of at most width characters,
+// words longer than a line are cut and the text that does not fit is dropped.
+// s must be in the SWIFT X character set, so that its characters are single bytes.
+func wrap(s string, width, maxLines int) []string {
+	var lines []string
+	line := ""
+	// flush ends the current line and reports whether another line may follow
+	flush := func() bool {
+		lines = append(lines, line)
+		line = ""
+		return len(lines) < maxLines
+	}
+	for _, word := range strings.Fields(s) {
+		for len(word) > width {
+			if line != "" && !flush() {
+				return lines
+			}
+			line, word = word[:width], word[width:]
+			if !flush() {
+				return lines
+			}
+		}
+		switch {
+		case word == "":
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= width:
+			line += " " + word
+		default:
+			if !flush() {
+				return lines
+			}
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,21 @@
+package swift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToX(t *testing.T) {
+	assert.Equal(t, "Societe Generale - Facture n. 12/A", ToX("Société Générale – Facture n° 12/A"))
+	assert.Equal(t, "Mueller + Soehne Strasse", ToX("Mueller & Soehne Strasse"))
+	assert.Equal(t, "Line one line two", ToX("Line one\nline two"))
+	assert.Equal(t, "Gruss..", ToX("Gruß@#"))
+}
+
+func TestWrap(t *testing.T) {
+	assert.Equal(t, []string{"ONE TWO", "THREE"}, wrap("ONE TWO THREE", 7, 3))
+	assert.Equal(t, []string{"ABCDE", "FGH I"}, wrap("ABCDEFGH I", 5, 3))
+	assert.Equal(t, []string{"ONE", "TWO"}, wrap("ONE TWO THREE", 3, 2))
+	assert.Nil(t, wrap("   ", 5, 2))
+}
@@ -0,0 +1,13 @@
+// Package swift provides the SWIFT FIN messages exchanged with corporate customers.
+//
+// The text block of a FIN message is a sequence of fields, each made of a tag such as :20:
+// and of one or more lines of content. Field contents are restricted to the SWIFT X character
+// set, and every field has a format that limits the number and the length of its lines.
+// Messages are read with or without their basic, application and trailer blocks.
+//
+// Key components:
+//   - ReadMT101: Streams the transactions of MT101 requests for transfer
+//   - MT940Writer: Streams an MT940 customer statement, split into pages at the FIN length limit
+//   - Party: Account, name and BIC of an ordering customer or a beneficiary
+//   - ToX: Transliterates text to the SWIFT X character set
+package swift
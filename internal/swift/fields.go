@@ -0,0 +1,200 @@
+package swift
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// field is a field of the text block of a message, with the line its tag was read at
+type field struct {
+	tag   string
+	lines []string
+	line  int
+}
+
+// FieldError reports a field of a message that failed validation
+type FieldError struct {
+	// Line is the 1-based line of the input the tag of the field was read at
+	Line int
+	Tag  string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("line %d: field :%s: %v", e.Line, e.Tag, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// fieldError returns an error reporting the field
+func fieldError(f field, format string, args ...any) error {
+	return &FieldError{Line: f.line, Tag: f.tag, Err: fmt.Errorf(format, args...)}
+}
+
+// tagLine matches the first line of a field, such as :32B:EUR100,
+var tagLine = regexp.MustCompile(`^:([0-9]{2}[A-Z]?):(.*)$`)
+
+// messageReader reads the text blocks of FIN messages one after the other.
+// Messages are either complete, with their text block between {4: and -}, or
+// made of their text block only and ended by a line holding a single hyphen.
+type messageReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newMessageReader(r io.Reader) *messageReader {
+	return &messageReader{scanner: bufio.NewScanner(r)}
+}
+
+// next returns the fields of the next message along with the line it starts at,
+// and no fields once the input is exhausted
+func (m *messageReader) next() ([]field, int, error) {
+	var fields []field
+	start := 0
+	inText := false
+	for m.scanner.Scan() {
+		m.line++
+		line := strings.TrimRight(m.scanner.Text(), " ")
+		if m.line == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if !inText {
+			// The basic, application and user header blocks precede the text block
+			if i := strings.Index(line, "{4:"); i >= 0 {
+				inText, start = true, m.line
+				line = line[i+len("{4:"):]
+				if line == "" {
+					continue
+				}
+			} else if strings.HasPrefix(line, ":") {
+				inText, start = true, m.line
+			} else if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "{") {
+				continue
+			} else {
+				return nil, 0, fmt.Errorf("line %d: expected the start of a message, got %q", m.line, line)
+			}
+		}
+
+		// The text block ends with a hyphen, followed by the trailer block in complete messages
+		if line == "-" || strings.HasPrefix(line, "-}") {
+			if len(fields) == 0 {
+				return nil, 0, fmt.Errorf("line %d: message has no field", start)
+			}
+			return fields, start, nil
+		}
+		if match := tagLine.FindStringSubmatch(line); match != nil {
+			fields = append(fields, field{tag: match[1], lines: []string{match[2]}, line: m.line})
+			continue
+		}
+		if len(fields) == 0 {
+			return nil, 0, fmt.Errorf("line %d: expected a field tag, got %q", m.line, line)
+		}
+		last := &fields[len(fields)-1]
+		last.lines = append(last.lines, line)
+	}
+	if err := m.scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	if inText {
+		// The final hyphen of a text block may be left out at the end of the input
+		if len(fields) == 0 {
+			return nil, 0, fmt.Errorf("line %d: message has no field", start)
+		}
+		return fields, start, nil
+	}
+	return nil, 0, nil
+}
+
+// fieldFormat is the number and the length of the lines of the content of a field
+type fieldFormat struct {
+	lines int
+	width int
+}
+
+// check validates the lines of the field against the format and the X character set
+func (ff fieldFormat) check(f field) error {
+	if len(f.lines) > ff.lines {
+		return fieldError(f, "has %d lines, at most %d are allowed", len(f.lines), ff.lines)
+	}
+	if len(f.lines) == 1 && f.lines[0] == "" {
+		return fieldError(f, "is empty")
+	}
+	for i, line := range f.lines {
+		if n := len([]rune(line)); n > ff.width {
+			return fieldError(f, "line %d is %d characters long, at most %d are allowed", i+1, n, ff.width)
+		}
+		if err := checkX(line); err != nil {
+			return fieldError(f, "line %d: %v", i+1, err)
+		}
+		if i > 0 && (strings.HasPrefix(line, ":") || strings.HasPrefix(line, "-")) {
+			return fieldError(f, "line %d must not start with %q", i+1, line[:1])
+		}
+	}
+	return nil
+}
+
+var (
+	// bicPattern matches a BIC of 8 or 11 characters
+	bicPattern = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	// amountPattern matches a currency followed by an amount of format 15d, with a decimal comma
+	amountPattern = regexp.MustCompile(`^([A-Z]{3})([0-9]+,[0-9]*)$`)
+)
+
+// errInvalidReference is returned for references that start or end with a slash or hold two consecutive slashes
+var errInvalidReference = errors.New("must not start or end with a slash nor contain two consecutive slashes")
+
+// checkReference validates a reference of format 16x
+func checkReference(f field) error {
+	ref := f.lines[0]
+	if strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") || strings.Contains(ref, "//") {
+		return &FieldError{Line: f.line, Tag: f.tag, Err: errInvalidReference}
+	}
+	return nil
+}
+
+// parseAmount parses a currency and an amount of format 3!a15d, and returns the amount
+// as a decimal number with a decimal point
+func parseAmount(f field) (currency, amount string, err error) {
+	match := amountPattern.FindStringSubmatch(f.lines[0])
+	if match == nil {
+		return "", "", fieldError(f, "expected a currency code followed by an amount with a decimal comma, got %q", f.lines[0])
+	}
+	whole, fraction, _ := strings.Cut(match[2], ",")
+	if fraction == "" {
+		return match[1], whole, nil
+	}
+	return match[1], whole + "." + fraction, nil
+}
+
+// parseAccount parses a line of format /34x identifying an account
+func parseAccount(f field, line string) (string, error) {
+	account, ok := strings.CutPrefix(line, "/")
+	if !ok || account == "" || len(account) > 34 {
+		return "", fieldError(f, "expected an account of format /34x, got %q", line)
+	}
+	return account, nil
+}
+
+// parseBIC parses a line holding a BIC
+func parseBIC(f field, line string) (string, error) {
+	if !bicPattern.MatchString(line) {
+		return "", fieldError(f, "invalid BIC %q", line)
+	}
+	return line, nil
+}
+
+// parseOptionA parses a field of option A: an optional party identifier line followed by a BIC
+func parseOptionA(f field) (string, error) {
+	line := f.lines[len(f.lines)-1]
+	if len(f.lines) == 2 && !strings.HasPrefix(f.lines[0], "/") {
+		return "", fieldError(f, "expected a party identifier starting with a slash, got %q", f.lines[0])
+	}
+	return parseBIC(f, line)
+}
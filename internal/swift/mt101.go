@@ -0,0 +1,400 @@
+package swift
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MessageTypeMT101 is the name of MT101 request for transfer messages
+const MessageTypeMT101 = "MT101"
+
+// MT101 is sequence A of an MT101 request for transfer, the general information
+// shared by all its transactions
+type MT101 struct {
+	// SenderReference is field 20, the reference of the message given by its sender
+	SenderReference string
+	// CustomerReference is field 21R, the reference of a set of messages
+	CustomerReference string
+	// MessageIndex and MessageTotal are field 28D, the position of the message in its set
+	MessageIndex int
+	MessageTotal int
+	// OrderingCustomer is field 50F, 50G or 50H, when it is shared by all the transactions
+	OrderingCustomer *Party
+	// AccountServicingInstitution is the BIC of field 52A, the bank of the ordering customer
+	AccountServicingInstitution string
+	// RequestedExecutionDate is field 30
+	RequestedExecutionDate time.Time
+	// Line is the line of the input the message starts at
+	Line int
+}
+
+// MT101Transaction is a sequence B of an MT101 request for transfer
+type MT101Transaction struct {
+	// Reference is field 21, the reference of the transaction
+	Reference string
+	// Currency and Amount are field 32B, the amount is a decimal number with a decimal point
+	Currency string
+	Amount   string
+	// OrderingCustomer is field 50F, 50G or 50H, when it is not given in sequence A
+	OrderingCustomer *Party
+	// AccountWithInstitution is the BIC of field 57A, the bank of the beneficiary
+	AccountWithInstitution string
+	// Beneficiary is field 59, 59A or 59F
+	Beneficiary Party
+	// RemittanceInformation is field 70, its lines joined with spaces
+	RemittanceInformation string
+	// Charges is field 71A: OUR, SHA or BEN
+	Charges string
+	// Line is the line of the input the transaction starts at
+	Line int
+}
+
+// Party identifies an ordering customer or a beneficiary
+type Party struct {
+	// Account is the account of the party, without its leading slash
+	Account string
+	Name    string
+	Address []string
+	BIC     string
+}
+
+var (
+	// mt101SequenceA are the formats of the fields of sequence A
+	mt101SequenceA = map[string]fieldFormat{
+		"20": {1, 16}, "21R": {1, 16}, "28D": {1, 11},
+		"50C": {1, 11}, "50L": {1, 35}, "50F": {5, 35}, "50G": {2, 35}, "50H": {5, 35},
+		"52A": {2, 35}, "52C": {1, 35}, "51A": {2, 35}, "30": {1, 6}, "25": {1, 35},
+	}
+	// mt101SequenceB are the formats of the fields of sequence B
+	mt101SequenceB = map[string]fieldFormat{
+		"21": {1, 16}, "21F": {1, 16}, "23E": {1, 35}, "32B": {1, 18},
+		"50C": {1, 11}, "50L": {1, 35}, "50F": {5, 35}, "50G": {2, 35}, "50H": {5, 35},
+		"52A": {2, 35}, "52C": {1, 35}, "56A": {2, 35}, "56C": {1, 35}, "56D": {5, 35},
+		"57A": {2, 35}, "57C": {1, 35}, "57D": {5, 35}, "59": {5, 35}, "59A": {2, 35}, "59F": {5, 35},
+		"70": {4, 35}, "77B": {3, 35}, "33B": {1, 18}, "71A": {1, 3}, "25A": {1, 35}, "36": {1, 12},
+	}
+
+	messageIndexPattern    = regexp.MustCompile(`^([0-9]{1,5})/([0-9]{1,5})$`)
+	instructionCodePattern = regexp.MustCompile(`^[A-Z0-9]{4}(/.{1,30})?$`)
+	exchangeRatePattern    = regexp.MustCompile(`^[0-9]+,[0-9]*$`)
+	structuredLinePattern  = regexp.MustCompile(`^([1-8])/(.+)$`)
+)
+
+// ReadMT101 reads the MT101 messages of r and streams their transactions to yield, along
+// with the sequence A of the message they belong to, so that large files are never held
+// in memory. Every field is checked against its format, the SWIFT X character set and
+// the rules on the presence of fields in each sequence. The sequences A of the messages
+// are returned once the whole input has been read.
+// An error returned by yield stops the reading and is returned as is.
+func ReadMT101(r io.Reader, yield func(*MT101, *MT101Transaction) error) ([]MT101, error) {
+	reader := newMessageReader(r)
+	var messages []MT101
+	for {
+		fields, start, err := reader.next()
+		if err != nil {
+			return nil, err
+		}
+		if fields == nil {
+			break
+		}
+		msg, err := readMT101Message(fields, start, yield)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *msg)
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no MT101 message found")
+	}
+	return messages, nil
+}
+
+// readMT101Message reads a message whose fields were read from the line start
+func readMT101Message(fields []field, start int, yield func(*MT101, *MT101Transaction) error) (*MT101, error) {
+	msg := &MT101{Line: start}
+	i := 0
+	seen := map[string]bool{}
+	for ; i < len(fields) && fields[i].tag != "21"; i++ {
+		f := fields[i]
+		if err := checkField(f, mt101SequenceA, seen, "sequence A"); err != nil {
+			return nil, err
+		}
+		if err := msg.set(f); err != nil {
+			return nil, err
+		}
+	}
+	for _, tag := range []string{"20", "28D", "30"} {
+		if !seen[tag] {
+			return nil, fmt.Errorf("line %d: mandatory field :%s: is missing from sequence A", start, tag)
+		}
+	}
+	if i == len(fields) {
+		return nil, fmt.Errorf("line %d: message %q has no transaction", start, msg.SenderReference)
+	}
+
+	for i < len(fields) {
+		tx := &MT101Transaction{Line: fields[i].line}
+		seen := map[string]bool{}
+		for ; i < len(fields); i++ {
+			f := fields[i]
+			if f.tag == "21" && seen["21"] {
+				break
+			}
+			if err := checkField(f, mt101SequenceB, seen, "sequence B"); err != nil {
+				return nil, err
+			}
+			if err := tx.set(f); err != nil {
+				return nil, err
+			}
+		}
+		for _, tag := range []string{"32B", "59", "71A"} {
+			if !seen[tag] {
+				return nil, fmt.Errorf("line %d: mandatory field :%s: is missing from transaction %q", tx.Line, tag, tx.Reference)
+			}
+		}
+		// The ordering customer is given either once in sequence A or in every sequence B
+		switch {
+		case msg.OrderingCustomer != nil && tx.OrderingCustomer != nil:
+			return nil, fmt.Errorf("line %d: the ordering customer of transaction %q is already given in sequence A", tx.Line, tx.Reference)
+		case msg.OrderingCustomer == nil && tx.OrderingCustomer == nil:
+			return nil, fmt.Errorf("line %d: the ordering customer of transaction %q is missing from sequences A and B", tx.Line, tx.Reference)
+		}
+		if err := yield(msg, tx); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// checkField checks that the field is allowed in the sequence, is not repeated and follows its format.
+// Fields of the same number but of another option, such as 50C and 50H, are different fields, except for
+// the ordering customer, the beneficiary and the agents, which are given once in one of their options.
+func checkField(f field, formats map[string]fieldFormat, seen map[string]bool, sequence string) error {
+	format, ok := formats[f.tag]
+	if !ok {
+		return fieldError(f, "is not allowed in %s", sequence)
+	}
+	key := f.tag
+	switch {
+	case f.tag == "50F" || f.tag == "50G" || f.tag == "50H":
+		key = "50 ordering customer"
+	case f.tag == "50C" || f.tag == "50L":
+		key = "50 instructing party"
+	case strings.HasPrefix(f.tag, "52"), strings.HasPrefix(f.tag, "56"), strings.HasPrefix(f.tag, "57"), strings.HasPrefix(f.tag, "59"):
+		key = f.tag[:2]
+	}
+	if seen[key] {
+		return fieldError(f, "is repeated in %s", sequence)
+	}
+	seen[key], seen[f.tag] = true, true
+	return format.check(f)
+}
+
+// set sets the field of sequence A
+func (m *MT101) set(f field) error {
+	value := f.lines[0]
+	switch f.tag {
+	case "20":
+		m.SenderReference = value
+		return checkReference(f)
+	case "21R":
+		m.CustomerReference = value
+		return checkReference(f)
+	case "28D":
+		match := messageIndexPattern.FindStringSubmatch(value)
+		if match == nil {
+			return fieldError(f, "expected a message index and total of format 5n/5n, got %q", value)
+		}
+		m.MessageIndex, _ = strconv.Atoi(match[1])
+		m.MessageTotal, _ = strconv.Atoi(match[2])
+		if m.MessageIndex < 1 || m.MessageIndex > m.MessageTotal {
+			return fieldError(f, "message index %d is not between 1 and the total of %d", m.MessageIndex, m.MessageTotal)
+		}
+	case "50F", "50G", "50H":
+		party, err := parseOrderingCustomer(f)
+		if err != nil {
+			return err
+		}
+		m.OrderingCustomer = party
+	case "50C":
+		_, err := parseBIC(f, value)
+		return err
+	case "52A":
+		bic, err := parseOptionA(f)
+		if err != nil {
+			return err
+		}
+		m.AccountServicingInstitution = bic
+	case "51A":
+		_, err := parseOptionA(f)
+		return err
+	case "52C":
+		_, err := parseAccount(f, value)
+		return err
+	case "30":
+		date, err := time.Parse("060102", value)
+		if err != nil {
+			return fieldError(f, "expected a date of format YYMMDD, got %q", value)
+		}
+		m.RequestedExecutionDate = date
+	}
+	return nil
+}
+
+// set sets the field of sequence B
+func (t *MT101Transaction) set(f field) error {
+	value := f.lines[0]
+	switch f.tag {
+	case "21":
+		t.Reference = value
+		return checkReference(f)
+	case "21F":
+		return checkReference(f)
+	case "23E":
+		if !instructionCodePattern.MatchString(value) {
+			return fieldError(f, "expected an instruction code of format 4!c[/30x], got %q", value)
+		}
+	case "32B":
+		currency, amount, err := parseAmount(f)
+		if err != nil {
+			return err
+		}
+		t.Currency, t.Amount = currency, amount
+	case "33B":
+		_, _, err := parseAmount(f)
+		return err
+	case "36":
+		if !exchangeRatePattern.MatchString(value) {
+			return fieldError(f, "expected an exchange rate with a decimal comma, got %q", value)
+		}
+	case "50F", "50G", "50H":
+		party, err := parseOrderingCustomer(f)
+		if err != nil {
+			return err
+		}
+		t.OrderingCustomer = party
+	case "50C":
+		_, err := parseBIC(f, value)
+		return err
+	case "52A", "56A":
+		_, err := parseOptionA(f)
+		return err
+	case "57A":
+		bic, err := parseOptionA(f)
+		if err != nil {
+			return err
+		}
+		t.AccountWithInstitution = bic
+	case "52C", "56C", "57C", "25A":
+		_, err := parseAccount(f, value)
+		return err
+	case "59", "59A", "59F":
+		party, err := parseBeneficiary(f)
+		if err != nil {
+			return err
+		}
+		t.Beneficiary = *party
+	case "70":
+		t.RemittanceInformation = strings.Join(f.lines, " ")
+	case "71A":
+		if value != "OUR" && value != "SHA" && value != "BEN" {
+			return fieldError(f, "expected OUR, SHA or BEN, got %q", value)
+		}
+		t.Charges = value
+	}
+	return nil
+}
+
+// parseOrderingCustomer parses field 50F, 50G or 50H
+func parseOrderingCustomer(f field) (*Party, error) {
+	account, err := parseAccount(f, f.lines[0])
+	if err != nil {
+		return nil, err
+	}
+	party := &Party{Account: account}
+	switch f.tag {
+	case "50G":
+		if len(f.lines) != 2 {
+			return nil, fieldError(f, "expected an account followed by a BIC")
+		}
+		party.BIC, err = parseBIC(f, f.lines[1])
+		if err != nil {
+			return nil, err
+		}
+	case "50F":
+		if err := parseStructuredParty(f, party); err != nil {
+			return nil, err
+		}
+	default:
+		if len(f.lines) < 2 {
+			return nil, fieldError(f, "expected an account followed by a name")
+		}
+		party.Name, party.Address = f.lines[1], f.lines[2:]
+	}
+	return party, nil
+}
+
+// parseBeneficiary parses field 59, 59A or 59F, whose account is optional except in option F
+func parseBeneficiary(f field) (*Party, error) {
+	party := &Party{}
+	lines := f.lines
+	if strings.HasPrefix(lines[0], "/") || f.tag == "59F" {
+		account, err := parseAccount(f, lines[0])
+		if err != nil {
+			return nil, err
+		}
+		party.Account, lines = account, lines[1:]
+	}
+	switch f.tag {
+	case "59A":
+		if len(lines) != 1 {
+			return nil, fieldError(f, "expected an optional account followed by a BIC")
+		}
+		bic, err := parseBIC(f, lines[0])
+		if err != nil {
+			return nil, err
+		}
+		party.BIC = bic
+	case "59F":
+		if err := parseStructuredParty(f, party); err != nil {
+			return nil, err
+		}
+	default:
+		if len(lines) == 0 || len(lines) > 4 {
+			return nil, fieldError(f, "expected an optional account followed by 1 to 4 lines of name and address")
+		}
+		party.Name, party.Address = lines[0], lines[1:]
+	}
+	return party, nil
+}
+
+// parseStructuredParty parses the numbered lines of option F, such as 1/NAME and 2/STREET,
+// which follow the account
+func parseStructuredParty(f field, party *Party) error {
+	if len(f.lines) < 2 {
+		return fieldError(f, "expected an account followed by numbered lines of name and address")
+	}
+	for i, line := range f.lines[1:] {
+		match := structuredLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			return fieldError(f, "line %d: expected a line of format n/33x, got %q", i+2, line)
+		}
+		switch match[1] {
+		case "1":
+			if party.Name != "" {
+				party.Name += " "
+			}
+			party.Name += match[2]
+		case "2", "3":
+			party.Address = append(party.Address, match[2])
+		}
+	}
+	if party.Name == "" {
+		return fieldError(f, "the name line 1/ is missing")
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package swift
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mt101 is a complete MT101 message whose ordering customer is given in sequence A
+const mt101 = "{1:F01BNPAFRPPAXXX0000000000}{2:I101BNPAFRPPXXXXN}{4:\r\n" +
+	":20:MSG-1\r\n" +
+	":28D:1/1\r\n" +
+	":50H:/FR1420041010050500013M02606\r\n" +
+	"ACME CORP\r\n" +
+	"1 RUE DE LA PAIX\r\n" +
+	":52A:BNPAFRPP\r\n" +
+	":30:240304\r\n" +
+	":21:E2E-1\r\n" +
+	":32B:EUR100,5\r\n" +
+	":57A:DEUTDEFF\r\n" +
+	":59:/DE89370400440532013000\r\n" +
+	"JOHN DOE\r\n" +
+	":70:INVOICE 1\r\n" +
+	"PART TWO\r\n" +
+	":71A:SHA\r\n" +
+	":21:E2E-2\r\n" +
+	":32B:EUR50,\r\n" +
+	":59F:/NL91ABNA0417164300\r\n" +
+	"1/JANE\r\n" +
+	"1/DOE\r\n" +
+	"2/DAMRAK 1\r\n" +
+	":71A:OUR\r\n" +
+	"-}{5:{CHK:123456789ABC}}\r\n"
+
+// mt101TextOnly is a text block only MT101 message whose ordering customer is given in each transaction
+const mt101TextOnly = ":20:MSG-2\n" +
+	":21R:BATCH\n" +
+	":28D:1/2\n" +
+	":30:240305\n" +
+	":21:E2E-3\n" +
+	":32B:EUR0,01\n" +
+	":50G:/FR1420041010050500013M02606\n" +
+	"BNPAFRPP\n" +
+	":59A:/DE89370400440532013000\n" +
+	"DEUTDEFF\n" +
+	":71A:BEN\n" +
+	"-\n"
+
+type readTransaction struct {
+	message string
+	tx      MT101Transaction
+}
+
+func readAll(t *testing.T, content string) ([]MT101, []readTransaction, error) {
+	t.Helper()
+	var txs []readTransaction
+	messages, err := ReadMT101(strings.NewReader(content), func(msg *MT101, tx *MT101Transaction) error {
+		txs = append(txs, readTransaction{message: msg.SenderReference, tx: *tx})
+		return nil
+	})
+	return messages, txs, err
+}
+
+func TestReadMT101(t *testing.T) {
+	messages, txs, err := readAll(t, mt101+mt101TextOnly)
+	require.NoError(t, err)
+
+	require.Len(t, messages, 2)
+	assert.Equal(t, "MSG-1", messages[0].SenderReference)
+	assert.Equal(t, 1, messages[0].MessageIndex)
+	assert.Equal(t, 1, messages[0].MessageTotal)
+	assert.Equal(t, &Party{Account: "FR1420041010050500013M02606", Name: "ACME CORP", Address: []string{"1 RUE DE LA PAIX"}}, messages[0].OrderingCustomer)
+	assert.Equal(t, "BNPAFRPP", messages[0].AccountServicingInstitution)
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), messages[0].RequestedExecutionDate)
+	assert.Equal(t, 1, messages[0].Line)
+	assert.Equal(t, "BATCH", messages[1].CustomerReference)
+	assert.Nil(t, messages[1].OrderingCustomer)
+	assert.Equal(t, 25, messages[1].Line)
+
+	require.Len(t, txs, 3)
+	assert.Equal(t, readTransaction{message: "MSG-1", tx: MT101Transaction{
+		Reference:              "E2E-1",
+		Currency:               "EUR",
+		Amount:                 "100.5",
+		AccountWithInstitution: "DEUTDEFF",
+		Beneficiary:            Party{Account: "DE89370400440532013000", Name: "JOHN DOE", Address: []string{}},
+		RemittanceInformation:  "INVOICE 1 PART TWO",
+		Charges:                "SHA",
+		Line:                   9,
+	}}, txs[0])
+	assert.Equal(t, "50", txs[1].tx.Amount)
+	assert.Equal(t, Party{Account: "NL91ABNA0417164300", Name: "JANE DOE", Address: []string{"DAMRAK 1"}}, txs[1].tx.Beneficiary)
+	assert.Equal(t, "MSG-2", txs[2].message)
+	assert.Equal(t, "0.01", txs[2].tx.Amount)
+	assert.Equal(t, &Party{Account: "FR1420041010050500013M02606", BIC: "BNPAFRPP"}, txs[2].tx.OrderingCustomer)
+	assert.Equal(t, Party{Account: "DE89370400440532013000", BIC: "DEUTDEFF"}, txs[2].tx.Beneficiary)
+}
+
+func TestReadMT101_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "Missing mandatory field of sequence A",
+			content: strings.Replace(mt101TextOnly, ":30:240305\n", "", 1),
+			wantErr: "line 1: mandatory field :30: is missing from sequence A",
+		},
+		{
+			name:    "Missing mandatory field of sequence B",
+			content: strings.Replace(mt101TextOnly, ":71A:BEN\n", "", 1),
+			wantErr: `line 5: mandatory field :71A: is missing from transaction "E2E-3"`,
+		},
+		{
+			name:    "Message without transaction",
+			content: ":20:MSG-2\n:28D:1/1\n:30:240305\n-\n",
+			wantErr: `line 1: message "MSG-2" has no transaction`,
+		},
+		{
+			name:    "Field not allowed in the sequence",
+			content: strings.Replace(mt101TextOnly, ":30:240305\n", ":30:240305\n:71A:SHA\n", 1),
+			wantErr: "line 5: field :71A: is not allowed in sequence A",
+		},
+		{
+			name:    "Repeated field",
+			content: strings.Replace(mt101TextOnly, ":71A:BEN\n", ":71A:BEN\n:71A:SHA\n", 1),
+			wantErr: "line 12: field :71A: is repeated in sequence B",
+		},
+		{
+			name:    "Repeated beneficiary in another option",
+			content: strings.Replace(mt101TextOnly, ":71A:BEN\n", ":59:JOHN DOE\n:71A:BEN\n", 1),
+			wantErr: "line 11: field :59: is repeated in sequence B",
+		},
+		{
+			name:    "Ordering customer in both sequences",
+			content: strings.Replace(mt101, ":71A:OUR\r\n", ":50G:/FR1420041010050500013M02606\r\nBNPAFRPP\r\n:71A:OUR\r\n", 1),
+			wantErr: `line 17: the ordering customer of transaction "E2E-2" is already given in sequence A`,
+		},
+		{
+			name:    "Ordering customer missing",
+			content: strings.Replace(mt101TextOnly, ":50G:/FR1420041010050500013M02606\nBNPAFRPP\n", "", 1),
+			wantErr: `line 5: the ordering customer of transaction "E2E-3" is missing from sequences A and B`,
+		},
+		{
+			name:    "Character outside of the X character set",
+			content: strings.Replace(mt101, "JOHN DOE", "JOHN DOE & CO", 1),
+			wantErr: `line 12: field :59: line 2: character '&' at position 10 is not in the SWIFT X character set`,
+		},
+		{
+			name:    "Line too long",
+			content: strings.Replace(mt101, "PART TWO", strings.Repeat("X", 36), 1),
+			wantErr: "line 14: field :70: line 2 is 36 characters long, at most 35 are allowed",
+		},
+		{
+			name:    "Too many lines",
+			content: strings.Replace(mt101, "PART TWO\r\n", "2\r\n3\r\n4\r\n5\r\n", 1),
+			wantErr: "line 14: field :70: has 5 lines, at most 4 are allowed",
+		},
+		{
+			name:    "Reference too long",
+			content: strings.Replace(mt101, ":21:E2E-1", ":21:"+strings.Repeat("E", 17), 1),
+			wantErr: "line 9: field :21: line 1 is 17 characters long, at most 16 are allowed",
+		},
+		{
+			name:    "Reference with consecutive slashes",
+			content: strings.Replace(mt101, ":20:MSG-1", ":20:MSG//1", 1),
+			wantErr: "line 2: field :20: must not start or end with a slash nor contain two consecutive slashes",
+		},
+		{
+			name:    "Message index beyond the total",
+			content: strings.Replace(mt101TextOnly, ":28D:1/2", ":28D:3/2", 1),
+			wantErr: "line 3: field :28D: message index 3 is not between 1 and the total of 2",
+		},
+		{
+			name:    "Invalid date",
+			content: strings.Replace(mt101TextOnly, ":30:240305", ":30:240230", 1),
+			wantErr: `line 4: field :30: expected a date of format YYMMDD, got "240230"`,
+		},
+		{
+			name:    "Amount with a decimal point",
+			content: strings.Replace(mt101TextOnly, ":32B:EUR0,01", ":32B:EUR0.01", 1),
+			wantErr: `line 6: field :32B: expected a currency code followed by an amount with a decimal comma, got "EUR0.01"`,
+		},
+		{
+			name:    "Amount too long",
+			content: strings.Replace(mt101TextOnly, ":32B:EUR0,01", ":32B:EUR1234567890123,45", 1),
+			wantErr: "line 6: field :32B: line 1 is 19 characters long, at most 18 are allowed",
+		},
+		{
+			name:    "Invalid BIC",
+			content: strings.Replace(mt101, ":57A:DEUTDEFF", ":57A:DEUT", 1),
+			wantErr: `line 11: field :57A: invalid BIC "DEUT"`,
+		},
+		{
+			name:    "Invalid charges",
+			content: strings.Replace(mt101TextOnly, ":71A:BEN", ":71A:ALL", 1),
+			wantErr: `line 11: field :71A: expected OUR, SHA or BEN, got "ALL"`,
+		},
+		{
+			name:    "Structured beneficiary without name",
+			content: strings.Replace(mt101, "1/JANE\r\n1/DOE\r\n", "", 1),
+			wantErr: "line 19: field :59F: the name line 1/ is missing",
+		},
+		{
+			name:    "Text before the first field",
+			content: "MT101\n" + mt101TextOnly,
+			wantErr: `line 1: expected the start of a message, got "MT101"`,
+		},
+		{
+			name:    "No message",
+			content: "\n",
+			wantErr: "no MT101 message found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := readAll(t, tt.content)
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.wantErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestReadMT101_FieldError(t *testing.T) {
+	_, _, err := readAll(t, strings.Replace(mt101, ":20:MSG-1", ":20:/MSG-1", 1))
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, 2, fieldErr.Line)
+	assert.Equal(t, "20", fieldErr.Tag)
+	assert.ErrorIs(t, err, errInvalidReference)
+}
+
+func TestReadMT101_YieldError(t *testing.T) {
+	stop := errors.New("stop")
+	var calls int
+	_, err := ReadMT101(strings.NewReader(mt101), func(*MT101, *MT101Transaction) error {
+		calls++
+		return stop
+	})
+
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+}
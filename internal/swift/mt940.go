@@ -0,0 +1,222 @@
+package swift
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MessageTypeMT940 is the name of MT940 customer statement messages
+	MessageTypeMT940 = "MT940"
+	// maxMessageLength is the maximum number of characters of the text block of a FIN message
+	maxMessageLength = 2000
+	// balanceReserve is the length reserved at the end of a page for its closing balance and
+	// the end of its text block: :62M:, a mark, a date, a currency and a 15 characters amount
+	balanceReserve = len(":62M:D060102EUR\r\n") + 15 + len("-\r\n")
+	// informationWidth and informationLines are the format of field 86
+	informationWidth = 65
+	informationLines = 6
+	// noReference is the customer reference of the lines that have none
+	noReference = "NONREF"
+)
+
+// currencyPattern matches an ISO 4217 currency code
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// MT940Statement is the account and the opening balance of an MT940 customer statement
+type MT940Statement struct {
+	// Reference is field 20, the reference of the statement of format 16x
+	Reference string
+	// Account is field 25, the account of the statement
+	Account string
+	// Number is the statement number of field 28C
+	Number int
+	// Currency is the currency of the balances and of the lines
+	Currency string
+	// OpeningBalanceCents and OpeningDate are field 60F, the balance is in cents and negative for a debit
+	OpeningBalanceCents int64
+	OpeningDate         time.Time
+}
+
+// MT940Line is a statement line, field 61, with its information to the account owner, field 86
+type MT940Line struct {
+	ValueDate time.Time
+	EntryDate time.Time
+	// Credit tells whether the line credits the account, otherwise it debits it
+	Credit      bool
+	AmountCents int64
+	// TransactionType is the identification code of the transaction, such as NTRF
+	TransactionType string
+	// CustomerReference is the reference given by the account owner, lines without a valid
+	// reference of format 16x are written with NONREF
+	CustomerReference string
+	// BankReference is the reference given by the account servicing institution, of format 16x
+	BankReference string
+	// Information is written in field 86, transliterated to the SWIFT X character set and
+	// wrapped into 6 lines of 65 characters
+	Information string
+}
+
+// MT940Writer streams an MT940 customer statement. A statement longer than a FIN message
+// is split into pages: every page but the last ends with an intermediate closing balance
+// :62M:, which the next page opens with as :60M:, and the last one ends with the final
+// closing balance :62F:. Pages are text blocks ended by a line holding a single hyphen,
+// with CRLF line endings.
+type MT940Writer struct {
+	w         io.Writer
+	statement MT940Statement
+	page      int
+	length    int
+	balance   int64
+	date      time.Time
+	err       error
+}
+
+// NewMT940Writer validates the statement and writes the header of its first page to w
+func NewMT940Writer(w io.Writer, statement MT940Statement) (*MT940Writer, error) {
+	if !isReference(statement.Reference) {
+		return nil, fmt.Errorf("invalid statement reference %q: expected at most 16 characters of the SWIFT X character set that %v", statement.Reference, errInvalidReference)
+	}
+	if statement.Account == "" || len(statement.Account) > 35 || checkX(statement.Account) != nil {
+		return nil, fmt.Errorf("invalid statement account %q: expected 35 characters of the SWIFT X character set", statement.Account)
+	}
+	if statement.Number < 0 || statement.Number > 99999 {
+		return nil, fmt.Errorf("invalid statement number %d: expected at most 5 digits", statement.Number)
+	}
+	if !currencyPattern.MatchString(statement.Currency) {
+		return nil, fmt.Errorf("invalid statement currency %q", statement.Currency)
+	}
+	mw := &MT940Writer{
+		w:         w,
+		statement: statement,
+		balance:   statement.OpeningBalanceCents,
+		date:      statement.OpeningDate,
+	}
+	mw.startPage("60F")
+	return mw, mw.err
+}
+
+// WriteLine writes a statement line and its information, starting a new page when the
+// current one would exceed the length of a FIN message
+func (mw *MT940Writer) WriteLine(line MT940Line) error {
+	if mw.err != nil {
+		return mw.err
+	}
+	if line.AmountCents < 0 {
+		return fmt.Errorf("invalid statement line amount %d: amounts are positive, debits are told by the mark", line.AmountCents)
+	}
+	if len(line.TransactionType) != 4 || checkX(line.TransactionType) != nil {
+		return fmt.Errorf("invalid transaction type %q: expected 4 characters", line.TransactionType)
+	}
+	if line.BankReference != "" && !isReference(line.BankReference) {
+		return fmt.Errorf("invalid bank reference %q: %v", line.BankReference, errInvalidReference)
+	}
+
+	mark := "D"
+	if line.Credit {
+		mark = "C"
+	}
+	customerRef := line.CustomerReference
+	if !isReference(customerRef) {
+		customerRef = noReference
+	}
+	statementLine := line.ValueDate.Format("060102") + line.EntryDate.Format("0102") + mark +
+		formatAmount(line.AmountCents) + line.TransactionType + customerRef
+	if line.BankReference != "" {
+		statementLine += "//" + line.BankReference
+	}
+	information := wrap(ToX(line.Information), informationWidth, informationLines)
+
+	length := fieldLength("61", statementLine)
+	if len(information) > 0 {
+		length += fieldLength("86", information...)
+	}
+	if mw.length+length+balanceReserve > maxMessageLength {
+		mw.writeBalance("62M", mw.balance, mw.date)
+		mw.write("-")
+		mw.startPage("60M")
+	}
+
+	mw.writeField("61", statementLine)
+	if len(information) > 0 {
+		mw.writeField("86", information...)
+	}
+	if line.Credit {
+		mw.balance += line.AmountCents
+	} else {
+		mw.balance -= line.AmountCents
+	}
+	if line.EntryDate.After(mw.date) {
+		mw.date = line.EntryDate
+	}
+	return mw.err
+}
+
+// Close ends the last page with the final closing balance at closingDate, which is the
+// opening balance plus the credits and minus the debits of the lines written
+func (mw *MT940Writer) Close(closingDate time.Time) error {
+	if mw.err != nil {
+		return mw.err
+	}
+	mw.writeBalance("62F", mw.balance, closingDate)
+	mw.write("-")
+	return mw.err
+}
+
+// startPage writes the header of the next page, which opens with the balance carried
+// over from the previous page, as field 60F on the first page and 60M on the others
+func (mw *MT940Writer) startPage(openingTag string) {
+	mw.page++
+	mw.length = 0
+	mw.writeField("20", mw.statement.Reference)
+	mw.writeField("25", mw.statement.Account)
+	mw.writeField("28C", fmt.Sprintf("%d/%d", mw.statement.Number, mw.page))
+	mw.writeBalance(openingTag, mw.balance, mw.date)
+}
+
+// writeBalance writes a balance field of format 1!a6!n3!a15d
+func (mw *MT940Writer) writeBalance(tag string, cents int64, date time.Time) {
+	mark := "C"
+	if cents < 0 {
+		mark, cents = "D", -cents
+	}
+	mw.writeField(tag, mark+date.Format("060102")+mw.statement.Currency+formatAmount(cents))
+}
+
+// writeField writes a field made of its tag and of its lines of content
+func (mw *MT940Writer) writeField(tag string, lines ...string) {
+	mw.length += fieldLength(tag, lines...)
+	mw.write(":" + tag + ":" + strings.Join(lines, "\r\n"))
+}
+
+// write writes a line of the text block, remembering the first error
+func (mw *MT940Writer) write(line string) {
+	if mw.err != nil {
+		return
+	}
+	_, mw.err = io.WriteString(mw.w, line+"\r\n")
+}
+
+// fieldLength returns the number of characters of a field, line endings included
+func fieldLength(tag string, lines ...string) int {
+	n := len(tag) + 2
+	for _, line := range lines {
+		n += len(line) + 2
+	}
+	return n
+}
+
+// formatAmount formats cents as an amount of format 15d with a decimal comma
+func formatAmount(cents int64) string {
+	return strconv.FormatInt(cents/100, 10) + "," + fmt.Sprintf("%02d", cents%100)
+}
+
+// isReference reports whether s is a reference of format 16x
+func isReference(s string) bool {
+	return s != "" && len(s) <= 16 && checkX(s) == nil &&
+		!strings.HasPrefix(s, "/") && !strings.HasSuffix(s, "/") && !strings.Contains(s, "//")
+}
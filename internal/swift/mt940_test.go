@@ -0,0 +1,161 @@
+package swift
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	march4 = time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	march5 = time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+)
+
+func testStatement() MT940Statement {
+	return MT940Statement{
+		Reference:           "STMT-7-240304",
+		Account:             "FR1420041010050500013M02606",
+		Number:              64,
+		Currency:            "EUR",
+		OpeningBalanceCents: 113000,
+		OpeningDate:         march4,
+	}
+}
+
+func TestMT940Writer(t *testing.T) {
+	var out strings.Builder
+	w, err := NewMT940Writer(&out, testStatement())
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteLine(MT940Line{
+		ValueDate:         march4,
+		EntryDate:         march4,
+		AmountCents:       10000,
+		TransactionType:   "NTRF",
+		CustomerReference: "E2E-1",
+		BankReference:     "T1",
+		Information:       "John Doe – Facture n° 1",
+	}))
+	require.NoError(t, w.WriteLine(MT940Line{
+		ValueDate:         march5,
+		EntryDate:         march5,
+		Credit:            true,
+		AmountCents:       2005,
+		TransactionType:   "NRTI",
+		CustomerReference: "not a 16x reference",
+		BankReference:     "R2",
+	}))
+	require.NoError(t, w.Close(march5))
+
+	assert.Equal(t, ":20:STMT-7-240304\r\n"+
+		":25:FR1420041010050500013M02606\r\n"+
+		":28C:64/1\r\n"+
+		":60F:C240304EUR1130,00\r\n"+
+		":61:2403040304D100,00NTRFE2E-1//T1\r\n"+
+		":86:John Doe - Facture n. 1\r\n"+
+		":61:2403050305C20,05NRTINONREF//R2\r\n"+
+		":62F:C240305EUR1050,05\r\n"+
+		"-\r\n", out.String())
+}
+
+func TestMT940Writer_Pages(t *testing.T) {
+	statement := testStatement()
+	statement.OpeningBalanceCents = -150
+
+	var out strings.Builder
+	w, err := NewMT940Writer(&out, statement)
+	require.NoError(t, err)
+	for i := 0; i < 40; i++ {
+		require.NoError(t, w.WriteLine(MT940Line{
+			ValueDate:         march5,
+			EntryDate:         march5,
+			AmountCents:       100,
+			TransactionType:   "NTRF",
+			CustomerReference: "E2E",
+			Information:       strings.Repeat("Remittance information ", 6),
+		}))
+	}
+	require.NoError(t, w.Close(march5))
+
+	pages := strings.SplitAfter(out.String(), "-\r\n")
+	require.Empty(t, pages[len(pages)-1])
+	pages = pages[:len(pages)-1]
+	require.Greater(t, len(pages), 1)
+
+	lines := 0
+	balance := ":60F:D240304EUR1,50\r\n"
+	for i, page := range pages {
+		assert.LessOrEqual(t, len(page), maxMessageLength, "page %d", i+1)
+		assert.True(t, strings.HasPrefix(page, fmt.Sprintf(":20:STMT-7-240304\r\n:25:FR1420041010050500013M02606\r\n:28C:64/%d\r\n%s", i+1, balance)), page)
+		lines += strings.Count(page, ":61:")
+		if i < len(pages)-1 {
+			closing := regexp.MustCompile(`:62M:(D240305EUR[0-9]+,[0-9]{2}\r\n)-\r\n$`).FindStringSubmatch(page)
+			require.NotNil(t, closing, page)
+			balance = ":60M:" + closing[1]
+		}
+	}
+	assert.Equal(t, 40, lines)
+	assert.True(t, strings.HasSuffix(pages[len(pages)-1], ":62F:D240305EUR41,50\r\n-\r\n"))
+}
+
+func TestNewMT940Writer_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*MT940Statement)
+		wantErr string
+	}{
+		{
+			name:    "Reference too long",
+			modify:  func(s *MT940Statement) { s.Reference = strings.Repeat("R", 17) },
+			wantErr: "invalid statement reference",
+		},
+		{
+			name:    "Reference ending with a slash",
+			modify:  func(s *MT940Statement) { s.Reference = "STMT/" },
+			wantErr: "must not start or end with a slash",
+		},
+		{
+			name:    "Account outside of the X character set",
+			modify:  func(s *MT940Statement) { s.Account = "FR14_2004" },
+			wantErr: `invalid statement account "FR14_2004"`,
+		},
+		{
+			name:    "Statement number too large",
+			modify:  func(s *MT940Statement) { s.Number = 100000 },
+			wantErr: "invalid statement number 100000",
+		},
+		{
+			name:    "Invalid currency",
+			modify:  func(s *MT940Statement) { s.Currency = "eur" },
+			wantErr: `invalid statement currency "eur"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := testStatement()
+			tt.modify(&statement)
+			var out strings.Builder
+			_, err := NewMT940Writer(&out, statement)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+			assert.Empty(t, out.String())
+		})
+	}
+}
+
+func TestMT940Writer_InvalidLine(t *testing.T) {
+	var out strings.Builder
+	w, err := NewMT940Writer(&out, testStatement())
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, w.WriteLine(MT940Line{AmountCents: -1, TransactionType: "NTRF"}), "invalid statement line amount -1")
+	assert.ErrorContains(t, w.WriteLine(MT940Line{TransactionType: "TRF"}), `invalid transaction type "TRF"`)
+	assert.ErrorContains(t, w.WriteLine(MT940Line{TransactionType: "NTRF", BankReference: "T//1"}), `invalid bank reference "T//1"`)
+}
@@ -0,0 +1,106 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertificateReloader serves a certificate read from a certificate and a key file.
+// The files are checked for changes at most every check interval, during handshakes,
+// and the certificate is reloaded when either of them changed. The current certificate
+// keeps being served when a reload fails, for instance while the files are being
+// replaced one after the other. It is safe for concurrent use.
+type CertificateReloader struct {
+	certFile      string
+	keyFile       string
+	checkInterval time.Duration
+	logger        *slog.Logger
+	now           func() time.Time
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	certMod   time.Time
+	keyMod    time.Time
+	checkedAt time.Time
+}
+
+// NewCertificateReloader loads the certificate of the files, an error is returned if it cannot be loaded
+func NewCertificateReloader(certFile, keyFile string, checkInterval time.Duration, logger *slog.Logger) (*CertificateReloader, error) {
+	r := &CertificateReloader{
+		certFile:      certFile,
+		keyFile:       keyFile,
+		checkInterval: checkInterval,
+		logger:        logger,
+		now:           time.Now,
+	}
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(certMod, keyMod); err != nil {
+		return nil, err
+	}
+	r.checkedAt = r.now()
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, it is used as the GetCertificate of a tls.Config
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.checkedAt) < r.checkInterval {
+		return r.cert, nil
+	}
+	r.checkedAt = now
+
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		r.logger.Error("Failed to check TLS certificate files", "error", err)
+		return r.cert, nil
+	}
+	if certMod.Equal(r.certMod) && keyMod.Equal(r.keyMod) {
+		return r.cert, nil
+	}
+	if err := r.load(certMod, keyMod); err != nil {
+		r.logger.Error("Failed to reload TLS certificate, the previous one is still served", "error", err)
+		return r.cert, nil
+	}
+	r.logger.Info("TLS certificate reloaded", "cert_file", r.certFile, "not_after", r.cert.Leaf.NotAfter)
+	return r.cert, nil
+}
+
+// modTimes returns the modification times of the certificate and key files
+func (r *CertificateReloader) modTimes() (certMod, keyMod time.Time, err error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+// load replaces the certificate with the one of the files, modified at the given times
+func (r *CertificateReloader) load(certMod, keyMod time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	// The leaf is parsed once here rather than on every handshake
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return fmt.Errorf("failed to parse TLS certificate: %w", err)
+	}
+	r.cert = &cert
+	r.certMod = certMod
+	r.keyMod = keyMod
+	return nil
+}
@@ -0,0 +1,127 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config holds the TLS settings of the server
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// MinVersion is the minimum TLS version accepted, 1.2 or 1.3
+	MinVersion string
+	// CipherSuites are the names of the cipher suites accepted with TLS 1.2, the secure
+	// suites of the Go standard library if empty. The suites of TLS 1.3 are not configurable.
+	CipherSuites []string
+	// ClientCAFile is the PEM bundle of the CAs client certificates are verified with,
+	// client certificates are not requested when it is empty
+	ClientCAFile string
+	// ClientCertRequired rejects the clients without a certificate during the handshake,
+	// otherwise they can still authenticate with an API key or a bearer token
+	ClientCertRequired bool
+	// ReloadInterval is the interval the certificate files are checked for changes at
+	ReloadInterval time.Duration
+}
+
+// NewServerConfig returns the tls.Config of the server
+func NewServerConfig(c Config, logger *slog.Logger) (*tls.Config, error) {
+	minVersion, err := ParseVersion(c.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := ParseCipherSuites(c.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	certificates, err := NewCertificateReloader(c.CertFile, c.KeyFile, c.ReloadInterval, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+		GetCertificate: certificates.GetCertificate,
+	}
+	if c.ClientCAFile != "" {
+		config.ClientCAs, err = loadCertPool(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+		if c.ClientCertRequired {
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return config, nil
+}
+
+// ParseVersion returns the TLS version of its name, 1.2 or 1.3
+func ParseVersion(name string) (uint16, error) {
+	switch name {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q, expected 1.2 or 1.3", name)
+	}
+}
+
+// ParseCipherSuites returns the IDs of the named TLS 1.2 cipher suites, nil if names is empty.
+// Insecure suites, and the suites of TLS 1.3 which cannot be configured, are rejected.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	suites := make(map[string]*tls.CipherSuite)
+	for _, s := range tls.CipherSuites() {
+		suites[s.Name] = s
+	}
+	for _, s := range tls.InsecureCipherSuites() {
+		suites[s.Name] = s
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		s, ok := suites[strings.TrimSpace(name)]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("unknown cipher suite %q", name)
+		case s.Insecure:
+			return nil, fmt.Errorf("insecure cipher suite %q", name)
+		case !supportsTLS12(s):
+			return nil, fmt.Errorf("cipher suite %q is a TLS 1.3 suite, which cannot be configured", name)
+		}
+		ids = append(ids, s.ID)
+	}
+	return ids, nil
+}
+
+func supportsTLS12(s *tls.CipherSuite) bool {
+	for _, v := range s.SupportedVersions {
+		if v == tls.VersionTLS12 {
+			return true
+		}
+	}
+	return false
+}
+
+// loadCertPool returns the pool of the certificates of a PEM bundle
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificate found in client CA bundle %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,14 @@
+// Package tlsconfig builds the TLS configuration of the REST server.
+//
+// The certificate and key of the server are read from files, which are checked
+// for changes and reloaded without restarting the server, so that certificates
+// can be renewed in place. The minimum TLS version and the cipher suites of TLS 1.2
+// are configurable. With a client CA bundle, clients can authenticate with
+// certificates issued by that CA (mutual TLS), the subject of their certificate
+// being mapped onto the organization they act for.
+//
+// Key components:
+//   - Config: Struct representing the TLS settings of the server
+//   - CertificateReloader: Serves the certificate of the server, reloaded when its files change
+//   - SubjectMapper: Maps the subject of client certificates onto an auth.Principal
+package tlsconfig
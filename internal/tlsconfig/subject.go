@@ -0,0 +1,58 @@
+package tlsconfig
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"moneytransfer/internal/auth"
+)
+
+// Subject fields a client certificate can name its organization with
+const (
+	FieldOrganization       = "O"
+	FieldOrganizationalUnit = "OU"
+	FieldCommonName         = "CN"
+)
+
+// SubjectMapper maps the client certificates verified by the TLS handshake onto the
+// organization named by a field of their subject. The CAs of the client CA bundle
+// are trusted to only issue certificates naming the organization of their holder.
+type SubjectMapper struct {
+	// Field is the subject field naming the organization: O, OU or CN
+	Field string
+}
+
+// NewSubjectMapper returns the mapper of the organizations named by field
+func NewSubjectMapper(field string) (*SubjectMapper, error) {
+	switch field {
+	case FieldOrganization, FieldOrganizationalUnit, FieldCommonName:
+		return &SubjectMapper{Field: field}, nil
+	default:
+		return nil, fmt.Errorf("unsupported subject field %q, expected O, OU or CN", field)
+	}
+}
+
+// MapCertificate returns the principal of the organization named by the certificate.
+// The subject of the principal is the distinguished name of the certificate.
+func (m *SubjectMapper) MapCertificate(cert *x509.Certificate) (auth.Principal, error) {
+	var values []string
+	switch m.Field {
+	case FieldOrganization:
+		values = cert.Subject.Organization
+	case FieldOrganizationalUnit:
+		values = cert.Subject.OrganizationalUnit
+	case FieldCommonName:
+		if cert.Subject.CommonName != "" {
+			values = []string{cert.Subject.CommonName}
+		}
+	}
+	// A certificate naming several organizations is ambiguous
+	if len(values) != 1 || values[0] == "" {
+		return auth.Principal{}, fmt.Errorf("%w: the subject %q must name exactly one organization in its %s field",
+			auth.ErrInvalidCertificate, cert.Subject.String(), m.Field)
+	}
+	return auth.Principal{
+		OrganizationName: values[0],
+		Subject:          "mtls:" + cert.Subject.String(),
+	}, nil
+}
@@ -0,0 +1,277 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issuer is a certificate and its key, used to sign other certificates
+type issuer struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+var serial int64
+
+// newCertificate returns a certificate of subject signed by parent, self-signed if parent is nil
+func newCertificate(t *testing.T, subject pkix.Name, parent *issuer, isCA bool) (*issuer, []byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial++
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               subject,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &issuer{cert: cert, key: key},
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte, mod time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	require.NoError(t, os.Chtimes(path, mod, mod))
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	_, firstCert, firstKey := newCertificate(t, pkix.Name{CommonName: "first"}, nil, false)
+	_, secondCert, secondKey := newCertificate(t, pkix.Name{CommonName: "second"}, nil, false)
+
+	start := time.Now().Add(-time.Hour)
+	writeFile(t, certFile, firstCert, start)
+	writeFile(t, keyFile, firstKey, start)
+
+	r, err := NewCertificateReloader(certFile, keyFile, 10*time.Second, slog.Default())
+	require.NoError(t, err)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	commonName := func() string {
+		cert, err := r.GetCertificate(nil)
+		require.NoError(t, err)
+		return cert.Leaf.Subject.CommonName
+	}
+	assert.Equal(t, "first", commonName())
+
+	// The files are replaced, the change is only seen after the check interval
+	writeFile(t, certFile, secondCert, start.Add(time.Minute))
+	writeFile(t, keyFile, secondKey, start.Add(time.Minute))
+	assert.Equal(t, "first", commonName())
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, "second", commonName())
+
+	// A broken certificate is not served, the previous one is kept
+	writeFile(t, certFile, firstCert, start.Add(2*time.Minute))
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, "second", commonName())
+
+	// Once the key matches again, the new certificate is served
+	writeFile(t, keyFile, firstKey, start.Add(3*time.Minute))
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, "first", commonName())
+}
+
+func TestNewCertificateReloader_MissingFiles(t *testing.T) {
+	_, err := NewCertificateReloader("missing.crt", "missing.key", time.Second, slog.Default())
+	assert.Error(t, err)
+}
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion("1.2")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), v)
+
+	v, err = ParseVersion("1.3")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), v)
+
+	_, err = ParseVersion("1.1")
+	assert.Error(t, err)
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		want    []uint16
+		wantErr string
+	}{
+		{"Default suites", nil, nil, ""},
+		{
+			"TLS 1.2 suites",
+			[]string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", " TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"},
+			[]uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256},
+			"",
+		},
+		{"Unknown suite", []string{"TLS_NULL"}, nil, "unknown"},
+		{"Insecure suite", []string{"TLS_RSA_WITH_RC4_128_SHA"}, nil, "insecure"},
+		{"TLS 1.3 suite", []string{"TLS_AES_128_GCM_SHA256"}, nil, "TLS 1.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCipherSuites(tt.names)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewServerConfig_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caPEM, _ := newCertificate(t, pkix.Name{CommonName: "ERP CA"}, nil, true)
+	_, serverCert, serverKey := newCertificate(t, pkix.Name{CommonName: "server"}, ca, false)
+	_, clientCert, clientKey := newCertificate(t, pkix.Name{CommonName: "erp", Organization: []string{"Test Org"}}, ca, false)
+	_, strangerCert, strangerKey := newCertificate(t, pkix.Name{CommonName: "stranger", Organization: []string{"Test Org"}}, nil, false)
+
+	now := time.Now()
+	writeFile(t, filepath.Join(dir, "server.crt"), serverCert, now)
+	writeFile(t, filepath.Join(dir, "server.key"), serverKey, now)
+	writeFile(t, filepath.Join(dir, "ca.pem"), caPEM, now)
+
+	newServer := func(t *testing.T, required bool) *httptest.Server {
+		config, err := NewServerConfig(Config{
+			CertFile:           filepath.Join(dir, "server.crt"),
+			KeyFile:            filepath.Join(dir, "server.key"),
+			MinVersion:         "1.2",
+			ClientCAFile:       filepath.Join(dir, "ca.pem"),
+			ClientCertRequired: required,
+			ReloadInterval:     time.Second,
+		}, slog.Default())
+		require.NoError(t, err)
+
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(r.TLS.VerifiedChains) == 0 {
+				io.WriteString(w, "anonymous")
+				return
+			}
+			principal, err := (&SubjectMapper{Field: FieldOrganization}).MapCertificate(r.TLS.VerifiedChains[0][0])
+			require.NoError(t, err)
+			io.WriteString(w, principal.OrganizationName)
+		}))
+		// StartTLS would serve a certificate of its own, the listener serves the configured one
+		server.Listener = tls.NewListener(server.Listener, config)
+		server.Start()
+		server.URL = "https://" + server.Listener.Addr().String()
+		t.Cleanup(server.Close)
+		return server
+	}
+	newClient := func(t *testing.T, certPEM, keyPEM []byte) *http.Client {
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+		config := &tls.Config{RootCAs: roots}
+		if certPEM != nil {
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			require.NoError(t, err)
+			config.Certificates = []tls.Certificate{cert}
+		}
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	}
+	get := func(t *testing.T, server *httptest.Server, client *http.Client) (string, error) {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	t.Run("Client certificate is mapped to its organization", func(t *testing.T) {
+		body, err := get(t, newServer(t, false), newClient(t, clientCert, clientKey))
+		assert.NoError(t, err)
+		assert.Equal(t, "Test Org", body)
+	})
+
+	t.Run("Client without certificate when optional", func(t *testing.T) {
+		body, err := get(t, newServer(t, false), newClient(t, nil, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, "anonymous", body)
+	})
+
+	t.Run("Client without certificate when required", func(t *testing.T) {
+		_, err := get(t, newServer(t, true), newClient(t, nil, nil))
+		assert.Error(t, err)
+	})
+
+	t.Run("Certificate of another CA", func(t *testing.T) {
+		_, err := get(t, newServer(t, true), newClient(t, strangerCert, strangerKey))
+		assert.Error(t, err)
+	})
+}
+
+func TestSubjectMapper(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		subject pkix.Name
+		want    string
+		wantErr bool
+	}{
+		{"Organization", FieldOrganization, pkix.Name{CommonName: "erp", Organization: []string{"Test Org"}}, "Test Org", false},
+		{"Organizational unit", FieldOrganizationalUnit, pkix.Name{OrganizationalUnit: []string{"Test Org"}}, "Test Org", false},
+		{"Common name", FieldCommonName, pkix.Name{CommonName: "Test Org"}, "Test Org", false},
+		{"Missing field", FieldOrganization, pkix.Name{CommonName: "Test Org"}, "", true},
+		{"Several organizations", FieldOrganization, pkix.Name{Organization: []string{"Test Org", "Other Org"}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewSubjectMapper(tt.field)
+			require.NoError(t, err)
+
+			p, err := m.MapCertificate(&x509.Certificate{Subject: tt.subject})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, p.OrganizationName)
+			assert.Equal(t, "mtls:"+tt.subject.String(), p.Subject)
+		})
+	}
+
+	_, err := NewSubjectMapper("C")
+	assert.Error(t, err)
+}
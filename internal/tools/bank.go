@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var (
+	// ibanPattern matches an IBAN in electronic format: country code, check digits and account number
+	ibanPattern = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	// bicPattern matches a BIC: institution, country and location codes, and an optional branch code
+	bicPattern = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+)
+
+// ibanLengths is the length of the IBANs of every country of the IBAN registry
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BI": 27,
+	"BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DJ": 27, "DK": 18, "DO": 28,
+	"EE": 20, "EG": 29, "ES": 24, "FI": 18, "FK": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23,
+	"GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27,
+	"JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "LY": 25,
+	"MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20, "MR": 27, "MT": 31, "MU": 30, "NI": 28, "NL": 18,
+	"NO": 15, "OM": 23, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "RU": 33,
+	"SA": 24, "SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "SO": 23, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20, "YE": 30,
+}
+
+// ValidateIBAN checks an IBAN in electronic format, without spaces, against ISO 13616:
+// its format, the length of the IBANs of its country and its check digits
+func ValidateIBAN(iban string) error {
+	if !ibanPattern.MatchString(iban) {
+		return fmt.Errorf("invalid IBAN %q: expected a country code, two check digits and up to 30 letters or digits", iban)
+	}
+	length, ok := ibanLengths[iban[:2]]
+	if !ok {
+		return fmt.Errorf("invalid IBAN %q: unknown country code %s", iban, iban[:2])
+	}
+	if len(iban) != length {
+		return fmt.Errorf("invalid IBAN %q: IBANs of country %s have %d characters, got %d", iban, iban[:2], length, len(iban))
+	}
+
+	// The IBAN with its first four characters moved to the end, and its letters replaced by
+	// numbers from 10 for A to 35 for Z, is a number whose remainder modulo 97 is 1
+	remainder := 0
+	for _, c := range iban[4:] + iban[:4] {
+		if c >= 'A' {
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		} else {
+			remainder = (remainder*10 + int(c-'0')) % 97
+		}
+	}
+	if remainder != 1 {
+		return fmt.Errorf("invalid IBAN %q: wrong check digits", iban)
+	}
+	return nil
+}
+
+// ValidateBIC checks the format of a BIC of 8 or 11 characters against ISO 9362
+func ValidateBIC(bic string) error {
+	if !bicPattern.MatchString(bic) {
+		return fmt.Errorf("invalid BIC %q: expected 8 or 11 letters or digits, the first 6 being letters", bic)
+	}
+	return nil
+}
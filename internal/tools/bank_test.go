@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateIBAN(t *testing.T) {
+	tests := []struct {
+		name    string
+		iban    string
+		wantErr string
+	}{
+		{"German IBAN", "DE89370400440532013000", ""},
+		{"French IBAN with letters", "FR1420041010050500013M02606", ""},
+		{"Shortest IBAN", "NO9386011117947", ""},
+		{"Spaces", "DE89 3704 0044 0532 0130 00", `invalid IBAN "DE89 3704 0044 0532 0130 00": expected a country code, two check digits and up to 30 letters or digits`},
+		{"Lower case", "de89370400440532013000", `invalid IBAN "de89370400440532013000": expected a country code, two check digits and up to 30 letters or digits`},
+		{"Unknown country", "ZZ89370400440532013000", `invalid IBAN "ZZ89370400440532013000": unknown country code ZZ`},
+		{"Wrong length", "DE8937040044053201300", `invalid IBAN "DE8937040044053201300": IBANs of country DE have 22 characters, got 21`},
+		{"Wrong check digits", "DE88370400440532013000", `invalid IBAN "DE88370400440532013000": wrong check digits`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIBAN(tt.iban)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateBIC(t *testing.T) {
+	assert.NoError(t, ValidateBIC("DEUTDEFF"))
+	assert.NoError(t, ValidateBIC("DEUTDEFF500"))
+	assert.Error(t, ValidateBIC("DEUTDEF"))
+	assert.Error(t, ValidateBIC("DEUTDEFF50"))
+	assert.Error(t, ValidateBIC("1EUTDEFF"))
+	assert.Error(t, ValidateBIC("deutdeff"))
+}
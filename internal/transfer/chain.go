@@ -0,0 +1,107 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// LinkKind is the change of the balance of a bank account recorded by a link of its chain
+type LinkKind string
+
+const (
+	// LinkDebit records the debit of a transfer when it was executed
+	LinkDebit LinkKind = "debit"
+	// LinkCredit records the credit back of a returned transfer
+	LinkCredit LinkKind = "credit"
+)
+
+// GenesisHash is the previous hash of the first link of the chain of a bank account
+var GenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// Link is a record of the hash chain of a bank account. Each link hashes the transfer it
+// records and the balance it left the account with, chained to the hash of the previous
+// link of the account, so that a transfer or a link edited in the database breaks the chain.
+type Link struct {
+	BankAccountID int64
+	// Seq is the position of the link in the chain of the account, starting at 1
+	Seq         int64
+	Kind        LinkKind
+	TransferID  int64
+	AmountCents int64
+	// BalanceCents is the balance of the account after the change
+	BalanceCents int64
+	PrevHash     string
+	Hash         string
+	CreatedAt    time.Time
+}
+
+// linkRecord is the canonical record of a link hashed by ComputeHash. A debit records the
+// transfer as it was created, a credit the return of the transfer.
+type linkRecord struct {
+	BankAccountID        int64    `json:"bank_account_id"`
+	Seq                  int64    `json:"seq"`
+	Kind                 LinkKind `json:"kind"`
+	TransferID           int64    `json:"transfer_id"`
+	AmountCents          int64    `json:"amount_cents"`
+	BalanceCents         int64    `json:"balance_cents"`
+	TransferAmountCents  int64    `json:"transfer_amount_cents"`
+	CounterpartyName     string   `json:"counterparty_name,omitempty"`
+	CounterpartyIBAN     string   `json:"counterparty_iban,omitempty"`
+	CounterpartyBIC      string   `json:"counterparty_bic,omitempty"`
+	Description          string   `json:"description,omitempty"`
+	EndToEndID           string   `json:"end_to_end_id,omitempty"`
+	PaymentInformationID string   `json:"payment_information_id,omitempty"`
+	BatchID              int64    `json:"batch_id,omitempty"`
+	CreatedAt            string   `json:"created_at,omitempty"`
+	Status               Status   `json:"status,omitempty"`
+	ReturnReason         string   `json:"return_reason,omitempty"`
+	ReturnedAt           string   `json:"returned_at,omitempty"`
+}
+
+// ComputeHash returns the hex encoded SHA-256 hash of the previous hash of the link followed by
+// its record, with the details of the transfer t it records. The counterparty details are hashed
+// in clear, so that the hash does not change when they are encrypted again with another key.
+func (l *Link) ComputeHash(t *Transfer) string {
+	record := linkRecord{
+		BankAccountID:       l.BankAccountID,
+		Seq:                 l.Seq,
+		Kind:                l.Kind,
+		TransferID:          l.TransferID,
+		AmountCents:         l.AmountCents,
+		BalanceCents:        l.BalanceCents,
+		TransferAmountCents: t.AmountCents,
+	}
+	if l.Kind == LinkCredit {
+		record.Status = t.Status
+		record.ReturnReason = t.ReturnReason
+		record.ReturnedAt = chainTime(t.ReturnedAt)
+	} else {
+		record.CounterpartyName = t.CounterpartyName
+		record.CounterpartyIBAN = t.CounterpartyIBAN
+		record.CounterpartyBIC = t.CounterpartyBIC
+		record.Description = t.Description
+		record.EndToEndID = t.EndToEndID
+		record.PaymentInformationID = t.PaymentInformationID
+		record.BatchID = t.BatchID
+		record.CreatedAt = chainTime(t.CreatedAt)
+	}
+	// Marshaling a struct of strings and numbers cannot fail, and its fields keep their order
+	data, _ := json.Marshal(record)
+
+	h := sha256.New()
+	h.Write([]byte(l.PrevHash))
+	h.Write([]byte{'\n'})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// chainTime formats the times hashed by the chain to the microsecond stored by the database
+func chainTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
@@ -0,0 +1,45 @@
+package transfer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLink_ComputeHash(t *testing.T) {
+	created := time.Date(2024, 3, 4, 10, 0, 0, 123456789, time.FixedZone("CET", 3600))
+	tr := Transfer{ID: 7, CounterpartyName: "John Doe", CounterpartyIBAN: "DE89370400440532013000", CounterpartyBIC: "DEUTDEFF", AmountCents: 1000, BankAccountID: 1, Description: "Invoice", CreatedAt: created}
+	debit := Link{BankAccountID: 1, Seq: 1, Kind: LinkDebit, TransferID: 7, AmountCents: 1000, BalanceCents: 4000, PrevHash: GenesisHash}
+	hash := debit.ComputeHash(&tr)
+	assert.Len(t, hash, 64)
+
+	t.Run("Hash is stable across time zones and precisions", func(t *testing.T) {
+		stored := tr
+		stored.CreatedAt = created.UTC().Truncate(time.Microsecond)
+		assert.Equal(t, hash, debit.ComputeHash(&stored))
+	})
+
+	t.Run("Edited transfer changes the hash", func(t *testing.T) {
+		edited := tr
+		edited.CounterpartyIBAN = "GB29NWBK60161331926819"
+		assert.NotEqual(t, hash, debit.ComputeHash(&edited))
+	})
+
+	t.Run("Previous hash is chained", func(t *testing.T) {
+		next := debit
+		next.PrevHash = hash
+		assert.NotEqual(t, hash, next.ComputeHash(&tr))
+	})
+
+	t.Run("Debit does not change with the return", func(t *testing.T) {
+		returned := tr
+		assert.NoError(t, returned.Return("AC04", created.Add(time.Hour)))
+		assert.Equal(t, hash, debit.ComputeHash(&returned))
+
+		credit := Link{BankAccountID: 1, Seq: 2, Kind: LinkCredit, TransferID: 7, AmountCents: 1000, BalanceCents: 5000, PrevHash: hash}
+		creditHash := credit.ComputeHash(&returned)
+		returned.ReturnReason = "MS03"
+		assert.NotEqual(t, creditHash, credit.ComputeHash(&returned))
+	})
+}
@@ -3,9 +3,104 @@ package transfer
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the requested transfer does not exist
+	ErrNotFound = errors.New("transfer not found")
+	// ErrAlreadySent is returned when a transfer marked as sent was already sent in another message
+	ErrAlreadySent = errors.New("transfer already sent")
+	// ErrEncryptionDisabled is returned when re-encrypting without a field cipher
+	ErrEncryptionDisabled = errors.New("field encryption is disabled")
+	// ErrMixedAccounts is returned when the transfers created together are not of a single bank account
+	ErrMixedAccounts = errors.New("transfers of several bank accounts")
 )
 
 //go:generate go run go.uber.org/mock/mockgen -source=repository.go -destination=../../mock/transfer_repository_mock.go -package=mock -mock_names=Repository=TransferRepositoryMock
 type Repository interface {
-	CreateBulkTransfers(ctx context.Context, tx *sql.Tx, transfers []Transfer) error
+	// CreateBulkTransfers inserts the transfers of a bank account whose balance before them is
+	// balanceCents, and appends the debit of each of them to the hash chain of the account
+	CreateBulkTransfers(ctx context.Context, tx *sql.Tx, transfers []Transfer, balanceCents int64) error
+	// Get returns the transfer with the given id, locking it until tx ends when tx is set
+	Get(ctx context.Context, tx *sql.Tx, id int64) (*Transfer, error)
+	// UpdateStatus stores the status of the transfer and its return details
+	UpdateStatus(ctx context.Context, tx *sql.Tx, t *Transfer) error
+	// ChainReturn appends the credit of the returned transfer, stored by UpdateStatus, to the hash
+	// chain of its bank account, balanceCents being the balance of the account after the credit
+	ChainReturn(ctx context.Context, tx *sql.Tx, t *Transfer, balanceCents int64) error
+	// ListByBatch returns up to limit transfers of the batch with an id greater than afterID, by id
+	ListByBatch(ctx context.Context, batchID, afterID int64, limit int) ([]Transfer, error)
+	// CountByPaymentInformation counts the transfers of the batch by payment information block,
+	// in the order the blocks were created
+	CountByPaymentInformation(ctx context.Context, batchID int64) ([]PaymentInformationCount, error)
+	// SummarizeMovements counts and sums the movements of the bank account booked from from
+	// until to, excluded. A zero to summarizes all the movements booked since from.
+	SummarizeMovements(ctx context.Context, tx *sql.Tx, bankAccountID int64, from, to time.Time) (*MovementSummary, error)
+	// ForEachMovement calls fn for every movement of the bank account booked from from until to,
+	// excluded, by booking time. An error returned by fn stops the iteration and is returned as is.
+	ForEachMovement(ctx context.Context, tx *sql.Tx, bankAccountID int64, from, to time.Time, fn func(Movement) error) error
+	// LockSending takes the lock of the sending of transfers until tx ends, so that a single
+	// transaction sends transfers at a time. It returns false when another transaction holds it.
+	LockSending(ctx context.Context, tx *sql.Tx) (bool, error)
+	// ClaimUnsent returns up to limit executed transfers that were not sent yet, by bank account and
+	// creation time, locking them until tx ends. Transfers locked by another transaction are skipped.
+	ClaimUnsent(ctx context.Context, tx *sql.Tx, limit int) ([]Transfer, error)
+	// MarkSent records that the transfers were sent in the given message, it returns ErrAlreadySent
+	// when one of them was already sent
+	MarkSent(ctx context.Context, tx *sql.Tx, ids []int64, messageID string, sentAt time.Time) error
+	// CountSent counts the transfers sent in the given message
+	CountSent(ctx context.Context, messageID string) (int, error)
+	// ForEachTransfer calls fn for every transfer matching the filter, by creation time. The transfers
+	// are fetched in chunks through a server-side cursor declared in tx, which is required, so that
+	// they are read from the snapshot of tx without being held in memory. An error returned by fn
+	// stops the iteration and is returned as is.
+	ForEachTransfer(ctx context.Context, tx *sql.Tx, filter Filter, fn func(Transfer) error) error
+	// Reencrypt scans up to limit transfers with an id greater than afterID, by id, and encrypts
+	// with the primary key the counterparty details of those stored in clear, encrypted with
+	// a retired key or in the former format, indexing their IBAN. It fails when the encryption is disabled.
+	Reencrypt(ctx context.Context, afterID int64, limit int) (*ReencryptBatch, error)
+	// ChainAccounts returns the ids of the bank accounts with transfers or links, by id
+	ChainAccounts(ctx context.Context) ([]int64, error)
+	// ForEachLink calls fn for every link of the hash chain of the bank account, by sequence, with
+	// the transfer it records, nil when the transfer no longer exists. The links are read from the
+	// snapshot of tx. An error returned by fn stops the iteration and is returned as is.
+	ForEachLink(ctx context.Context, tx *sql.Tx, bankAccountID int64, fn func(Link, *Transfer) error) error
+	// FirstUnchained returns the id of the first transfer of the bank account created since the
+	// chain was introduced that is not chained, zero when there is none. It does not depend on the
+	// links of the account, which may all have been deleted.
+	FirstUnchained(ctx context.Context, tx *sql.Tx, bankAccountID int64) (int64, error)
+}
+
+// Filter selects the transfers of a bank account
+type Filter struct {
+	BankAccountID int64
+	// CreatedFrom and CreatedTo bound the creation time of the transfers, CreatedTo excluded.
+	// Zero times leave the creation time unbounded.
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Status selects the transfers in the status, all statuses when empty
+	Status Status
+	// BatchID selects the transfers created by the batch, all transfers when zero
+	BatchID int64
+	// CounterpartyIBAN selects the transfers to the IBAN, all transfers when empty
+	CounterpartyIBAN string
+}
+
+// ReencryptBatch reports a batch of the re-encryption of the counterparty details
+type ReencryptBatch struct {
+	// Scanned counts the transfers scanned, fewer than the limit when the last transfer was reached
+	Scanned int
+	// Reencrypted counts the transfers whose counterparty details were encrypted again
+	Reencrypted int
+	// LastID is the id of the last transfer scanned, from which the next batch starts
+	LastID int64
+}
+
+// PaymentInformationCount counts the transfers of a payment information block of a batch
+type PaymentInformationCount struct {
+	PaymentInformationID string
+	Transfers            int
+	Returned             int
 }
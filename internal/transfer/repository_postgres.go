@@ -4,20 +4,101 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Columns of the counterparty details encrypted by the field cipher, the ciphertexts are bound to them
+// and to the ID of their transfer
+const (
+	columnCounterpartyName = "transfers.counterparty_name"
+	columnCounterpartyIBAN = "transfers.counterparty_iban"
+	columnDescription      = "transfers.description"
 )
 
+// FieldCipher encrypts the counterparty details of the transfers at rest
+type FieldCipher interface {
+	// Encrypt encrypts the value stored in the column of the row, bound to both
+	Encrypt(plaintext, column, row string) (string, error)
+	// Decrypt decrypts the value stored in the column of the row, values stored in clear are returned as they are
+	Decrypt(stored, column, row string) (string, error)
+	// BlindIndex returns the searchable index of an IBAN
+	BlindIndex(value string) string
+	// Current reports whether the stored value is encrypted with the key encrypting new values
+	Current(stored string) bool
+}
+
 type postgresRepository struct {
 	db *sql.DB
+	// cipher encrypts the counterparty details, they are stored in clear when it is nil
+	cipher FieldCipher
+}
+
+// NewPostgresRepository returns the repository of the transfers. The counterparty names, IBANs and
+// the descriptions are encrypted with cipher, or stored in clear when cipher is nil.
+func NewPostgresRepository(db *sql.DB, cipher FieldCipher) Repository {
+	return &postgresRepository{db: db, cipher: cipher}
+}
+
+// encrypt encrypts the value of the column of the transfer id, empty values are stored as they are
+func (r *postgresRepository) encrypt(value, column string, id int64) (string, error) {
+	if r.cipher == nil || value == "" {
+		return value, nil
+	}
+	stored, err := r.cipher.Encrypt(value, column, strconv.FormatInt(id, 10))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", column, err)
+	}
+	return stored, nil
+}
+
+// decrypt decrypts the value of the column of the transfer id
+func (r *postgresRepository) decrypt(stored, column string, id int64) (string, error) {
+	if r.cipher == nil || stored == "" {
+		return stored, nil
+	}
+	value, err := r.cipher.Decrypt(stored, column, strconv.FormatInt(id, 10))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", column, err)
+	}
+	return value, nil
+}
+
+// ibanIndex returns the blind index of the counterparty IBAN, NULL when the encryption is disabled
+func (r *postgresRepository) ibanIndex(iban string) sql.NullString {
+	if r.cipher == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: r.cipher.BlindIndex(iban), Valid: true}
 }
 
-func NewPostgresRepository(db *sql.DB) Repository {
-	return &postgresRepository{db: db}
+// sealCounterparty returns the stored counterparty name, IBAN and description of the transfer,
+// bound to its ID
+func (r *postgresRepository) sealCounterparty(t *Transfer) (name, iban, description string, err error) {
+	if name, err = r.encrypt(t.CounterpartyName, columnCounterpartyName, t.ID); err != nil {
+		return "", "", "", err
+	}
+	if iban, err = r.encrypt(t.CounterpartyIBAN, columnCounterpartyIBAN, t.ID); err != nil {
+		return "", "", "", err
+	}
+	if description, err = r.encrypt(t.Description, columnDescription, t.ID); err != nil {
+		return "", "", "", err
+	}
+	return name, iban, description, nil
 }
 
-func (r *postgresRepository) CreateBulkTransfers(ctx context.Context, tx *sql.Tx, transfers []Transfer) error {
+func (r *postgresRepository) CreateBulkTransfers(ctx context.Context, tx *sql.Tx, transfers []Transfer, balanceCents int64) error {
+	if len(transfers) == 0 {
+		return nil
+	}
 	query := `
-		INSERT INTO transfers (counterparty_name, counterparty_iban, counterparty_bic, amount_cents, bank_account_id, description)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO transfers (id, counterparty_name, counterparty_iban, counterparty_bic, amount_cents, bank_account_id, description, batch_id, status, end_to_end_id, payment_information_id, counterparty_iban_index)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0), $9, $10, $11, $12)
+		RETURNING created_at
 	`
 
 	stmt, err := tx.PrepareContext(ctx, query)
@@ -26,19 +107,663 @@ func (r *postgresRepository) CreateBulkTransfers(ctx context.Context, tx *sql.Tx
 	}
 	defer stmt.Close()
 
-	for _, transfer := range transfers {
-		_, err := stmt.ExecContext(ctx,
-			transfer.CounterpartyName,
-			transfer.CounterpartyIBAN,
+	bankAccountID := transfers[0].BankAccountID
+	head, err := chainHead(ctx, tx, bankAccountID)
+	if err != nil {
+		return err
+	}
+
+	// The IDs are taken before the transfers are inserted, their encrypted values are bound to them
+	ids, err := nextTransferIDs(ctx, tx, len(transfers))
+	if err != nil {
+		return err
+	}
+
+	for i, transfer := range transfers {
+		if transfer.BankAccountID != bankAccountID {
+			return fmt.Errorf("%w: %d and %d", ErrMixedAccounts, bankAccountID, transfer.BankAccountID)
+		}
+		transfer.ID = ids[i]
+		name, iban, description, err := r.sealCounterparty(&transfer)
+		if err != nil {
+			return err
+		}
+		err = stmt.QueryRowContext(ctx,
+			transfer.ID,
+			name,
+			iban,
 			transfer.CounterpartyBIC,
 			transfer.AmountCents,
 			transfer.BankAccountID,
-			transfer.Description,
-		)
+			description,
+			transfer.BatchID,
+			transfer.status(),
+			transfer.EndToEndID,
+			transfer.PaymentInformationID,
+			r.ibanIndex(transfer.CounterpartyIBAN),
+		).Scan(&transfer.CreatedAt)
 		if err != nil {
 			return fmt.Errorf("failed to insert transfer: %w", err)
 		}
+
+		balanceCents -= transfer.AmountCents
+		if head, err = appendLink(ctx, tx, head, LinkDebit, &transfer, balanceCents); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nextTransferIDs takes n IDs of the sequence of the transfers, in ascending order
+func nextTransferIDs(ctx context.Context, tx *sql.Tx, n int) ([]int64, error) {
+	query := `SELECT nextval('transfers_id_seq') FROM generate_series(1, $1)`
+
+	rows, err := tx.QueryContext(ctx, query, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take transfer IDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0, n)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to take transfer IDs: %w", err)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// transferColumns are the columns scanned by scanTransfer
+const transferColumns = `id, counterparty_name, counterparty_iban, counterparty_bic, amount_cents, bank_account_id,
+	COALESCE(description, ''), COALESCE(batch_id, 0), status, returned_at, return_reason, end_to_end_id,
+	payment_information_id, created_at`
+
+// scanTransfer scans a row of transferColumns followed by the extra columns, decrypting the counterparty details
+func (r *postgresRepository) scanTransfer(row interface{ Scan(...any) error }, extra ...any) (*Transfer, error) {
+	var t Transfer
+	var returnedAt sql.NullTime
+	dest := []any{
+		&t.ID,
+		&t.CounterpartyName,
+		&t.CounterpartyIBAN,
+		&t.CounterpartyBIC,
+		&t.AmountCents,
+		&t.BankAccountID,
+		&t.Description,
+		&t.BatchID,
+		&t.Status,
+		&returnedAt,
+		&t.ReturnReason,
+		&t.EndToEndID,
+		&t.PaymentInformationID,
+		&t.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	t.ReturnedAt = returnedAt.Time
+
+	var err error
+	if t.CounterpartyName, err = r.decrypt(t.CounterpartyName, columnCounterpartyName, t.ID); err != nil {
+		return nil, err
+	}
+	if t.CounterpartyIBAN, err = r.decrypt(t.CounterpartyIBAN, columnCounterpartyIBAN, t.ID); err != nil {
+		return nil, err
+	}
+	if t.Description, err = r.decrypt(t.Description, columnDescription, t.ID); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *postgresRepository) Get(ctx context.Context, tx *sql.Tx, id int64) (*Transfer, error) {
+	query := `SELECT ` + transferColumns + ` FROM transfers WHERE id = $1`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query+" FOR UPDATE", id)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, id)
+	}
+
+	t, err := r.scanTransfer(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *postgresRepository) UpdateStatus(ctx context.Context, tx *sql.Tx, t *Transfer) error {
+	query := `
+		UPDATE transfers
+		SET status = $1, returned_at = $2, return_reason = $3
+		WHERE id = $4
+	`
+	returnedAt := sql.NullTime{Time: t.ReturnedAt, Valid: !t.ReturnedAt.IsZero()}
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, t.status(), returnedAt, t.ReturnReason, t.ID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, t.status(), returnedAt, t.ReturnReason, t.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update transfer status: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) ListByBatch(ctx context.Context, batchID, afterID int64, limit int) ([]Transfer, error) {
+	query := `SELECT ` + transferColumns + ` FROM transfers WHERE batch_id = $1 AND id > $2 ORDER BY id LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, query, batchID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var transfers []Transfer
+	for rows.Next() {
+		t, err := r.scanTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transfer: %w", err)
+		}
+		transfers = append(transfers, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+func (r *postgresRepository) CountByPaymentInformation(ctx context.Context, batchID int64) ([]PaymentInformationCount, error) {
+	query := `
+		SELECT payment_information_id, COUNT(*), COUNT(*) FILTER (WHERE status = $2)
+		FROM transfers
+		WHERE batch_id = $1
+		GROUP BY payment_information_id
+		ORDER BY MIN(id)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, batchID, StatusReturned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []PaymentInformationCount
+	for rows.Next() {
+		var c PaymentInformationCount
+		if err := rows.Scan(&c.PaymentInformationID, &c.Transfers, &c.Returned); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count transfers: %w", err)
+	}
+	return counts, nil
+}
+
+func (r *postgresRepository) SummarizeMovements(ctx context.Context, tx *sql.Tx, bankAccountID int64, from, to time.Time) (*MovementSummary, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE debited),
+			COALESCE(SUM(amount_cents) FILTER (WHERE debited), 0),
+			COUNT(*) FILTER (WHERE credited),
+			COALESCE(SUM(amount_cents) FILTER (WHERE credited), 0)
+		FROM (
+			SELECT amount_cents,
+				created_at >= $2 AND ($3::timestamptz IS NULL OR created_at < $3) AS debited,
+				status = $4 AND returned_at >= $2 AND ($3::timestamptz IS NULL OR returned_at < $3) AS credited
+			FROM transfers
+			WHERE bank_account_id = $1 AND (created_at >= $2 OR returned_at >= $2)
+		) movements
+	`
+	args := []any{bankAccountID, from, sql.NullTime{Time: to, Valid: !to.IsZero()}, StatusReturned}
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, args...)
+	}
+
+	var s MovementSummary
+	if err := row.Scan(&s.Debits, &s.DebitCents, &s.Credits, &s.CreditCents); err != nil {
+		return nil, fmt.Errorf("failed to summarize movements: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *postgresRepository) ForEachMovement(ctx context.Context, tx *sql.Tx, bankAccountID int64, from, to time.Time, fn func(Movement) error) error {
+	query := `
+		SELECT ` + transferColumns + `, FALSE AS credit, created_at AS booked_at
+		FROM transfers
+		WHERE bank_account_id = $1 AND created_at >= $2 AND created_at < $3
+		UNION ALL
+		SELECT ` + transferColumns + `, TRUE AS credit, returned_at AS booked_at
+		FROM transfers
+		WHERE bank_account_id = $1 AND status = $4 AND returned_at >= $2 AND returned_at < $3
+		ORDER BY booked_at, id, credit
+	`
+	args := []any{bankAccountID, from, to, StatusReturned}
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, args...)
+	} else {
+		rows, err = r.db.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list movements: %w", err)
 	}
+	defer rows.Close()
 
+	for rows.Next() {
+		var m Movement
+		t, err := r.scanTransfer(rows, &m.Credit, &m.BookedAt)
+		if err != nil {
+			return fmt.Errorf("failed to scan movement: %w", err)
+		}
+		m.Transfer = *t
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list movements: %w", err)
+	}
 	return nil
 }
+
+// sendingLockKey is the advisory lock key of the sending of transfers
+const sendingLockKey = 7306112
+
+func (r *postgresRepository) LockSending(ctx context.Context, tx *sql.Tx) (bool, error) {
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, sendingLockKey).Scan(&locked); err != nil {
+		return false, fmt.Errorf("failed to lock the sending of transfers: %w", err)
+	}
+	return locked, nil
+}
+
+func (r *postgresRepository) ClaimUnsent(ctx context.Context, tx *sql.Tx, limit int) ([]Transfer, error) {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM transfers
+		WHERE status = $1 AND sent_at IS NULL
+		ORDER BY bank_account_id, created_at, id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.QueryContext(ctx, query, StatusExecuted, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim unsent transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var transfers []Transfer
+	for rows.Next() {
+		t, err := r.scanTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transfer: %w", err)
+		}
+		transfers = append(transfers, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim unsent transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+func (r *postgresRepository) MarkSent(ctx context.Context, tx *sql.Tx, ids []int64, messageID string, sentAt time.Time) error {
+	query := `
+		UPDATE transfers
+		SET sent_at = $2, sent_message_id = $3
+		WHERE id = ANY($1) AND sent_at IS NULL
+	`
+
+	res, err := tx.ExecContext(ctx, query, pq.Array(ids), sentAt, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to mark transfers as sent: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark transfers as sent: %w", err)
+	}
+	if n != int64(len(ids)) {
+		return fmt.Errorf("%w: %d of %d transfers of message %s", ErrAlreadySent, int64(len(ids))-n, len(ids), messageID)
+	}
+	return nil
+}
+
+func (r *postgresRepository) CountSent(ctx context.Context, messageID string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM transfers WHERE sent_message_id = $1`, messageID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count sent transfers: %w", err)
+	}
+	return n, nil
+}
+
+// exportFetchSize is the number of transfers fetched at a time from the cursor of ForEachTransfer
+const exportFetchSize = 1000
+
+func (r *postgresRepository) ForEachTransfer(ctx context.Context, tx *sql.Tx, filter Filter, fn func(Transfer) error) error {
+	conditions := []string{"bank_account_id = $1"}
+	args := []any{filter.BankAccountID}
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		where("created_at >= $%d", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		where("created_at < $%d", filter.CreatedTo)
+	}
+	if filter.Status != "" {
+		where("status = $%d", filter.Status)
+	}
+	if filter.BatchID != 0 {
+		where("batch_id = $%d", filter.BatchID)
+	}
+	if filter.CounterpartyIBAN != "" {
+		// Encrypted IBANs are found by their blind index, the IBANs stored in clear before the
+		// encryption was enabled are indexed by the re-encryption
+		if r.cipher != nil {
+			where("counterparty_iban_index = $%d", r.cipher.BlindIndex(filter.CounterpartyIBAN))
+		} else {
+			where("counterparty_iban = $%d", filter.CounterpartyIBAN)
+		}
+	}
+
+	query := `DECLARE transfers_export NO SCROLL CURSOR FOR
+		SELECT ` + transferColumns + ` FROM transfers
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY created_at, id`
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to declare transfers cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM transfers_export", exportFetchSize)
+	for {
+		fetched, err := r.fetchTransfers(ctx, tx, fetch, fn)
+		if err != nil {
+			return err
+		}
+		if fetched < exportFetchSize {
+			break
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "CLOSE transfers_export"); err != nil {
+		return fmt.Errorf("failed to close transfers cursor: %w", err)
+	}
+	return nil
+}
+
+// fetchTransfers fetches the next chunk of transfers from the cursor of ForEachTransfer,
+// calls fn for each of them and returns how many were fetched
+func (r *postgresRepository) fetchTransfers(ctx context.Context, tx *sql.Tx, fetch string, fn func(Transfer) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch transfers: %w", err)
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		t, err := r.scanTransfer(rows)
+		if err != nil {
+			return 0, fmt.Errorf("failed to scan transfer: %w", err)
+		}
+		fetched++
+		if err := fn(*t); err != nil {
+			return 0, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to fetch transfers: %w", err)
+	}
+	return fetched, nil
+}
+
+func (r *postgresRepository) Reencrypt(ctx context.Context, afterID int64, limit int) (*ReencryptBatch, error) {
+	if r.cipher == nil {
+		return nil, ErrEncryptionDisabled
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The transfers are locked so that a concurrent update of their status is not lost
+	query := `
+		SELECT id, counterparty_name, counterparty_iban, COALESCE(description, ''), counterparty_iban_index IS NOT NULL
+		FROM transfers
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE
+	`
+	rows, err := tx.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers to re-encrypt: %w", err)
+	}
+	defer rows.Close()
+
+	var batch ReencryptBatch
+	var stale []Transfer
+	for rows.Next() {
+		var t Transfer
+		var indexed bool
+		if err := rows.Scan(&t.ID, &t.CounterpartyName, &t.CounterpartyIBAN, &t.Description, &indexed); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer: %w", err)
+		}
+		batch.Scanned++
+		batch.LastID = t.ID
+		if indexed && r.current(t.CounterpartyName) && r.current(t.CounterpartyIBAN) && r.current(t.Description) {
+			continue
+		}
+		stale = append(stale, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list transfers to re-encrypt: %w", err)
+	}
+
+	update := `
+		UPDATE transfers
+		SET counterparty_name = $2, counterparty_iban = $3, description = COALESCE(NULLIF($4, ''), description), counterparty_iban_index = $5
+		WHERE id = $1
+	`
+	for _, t := range stale {
+		if t.CounterpartyName, err = r.decrypt(t.CounterpartyName, columnCounterpartyName, t.ID); err != nil {
+			return nil, fmt.Errorf("transfer %d: %w", t.ID, err)
+		}
+		if t.CounterpartyIBAN, err = r.decrypt(t.CounterpartyIBAN, columnCounterpartyIBAN, t.ID); err != nil {
+			return nil, fmt.Errorf("transfer %d: %w", t.ID, err)
+		}
+		if t.Description, err = r.decrypt(t.Description, columnDescription, t.ID); err != nil {
+			return nil, fmt.Errorf("transfer %d: %w", t.ID, err)
+		}
+		name, iban, description, err := r.sealCounterparty(&t)
+		if err != nil {
+			return nil, fmt.Errorf("transfer %d: %w", t.ID, err)
+		}
+		if _, err := tx.ExecContext(ctx, update, t.ID, name, iban, description, r.ibanIndex(t.CounterpartyIBAN)); err != nil {
+			return nil, fmt.Errorf("failed to re-encrypt transfer %d: %w", t.ID, err)
+		}
+		batch.Reencrypted++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// current reports whether a stored value needs no re-encryption, empty values are not encrypted
+func (r *postgresRepository) current(stored string) bool {
+	return stored == "" || r.cipher.Current(stored)
+}
+
+// chainHead returns the last link of the hash chain of the bank account, a link with the
+// sequence 0 and the genesis hash when the chain is empty
+func chainHead(ctx context.Context, tx *sql.Tx, bankAccountID int64) (*Link, error) {
+	query := `SELECT seq, hash, balance_cents FROM chain_links WHERE bank_account_id = $1 ORDER BY seq DESC LIMIT 1`
+
+	head := &Link{BankAccountID: bankAccountID, Hash: GenesisHash}
+	err := tx.QueryRowContext(ctx, query, bankAccountID).Scan(&head.Seq, &head.Hash, &head.BalanceCents)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to read chain head: %w", err)
+	}
+	return head, nil
+}
+
+// appendLink appends the link of the change of the balance made by the transfer after head,
+// and returns it as the new head
+func appendLink(ctx context.Context, tx *sql.Tx, head *Link, kind LinkKind, t *Transfer, balanceCents int64) (*Link, error) {
+	link := &Link{
+		BankAccountID: head.BankAccountID,
+		Seq:           head.Seq + 1,
+		Kind:          kind,
+		TransferID:    t.ID,
+		AmountCents:   t.AmountCents,
+		BalanceCents:  balanceCents,
+		PrevHash:      head.Hash,
+	}
+	link.Hash = link.ComputeHash(t)
+
+	query := `
+		INSERT INTO chain_links (bank_account_id, seq, kind, transfer_id, amount_cents, balance_cents, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := tx.ExecContext(ctx, query, link.BankAccountID, link.Seq, link.Kind, link.TransferID, link.AmountCents, link.BalanceCents, link.PrevHash, link.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to append chain link: %w", err)
+	}
+	return link, nil
+}
+
+func (r *postgresRepository) ChainReturn(ctx context.Context, tx *sql.Tx, t *Transfer, balanceCents int64) error {
+	// The return is hashed as stored, the database keeps its time to the microsecond
+	returned := *t
+	var returnedAt sql.NullTime
+	err := tx.QueryRowContext(ctx, `SELECT status, return_reason, returned_at FROM transfers WHERE id = $1`, t.ID).
+		Scan(&returned.Status, &returned.ReturnReason, &returnedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to read returned transfer: %w", err)
+	}
+	returned.ReturnedAt = returnedAt.Time
+
+	head, err := chainHead(ctx, tx, t.BankAccountID)
+	if err != nil {
+		return err
+	}
+	_, err = appendLink(ctx, tx, head, LinkCredit, &returned, balanceCents)
+	return err
+}
+
+func (r *postgresRepository) ChainAccounts(ctx context.Context) ([]int64, error) {
+	// The accounts whose links were all deleted are listed from their transfers
+	query := `
+		SELECT bank_account_id FROM transfers
+		UNION
+		SELECT bank_account_id FROM chain_links
+		ORDER BY bank_account_id
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chain accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan chain account: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list chain accounts: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *postgresRepository) ForEachLink(ctx context.Context, tx *sql.Tx, bankAccountID int64, fn func(Link, *Transfer) error) error {
+	// The transfer columns are those of transferColumns, empty when the transfer no longer exists
+	query := `
+		SELECT COALESCE(t.id, 0), COALESCE(t.counterparty_name, ''), COALESCE(t.counterparty_iban, ''),
+			COALESCE(t.counterparty_bic, ''), COALESCE(t.amount_cents, 0), COALESCE(t.bank_account_id, 0),
+			COALESCE(t.description, ''), COALESCE(t.batch_id, 0), COALESCE(t.status, ''), t.returned_at,
+			COALESCE(t.return_reason, ''), COALESCE(t.end_to_end_id, ''), COALESCE(t.payment_information_id, ''),
+			COALESCE(t.created_at, l.created_at), t.id IS NOT NULL,
+			l.bank_account_id, l.seq, l.kind, l.transfer_id, l.amount_cents, l.balance_cents, l.prev_hash, l.hash, l.created_at
+		FROM chain_links l
+		LEFT JOIN transfers t ON t.id = l.transfer_id
+		WHERE l.bank_account_id = $1
+		ORDER BY l.seq
+	`
+
+	rows, err := tx.QueryContext(ctx, query, bankAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to list chain links: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l Link
+		var found bool
+		t, err := r.scanTransfer(rows, &found, &l.BankAccountID, &l.Seq, &l.Kind, &l.TransferID, &l.AmountCents, &l.BalanceCents, &l.PrevHash, &l.Hash, &l.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to scan chain link: %w", err)
+		}
+		if !found {
+			t = nil
+		}
+		if err := fn(l, t); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list chain links: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) FirstUnchained(ctx context.Context, tx *sql.Tx, bankAccountID int64) (int64, error) {
+	query := `
+		SELECT COALESCE(MIN(t.id), 0)
+		FROM transfers t
+		WHERE t.bank_account_id = $1
+			AND t.id >= (SELECT first_transfer_id FROM chain_start)
+			AND NOT EXISTS (SELECT 1 FROM chain_links l WHERE l.transfer_id = t.id AND l.kind = $2)
+	`
+
+	var id int64
+	if err := tx.QueryRowContext(ctx, query, bankAccountID, LinkDebit).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to find unchained transfers: %w", err)
+	}
+	return id, nil
+}
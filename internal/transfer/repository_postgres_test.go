@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"fmt"
 	"testing"
+	"time"
+
+	"moneytransfer/internal/fieldcrypt"
 
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/suite"
@@ -54,7 +57,7 @@ func (s *PostgresRepositoryTestSuite) SetupSuite() {
 	s.db, err = sql.Open("postgres", dbURL)
 	s.Require().NoError(err)
 
-	s.repo = NewPostgresRepository(s.db)
+	s.repo = NewPostgresRepository(s.db, nil)
 
 	err = s.db.Ping()
 	s.Require().NoError(err)
@@ -76,8 +79,36 @@ func (s *PostgresRepositoryTestSuite) createSchema() {
 			counterparty_bic TEXT NOT NULL,
 			amount_cents INTEGER NOT NULL,
 			bank_account_id INTEGER NOT NULL,
-			description TEXT
-		)
+			description TEXT,
+			batch_id BIGINT,
+			status TEXT NOT NULL DEFAULT 'executed',
+			returned_at TIMESTAMPTZ,
+			return_reason TEXT NOT NULL DEFAULT '',
+			end_to_end_id TEXT NOT NULL DEFAULT '',
+			payment_information_id TEXT NOT NULL DEFAULT '',
+			sent_at TIMESTAMPTZ,
+			sent_message_id TEXT NOT NULL DEFAULT '',
+			counterparty_iban_index TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		CREATE TABLE IF NOT EXISTS chain_links (
+			id BIGSERIAL PRIMARY KEY,
+			bank_account_id BIGINT NOT NULL,
+			seq BIGINT NOT NULL,
+			kind TEXT NOT NULL,
+			transfer_id BIGINT NOT NULL,
+			amount_cents BIGINT NOT NULL,
+			balance_cents BIGINT NOT NULL,
+			prev_hash TEXT NOT NULL,
+			hash TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			UNIQUE (bank_account_id, seq)
+		);
+		CREATE TABLE IF NOT EXISTS chain_start (
+			id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+			first_transfer_id BIGINT NOT NULL
+		);
+		INSERT INTO chain_start (first_transfer_id) VALUES (1) ON CONFLICT (id) DO NOTHING
 	`)
 	s.Require().NoError(err)
 }
@@ -99,7 +130,7 @@ func (s *PostgresRepositoryTestSuite) TestCreateBulkTransfers() {
 				CounterpartyIBAN: "DE89370400440532013000",
 				CounterpartyBIC:  "DEUTDEFF",
 				AmountCents:      20000,
-				BankAccountID:    2,
+				BankAccountID:    1,
 				Description:      "Test transfer 2",
 			},
 		}
@@ -107,7 +138,7 @@ func (s *PostgresRepositoryTestSuite) TestCreateBulkTransfers() {
 		tx, err := s.db.Begin()
 		s.Require().NoError(err)
 
-		err = s.repo.CreateBulkTransfers(s.ctx, tx, transfers)
+		err = s.repo.CreateBulkTransfers(s.ctx, tx, transfers, 50000)
 		s.Require().NoError(err)
 
 		err = tx.Commit()
@@ -120,3 +151,490 @@ func (s *PostgresRepositoryTestSuite) TestCreateBulkTransfers() {
 		s.Equal(2, count)
 	})
 }
+
+func (s *PostgresRepositoryTestSuite) TestGetAndUpdateStatus() {
+	tx, err := s.db.Begin()
+	s.Require().NoError(err)
+	err = s.repo.CreateBulkTransfers(s.ctx, tx, []Transfer{{
+		CounterpartyName: "John Doe",
+		CounterpartyIBAN: "GB29NWBK60161331926819",
+		CounterpartyBIC:  "NWBKGB2L",
+		AmountCents:      10000,
+		BankAccountID:    1,
+		Description:      "Returned transfer",
+		EndToEndID:       "E2E-1",
+	}}, 0)
+	s.Require().NoError(err)
+	s.Require().NoError(tx.Commit())
+
+	var id int64
+	err = s.db.QueryRow("SELECT id FROM transfers WHERE description = 'Returned transfer'").Scan(&id)
+	s.Require().NoError(err)
+
+	s.Run("Returned transfer is stored", func() {
+		t, err := s.repo.Get(s.ctx, nil, id)
+		s.Require().NoError(err)
+		s.Equal(StatusExecuted, t.Status)
+		s.Equal("E2E-1", t.EndToEndID)
+
+		s.Require().NoError(t.Return("AC04", time.Now().UTC().Truncate(time.Second)))
+		s.Require().NoError(s.repo.UpdateStatus(s.ctx, nil, t))
+
+		got, err := s.repo.Get(s.ctx, nil, id)
+		s.Require().NoError(err)
+		s.Equal(StatusReturned, got.Status)
+		s.Equal("AC04", got.ReturnReason)
+		s.True(t.ReturnedAt.Equal(got.ReturnedAt))
+	})
+
+	s.Run("Unknown transfer", func() {
+		_, err := s.repo.Get(s.ctx, nil, -1)
+		s.ErrorIs(err, ErrNotFound)
+	})
+}
+
+func (s *PostgresRepositoryTestSuite) TestListAndCountByBatch() {
+	newTransfer := func(pmtInfID, description string) Transfer {
+		return Transfer{
+			CounterpartyName:     "John Doe",
+			CounterpartyIBAN:     "GB29NWBK60161331926819",
+			CounterpartyBIC:      "NWBKGB2L",
+			AmountCents:          100,
+			BankAccountID:        1,
+			Description:          description,
+			BatchID:              42,
+			PaymentInformationID: pmtInfID,
+		}
+	}
+	tx, err := s.db.Begin()
+	s.Require().NoError(err)
+	err = s.repo.CreateBulkTransfers(s.ctx, tx, []Transfer{
+		newTransfer("PMT-1", "Batch transfer 1"),
+		newTransfer("PMT-1", "Batch transfer 2"),
+		newTransfer("PMT-2", "Batch transfer 3"),
+	}, 0)
+	s.Require().NoError(err)
+	s.Require().NoError(tx.Commit())
+
+	s.Run("Transfers are listed by pages", func() {
+		first, err := s.repo.ListByBatch(s.ctx, 42, 0, 2)
+		s.Require().NoError(err)
+		s.Require().Len(first, 2)
+		s.Equal("Batch transfer 1", first[0].Description)
+		s.Equal("PMT-1", first[0].PaymentInformationID)
+
+		rest, err := s.repo.ListByBatch(s.ctx, 42, first[1].ID, 2)
+		s.Require().NoError(err)
+		s.Require().Len(rest, 1)
+		s.Equal("Batch transfer 3", rest[0].Description)
+	})
+
+	s.Run("Returned transfers are counted by block", func() {
+		transfers, err := s.repo.ListByBatch(s.ctx, 42, 0, 1)
+		s.Require().NoError(err)
+		s.Require().NoError(transfers[0].Return("AC04", time.Now()))
+		s.Require().NoError(s.repo.UpdateStatus(s.ctx, nil, &transfers[0]))
+
+		counts, err := s.repo.CountByPaymentInformation(s.ctx, 42)
+		s.Require().NoError(err)
+		s.Equal([]PaymentInformationCount{
+			{PaymentInformationID: "PMT-1", Transfers: 2, Returned: 1},
+			{PaymentInformationID: "PMT-2", Transfers: 1},
+		}, counts)
+	})
+}
+
+func (s *PostgresRepositoryTestSuite) TestMovements() {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	tx, err := s.db.Begin()
+	s.Require().NoError(err)
+	err = s.repo.CreateBulkTransfers(s.ctx, tx, []Transfer{
+		{CounterpartyName: "Before", CounterpartyIBAN: "IBAN1", CounterpartyBIC: "BIC1", AmountCents: 100, BankAccountID: 7, Description: "Movement before"},
+		{CounterpartyName: "During", CounterpartyIBAN: "IBAN2", CounterpartyBIC: "BIC2", AmountCents: 200, BankAccountID: 7, Description: "Movement during"},
+		{CounterpartyName: "After", CounterpartyIBAN: "IBAN3", CounterpartyBIC: "BIC3", AmountCents: 400, BankAccountID: 7, Description: "Movement after"},
+	}, 0)
+	s.Require().NoError(err)
+	s.Require().NoError(tx.Commit())
+
+	// The first transfer is debited the day before and returned during the day
+	for description, createdAt := range map[string]time.Time{
+		"Movement before": day.Add(-time.Hour),
+		"Movement during": day.Add(time.Hour),
+		"Movement after":  day.Add(25 * time.Hour),
+	} {
+		_, err := s.db.Exec("UPDATE transfers SET created_at = $1 WHERE description = $2", createdAt, description)
+		s.Require().NoError(err)
+	}
+	_, err = s.db.Exec("UPDATE transfers SET status = 'returned', returned_at = $1 WHERE description = 'Movement before'", day.Add(2*time.Hour))
+	s.Require().NoError(err)
+
+	s.Run("Movements of a day are summarized", func() {
+		summary, err := s.repo.SummarizeMovements(s.ctx, nil, 7, day, day.Add(24*time.Hour))
+		s.Require().NoError(err)
+		s.Equal(MovementSummary{Debits: 1, DebitCents: 200, Credits: 1, CreditCents: 100}, *summary)
+	})
+
+	s.Run("Movements since a time are summarized", func() {
+		summary, err := s.repo.SummarizeMovements(s.ctx, nil, 7, day.Add(24*time.Hour), time.Time{})
+		s.Require().NoError(err)
+		s.Equal(MovementSummary{Debits: 1, DebitCents: 400}, *summary)
+	})
+
+	s.Run("Movements of a day are listed by booking time", func() {
+		var movements []Movement
+		err := s.repo.ForEachMovement(s.ctx, nil, 7, day, day.Add(24*time.Hour), func(m Movement) error {
+			movements = append(movements, m)
+			return nil
+		})
+		s.Require().NoError(err)
+		s.Require().Len(movements, 2)
+		s.Equal("Movement during", movements[0].Transfer.Description)
+		s.False(movements[0].Credit)
+		s.Equal("Movement before", movements[1].Transfer.Description)
+		s.True(movements[1].Credit)
+		s.True(day.Add(2 * time.Hour).Equal(movements[1].BookedAt))
+	})
+}
+
+func (s *PostgresRepositoryTestSuite) TestSent() {
+	tx, err := s.db.Begin()
+	s.Require().NoError(err)
+	err = s.repo.CreateBulkTransfers(s.ctx, tx, []Transfer{
+		{CounterpartyName: "First", CounterpartyIBAN: "IBAN1", CounterpartyBIC: "BIC1", AmountCents: 100, BankAccountID: 9, Description: "Unsent"},
+		{CounterpartyName: "Second", CounterpartyIBAN: "IBAN2", CounterpartyBIC: "BIC2", AmountCents: 200, BankAccountID: 9, Description: "Unsent"},
+		{CounterpartyName: "Returned", CounterpartyIBAN: "IBAN3", CounterpartyBIC: "BIC3", AmountCents: 400, BankAccountID: 9, Description: "Unsent", Status: StatusReturned},
+	}, 0)
+	s.Require().NoError(err)
+	s.Require().NoError(tx.Commit())
+
+	// claim returns the IDs of the unsent transfers of the account, locked by tx
+	claim := func(tx *sql.Tx) []int64 {
+		transfers, err := s.repo.ClaimUnsent(s.ctx, tx, 1000)
+		s.Require().NoError(err)
+		var ids []int64
+		for _, t := range transfers {
+			if t.BankAccountID == 9 {
+				ids = append(ids, t.ID)
+			}
+		}
+		return ids
+	}
+
+	tx, err = s.db.Begin()
+	s.Require().NoError(err)
+	ids := claim(tx)
+	s.Require().Len(ids, 2, "returned transfers are not sent")
+
+	s.Run("Claimed transfers are skipped by other transactions", func() {
+		other, err := s.db.Begin()
+		s.Require().NoError(err)
+		defer other.Rollback()
+		s.Empty(claim(other))
+	})
+
+	s.Require().NoError(s.repo.MarkSent(s.ctx, tx, ids, "OUT-9-1", time.Now()))
+	s.Require().NoError(tx.Commit())
+
+	s.Run("Sent transfers are not claimed again", func() {
+		tx, err := s.db.Begin()
+		s.Require().NoError(err)
+		defer tx.Rollback()
+		s.Empty(claim(tx))
+		s.ErrorIs(s.repo.MarkSent(s.ctx, tx, ids, "OUT-9-2", time.Now()), ErrAlreadySent)
+	})
+
+	s.Run("A single transaction sends transfers at a time", func() {
+		first, err := s.db.Begin()
+		s.Require().NoError(err)
+		defer first.Rollback()
+		second, err := s.db.Begin()
+		s.Require().NoError(err)
+		defer second.Rollback()
+
+		locked, err := s.repo.LockSending(s.ctx, first)
+		s.Require().NoError(err)
+		s.True(locked)
+		locked, err = s.repo.LockSending(s.ctx, second)
+		s.Require().NoError(err)
+		s.False(locked)
+	})
+
+	s.Run("Sent transfers are counted by message", func() {
+		n, err := s.repo.CountSent(s.ctx, "OUT-9-1")
+		s.Require().NoError(err)
+		s.Equal(2, n)
+
+		n, err = s.repo.CountSent(s.ctx, "OUT-9-2")
+		s.Require().NoError(err)
+		s.Zero(n)
+	})
+}
+
+func (s *PostgresRepositoryTestSuite) TestForEachTransfer() {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	transfers := make([]Transfer, exportFetchSize+1)
+	for i := range transfers {
+		transfers[i] = Transfer{CounterpartyName: "Export", CounterpartyIBAN: "IBAN1", CounterpartyBIC: "BIC1", AmountCents: int64(i + 1), BankAccountID: 11, Description: "Export", BatchID: 5}
+	}
+	transfers[0].Status = StatusReturned
+	transfers[1].BatchID = 6
+	tx, err := s.db.Begin()
+	s.Require().NoError(err)
+	s.Require().NoError(s.repo.CreateBulkTransfers(s.ctx, tx, transfers, 0))
+	s.Require().NoError(tx.Commit())
+	_, err = s.db.Exec("UPDATE transfers SET created_at = $1 WHERE bank_account_id = 11 AND amount_cents = 2", day.Add(-time.Hour))
+	s.Require().NoError(err)
+
+	// export returns the amounts of the transfers matching the filter, read in a repeatable read transaction
+	export := func(filter Filter, during func()) []int64 {
+		tx, err := s.db.BeginTx(s.ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+		s.Require().NoError(err)
+		defer tx.Rollback()
+		var amounts []int64
+		err = s.repo.ForEachTransfer(s.ctx, tx, filter, func(t Transfer) error {
+			if len(amounts) == 0 && during != nil {
+				during()
+			}
+			amounts = append(amounts, t.AmountCents)
+			return nil
+		})
+		s.Require().NoError(err)
+		return amounts
+	}
+
+	s.Run("Transfers are fetched in chunks by creation time", func() {
+		amounts := export(Filter{BankAccountID: 11}, nil)
+		s.Require().Len(amounts, exportFetchSize+1)
+		s.Equal(int64(2), amounts[0])
+	})
+
+	s.Run("Transfers are filtered", func() {
+		s.Equal([]int64{1}, export(Filter{BankAccountID: 11, Status: StatusReturned}, nil))
+		s.Equal([]int64{2}, export(Filter{BankAccountID: 11, BatchID: 6}, nil))
+		s.Equal([]int64{2}, export(Filter{BankAccountID: 11, CreatedTo: day}, nil))
+		s.Len(export(Filter{BankAccountID: 11, CreatedFrom: day}, nil), exportFetchSize)
+	})
+
+	s.Run("Transfers inserted during the export are not read", func() {
+		amounts := export(Filter{BankAccountID: 11}, func() {
+			tx, err := s.db.Begin()
+			s.Require().NoError(err)
+			s.Require().NoError(s.repo.CreateBulkTransfers(s.ctx, tx, []Transfer{
+				{CounterpartyName: "Late", CounterpartyIBAN: "IBAN1", CounterpartyBIC: "BIC1", AmountCents: 99999, BankAccountID: 11, Description: "Late"},
+			}, 0))
+			s.Require().NoError(tx.Commit())
+		})
+		s.Len(amounts, exportFetchSize+1)
+		s.NotContains(amounts, int64(99999))
+	})
+}
+
+func (s *PostgresRepositoryTestSuite) TestEncryption() {
+	key := func(b byte) []byte {
+		k := make([]byte, fieldcrypt.KeySize)
+		for i := range k {
+			k[i] = b
+		}
+		return k
+	}
+	keyring, err := fieldcrypt.NewKeyring(map[string][]byte{"k1": key(1)}, "k1", key(9))
+	s.Require().NoError(err)
+	encrypted := NewPostgresRepository(s.db, keyring)
+
+	create := func(repo Repository, t Transfer) {
+		tx, err := s.db.Begin()
+		s.Require().NoError(err)
+		s.Require().NoError(repo.CreateBulkTransfers(s.ctx, tx, []Transfer{t}, 0))
+		s.Require().NoError(tx.Commit())
+	}
+	// The transfer in clear was created before the encryption was enabled
+	create(s.repo, Transfer{CounterpartyName: "Clear", CounterpartyIBAN: "FR7630006000011234567890189", CounterpartyBIC: "BIC1", AmountCents: 1, BankAccountID: 21, Description: "Before"})
+	create(encrypted, Transfer{CounterpartyName: "Sealed", CounterpartyIBAN: "FR7630006000011234567890189", CounterpartyBIC: "BIC1", AmountCents: 2, BankAccountID: 21, Description: "After"})
+
+	// export returns the transfers of the account to the IBAN
+	export := func(repo Repository, iban string) []Transfer {
+		tx, err := s.db.BeginTx(s.ctx, &sql.TxOptions{ReadOnly: true})
+		s.Require().NoError(err)
+		defer tx.Rollback()
+		var transfers []Transfer
+		s.Require().NoError(repo.ForEachTransfer(s.ctx, tx, Filter{BankAccountID: 21, CounterpartyIBAN: iban}, func(t Transfer) error {
+			transfers = append(transfers, t)
+			return nil
+		}))
+		return transfers
+	}
+
+	s.Run("Counterparty details are stored encrypted", func() {
+		var name, iban, description string
+		err := s.db.QueryRow("SELECT counterparty_name, counterparty_iban, description FROM transfers WHERE bank_account_id = 21 AND amount_cents = 2").Scan(&name, &iban, &description)
+		s.Require().NoError(err)
+		s.NotContains(name+iban+description, "Sealed")
+		s.NotContains(iban, "FR76")
+		s.NotContains(description, "After")
+	})
+
+	s.Run("Values moved to another transfer are not decrypted", func() {
+		create(encrypted, Transfer{CounterpartyName: "Other", CounterpartyIBAN: "DE89370400440532013000", CounterpartyBIC: "BIC2", AmountCents: 3, BankAccountID: 22, Description: "Swapped"})
+		var id int64
+		s.Require().NoError(s.db.QueryRow("SELECT id FROM transfers WHERE bank_account_id = 22 AND amount_cents = 3").Scan(&id))
+		_, err := s.db.Exec(`UPDATE transfers SET counterparty_iban = (SELECT counterparty_iban FROM transfers WHERE bank_account_id = 21 AND amount_cents = 2)
+			WHERE id = $1`, id)
+		s.Require().NoError(err)
+
+		_, err = encrypted.Get(s.ctx, nil, id)
+		s.ErrorIs(err, fieldcrypt.ErrInvalidCiphertext)
+	})
+
+	s.Run("Encrypted transfers are read in clear and found by IBAN", func() {
+		transfers := export(encrypted, "fr76 3000 6000 0112 3456 7890 189")
+		s.Require().Len(transfers, 1)
+		s.Equal("Sealed", transfers[0].CounterpartyName)
+		s.Equal("FR7630006000011234567890189", transfers[0].CounterpartyIBAN)
+		s.Equal("After", transfers[0].Description)
+	})
+
+	s.Run("Re-encryption encrypts and indexes the transfers in clear", func() {
+		var total ReencryptBatch
+		for afterID := int64(0); ; {
+			batch, err := encrypted.Reencrypt(s.ctx, afterID, 100)
+			s.Require().NoError(err)
+			total.Reencrypted += batch.Reencrypted
+			if batch.Scanned < 100 {
+				break
+			}
+			afterID = batch.LastID
+		}
+		s.GreaterOrEqual(total.Reencrypted, 1)
+
+		transfers := export(encrypted, "FR7630006000011234567890189")
+		s.Require().Len(transfers, 2)
+		s.Equal("Clear", transfers[0].CounterpartyName)
+		s.Equal("Before", transfers[0].Description)
+
+		again, err := encrypted.Reencrypt(s.ctx, 0, 1000000)
+		s.Require().NoError(err)
+		s.Zero(again.Reencrypted)
+	})
+
+	s.Run("Rotated keys re-encrypt with the new primary key", func() {
+		rotated, err := fieldcrypt.NewKeyring(map[string][]byte{"k1": key(1), "k2": key(2)}, "k2", key(9))
+		s.Require().NoError(err)
+		repo := NewPostgresRepository(s.db, rotated)
+		_, err = repo.Reencrypt(s.ctx, 0, 1000000)
+		s.Require().NoError(err)
+
+		retired, err := fieldcrypt.NewKeyring(map[string][]byte{"k2": key(2)}, "k2", key(9))
+		s.Require().NoError(err)
+		s.Len(export(NewPostgresRepository(s.db, retired), "FR7630006000011234567890189"), 2)
+	})
+
+	s.Run("Re-encryption requires a cipher", func() {
+		_, err := s.repo.Reencrypt(s.ctx, 0, 10)
+		s.ErrorIs(err, ErrEncryptionDisabled)
+	})
+}
+
+func (s *PostgresRepositoryTestSuite) TestChain() {
+	tx, err := s.db.Begin()
+	s.Require().NoError(err)
+	s.Require().NoError(s.repo.CreateBulkTransfers(s.ctx, tx, []Transfer{
+		{CounterpartyName: "First", CounterpartyIBAN: "IBAN1", CounterpartyBIC: "BIC1", AmountCents: 100, BankAccountID: 31, Description: "Chained"},
+		{CounterpartyName: "Second", CounterpartyIBAN: "IBAN2", CounterpartyBIC: "BIC2", AmountCents: 200, BankAccountID: 31, Description: "Chained"},
+	}, 1000))
+	s.Require().NoError(tx.Commit())
+
+	var id int64
+	s.Require().NoError(s.db.QueryRow("SELECT id FROM transfers WHERE bank_account_id = 31 AND amount_cents = 100").Scan(&id))
+	tx, err = s.db.Begin()
+	s.Require().NoError(err)
+	t, err := s.repo.Get(s.ctx, tx, id)
+	s.Require().NoError(err)
+	s.Require().NoError(t.Return("AC04", time.Now()))
+	s.Require().NoError(s.repo.UpdateStatus(s.ctx, tx, t))
+	s.Require().NoError(s.repo.ChainReturn(s.ctx, tx, t, 800))
+	s.Require().NoError(tx.Commit())
+
+	// links returns the links of the account with the transfers they record
+	links := func() ([]Link, []*Transfer) {
+		tx, err := s.db.BeginTx(s.ctx, &sql.TxOptions{ReadOnly: true})
+		s.Require().NoError(err)
+		defer tx.Rollback()
+		var links []Link
+		var transfers []*Transfer
+		s.Require().NoError(s.repo.ForEachLink(s.ctx, tx, 31, func(l Link, t *Transfer) error {
+			links = append(links, l)
+			transfers = append(transfers, t)
+			return nil
+		}))
+		return links, transfers
+	}
+
+	s.Run("Debits and credits are chained", func() {
+		links, transfers := links()
+		s.Require().Len(links, 3)
+		prev := GenesisHash
+		for i, l := range links {
+			s.Equal(int64(i+1), l.Seq)
+			s.Equal(prev, l.PrevHash)
+			s.Require().NotNil(transfers[i])
+			s.Equal(l.Hash, l.ComputeHash(transfers[i]))
+			prev = l.Hash
+		}
+		s.Equal([]LinkKind{LinkDebit, LinkDebit, LinkCredit}, []LinkKind{links[0].Kind, links[1].Kind, links[2].Kind})
+		s.Equal([]int64{900, 700, 800}, []int64{links[0].BalanceCents, links[1].BalanceCents, links[2].BalanceCents})
+
+		accounts, err := s.repo.ChainAccounts(s.ctx)
+		s.Require().NoError(err)
+		s.Contains(accounts, int64(31))
+	})
+
+	s.Run("Edited transfer no longer matches its hash", func() {
+		_, err := s.db.Exec("UPDATE transfers SET amount_cents = 1 WHERE bank_account_id = 31 AND amount_cents = 200")
+		s.Require().NoError(err)
+		links, transfers := links()
+		s.NotEqual(links[1].Hash, links[1].ComputeHash(transfers[1]))
+	})
+
+	s.Run("Transfer inserted directly is not chained", func() {
+		var unchained int64
+		err := s.db.QueryRow(`INSERT INTO transfers (counterparty_name, counterparty_iban, counterparty_bic, amount_cents, bank_account_id)
+			VALUES ('Direct', 'IBAN3', 'BIC3', 300, 31) RETURNING id`).Scan(&unchained)
+		s.Require().NoError(err)
+
+		tx, err := s.db.Begin()
+		s.Require().NoError(err)
+		defer tx.Rollback()
+		first, err := s.repo.FirstUnchained(s.ctx, tx, 31)
+		s.Require().NoError(err)
+		s.Equal(unchained, first)
+	})
+
+	s.Run("Transfer of an account without links is not chained", func() {
+		var unchained int64
+		err := s.db.QueryRow(`INSERT INTO transfers (counterparty_name, counterparty_iban, counterparty_bic, amount_cents, bank_account_id)
+			VALUES ('Direct', 'IBAN4', 'BIC4', 400, 34) RETURNING id`).Scan(&unchained)
+		s.Require().NoError(err)
+
+		accounts, err := s.repo.ChainAccounts(s.ctx)
+		s.Require().NoError(err)
+		s.Contains(accounts, int64(34))
+
+		tx, err := s.db.Begin()
+		s.Require().NoError(err)
+		defer tx.Rollback()
+		first, err := s.repo.FirstUnchained(s.ctx, tx, 34)
+		s.Require().NoError(err)
+		s.Equal(unchained, first)
+	})
+
+	s.Run("Transfers of several accounts", func() {
+		tx, err := s.db.Begin()
+		s.Require().NoError(err)
+		defer tx.Rollback()
+		err = s.repo.CreateBulkTransfers(s.ctx, tx, []Transfer{
+			{CounterpartyName: "A", CounterpartyIBAN: "IBAN1", CounterpartyBIC: "BIC1", AmountCents: 1, BankAccountID: 32},
+			{CounterpartyName: "B", CounterpartyIBAN: "IBAN1", CounterpartyBIC: "BIC1", AmountCents: 1, BankAccountID: 33},
+		}, 10)
+		s.ErrorIs(err, ErrMixedAccounts)
+	})
+}
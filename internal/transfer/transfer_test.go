@@ -2,6 +2,7 @@ package transfer
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -100,3 +101,17 @@ func TestTransfer_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestTransfer_Return(t *testing.T) {
+	transfer := NewTransfer("John Doe", "DE89370400440532013000", "DEUTDEFF", 10000, 1, "Test transfer")
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	err := transfer.Return("AC04", at)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusReturned, transfer.Status)
+	assert.Equal(t, "AC04", transfer.ReturnReason)
+	assert.Equal(t, at, transfer.ReturnedAt)
+
+	err = transfer.Return("AC04", at)
+	assert.ErrorIs(t, err, ErrNotReturnable)
+}
@@ -6,16 +6,64 @@ import (
 	"time"
 )
 
+// Status is the state of a transfer
+type Status string
+
+const (
+	// StatusExecuted is the state of a transfer that was debited from its bank account
+	StatusExecuted Status = "executed"
+	// StatusReturned is the state of a transfer that was returned by the receiving bank
+	// and credited back to its bank account
+	StatusReturned Status = "returned"
+)
+
+// ErrNotReturnable is returned when a transfer that is not executed is returned
+var ErrNotReturnable = errors.New("only executed transfers can be returned")
+
 type Transfer struct {
 	// ID is the unique identifier for the transfer
 	// it is generated by the database
-	ID               int64
-	CounterpartyName string
-	CounterpartyIBAN string
-	CounterpartyBIC  string
-	AmountCents      int64
-	BankAccountID    int64
-	Description      string
+	ID               int64  `json:"id"`
+	CounterpartyName string `json:"counterparty_name"`
+	CounterpartyIBAN string `json:"counterparty_iban"`
+	CounterpartyBIC  string `json:"counterparty_bic"`
+	AmountCents      int64  `json:"amount_cents"`
+	BankAccountID    int64  `json:"bank_account_id"`
+	Description      string `json:"description"`
+	// EndToEndID is the reference the debtor gave the transfer, it is passed on to the creditor
+	EndToEndID string `json:"end_to_end_id,omitempty"`
+	// PaymentInformationID is the payment information block of the message the transfer
+	// was imported from, empty if it was not imported from a payment message
+	PaymentInformationID string `json:"payment_information_id,omitempty"`
+	// BatchID is the bulk transfer request the transfer was created by, zero if none
+	BatchID int64  `json:"batch_id,omitempty"`
+	Status  Status `json:"status"`
+	// ReturnedAt and ReturnReason are only set for returned transfers
+	ReturnedAt   time.Time `json:"returned_at"`
+	ReturnReason string    `json:"return_reason,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Movement is a change of the balance of a bank account made by a transfer: the debit
+// of the transfer when it was executed, or its credit back once it was returned
+type Movement struct {
+	Transfer Transfer
+	// Credit is set for the credit of a returned transfer, the movement is a debit otherwise
+	Credit   bool
+	BookedAt time.Time
+}
+
+// MovementSummary counts and sums the debits and credits of a bank account over a period
+type MovementSummary struct {
+	Debits      int
+	DebitCents  int64
+	Credits     int
+	CreditCents int64
+}
+
+// NetCents returns the change of the balance over the period
+func (s MovementSummary) NetCents() int64 {
+	return s.CreditCents - s.DebitCents
 }
 
 func NewTransfer(counterpartyName, counterpartyIBAN, counterpartyBIC string, amountCents, bankAccountID int64, description string) *Transfer {
@@ -32,7 +80,27 @@ func NewTransfer(counterpartyName, counterpartyIBAN, counterpartyBIC string, amo
 		AmountCents:      amountCents,
 		BankAccountID:    bankAccountID,
 		Description:      description,
+		Status:           StatusExecuted,
+	}
+}
+
+// status returns the status of the transfer, transfers created without one are executed
+func (t *Transfer) status() Status {
+	if t.Status == "" {
+		return StatusExecuted
 	}
+	return t.Status
+}
+
+// Return marks the transfer returned for the given reason
+func (t *Transfer) Return(reason string, at time.Time) error {
+	if t.status() != StatusExecuted {
+		return ErrNotReturnable
+	}
+	t.Status = StatusReturned
+	t.ReturnReason = reason
+	t.ReturnedAt = at
+	return nil
 }
 
 func (t *Transfer) Validate() error {
@@ -0,0 +1,16 @@
+// Package webhook provides durable delivery of events to the HTTP endpoints of organizations.
+//
+// Organizations register endpoints for the event types they want to be notified of.
+// The worker turns committed events into deliveries, one per subscribed endpoint, and
+// posts them with a signature computed from the secret of the endpoint. Failed deliveries
+// are retried with an exponential backoff until the maximum number of attempts, after
+// which they are dead and only delivered again on request.
+//
+// Key components:
+//   - Endpoint: Struct representing a registered webhook URL
+//   - Delivery: Struct representing an event to be delivered to an endpoint
+//   - Attempt: Struct representing the outcome of a delivery attempt
+//   - Repository: Interface to store endpoints, deliveries and attempts
+//   - Worker: Enqueues and delivers webhooks in the background
+//   - URLPolicy: Restricts the endpoints to public addresses and builds the client of the deliveries
+package webhook
@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"moneytransfer/internal/event"
+)
+
+var (
+	// ErrEndpointNotFound is returned when the requested endpoint does not exist
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+	// ErrDeliveryNotFound is returned when the requested delivery does not exist
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source=repository.go -destination=../../mock/webhook_repository_mock.go -package=mock -mock_names=Repository=WebhookRepositoryMock
+type Repository interface {
+	CreateEndpoint(ctx context.Context, e *Endpoint) error
+	GetEndpoint(ctx context.Context, id int64) (*Endpoint, error)
+	ListEndpoints(ctx context.Context, organizationName string) ([]Endpoint, error)
+	// DisableEndpoint disables the endpoint of the organization, endpoints of other
+	// organizations are not found
+	DisableEndpoint(ctx context.Context, organizationName string, id int64) error
+
+	// Cursor returns the sequence of the last event deliveries were enqueued for
+	Cursor(ctx context.Context) (int64, error)
+	// Enqueue creates a delivery of each event for every active endpoint subscribed to it
+	// and moves the cursor past the events, in one transaction. Events at or before the
+	// cursor are skipped, so that an event is never enqueued twice by concurrent workers.
+	// It returns the number of deliveries created.
+	Enqueue(ctx context.Context, events []event.Event) (int, error)
+
+	// ClaimDue returns up to limit pending deliveries that are due and postpones their
+	// next attempt by lease, so that other workers do not attempt them in the meantime
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	// RecordAttempt stores the attempt and the state of its delivery after the attempt
+	RecordAttempt(ctx context.Context, d *Delivery, a *Attempt) error
+	CreateDelivery(ctx context.Context, d *Delivery) error
+	GetDelivery(ctx context.Context, id int64) (*Delivery, error)
+	// ListDeliveries returns the latest deliveries to the endpoint of the organization, newest
+	// first, with their attempts. Nothing is returned for the endpoints of other organizations.
+	ListDeliveries(ctx context.Context, organizationName string, endpointID int64, limit int) ([]Delivery, error)
+}
@@ -0,0 +1,344 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"moneytransfer/internal/event"
+
+	"github.com/lib/pq"
+)
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &postgresRepository{db: db}
+}
+
+const deliveryColumns = `id, endpoint_id, event_seq, event_type, payload, status, attempts, next_attempt_at,
+	COALESCE(redelivery_of, 0), created_at, updated_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDelivery(row rowScanner) (*Delivery, error) {
+	var d Delivery
+	var payload []byte
+	err := row.Scan(
+		&d.ID,
+		&d.EndpointID,
+		&d.EventSeq,
+		&d.EventType,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.RedeliveryOf,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	return &d, nil
+}
+
+func scanEndpoint(row rowScanner) (*Endpoint, error) {
+	var e Endpoint
+	var eventTypes pq.StringArray
+	err := row.Scan(&e.ID, &e.OrganizationName, &e.URL, &e.Secret, &eventTypes, &e.Active, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range eventTypes {
+		e.EventTypes = append(e.EventTypes, event.Type(t))
+	}
+	return &e, nil
+}
+
+func (r *postgresRepository) CreateEndpoint(ctx context.Context, e *Endpoint) error {
+	query := `
+		INSERT INTO webhook_endpoints (organization_name, url, secret, event_types, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	eventTypes := make([]string, len(e.EventTypes))
+	for i, t := range e.EventTypes {
+		eventTypes[i] = string(t)
+	}
+
+	err := r.db.QueryRowContext(ctx, query, e.OrganizationName, e.URL, e.Secret, pq.Array(eventTypes), e.Active).
+		Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) GetEndpoint(ctx context.Context, id int64) (*Endpoint, error) {
+	query := `
+		SELECT id, organization_name, url, secret, event_types, active, created_at
+		FROM webhook_endpoints
+		WHERE id = $1
+	`
+	e, err := scanEndpoint(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEndpointNotFound
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *postgresRepository) ListEndpoints(ctx context.Context, organizationName string) ([]Endpoint, error) {
+	query := `
+		SELECT id, organization_name, url, secret, event_types, active, created_at
+		FROM webhook_endpoints
+		WHERE organization_name = $1
+		ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, organizationName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []Endpoint
+	for rows.Next() {
+		e, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, *e)
+	}
+	return endpoints, rows.Err()
+}
+
+func (r *postgresRepository) DisableEndpoint(ctx context.Context, organizationName string, id int64) error {
+	query := `UPDATE webhook_endpoints SET active = FALSE WHERE id = $1 AND organization_name = $2`
+	res, err := r.db.ExecContext(ctx, query, id, organizationName)
+	if err != nil {
+		return fmt.Errorf("failed to disable webhook endpoint: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrEndpointNotFound
+	}
+	return nil
+}
+
+func (r *postgresRepository) Cursor(ctx context.Context) (int64, error) {
+	var cursor int64
+	err := r.db.QueryRowContext(ctx, `SELECT last_seq FROM webhook_cursor WHERE id = 1`).Scan(&cursor)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read webhook cursor: %w", err)
+	}
+	return cursor, nil
+}
+
+func (r *postgresRepository) Enqueue(ctx context.Context, events []event.Event) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// The cursor row is locked so that concurrent workers enqueue one after the other
+	var cursor int64
+	err = tx.QueryRowContext(ctx, `SELECT last_seq FROM webhook_cursor WHERE id = 1 FOR UPDATE`).Scan(&cursor)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock webhook cursor: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_seq, event_type, payload, status)
+		SELECT id, $1, $2, $3, $4
+		FROM webhook_endpoints
+		WHERE active AND organization_name = $5 AND $2 = ANY(event_types)
+	`
+	var created int
+	for _, e := range events {
+		if e.Seq <= cursor {
+			continue
+		}
+		cursor = e.Seq
+		if !isEventType(e.Type) {
+			continue
+		}
+
+		payload, err := NewPayload(e)
+		if err != nil {
+			return 0, err
+		}
+		res, err := tx.ExecContext(ctx, query, e.Seq, string(e.Type), payload, DeliveryPending, e.OrganizationName)
+		if err != nil {
+			return 0, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		created += int(n)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE webhook_cursor SET last_seq = $1 WHERE id = 1`, cursor); err != nil {
+		return 0, fmt.Errorf("failed to update webhook cursor: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+func (r *postgresRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = now() + $2 * interval '1 millisecond', updated_at = now()
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $3 AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds(), DeliveryPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, *d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *postgresRepository) RecordAttempt(ctx context.Context, d *Delivery, a *Attempt) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, a.DeliveryID, a.Attempt, a.StatusCode, a.Error, a.Duration.Milliseconds()).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert webhook attempt: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, updated_at = now()
+		WHERE id = $4
+		RETURNING updated_at
+	`, d.Status, d.Attempts, d.NextAttemptAt, d.ID).Scan(&d.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *postgresRepository) CreateDelivery(ctx context.Context, d *Delivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_seq, event_type, payload, status, redelivery_of)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0))
+		RETURNING id, attempts, next_attempt_at, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, d.EndpointID, d.EventSeq, d.EventType, []byte(d.Payload), d.Status, d.RedeliveryOf).
+		Scan(&d.ID, &d.Attempts, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) GetDelivery(ctx context.Context, id int64) (*Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+	d, err := scanDelivery(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *postgresRepository) ListDeliveries(ctx context.Context, organizationName string, endpointID int64, limit int) ([]Delivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE endpoint_id = $1
+		AND endpoint_id IN (SELECT id FROM webhook_endpoints WHERE organization_name = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, endpointID, organizationName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	index := make(map[int64]int)
+	ids := make([]int64, 0, limit)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		index[d.ID] = len(deliveries)
+		ids = append(ids, d.ID)
+		deliveries = append(deliveries, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	attemptRows, err := r.db.QueryContext(ctx, `
+		SELECT id, delivery_id, attempt, status_code, error, duration_ms, created_at
+		FROM webhook_attempts
+		WHERE delivery_id = ANY($1)
+		ORDER BY delivery_id, attempt
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook attempts: %w", err)
+	}
+	defer attemptRows.Close()
+
+	for attemptRows.Next() {
+		var a Attempt
+		var durationMillis int64
+		if err := attemptRows.Scan(&a.ID, &a.DeliveryID, &a.Attempt, &a.StatusCode, &a.Error, &durationMillis, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook attempt: %w", err)
+		}
+		a.Duration = time.Duration(durationMillis) * time.Millisecond
+		i := index[a.DeliveryID]
+		deliveries[i].AttemptLog = append(deliveries[i].AttemptLog, a)
+	}
+	return deliveries, attemptRows.Err()
+}
@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// dialTimeout is the maximum duration of the connection to an endpoint
+const dialTimeout = 10 * time.Second
+
+// ErrNonPublicAddress is returned when the host of an endpoint is, or resolves to, an address
+// that is not publicly routable, such as a loopback, private or link-local address
+var ErrNonPublicAddress = errors.New("address is not public")
+
+// nonPublicPrefixes are the special-purpose ranges that are not covered by the netip.Addr predicates
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // shared address space of carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, including the broadcast address
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, which embeds IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("2002::/16"),       // 6to4, which embeds IPv4 addresses
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/32"),       // Teredo, which embeds IPv4 addresses
+	netip.MustParsePrefix("fec0::/10"),       // deprecated site-local
+	netip.MustParsePrefix("::ffff:0:0:0/96"), // IPv4-translated
+}
+
+// isPublic reports whether the address is publicly routable
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// URLPolicy restricts the URLs deliveries are posted to, so that the endpoints registered by
+// organizations cannot make the server reach its own network or the metadata services of its host
+type URLPolicy struct {
+	// AllowHTTP accepts http URLs, only https URLs are accepted otherwise
+	AllowHTTP bool
+	// LookupHost returns the addresses of the host of a URL, those of net.DefaultResolver when nil
+	LookupHost func(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+// Check returns an error unless the URL uses an accepted scheme and its host only resolves to
+// public addresses. The addresses are checked again when deliveries connect, since the host may
+// resolve to other addresses by then.
+func (p URLPolicy) Check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return errors.New("URL must be an absolute URL")
+	}
+	switch {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && p.AllowHTTP:
+	case p.AllowHTTP:
+		return errors.New("URL must be an http or https URL")
+	default:
+		return errors.New("URL must be an https URL")
+	}
+
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !isPublic(addr) {
+			return fmt.Errorf("host %s: %w", host, ErrNonPublicAddress)
+		}
+		return nil
+	}
+	addrs, err := p.lookupHost(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("host %s cannot be resolved", host)
+	}
+	for _, addr := range addrs {
+		if !isPublic(addr) {
+			return fmt.Errorf("host %s: %w", host, ErrNonPublicAddress)
+		}
+	}
+	return nil
+}
+
+func (p URLPolicy) lookupHost(ctx context.Context, host string) ([]netip.Addr, error) {
+	if p.LookupHost != nil {
+		return p.LookupHost(ctx, host)
+	}
+	return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+}
+
+// Client returns the HTTP client of the deliveries. It only connects to public addresses, whatever
+// the host of the URL resolves to at the time, does not go through the proxies of the environment
+// and does not follow redirects: the redirect is the response of the attempt.
+func (p URLPolicy) Client() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: dialTimeout,
+		// Control is called with the resolved address of every connection attempt
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("address %s: %w", address, ErrNonPublicAddress)
+			}
+			if !isPublic(addrPort.Addr()) {
+				return fmt.Errorf("address %s: %w", addrPort.Addr(), ErrNonPublicAddress)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublic(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::1":     true,
+		"127.0.0.1":              false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"100.64.0.1":             false,
+		"0.0.0.0":                false,
+		"255.255.255.255":        false,
+		"224.0.0.1":              false,
+		"::1":                    false,
+		"::":                     false,
+		"fd00::1":                false,
+		"fe80::1":                false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+		"64:ff9b::a00:1":         false,
+	} {
+		assert.Equal(t, want, isPublic(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestURLPolicy_Check(t *testing.T) {
+	lookup := func(_ context.Context, host string) ([]netip.Addr, error) {
+		switch host {
+		case "erp.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+		case "rebind.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("127.0.0.1")}, nil
+		default:
+			return nil, errors.New("no such host")
+		}
+	}
+	policy := URLPolicy{LookupHost: lookup}
+	httpPolicy := URLPolicy{AllowHTTP: true, LookupHost: lookup}
+
+	tests := []struct {
+		name    string
+		policy  URLPolicy
+		url     string
+		wantErr string
+	}{
+		{name: "Public host", policy: policy, url: "https://erp.example.com/hooks"},
+		{name: "Public address", policy: policy, url: "https://93.184.216.34:8443/hooks"},
+		{name: "http URL allowed", policy: httpPolicy, url: "http://erp.example.com/hooks"},
+		{name: "http URL", policy: policy, url: "http://erp.example.com/hooks", wantErr: "URL must be an https URL"},
+		{name: "Other scheme", policy: httpPolicy, url: "ftp://erp.example.com/hooks", wantErr: "URL must be an http or https URL"},
+		{name: "Relative URL", policy: policy, url: "/hooks", wantErr: "URL must be an absolute URL"},
+		{name: "Loopback address", policy: httpPolicy, url: "http://127.0.0.1:9000/hooks", wantErr: "address is not public"},
+		{name: "IPv6 loopback address", policy: policy, url: "https://[::1]/hooks", wantErr: "address is not public"},
+		{name: "Metadata service", policy: httpPolicy, url: "http://169.254.169.254/latest/meta-data", wantErr: "address is not public"},
+		{name: "Host with a private address", policy: policy, url: "https://rebind.example.com/hooks", wantErr: "address is not public"},
+		{name: "Unknown host", policy: policy, url: "https://unknown.example.com/hooks", wantErr: "cannot be resolved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(context.Background(), tt.url)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestURLPolicy_Client(t *testing.T) {
+	t.Run("Connections to non-public addresses are refused", func(t *testing.T) {
+		var called bool
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		client := URLPolicy{AllowHTTP: true}.Client()
+		_, err := client.Post(server.URL, "application/json", nil)
+		assert.ErrorIs(t, err, ErrNonPublicAddress)
+		assert.False(t, called)
+	})
+
+	t.Run("Redirects are not followed", func(t *testing.T) {
+		var redirected bool
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/internal" {
+				redirected = true
+				return
+			}
+			http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+		}))
+		defer server.Close()
+
+		// The transport of the test server reaches its loopback address
+		client := URLPolicy{}.Client()
+		client.Transport = server.Client().Transport
+
+		resp, err := client.Post(server.URL+"/hooks", "application/json", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+		assert.False(t, redirected)
+	})
+}
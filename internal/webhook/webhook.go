@@ -0,0 +1,191 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"moneytransfer/internal/event"
+)
+
+const (
+	// SignatureHeader carries the signature of a delivery, see Sign
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader carries the time a delivery was signed, in Unix seconds
+	TimestampHeader = "X-Webhook-Timestamp"
+	// EventHeader carries the event type of a delivery
+	EventHeader = "X-Webhook-Event"
+	// DeliveryHeader carries the ID of a delivery, it is the same for every attempt
+	DeliveryHeader = "X-Webhook-Delivery"
+
+	// signatureVersion prefixes signatures so that the scheme can evolve
+	signatureVersion = "v1"
+)
+
+// EventTypes lists the event types endpoints can subscribe to
+var EventTypes = []event.Type{
+	event.TypeBatchExecuted,
+	event.TypeBatchFailed,
+	event.TypeTransferReturned,
+}
+
+type Endpoint struct {
+	// ID is the unique identifier for the endpoint
+	// it is generated by the database
+	ID               int64
+	OrganizationName string
+	URL              string
+	// Secret is the key of the signatures of the deliveries to the endpoint
+	Secret     string
+	EventTypes []event.Type
+	Active     bool
+	CreatedAt  time.Time
+}
+
+// NewEndpoint returns an active endpoint with a new random secret
+func NewEndpoint(organizationName, rawURL string, eventTypes []event.Type) (*Endpoint, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	return &Endpoint{
+		OrganizationName: organizationName,
+		URL:              rawURL,
+		Secret:           "whsec_" + hex.EncodeToString(secret),
+		EventTypes:       eventTypes,
+		Active:           true,
+	}, nil
+}
+
+func (e *Endpoint) Validate() error {
+	if e.OrganizationName == "" {
+		return errors.New("organization name is required")
+	}
+	u, err := url.Parse(e.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("URL must be an absolute http or https URL")
+	}
+	if len(e.EventTypes) == 0 {
+		return errors.New("at least one event type is required")
+	}
+	for _, t := range e.EventTypes {
+		if !isEventType(t) {
+			return fmt.Errorf("unsupported event type %q", t)
+		}
+	}
+	return nil
+}
+
+func isEventType(t event.Type) bool {
+	for _, et := range EventTypes {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus is the state of a delivery
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is the state of a delivery that is yet to be attempted or retried
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySucceeded is the state of a delivery the endpoint acknowledged with a 2xx response
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryDead is the state of a delivery that failed the maximum number of attempts
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+type Delivery struct {
+	// ID is the unique identifier for the delivery
+	// it is generated by the database
+	ID         int64
+	EndpointID int64
+	EventSeq   int64
+	EventType  event.Type
+	// Payload is the request body posted to the endpoint
+	Payload       json.RawMessage
+	Status        DeliveryStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	// RedeliveryOf is the delivery this one was created to deliver again, zero if none
+	RedeliveryOf int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	// AttemptLog holds the attempts of the delivery when they were loaded with it
+	AttemptLog []Attempt
+}
+
+// Redelivery returns a new pending delivery of the same event to the same endpoint
+func (d *Delivery) Redelivery() *Delivery {
+	return &Delivery{
+		EndpointID:   d.EndpointID,
+		EventSeq:     d.EventSeq,
+		EventType:    d.EventType,
+		Payload:      d.Payload,
+		Status:       DeliveryPending,
+		RedeliveryOf: d.ID,
+	}
+}
+
+type Attempt struct {
+	// ID is the unique identifier for the attempt
+	// it is generated by the database
+	ID         int64
+	DeliveryID int64
+	// Attempt is the 1-based number of the attempt of its delivery
+	Attempt int
+	// StatusCode is the HTTP status returned by the endpoint, zero if there was no response
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	// ID is the sequence of the event, receivers use it to discard duplicates
+	ID               int64           `json:"id"`
+	Type             event.Type      `json:"type"`
+	OrganizationName string          `json:"organization_name"`
+	OrganizationIBAN string          `json:"organization_iban"`
+	CreatedAt        time.Time       `json:"created_at"`
+	Data             json.RawMessage `json:"data"`
+}
+
+// NewPayload returns the body of the deliveries of e
+func NewPayload(e event.Event) ([]byte, error) {
+	return json.Marshal(Payload{
+		ID:               e.Seq,
+		Type:             e.Type,
+		OrganizationName: e.OrganizationName,
+		OrganizationIBAN: e.OrganizationIBAN,
+		CreatedAt:        e.CreatedAt,
+		Data:             e.Data,
+	})
+}
+
+// Sign returns the signature of body sent at timestamp: the hex encoded HMAC-SHA256,
+// keyed with secret, of the Unix timestamp in seconds, a dot and the body.
+// Covering the timestamp lets receivers reject replayed deliveries.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body sent at timestamp
+func Verify(secret string, timestamp time.Time, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/event"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEndpoint(t *testing.T) {
+	e, err := NewEndpoint("Test Org", "https://erp.example.com/hooks", []event.Type{event.TypeBatchExecuted})
+
+	assert.NoError(t, err)
+	assert.True(t, e.Active)
+	assert.True(t, strings.HasPrefix(e.Secret, "whsec_"))
+	assert.NoError(t, e.Validate())
+
+	other, err := NewEndpoint("Test Org", "https://erp.example.com/hooks", []event.Type{event.TypeBatchExecuted})
+	assert.NoError(t, err)
+	assert.NotEqual(t, e.Secret, other.Secret)
+}
+
+func TestEndpoint_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		wantErr  string
+	}{
+		{
+			name:     "Valid endpoint",
+			endpoint: Endpoint{OrganizationName: "Test Org", URL: "http://localhost:9000/hooks", EventTypes: EventTypes},
+		},
+		{
+			name:     "Missing organization",
+			endpoint: Endpoint{URL: "https://erp.example.com", EventTypes: EventTypes},
+			wantErr:  "organization name is required",
+		},
+		{
+			name:     "Relative URL",
+			endpoint: Endpoint{OrganizationName: "Test Org", URL: "/hooks", EventTypes: EventTypes},
+			wantErr:  "URL must be an absolute http or https URL",
+		},
+		{
+			name:     "Unsupported scheme",
+			endpoint: Endpoint{OrganizationName: "Test Org", URL: "ftp://erp.example.com", EventTypes: EventTypes},
+			wantErr:  "URL must be an absolute http or https URL",
+		},
+		{
+			name:     "No event types",
+			endpoint: Endpoint{OrganizationName: "Test Org", URL: "https://erp.example.com"},
+			wantErr:  "at least one event type is required",
+		},
+		{
+			name:     "Unsupported event type",
+			endpoint: Endpoint{OrganizationName: "Test Org", URL: "https://erp.example.com", EventTypes: []event.Type{event.TypeBatchReceived}},
+			wantErr:  `unsupported event type "batch.received"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.endpoint.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"id":1}`)
+
+	// Computed with: printf '1700000000.{"id":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "v1=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11", Sign("secret", timestamp, body))
+
+	signature := Sign("secret", timestamp, body)
+	assert.True(t, Verify("secret", timestamp, body, signature))
+	assert.False(t, Verify("other", timestamp, body, signature))
+	assert.False(t, Verify("secret", timestamp.Add(time.Second), body, signature))
+	assert.False(t, Verify("secret", timestamp, []byte(`{"id":2}`), signature))
+}
+
+func TestNewPayload(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	payload, err := NewPayload(event.Event{
+		Seq:              9,
+		Type:             event.TypeBatchExecuted,
+		OrganizationName: "Test Org",
+		OrganizationIBAN: "TEST123456789",
+		BatchID:          42,
+		Data:             json.RawMessage(`{"id":42}`),
+		CreatedAt:        createdAt,
+	})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 9,
+		"type": "batch.executed",
+		"organization_name": "Test Org",
+		"organization_iban": "TEST123456789",
+		"created_at": "2024-05-01T12:00:00Z",
+		"data": {"id": 42}
+	}`, string(payload))
+}
+
+func TestDelivery_Redelivery(t *testing.T) {
+	d := Delivery{ID: 3, EndpointID: 1, EventSeq: 9, EventType: event.TypeBatchFailed, Payload: json.RawMessage(`{}`), Status: DeliveryDead, Attempts: 5}
+
+	r := d.Redelivery()
+	assert.Equal(t, int64(0), r.ID)
+	assert.Equal(t, int64(3), r.RedeliveryOf)
+	assert.Equal(t, DeliveryPending, r.Status)
+	assert.Equal(t, 0, r.Attempts)
+	assert.Equal(t, d.Payload, r.Payload)
+}
@@ -0,0 +1,219 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"moneytransfer/internal/event"
+	"moneytransfer/internal/tools"
+)
+
+const (
+	// eventPageSize is the maximum number of events enqueued at once
+	eventPageSize = 500
+	// maxResponseBytes is the part of a response body read before the connection is reused
+	maxResponseBytes = 4 << 10
+	// maxErrorLength is the maximum length of the error stored with an attempt
+	maxErrorLength = 1000
+)
+
+// WorkerConfig is a struct that contains the delivery configuration of the worker
+type WorkerConfig struct {
+	// MaxAttempts is the number of attempts after which a delivery is dead
+	MaxAttempts int
+	// BaseDelay and MaxDelay bound the backoff between attempts
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// PollInterval is the interval at which the worker looks for new events and due deliveries
+	PollInterval time.Duration
+	// Timeout is the maximum duration of an attempt
+	Timeout time.Duration
+	// BatchSize is the maximum number of deliveries attempted at once
+	BatchSize int
+}
+
+// Worker enqueues the deliveries of committed events and delivers them
+type Worker struct {
+	repo     Repository
+	events   event.Repository
+	client   *http.Client
+	logger   *slog.Logger
+	config   WorkerConfig
+	now      func() time.Time
+	notifier <-chan struct{}
+}
+
+// NewWorker is a function that creates a new webhook worker
+// notify wakes the worker up before the next poll when new events may have been committed, it may be nil
+func NewWorker(repo Repository, events event.Repository, client *http.Client, logger *slog.Logger, config WorkerConfig, notify <-chan struct{}) *Worker {
+	return &Worker{
+		repo:     repo,
+		events:   events,
+		client:   client,
+		logger:   logger.With("component", "webhook_worker"),
+		config:   config,
+		now:      time.Now,
+		notifier: notify,
+	}
+}
+
+// Run enqueues and delivers webhooks until ctx is canceled
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("Webhook worker started")
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("Failed to process webhooks", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Webhook worker stopped")
+			return
+		case <-ticker.C:
+		case <-w.notifier:
+		}
+	}
+}
+
+// RunOnce enqueues the deliveries of the events committed since the last run
+// and attempts the deliveries that are due
+func (w *Worker) RunOnce(ctx context.Context) error {
+	if err := w.enqueue(ctx); err != nil {
+		return err
+	}
+	return w.deliverDue(ctx)
+}
+
+func (w *Worker) enqueue(ctx context.Context) error {
+	for {
+		cursor, err := w.repo.Cursor(ctx)
+		if err != nil {
+			return err
+		}
+
+		events, err := w.events.ListAfter(ctx, event.Filter{}, cursor, eventPageSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		created, err := w.repo.Enqueue(ctx, events)
+		if err != nil {
+			return err
+		}
+		if created > 0 {
+			w.logger.Debug("Webhook deliveries enqueued", "deliveries", created, "last_event_seq", events[len(events)-1].Seq)
+		}
+		if len(events) < eventPageSize {
+			return nil
+		}
+	}
+}
+
+func (w *Worker) deliverDue(ctx context.Context) error {
+	// A claimed delivery is not attempted by another worker until the lease expires,
+	// which leaves enough time to attempt every delivery of the batch
+	lease := w.config.Timeout*time.Duration(w.config.BatchSize) + w.config.PollInterval
+
+	deliveries, err := w.repo.ClaimDue(ctx, w.config.BatchSize, lease)
+	if err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		if err := w.deliver(ctx, &deliveries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver attempts the delivery and records the outcome
+func (w *Worker) deliver(ctx context.Context, d *Delivery) error {
+	logger := w.logger.With("delivery_id", d.ID, "endpoint_id", d.EndpointID, "event_type", d.EventType)
+
+	attempt := &Attempt{DeliveryID: d.ID, Attempt: d.Attempts + 1}
+
+	endpoint, err := w.repo.GetEndpoint(ctx, d.EndpointID)
+	if err != nil {
+		return err
+	}
+
+	start := w.now()
+	if endpoint.Active {
+		attempt.StatusCode, err = w.post(ctx, endpoint, d)
+	} else {
+		err = errors.New("endpoint is disabled")
+	}
+	attempt.Duration = w.now().Sub(start)
+
+	d.Attempts++
+	switch {
+	case err == nil:
+		d.Status = DeliverySucceeded
+		logger.Info("Webhook delivered", "attempt", d.Attempts, "status_code", attempt.StatusCode)
+	case !endpoint.Active || d.Attempts >= w.config.MaxAttempts:
+		d.Status = DeliveryDead
+		attempt.Error = truncate(err.Error(), maxErrorLength)
+		logger.Warn("Webhook delivery is dead", "attempt", d.Attempts, "error", err)
+	default:
+		delay := tools.CalculateBackoff(w.config.BaseDelay, w.config.MaxDelay, d.Attempts-1)
+		d.NextAttemptAt = w.now().Add(delay)
+		attempt.Error = truncate(err.Error(), maxErrorLength)
+		logger.Warn("Webhook delivery failed, retrying", "attempt", d.Attempts, "error", err, "retry_after", delay)
+	}
+
+	// The outcome is recorded even if ctx was canceled during the attempt
+	return w.repo.RecordAttempt(context.WithoutCancel(ctx), d, attempt)
+}
+
+// post sends the delivery to the endpoint and returns the response status.
+// Any response other than 2xx is an error.
+func (w *Worker) post(ctx context.Context, endpoint *Endpoint, d *Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := w.now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "moneytransfer-webhooks/1.0")
+	req.Header.Set(EventHeader, string(d.EventType))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, timestamp, d.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,149 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"moneytransfer/internal/event"
+	"moneytransfer/internal/webhook"
+	"moneytransfer/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWorker_RunOnce(t *testing.T) {
+	config := webhook.WorkerConfig{
+		MaxAttempts:  3,
+		BaseDelay:    time.Second,
+		MaxDelay:     time.Minute,
+		PollInterval: time.Second,
+		Timeout:      time.Second,
+		BatchSize:    10,
+	}
+	payload := json.RawMessage(`{"id":9,"type":"batch.executed"}`)
+
+	// setup returns a worker whose repository has delivery d due and no new events
+	setup := func(t *testing.T, url string, active bool, d webhook.Delivery) (*mock.WebhookRepositoryMock, *webhook.Worker) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mock.NewWebhookRepositoryMock(ctrl)
+		mockEvents := mock.NewEventRepositoryMock(ctrl)
+
+		mockRepo.EXPECT().Cursor(gomock.Any()).Return(int64(9), nil)
+		mockEvents.EXPECT().ListAfter(gomock.Any(), event.Filter{}, int64(9), gomock.Any()).Return(nil, nil)
+		mockRepo.EXPECT().ClaimDue(gomock.Any(), 10, gomock.Any()).Return([]webhook.Delivery{d}, nil)
+		mockRepo.EXPECT().GetEndpoint(gomock.Any(), int64(1)).Return(&webhook.Endpoint{
+			ID:     1,
+			URL:    url,
+			Secret: "whsec_test",
+			Active: active,
+		}, nil)
+
+		return mockRepo, webhook.NewWorker(mockRepo, mockEvents, http.DefaultClient, slog.Default(), config, nil)
+	}
+
+	t.Run("Enqueues new events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mock.NewWebhookRepositoryMock(ctrl)
+		mockEvents := mock.NewEventRepositoryMock(ctrl)
+
+		events := []event.Event{{Seq: 10, Type: event.TypeBatchExecuted}, {Seq: 11, Type: event.TypeBatchReceived}}
+		mockRepo.EXPECT().Cursor(gomock.Any()).Return(int64(9), nil)
+		mockEvents.EXPECT().ListAfter(gomock.Any(), event.Filter{}, int64(9), gomock.Any()).Return(events, nil)
+		mockRepo.EXPECT().Enqueue(gomock.Any(), events).Return(1, nil)
+		mockRepo.EXPECT().ClaimDue(gomock.Any(), 10, gomock.Any()).Return(nil, nil)
+
+		worker := webhook.NewWorker(mockRepo, mockEvents, http.DefaultClient, slog.Default(), config, nil)
+		assert.NoError(t, worker.RunOnce(context.Background()))
+	})
+
+	t.Run("Delivers a signed payload", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			unix, err := strconv.ParseInt(r.Header.Get(webhook.TimestampHeader), 10, 64)
+			assert.NoError(t, err)
+			assert.True(t, webhook.Verify("whsec_test", time.Unix(unix, 0), body, r.Header.Get(webhook.SignatureHeader)))
+			assert.Equal(t, "batch.executed", r.Header.Get(webhook.EventHeader))
+			assert.Equal(t, "5", r.Header.Get(webhook.DeliveryHeader))
+			assert.JSONEq(t, string(payload), string(body))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		d := webhook.Delivery{ID: 5, EndpointID: 1, EventType: event.TypeBatchExecuted, Payload: payload, Status: webhook.DeliveryPending}
+		mockRepo, worker := setup(t, server.URL, true, d)
+		mockRepo.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, d *webhook.Delivery, a *webhook.Attempt) error {
+				assert.Equal(t, webhook.DeliverySucceeded, d.Status)
+				assert.Equal(t, 1, d.Attempts)
+				assert.Equal(t, 1, a.Attempt)
+				assert.Equal(t, http.StatusNoContent, a.StatusCode)
+				assert.Empty(t, a.Error)
+				return nil
+			})
+
+		assert.NoError(t, worker.RunOnce(context.Background()))
+	})
+
+	t.Run("Failed delivery is retried with backoff", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		d := webhook.Delivery{ID: 5, EndpointID: 1, EventType: event.TypeBatchFailed, Payload: payload, Status: webhook.DeliveryPending, Attempts: 1}
+		mockRepo, worker := setup(t, server.URL, true, d)
+		before := time.Now()
+		mockRepo.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, d *webhook.Delivery, a *webhook.Attempt) error {
+				assert.Equal(t, webhook.DeliveryPending, d.Status)
+				assert.Equal(t, 2, d.Attempts)
+				assert.Equal(t, 2, a.Attempt)
+				assert.Equal(t, http.StatusServiceUnavailable, a.StatusCode)
+				assert.Equal(t, "unexpected response status 503", a.Error)
+				// The second attempt waits at least twice the base delay
+				assert.True(t, d.NextAttemptAt.After(before.Add(2*time.Second)))
+				return nil
+			})
+
+		assert.NoError(t, worker.RunOnce(context.Background()))
+	})
+
+	t.Run("Delivery is dead after the maximum attempts", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		d := webhook.Delivery{ID: 5, EndpointID: 1, EventType: event.TypeBatchFailed, Payload: payload, Status: webhook.DeliveryPending, Attempts: 2}
+		mockRepo, worker := setup(t, server.URL, true, d)
+		mockRepo.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, d *webhook.Delivery, a *webhook.Attempt) error {
+				assert.Equal(t, webhook.DeliveryDead, d.Status)
+				assert.Equal(t, 3, d.Attempts)
+				return nil
+			})
+
+		assert.NoError(t, worker.RunOnce(context.Background()))
+	})
+
+	t.Run("Delivery to a disabled endpoint is dead", func(t *testing.T) {
+		d := webhook.Delivery{ID: 5, EndpointID: 1, EventType: event.TypeBatchFailed, Payload: payload, Status: webhook.DeliveryPending}
+		mockRepo, worker := setup(t, "http://127.0.0.1:1", false, d)
+		mockRepo.EXPECT().RecordAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, d *webhook.Delivery, a *webhook.Attempt) error {
+				assert.Equal(t, webhook.DeliveryDead, d.Status)
+				assert.Equal(t, "endpoint is disabled", a.Error)
+				return nil
+			})
+
+		assert.NoError(t, worker.RunOnce(context.Background()))
+	})
+}
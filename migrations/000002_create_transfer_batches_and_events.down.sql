@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TABLE IF EXISTS events;
+DROP INDEX IF EXISTS transfers_batch_id_idx;
+ALTER TABLE transfers DROP COLUMN IF EXISTS created_at;
+ALTER TABLE transfers DROP COLUMN IF EXISTS status;
+ALTER TABLE transfers DROP COLUMN IF EXISTS batch_id;
+DROP TABLE IF EXISTS transfer_batches;
+
+COMMIT;
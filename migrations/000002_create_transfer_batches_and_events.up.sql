@@ -0,0 +1,43 @@
+BEGIN;
+
+-- Create transfer_batches table, one row per bulk transfer request
+CREATE TABLE IF NOT EXISTS transfer_batches (
+    id BIGSERIAL PRIMARY KEY,
+    organization_name TEXT NOT NULL,
+    organization_iban TEXT NOT NULL,
+    bank_account_id BIGINT REFERENCES bank_accounts(id),
+    status TEXT NOT NULL,
+    transfer_count INTEGER NOT NULL DEFAULT 0,
+    total_cents BIGINT NOT NULL DEFAULT 0,
+    error_code TEXT NOT NULL DEFAULT '',
+    error_detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Link transfers to the batch that created them and track their state
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS batch_id BIGINT REFERENCES transfer_batches(id);
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'executed';
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+CREATE INDEX IF NOT EXISTS transfers_batch_id_idx ON transfers (batch_id);
+
+-- Create events table, the persisted sequence of state changes streamed to clients.
+-- txid records the writing transaction so that readers can skip events of
+-- transactions that are still in progress and would otherwise be missed.
+CREATE TABLE IF NOT EXISTS events (
+    seq BIGSERIAL PRIMARY KEY,
+    type TEXT NOT NULL,
+    organization_name TEXT NOT NULL,
+    organization_iban TEXT NOT NULL,
+    bank_account_id BIGINT,
+    batch_id BIGINT,
+    transfer_id BIGINT,
+    data JSONB NOT NULL DEFAULT '{}',
+    txid XID8 NOT NULL DEFAULT pg_current_xact_id(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS events_organization_name_seq_idx ON events (organization_name, seq);
+CREATE INDEX IF NOT EXISTS events_organization_iban_seq_idx ON events (organization_iban, seq);
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TABLE IF EXISTS webhook_cursor;
+DROP TABLE IF EXISTS webhook_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
+ALTER TABLE transfers DROP COLUMN IF EXISTS return_reason;
+ALTER TABLE transfers DROP COLUMN IF EXISTS returned_at;
+
+COMMIT;
@@ -0,0 +1,63 @@
+BEGIN;
+
+-- Track transfers returned by the receiving bank
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS returned_at TIMESTAMPTZ;
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS return_reason TEXT NOT NULL DEFAULT '';
+
+-- Create webhook_endpoints table, the URLs an organization receives notifications on
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id BIGSERIAL PRIMARY KEY,
+    organization_name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_endpoints_organization_name_idx ON webhook_endpoints (organization_name);
+
+-- Create webhook_deliveries table, one row per event and endpoint to be notified
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint_id BIGINT NOT NULL REFERENCES webhook_endpoints(id),
+    event_seq BIGINT NOT NULL REFERENCES events(seq),
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    redelivery_of BIGINT REFERENCES webhook_deliveries(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_endpoint_id_idx ON webhook_deliveries (endpoint_id, id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- Create webhook_attempts table, the outcome of every attempt to deliver a webhook
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    delivery_id BIGINT NOT NULL REFERENCES webhook_deliveries(id),
+    attempt INTEGER NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_attempts_delivery_id_idx ON webhook_attempts (delivery_id);
+
+-- Create webhook_cursor table, the sequence of the last event turned into deliveries.
+-- It holds a single row that is locked while deliveries are enqueued.
+CREATE TABLE IF NOT EXISTS webhook_cursor (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    last_seq BIGINT NOT NULL
+);
+
+-- Events written before webhooks existed are not delivered
+INSERT INTO webhook_cursor (id, last_seq)
+SELECT 1, COALESCE(MAX(seq), 0) FROM events
+ON CONFLICT (id) DO NOTHING;
+
+COMMIT;
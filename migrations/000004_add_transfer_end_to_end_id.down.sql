@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE transfers DROP COLUMN IF EXISTS end_to_end_id;
+
+COMMIT;
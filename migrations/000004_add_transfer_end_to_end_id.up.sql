@@ -0,0 +1,6 @@
+BEGIN;
+
+-- The end-to-end ID is set by the debtor and travels with the transfer to the creditor
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS end_to_end_id TEXT NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE transfers DROP COLUMN IF EXISTS payment_information_id;
+ALTER TABLE transfer_batches DROP COLUMN IF EXISTS message_name;
+ALTER TABLE transfer_batches DROP COLUMN IF EXISTS message_id;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+-- The identification and name of the message a batch was imported from, referenced by its status reports
+ALTER TABLE transfer_batches ADD COLUMN IF NOT EXISTS message_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE transfer_batches ADD COLUMN IF NOT EXISTS message_name TEXT NOT NULL DEFAULT '';
+
+-- The payment information block of the message a transfer was imported from
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS payment_information_id TEXT NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS transfers_bank_account_id_returned_at_idx;
+DROP INDEX IF EXISTS transfers_bank_account_id_created_at_idx;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Statements read the debits and the credits back of an account by booking time
+CREATE INDEX IF NOT EXISTS transfers_bank_account_id_created_at_idx ON transfers (bank_account_id, created_at);
+CREATE INDEX IF NOT EXISTS transfers_bank_account_id_returned_at_idx ON transfers (bank_account_id, returned_at) WHERE returned_at IS NOT NULL;
+
+COMMIT;
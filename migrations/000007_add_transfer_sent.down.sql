@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS transfers_sent_message_id_idx;
+DROP INDEX IF EXISTS transfers_unsent_idx;
+ALTER TABLE transfers DROP COLUMN IF EXISTS sent_message_id;
+ALTER TABLE transfers DROP COLUMN IF EXISTS sent_at;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- Executed transfers are handed to the sending bank in outbound pain.001 files,
+-- the message a transfer was sent in is recorded so that it is never sent twice
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS sent_at TIMESTAMPTZ;
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS sent_message_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS transfers_unsent_idx ON transfers (bank_account_id, created_at, id) WHERE status = 'executed' AND sent_at IS NULL;
+CREATE INDEX IF NOT EXISTS transfers_sent_message_id_idx ON transfers (sent_message_id) WHERE sent_message_id <> '';
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Create api_keys table, the keys organizations authenticate with.
+-- Only the SHA-256 hash of a key is stored, its prefix identifies it.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    organization_name TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    prefix TEXT NOT NULL UNIQUE,
+    hash TEXT NOT NULL,
+    rotated_from BIGINT REFERENCES api_keys(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_organization_name_idx ON api_keys (organization_name);
+
+COMMIT;
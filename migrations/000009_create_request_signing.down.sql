@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS request_nonces;
+DROP TABLE IF EXISTS signing_keys;
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+-- Create signing_keys table, the secrets organizations sign their bulk uploads with.
+-- The secrets are kept as they are, they are needed to verify the signatures.
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id BIGSERIAL PRIMARY KEY,
+    organization_name TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS signing_keys_organization_name_idx ON signing_keys (organization_name);
+
+-- Create request_nonces table, the nonces of the signed requests of each organization.
+-- A nonce is kept until the signatures carrying it are too old to be accepted.
+CREATE TABLE IF NOT EXISTS request_nonces (
+    organization_name TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (organization_name, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS request_nonces_expires_at_idx ON request_nonces (expires_at);
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- Create role_grants table, the roles of the principals of organizations.
+-- A grant without account_iban applies to every account of the organization.
+CREATE TABLE IF NOT EXISTS role_grants (
+    id BIGSERIAL PRIMARY KEY,
+    organization_name TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'submitter', 'approver', 'admin')),
+    account_iban TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS role_grants_unique_idx
+    ON role_grants (organization_name, subject, role, COALESCE(account_iban, ''));
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS quota_usage;
+DROP TABLE IF EXISTS rate_limit_buckets;
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Create rate_limit_buckets table, the token buckets of the callers when the
+-- instances share their rate limits.
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- Create quota_usage table, the usage of the daily quotas of the callers.
+CREATE TABLE IF NOT EXISTS quota_usage (
+    key TEXT NOT NULL,
+    day DATE NOT NULL,
+    used BIGINT NOT NULL,
+    PRIMARY KEY (key, day)
+);
+
+COMMIT;
@@ -0,0 +1,44 @@
+BEGIN;
+
+-- Create audit_events table, the append-only log of the money-moving and administrative
+-- actions. The events of a change are inserted in the transaction that makes it.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor TEXT NOT NULL,
+    actor_organization TEXT NOT NULL DEFAULT '',
+    actor_user TEXT NOT NULL DEFAULT '',
+    source_ip TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,
+    organization_name TEXT NOT NULL DEFAULT '',
+    account_iban TEXT NOT NULL DEFAULT '',
+    resource_type TEXT NOT NULL DEFAULT '',
+    resource_id TEXT NOT NULL DEFAULT '',
+    before_state JSONB,
+    after_state JSONB,
+    outcome TEXT NOT NULL CHECK (outcome IN ('success', 'failure', 'denied')),
+    error_code TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_actor_id_idx ON audit_events (actor, id);
+CREATE INDEX IF NOT EXISTS audit_events_organization_name_id_idx ON audit_events (organization_name, id);
+CREATE INDEX IF NOT EXISTS audit_events_account_iban_id_idx ON audit_events (account_iban, id);
+CREATE INDEX IF NOT EXISTS audit_events_occurred_at_idx ON audit_events (occurred_at);
+
+-- The log is append-only: updates, deletions and truncations are refused
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update_or_delete
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS transfers_bank_account_id_counterparty_iban_index_idx;
+ALTER TABLE transfers DROP COLUMN IF EXISTS counterparty_iban_index;
+
+COMMIT;
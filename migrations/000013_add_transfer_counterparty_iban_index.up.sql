@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Counterparty details may be encrypted, the IBAN is searched by its blind index, a keyed hash
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS counterparty_iban_index TEXT;
+CREATE INDEX IF NOT EXISTS transfers_bank_account_id_counterparty_iban_index_idx ON transfers (bank_account_id, counterparty_iban_index) WHERE counterparty_iban_index IS NOT NULL;
+
+COMMIT;
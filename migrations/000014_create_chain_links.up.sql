@@ -0,0 +1,22 @@
+BEGIN;
+
+-- Every change of the balance of an account, the debit of a transfer or the credit of its return,
+-- is a link of the hash chain of the account: its hash covers the transfer, the balance after the
+-- change and the hash of the previous link, so that rows edited in the database break the chain
+CREATE TABLE IF NOT EXISTS chain_links (
+    id BIGSERIAL PRIMARY KEY,
+    bank_account_id BIGINT NOT NULL REFERENCES bank_accounts(id),
+    seq BIGINT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('debit', 'credit')),
+    transfer_id BIGINT NOT NULL,
+    amount_cents BIGINT NOT NULL,
+    balance_cents BIGINT NOT NULL,
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (bank_account_id, seq)
+);
+
+CREATE INDEX IF NOT EXISTS chain_links_transfer_id_idx ON chain_links (transfer_id);
+
+COMMIT;
@@ -0,0 +1 @@
+-- The counterparty details removed from the events cannot be restored
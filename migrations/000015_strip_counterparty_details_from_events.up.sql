@@ -0,0 +1,13 @@
+BEGIN;
+
+-- The transfer.returned events used to carry the whole transfer, counterparty details included,
+-- in clear; they are removed from the events and from the webhook deliveries copied from them
+UPDATE events
+SET data = data - 'counterparty_name' - 'counterparty_iban' - 'counterparty_bic' - 'description'
+WHERE type = 'transfer.returned';
+
+UPDATE webhook_deliveries
+SET payload = jsonb_set(payload, '{data}', (payload -> 'data') - 'counterparty_name' - 'counterparty_iban' - 'counterparty_bic' - 'description')
+WHERE event_type = 'transfer.returned' AND payload ? 'data';
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+-- The transfers are chained from the first transfer created after the introduction of the chain,
+-- recorded once so that the transfers inserted without a link are still found by the verification
+-- when all the links of their account were deleted
+CREATE TABLE IF NOT EXISTS chain_start (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    first_transfer_id BIGINT NOT NULL
+);
+
+INSERT INTO chain_start (first_transfer_id)
+SELECT COALESCE(
+    (SELECT MIN(transfer_id) FROM chain_links WHERE kind = 'debit'),
+    (SELECT COALESCE(MAX(id), 0) + 1 FROM transfers)
+)
+ON CONFLICT (id) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,119 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: api_key_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=api_key_service.go -destination=../../mock/api_key_service_mock.go -package=mock -mock_names=APIKeyService=APIKeyServiceMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	apikey "moneytransfer/internal/apikey"
+	auth "moneytransfer/internal/auth"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// APIKeyServiceMock is a mock of APIKeyService interface.
+type APIKeyServiceMock struct {
+	ctrl     *gomock.Controller
+	recorder *APIKeyServiceMockMockRecorder
+}
+
+// APIKeyServiceMockMockRecorder is the mock recorder for APIKeyServiceMock.
+type APIKeyServiceMockMockRecorder struct {
+	mock *APIKeyServiceMock
+}
+
+// NewAPIKeyServiceMock creates a new mock instance.
+func NewAPIKeyServiceMock(ctrl *gomock.Controller) *APIKeyServiceMock {
+	mock := &APIKeyServiceMock{ctrl: ctrl}
+	mock.recorder = &APIKeyServiceMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *APIKeyServiceMock) EXPECT() *APIKeyServiceMockMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *APIKeyServiceMock) Authenticate(ctx context.Context, plaintext string) (auth.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, plaintext)
+	ret0, _ := ret[0].(auth.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *APIKeyServiceMockMockRecorder) Authenticate(ctx, plaintext any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*APIKeyServiceMock)(nil).Authenticate), ctx, plaintext)
+}
+
+// Create mocks base method.
+func (m *APIKeyServiceMock) Create(ctx context.Context, organizationName, name string) (*apikey.Key, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, organizationName, name)
+	ret0, _ := ret[0].(*apikey.Key)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Create indicates an expected call of Create.
+func (mr *APIKeyServiceMockMockRecorder) Create(ctx, organizationName, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*APIKeyServiceMock)(nil).Create), ctx, organizationName, name)
+}
+
+// List mocks base method.
+func (m *APIKeyServiceMock) List(ctx context.Context, organizationName string) ([]apikey.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, organizationName)
+	ret0, _ := ret[0].([]apikey.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *APIKeyServiceMockMockRecorder) List(ctx, organizationName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*APIKeyServiceMock)(nil).List), ctx, organizationName)
+}
+
+// Revoke mocks base method.
+func (m *APIKeyServiceMock) Revoke(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *APIKeyServiceMockMockRecorder) Revoke(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*APIKeyServiceMock)(nil).Revoke), ctx, id)
+}
+
+// Rotate mocks base method.
+func (m *APIKeyServiceMock) Rotate(ctx context.Context, id int64, grace time.Duration) (*apikey.Key, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", ctx, id, grace)
+	ret0, _ := ret[0].(*apikey.Key)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *APIKeyServiceMockMockRecorder) Rotate(ctx, id, grace any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*APIKeyServiceMock)(nil).Rotate), ctx, id, grace)
+}
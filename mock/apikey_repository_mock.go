@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=repository.go -destination=../../mock/apikey_repository_mock.go -package=mock -mock_names=Repository=APIKeyRepositoryMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	apikey "moneytransfer/internal/apikey"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// APIKeyRepositoryMock is a mock of Repository interface.
+type APIKeyRepositoryMock struct {
+	ctrl     *gomock.Controller
+	recorder *APIKeyRepositoryMockMockRecorder
+}
+
+// APIKeyRepositoryMockMockRecorder is the mock recorder for APIKeyRepositoryMock.
+type APIKeyRepositoryMockMockRecorder struct {
+	mock *APIKeyRepositoryMock
+}
+
+// NewAPIKeyRepositoryMock creates a new mock instance.
+func NewAPIKeyRepositoryMock(ctrl *gomock.Controller) *APIKeyRepositoryMock {
+	mock := &APIKeyRepositoryMock{ctrl: ctrl}
+	mock.recorder = &APIKeyRepositoryMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *APIKeyRepositoryMock) EXPECT() *APIKeyRepositoryMockMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *APIKeyRepositoryMock) Create(ctx context.Context, k *apikey.Key) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, k)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *APIKeyRepositoryMockMockRecorder) Create(ctx, k any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*APIKeyRepositoryMock)(nil).Create), ctx, k)
+}
+
+// Get mocks base method.
+func (m *APIKeyRepositoryMock) Get(ctx context.Context, id int64) (*apikey.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*apikey.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *APIKeyRepositoryMockMockRecorder) Get(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*APIKeyRepositoryMock)(nil).Get), ctx, id)
+}
+
+// GetByPrefix mocks base method.
+func (m *APIKeyRepositoryMock) GetByPrefix(ctx context.Context, prefix string) (*apikey.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByPrefix", ctx, prefix)
+	ret0, _ := ret[0].(*apikey.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByPrefix indicates an expected call of GetByPrefix.
+func (mr *APIKeyRepositoryMockMockRecorder) GetByPrefix(ctx, prefix any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPrefix", reflect.TypeOf((*APIKeyRepositoryMock)(nil).GetByPrefix), ctx, prefix)
+}
+
+// List mocks base method.
+func (m *APIKeyRepositoryMock) List(ctx context.Context, organizationName string) ([]apikey.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, organizationName)
+	ret0, _ := ret[0].([]apikey.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *APIKeyRepositoryMockMockRecorder) List(ctx, organizationName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*APIKeyRepositoryMock)(nil).List), ctx, organizationName)
+}
+
+// Revoke mocks base method.
+func (m *APIKeyRepositoryMock) Revoke(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *APIKeyRepositoryMockMockRecorder) Revoke(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*APIKeyRepositoryMock)(nil).Revoke), ctx, id)
+}
+
+// Rotate mocks base method.
+func (m *APIKeyRepositoryMock) Rotate(ctx context.Context, id int64, next *apikey.Key, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", ctx, id, next, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *APIKeyRepositoryMockMockRecorder) Rotate(ctx, id, next, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*APIKeyRepositoryMock)(nil).Rotate), ctx, id, next, expiresAt)
+}
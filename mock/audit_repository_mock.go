@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=repository.go -destination=../../mock/audit_repository_mock.go -package=mock -mock_names=Repository=AuditRepositoryMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	sql "database/sql"
+	audit "moneytransfer/internal/audit"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// AuditRepositoryMock is a mock of Repository interface.
+type AuditRepositoryMock struct {
+	ctrl     *gomock.Controller
+	recorder *AuditRepositoryMockMockRecorder
+}
+
+// AuditRepositoryMockMockRecorder is the mock recorder for AuditRepositoryMock.
+type AuditRepositoryMockMockRecorder struct {
+	mock *AuditRepositoryMock
+}
+
+// NewAuditRepositoryMock creates a new mock instance.
+func NewAuditRepositoryMock(ctrl *gomock.Controller) *AuditRepositoryMock {
+	mock := &AuditRepositoryMock{ctrl: ctrl}
+	mock.recorder = &AuditRepositoryMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *AuditRepositoryMock) EXPECT() *AuditRepositoryMockMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *AuditRepositoryMock) Append(ctx context.Context, tx *sql.Tx, e *audit.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, tx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *AuditRepositoryMockMockRecorder) Append(ctx, tx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*AuditRepositoryMock)(nil).Append), ctx, tx, e)
+}
+
+// List mocks base method.
+func (m *AuditRepositoryMock) List(ctx context.Context, filter audit.Filter) ([]audit.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]audit.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *AuditRepositoryMockMockRecorder) List(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*AuditRepositoryMock)(nil).List), ctx, filter)
+}
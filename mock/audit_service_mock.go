@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: audit_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=audit_service.go -destination=../../mock/audit_service_mock.go -package=mock -mock_names=AuditService=AuditServiceMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	audit "moneytransfer/internal/audit"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// AuditServiceMock is a mock of AuditService interface.
+type AuditServiceMock struct {
+	ctrl     *gomock.Controller
+	recorder *AuditServiceMockMockRecorder
+}
+
+// AuditServiceMockMockRecorder is the mock recorder for AuditServiceMock.
+type AuditServiceMockMockRecorder struct {
+	mock *AuditServiceMock
+}
+
+// NewAuditServiceMock creates a new mock instance.
+func NewAuditServiceMock(ctrl *gomock.Controller) *AuditServiceMock {
+	mock := &AuditServiceMock{ctrl: ctrl}
+	mock.recorder = &AuditServiceMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *AuditServiceMock) EXPECT() *AuditServiceMockMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *AuditServiceMock) List(ctx context.Context, filter audit.Filter) ([]audit.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]audit.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *AuditServiceMockMockRecorder) List(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*AuditServiceMock)(nil).List), ctx, filter)
+}
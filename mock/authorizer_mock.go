@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: policy.go
+//
+// Generated by this command:
+//
+//	mockgen -source=policy.go -destination=../../mock/authorizer_mock.go -package=mock -mock_names=Authorizer=AuthorizerMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	authz "moneytransfer/internal/authz"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// AuthorizerMock is a mock of Authorizer interface.
+type AuthorizerMock struct {
+	ctrl     *gomock.Controller
+	recorder *AuthorizerMockMockRecorder
+}
+
+// AuthorizerMockMockRecorder is the mock recorder for AuthorizerMock.
+type AuthorizerMockMockRecorder struct {
+	mock *AuthorizerMock
+}
+
+// NewAuthorizerMock creates a new mock instance.
+func NewAuthorizerMock(ctrl *gomock.Controller) *AuthorizerMock {
+	mock := &AuthorizerMock{ctrl: ctrl}
+	mock.recorder = &AuthorizerMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *AuthorizerMock) EXPECT() *AuthorizerMockMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *AuthorizerMock) Authorize(ctx context.Context, action authz.Action, resource authz.Resource) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, action, resource)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *AuthorizerMockMockRecorder) Authorize(ctx, action, resource any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*AuthorizerMock)(nil).Authorize), ctx, action, resource)
+}
@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=repository.go -destination=../../mock/authz_repository_mock.go -package=mock -mock_names=Repository=AuthzRepositoryMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	authz "moneytransfer/internal/authz"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// AuthzRepositoryMock is a mock of Repository interface.
+type AuthzRepositoryMock struct {
+	ctrl     *gomock.Controller
+	recorder *AuthzRepositoryMockMockRecorder
+}
+
+// AuthzRepositoryMockMockRecorder is the mock recorder for AuthzRepositoryMock.
+type AuthzRepositoryMockMockRecorder struct {
+	mock *AuthzRepositoryMock
+}
+
+// NewAuthzRepositoryMock creates a new mock instance.
+func NewAuthzRepositoryMock(ctrl *gomock.Controller) *AuthzRepositoryMock {
+	mock := &AuthzRepositoryMock{ctrl: ctrl}
+	mock.recorder = &AuthzRepositoryMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *AuthzRepositoryMock) EXPECT() *AuthzRepositoryMockMockRecorder {
+	return m.recorder
+}
+
+// CreateGrant mocks base method.
+func (m *AuthzRepositoryMock) CreateGrant(ctx context.Context, g *authz.Grant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGrant", ctx, g)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateGrant indicates an expected call of CreateGrant.
+func (mr *AuthzRepositoryMockMockRecorder) CreateGrant(ctx, g any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGrant", reflect.TypeOf((*AuthzRepositoryMock)(nil).CreateGrant), ctx, g)
+}
+
+// DeleteGrant mocks base method.
+func (m *AuthzRepositoryMock) DeleteGrant(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGrant", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGrant indicates an expected call of DeleteGrant.
+func (mr *AuthzRepositoryMockMockRecorder) DeleteGrant(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGrant", reflect.TypeOf((*AuthzRepositoryMock)(nil).DeleteGrant), ctx, id)
+}
+
+// GrantsOf mocks base method.
+func (m *AuthzRepositoryMock) GrantsOf(ctx context.Context, organizationName, subject string) ([]authz.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantsOf", ctx, organizationName, subject)
+	ret0, _ := ret[0].([]authz.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantsOf indicates an expected call of GrantsOf.
+func (mr *AuthzRepositoryMockMockRecorder) GrantsOf(ctx, organizationName, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantsOf", reflect.TypeOf((*AuthzRepositoryMock)(nil).GrantsOf), ctx, organizationName, subject)
+}
+
+// ListGrants mocks base method.
+func (m *AuthzRepositoryMock) ListGrants(ctx context.Context, organizationName string) ([]authz.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGrants", ctx, organizationName)
+	ret0, _ := ret[0].([]authz.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGrants indicates an expected call of ListGrants.
+func (mr *AuthzRepositoryMockMockRecorder) ListGrants(ctx, organizationName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGrants", reflect.TypeOf((*AuthzRepositoryMock)(nil).ListGrants), ctx, organizationName)
+}
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=repository.go -destination=../../mock/batch_repository_mock.go -package=mock -mock_names=Repository=BatchRepositoryMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	sql "database/sql"
+	batch "moneytransfer/internal/batch"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// BatchRepositoryMock is a mock of Repository interface.
+type BatchRepositoryMock struct {
+	ctrl     *gomock.Controller
+	recorder *BatchRepositoryMockMockRecorder
+}
+
+// BatchRepositoryMockMockRecorder is the mock recorder for BatchRepositoryMock.
+type BatchRepositoryMockMockRecorder struct {
+	mock *BatchRepositoryMock
+}
+
+// NewBatchRepositoryMock creates a new mock instance.
+func NewBatchRepositoryMock(ctrl *gomock.Controller) *BatchRepositoryMock {
+	mock := &BatchRepositoryMock{ctrl: ctrl}
+	mock.recorder = &BatchRepositoryMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *BatchRepositoryMock) EXPECT() *BatchRepositoryMockMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *BatchRepositoryMock) Create(ctx context.Context, tx *sql.Tx, b *batch.Batch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, tx, b)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *BatchRepositoryMockMockRecorder) Create(ctx, tx, b any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*BatchRepositoryMock)(nil).Create), ctx, tx, b)
+}
+
+// Get mocks base method.
+func (m *BatchRepositoryMock) Get(ctx context.Context, id int64) (*batch.Batch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*batch.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *BatchRepositoryMockMockRecorder) Get(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*BatchRepositoryMock)(nil).Get), ctx, id)
+}
+
+// Update mocks base method.
+func (m *BatchRepositoryMock) Update(ctx context.Context, tx *sql.Tx, b *batch.Batch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, tx, b)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *BatchRepositoryMockMockRecorder) Update(ctx, tx, b any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*BatchRepositoryMock)(nil).Update), ctx, tx, b)
+}
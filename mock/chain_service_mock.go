@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: chain_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=chain_service.go -destination=../../mock/chain_service_mock.go -package=mock -mock_names=ChainService=ChainServiceMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	service "moneytransfer/internal/service"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// ChainServiceMock is a mock of ChainService interface.
+type ChainServiceMock struct {
+	ctrl     *gomock.Controller
+	recorder *ChainServiceMockMockRecorder
+}
+
+// ChainServiceMockMockRecorder is the mock recorder for ChainServiceMock.
+type ChainServiceMockMockRecorder struct {
+	mock *ChainServiceMock
+}
+
+// NewChainServiceMock creates a new mock instance.
+func NewChainServiceMock(ctrl *gomock.Controller) *ChainServiceMock {
+	mock := &ChainServiceMock{ctrl: ctrl}
+	mock.recorder = &ChainServiceMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *ChainServiceMock) EXPECT() *ChainServiceMockMockRecorder {
+	return m.recorder
+}
+
+// Verify mocks base method.
+func (m *ChainServiceMock) Verify(ctx context.Context, iban string) (*service.ChainReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, iban)
+	ret0, _ := ret[0].(*service.ChainReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *ChainServiceMockMockRecorder) Verify(ctx, iban any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*ChainServiceMock)(nil).Verify), ctx, iban)
+}
+
+// VerifyAll mocks base method.
+func (m *ChainServiceMock) VerifyAll(ctx context.Context) ([]service.ChainReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAll", ctx)
+	ret0, _ := ret[0].([]service.ChainReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAll indicates an expected call of VerifyAll.
+func (mr *ChainServiceMockMockRecorder) VerifyAll(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAll", reflect.TypeOf((*ChainServiceMock)(nil).VerifyAll), ctx)
+}
@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=repository.go -destination=../../mock/event_repository_mock.go -package=mock -mock_names=Repository=EventRepositoryMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	sql "database/sql"
+	event "moneytransfer/internal/event"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// EventRepositoryMock is a mock of Repository interface.
+type EventRepositoryMock struct {
+	ctrl     *gomock.Controller
+	recorder *EventRepositoryMockMockRecorder
+}
+
+// EventRepositoryMockMockRecorder is the mock recorder for EventRepositoryMock.
+type EventRepositoryMockMockRecorder struct {
+	mock *EventRepositoryMock
+}
+
+// NewEventRepositoryMock creates a new mock instance.
+func NewEventRepositoryMock(ctrl *gomock.Controller) *EventRepositoryMock {
+	mock := &EventRepositoryMock{ctrl: ctrl}
+	mock.recorder = &EventRepositoryMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *EventRepositoryMock) EXPECT() *EventRepositoryMockMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *EventRepositoryMock) Append(ctx context.Context, tx *sql.Tx, e *event.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, tx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *EventRepositoryMockMockRecorder) Append(ctx, tx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*EventRepositoryMock)(nil).Append), ctx, tx, e)
+}
+
+// ListAfter mocks base method.
+func (m *EventRepositoryMock) ListAfter(ctx context.Context, filter event.Filter, afterSeq int64, limit int) ([]event.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfter", ctx, filter, afterSeq, limit)
+	ret0, _ := ret[0].([]event.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfter indicates an expected call of ListAfter.
+func (mr *EventRepositoryMockMockRecorder) ListAfter(ctx, filter, afterSeq, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfter", reflect.TypeOf((*EventRepositoryMock)(nil).ListAfter), ctx, filter, afterSeq, limit)
+}
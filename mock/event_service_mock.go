@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: event_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=event_service.go -destination=../../mock/event_service_mock.go -package=mock -mock_names=EventService=EventServiceMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	event "moneytransfer/internal/event"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// EventServiceMock is a mock of EventService interface.
+type EventServiceMock struct {
+	ctrl     *gomock.Controller
+	recorder *EventServiceMockMockRecorder
+}
+
+// EventServiceMockMockRecorder is the mock recorder for EventServiceMock.
+type EventServiceMockMockRecorder struct {
+	mock *EventServiceMock
+}
+
+// NewEventServiceMock creates a new mock instance.
+func NewEventServiceMock(ctrl *gomock.Controller) *EventServiceMock {
+	mock := &EventServiceMock{ctrl: ctrl}
+	mock.recorder = &EventServiceMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *EventServiceMock) EXPECT() *EventServiceMockMockRecorder {
+	return m.recorder
+}
+
+// ListEvents mocks base method.
+func (m *EventServiceMock) ListEvents(ctx context.Context, filter event.Filter, afterSeq int64, limit int) ([]event.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEvents", ctx, filter, afterSeq, limit)
+	ret0, _ := ret[0].([]event.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEvents indicates an expected call of ListEvents.
+func (mr *EventServiceMockMockRecorder) ListEvents(ctx, filter, afterSeq, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEvents", reflect.TypeOf((*EventServiceMock)(nil).ListEvents), ctx, filter, afterSeq, limit)
+}
+
+// Subscribe mocks base method.
+func (m *EventServiceMock) Subscribe(ctx context.Context, filter event.Filter) (<-chan struct{}, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, filter)
+	ret0, _ := ret[0].(<-chan struct{})
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *EventServiceMockMockRecorder) Subscribe(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*EventServiceMock)(nil).Subscribe), ctx, filter)
+}
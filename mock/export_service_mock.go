@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: export_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=export_service.go -destination=../../mock/export_service_mock.go -package=mock -mock_names=ExportService=ExportServiceMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	io "io"
+	service "moneytransfer/internal/service"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// ExportServiceMock is a mock of ExportService interface.
+type ExportServiceMock struct {
+	ctrl     *gomock.Controller
+	recorder *ExportServiceMockMockRecorder
+}
+
+// ExportServiceMockMockRecorder is the mock recorder for ExportServiceMock.
+type ExportServiceMockMockRecorder struct {
+	mock *ExportServiceMock
+}
+
+// NewExportServiceMock creates a new mock instance.
+func NewExportServiceMock(ctrl *gomock.Controller) *ExportServiceMock {
+	mock := &ExportServiceMock{ctrl: ctrl}
+	mock.recorder = &ExportServiceMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *ExportServiceMock) EXPECT() *ExportServiceMockMockRecorder {
+	return m.recorder
+}
+
+// ExportTransfers mocks base method.
+func (m *ExportServiceMock) ExportTransfers(ctx context.Context, format service.ExportFormat, filter service.ExportFilter, w io.Writer) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportTransfers", ctx, format, filter, w)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportTransfers indicates an expected call of ExportTransfers.
+func (mr *ExportServiceMockMockRecorder) ExportTransfers(ctx, format, filter, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportTransfers", reflect.TypeOf((*ExportServiceMock)(nil).ExportTransfers), ctx, format, filter, w)
+}
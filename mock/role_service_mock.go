@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: role_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=role_service.go -destination=../../mock/role_service_mock.go -package=mock -mock_names=RoleService=RoleServiceMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	authz "moneytransfer/internal/authz"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// RoleServiceMock is a mock of RoleService interface.
+type RoleServiceMock struct {
+	ctrl     *gomock.Controller
+	recorder *RoleServiceMockMockRecorder
+}
+
+// RoleServiceMockMockRecorder is the mock recorder for RoleServiceMock.
+type RoleServiceMockMockRecorder struct {
+	mock *RoleServiceMock
+}
+
+// NewRoleServiceMock creates a new mock instance.
+func NewRoleServiceMock(ctrl *gomock.Controller) *RoleServiceMock {
+	mock := &RoleServiceMock{ctrl: ctrl}
+	mock.recorder = &RoleServiceMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *RoleServiceMock) EXPECT() *RoleServiceMockMockRecorder {
+	return m.recorder
+}
+
+// Grant mocks base method.
+func (m *RoleServiceMock) Grant(ctx context.Context, organizationName, subject string, role authz.Role, accountIBAN string) (*authz.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Grant", ctx, organizationName, subject, role, accountIBAN)
+	ret0, _ := ret[0].(*authz.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Grant indicates an expected call of Grant.
+func (mr *RoleServiceMockMockRecorder) Grant(ctx, organizationName, subject, role, accountIBAN any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Grant", reflect.TypeOf((*RoleServiceMock)(nil).Grant), ctx, organizationName, subject, role, accountIBAN)
+}
+
+// List mocks base method.
+func (m *RoleServiceMock) List(ctx context.Context, organizationName string) ([]authz.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, organizationName)
+	ret0, _ := ret[0].([]authz.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *RoleServiceMockMockRecorder) List(ctx, organizationName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*RoleServiceMock)(nil).List), ctx, organizationName)
+}
+
+// Revoke mocks base method.
+func (m *RoleServiceMock) Revoke(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *RoleServiceMockMockRecorder) Revoke(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*RoleServiceMock)(nil).Revoke), ctx, id)
+}
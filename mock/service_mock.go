@@ -11,7 +11,9 @@ package mock
 
 import (
 	context "context"
+	batch "moneytransfer/internal/batch"
 	service "moneytransfer/internal/service"
+	transfer "moneytransfer/internal/transfer"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -41,11 +43,12 @@ func (m *TransferServiceMock) EXPECT() *TransferServiceMockMockRecorder {
 }
 
 // BulkTransfer mocks base method.
-func (m *TransferServiceMock) BulkTransfer(ctx context.Context, req service.BulkTransferRequest) error {
+func (m *TransferServiceMock) BulkTransfer(ctx context.Context, req service.BulkTransferRequest) (*batch.Batch, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "BulkTransfer", ctx, req)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*batch.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // BulkTransfer indicates an expected call of BulkTransfer.
@@ -53,3 +56,33 @@ func (mr *TransferServiceMockMockRecorder) BulkTransfer(ctx, req any) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkTransfer", reflect.TypeOf((*TransferServiceMock)(nil).BulkTransfer), ctx, req)
 }
+
+// GetBatch mocks base method.
+func (m *TransferServiceMock) GetBatch(ctx context.Context, id int64) (*batch.Batch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBatch", ctx, id)
+	ret0, _ := ret[0].(*batch.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBatch indicates an expected call of GetBatch.
+func (mr *TransferServiceMockMockRecorder) GetBatch(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBatch", reflect.TypeOf((*TransferServiceMock)(nil).GetBatch), ctx, id)
+}
+
+// ReturnTransfer mocks base method.
+func (m *TransferServiceMock) ReturnTransfer(ctx context.Context, id int64, reason string) (*transfer.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReturnTransfer", ctx, id, reason)
+	ret0, _ := ret[0].(*transfer.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReturnTransfer indicates an expected call of ReturnTransfer.
+func (mr *TransferServiceMockMockRecorder) ReturnTransfer(ctx, id, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReturnTransfer", reflect.TypeOf((*TransferServiceMock)(nil).ReturnTransfer), ctx, id, reason)
+}
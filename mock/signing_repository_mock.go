@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=repository.go -destination=../../mock/signing_repository_mock.go -package=mock -mock_names=Repository=SigningRepositoryMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	signing "moneytransfer/internal/signing"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// SigningRepositoryMock is a mock of Repository interface.
+type SigningRepositoryMock struct {
+	ctrl     *gomock.Controller
+	recorder *SigningRepositoryMockMockRecorder
+}
+
+// SigningRepositoryMockMockRecorder is the mock recorder for SigningRepositoryMock.
+type SigningRepositoryMockMockRecorder struct {
+	mock *SigningRepositoryMock
+}
+
+// NewSigningRepositoryMock creates a new mock instance.
+func NewSigningRepositoryMock(ctrl *gomock.Controller) *SigningRepositoryMock {
+	mock := &SigningRepositoryMock{ctrl: ctrl}
+	mock.recorder = &SigningRepositoryMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *SigningRepositoryMock) EXPECT() *SigningRepositoryMockMockRecorder {
+	return m.recorder
+}
+
+// CreateKey mocks base method.
+func (m *SigningRepositoryMock) CreateKey(ctx context.Context, k *signing.Key) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKey", ctx, k)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateKey indicates an expected call of CreateKey.
+func (mr *SigningRepositoryMockMockRecorder) CreateKey(ctx, k any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKey", reflect.TypeOf((*SigningRepositoryMock)(nil).CreateKey), ctx, k)
+}
+
+// GetKey mocks base method.
+func (m *SigningRepositoryMock) GetKey(ctx context.Context, id int64) (*signing.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKey", ctx, id)
+	ret0, _ := ret[0].(*signing.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKey indicates an expected call of GetKey.
+func (mr *SigningRepositoryMockMockRecorder) GetKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKey", reflect.TypeOf((*SigningRepositoryMock)(nil).GetKey), ctx, id)
+}
+
+// ListKeys mocks base method.
+func (m *SigningRepositoryMock) ListKeys(ctx context.Context, organizationName string) ([]signing.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKeys", ctx, organizationName)
+	ret0, _ := ret[0].([]signing.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKeys indicates an expected call of ListKeys.
+func (mr *SigningRepositoryMockMockRecorder) ListKeys(ctx, organizationName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeys", reflect.TypeOf((*SigningRepositoryMock)(nil).ListKeys), ctx, organizationName)
+}
+
+// RevokeKey mocks base method.
+func (m *SigningRepositoryMock) RevokeKey(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeKey indicates an expected call of RevokeKey.
+func (mr *SigningRepositoryMockMockRecorder) RevokeKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeKey", reflect.TypeOf((*SigningRepositoryMock)(nil).RevokeKey), ctx, id)
+}
+
+// UseNonce mocks base method.
+func (m *SigningRepositoryMock) UseNonce(ctx context.Context, organizationName, nonce string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseNonce", ctx, organizationName, nonce, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseNonce indicates an expected call of UseNonce.
+func (mr *SigningRepositoryMockMockRecorder) UseNonce(ctx, organizationName, nonce, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseNonce", reflect.TypeOf((*SigningRepositoryMock)(nil).UseNonce), ctx, organizationName, nonce, expiresAt)
+}
@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: signing_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=signing_service.go -destination=../../mock/signing_service_mock.go -package=mock -mock_names=SigningService=SigningServiceMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	signing "moneytransfer/internal/signing"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// SigningServiceMock is a mock of SigningService interface.
+type SigningServiceMock struct {
+	ctrl     *gomock.Controller
+	recorder *SigningServiceMockMockRecorder
+}
+
+// SigningServiceMockMockRecorder is the mock recorder for SigningServiceMock.
+type SigningServiceMockMockRecorder struct {
+	mock *SigningServiceMock
+}
+
+// NewSigningServiceMock creates a new mock instance.
+func NewSigningServiceMock(ctrl *gomock.Controller) *SigningServiceMock {
+	mock := &SigningServiceMock{ctrl: ctrl}
+	mock.recorder = &SigningServiceMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *SigningServiceMock) EXPECT() *SigningServiceMockMockRecorder {
+	return m.recorder
+}
+
+// CreateKey mocks base method.
+func (m *SigningServiceMock) CreateKey(ctx context.Context, organizationName string) (*signing.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKey", ctx, organizationName)
+	ret0, _ := ret[0].(*signing.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateKey indicates an expected call of CreateKey.
+func (mr *SigningServiceMockMockRecorder) CreateKey(ctx, organizationName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKey", reflect.TypeOf((*SigningServiceMock)(nil).CreateKey), ctx, organizationName)
+}
+
+// ListKeys mocks base method.
+func (m *SigningServiceMock) ListKeys(ctx context.Context, organizationName string) ([]signing.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKeys", ctx, organizationName)
+	ret0, _ := ret[0].([]signing.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKeys indicates an expected call of ListKeys.
+func (mr *SigningServiceMockMockRecorder) ListKeys(ctx, organizationName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeys", reflect.TypeOf((*SigningServiceMock)(nil).ListKeys), ctx, organizationName)
+}
+
+// RevokeKey mocks base method.
+func (m *SigningServiceMock) RevokeKey(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeKey indicates an expected call of RevokeKey.
+func (mr *SigningServiceMockMockRecorder) RevokeKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeKey", reflect.TypeOf((*SigningServiceMock)(nil).RevokeKey), ctx, id)
+}
+
+// Verify mocks base method.
+func (m *SigningServiceMock) Verify(ctx context.Context, r signing.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *SigningServiceMockMockRecorder) Verify(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*SigningServiceMock)(nil).Verify), ctx, r)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: statement_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=statement_service.go -destination=../../mock/statement_service_mock.go -package=mock -mock_names=StatementService=StatementServiceMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// StatementServiceMock is a mock of StatementService interface.
+type StatementServiceMock struct {
+	ctrl     *gomock.Controller
+	recorder *StatementServiceMockMockRecorder
+}
+
+// StatementServiceMockMockRecorder is the mock recorder for StatementServiceMock.
+type StatementServiceMockMockRecorder struct {
+	mock *StatementServiceMock
+}
+
+// NewStatementServiceMock creates a new mock instance.
+func NewStatementServiceMock(ctrl *gomock.Controller) *StatementServiceMock {
+	mock := &StatementServiceMock{ctrl: ctrl}
+	mock.recorder = &StatementServiceMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *StatementServiceMock) EXPECT() *StatementServiceMockMockRecorder {
+	return m.recorder
+}
+
+// WriteMT940Statement mocks base method.
+func (m *StatementServiceMock) WriteMT940Statement(ctx context.Context, iban string, fromDate, toDate time.Time, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteMT940Statement", ctx, iban, fromDate, toDate, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteMT940Statement indicates an expected call of WriteMT940Statement.
+func (mr *StatementServiceMockMockRecorder) WriteMT940Statement(ctx, iban, fromDate, toDate, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteMT940Statement", reflect.TypeOf((*StatementServiceMock)(nil).WriteMT940Statement), ctx, iban, fromDate, toDate, w)
+}
+
+// WriteStatement mocks base method.
+func (m *StatementServiceMock) WriteStatement(ctx context.Context, iban string, fromDate, toDate time.Time, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteStatement", ctx, iban, fromDate, toDate, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteStatement indicates an expected call of WriteStatement.
+func (mr *StatementServiceMockMockRecorder) WriteStatement(ctx, iban, fromDate, toDate, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteStatement", reflect.TypeOf((*StatementServiceMock)(nil).WriteStatement), ctx, iban, fromDate, toDate, w)
+}
@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: status_report_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=status_report_service.go -destination=../../mock/status_report_service_mock.go -package=mock -mock_names=StatusReportService=StatusReportServiceMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// StatusReportServiceMock is a mock of StatusReportService interface.
+type StatusReportServiceMock struct {
+	ctrl     *gomock.Controller
+	recorder *StatusReportServiceMockMockRecorder
+}
+
+// StatusReportServiceMockMockRecorder is the mock recorder for StatusReportServiceMock.
+type StatusReportServiceMockMockRecorder struct {
+	mock *StatusReportServiceMock
+}
+
+// NewStatusReportServiceMock creates a new mock instance.
+func NewStatusReportServiceMock(ctrl *gomock.Controller) *StatusReportServiceMock {
+	mock := &StatusReportServiceMock{ctrl: ctrl}
+	mock.recorder = &StatusReportServiceMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *StatusReportServiceMock) EXPECT() *StatusReportServiceMockMockRecorder {
+	return m.recorder
+}
+
+// WriteStatusReport mocks base method.
+func (m *StatusReportServiceMock) WriteStatusReport(ctx context.Context, batchID int64, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteStatusReport", ctx, batchID, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteStatusReport indicates an expected call of WriteStatusReport.
+func (mr *StatusReportServiceMockMockRecorder) WriteStatusReport(ctx, batchID, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteStatusReport", reflect.TypeOf((*StatusReportServiceMock)(nil).WriteStatusReport), ctx, batchID, w)
+}
@@ -14,6 +14,7 @@ import (
 	sql "database/sql"
 	transfer "moneytransfer/internal/transfer"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,16 +42,250 @@ func (m *TransferRepositoryMock) EXPECT() *TransferRepositoryMockMockRecorder {
 	return m.recorder
 }
 
+// ChainAccounts mocks base method.
+func (m *TransferRepositoryMock) ChainAccounts(ctx context.Context) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainAccounts", ctx)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainAccounts indicates an expected call of ChainAccounts.
+func (mr *TransferRepositoryMockMockRecorder) ChainAccounts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainAccounts", reflect.TypeOf((*TransferRepositoryMock)(nil).ChainAccounts), ctx)
+}
+
+// ChainReturn mocks base method.
+func (m *TransferRepositoryMock) ChainReturn(ctx context.Context, tx *sql.Tx, t *transfer.Transfer, balanceCents int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainReturn", ctx, tx, t, balanceCents)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChainReturn indicates an expected call of ChainReturn.
+func (mr *TransferRepositoryMockMockRecorder) ChainReturn(ctx, tx, t, balanceCents any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainReturn", reflect.TypeOf((*TransferRepositoryMock)(nil).ChainReturn), ctx, tx, t, balanceCents)
+}
+
+// ClaimUnsent mocks base method.
+func (m *TransferRepositoryMock) ClaimUnsent(ctx context.Context, tx *sql.Tx, limit int) ([]transfer.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimUnsent", ctx, tx, limit)
+	ret0, _ := ret[0].([]transfer.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimUnsent indicates an expected call of ClaimUnsent.
+func (mr *TransferRepositoryMockMockRecorder) ClaimUnsent(ctx, tx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimUnsent", reflect.TypeOf((*TransferRepositoryMock)(nil).ClaimUnsent), ctx, tx, limit)
+}
+
+// CountByPaymentInformation mocks base method.
+func (m *TransferRepositoryMock) CountByPaymentInformation(ctx context.Context, batchID int64) ([]transfer.PaymentInformationCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByPaymentInformation", ctx, batchID)
+	ret0, _ := ret[0].([]transfer.PaymentInformationCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByPaymentInformation indicates an expected call of CountByPaymentInformation.
+func (mr *TransferRepositoryMockMockRecorder) CountByPaymentInformation(ctx, batchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByPaymentInformation", reflect.TypeOf((*TransferRepositoryMock)(nil).CountByPaymentInformation), ctx, batchID)
+}
+
+// CountSent mocks base method.
+func (m *TransferRepositoryMock) CountSent(ctx context.Context, messageID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSent", ctx, messageID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSent indicates an expected call of CountSent.
+func (mr *TransferRepositoryMockMockRecorder) CountSent(ctx, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSent", reflect.TypeOf((*TransferRepositoryMock)(nil).CountSent), ctx, messageID)
+}
+
 // CreateBulkTransfers mocks base method.
-func (m *TransferRepositoryMock) CreateBulkTransfers(ctx context.Context, tx *sql.Tx, transfers []transfer.Transfer) error {
+func (m *TransferRepositoryMock) CreateBulkTransfers(ctx context.Context, tx *sql.Tx, transfers []transfer.Transfer, balanceCents int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateBulkTransfers", ctx, tx, transfers)
+	ret := m.ctrl.Call(m, "CreateBulkTransfers", ctx, tx, transfers, balanceCents)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateBulkTransfers indicates an expected call of CreateBulkTransfers.
-func (mr *TransferRepositoryMockMockRecorder) CreateBulkTransfers(ctx, tx, transfers any) *gomock.Call {
+func (mr *TransferRepositoryMockMockRecorder) CreateBulkTransfers(ctx, tx, transfers, balanceCents any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBulkTransfers", reflect.TypeOf((*TransferRepositoryMock)(nil).CreateBulkTransfers), ctx, tx, transfers, balanceCents)
+}
+
+// FirstUnchained mocks base method.
+func (m *TransferRepositoryMock) FirstUnchained(ctx context.Context, tx *sql.Tx, bankAccountID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FirstUnchained", ctx, tx, bankAccountID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FirstUnchained indicates an expected call of FirstUnchained.
+func (mr *TransferRepositoryMockMockRecorder) FirstUnchained(ctx, tx, bankAccountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FirstUnchained", reflect.TypeOf((*TransferRepositoryMock)(nil).FirstUnchained), ctx, tx, bankAccountID)
+}
+
+// ForEachLink mocks base method.
+func (m *TransferRepositoryMock) ForEachLink(ctx context.Context, tx *sql.Tx, bankAccountID int64, fn func(transfer.Link, *transfer.Transfer) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachLink", ctx, tx, bankAccountID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachLink indicates an expected call of ForEachLink.
+func (mr *TransferRepositoryMockMockRecorder) ForEachLink(ctx, tx, bankAccountID, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachLink", reflect.TypeOf((*TransferRepositoryMock)(nil).ForEachLink), ctx, tx, bankAccountID, fn)
+}
+
+// ForEachMovement mocks base method.
+func (m *TransferRepositoryMock) ForEachMovement(ctx context.Context, tx *sql.Tx, bankAccountID int64, from, to time.Time, fn func(transfer.Movement) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachMovement", ctx, tx, bankAccountID, from, to, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachMovement indicates an expected call of ForEachMovement.
+func (mr *TransferRepositoryMockMockRecorder) ForEachMovement(ctx, tx, bankAccountID, from, to, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachMovement", reflect.TypeOf((*TransferRepositoryMock)(nil).ForEachMovement), ctx, tx, bankAccountID, from, to, fn)
+}
+
+// ForEachTransfer mocks base method.
+func (m *TransferRepositoryMock) ForEachTransfer(ctx context.Context, tx *sql.Tx, filter transfer.Filter, fn func(transfer.Transfer) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachTransfer", ctx, tx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachTransfer indicates an expected call of ForEachTransfer.
+func (mr *TransferRepositoryMockMockRecorder) ForEachTransfer(ctx, tx, filter, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachTransfer", reflect.TypeOf((*TransferRepositoryMock)(nil).ForEachTransfer), ctx, tx, filter, fn)
+}
+
+// Get mocks base method.
+func (m *TransferRepositoryMock) Get(ctx context.Context, tx *sql.Tx, id int64) (*transfer.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, tx, id)
+	ret0, _ := ret[0].(*transfer.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *TransferRepositoryMockMockRecorder) Get(ctx, tx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*TransferRepositoryMock)(nil).Get), ctx, tx, id)
+}
+
+// ListByBatch mocks base method.
+func (m *TransferRepositoryMock) ListByBatch(ctx context.Context, batchID, afterID int64, limit int) ([]transfer.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByBatch", ctx, batchID, afterID, limit)
+	ret0, _ := ret[0].([]transfer.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByBatch indicates an expected call of ListByBatch.
+func (mr *TransferRepositoryMockMockRecorder) ListByBatch(ctx, batchID, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByBatch", reflect.TypeOf((*TransferRepositoryMock)(nil).ListByBatch), ctx, batchID, afterID, limit)
+}
+
+// LockSending mocks base method.
+func (m *TransferRepositoryMock) LockSending(ctx context.Context, tx *sql.Tx) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockSending", ctx, tx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockSending indicates an expected call of LockSending.
+func (mr *TransferRepositoryMockMockRecorder) LockSending(ctx, tx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockSending", reflect.TypeOf((*TransferRepositoryMock)(nil).LockSending), ctx, tx)
+}
+
+// MarkSent mocks base method.
+func (m *TransferRepositoryMock) MarkSent(ctx context.Context, tx *sql.Tx, ids []int64, messageID string, sentAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSent", ctx, tx, ids, messageID, sentAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSent indicates an expected call of MarkSent.
+func (mr *TransferRepositoryMockMockRecorder) MarkSent(ctx, tx, ids, messageID, sentAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSent", reflect.TypeOf((*TransferRepositoryMock)(nil).MarkSent), ctx, tx, ids, messageID, sentAt)
+}
+
+// Reencrypt mocks base method.
+func (m *TransferRepositoryMock) Reencrypt(ctx context.Context, afterID int64, limit int) (*transfer.ReencryptBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reencrypt", ctx, afterID, limit)
+	ret0, _ := ret[0].(*transfer.ReencryptBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reencrypt indicates an expected call of Reencrypt.
+func (mr *TransferRepositoryMockMockRecorder) Reencrypt(ctx, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reencrypt", reflect.TypeOf((*TransferRepositoryMock)(nil).Reencrypt), ctx, afterID, limit)
+}
+
+// SummarizeMovements mocks base method.
+func (m *TransferRepositoryMock) SummarizeMovements(ctx context.Context, tx *sql.Tx, bankAccountID int64, from, to time.Time) (*transfer.MovementSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SummarizeMovements", ctx, tx, bankAccountID, from, to)
+	ret0, _ := ret[0].(*transfer.MovementSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SummarizeMovements indicates an expected call of SummarizeMovements.
+func (mr *TransferRepositoryMockMockRecorder) SummarizeMovements(ctx, tx, bankAccountID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SummarizeMovements", reflect.TypeOf((*TransferRepositoryMock)(nil).SummarizeMovements), ctx, tx, bankAccountID, from, to)
+}
+
+// UpdateStatus mocks base method.
+func (m *TransferRepositoryMock) UpdateStatus(ctx context.Context, tx *sql.Tx, t *transfer.Transfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, tx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *TransferRepositoryMockMockRecorder) UpdateStatus(ctx, tx, t any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBulkTransfers", reflect.TypeOf((*TransferRepositoryMock)(nil).CreateBulkTransfers), ctx, tx, transfers)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*TransferRepositoryMock)(nil).UpdateStatus), ctx, tx, t)
 }
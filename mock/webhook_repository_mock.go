@@ -0,0 +1,204 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=repository.go -destination=../../mock/webhook_repository_mock.go -package=mock -mock_names=Repository=WebhookRepositoryMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	event "moneytransfer/internal/event"
+	webhook "moneytransfer/internal/webhook"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// WebhookRepositoryMock is a mock of Repository interface.
+type WebhookRepositoryMock struct {
+	ctrl     *gomock.Controller
+	recorder *WebhookRepositoryMockMockRecorder
+}
+
+// WebhookRepositoryMockMockRecorder is the mock recorder for WebhookRepositoryMock.
+type WebhookRepositoryMockMockRecorder struct {
+	mock *WebhookRepositoryMock
+}
+
+// NewWebhookRepositoryMock creates a new mock instance.
+func NewWebhookRepositoryMock(ctrl *gomock.Controller) *WebhookRepositoryMock {
+	mock := &WebhookRepositoryMock{ctrl: ctrl}
+	mock.recorder = &WebhookRepositoryMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *WebhookRepositoryMock) EXPECT() *WebhookRepositoryMockMockRecorder {
+	return m.recorder
+}
+
+// ClaimDue mocks base method.
+func (m *WebhookRepositoryMock) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDue", ctx, limit, lease)
+	ret0, _ := ret[0].([]webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDue indicates an expected call of ClaimDue.
+func (mr *WebhookRepositoryMockMockRecorder) ClaimDue(ctx, limit, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDue", reflect.TypeOf((*WebhookRepositoryMock)(nil).ClaimDue), ctx, limit, lease)
+}
+
+// CreateDelivery mocks base method.
+func (m *WebhookRepositoryMock) CreateDelivery(ctx context.Context, d *webhook.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDelivery", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDelivery indicates an expected call of CreateDelivery.
+func (mr *WebhookRepositoryMockMockRecorder) CreateDelivery(ctx, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDelivery", reflect.TypeOf((*WebhookRepositoryMock)(nil).CreateDelivery), ctx, d)
+}
+
+// CreateEndpoint mocks base method.
+func (m *WebhookRepositoryMock) CreateEndpoint(ctx context.Context, e *webhook.Endpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEndpoint", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEndpoint indicates an expected call of CreateEndpoint.
+func (mr *WebhookRepositoryMockMockRecorder) CreateEndpoint(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEndpoint", reflect.TypeOf((*WebhookRepositoryMock)(nil).CreateEndpoint), ctx, e)
+}
+
+// Cursor mocks base method.
+func (m *WebhookRepositoryMock) Cursor(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cursor", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cursor indicates an expected call of Cursor.
+func (mr *WebhookRepositoryMockMockRecorder) Cursor(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cursor", reflect.TypeOf((*WebhookRepositoryMock)(nil).Cursor), ctx)
+}
+
+// DisableEndpoint mocks base method.
+func (m *WebhookRepositoryMock) DisableEndpoint(ctx context.Context, organizationName string, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableEndpoint", ctx, organizationName, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableEndpoint indicates an expected call of DisableEndpoint.
+func (mr *WebhookRepositoryMockMockRecorder) DisableEndpoint(ctx, organizationName, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableEndpoint", reflect.TypeOf((*WebhookRepositoryMock)(nil).DisableEndpoint), ctx, organizationName, id)
+}
+
+// Enqueue mocks base method.
+func (m *WebhookRepositoryMock) Enqueue(ctx context.Context, events []event.Event) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, events)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *WebhookRepositoryMockMockRecorder) Enqueue(ctx, events any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*WebhookRepositoryMock)(nil).Enqueue), ctx, events)
+}
+
+// GetDelivery mocks base method.
+func (m *WebhookRepositoryMock) GetDelivery(ctx context.Context, id int64) (*webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDelivery", ctx, id)
+	ret0, _ := ret[0].(*webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDelivery indicates an expected call of GetDelivery.
+func (mr *WebhookRepositoryMockMockRecorder) GetDelivery(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelivery", reflect.TypeOf((*WebhookRepositoryMock)(nil).GetDelivery), ctx, id)
+}
+
+// GetEndpoint mocks base method.
+func (m *WebhookRepositoryMock) GetEndpoint(ctx context.Context, id int64) (*webhook.Endpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEndpoint", ctx, id)
+	ret0, _ := ret[0].(*webhook.Endpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEndpoint indicates an expected call of GetEndpoint.
+func (mr *WebhookRepositoryMockMockRecorder) GetEndpoint(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEndpoint", reflect.TypeOf((*WebhookRepositoryMock)(nil).GetEndpoint), ctx, id)
+}
+
+// ListDeliveries mocks base method.
+func (m *WebhookRepositoryMock) ListDeliveries(ctx context.Context, organizationName string, endpointID int64, limit int) ([]webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, organizationName, endpointID, limit)
+	ret0, _ := ret[0].([]webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *WebhookRepositoryMockMockRecorder) ListDeliveries(ctx, organizationName, endpointID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*WebhookRepositoryMock)(nil).ListDeliveries), ctx, organizationName, endpointID, limit)
+}
+
+// ListEndpoints mocks base method.
+func (m *WebhookRepositoryMock) ListEndpoints(ctx context.Context, organizationName string) ([]webhook.Endpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEndpoints", ctx, organizationName)
+	ret0, _ := ret[0].([]webhook.Endpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEndpoints indicates an expected call of ListEndpoints.
+func (mr *WebhookRepositoryMockMockRecorder) ListEndpoints(ctx, organizationName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEndpoints", reflect.TypeOf((*WebhookRepositoryMock)(nil).ListEndpoints), ctx, organizationName)
+}
+
+// RecordAttempt mocks base method.
+func (m *WebhookRepositoryMock) RecordAttempt(ctx context.Context, d *webhook.Delivery, a *webhook.Attempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAttempt", ctx, d, a)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAttempt indicates an expected call of RecordAttempt.
+func (mr *WebhookRepositoryMockMockRecorder) RecordAttempt(ctx, d, a any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAttempt", reflect.TypeOf((*WebhookRepositoryMock)(nil).RecordAttempt), ctx, d, a)
+}
@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webhook_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webhook_service.go -destination=../../mock/webhook_service_mock.go -package=mock -mock_names=WebhookService=WebhookServiceMock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	event "moneytransfer/internal/event"
+	webhook "moneytransfer/internal/webhook"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// WebhookServiceMock is a mock of WebhookService interface.
+type WebhookServiceMock struct {
+	ctrl     *gomock.Controller
+	recorder *WebhookServiceMockMockRecorder
+}
+
+// WebhookServiceMockMockRecorder is the mock recorder for WebhookServiceMock.
+type WebhookServiceMockMockRecorder struct {
+	mock *WebhookServiceMock
+}
+
+// NewWebhookServiceMock creates a new mock instance.
+func NewWebhookServiceMock(ctrl *gomock.Controller) *WebhookServiceMock {
+	mock := &WebhookServiceMock{ctrl: ctrl}
+	mock.recorder = &WebhookServiceMockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *WebhookServiceMock) EXPECT() *WebhookServiceMockMockRecorder {
+	return m.recorder
+}
+
+// DisableEndpoint mocks base method.
+func (m *WebhookServiceMock) DisableEndpoint(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableEndpoint", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableEndpoint indicates an expected call of DisableEndpoint.
+func (mr *WebhookServiceMockMockRecorder) DisableEndpoint(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableEndpoint", reflect.TypeOf((*WebhookServiceMock)(nil).DisableEndpoint), ctx, id)
+}
+
+// ListDeliveries mocks base method.
+func (m *WebhookServiceMock) ListDeliveries(ctx context.Context, endpointID int64, limit int) ([]webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, endpointID, limit)
+	ret0, _ := ret[0].([]webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *WebhookServiceMockMockRecorder) ListDeliveries(ctx, endpointID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*WebhookServiceMock)(nil).ListDeliveries), ctx, endpointID, limit)
+}
+
+// ListEndpoints mocks base method.
+func (m *WebhookServiceMock) ListEndpoints(ctx context.Context, organizationName string) ([]webhook.Endpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEndpoints", ctx, organizationName)
+	ret0, _ := ret[0].([]webhook.Endpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEndpoints indicates an expected call of ListEndpoints.
+func (mr *WebhookServiceMockMockRecorder) ListEndpoints(ctx, organizationName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEndpoints", reflect.TypeOf((*WebhookServiceMock)(nil).ListEndpoints), ctx, organizationName)
+}
+
+// Redeliver mocks base method.
+func (m *WebhookServiceMock) Redeliver(ctx context.Context, endpointID, deliveryID int64) (*webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", ctx, endpointID, deliveryID)
+	ret0, _ := ret[0].(*webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *WebhookServiceMockMockRecorder) Redeliver(ctx, endpointID, deliveryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*WebhookServiceMock)(nil).Redeliver), ctx, endpointID, deliveryID)
+}
+
+// RegisterEndpoint mocks base method.
+func (m *WebhookServiceMock) RegisterEndpoint(ctx context.Context, organizationName, url string, eventTypes []event.Type) (*webhook.Endpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterEndpoint", ctx, organizationName, url, eventTypes)
+	ret0, _ := ret[0].(*webhook.Endpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterEndpoint indicates an expected call of RegisterEndpoint.
+func (mr *WebhookServiceMockMockRecorder) RegisterEndpoint(ctx, organizationName, url, eventTypes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterEndpoint", reflect.TypeOf((*WebhookServiceMock)(nil).RegisterEndpoint), ctx, organizationName, url, eventTypes)
+}